@@ -1,31 +1,60 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/fatih/color"
+	"github.com/promptsmith/cli/internal/benchmark"
 	"github.com/promptsmith/cli/internal/db"
 	"github.com/promptsmith/cli/internal/prompt"
 	"github.com/promptsmith/cli/internal/scanner"
 	"github.com/spf13/cobra"
 )
 
+var addStrict bool
+
 var addCmd = &cobra.Command{
-	Use:   "add <file>",
-	Short: "Track a new prompt file",
-	Long:  `Add a prompt file to PromptSmith tracking. The file will be parsed and an initial version will be created.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runAdd,
+	Use:   "add <file|dir|glob>...",
+	Short: "Track new prompt files",
+	Long: `Add prompt files to PromptSmith tracking. Each file will be parsed and an initial version will be created.
+
+A directory adds every .prompt file under it, and a quoted glob pattern
+adds every file it matches. When adding several files, ones that are
+already tracked are skipped with a warning instead of failing the batch.
+
+With --strict, the file's frontmatter must give a name and a description,
+and its model_hint, if any, must be a known provider model or a configured
+model alias.
+
+Examples:
+  promptsmith add prompts/summarizer.prompt
+  promptsmith add prompts/                        # Every .prompt file, recursively
+  promptsmith add 'prompts/*.prompt'
+  promptsmith add prompts/summarizer.prompt --strict`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runAdd,
 }
 
 func init() {
+	addCmd.Flags().BoolVar(&addStrict, "strict", false, "reject prompts with incomplete frontmatter or an unknown model_hint")
 	rootCmd.AddCommand(addCmd)
 }
 
+// errAlreadyTracked is returned by addPromptFile for a file that is
+// already tracked, which a batch add skips
+var errAlreadyTracked = errors.New("already tracked")
+
 func runAdd(cmd *cobra.Command, args []string) error {
-	filePath := args[0]
+	files, batch, err := expandAddArgs(args)
+	if err != nil {
+		return err
+	}
 
 	// Find project root
 	projectRoot, err := db.FindProjectRoot()
@@ -49,40 +78,136 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no project found in database")
 	}
 
+	cyan := color.New(color.FgCyan).SprintFunc()
+	if !batch {
+		p, err := addPromptFile(database, project, projectRoot, files[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nRun %s and %s to create the first version.\n", cyan("promptsmith stage "+p.Name), cyan("promptsmith commit -m \"message\""))
+		return nil
+	}
+
+	yellow := color.New(color.FgYellow).SprintFunc()
+	var added, skipped int
+	for _, file := range files {
+		if _, err := addPromptFile(database, project, projectRoot, file); err != nil {
+			if errors.Is(err, errAlreadyTracked) {
+				fmt.Printf("%s Skipped %s: already tracked\n", yellow("!"), file)
+				skipped++
+				continue
+			}
+			fmt.Printf("\nAdded %d prompt(s) before the error.\n", added)
+			return err
+		}
+		added++
+	}
+
+	fmt.Printf("\nAdded %d prompt(s), skipped %d already tracked.\n", added, skipped)
+	if added > 0 {
+		fmt.Printf("Run %s to create the first versions.\n", cyan("promptsmith commit --all -m \"message\""))
+	}
+	return nil
+}
+
+// expandAddArgs turns add's arguments into the prompt files to add. Glob
+// patterns are expanded and directories searched recursively for .prompt
+// files. batch reports whether more than a single named file may be added.
+func expandAddArgs(args []string) (files []string, batch bool, err error) {
+	seen := make(map[string]bool)
+	addFile := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, arg := range args {
+		if strings.ContainsAny(arg, "*?[") {
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid pattern %s: %w", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, false, fmt.Errorf("no files match %s", arg)
+			}
+			for _, m := range matches {
+				addFile(m)
+			}
+			batch = true
+			continue
+		}
+
+		info, err := os.Stat(arg)
+		if err != nil || !info.IsDir() {
+			// Missing files are reported when they are read
+			addFile(arg)
+			continue
+		}
+
+		batch = true
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && filepath.Ext(path) == ".prompt" {
+				addFile(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to search %s: %w", arg, err)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, false, fmt.Errorf("no .prompt files found in %s", strings.Join(args, ", "))
+	}
+	return files, batch || len(files) > 1, nil
+}
+
+// addPromptFile tracks the prompt file at filePath
+func addPromptFile(database *db.DB, project *db.Project, projectRoot, filePath string) (*db.Prompt, error) {
 	// Resolve file path
 	absPath, err := filepath.Abs(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to resolve path: %w", err)
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
 	}
 
 	// Make path relative to project root
 	relPath, err := filepath.Rel(projectRoot, absPath)
 	if err != nil {
-		return fmt.Errorf("failed to make path relative: %w", err)
+		return nil, fmt.Errorf("failed to make path relative: %w", err)
 	}
 	if _, err := safeProjectPath(projectRoot, relPath); err != nil {
-		return fmt.Errorf("prompt file must be inside the project: %w", err)
+		return nil, fmt.Errorf("prompt file must be inside the project: %w", err)
 	}
 
 	// Check if file exists
 	content, err := os.ReadFile(absPath)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	// Check if already tracked
 	existing, err := database.GetPromptByPath(relPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if existing != nil {
-		return fmt.Errorf("prompt %s is already tracked", relPath)
+		return nil, fmt.Errorf("prompt %s is %w", relPath, errAlreadyTracked)
 	}
 
 	// Parse prompt file
 	parsed, err := prompt.Parse(string(content))
 	if err != nil {
-		return fmt.Errorf("failed to parse prompt: %w", err)
+		return nil, fmt.Errorf("failed to parse prompt: %w", err)
+	}
+
+	if addStrict {
+		if problems := strictFrontmatterProblems(projectRoot, parsed); len(problems) > 0 {
+			return nil, fmt.Errorf("%s does not pass --strict:\n  - %s", relPath, strings.Join(problems, "\n  - "))
+		}
 	}
 
 	// Scan for secrets
@@ -109,16 +234,16 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	// Check for name collision
 	existingByName, err := database.GetPromptByName(promptName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if existingByName != nil {
-		return fmt.Errorf("a prompt named %s already exists", promptName)
+		return nil, fmt.Errorf("a prompt named %s already exists", promptName)
 	}
 
 	// Create prompt entry
 	p, err := database.CreatePrompt(project.ID, promptName, parsed.Description(), relPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	green := color.New(color.FgGreen).SprintFunc()
@@ -132,8 +257,31 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	if len(parsed.ExtractedVars) > 0 {
 		fmt.Printf("  Variables: %v\n", parsed.ExtractedVars)
 	}
-	fmt.Printf("\nRun %s to create the first version.\n", cyan("promptsmith commit -m \"message\""))
+	return p, nil
+}
+
+// strictFrontmatterProblems describes each way parsed falls short of what
+// --strict requires of its frontmatter
+func strictFrontmatterProblems(projectRoot string, parsed *prompt.ParsedPrompt) []string {
+	if !parsed.HasFrontmatter || parsed.Frontmatter == nil {
+		return []string{"no frontmatter: add a name and description between --- lines"}
+	}
 
-	_ = p // Silence unused warning
-	return nil
+	var problems []string
+	if strings.TrimSpace(parsed.Frontmatter.Name) == "" {
+		problems = append(problems, "frontmatter is missing a name")
+	}
+	if strings.TrimSpace(parsed.Frontmatter.Description) == "" {
+		problems = append(problems, "frontmatter is missing a description")
+	}
+
+	if hint := parsed.Frontmatter.ModelHint; hint != "" {
+		catalog := benchmark.NewModelCatalog()
+		if err := applyModelAliases(projectRoot, catalog); err != nil {
+			problems = append(problems, err.Error())
+		} else if !slices.Contains(catalog.Models(), catalog.ResolveModel(hint)) {
+			problems = append(problems, fmt.Sprintf("model_hint %s is not a known model (known: %s)", hint, strings.Join(catalog.Models(), ", ")))
+		}
+	}
+	return problems
 }
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/promptsmith/cli/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	aliasDelete bool
+	aliasList   bool
+)
+
+// aliasHintOutput receives the hint printed when a prompt is looked up by
+// an alias
+var aliasHintOutput io.Writer = os.Stderr
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias <old-name> [prompt]",
+	Short: "Create, list, or delete prompt aliases",
+	Long: `Manage other names a prompt can be referred to by.
+
+Tests, benchmarks, chains and the show, log, diff, checkout and blame
+commands accept an alias where a prompt name is expected, printing a hint
+to move to the prompt's name. Commands that create, change or remove
+prompts only take a prompt's own name, and a new prompt can reuse an
+alias's name. 'promptsmith rename' keeps the old name as an alias
+automatically.
+
+Examples:
+  promptsmith alias summarizer digest          # 'summarizer' now means 'digest'
+  promptsmith alias --list                     # List all aliases
+  promptsmith alias summarizer --delete        # Delete an alias`,
+	Args: cobra.RangeArgs(0, 2),
+	RunE: runAlias,
+}
+
+func init() {
+	aliasCmd.Flags().BoolVarP(&aliasDelete, "delete", "d", false, "delete the specified alias")
+	aliasCmd.Flags().BoolVarP(&aliasList, "list", "l", false, "list all aliases")
+	rootCmd.AddCommand(aliasCmd)
+}
+
+type aliasOutput struct {
+	Alias     string `json:"alias"`
+	Prompt    string `json:"prompt"`
+	CreatedAt string `json:"created_at"`
+}
+
+func runAlias(cmd *cobra.Command, args []string) error {
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	database, err := db.Open(projectRoot)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if aliasList || len(args) == 0 {
+		return listAliases(database)
+	}
+
+	alias := args[0]
+	if aliasDelete {
+		if len(args) > 1 {
+			return fmt.Errorf("--delete takes only the alias")
+		}
+		if err := database.DeletePromptAlias(alias); err != nil {
+			return err
+		}
+		green := color.New(color.FgGreen).SprintFunc()
+		fmt.Printf("%s Deleted alias '%s'\n", green("✓"), alias)
+		return nil
+	}
+
+	if len(args) < 2 {
+		return fmt.Errorf("prompt name required")
+	}
+	p, err := database.GetPromptByName(args[1])
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return fmt.Errorf("prompt '%s' not found", args[1])
+	}
+
+	if _, err := database.SetPromptAlias(alias, p.ID); err != nil {
+		return err
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	fmt.Printf("%s '%s' is now an alias of %s\n", green("✓"), alias, cyan(p.Name))
+	return nil
+}
+
+func listAliases(database *db.DB) error {
+	aliases, err := database.ListPromptAliases()
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		outputs := make([]aliasOutput, 0, len(aliases))
+		for _, a := range aliases {
+			outputs = append(outputs, aliasOutput{
+				Alias:     a.Alias,
+				Prompt:    a.PromptName,
+				CreatedAt: a.CreatedAt.Format("2006-01-02 15:04:05"),
+			})
+		}
+		data, _ := json.MarshalIndent(outputs, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(aliases) == 0 {
+		fmt.Println("No aliases")
+		return nil
+	}
+
+	cyan := color.New(color.FgCyan).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+	for _, a := range aliases {
+		fmt.Printf("  %s -> %s  %s\n", yellow(a.Alias), cyan(a.PromptName), dim(a.CreatedAt.Format("2006-01-02")))
+	}
+	return nil
+}
+
+// resolvePrompt finds a prompt by name or by an alias left by a rename,
+// hinting that a reference by alias should use the new name. Commands that
+// only read a prompt use it; ones that create or remove prompts look names
+// up exactly.
+func resolvePrompt(database *db.DB, name string) (*db.Prompt, error) {
+	p, aliased, err := database.ResolvePromptName(name)
+	if err != nil {
+		return nil, err
+	}
+	if aliased {
+		fmt.Fprintf(aliasHintOutput, "hint: prompt '%s' was renamed to '%s'; update references to use the new name\n", name, p.Name)
+	}
+	return p, nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/promptsmith/cli/internal/logging"
+)
+
+// authorEnvVar overrides the author recorded on new versions
+const authorEnvVar = "PROMPTSMITH_AUTHOR"
+
+// resolveAuthor returns the author to record on versions created in
+// projectRoot. The first of these that is set wins: override (the --author
+// flag), $PROMPTSMITH_AUTHOR, git's user.name or user.email for the
+// project, and $USER. Without any of them the author is "unknown".
+func resolveAuthor(projectRoot, override string) string {
+	if author := strings.TrimSpace(override); author != "" {
+		return author
+	}
+	if author := strings.TrimSpace(os.Getenv(authorEnvVar)); author != "" {
+		return author
+	}
+	for _, key := range []string{"user.name", "user.email"} {
+		if author := gitConfigValue(projectRoot, key); author != "" {
+			return author
+		}
+	}
+	if author := strings.TrimSpace(os.Getenv("USER")); author != "" {
+		return author
+	}
+	return "unknown"
+}
+
+// gitConfigValue returns a git config value as seen from dir, or "" if git
+// is not installed or the key is unset
+func gitConfigValue(dir, key string) string {
+	out, err := exec.Command("git", "-C", dir, "config", "--get", key).Output()
+	if err != nil {
+		logging.Debug("git config lookup failed", "key", key, "error", err)
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
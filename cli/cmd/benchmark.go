@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/fsnotify/fsnotify"
 	"github.com/promptsmith/cli/internal/benchmark"
 	"github.com/promptsmith/cli/internal/db"
 	"github.com/spf13/cobra"
@@ -19,6 +23,22 @@ var (
 	benchRuns    int
 	benchVersion string
 	benchOutput  string
+	benchOutDir  string
+	benchAppend  bool
+	benchConc    int
+	benchFormat  string
+	benchTemps   string
+	benchInput   string
+	benchRetries int
+	benchEnvFile string
+	benchWatch   bool
+	benchLive    bool
+
+	benchCompareThreshold float64
+
+	rankWeightCost    float64
+	rankWeightLatency float64
+	rankWeightErrors  float64
 )
 
 var benchmarkCmd = &cobra.Command{
@@ -29,36 +49,152 @@ var benchmarkCmd = &cobra.Command{
 Benchmark suites are YAML files that define models to test and metrics to collect.
 If no files are specified, runs all .bench.yaml files in the benchmarks/ directory.
 
+With --watch, the suites run once and then the prompts/ and benchmarks/
+directories are watched. When a suite file, or the file of the prompt a suite
+benchmarks, is saved, the affected suites run again. Each run calls the
+models and costs money, so a re-run asks for confirmation first unless
+--watch-live is given. A suite that pins no version benchmarks its prompt
+file as saved, so edits need not be committed first; runs of uncommitted
+edits are not stored.
+
 Examples:
   promptsmith benchmark                              # Run all benchmarks
   promptsmith benchmark benchmarks/summarizer.bench.yaml
   promptsmith benchmark --models gpt-4o,claude-sonnet
   promptsmith benchmark --runs 10                    # 10 runs per model
-  promptsmith benchmark -o results.json              # Save results`,
+  promptsmith benchmark --concurrency 4              # Up to 4 requests at once
+  promptsmith benchmark --temperatures 0,0.5,1       # Sweep temperatures per model
+  promptsmith benchmark --input article.yaml         # Render the prompt with these inputs
+  promptsmith benchmark -o results.json              # Save results
+  promptsmith benchmark -o history.json --append     # Add results to the file
+  promptsmith benchmark --format csv -o runs.csv     # One spreadsheet row per run
+  promptsmith benchmark --output-dir bench-out       # Save raw model outputs
+  promptsmith benchmark --env-file .env              # Load API keys from .env
+  promptsmith benchmark --watch                      # Re-run suites on changes, asking first
+  promptsmith benchmark --watch --watch-live         # Re-run without asking`,
 	RunE: runBenchmark,
 }
 
 var benchmarkCompareCmd = &cobra.Command{
-	Use:   "compare <file1.json> <file2.json>",
-	Short: "Compare two benchmark result files",
-	Long: `Compare two JSON benchmark result files and show a delta table.
+	Use:   "compare <file1.json> <file2.json> | <suite> <run-a> <run-b>",
+	Short: "Compare two benchmark runs",
+	Long: `Compare two benchmark runs and flag per-model regressions.
+
+The runs are either two JSON result files written with --output, or two runs
+of a suite stored by 'promptsmith benchmark' (which prints each run's ID).
+For every model in both runs, p50 and p95 latency, cost per request, average
+tokens and error rate are compared, and any that grew by more than
+--threshold percent is flagged as a regression. Models whose completions
+differ between the runs are marked, and models in only one run are reported
+as added or removed.
 
 Examples:
-  promptsmith benchmark compare baseline.json latest.json`,
-	Args: cobra.ExactArgs(2),
+  promptsmith benchmark compare baseline.json latest.json
+  promptsmith benchmark compare summarizer-benchmark 3f2a... 9c1e...
+  promptsmith benchmark compare summarizer-benchmark 3f2a... 9c1e... --threshold 5`,
+	Args: cobra.RangeArgs(2, 3),
 	RunE: runBenchmarkCompare,
 }
 
+var benchmarkDiffModelsCmd = &cobra.Command{
+	Use:   "diff-models <benchmark|prompt>",
+	Short: "Rank models from the latest benchmark run",
+	Long: `Rank the models of the most recent stored benchmark run by a weighted score.
+
+Each metric is scaled across the models in the run, so the cheapest model gets
+full marks for cost and the most expensive none, and likewise for latency and
+error rate. The score (0-100, higher is better) is the weighted average.
+
+The argument is a benchmark suite name, or a prompt name to use the latest run
+of any suite benchmarking it. Runs are stored by 'promptsmith benchmark' and
+by benchmarks started from the web UI.
+
+Examples:
+  promptsmith benchmark diff-models summarizer-benchmark
+  promptsmith benchmark diff-models summarizer --weight-cost 0.8 --weight-latency 0.2
+  promptsmith benchmark diff-models summarizer --weight-errors 1 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBenchmarkDiffModels,
+}
+
 func init() {
 	benchmarkCmd.Flags().StringVarP(&benchModels, "models", "m", "", "comma-separated list of models to benchmark")
 	benchmarkCmd.Flags().IntVarP(&benchRuns, "runs", "r", 0, "number of runs per model (overrides suite config)")
+	benchmarkCmd.Flags().StringVar(&benchTemps, "temperatures", "", "comma-separated temperatures to run each model at (overrides suite config)")
+	benchmarkCmd.Flags().StringVar(&benchInput, "input", "", "YAML or JSON file of prompt variable values (overrides suite inputs)")
+	benchmarkCmd.Flags().StringVar(&benchEnvFile, "env-file", "", "load provider API keys from this dotenv file (variables already set win)")
+	benchmarkCmd.Flags().IntVar(&benchRetries, "max-retries", 2, "retry model requests that hit rate limits or transient server errors up to this many times")
+	benchmarkCmd.Flags().BoolVarP(&benchWatch, "watch", "w", false, "watch for file changes and re-run the affected suites")
+	benchmarkCmd.Flags().BoolVar(&benchLive, "watch-live", false, "with --watch, re-run suites without asking for confirmation")
+	benchmarkCmd.Flags().IntVar(&benchConc, "concurrency", 1, "maximum number of model requests in flight at once")
 	benchmarkCmd.Flags().StringVarP(&benchVersion, "version", "v", "", "benchmark against specific prompt version")
 	benchmarkCmd.Flags().StringVarP(&benchOutput, "output", "o", "", "write results to file (JSON format)")
+	benchmarkCmd.Flags().BoolVar(&benchAppend, "append", false, "with --output, append results to the JSON array in the file instead of overwriting it")
+	benchmarkCmd.Flags().StringVar(&benchFormat, "format", "text", "output format: text, csv (one row per run, written to --output or stdout)")
+	benchmarkCmd.Flags().StringVar(&benchOutDir, "output-dir", "", "write each run's raw prompt and completion to <dir>/<model>/<run>.txt")
+	benchmarkCompareCmd.Flags().Float64Var(&benchCompareThreshold, "threshold", 10, "flag metrics that grew by more than this percentage")
+	benchmarkDiffModelsCmd.Flags().Float64Var(&rankWeightCost, "weight-cost", 0.5, "weight of cost per request in the score")
+	benchmarkDiffModelsCmd.Flags().Float64Var(&rankWeightLatency, "weight-latency", 0.5, "weight of p50 latency in the score")
+	benchmarkDiffModelsCmd.Flags().Float64Var(&rankWeightErrors, "weight-errors", 0, "weight of error rate in the score")
 	benchmarkCmd.AddCommand(benchmarkCompareCmd)
+	benchmarkCmd.AddCommand(benchmarkDiffModelsCmd)
 	rootCmd.AddCommand(benchmarkCmd)
 }
 
 func runBenchmark(cmd *cobra.Command, args []string) error {
+	if benchAppend && benchOutput == "" {
+		return fmt.Errorf("--append requires --output")
+	}
+	if benchRetries < 0 {
+		return fmt.Errorf("--max-retries must not be negative")
+	}
+	if benchEnvFile != "" {
+		if err := loadEnvFile(benchEnvFile); err != nil {
+			return err
+		}
+	}
+	if benchConc < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	switch benchFormat {
+	case "", "text":
+	case "csv":
+		if jsonOut {
+			return fmt.Errorf("--format csv cannot be combined with --json")
+		}
+		if benchAppend {
+			return fmt.Errorf("--append cannot be combined with --format csv")
+		}
+	default:
+		return fmt.Errorf("unknown format '%s' (expected text or csv)", benchFormat)
+	}
+	if benchLive && !benchWatch {
+		return fmt.Errorf("--watch-live requires --watch")
+	}
+	if benchWatch {
+		switch {
+		case jsonOut:
+			return fmt.Errorf("--json cannot be combined with --watch")
+		case benchFormat == "csv":
+			return fmt.Errorf("--format csv cannot be combined with --watch")
+		case benchOutput != "":
+			return fmt.Errorf("--output cannot be combined with --watch")
+		}
+	}
+	temperatures, err := parseTemperatures(benchTemps)
+	if err != nil {
+		return err
+	}
+	var inputs map[string]any
+	if benchInput != "" {
+		inputs, err = benchmark.ParseInputsFile(benchInput)
+		if err != nil {
+			return err
+		}
+	}
+	// Only the results go to stdout when it carries JSON or CSV
+	quiet := jsonOut || (benchFormat == "csv" && benchOutput == "")
+
 	projectRoot, err := db.FindProjectRoot()
 	if err != nil {
 		return err
@@ -74,16 +210,8 @@ func runBenchmark(cmd *cobra.Command, args []string) error {
 	var suiteFiles []string
 	if len(args) > 0 {
 		suiteFiles = args
-	} else {
-		// Look for *.bench.yaml in benchmarks/ directory
-		benchDir := filepath.Join(projectRoot, "benchmarks")
-		if _, err := os.Stat(benchDir); err == nil {
-			matches, err := filepath.Glob(filepath.Join(benchDir, "*.bench.yaml"))
-			if err != nil {
-				return fmt.Errorf("failed to find benchmark files: %w", err)
-			}
-			suiteFiles = matches
-		}
+	} else if suiteFiles, err = findBenchmarkSuites(projectRoot); err != nil {
+		return err
 	}
 
 	if len(suiteFiles) == 0 {
@@ -100,8 +228,44 @@ func runBenchmark(cmd *cobra.Command, args []string) error {
 	if anthropic, err := benchmark.NewAnthropicProvider(); err == nil {
 		registry.Register(anthropic)
 	}
+	if gemini, err := benchmark.NewGeminiProvider(); err == nil {
+		registry.Register(gemini)
+	}
+	if ollama, err := benchmark.NewOllamaProvider(); err == nil {
+		registry.Register(ollama)
+	}
+	if err := applyModelAliases(projectRoot, registry); err != nil {
+		return err
+	}
 
 	runner := benchmark.NewRunner(database, registry)
+	runner.OutputDir = benchOutDir
+	runner.Concurrency = benchConc
+	runner.MaxRetries = benchRetries
+
+	if benchWatch {
+		return runBenchmarkWatch(database, runner, projectRoot, args, suiteFiles, temperatures, inputs)
+	}
+	return runBenchmarkSuites(database, runner, suiteFiles, temperatures, inputs, quiet)
+}
+
+// findBenchmarkSuites returns the *.bench.yaml files in the project's
+// benchmarks/ directory
+func findBenchmarkSuites(projectRoot string) ([]string, error) {
+	benchDir := filepath.Join(projectRoot, "benchmarks")
+	if _, err := os.Stat(benchDir); err != nil {
+		return nil, nil
+	}
+	matches, err := filepath.Glob(filepath.Join(benchDir, "*.bench.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find benchmark files: %w", err)
+	}
+	return matches, nil
+}
+
+// runBenchmarkSuites runs each suite file with the command-line overrides
+// applied, stores the runs and reports the results
+func runBenchmarkSuites(database *db.DB, runner *benchmark.Runner, suiteFiles []string, temperatures []float64, inputs map[string]any, quiet bool) error {
 	var allResults []*benchmark.BenchmarkResult
 
 	cyan := color.New(color.FgCyan).SprintFunc()
@@ -125,6 +289,21 @@ func runBenchmark(cmd *cobra.Command, args []string) error {
 			suite.RunsPerModel = benchRuns
 		}
 
+		// Layer the inputs file over the suite's inputs
+		if len(inputs) > 0 {
+			if suite.Inputs == nil {
+				suite.Inputs = make(map[string]any, len(inputs))
+			}
+			for k, v := range inputs {
+				suite.Inputs[k] = v
+			}
+		}
+
+		// Override temperatures if specified
+		if temperatures != nil {
+			suite.Temperatures = temperatures
+		}
+
 		// Override models if specified
 		if benchModels != "" {
 			suite.Models = strings.Split(benchModels, ",")
@@ -133,10 +312,17 @@ func runBenchmark(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		if !jsonOut {
+		if !quiet {
 			fmt.Printf("\n%s %s@%s\n", cyan("▶"), suite.Prompt, suite.Version)
 			fmt.Printf("  Models: %s\n", strings.Join(suite.Models, ", "))
 			fmt.Printf("  Runs per model: %d\n", suite.RunsPerModel)
+			if len(suite.Temperatures) > 0 {
+				temps := make([]string, len(suite.Temperatures))
+				for i, t := range suite.Temperatures {
+					temps[i] = fmt.Sprintf("%g", t)
+				}
+				fmt.Printf("  Temperatures: %s\n", strings.Join(temps, ", "))
+			}
 		}
 
 		result, err := runner.Run(context.Background(), suite)
@@ -147,33 +333,57 @@ func runBenchmark(cmd *cobra.Command, args []string) error {
 
 		allResults = append(allResults, result)
 
+		// A run of uncommitted edits is not stored against the version
+		// they were made to
+		var run *db.BenchmarkRun
+		if !result.Uncommitted {
+			run, err = saveBenchmarkResult(database, suite, result)
+			if err != nil && !quiet {
+				fmt.Printf("%s Failed to store run: %v\n", yellow("!"), err)
+			}
+		}
+
 		// Print results table
-		if !jsonOut {
+		if !quiet {
 			printBenchmarkTable(result)
+			if run != nil {
+				fmt.Printf("  %s %s\n", dim("Run ID:"), run.ID)
+			} else if result.Uncommitted {
+				fmt.Printf("  %s\n", dim(fmt.Sprintf("Benchmarked uncommitted edits to %s@%s; run not stored", result.PromptName, result.Version)))
+			}
 		}
 	}
 
-	// Output JSON if requested
+	// Output JSON or CSV if requested
 	if jsonOut {
-		data, _ := json.MarshalIndent(allResults, "", "  ")
 		if benchOutput != "" {
-			if err := os.WriteFile(benchOutput, data, 0644); err != nil {
-				return fmt.Errorf("failed to write output: %w", err)
+			if err := writeBenchmarkOutput(allResults); err != nil {
+				return err
 			}
 			fmt.Printf("Results written to %s\n", benchOutput)
 		} else {
+			data, _ := json.MarshalIndent(allResults, "", "  ")
 			fmt.Println(string(data))
 		}
 	} else if benchOutput != "" {
-		data, _ := json.MarshalIndent(allResults, "", "  ")
-		if err := os.WriteFile(benchOutput, data, 0644); err != nil {
-			return fmt.Errorf("failed to write output: %w", err)
+		if err := writeBenchmarkOutput(allResults); err != nil {
+			return err
 		}
 		fmt.Printf("\n%s Results written to %s\n", dim("→"), benchOutput)
+	} else if benchFormat == "csv" {
+		data, err := benchmark.MarshalCSV(allResults)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+	}
+
+	if benchOutDir != "" && !quiet && len(allResults) > 0 {
+		fmt.Printf("%s Raw outputs written to %s\n", dim("→"), benchOutDir)
 	}
 
 	// Print recommendation
-	if !jsonOut && len(allResults) > 0 {
+	if !quiet && len(allResults) > 0 {
 		for _, result := range allResults {
 			printRecommendation(result, yellow)
 		}
@@ -182,20 +392,220 @@ func runBenchmark(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runBenchmarkWatch(database *db.DB, runner *benchmark.Runner, projectRoot string, args, suiteFiles []string, temperatures []float64, inputs map[string]any) error {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	// Watch prompts/, benchmarks/ and the directory of any suite named on the
+	// command line
+	dirs := []string{filepath.Join(projectRoot, "prompts"), filepath.Join(projectRoot, "benchmarks")}
+	for _, file := range suiteFiles {
+		if abs, err := filepath.Abs(file); err == nil {
+			dirs = append(dirs, filepath.Dir(abs))
+		}
+	}
+	watching := 0
+	for _, dir := range slices.Compact(slices.Sorted(slices.Values(dirs))) {
+		if err := watcher.Add(dir); err == nil {
+			watching++
+		}
+	}
+	if watching == 0 {
+		return fmt.Errorf("failed to watch the prompts and benchmarks directories")
+	}
+
+	printWatching := func() {
+		fmt.Printf("\n%s Watching for changes... %s\n", cyan("👁"), dim("(Ctrl+C to stop)"))
+	}
+
+	// Benchmark prompt edits as they are saved, without committing them
+	runner.WorkingTree = projectRoot
+
+	// Initial run
+	if err := runBenchmarkSuites(database, runner, suiteFiles, temperatures, inputs, false); err != nil {
+		fmt.Printf("%s %v\n", color.RedString("✗"), err)
+	}
+	printWatching()
+
+	return watchLoop(watcher.Events, watcher.Errors, 100*time.Millisecond, func(changed []string) {
+		files := suiteFiles
+		if len(args) == 0 {
+			// Suites added while watching are picked up too
+			found, err := findBenchmarkSuites(projectRoot)
+			if err != nil {
+				fmt.Printf("%s %v\n", color.RedString("✗"), err)
+				return
+			}
+			files = found
+		}
+		affected := affectedBenchmarkSuites(benchmarkWatchTargets(database, projectRoot, files), changed)
+		if len(affected) == 0 {
+			return
+		}
+
+		names := make([]string, len(affected))
+		for i, file := range affected {
+			names[i] = relativeToProject(projectRoot, file)
+		}
+		fmt.Printf("\n%s Changed, affecting %s\n", cyan("↻"), strings.Join(names, ", "))
+		if !benchLive && !confirmBenchmarkRerun(len(affected)) {
+			fmt.Printf("%s Skipped. Use --watch-live to re-run without asking.\n", yellow("!"))
+			printWatching()
+			return
+		}
+		if err := runBenchmarkSuites(database, runner, affected, temperatures, inputs, false); err != nil {
+			fmt.Printf("%s %v\n", color.RedString("✗"), err)
+		}
+		printWatching()
+	})
+}
+
+// benchmarkWatchTarget is a suite file and the file of the prompt it
+// benchmarks, as absolute paths
+type benchmarkWatchTarget struct {
+	suite  string
+	prompt string // Empty if the suite or its prompt cannot be read
+}
+
+// benchmarkWatchTargets finds the prompt file each suite benchmarks
+func benchmarkWatchTargets(database *db.DB, projectRoot string, suiteFiles []string) []benchmarkWatchTarget {
+	targets := make([]benchmarkWatchTarget, 0, len(suiteFiles))
+	for _, file := range suiteFiles {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			continue
+		}
+		target := benchmarkWatchTarget{suite: abs}
+		if suite, err := benchmark.ParseSuiteFile(abs); err == nil {
+			if p, _, err := database.ResolvePromptName(suite.Prompt); err == nil && p != nil {
+				target.prompt = filepath.Join(projectRoot, p.FilePath)
+			}
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// affectedBenchmarkSuites returns the suites to re-run after the changed
+// files were saved: those whose own file or prompt file changed, in the
+// order of targets
+func affectedBenchmarkSuites(targets []benchmarkWatchTarget, changed []string) []string {
+	var affected []string
+	for _, t := range targets {
+		if slices.Contains(changed, t.suite) || (t.prompt != "" && slices.Contains(changed, t.prompt)) {
+			affected = append(affected, t.suite)
+		}
+	}
+	return affected
+}
+
+// confirmBenchmarkRerun asks before re-running suites, since every run calls
+// the models
+func confirmBenchmarkRerun(suites int) bool {
+	fmt.Printf("Run %d suite(s) again? This calls the models and may cost money. [y/N] ", suites)
+	var response string
+	fmt.Scanln(&response)
+	return response == "y" || response == "Y"
+}
+
+// relativeToProject returns path relative to the project root if it is
+// inside it
+func relativeToProject(projectRoot, path string) string {
+	if rel, err := filepath.Rel(projectRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// parseTemperatures reads the --temperatures list; empty means no override
+func parseTemperatures(list string) ([]float64, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, nil
+	}
+	var temperatures []float64
+	for _, field := range strings.Split(list, ",") {
+		t, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid temperature '%s'", strings.TrimSpace(field))
+		}
+		temperatures = append(temperatures, t)
+	}
+	if err := benchmark.CheckTemperatures(temperatures); err != nil {
+		return nil, err
+	}
+	return temperatures, nil
+}
+
+// writeBenchmarkOutput writes results to --output as CSV with --format csv,
+// or as JSON, adding them to the results already there with --append
+func writeBenchmarkOutput(results []*benchmark.BenchmarkResult) error {
+	if benchFormat == "csv" {
+		data, err := benchmark.MarshalCSV(results)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(benchOutput, data, 0644); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		return nil
+	}
+	if benchAppend {
+		entries := make([]json.RawMessage, 0, len(results))
+		for _, r := range results {
+			data, _ := json.Marshal(r)
+			entries = append(entries, data)
+		}
+		return appendJSONOutput(benchOutput, entries...)
+	}
+
+	data, _ := json.MarshalIndent(results, "", "  ")
+	if err := os.WriteFile(benchOutput, data, 0644); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// saveBenchmarkResult stores a run so diff-models and the web UI can read it
+// back later, keyed by suite name like runs started from the API
+func saveBenchmarkResult(database *db.DB, suite *benchmark.Suite, result *benchmark.BenchmarkResult) (*db.BenchmarkRun, error) {
+	// Suites may still name the prompt by an alias left by a rename
+	p, _, err := database.ResolvePromptName(suite.Prompt)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, fmt.Errorf("prompt '%s' not found", suite.Prompt)
+	}
+	if err := database.EnsureBenchmark(suite.Name, p.ID, "{}"); err != nil {
+		return nil, err
+	}
+	data, _ := json.Marshal(result)
+	return database.SaveBenchmarkRun(suite.Name, result.VersionID, string(data))
+}
+
 func printBenchmarkTable(result *benchmark.BenchmarkResult) {
 	dim := color.New(color.Faint).SprintFunc()
 
 	// Table header
 	fmt.Println()
-	fmt.Printf("  %-20s %10s %10s %12s %10s\n",
-		"Model", "Latency", "Tokens", "Cost/Req", "Errors")
-	fmt.Printf("  %s\n", dim(strings.Repeat("─", 66)))
+	fmt.Printf("  %-20s %8s %8s %8s %8s %8s %10s %10s %10s\n",
+		"Model", "p50", "p95", "p99", "Mean", "Tokens", "Cost/Req", "Total", "Errors")
+	fmt.Printf("  %s\n", dim(strings.Repeat("─", 100)))
 
 	// Table rows
 	for _, m := range result.Models {
-		latency := fmt.Sprintf("%.0fms", m.LatencyP50Ms)
-		if m.LatencyP50Ms == 0 && m.Errors > 0 {
-			latency = "-"
+		ms := func(v float64) string {
+			if v == 0 && m.Errors > 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%.0fms", v)
 		}
 
 		tokens := fmt.Sprintf("%.0f", m.TotalTokensAvg)
@@ -204,8 +614,9 @@ func printBenchmarkTable(result *benchmark.BenchmarkResult) {
 		}
 
 		cost := fmt.Sprintf("$%.4f", m.CostPerRequest)
+		total := fmt.Sprintf("$%.4f", m.TotalCost)
 		if m.CostPerRequest == 0 {
-			cost = "-"
+			cost, total = "-", "-"
 		}
 
 		errors := "-"
@@ -213,119 +624,192 @@ func printBenchmarkTable(result *benchmark.BenchmarkResult) {
 			errors = fmt.Sprintf("%d (%.0f%%)", m.Errors, m.ErrorRate*100)
 		}
 
-		fmt.Printf("  %-20s %10s %10s %12s %10s\n",
-			m.Model, latency, tokens, cost, errors)
+		fmt.Printf("  %-20s %8s %8s %8s %8s %8s %10s %10s %10s\n",
+			m.Label(), ms(m.LatencyP50Ms), ms(m.LatencyP95Ms), ms(m.LatencyP99Ms), ms(m.LatencyAvgMs),
+			tokens, cost, total, errors)
 	}
 
-	fmt.Printf("  %s\n", dim(strings.Repeat("─", 66)))
+	fmt.Printf("  %s\n", dim(strings.Repeat("─", 100)))
 	fmt.Printf("  %s %dms\n", dim("Total time:"), result.DurationMs)
 }
 
+// benchmarkCompareOutput is the --json output of benchmark compare
+type benchmarkCompareOutput struct {
+	Before       string                      `json:"before"`
+	After        string                      `json:"after"`
+	ThresholdPct float64                     `json:"threshold_pct"`
+	Regressed    bool                        `json:"regressed"`
+	Models       []benchmark.ModelComparison `json:"models"`
+}
+
 func runBenchmarkCompare(cmd *cobra.Command, args []string) error {
-	file1, file2 := args[0], args[1]
+	if benchCompareThreshold < 0 {
+		return fmt.Errorf("--threshold must not be negative")
+	}
+
+	var before, after *benchmark.BenchmarkResult
+	var beforeLabel, afterLabel string
+	var err error
+	if len(args) == 3 {
+		before, after, err = loadStoredBenchmarkRuns(args[0], args[1], args[2])
+		beforeLabel, afterLabel = args[1], args[2]
+	} else {
+		before, after, err = loadBenchmarkResultFiles(args[0], args[1])
+		beforeLabel, afterLabel = args[0], args[1]
+	}
+	if err != nil {
+		return err
+	}
+
+	comparisons := benchmark.CompareResults(before, after, benchCompareThreshold)
+	regressed := false
+	for _, c := range comparisons {
+		regressed = regressed || c.Regressed
+	}
+
+	if jsonOut {
+		data, _ := json.MarshalIndent(benchmarkCompareOutput{
+			Before:       beforeLabel,
+			After:        afterLabel,
+			ThresholdPct: benchCompareThreshold,
+			Regressed:    regressed,
+			Models:       comparisons,
+		}, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	cyan := color.New(color.FgCyan).SprintFunc()
+	fmt.Printf("\n%s Comparing %s vs %s\n", cyan("▶"), beforeLabel, afterLabel)
+	printBenchmarkComparison(comparisons, benchCompareThreshold)
+	return nil
+}
 
+// loadBenchmarkResultFiles reads the first result of two files written by
+// 'promptsmith benchmark --output'
+func loadBenchmarkResultFiles(file1, file2 string) (*benchmark.BenchmarkResult, *benchmark.BenchmarkResult, error) {
 	data1, err := os.ReadFile(file1)
 	if err != nil {
-		return fmt.Errorf("failed to read %s: %w", file1, err)
+		return nil, nil, fmt.Errorf("failed to read %s: %w", file1, err)
 	}
 
 	data2, err := os.ReadFile(file2)
 	if err != nil {
-		return fmt.Errorf("failed to read %s: %w", file2, err)
+		return nil, nil, fmt.Errorf("failed to read %s: %w", file2, err)
 	}
 
 	var results1, results2 []*benchmark.BenchmarkResult
 	if err := json.Unmarshal(data1, &results1); err != nil {
-		return fmt.Errorf("failed to parse %s: %w", file1, err)
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", file1, err)
 	}
 	if err := json.Unmarshal(data2, &results2); err != nil {
-		return fmt.Errorf("failed to parse %s: %w", file2, err)
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", file2, err)
 	}
 
 	if len(results1) == 0 || len(results2) == 0 {
-		return fmt.Errorf("both files must contain at least one benchmark result")
+		return nil, nil, fmt.Errorf("both files must contain at least one benchmark result")
 	}
+	return results1[0], results2[0], nil
+}
 
-	cyan := color.New(color.FgCyan).SprintFunc()
-	green := color.New(color.FgGreen).SprintFunc()
-	red := color.New(color.FgRed).SprintFunc()
-	dim := color.New(color.Faint).SprintFunc()
+// loadStoredBenchmarkRuns reads two stored runs of the benchmark suite
+func loadStoredBenchmarkRuns(suite, runA, runB string) (*benchmark.BenchmarkResult, *benchmark.BenchmarkResult, error) {
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return nil, nil, err
+	}
 
-	fmt.Printf("\n%s Comparing %s vs %s\n", cyan("▶"), file1, file2)
+	database, err := db.Open(projectRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer database.Close()
 
-	// Build model map from both results
-	r1 := results1[0]
-	r2 := results2[0]
+	load := func(id string) (*benchmark.BenchmarkResult, error) {
+		run, err := database.GetBenchmarkRun(id)
+		if err != nil {
+			return nil, err
+		}
+		if run == nil || run.BenchmarkID != suite {
+			return nil, fmt.Errorf("run '%s' not found for benchmark '%s'", id, suite)
+		}
+		var result benchmark.BenchmarkResult
+		if err := json.Unmarshal([]byte(run.Results), &result); err != nil {
+			return nil, fmt.Errorf("failed to parse benchmark run %s: %w", id, err)
+		}
+		return &result, nil
+	}
 
-	modelMap1 := map[string]*benchmark.ModelResult{}
-	for i := range r1.Models {
-		modelMap1[r1.Models[i].Model] = &r1.Models[i]
+	before, err := load(runA)
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err := load(runB)
+	if err != nil {
+		return nil, nil, err
 	}
+	return before, after, nil
+}
 
-	fmt.Printf("\n  %-20s %12s %12s %12s\n", "Model", "Latency Δ", "Cost Δ", "Errors Δ")
-	fmt.Printf("  %s\n", dim(strings.Repeat("─", 60)))
+func printBenchmarkComparison(comparisons []benchmark.ModelComparison, thresholdPct float64) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+
+	fmt.Printf("\n  %-20s %10s %10s %10s %10s %10s  %s\n",
+		"Model", "p50", "p95", "Cost/Req", "Tokens", "Errors", "Output")
+	fmt.Printf("  %s\n", dim(strings.Repeat("─", 86)))
 
-	for _, m2 := range r2.Models {
-		m1, ok := modelMap1[m2.Model]
-		if !ok {
-			fmt.Printf("  %-20s %12s %12s %12s\n", m2.Model, "new", "new", "new")
+	regressions := 0
+	for _, c := range comparisons {
+		if c.Status != benchmark.ModelCompared {
+			fmt.Printf("  %-20s %s\n", c.Model, yellow(c.Status))
 			continue
 		}
 
-		latDelta := m2.LatencyP50Ms - m1.LatencyP50Ms
-		costDelta := m2.CostPerRequest - m1.CostPerRequest
-		errDelta := m2.Errors - m1.Errors
-
-		latStr := formatDelta(latDelta, "ms", true, green, red)
-		costStr := formatCostDelta(costDelta, green, red)
-		errStr := formatIntDelta(errDelta, green, red)
-
-		fmt.Printf("  %-20s %12s %12s %12s\n", m2.Model, latStr, costStr, errStr)
+		cols := make([]string, len(c.Metrics))
+		for i, d := range c.Metrics {
+			cols[i] = formatMetricDelta(d, green, red)
+			if d.Regressed {
+				regressions++
+			}
+		}
+		output := dim("same")
+		if c.OutputChanged {
+			output = yellow("changed")
+		}
+		fmt.Printf("  %-20s %s  %s\n", c.Model, strings.Join(cols, " "), output)
 	}
 
-	fmt.Printf("  %s\n", dim(strings.Repeat("─", 60)))
-	return nil
-}
-
-func formatDelta(delta float64, unit string, lowerBetter bool, green, red func(a ...interface{}) string) string {
-	if delta == 0 {
-		return fmt.Sprintf("0%s", unit)
-	}
-	sign := "+"
-	colorFn := red
-	if (lowerBetter && delta < 0) || (!lowerBetter && delta > 0) {
-		colorFn = green
-	}
-	if delta < 0 {
-		sign = ""
+	fmt.Printf("  %s\n", dim(strings.Repeat("─", 86)))
+	if regressions > 0 {
+		fmt.Printf("  %s %d metric(s) regressed by more than %g%%\n", red("✗"), regressions, thresholdPct)
+	} else {
+		fmt.Printf("  %s No regressions above %g%%\n", green("✓"), thresholdPct)
 	}
-	return colorFn(fmt.Sprintf("%s%.0f%s", sign, delta, unit))
 }
 
-func formatCostDelta(delta float64, green, red func(a ...interface{}) string) string {
-	if delta == 0 {
-		return "$0"
+// formatMetricDelta shows a metric's change as a percentage, padded to its
+// column before colouring: red when it regressed past the threshold and
+// green when it improved
+func formatMetricDelta(d benchmark.MetricDelta, green, red func(a ...interface{}) string) string {
+	var text string
+	switch {
+	case d.Before == d.After:
+		return fmt.Sprintf("%10s", "0%")
+	case d.Before == 0:
+		text = fmt.Sprintf("%10s", "new")
+	default:
+		text = fmt.Sprintf("%+9.1f%%", d.ChangePct)
 	}
-	sign := "+"
-	colorFn := red
-	if delta < 0 {
-		sign = ""
-		colorFn = green
-	}
-	return colorFn(fmt.Sprintf("%s$%.4f", sign, delta))
-}
-
-func formatIntDelta(delta int, green, red func(a ...interface{}) string) string {
-	if delta == 0 {
-		return "0"
+	if d.Regressed {
+		return red(text)
 	}
-	sign := "+"
-	colorFn := red
-	if delta < 0 {
-		sign = ""
-		colorFn = green
+	if d.After < d.Before {
+		return green(text)
 	}
-	return colorFn(fmt.Sprintf("%s%d", sign, delta))
+	return text
 }
 
 func printRecommendation(result *benchmark.BenchmarkResult, yellow func(a ...interface{}) string) {
@@ -350,11 +834,124 @@ func printRecommendation(result *benchmark.BenchmarkResult, yellow func(a ...int
 
 	fmt.Println()
 	if bestLatency != nil && bestCost != nil {
-		if bestLatency.Model == bestCost.Model {
-			fmt.Printf("  %s %s (best latency & cost)\n", yellow("★"), bestLatency.Model)
+		if bestLatency.Label() == bestCost.Label() {
+			fmt.Printf("  %s %s (best latency & cost)\n", yellow("★"), bestLatency.Label())
 		} else {
 			fmt.Printf("  %s %s for speed, %s for cost\n",
-				yellow("★"), bestLatency.Model, bestCost.Model)
+				yellow("★"), bestLatency.Label(), bestCost.Label())
 		}
 	}
 }
+
+// diffModelsOutput is the --json output of benchmark diff-models
+type diffModelsOutput struct {
+	Benchmark string                `json:"benchmark"`
+	Prompt    string                `json:"prompt"`
+	Version   string                `json:"version"`
+	RunAt     string                `json:"run_at"`
+	Weights   benchmark.RankWeights `json:"weights"`
+	Models    []benchmark.ModelRank `json:"models"`
+}
+
+func runBenchmarkDiffModels(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	database, err := db.Open(projectRoot)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	run, err := latestBenchmarkRun(database, name)
+	if err != nil {
+		return err
+	}
+	if run == nil {
+		return fmt.Errorf("no benchmark runs found for '%s'", name)
+	}
+
+	var result benchmark.BenchmarkResult
+	if err := json.Unmarshal([]byte(run.Results), &result); err != nil {
+		return fmt.Errorf("failed to parse benchmark run: %w", err)
+	}
+
+	weights := benchmark.RankWeights{
+		Cost:    rankWeightCost,
+		Latency: rankWeightLatency,
+		Errors:  rankWeightErrors,
+	}
+	ranks, err := benchmark.RankModels(&result, weights)
+	if err != nil {
+		return err
+	}
+	if len(ranks) == 0 {
+		return fmt.Errorf("every model failed in the latest run of '%s'", run.BenchmarkID)
+	}
+
+	if jsonOut {
+		data, _ := json.MarshalIndent(diffModelsOutput{
+			Benchmark: run.BenchmarkID,
+			Prompt:    result.PromptName,
+			Version:   result.Version,
+			RunAt:     run.CreatedAt.Format(time.RFC3339),
+			Weights:   weights,
+			Models:    ranks,
+		}, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	cyan := color.New(color.FgCyan).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+
+	fmt.Printf("\n%s %s@%s %s\n", cyan("▶"), result.PromptName, result.Version,
+		dim(fmt.Sprintf("(%s, %s)", run.BenchmarkID, run.CreatedAt.Format("2006-01-02 15:04"))))
+	fmt.Printf("  %s\n", dim(fmt.Sprintf("Weights: cost %g, latency %g, errors %g",
+		weights.Cost, weights.Latency, weights.Errors)))
+
+	fmt.Println()
+	fmt.Printf("  %-4s %-20s %8s %10s %12s %8s\n", "#", "Model", "Score", "Latency", "Cost/Req", "Errors")
+	fmt.Printf("  %s\n", dim(strings.Repeat("─", 67)))
+	for _, r := range ranks {
+		fmt.Printf("  %-4d %-20s %8.1f %10s %12s %7.0f%%\n",
+			r.Rank, r.Model, r.Score,
+			fmt.Sprintf("%.0fms", r.LatencyP50Ms),
+			fmt.Sprintf("$%.4f", r.CostPerRequest),
+			r.ErrorRate*100)
+	}
+	fmt.Printf("  %s\n", dim(strings.Repeat("─", 67)))
+
+	if skipped := len(result.Models) - len(ranks); skipped > 0 {
+		fmt.Printf("  %s\n", dim(fmt.Sprintf("%d model(s) with no successful runs not ranked", skipped)))
+	}
+
+	fmt.Printf("\n  %s %s\n", yellow("★"), ranks[0].Model)
+	return nil
+}
+
+// latestBenchmarkRun finds the newest run of the benchmark suite called
+// name, falling back to the newest run for the prompt called name
+func latestBenchmarkRun(database *db.DB, name string) (*db.BenchmarkRun, error) {
+	runs, err := database.ListBenchmarkRuns(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) > 0 {
+		return runs[0], nil
+	}
+
+	p, err := resolvePrompt(database, name)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, nil
+	}
+	return database.GetLatestBenchmarkRunForPrompt(p.ID)
+}
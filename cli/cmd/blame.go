@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/promptsmith/cli/internal/db"
+	"github.com/promptsmith/cli/internal/diff"
+	"github.com/spf13/cobra"
+)
+
+var blameCmd = &cobra.Command{
+	Use:   "blame <prompt>",
+	Short: "Show which version last changed each line",
+	Long: `Annotate each line of the latest version of a prompt with the version,
+author and commit message that introduced or last modified it.
+
+History is followed through each version's parent, so only the lineage of
+the latest version is considered.
+
+Examples:
+  promptsmith blame summarizer
+  promptsmith blame summarizer --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBlame,
+}
+
+func init() {
+	rootCmd.AddCommand(blameCmd)
+}
+
+type blameLine struct {
+	Line          int    `json:"line"`
+	Version       string `json:"version"`
+	Author        string `json:"author"`
+	CommitMessage string `json:"commit_message"`
+	Content       string `json:"content"`
+}
+
+func runBlame(cmd *cobra.Command, args []string) error {
+	promptName := args[0]
+
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	database, err := db.Open(projectRoot)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	p, err := resolvePrompt(database, promptName)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return fmt.Errorf("prompt '%s' not found", promptName)
+	}
+
+	latest, err := database.GetLatestVersion(p.ID)
+	if err != nil {
+		return err
+	}
+	if latest == nil {
+		return fmt.Errorf("no versions found for prompt '%s'", promptName)
+	}
+
+	ancestry, err := database.GetVersionAncestry(latest.ID)
+	if err != nil {
+		return err
+	}
+
+	// Ancestry runs newest first; attribution is built up from the root
+	chain := make([]*db.PromptVersion, len(ancestry))
+	for i, v := range ancestry {
+		chain[len(ancestry)-1-i] = v
+	}
+	lines := blameVersions(chain)
+
+	if jsonOut {
+		data, _ := json.MarshalIndent(lines, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	yellow := color.New(color.FgYellow).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+
+	versionWidth, authorWidth := 0, 0
+	for _, l := range lines {
+		versionWidth = max(versionWidth, len(l.Version))
+		authorWidth = max(authorWidth, len(l.Author))
+	}
+	lineWidth := len(fmt.Sprint(len(lines)))
+
+	for _, l := range lines {
+		fmt.Printf("%s %s %s %s\n",
+			yellow(fmt.Sprintf("%-*s", versionWidth, l.Version)),
+			dim(fmt.Sprintf("(%-*s", authorWidth, l.Author)),
+			dim(fmt.Sprintf("%*d)", lineWidth, l.Line)),
+			l.Content,
+		)
+	}
+
+	return nil
+}
+
+// blameVersions attributes each line of the last version in chain to the
+// version that introduced it. chain must run from the root version forward,
+// each entry a child of the one before it. Every line of the root version is
+// attributed to the root.
+func blameVersions(chain []*db.PromptVersion) []blameLine {
+	if len(chain) == 0 {
+		return nil
+	}
+
+	attribute := func(v *db.PromptVersion) blameLine {
+		return blameLine{Version: v.Version, Author: v.CreatedBy, CommitMessage: v.CommitMessage}
+	}
+
+	root := chain[0]
+	lines := strings.Split(root.Content, "\n")
+	attrs := make([]blameLine, len(lines))
+	for i := range attrs {
+		attrs[i] = attribute(root)
+	}
+
+	for _, v := range chain[1:] {
+		newLines := strings.Split(v.Content, "\n")
+		newAttrs := make([]blameLine, 0, len(newLines))
+		oldIdx := 0
+
+		for _, h := range diff.Compute(lines, newLines, diffContextLines) {
+			// Lines between hunks are unchanged
+			for oldIdx < h.OldStart-1 {
+				newAttrs = append(newAttrs, attrs[oldIdx])
+				oldIdx++
+			}
+			for _, line := range h.Lines {
+				switch line[0] {
+				case ' ':
+					newAttrs = append(newAttrs, attrs[oldIdx])
+					oldIdx++
+				case '-':
+					oldIdx++
+				case '+':
+					newAttrs = append(newAttrs, attribute(v))
+				}
+			}
+		}
+		for oldIdx < len(attrs) {
+			newAttrs = append(newAttrs, attrs[oldIdx])
+			oldIdx++
+		}
+
+		lines, attrs = newLines, newAttrs
+	}
+
+	for i := range attrs {
+		attrs[i].Line = i + 1
+		attrs[i].Content = lines[i]
+	}
+	return attrs
+}
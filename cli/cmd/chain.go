@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/promptsmith/cli/internal/benchmark"
+	"github.com/promptsmith/cli/internal/condition"
 	"github.com/promptsmith/cli/internal/db"
 	"github.com/spf13/cobra"
 )
@@ -215,7 +217,18 @@ func runChainShow(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  %s (%d)\n", cyan("Steps"), len(steps))
 		fmt.Printf("  %s\n", dim(strings.Repeat("─", 40)))
 		for _, s := range steps {
-			fmt.Printf("  %d. %s → %s\n", s.StepOrder, cyan(s.PromptName), s.OutputKey)
+			name := s.PromptName
+			if s.Version != "" {
+				name += "@" + s.Version
+			}
+			line := fmt.Sprintf("  %d. %s → %s", s.StepOrder, cyan(name), s.OutputKey)
+			if deps := s.DeclaredDependencies(); len(deps) > 0 {
+				line += dim(" (after " + strings.Join(deps, ", ") + ")")
+			}
+			if s.Condition != "" {
+				line += dim(" if " + s.Condition)
+			}
+			fmt.Println(line)
 		}
 	}
 	fmt.Println()
@@ -252,6 +265,14 @@ func runChainRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("chain '%s' has no steps — add steps first", name)
 	}
 
+	// The CLI runs steps one at a time, in an order that respects their
+	// dependencies
+	waves, err := db.PlanChainSteps(steps)
+	if err != nil {
+		return fmt.Errorf("chain '%s': %w", name, err)
+	}
+	steps = slices.Concat(waves...)
+
 	// Parse inputs
 	inputs := make(map[string]string)
 	for _, kv := range chainInputs {
@@ -270,8 +291,19 @@ func runChainRun(cmd *cobra.Command, args []string) error {
 	if anthropic, err := benchmark.NewAnthropicProvider(); err == nil {
 		registry.Register(anthropic)
 	}
+	if gemini, err := benchmark.NewGeminiProvider(); err == nil {
+		registry.Register(gemini)
+	}
+	if ollama, err := benchmark.NewOllamaProvider(); err == nil {
+		registry.Register(ollama)
+	}
+
+	if err := applyModelAliases(projectRoot, registry); err != nil {
+		return err
+	}
+	model := registry.ResolveModel(chainModel)
 
-	provider, err := registry.GetForModel(chainModel)
+	provider, err := registry.GetForModel(model)
 	if err != nil {
 		return fmt.Errorf("model error: %w", err)
 	}
@@ -282,19 +314,39 @@ func runChainRun(cmd *cobra.Command, args []string) error {
 
 	if !jsonOut {
 		fmt.Printf("\n%s Running chain '%s' with %d steps\n", cyan("▶"), chain.Name, len(steps))
-		fmt.Printf("  Model: %s\n\n", chainModel)
+		fmt.Printf("  Model: %s\n\n", model)
 	}
 
 	stepOutputs := make(map[string]string)
 	type stepResult struct {
-		Step   int    `json:"step"`
-		Prompt string `json:"prompt"`
-		Output string `json:"output"`
-		Key    string `json:"output_key"`
+		Step         int     `json:"step"`
+		Prompt       string  `json:"prompt"`
+		Version      string  `json:"version"`
+		Output       string  `json:"output"`
+		Key          string  `json:"output_key"`
+		PromptTokens int     `json:"prompt_tokens"`
+		OutputTokens int     `json:"output_tokens"`
+		Cost         float64 `json:"cost"`
+		Skipped      bool    `json:"skipped,omitempty"`
 	}
 	var results []stepResult
+	var finalOutput string
 
 	for _, step := range steps {
+		if step.Condition != "" {
+			expr, err := condition.Parse(step.Condition)
+			if err != nil {
+				return fmt.Errorf("step %d: %w", step.StepOrder, err)
+			}
+			if !expr.Eval(inputs, stepOutputs) {
+				results = append(results, stepResult{Step: step.StepOrder, Prompt: step.PromptName, Key: step.OutputKey, Skipped: true})
+				if !jsonOut {
+					fmt.Printf("  %s Step %d: %s %s\n\n", dim("→"), step.StepOrder, cyan(step.PromptName), dim("skipped: "+step.Condition+" is false"))
+				}
+				continue
+			}
+		}
+
 		// Resolve inputs
 		var inputMap map[string]string
 		if err := json.Unmarshal([]byte(step.InputMapping), &inputMap); err != nil {
@@ -307,13 +359,19 @@ func runChainRun(cmd *cobra.Command, args []string) error {
 		}
 
 		// Load prompt
-		prompt, err := database.GetPromptByName(step.PromptName)
+		prompt, err := resolvePrompt(database, step.PromptName)
 		if err != nil || prompt == nil {
 			return fmt.Errorf("step %d: prompt '%s' not found", step.StepOrder, step.PromptName)
 		}
 
-		version, err := database.GetLatestVersion(prompt.ID)
-		if err != nil || version == nil {
+		version, err := chainStepVersion(database, prompt.ID, step.Version)
+		if err != nil {
+			return err
+		}
+		if version == nil {
+			if step.Version != "" {
+				return fmt.Errorf("step %d: version '%s' of prompt '%s' not found", step.StepOrder, step.Version, step.PromptName)
+			}
 			return fmt.Errorf("step %d: no version for prompt '%s'", step.StepOrder, step.PromptName)
 		}
 
@@ -328,7 +386,7 @@ func runChainRun(cmd *cobra.Command, args []string) error {
 		}
 
 		resp, err := provider.Complete(context.Background(), benchmark.CompletionRequest{
-			Model:       chainModel,
+			Model:       model,
 			Prompt:      rendered,
 			MaxTokens:   1024,
 			Temperature: 1.0,
@@ -338,11 +396,16 @@ func runChainRun(cmd *cobra.Command, args []string) error {
 		}
 
 		stepOutputs[step.OutputKey] = resp.Content
+		finalOutput = resp.Content
 		results = append(results, stepResult{
-			Step:   step.StepOrder,
-			Prompt: step.PromptName,
-			Output: resp.Content,
-			Key:    step.OutputKey,
+			Step:         step.StepOrder,
+			Prompt:       step.PromptName,
+			Version:      version.Version,
+			Output:       resp.Content,
+			Key:          step.OutputKey,
+			PromptTokens: resp.PromptTokens,
+			OutputTokens: resp.OutputTokens,
+			Cost:         resp.Cost,
 		})
 
 		if !jsonOut {
@@ -358,8 +421,13 @@ func runChainRun(cmd *cobra.Command, args []string) error {
 	// Save run
 	inputsJSON, _ := json.Marshal(inputs)
 	resultsJSON, _ := json.Marshal(results)
-	finalOutput := stepOutputs[steps[len(steps)-1].OutputKey]
-	database.SaveChainRun(chain.ID, "completed", string(inputsJSON), string(resultsJSON), finalOutput)
+	var totalTokens int
+	var totalCost float64
+	for _, r := range results {
+		totalTokens += r.PromptTokens + r.OutputTokens
+		totalCost += r.Cost
+	}
+	database.SaveChainRun(chain.ID, "completed", string(inputsJSON), string(resultsJSON), finalOutput, totalTokens, totalCost)
 
 	if jsonOut {
 		out := map[string]interface{}{
@@ -391,3 +459,20 @@ func resolveInput(source string, inputs map[string]string, stepOutputs map[strin
 	}
 	return source
 }
+
+// chainStepVersion loads the version a chain step runs: the version or tag it
+// is pinned to, or the latest version if it is not pinned
+func chainStepVersion(database *db.DB, promptID, pinned string) (*db.PromptVersion, error) {
+	if pinned == "" {
+		return database.GetLatestVersion(promptID)
+	}
+	v, err := database.GetVersionByString(promptID, pinned)
+	if err != nil || v != nil {
+		return v, err
+	}
+	tag, err := database.GetTagByName(promptID, pinned)
+	if err != nil || tag == nil {
+		return nil, err
+	}
+	return database.GetVersionByID(tag.VersionID)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/promptsmith/cli/internal/db"
+	"github.com/promptsmith/cli/internal/logging"
 	"github.com/spf13/cobra"
 )
 
@@ -20,15 +21,23 @@ var checkoutCmd = &cobra.Command{
 This updates the working file to match the specified version.
 You can reference versions by version number, tag name, or HEAD notation.
 
+Checkout refuses to overwrite a working file with uncommitted changes.
+Commit them first, or pass --force to discard them.
+
 Examples:
   promptsmith checkout summarizer 1.0.0      # Checkout version 1.0.0
   promptsmith checkout summarizer prod       # Checkout tagged version
-  promptsmith checkout summarizer HEAD~2     # Checkout 2 versions back`,
+  promptsmith checkout summarizer HEAD~2     # Checkout 2 versions back
+  promptsmith checkout summarizer 1.0.0 --force  # Discard uncommitted changes`,
 	Args: cobra.ExactArgs(2),
 	RunE: runCheckout,
 }
 
+var checkoutForce bool
+
 func init() {
+	checkoutCmd.Flags().BoolVar(&checkoutForce, "force", false, "overwrite the working file even if it has uncommitted changes")
+	checkoutCmd.ValidArgsFunction = completePromptArgs(listRefCompletions)
 	rootCmd.AddCommand(checkoutCmd)
 }
 
@@ -47,7 +56,7 @@ func runCheckout(cmd *cobra.Command, args []string) error {
 	}
 	defer database.Close()
 
-	p, err := database.GetPromptByName(promptName)
+	p, err := resolvePrompt(database, promptName)
 	if err != nil {
 		return err
 	}
@@ -82,12 +91,8 @@ func runCheckout(cmd *cobra.Command, args []string) error {
 	}
 
 	latest := versions[0]
-	if err == nil && string(currentContent) != latest.Content {
-		yellow := color.New(color.FgYellow).SprintFunc()
-		fmt.Printf("%s Warning: You have uncommitted changes in %s\n", yellow("!"), p.FilePath)
-		fmt.Println("  Use 'promptsmith commit' to save changes before checkout,")
-		fmt.Println("  or use '--force' to discard changes (not implemented yet).")
-		return fmt.Errorf("uncommitted changes would be overwritten")
+	if err == nil && !checkoutForce && hashContent(string(currentContent)) != hashContent(latest.Content) {
+		return fmt.Errorf("uncommitted changes to '%s' (%s) would be overwritten; commit them first or use --force to discard them", p.Name, p.FilePath)
 	}
 
 	// Write the version content to file
@@ -95,6 +100,7 @@ func runCheckout(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
+	logging.Debug("wrote prompt file", "path", absPath, "version", targetVersion.Version)
 
 	green := color.New(color.FgGreen).SprintFunc()
 	cyan := color.New(color.FgCyan).SprintFunc()
@@ -2,14 +2,33 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
+	gosync "sync"
 	"testing"
+	"time"
 
+	"github.com/fsnotify/fsnotify"
+	"github.com/promptsmith/cli/internal/benchmark"
 	"github.com/promptsmith/cli/internal/db"
+	"github.com/promptsmith/cli/internal/diff"
+	"github.com/promptsmith/cli/internal/logging"
+	"github.com/promptsmith/cli/internal/sync"
+	pstesting "github.com/promptsmith/cli/internal/testing"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 // Test helper to set up a test project
@@ -165,77 +184,66 @@ func TestResolveVersion(t *testing.T) {
 	}
 }
 
-func TestComputeDiff(t *testing.T) {
+func TestComputeWordDiff(t *testing.T) {
 	tests := []struct {
-		name     string
-		lines1   []string
-		lines2   []string
-		hasHunks bool
+		name    string
+		oldLine string
+		newLine string
+		wantOld []wordSpan
+		wantNew []wordSpan
 	}{
 		{
-			name:     "identical",
-			lines1:   []string{"line 1", "line 2", "line 3"},
-			lines2:   []string{"line 1", "line 2", "line 3"},
-			hasHunks: false,
+			name:    "single word changed",
+			oldLine: "You are a helpful assistant that writes concise summaries.",
+			newLine: "You are a helpful assistant that writes detailed summaries.",
+			wantOld: []wordSpan{
+				{' ', "You are a helpful assistant that writes "},
+				{'-', "concise"},
+				{' ', " summaries."},
+			},
+			wantNew: []wordSpan{
+				{' ', "You are a helpful assistant that writes "},
+				{'+', "detailed"},
+				{' ', " summaries."},
+			},
 		},
 		{
-			name:     "added line",
-			lines1:   []string{"line 1", "line 2"},
-			lines2:   []string{"line 1", "line 2", "line 3"},
-			hasHunks: true,
+			name:    "multibyte words",
+			oldLine: "Résumé du café naïve",
+			newLine: "Résumé du thé naïve",
+			wantOld: []wordSpan{{' ', "Résumé du "}, {'-', "café"}, {' ', " naïve"}},
+			wantNew: []wordSpan{{' ', "Résumé du "}, {'+', "thé"}, {' ', " naïve"}},
 		},
 		{
-			name:     "removed line",
-			lines1:   []string{"line 1", "line 2", "line 3"},
-			lines2:   []string{"line 1", "line 3"},
-			hasHunks: true,
-		},
-		{
-			name:     "changed line",
-			lines1:   []string{"line 1", "OLD", "line 3"},
-			lines2:   []string{"line 1", "NEW", "line 3"},
-			hasHunks: true,
-		},
-		{
-			name:     "empty to content",
-			lines1:   []string{},
-			lines2:   []string{"new content"},
-			hasHunks: true,
+			name:    "runes in unspaced scripts",
+			oldLine: "日本語のテスト",
+			newLine: "日本語の試験",
+			wantOld: []wordSpan{{' ', "日本語の"}, {'-', "テスト"}},
+			wantNew: []wordSpan{{' ', "日本語の"}, {'+', "試験"}},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			hunks := computeDiff(tt.lines1, tt.lines2)
-
-			if tt.hasHunks && len(hunks) == 0 {
-				t.Error("expected hunks but got none")
+			gotOld, gotNew := computeWordDiff(tt.oldLine, tt.newLine)
+			if !reflect.DeepEqual(gotOld, tt.wantOld) {
+				t.Errorf("old spans = %q, want %q", gotOld, tt.wantOld)
 			}
-			if !tt.hasHunks && len(hunks) > 0 {
-				t.Errorf("expected no hunks but got %d", len(hunks))
+			if !reflect.DeepEqual(gotNew, tt.wantNew) {
+				t.Errorf("new spans = %q, want %q", gotNew, tt.wantNew)
 			}
 		})
 	}
 }
 
-func TestComputeDiffContent(t *testing.T) {
-	lines1 := []string{"line 1", "line 2", "line 3"}
-	lines2 := []string{"line 1", "modified line 2", "line 3"}
-
-	hunks := computeDiff(lines1, lines2)
-
-	if len(hunks) == 0 {
-		t.Fatal("expected at least one hunk")
-	}
-
-	// Check that the hunk contains the expected changes
-	hunkContent := strings.Join(hunks[0].Lines, "\n")
+func TestIsSingleLineChange(t *testing.T) {
+	lines := []string{" ctx", "-old", "+new", " ctx", "-a", "-b", "+c"}
 
-	if !strings.Contains(hunkContent, "-line 2") {
-		t.Error("expected hunk to contain removed line")
+	if !isSingleLineChange(lines, 1) {
+		t.Error("expected lone -/+ pair to be a single line change")
 	}
-	if !strings.Contains(hunkContent, "+modified line 2") {
-		t.Error("expected hunk to contain added line")
+	if isSingleLineChange(lines, 4) || isSingleLineChange(lines, 5) {
+		t.Error("expected multi-line removal not to be a single line change")
 	}
 }
 
@@ -663,6 +671,59 @@ func TestStatusDetection(t *testing.T) {
 	database.Close()
 }
 
+func TestStatusCommandCategorizesPrompts(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() {
+		jsonOut = false
+		statusPorcelain = false
+	}()
+
+	addTestPrompt(t, tmpDir, "clean", "Summarize: {{.text}}")
+	addTestPrompt(t, tmpDir, "edited", "Translate: {{.text}}")
+	commitMessage = "Initial"
+	runCommit(&cobra.Command{}, []string{})
+
+	os.WriteFile(filepath.Join(tmpDir, "prompts", "edited.prompt"), []byte("Translate to French: {{.text}}"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "prompts", "draft.prompt"), []byte("Draft"), 0644)
+
+	jsonOut = true
+	out := captureStdout(t, func() {
+		if err := runStatus(&cobra.Command{}, []string{}); err != nil {
+			t.Fatalf("runStatus failed: %v", err)
+		}
+	})
+	jsonOut = false
+	var status struct {
+		Prompts   []promptStatus `json:"prompts"`
+		Untracked []string       `json:"untracked"`
+	}
+	if err := json.Unmarshal([]byte(out), &status); err != nil {
+		t.Fatalf("expected JSON status: %v\n%s", err, out)
+	}
+	got := map[string]string{}
+	for _, ps := range status.Prompts {
+		got[ps.Name] = ps.Status
+	}
+	if got["clean"] != "clean" || got["edited"] != "modified" {
+		t.Errorf("unexpected statuses %v", got)
+	}
+	if len(status.Untracked) != 1 || status.Untracked[0] != filepath.Join("prompts", "draft.prompt") {
+		t.Errorf("expected draft.prompt untracked, got %v", status.Untracked)
+	}
+
+	statusPorcelain = true
+	out = captureStdout(t, func() {
+		if err := runStatus(&cobra.Command{}, []string{}); err != nil {
+			t.Fatalf("runStatus --porcelain failed: %v", err)
+		}
+	})
+	want := "M prompts/edited.prompt\n?? prompts/draft.prompt\n"
+	if out != want {
+		t.Errorf("porcelain output = %q, want %q", out, want)
+	}
+}
+
 // ============================================================================
 // List Command Tests
 // ============================================================================
@@ -994,6 +1055,42 @@ func TestConfigDeeplyNestedKey(t *testing.T) {
 	}
 }
 
+func TestValidateConfigMissingDirectory(t *testing.T) {
+	tmpDir, cleanup := setupTestProjectWithConfig(t)
+	defer cleanup()
+
+	for _, dir := range []string{"tests", "benchmarks"} {
+		os.MkdirAll(filepath.Join(tmpDir, dir), 0755)
+	}
+
+	config, err := loadConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+
+	if problems := validateConfig(tmpDir, config); len(problems) != 0 {
+		t.Fatalf("expected valid config, got problems: %v", problems)
+	}
+
+	config.PromptsDir = "./missing"
+	config.Defaults.Temperature = 3
+	config.Defaults.Model = "not-a-model"
+
+	problems := validateConfig(tmpDir, config)
+	if len(problems) != 3 {
+		t.Fatalf("expected 3 problems, got %d: %v", len(problems), problems)
+	}
+	if !strings.Contains(problems[0], "prompts_dir") || !strings.Contains(problems[0], "does not exist") {
+		t.Errorf("expected missing prompts_dir problem, got %q", problems[0])
+	}
+	if !strings.Contains(problems[1], "defaults.temperature") {
+		t.Errorf("expected temperature problem, got %q", problems[1])
+	}
+	if !strings.Contains(problems[2], "defaults.model") {
+		t.Errorf("expected model problem, got %q", problems[2])
+	}
+}
+
 // ============================================================================
 // Init Command Integration Tests
 // ============================================================================
@@ -1008,6 +1105,29 @@ func executeCommand(root *cobra.Command, args ...string) (string, error) {
 	return buf.String(), err
 }
 
+// captureStdout runs fn and returns everything it printed to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	original := os.Stdout
+	os.Stdout = w
+
+	done := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		buf.ReadFrom(r)
+		done <- buf.String()
+	}()
+
+	defer func() { os.Stdout = original }()
+	fn()
+	w.Close()
+	return <-done
+}
+
 func TestInitCommand(t *testing.T) {
 	// Create temp directory
 	tmpDir, err := os.MkdirTemp("", "promptsmith-init-test-*")
@@ -1061,6 +1181,62 @@ func TestInitCommand(t *testing.T) {
 	}
 }
 
+func TestInitCommandDBPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(t.TempDir(), "local", "project.db")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	initDBPath = dbPath
+	defer func() { initDBPath = "" }()
+	if err := runInit(&cobra.Command{}, []string{"test-project"}); err != nil {
+		t.Fatalf("runInit failed: %v", err)
+	}
+	initDBPath = ""
+
+	if _, err := os.Stat(dbPath); err != nil {
+		t.Fatalf("expected database at %s: %v", dbPath, err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, db.ConfigDir, db.DBFile)); !os.IsNotExist(err) {
+		t.Error("expected no database in .promptsmith")
+	}
+	config, err := loadConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+	if config.DBPath != dbPath {
+		t.Errorf("expected db_path %s in config, got %q", dbPath, config.DBPath)
+	}
+
+	// Later commands find the database through the config
+	addTestPrompt(t, tmpDir, "greeting", "---\nname: greeting\n---\nHello!\n")
+	commitMessage = "Initial commit"
+	commitAll = true
+	defer func() { commitAll = false }()
+	if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runCommit failed: %v", err)
+	}
+	database, err := db.Open(tmpDir)
+	if err != nil {
+		t.Fatalf("db.Open failed: %v", err)
+	}
+	defer database.Close()
+	p, err := database.GetPromptByName("greeting")
+	if err != nil || p == nil {
+		t.Fatalf("expected the prompt in the custom database, got %v, %v", p, err)
+	}
+
+	// A second project cannot take over the same database
+	otherDir := t.TempDir()
+	os.Chdir(otherDir)
+	initDBPath = dbPath
+	if err := runInit(&cobra.Command{}, []string{"other"}); err == nil {
+		t.Error("expected init to refuse an existing database")
+	}
+}
+
 func TestInitCommandDefaultName(t *testing.T) {
 	// Create temp directory with specific name
 	tmpDir, err := os.MkdirTemp("", "my-awesome-project-*")
@@ -1122,6 +1298,91 @@ func TestInitCommandAlreadyInitialized(t *testing.T) {
 	}
 }
 
+func TestInitCommandGitHook(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	// A bare .git directory is enough for the hook to be installed
+	if err := os.Mkdir(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git: %v", err)
+	}
+
+	initGitHook = true
+	defer func() { initGitHook = false; initForce = false }()
+
+	if err := runInit(&cobra.Command{}, []string{"hooked"}); err != nil {
+		t.Fatalf("runInit failed: %v", err)
+	}
+
+	hookPath := filepath.Join(tmpDir, ".git", "hooks", "pre-commit")
+	info, err := os.Stat(hookPath)
+	if err != nil {
+		t.Fatalf("expected pre-commit hook to be created: %v", err)
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		t.Errorf("expected hook to be executable, got mode %v", info.Mode())
+	}
+	content, _ := os.ReadFile(hookPath)
+	if !strings.Contains(string(content), "promptsmith verify") {
+		t.Errorf("expected hook to run promptsmith verify, got:\n%s", content)
+	}
+}
+
+func TestInitCommandGitHookKeepsExistingHook(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	hooksDir := filepath.Join(tmpDir, ".git", "hooks")
+	os.MkdirAll(hooksDir, 0755)
+	hookPath := filepath.Join(hooksDir, "pre-commit")
+	os.WriteFile(hookPath, []byte("#!/bin/sh\nmake lint\n"), 0644)
+
+	initGitHook = true
+	defer func() { initGitHook = false; initForce = false }()
+
+	err := runInit(&cobra.Command{}, []string{"hooked"})
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected existing hook error, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, ".promptsmith")); !os.IsNotExist(err) {
+		t.Error("expected init to stop before creating the project")
+	}
+
+	initForce = true
+	if err := runInit(&cobra.Command{}, []string{"hooked"}); err != nil {
+		t.Fatalf("runInit with --force failed: %v", err)
+	}
+	content, _ := os.ReadFile(hookPath)
+	if !strings.Contains(string(content), "promptsmith verify") {
+		t.Errorf("expected hook to be replaced, got:\n%s", content)
+	}
+	info, _ := os.Stat(hookPath)
+	if info.Mode().Perm()&0111 == 0 {
+		t.Errorf("expected replaced hook to be executable, got mode %v", info.Mode())
+	}
+}
+
+func TestInitCommandGitHookExistingProject(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	os.Mkdir(filepath.Join(tmpDir, ".git"), 0755)
+
+	initGitHook = true
+	defer func() { initGitHook = false }()
+
+	if err := runInit(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runInit --git-hook on existing project failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, ".git", "hooks", "pre-commit")); err != nil {
+		t.Errorf("expected pre-commit hook to be created: %v", err)
+	}
+}
+
 // ============================================================================
 // Add Command Integration Tests
 // ============================================================================
@@ -1145,7 +1406,11 @@ func initTestProject(t *testing.T) (string, func()) {
 		t.Fatalf("failed to initialize project: %v", err)
 	}
 
+	// Most tests commit every changed prompt; staging has its own tests
+	commitAll = true
+
 	cleanup := func() {
+		commitAll = false
 		os.Chdir(originalWd)
 		os.RemoveAll(tmpDir)
 	}
@@ -1304,6 +1569,111 @@ Hello!
 	}
 }
 
+func TestAddCommandStrict(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	addStrict = true
+	defer func() { addStrict = false }()
+
+	write := func(name, content string) string {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(tmpDir, "prompts", name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write prompt file: %v", err)
+		}
+		return "prompts/" + name
+	}
+
+	valid := write("valid.prompt", "---\nname: valid\ndescription: Summarizes text\nmodel_hint: gpt-4o\n---\nSummarize: {{.text}}\n")
+	captureStdout(t, func() {
+		if err := runAdd(&cobra.Command{}, []string{valid}); err != nil {
+			t.Fatalf("expected strict add of a complete prompt to succeed: %v", err)
+		}
+	})
+
+	noName := write("noname.prompt", "---\ndescription: Summarizes text\n---\nSummarize: {{.text}}\n")
+	err := runAdd(&cobra.Command{}, []string{noName})
+	if err == nil || !strings.Contains(err.Error(), "missing a name") {
+		t.Errorf("expected a missing name error, got %v", err)
+	}
+
+	unknownModel := write("unknown.prompt", "---\nname: unknown\ndescription: Summarizes text\nmodel_hint: gpt-9000\n---\nSummarize: {{.text}}\n")
+	err = runAdd(&cobra.Command{}, []string{unknownModel})
+	if err == nil || !strings.Contains(err.Error(), "model_hint gpt-9000 is not a known model") {
+		t.Errorf("expected an unknown model_hint error, got %v", err)
+	}
+
+	database, _ := db.Open(tmpDir)
+	defer database.Close()
+	for _, name := range []string{"noname", "unknown"} {
+		if p, _ := database.GetPromptByName(name); p != nil {
+			t.Errorf("expected rejected prompt %s not to be tracked", name)
+		}
+	}
+}
+
+func TestAddCommandDirectory(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	os.MkdirAll(filepath.Join(tmpDir, "prompts", "support"), 0755)
+	for _, name := range []string{"summarizer", "translator", filepath.Join("support", "triage")} {
+		path := filepath.Join(tmpDir, "prompts", name+".prompt")
+		if err := os.WriteFile(path, []byte("Handle: {{.text}}"), 0644); err != nil {
+			t.Fatalf("failed to write prompt file: %v", err)
+		}
+	}
+	captureStdout(t, func() {
+		if err := runAdd(&cobra.Command{}, []string{"prompts/summarizer.prompt"}); err != nil {
+			t.Fatalf("first add failed: %v", err)
+		}
+	})
+
+	out := captureStdout(t, func() {
+		if err := runAdd(&cobra.Command{}, []string{"prompts/"}); err != nil {
+			t.Fatalf("expected adding a directory to skip tracked prompts, got: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Added 2 prompt(s), skipped 1 already tracked") {
+		t.Errorf("expected a summary of 2 added and 1 skipped, got:\n%s", out)
+	}
+
+	database, _ := db.Open(tmpDir)
+	defer database.Close()
+	prompts, _ := database.ListPrompts()
+	if len(prompts) != 3 {
+		t.Errorf("expected 3 tracked prompts, got %d", len(prompts))
+	}
+	if p, _ := database.GetPromptByName("triage"); p == nil || p.FilePath != filepath.Join("prompts", "support", "triage.prompt") {
+		t.Errorf("expected the nested prompt to be tracked, got %+v", p)
+	}
+}
+
+func TestAddCommandGlob(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	for _, name := range []string{"a.prompt", "b.prompt", "notes.txt"} {
+		os.WriteFile(filepath.Join(tmpDir, "prompts", name), []byte("Hello"), 0644)
+	}
+
+	captureStdout(t, func() {
+		if err := runAdd(&cobra.Command{}, []string{"prompts/*.prompt"}); err != nil {
+			t.Fatalf("runAdd with a glob failed: %v", err)
+		}
+	})
+
+	database, _ := db.Open(tmpDir)
+	defer database.Close()
+	prompts, _ := database.ListPrompts()
+	if len(prompts) != 2 {
+		t.Errorf("expected 2 tracked prompts, got %d", len(prompts))
+	}
+
+	if err := runAdd(&cobra.Command{}, []string{"prompts/*.missing"}); err == nil {
+		t.Error("expected an error for a glob matching nothing")
+	}
+}
+
 func TestAddCommandNameCollision(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
@@ -1365,701 +1735,1038 @@ func addTestPrompt(t *testing.T, tmpDir, name, content string) {
 	}
 }
 
-func TestCommitCommand(t *testing.T) {
+func TestSearchCommand(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
+	defer func() { jsonOut = false }()
 
-	// Add a prompt
-	addTestPrompt(t, tmpDir, "greeting", `---
-name: greeting
-description: A greeting prompt
----
-Hello {{name}}!
-`)
-
-	// Set commit message
-	commitMessage = "Initial commit"
-
-	// Run commit
-	err := runCommit(&cobra.Command{}, []string{})
-	if err != nil {
-		t.Fatalf("runCommit failed: %v", err)
-	}
+	addTestPrompt(t, tmpDir, "summarizer", "Summarize the text.\nFollow the house style guide.")
+	addTestPrompt(t, tmpDir, "greeter", "Say hello to {{.name}}.")
+	commitMessage = "Initial"
+	runCommit(&cobra.Command{}, []string{})
 
-	// Verify version was created
-	database, err := db.Open(tmpDir)
-	if err != nil {
-		t.Fatalf("failed to open db: %v", err)
+	output := captureStdout(t, func() {
+		if err := runSearch(&cobra.Command{}, []string{"house", "style"}); err != nil {
+			t.Fatalf("runSearch failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "summarizer@1.0.0") || strings.Contains(output, "greeter") {
+		t.Errorf("expected only summarizer to match, got:\n%s", output)
 	}
-	defer database.Close()
-
-	prompt, _ := database.GetPromptByName("greeting")
-	versions, err := database.ListVersions(prompt.ID)
-	if err != nil {
-		t.Fatalf("failed to list versions: %v", err)
+	if !strings.Contains(output, "Summarize the text. Follow the house style guide.") {
+		t.Errorf("expected the snippet on one line without markers, got:\n%s", output)
 	}
 
-	if len(versions) != 1 {
-		t.Fatalf("expected 1 version, got %d", len(versions))
+	jsonOut = true
+	output = captureStdout(t, func() {
+		if err := runSearch(&cobra.Command{}, []string{"goodbye"}); err != nil {
+			t.Fatalf("runSearch failed: %v", err)
+		}
+	})
+	if strings.TrimSpace(output) != "[]" {
+		t.Errorf("expected an empty JSON list, got %s", output)
 	}
+}
 
-	if versions[0].Version != "1.0.0" {
-		t.Errorf("expected version 1.0.0, got %s", versions[0].Version)
-	}
+func TestHighlightSnippet(t *testing.T) {
+	brackets := func(a ...interface{}) string { return "[" + fmt.Sprint(a...) + "]" }
 
-	if versions[0].CommitMessage != "Initial commit" {
-		t.Errorf("expected commit message 'Initial commit', got %s", versions[0].CommitMessage)
+	got := highlightSnippet("…ask about\nthe <mark>refund</mark> and <mark>refund</mark> policy", brackets)
+	if want := "…ask about the [refund] and [refund] policy"; got != want {
+		t.Errorf("highlightSnippet() = %q, want %q", got, want)
 	}
 }
 
-func TestCommitCommandNoChanges(t *testing.T) {
+func TestFreezeBlocksCommit(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	// Add a prompt
-	addTestPrompt(t, tmpDir, "nochange", `Hello!`)
+	addTestPrompt(t, tmpDir, "summarizer", "Summarize: {{.text}}")
+	addTestPrompt(t, tmpDir, "greeter", "Hello {{.name}}")
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
 
-	// Commit first time
-	commitMessage = "First commit"
-	err := runCommit(&cobra.Command{}, []string{})
-	if err != nil {
-		t.Fatalf("first commit failed: %v", err)
+	if err := runFreeze(&cobra.Command{}, []string{"summarizer"}); err != nil {
+		t.Fatalf("runFreeze failed: %v", err)
 	}
 
-	// Commit again without changes - should succeed but not create new version
-	commitMessage = "Second commit"
-	err = runCommit(&cobra.Command{}, []string{})
-	if err != nil {
-		t.Fatalf("second commit failed: %v", err)
+	// Unchanged frozen prompts don't get in the way of other commits
+	os.WriteFile(filepath.Join(tmpDir, "prompts", "greeter.prompt"), []byte("Hi {{.name}}"), 0644)
+	commitMessage = "Greeter"
+	if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("expected commit of an unfrozen prompt to succeed: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(tmpDir, "prompts", "summarizer.prompt"), []byte("Summarize briefly: {{.text}}"), 0644)
+	commitMessage = "V2"
+	err := runCommit(&cobra.Command{}, []string{})
+	if err == nil || !strings.Contains(err.Error(), "frozen") {
+		t.Fatalf("expected commit of a frozen prompt to fail, got %v", err)
 	}
 
-	// Verify only one version exists
 	database, err := db.Open(tmpDir)
 	if err != nil {
-		t.Fatalf("failed to open db: %v", err)
+		t.Fatalf("failed to open database: %v", err)
 	}
 	defer database.Close()
+	p, _ := database.GetPromptByName("summarizer")
+	if latest, _ := database.GetLatestVersion(p.ID); latest.Version != "1.0.0" {
+		t.Errorf("expected summarizer to stay at 1.0.0, got %s", latest.Version)
+	}
 
-	prompt, _ := database.GetPromptByName("nochange")
-	versions, _ := database.ListVersions(prompt.ID)
+	if err := runUnfreeze(&cobra.Command{}, []string{"summarizer"}); err != nil {
+		t.Fatalf("runUnfreeze failed: %v", err)
+	}
+	if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("expected commit to succeed once unfrozen: %v", err)
+	}
+	if latest, _ := database.GetLatestVersion(p.ID); latest.Version != "1.0.1" {
+		t.Errorf("expected summarizer at 1.0.1 after unfreezing, got %s", latest.Version)
+	}
 
-	if len(versions) != 1 {
-		t.Errorf("expected 1 version (no changes), got %d", len(versions))
+	if err := runFreeze(&cobra.Command{}, []string{"missing"}); err == nil {
+		t.Error("expected error freezing an unknown prompt")
 	}
 }
 
-func TestCommitCommandVersionBump(t *testing.T) {
+func TestAliasCommand(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
+	defer func() {
+		aliasList = false
+		aliasDelete = false
+	}()
 
-	promptPath := filepath.Join(tmpDir, "prompts", "versioned.prompt")
-
-	// Add and commit first version
-	if err := os.WriteFile(promptPath, []byte("Version 1"), 0644); err != nil {
-		t.Fatalf("failed to write prompt: %v", err)
-	}
-	runAdd(&cobra.Command{}, []string{"prompts/versioned.prompt"})
-	commitMessage = "Version 1"
+	addTestPrompt(t, tmpDir, "digest", "Summarize: {{.text}}")
+	commitMessage = "V1"
 	runCommit(&cobra.Command{}, []string{})
 
-	// Modify and commit second version
-	if err := os.WriteFile(promptPath, []byte("Version 2"), 0644); err != nil {
-		t.Fatalf("failed to write prompt: %v", err)
+	captureStdout(t, func() {
+		if err := runAlias(&cobra.Command{}, []string{"summarizer", "digest"}); err != nil {
+			t.Fatalf("runAlias failed: %v", err)
+		}
+	})
+	if err := runAlias(&cobra.Command{}, []string{"other", "missing"}); err == nil {
+		t.Error("expected an alias of an unknown prompt to fail")
+	}
+
+	// Commands that read a prompt accept the alias, with a hint
+	var hints bytes.Buffer
+	aliasHintOutput = &hints
+	defer func() { aliasHintOutput = os.Stderr }()
+	out := captureStdout(t, func() {
+		if err := runShow(&cobra.Command{}, []string{"summarizer"}); err != nil {
+			t.Fatalf("expected show through an alias to work: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Summarize: {{.text}}") {
+		t.Errorf("expected digest's content through the alias, got:\n%s", out)
+	}
+	if !strings.Contains(hints.String(), "'summarizer' was renamed to 'digest'") {
+		t.Errorf("expected a rename hint, got %q", hints.String())
 	}
-	commitMessage = "Version 2"
-	runCommit(&cobra.Command{}, []string{})
 
-	// Modify and commit third version
-	if err := os.WriteFile(promptPath, []byte("Version 3"), 0644); err != nil {
-		t.Fatalf("failed to write prompt: %v", err)
+	jsonOut = true
+	out = captureStdout(t, func() {
+		if err := runAlias(&cobra.Command{}, []string{}); err != nil {
+			t.Fatalf("runAlias --list failed: %v", err)
+		}
+	})
+	jsonOut = false
+	var aliases []aliasOutput
+	if err := json.Unmarshal([]byte(out), &aliases); err != nil {
+		t.Fatalf("expected JSON aliases: %v\n%s", err, out)
+	}
+	if len(aliases) != 1 || aliases[0].Alias != "summarizer" || aliases[0].Prompt != "digest" {
+		t.Errorf("unexpected aliases %+v", aliases)
+	}
+
+	aliasDelete = true
+	captureStdout(t, func() {
+		if err := runAlias(&cobra.Command{}, []string{"summarizer"}); err != nil {
+			t.Fatalf("runAlias --delete failed: %v", err)
+		}
+	})
+	if err := runAlias(&cobra.Command{}, []string{"summarizer"}); err == nil {
+		t.Error("expected deleting a missing alias to fail")
+	}
+}
+
+func TestRenameCommand(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() { renameNoFile = false }()
+
+	addTestPrompt(t, tmpDir, "summarizer", "Summarize: {{.text}}")
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+	os.WriteFile(filepath.Join(tmpDir, "prompts", "summarizer.prompt"), []byte("Summarize briefly: {{.text}}"), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+	runTag(&cobra.Command{}, []string{"summarizer", "prod", "1.0.0"})
+
+	if err := runRename(&cobra.Command{}, []string{"summarizer", "digest"}); err != nil {
+		t.Fatalf("runRename failed: %v", err)
 	}
-	commitMessage = "Version 3"
-	runCommit(&cobra.Command{}, []string{})
 
-	// Verify versions
 	database, err := db.Open(tmpDir)
 	if err != nil {
-		t.Fatalf("failed to open db: %v", err)
+		t.Fatalf("failed to open database: %v", err)
 	}
 	defer database.Close()
 
-	prompt, _ := database.GetPromptByName("versioned")
-	versions, _ := database.ListVersions(prompt.ID)
-
-	if len(versions) != 3 {
-		t.Fatalf("expected 3 versions, got %d", len(versions))
+	p, _ := database.GetPromptByName("digest")
+	if p == nil {
+		t.Fatal("expected prompt under new name")
 	}
-
-	// Versions are returned newest first
-	expectedVersions := []string{"1.0.2", "1.0.1", "1.0.0"}
-	for i, v := range versions {
-		if v.Version != expectedVersions[i] {
-			t.Errorf("expected version %s at index %d, got %s", expectedVersions[i], i, v.Version)
-		}
+	// The old name is kept as an alias
+	if old, _ := database.GetPromptByAlias("summarizer"); old == nil || old.ID != p.ID || old.Name != "digest" {
+		t.Errorf("expected old name to alias the renamed prompt, got %+v", old)
+	}
+	if p.FilePath != filepath.Join("prompts", "digest.prompt") {
+		t.Errorf("expected file path prompts/digest.prompt, got %s", p.FilePath)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "prompts", "digest.prompt")); err != nil {
+		t.Errorf("expected renamed file: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "prompts", "summarizer.prompt")); !os.IsNotExist(err) {
+		t.Error("expected old file to be moved")
 	}
-}
 
-func TestCommitCommandNoPrompts(t *testing.T) {
-	_, cleanup := initTestProject(t)
-	defer cleanup()
+	versions, _ := database.ListVersions(p.ID)
+	if len(versions) != 2 {
+		t.Errorf("expected 2 versions to survive the rename, got %d", len(versions))
+	}
+	tag, _ := database.GetTagByName(p.ID, "prod")
+	if tag == nil {
+		t.Error("expected tag to survive the rename")
+	}
 
-	// Try to commit with no prompts tracked
-	commitMessage = "Empty commit"
-	err := runCommit(&cobra.Command{}, []string{})
-	if err == nil {
-		t.Error("expected error when committing with no prompts")
+	// --no-file leaves the file where it is
+	renameNoFile = true
+	if err := runRename(&cobra.Command{}, []string{"digest", "brief"}); err != nil {
+		t.Fatalf("runRename --no-file failed: %v", err)
 	}
-	if !strings.Contains(err.Error(), "no prompts tracked") {
-		t.Errorf("expected 'no prompts tracked' error, got: %v", err)
+	p, _ = database.GetPromptByName("brief")
+	if p == nil || p.FilePath != filepath.Join("prompts", "digest.prompt") {
+		t.Errorf("expected brief to keep prompts/digest.prompt, got %+v", p)
 	}
 }
 
-func TestCommitCommandMultiplePrompts(t *testing.T) {
+func TestRenamedNameIsFree(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
+	defer func() { removeForce = false }()
+	aliasHintOutput = io.Discard
+	defer func() { aliasHintOutput = os.Stderr }()
 
-	// Add multiple prompts
-	addTestPrompt(t, tmpDir, "prompt1", "Content 1")
-	addTestPrompt(t, tmpDir, "prompt2", "Content 2")
-	addTestPrompt(t, tmpDir, "prompt3", "Content 3")
+	addTestPrompt(t, tmpDir, "summarizer", "Summarize: {{.text}}")
+	if err := runRename(&cobra.Command{}, []string{"summarizer", "digest"}); err != nil {
+		t.Fatalf("runRename failed: %v", err)
+	}
 
-	// Commit all
-	commitMessage = "Initial commit for all"
-	err := runCommit(&cobra.Command{}, []string{})
-	if err != nil {
-		t.Fatalf("commit failed: %v", err)
+	// The old name only aliases digest for reads: remove does not reach
+	// through it, and a new prompt can take the name
+	removeForce = true
+	if err := runRemove(&cobra.Command{}, []string{"summarizer"}); err == nil {
+		t.Error("expected remove of the old name to fail")
 	}
+	addTestPrompt(t, tmpDir, "summarizer", "A new summarizer")
 
-	// Verify all have versions
 	database, err := db.Open(tmpDir)
 	if err != nil {
-		t.Fatalf("failed to open db: %v", err)
+		t.Fatalf("failed to open database: %v", err)
 	}
 	defer database.Close()
-
-	for _, name := range []string{"prompt1", "prompt2", "prompt3"} {
-		prompt, _ := database.GetPromptByName(name)
-		versions, _ := database.ListVersions(prompt.ID)
-		if len(versions) != 1 {
-			t.Errorf("expected 1 version for %s, got %d", name, len(versions))
-		}
+	if p, _ := database.GetPromptByName("digest"); p == nil {
+		t.Error("expected digest to survive")
+	}
+	if p, _ := database.GetPromptByName("summarizer"); p == nil {
+		t.Error("expected a new prompt named summarizer")
 	}
 }
 
-// ============================================================================
-// Log Command Integration Tests
-// ============================================================================
-
-func TestLogCommand(t *testing.T) {
+func TestRenameCommandCollision(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	// Add and commit a prompt
-	addTestPrompt(t, tmpDir, "logtest", "Content v1")
-	commitMessage = "First version"
-	runCommit(&cobra.Command{}, []string{})
+	addTestPrompt(t, tmpDir, "first", "First")
+	addTestPrompt(t, tmpDir, "second", "Second")
 
-	// Run log command - should not error
-	logPrompt = ""
-	logLimit = 10
-	err := runLog(&cobra.Command{}, []string{})
-	if err != nil {
-		t.Fatalf("runLog failed: %v", err)
+	err := runRename(&cobra.Command{}, []string{"first", "second"})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected already exists error, got: %v", err)
+	}
+
+	// An untracked file in the way is not overwritten
+	os.WriteFile(filepath.Join(tmpDir, "prompts", "third.prompt"), []byte("Untracked"), 0644)
+	err = runRename(&cobra.Command{}, []string{"first", "third"})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected already exists error for file, got: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmpDir, "prompts", "third.prompt")); string(data) != "Untracked" {
+		t.Error("expected untracked file to be left alone")
+	}
+
+	if err := runRename(&cobra.Command{}, []string{"missing", "fourth"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got: %v", err)
 	}
 }
 
-func TestLogCommandSpecificPrompt(t *testing.T) {
+func TestCommitMessagePattern(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
+	defer func() { commitNoVerify = false }()
 
-	// Add and commit multiple versions
-	promptPath := filepath.Join(tmpDir, "prompts", "multilog.prompt")
-	if err := os.WriteFile(promptPath, []byte("V1"), 0644); err != nil {
-		t.Fatalf("failed to write prompt: %v", err)
+	if err := runConfig(&cobra.Command{}, []string{"commit_message_pattern", `^[A-Z]+-\d+: `}); err != nil {
+		t.Fatalf("runConfig failed: %v", err)
 	}
-	runAdd(&cobra.Command{}, []string{"prompts/multilog.prompt"})
-
-	commitMessage = "Version 1"
-	runCommit(&cobra.Command{}, []string{})
-
-	os.WriteFile(promptPath, []byte("V2"), 0644)
-	commitMessage = "Version 2"
-	runCommit(&cobra.Command{}, []string{})
-
-	os.WriteFile(promptPath, []byte("V3"), 0644)
-	commitMessage = "Version 3"
-	runCommit(&cobra.Command{}, []string{})
+	addTestPrompt(t, tmpDir, "ticketed", "Version one")
 
-	// Run log for specific prompt
-	logPrompt = "multilog"
-	logLimit = 10
-	err := runLog(&cobra.Command{}, []string{})
-	if err != nil {
-		t.Fatalf("runLog failed: %v", err)
+	commitMessage = "Tweak wording"
+	err := runCommit(&cobra.Command{}, []string{})
+	if err == nil || !strings.Contains(err.Error(), "does not match commit_message_pattern") {
+		t.Fatalf("expected non-conforming message to be rejected, got: %v", err)
 	}
 
-	// Verify 3 versions exist
 	database, err := db.Open(tmpDir)
 	if err != nil {
-		t.Fatalf("failed to open db: %v", err)
+		t.Fatalf("failed to open database: %v", err)
 	}
 	defer database.Close()
-
-	prompt, _ := database.GetPromptByName("multilog")
-	versions, _ := database.ListVersions(prompt.ID)
-	if len(versions) != 3 {
-		t.Errorf("expected 3 versions, got %d", len(versions))
+	p, _ := database.GetPromptByName("ticketed")
+	if latest, _ := database.GetLatestVersion(p.ID); latest != nil {
+		t.Fatal("expected rejected commit to create no version")
 	}
-}
-
-func TestLogCommandPromptNotFound(t *testing.T) {
-	_, cleanup := initTestProject(t)
-	defer cleanup()
 
-	// Try to log non-existent prompt
-	logPrompt = "nonexistent"
-	logLimit = 10
-	err := runLog(&cobra.Command{}, []string{})
-	if err == nil {
-		t.Error("expected error for non-existent prompt")
+	commitMessage = "ABC-1: Tweak wording"
+	if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("expected conforming message to commit, got: %v", err)
 	}
-	if !strings.Contains(err.Error(), "not found") {
-		t.Errorf("expected 'not found' error, got: %v", err)
+	latest, _ := database.GetLatestVersion(p.ID)
+	if latest == nil || latest.CommitMessage != "ABC-1: Tweak wording" {
+		t.Errorf("expected committed version with message, got %+v", latest)
 	}
-}
-
-func TestLogCommandNoCommits(t *testing.T) {
-	_, cleanup := initTestProject(t)
-	defer cleanup()
 
-	// Run log with no commits
-	logPrompt = ""
-	logLimit = 10
-	err := runLog(&cobra.Command{}, []string{})
-	if err != nil {
-		t.Fatalf("runLog failed: %v", err)
+	// --no-verify skips the pattern
+	os.WriteFile(filepath.Join(tmpDir, "prompts", "ticketed.prompt"), []byte("Version two"), 0644)
+	commitMessage = "wip"
+	commitNoVerify = true
+	if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("expected --no-verify to skip the pattern, got: %v", err)
 	}
-	// Should print "No commits yet." but not error
 }
 
-func TestLogCommandLimit(t *testing.T) {
+func TestCommitMessageTemplate(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	// Create multiple versions
-	promptPath := filepath.Join(tmpDir, "prompts", "limited.prompt")
-	os.WriteFile(promptPath, []byte("V1"), 0644)
-	runAdd(&cobra.Command{}, []string{"prompts/limited.prompt"})
+	addTestPrompt(t, tmpDir, "templated", "Content")
 
-	for i := 1; i <= 5; i++ {
-		os.WriteFile(promptPath, []byte(fmt.Sprintf("V%d", i)), 0644)
-		commitMessage = fmt.Sprintf("Version %d", i)
-		runCommit(&cobra.Command{}, []string{})
+	commitMessage = ""
+	err := runCommit(&cobra.Command{}, []string{})
+	if err == nil || !strings.Contains(err.Error(), "commit message is required") {
+		t.Fatalf("expected missing message error without a template, got: %v", err)
 	}
 
-	// Test with limit
-	logPrompt = "limited"
-	logLimit = 2
-	err := runLog(&cobra.Command{}, []string{})
+	if err := runConfig(&cobra.Command{}, []string{"commit_message_template", "Update {{.Prompts}}"}); err != nil {
+		t.Fatalf("runConfig failed: %v", err)
+	}
+	if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runCommit failed: %v", err)
+	}
+
+	database, err := db.Open(tmpDir)
 	if err != nil {
-		t.Fatalf("runLog failed: %v", err)
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+	p, _ := database.GetPromptByName("templated")
+	latest, _ := database.GetLatestVersion(p.ID)
+	if latest == nil || latest.CommitMessage != "Update templated" {
+		t.Errorf("expected message from template, got %+v", latest)
 	}
-	// The log should only show 2 entries (limit applies to display, not verification)
-}
 
-// ============================================================================
-// Diff Command Integration Tests
-// ============================================================================
+	if err := runConfig(&cobra.Command{}, []string{"commit_message_template", "{{.Missing"}); err == nil {
+		t.Error("expected invalid template to be rejected")
+	}
+}
 
-func TestDiffCommand(t *testing.T) {
+func TestCommitCommand(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	// Create and commit a prompt
-	promptPath := filepath.Join(tmpDir, "prompts", "difftest.prompt")
-	os.WriteFile(promptPath, []byte("Line 1\nLine 2\nLine 3"), 0644)
-	runAdd(&cobra.Command{}, []string{"prompts/difftest.prompt"})
-	commitMessage = "V1"
-	runCommit(&cobra.Command{}, []string{})
+	// Add a prompt
+	addTestPrompt(t, tmpDir, "greeting", `---
+name: greeting
+description: A greeting prompt
+---
+Hello {{name}}!
+`)
 
-	// Modify the file
-	os.WriteFile(promptPath, []byte("Line 1\nModified Line 2\nLine 3"), 0644)
+	// Set commit message
+	commitMessage = "Initial commit"
 
-	// Run diff (working vs latest)
-	err := runDiff(&cobra.Command{}, []string{"difftest"})
+	// Run commit
+	err := runCommit(&cobra.Command{}, []string{})
 	if err != nil {
-		t.Fatalf("runDiff failed: %v", err)
+		t.Fatalf("runCommit failed: %v", err)
 	}
-}
 
-func TestDiffCommandTwoVersions(t *testing.T) {
-	tmpDir, cleanup := initTestProject(t)
-	defer cleanup()
+	// Verify version was created
+	database, err := db.Open(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer database.Close()
 
-	promptPath := filepath.Join(tmpDir, "prompts", "twover.prompt")
+	prompt, _ := database.GetPromptByName("greeting")
+	versions, err := database.ListVersions(prompt.ID)
+	if err != nil {
+		t.Fatalf("failed to list versions: %v", err)
+	}
 
-	// Create v1
-	os.WriteFile(promptPath, []byte("Version 1 content"), 0644)
-	runAdd(&cobra.Command{}, []string{"prompts/twover.prompt"})
-	commitMessage = "V1"
-	runCommit(&cobra.Command{}, []string{})
+	if len(versions) != 1 {
+		t.Fatalf("expected 1 version, got %d", len(versions))
+	}
 
-	// Create v2
-	os.WriteFile(promptPath, []byte("Version 2 content"), 0644)
-	commitMessage = "V2"
-	runCommit(&cobra.Command{}, []string{})
+	if versions[0].Version != "1.0.0" {
+		t.Errorf("expected version 1.0.0, got %s", versions[0].Version)
+	}
 
-	// Diff between two versions
-	err := runDiff(&cobra.Command{}, []string{"twover", "1.0.0", "1.0.1"})
-	if err != nil {
-		t.Fatalf("runDiff failed: %v", err)
+	if versions[0].CommitMessage != "Initial commit" {
+		t.Errorf("expected commit message 'Initial commit', got %s", versions[0].CommitMessage)
 	}
 }
 
-func TestDiffCommandHeadNotation(t *testing.T) {
+func TestCommitCommandAuthor(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
+	defer func() { commitAuthor = "" }()
 
-	promptPath := filepath.Join(tmpDir, "prompts", "headtest.prompt")
-
-	// Create multiple versions
-	os.WriteFile(promptPath, []byte("V1"), 0644)
-	runAdd(&cobra.Command{}, []string{"prompts/headtest.prompt"})
-	commitMessage = "V1"
-	runCommit(&cobra.Command{}, []string{})
+	promptPath := filepath.Join(tmpDir, "prompts", "authored.prompt")
+	os.WriteFile(promptPath, []byte("V0"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/authored.prompt"})
 
-	os.WriteFile(promptPath, []byte("V2"), 0644)
-	commitMessage = "V2"
-	runCommit(&cobra.Command{}, []string{})
+	t.Setenv(authorEnvVar, "Dana Env")
+	commitMessage = "From env"
+	if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runCommit failed: %v", err)
+	}
 
-	os.WriteFile(promptPath, []byte("V3"), 0644)
-	commitMessage = "V3"
-	runCommit(&cobra.Command{}, []string{})
+	os.WriteFile(promptPath, []byte("V1"), 0644)
+	commitAuthor = "Sam Flag"
+	commitMessage = "From flag"
+	if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runCommit failed: %v", err)
+	}
 
-	// Diff using HEAD notation
-	err := runDiff(&cobra.Command{}, []string{"headtest", "HEAD~2", "HEAD"})
+	database, err := db.Open(tmpDir)
 	if err != nil {
-		t.Fatalf("runDiff failed: %v", err)
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer database.Close()
+	prompt, _ := database.GetPromptByName("authored")
+	versions, _ := database.ListVersions(prompt.ID)
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+	if versions[1].CreatedBy != "Dana Env" {
+		t.Errorf("expected $%s to set the author, got %q", authorEnvVar, versions[1].CreatedBy)
+	}
+	if versions[0].CreatedBy != "Sam Flag" {
+		t.Errorf("expected --author to override the environment, got %q", versions[0].CreatedBy)
 	}
 }
 
-func TestDiffCommandNoDifferences(t *testing.T) {
-	tmpDir, cleanup := initTestProject(t)
-	defer cleanup()
-
-	promptPath := filepath.Join(tmpDir, "prompts", "nodiff.prompt")
-	os.WriteFile(promptPath, []byte("Same content"), 0644)
-	runAdd(&cobra.Command{}, []string{"prompts/nodiff.prompt"})
-	commitMessage = "V1"
-	runCommit(&cobra.Command{}, []string{})
+func TestResolveAuthorFallback(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(authorEnvVar, "")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	// From a git repository, git's identity comes before $USER
+	if _, err := exec.LookPath("git"); err == nil {
+		repo := filepath.Join(dir, "repo")
+		for _, args := range [][]string{
+			{"init", "-q", repo},
+			{"-C", repo, "config", "user.email", "erin@example.com"},
+		} {
+			if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+				t.Fatalf("git %v failed: %v\n%s", args, err, out)
+			}
+		}
+		t.Setenv("USER", "sysuser")
+		if got := resolveAuthor(repo, ""); got != "erin@example.com" {
+			t.Errorf("expected git's user.email without a user.name, got %q", got)
+		}
+		exec.Command("git", "-C", repo, "config", "user.name", "Erin Git").Run()
+		if got := resolveAuthor(repo, ""); got != "Erin Git" {
+			t.Errorf("expected git's user.name, got %q", got)
+		}
+		t.Setenv(authorEnvVar, "Env Author")
+		if got := resolveAuthor(repo, ""); got != "Env Author" {
+			t.Errorf("expected $%s to override git, got %q", authorEnvVar, got)
+		}
+		t.Setenv(authorEnvVar, "")
+	}
 
-	// File unchanged, diff should show "No differences"
-	err := runDiff(&cobra.Command{}, []string{"nodiff"})
-	if err != nil {
-		t.Fatalf("runDiff failed: %v", err)
+	// Without git, $USER is used, then "unknown"
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("USER", "sysuser")
+	if got := resolveAuthor(dir, ""); got != "sysuser" {
+		t.Errorf("expected $USER when git is absent, got %q", got)
+	}
+	t.Setenv("USER", "")
+	if got := resolveAuthor(dir, ""); got != "unknown" {
+		t.Errorf("expected \"unknown\" with no author source, got %q", got)
+	}
+	if got := resolveAuthor(dir, "  Flag Author "); got != "Flag Author" {
+		t.Errorf("expected the override to win, got %q", got)
 	}
 }
 
-func TestDiffCommandPromptNotFound(t *testing.T) {
-	_, cleanup := initTestProject(t)
+func TestCommitCommandLogLevelDebug(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
+	defer func() {
+		logLevel, logFormat, commitMessage = logging.LevelOff, "text", ""
+		logging.Configure(os.Stderr, logging.LevelOff, "")
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+	}()
+
+	addTestPrompt(t, tmpDir, "greeting", "Hello {{name}}!")
+
+	var logged string
+	captureStdout(t, func() {
+		var err error
+		logged, err = executeCommand(rootCmd, "--log-level", "debug", "--log-format", "json", "commit", "-m", "Initial commit")
+		if err != nil {
+			t.Fatalf("commit failed: %v", err)
+		}
+	})
 
-	err := runDiff(&cobra.Command{}, []string{"nonexistent"})
-	if err == nil {
-		t.Error("expected error for non-existent prompt")
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logged), "\n") {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("expected a JSON event, got %q", line)
+		}
+		events = append(events, event)
 	}
-	if !strings.Contains(err.Error(), "not found") {
-		t.Errorf("expected 'not found' error, got: %v", err)
+
+	hasEvent := func(msg string, attrs map[string]any) bool {
+		for _, e := range events {
+			if e["msg"] != msg {
+				continue
+			}
+			match := true
+			for k, v := range attrs {
+				if e[k] != v {
+					match = false
+				}
+			}
+			if match {
+				return true
+			}
+		}
+		return false
+	}
+	if !hasEvent("opened database", map[string]any{"level": "DEBUG"}) {
+		t.Errorf("expected an opened database event, got %v", events)
+	}
+	if !hasEvent("created version", map[string]any{"level": "DEBUG", "version": "1.0.0"}) {
+		t.Errorf("expected a created version event for 1.0.0, got %v", events)
+	}
+
+	// The default level logs nothing
+	logLevel = logging.LevelOff
+	os.WriteFile(filepath.Join(tmpDir, "prompts", "greeting.prompt"), []byte("Hi {{name}}!"), 0644)
+	captureStdout(t, func() {
+		var err error
+		logged, err = executeCommand(rootCmd, "commit", "-m", "Second commit")
+		if err != nil {
+			t.Fatalf("commit failed: %v", err)
+		}
+	})
+	if logged != "" {
+		t.Errorf("expected no events at the default level, got %q", logged)
 	}
 }
 
-func TestDiffCommandVersionNotFound(t *testing.T) {
+func TestCommitCommandWithMeta(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	promptPath := filepath.Join(tmpDir, "prompts", "vernotfound.prompt")
-	os.WriteFile(promptPath, []byte("Content"), 0644)
-	runAdd(&cobra.Command{}, []string{"prompts/vernotfound.prompt"})
-	commitMessage = "V1"
-	runCommit(&cobra.Command{}, []string{})
+	addTestPrompt(t, tmpDir, "ticketed", `---
+name: ticketed
+model_hint: gpt-4o
+---
+Hello`)
 
-	// Try to diff with non-existent version
-	err := runDiff(&cobra.Command{}, []string{"vernotfound", "9.9.9"})
-	if err == nil {
-		t.Error("expected error for non-existent version")
+	commitMessage = "Tracked change"
+	commitMeta = []string{"ticket=ABC-1"}
+	defer func() { commitMeta = nil }()
+
+	if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runCommit failed: %v", err)
+	}
+
+	database, err := db.Open(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer database.Close()
+
+	prompt, _ := database.GetPromptByName("ticketed")
+	version, _ := database.GetLatestVersion(prompt.ID)
+
+	var meta map[string]any
+	if err := json.Unmarshal([]byte(version.Metadata), &meta); err != nil {
+		t.Fatalf("failed to parse metadata %q: %v", version.Metadata, err)
+	}
+	if meta["ticket"] != "ABC-1" {
+		t.Errorf("expected ticket ABC-1, got %v", meta["ticket"])
+	}
+	if meta["model_hint"] != "gpt-4o" {
+		t.Errorf("expected frontmatter model_hint to be kept, got %v", meta["model_hint"])
+	}
+
+	commitMeta = []string{"no-equals-sign"}
+	if err := runCommit(&cobra.Command{}, []string{}); err == nil {
+		t.Error("expected error for malformed --meta value")
 	}
 }
 
-// ============================================================================
-// Tag Command Integration Tests
-// ============================================================================
+func TestMergeMetadataJSON(t *testing.T) {
+	got, err := mergeMetadataJSON(`{"model_hint":"gpt-4o"}`, map[string]string{"ticket": "ABC-1"})
+	if err != nil {
+		t.Fatalf("mergeMetadataJSON failed: %v", err)
+	}
+	if got != `{"model_hint":"gpt-4o","ticket":"ABC-1"}` {
+		t.Errorf("unexpected metadata %s", got)
+	}
 
-func TestTagCommand(t *testing.T) {
+	if _, err := mergeMetadataJSON("{not json", map[string]string{"ticket": "ABC-1"}); err == nil {
+		t.Error("expected error for malformed base metadata")
+	}
+}
+
+func TestCommitCommandNoChanges(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	promptPath := filepath.Join(tmpDir, "prompts", "tagtest.prompt")
-	os.WriteFile(promptPath, []byte("Content"), 0644)
-	runAdd(&cobra.Command{}, []string{"prompts/tagtest.prompt"})
-	commitMessage = "V1"
-	runCommit(&cobra.Command{}, []string{})
+	// Add a prompt
+	addTestPrompt(t, tmpDir, "nochange", `Hello!`)
 
-	// Create a tag
-	tagList = false
-	tagDelete = false
-	err := runTag(&cobra.Command{}, []string{"tagtest", "prod"})
+	// Commit first time
+	commitMessage = "First commit"
+	err := runCommit(&cobra.Command{}, []string{})
 	if err != nil {
-		t.Fatalf("runTag failed: %v", err)
+		t.Fatalf("first commit failed: %v", err)
 	}
 
-	// Verify tag was created
+	// Commit again without changes - should succeed but not create new version
+	commitMessage = "Second commit"
+	err = runCommit(&cobra.Command{}, []string{})
+	if err != nil {
+		t.Fatalf("second commit failed: %v", err)
+	}
+
+	// Verify only one version exists
 	database, err := db.Open(tmpDir)
 	if err != nil {
 		t.Fatalf("failed to open db: %v", err)
 	}
 	defer database.Close()
 
-	prompt, _ := database.GetPromptByName("tagtest")
-	tags, _ := database.ListTags(prompt.ID)
-	if len(tags) != 1 {
-		t.Fatalf("expected 1 tag, got %d", len(tags))
-	}
-	if tags[0].Name != "prod" {
-		t.Errorf("expected tag 'prod', got %s", tags[0].Name)
+	prompt, _ := database.GetPromptByName("nochange")
+	versions, _ := database.ListVersions(prompt.ID)
+
+	if len(versions) != 1 {
+		t.Errorf("expected 1 version (no changes), got %d", len(versions))
 	}
 }
 
-func TestTagCommandWithVersion(t *testing.T) {
+func TestCommitCommandVersionBump(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	promptPath := filepath.Join(tmpDir, "prompts", "tagver.prompt")
+	promptPath := filepath.Join(tmpDir, "prompts", "versioned.prompt")
 
-	// Create multiple versions
-	os.WriteFile(promptPath, []byte("V1"), 0644)
-	runAdd(&cobra.Command{}, []string{"prompts/tagver.prompt"})
-	commitMessage = "V1"
+	// Add and commit first version
+	if err := os.WriteFile(promptPath, []byte("Version 1"), 0644); err != nil {
+		t.Fatalf("failed to write prompt: %v", err)
+	}
+	runAdd(&cobra.Command{}, []string{"prompts/versioned.prompt"})
+	commitMessage = "Version 1"
 	runCommit(&cobra.Command{}, []string{})
 
-	os.WriteFile(promptPath, []byte("V2"), 0644)
-	commitMessage = "V2"
+	// Modify and commit second version
+	if err := os.WriteFile(promptPath, []byte("Version 2"), 0644); err != nil {
+		t.Fatalf("failed to write prompt: %v", err)
+	}
+	commitMessage = "Version 2"
 	runCommit(&cobra.Command{}, []string{})
 
-	// Tag specific version
-	tagList = false
-	tagDelete = false
-	err := runTag(&cobra.Command{}, []string{"tagver", "stable", "1.0.0"})
-	if err != nil {
-		t.Fatalf("runTag failed: %v", err)
+	// Modify and commit third version
+	if err := os.WriteFile(promptPath, []byte("Version 3"), 0644); err != nil {
+		t.Fatalf("failed to write prompt: %v", err)
 	}
+	commitMessage = "Version 3"
+	runCommit(&cobra.Command{}, []string{})
 
-	// Verify tag points to correct version
+	// Verify versions
 	database, err := db.Open(tmpDir)
 	if err != nil {
 		t.Fatalf("failed to open db: %v", err)
 	}
 	defer database.Close()
 
-	prompt, _ := database.GetPromptByName("tagver")
-	tags, _ := database.ListTags(prompt.ID)
-	if len(tags) != 1 {
-		t.Fatalf("expected 1 tag, got %d", len(tags))
+	prompt, _ := database.GetPromptByName("versioned")
+	versions, _ := database.ListVersions(prompt.ID)
+
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 versions, got %d", len(versions))
 	}
 
-	// Get version for tag
-	version, _ := database.GetVersionByID(tags[0].VersionID)
-	if version.Version != "1.0.0" {
-		t.Errorf("expected tag to point to 1.0.0, got %s", version.Version)
+	// Versions are returned newest first
+	expectedVersions := []string{"1.0.2", "1.0.1", "1.0.0"}
+	for i, v := range versions {
+		if v.Version != expectedVersions[i] {
+			t.Errorf("expected version %s at index %d, got %s", expectedVersions[i], i, v.Version)
+		}
 	}
 }
 
-func TestTagCommandList(t *testing.T) {
-	tmpDir, cleanup := initTestProject(t)
+func TestCommitCommandNoPrompts(t *testing.T) {
+	_, cleanup := initTestProject(t)
 	defer cleanup()
 
-	promptPath := filepath.Join(tmpDir, "prompts", "taglist.prompt")
-	os.WriteFile(promptPath, []byte("Content"), 0644)
-	runAdd(&cobra.Command{}, []string{"prompts/taglist.prompt"})
-	commitMessage = "V1"
-	runCommit(&cobra.Command{}, []string{})
-
-	// Create multiple tags
-	tagList = false
-	tagDelete = false
-	runTag(&cobra.Command{}, []string{"taglist", "prod"})
-	runTag(&cobra.Command{}, []string{"taglist", "staging"})
-
-	// List tags
-	tagList = true
-	err := runTag(&cobra.Command{}, []string{"taglist"})
-	if err != nil {
-		t.Fatalf("runTag --list failed: %v", err)
+	// Try to commit with no prompts tracked
+	commitMessage = "Empty commit"
+	err := runCommit(&cobra.Command{}, []string{})
+	if err == nil {
+		t.Error("expected error when committing with no prompts")
+	}
+	if !strings.Contains(err.Error(), "no prompts tracked") {
+		t.Errorf("expected 'no prompts tracked' error, got: %v", err)
 	}
 }
 
-func TestTagCommandDelete(t *testing.T) {
+func TestCommitCommandMultiplePrompts(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	promptPath := filepath.Join(tmpDir, "prompts", "tagdel.prompt")
-	os.WriteFile(promptPath, []byte("Content"), 0644)
-	runAdd(&cobra.Command{}, []string{"prompts/tagdel.prompt"})
-	commitMessage = "V1"
-	runCommit(&cobra.Command{}, []string{})
-
-	// Create tag
-	tagList = false
-	tagDelete = false
-	runTag(&cobra.Command{}, []string{"tagdel", "temp"})
+	// Add multiple prompts
+	addTestPrompt(t, tmpDir, "prompt1", "Content 1")
+	addTestPrompt(t, tmpDir, "prompt2", "Content 2")
+	addTestPrompt(t, tmpDir, "prompt3", "Content 3")
 
-	// Delete tag
-	tagDelete = true
-	err := runTag(&cobra.Command{}, []string{"tagdel", "temp"})
+	// Commit all
+	commitMessage = "Initial commit for all"
+	err := runCommit(&cobra.Command{}, []string{})
 	if err != nil {
-		t.Fatalf("runTag --delete failed: %v", err)
+		t.Fatalf("commit failed: %v", err)
 	}
 
-	// Verify tag was deleted
+	// Verify all have versions
 	database, err := db.Open(tmpDir)
 	if err != nil {
 		t.Fatalf("failed to open db: %v", err)
 	}
 	defer database.Close()
 
-	prompt, _ := database.GetPromptByName("tagdel")
-	tags, _ := database.ListTags(prompt.ID)
-	if len(tags) != 0 {
-		t.Errorf("expected 0 tags after deletion, got %d", len(tags))
+	for _, name := range []string{"prompt1", "prompt2", "prompt3"} {
+		prompt, _ := database.GetPromptByName(name)
+		versions, _ := database.ListVersions(prompt.ID)
+		if len(versions) != 1 {
+			t.Errorf("expected 1 version for %s, got %d", name, len(versions))
+		}
 	}
 }
 
-func TestTagCommandPromptNotFound(t *testing.T) {
-	_, cleanup := initTestProject(t)
+func TestCommitCommandFailureLeavesNoPartialVersions(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	tagList = false
-	tagDelete = false
-	err := runTag(&cobra.Command{}, []string{"nonexistent", "tag"})
-	if err == nil {
-		t.Error("expected error for non-existent prompt")
+	addTestPrompt(t, tmpDir, "alpha", "Alpha content")
+	addTestPrompt(t, tmpDir, "beta", "Beta content")
+
+	// Replace beta's file with a directory so reading it fails after alpha
+	// has already been processed
+	betaPath := filepath.Join(tmpDir, "prompts", "beta.prompt")
+	if err := os.Remove(betaPath); err != nil {
+		t.Fatalf("failed to remove prompt file: %v", err)
+	}
+	if err := os.Mkdir(betaPath, 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
 	}
-}
 
-// ============================================================================
-// Checkout Command Integration Tests
-// ============================================================================
+	commitMessage = "Should not be recorded"
+	if err := runCommit(&cobra.Command{}, []string{}); err == nil {
+		t.Fatal("expected commit to fail")
+	}
 
-func TestCheckoutCommand(t *testing.T) {
+	database, err := db.Open(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer database.Close()
+
+	for _, name := range []string{"alpha", "beta"} {
+		p, _ := database.GetPromptByName(name)
+		versions, _ := database.ListVersions(p.ID)
+		if len(versions) != 0 {
+			t.Errorf("expected no versions for %s after failed commit, got %d", name, len(versions))
+		}
+	}
+}
+
+func TestStageCommitsOnlyStagedPrompts(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	promptPath := filepath.Join(tmpDir, "prompts", "checkout.prompt")
+	addTestPrompt(t, tmpDir, "first", "First v1")
+	addTestPrompt(t, tmpDir, "second", "Second v1")
+	commitMessage = "Initial"
+	if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runCommit failed: %v", err)
+	}
 
-	// Create multiple versions
-	os.WriteFile(promptPath, []byte("Version 1 content"), 0644)
-	runAdd(&cobra.Command{}, []string{"prompts/checkout.prompt"})
-	commitMessage = "V1"
-	runCommit(&cobra.Command{}, []string{})
+	// Modify both, but stage only the first
+	commitAll = false
+	firstPath := filepath.Join(tmpDir, "prompts", "first.prompt")
+	os.WriteFile(firstPath, []byte("First v2"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "prompts", "second.prompt"), []byte("Second v2"), 0644)
+	if err := runStage(&cobra.Command{}, []string{"first"}); err != nil {
+		t.Fatalf("runStage failed: %v", err)
+	}
+	// Edits after staging are not part of the snapshot
+	os.WriteFile(firstPath, []byte("First v3"), 0644)
 
-	os.WriteFile(promptPath, []byte("Version 2 content"), 0644)
-	commitMessage = "V2"
-	runCommit(&cobra.Command{}, []string{})
+	commitMessage = "Staged only"
+	if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runCommit failed: %v", err)
+	}
 
-	// Checkout first version
-	err := runCheckout(&cobra.Command{}, []string{"checkout", "1.0.0"})
+	database, err := db.Open(tmpDir)
 	if err != nil {
-		t.Fatalf("runCheckout failed: %v", err)
+		t.Fatalf("failed to open db: %v", err)
 	}
+	defer database.Close()
 
-	// Verify file content was restored
-	content, err := os.ReadFile(promptPath)
-	if err != nil {
-		t.Fatalf("failed to read file: %v", err)
+	first, _ := database.GetPromptByName("first")
+	latest, _ := database.GetLatestVersion(first.ID)
+	if latest.Version != "1.0.1" || latest.Content != "First v2" {
+		t.Errorf("expected first@1.0.1 with staged content, got %s %q", latest.Version, latest.Content)
 	}
-	if string(content) != "Version 1 content" {
-		t.Errorf("expected 'Version 1 content', got %q", string(content))
+	second, _ := database.GetPromptByName("second")
+	latest, _ = database.GetLatestVersion(second.ID)
+	if latest.Version != "1.0.0" {
+		t.Errorf("expected unstaged second prompt to stay at 1.0.0, got %s", latest.Version)
+	}
+	if staged, _ := database.ListStagedPrompts(); len(staged) != 0 {
+		t.Errorf("expected commit to empty the staging area, got %d staged", len(staged))
+	}
+
+	// Nothing staged: commit records nothing
+	commitMessage = "Nothing"
+	if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runCommit failed: %v", err)
+	}
+	if versions, _ := database.ListVersions(second.ID); len(versions) != 1 {
+		t.Errorf("expected no new versions without staging, got %d", len(versions))
+	}
+
+	// --all commits the remaining working changes
+	commitAll = true
+	commitMessage = "Everything"
+	if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runCommit failed: %v", err)
+	}
+	latest, _ = database.GetLatestVersion(second.ID)
+	if latest.Version != "1.0.1" || latest.Content != "Second v2" {
+		t.Errorf("expected --all to commit second@1.0.1, got %s %q", latest.Version, latest.Content)
 	}
 }
 
-func TestCheckoutCommandByTag(t *testing.T) {
+func TestCommitClearsUnchangedStagedPrompts(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	promptPath := filepath.Join(tmpDir, "prompts", "checkoutag.prompt")
+	addTestPrompt(t, tmpDir, "first", "First v1")
+	addTestPrompt(t, tmpDir, "second", "Second v1")
+	commitMessage = "Initial"
+	if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runCommit failed: %v", err)
+	}
+	commitAll = false
 
-	// Create versions
-	os.WriteFile(promptPath, []byte("Production content"), 0644)
-	runAdd(&cobra.Command{}, []string{"prompts/checkoutag.prompt"})
-	commitMessage = "V1"
-	runCommit(&cobra.Command{}, []string{})
+	database, err := db.Open(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer database.Close()
+	first, _ := database.GetPromptByName("first")
+	second, _ := database.GetPromptByName("second")
 
-	// Tag as prod
-	tagList = false
-	tagDelete = false
-	runTag(&cobra.Command{}, []string{"checkoutag", "prod"})
+	// A snapshot can match the latest version when that content was
+	// committed another way after staging
+	database.StagePrompt(first.ID, "First v1")
+	os.WriteFile(filepath.Join(tmpDir, "prompts", "second.prompt"), []byte("Second v2"), 0644)
+	if err := runStage(&cobra.Command{}, []string{"second"}); err != nil {
+		t.Fatalf("runStage failed: %v", err)
+	}
 
-	// Create another version
-	os.WriteFile(promptPath, []byte("Development content"), 0644)
-	commitMessage = "V2"
+	commitMessage = "Second only"
+	captureStdout(t, func() {
+		if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+			t.Fatalf("runCommit failed: %v", err)
+		}
+	})
+	if versions, _ := database.ListVersions(first.ID); len(versions) != 1 {
+		t.Errorf("expected no new version of an unchanged prompt, got %d", len(versions))
+	}
+	if latest, _ := database.GetLatestVersion(second.ID); latest.Version != "1.0.1" {
+		t.Errorf("expected second@1.0.1, got %s", latest.Version)
+	}
+	if staged, _ := database.ListStagedPrompts(); len(staged) != 0 {
+		t.Errorf("expected the unchanged snapshot to be unstaged with the commit, got %d staged", len(staged))
+	}
+
+	// Also when nothing else is committed
+	database.StagePrompt(first.ID, "First v1")
+	out := captureStdout(t, func() {
+		if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+			t.Fatalf("runCommit failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "No changes to commit.") {
+		t.Errorf("expected no changes, got:\n%s", out)
+	}
+	if staged, _ := database.ListStagedPrompts(); len(staged) != 0 {
+		t.Errorf("expected the unchanged snapshot to be unstaged, got %d staged", len(staged))
+	}
+}
+
+func TestStageCommand(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	addTestPrompt(t, tmpDir, "greeting", "Hello")
+	commitMessage = "Initial"
 	runCommit(&cobra.Command{}, []string{})
 
-	// Checkout by tag
-	err := runCheckout(&cobra.Command{}, []string{"checkoutag", "prod"})
-	if err != nil {
-		t.Fatalf("runCheckout failed: %v", err)
+	if err := runStage(&cobra.Command{}, []string{"greeting", "missing"}); err == nil {
+		t.Error("expected error for unknown prompt")
 	}
 
-	// Verify file content
-	content, err := os.ReadFile(promptPath)
+	database, err := db.Open(tmpDir)
 	if err != nil {
-		t.Fatalf("failed to read file: %v", err)
+		t.Fatalf("failed to open db: %v", err)
 	}
-	if string(content) != "Production content" {
-		t.Errorf("expected 'Production content', got %q", string(content))
+	defer database.Close()
+	p, _ := database.GetPromptByName("greeting")
+	if staged, _ := database.GetStagedPrompt(p.ID); staged != nil {
+		t.Error("expected nothing staged when any prompt is unknown")
+	}
+
+	promptPath := filepath.Join(tmpDir, "prompts", "greeting.prompt")
+	os.WriteFile(promptPath, []byte("Hello there"), 0644)
+	if err := runStage(&cobra.Command{}, []string{"greeting"}); err != nil {
+		t.Fatalf("runStage failed: %v", err)
+	}
+	if staged, _ := database.GetStagedPrompt(p.ID); staged == nil || staged.Content != "Hello there" {
+		t.Errorf("expected staged snapshot, got %+v", staged)
+	}
+
+	// Restoring the file and staging again drops it from the staging area
+	os.WriteFile(promptPath, []byte("Hello"), 0644)
+	if err := runStage(&cobra.Command{}, []string{"greeting"}); err != nil {
+		t.Fatalf("runStage failed: %v", err)
+	}
+	if staged, _ := database.GetStagedPrompt(p.ID); staged != nil {
+		t.Error("expected unchanged prompt to be unstaged")
 	}
 }
 
-func TestCheckoutCommandHeadNotation(t *testing.T) {
+// ============================================================================
+// Log Command Integration Tests
+// ============================================================================
+
+func TestLogCommand(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	promptPath := filepath.Join(tmpDir, "prompts", "checkhead.prompt")
+	// Add and commit a prompt
+	addTestPrompt(t, tmpDir, "logtest", "Content v1")
+	commitMessage = "First version"
+	runCommit(&cobra.Command{}, []string{})
 
-	// Create multiple versions
-	os.WriteFile(promptPath, []byte("V1"), 0644)
-	runAdd(&cobra.Command{}, []string{"prompts/checkhead.prompt"})
-	commitMessage = "V1"
+	// Run log command - should not error
+	logPrompt = ""
+	logLimit = 10
+	err := runLog(&cobra.Command{}, []string{})
+	if err != nil {
+		t.Fatalf("runLog failed: %v", err)
+	}
+}
+
+func TestLogCommandSpecificPrompt(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Add and commit multiple versions
+	promptPath := filepath.Join(tmpDir, "prompts", "multilog.prompt")
+	if err := os.WriteFile(promptPath, []byte("V1"), 0644); err != nil {
+		t.Fatalf("failed to write prompt: %v", err)
+	}
+	runAdd(&cobra.Command{}, []string{"prompts/multilog.prompt"})
+
+	commitMessage = "Version 1"
 	runCommit(&cobra.Command{}, []string{})
 
 	os.WriteFile(promptPath, []byte("V2"), 0644)
-	commitMessage = "V2"
+	commitMessage = "Version 2"
 	runCommit(&cobra.Command{}, []string{})
 
 	os.WriteFile(promptPath, []byte("V3"), 0644)
-	commitMessage = "V3"
+	commitMessage = "Version 3"
 	runCommit(&cobra.Command{}, []string{})
 
-	// Checkout HEAD~2 (first version)
-	err := runCheckout(&cobra.Command{}, []string{"checkhead", "HEAD~2"})
+	// Run log for specific prompt
+	logPrompt = "multilog"
+	logLimit = 10
+	err := runLog(&cobra.Command{}, []string{})
 	if err != nil {
-		t.Fatalf("runCheckout failed: %v", err)
+		t.Fatalf("runLog failed: %v", err)
 	}
 
-	// Verify file content
-	content, err := os.ReadFile(promptPath)
+	// Verify 3 versions exist
+	database, err := db.Open(tmpDir)
 	if err != nil {
-		t.Fatalf("failed to read file: %v", err)
+		t.Fatalf("failed to open db: %v", err)
 	}
-	if string(content) != "V1" {
-		t.Errorf("expected 'V1', got %q", string(content))
+	defer database.Close()
+
+	prompt, _ := database.GetPromptByName("multilog")
+	versions, _ := database.ListVersions(prompt.ID)
+	if len(versions) != 3 {
+		t.Errorf("expected 3 versions, got %d", len(versions))
 	}
 }
 
-func TestCheckoutCommandPromptNotFound(t *testing.T) {
+func TestLogCommandPromptNotFound(t *testing.T) {
 	_, cleanup := initTestProject(t)
 	defer cleanup()
 
-	err := runCheckout(&cobra.Command{}, []string{"nonexistent", "1.0.0"})
+	// Try to log non-existent prompt
+	logPrompt = "nonexistent"
+	logLimit = 10
+	err := runLog(&cobra.Command{}, []string{})
 	if err == nil {
 		t.Error("expected error for non-existent prompt")
 	}
@@ -2068,824 +2775,4410 @@ func TestCheckoutCommandPromptNotFound(t *testing.T) {
 	}
 }
 
-func TestCheckoutCommandVersionNotFound(t *testing.T) {
-	tmpDir, cleanup := initTestProject(t)
+func TestLogCommandNoCommits(t *testing.T) {
+	_, cleanup := initTestProject(t)
 	defer cleanup()
 
-	promptPath := filepath.Join(tmpDir, "prompts", "checkver.prompt")
-	os.WriteFile(promptPath, []byte("Content"), 0644)
-	runAdd(&cobra.Command{}, []string{"prompts/checkver.prompt"})
-	commitMessage = "V1"
-	runCommit(&cobra.Command{}, []string{})
-
-	err := runCheckout(&cobra.Command{}, []string{"checkver", "9.9.9"})
-	if err == nil {
-		t.Error("expected error for non-existent version")
-	}
-}
-
-// ============================================================================
-// Test Command Integration Tests
-// ============================================================================
-
-// createTestSuite creates a test suite YAML file for testing
-func createTestSuite(t *testing.T, tmpDir, name, content string) {
-	t.Helper()
-	testsDir := filepath.Join(tmpDir, "tests")
-	suitePath := filepath.Join(testsDir, name+".test.yaml")
-	if err := os.WriteFile(suitePath, []byte(content), 0644); err != nil {
-		t.Fatalf("failed to write test suite file: %v", err)
+	// Run log with no commits
+	logPrompt = ""
+	logLimit = 10
+	err := runLog(&cobra.Command{}, []string{})
+	if err != nil {
+		t.Fatalf("runLog failed: %v", err)
 	}
+	// Should print "No commits yet." but not error
 }
 
-func TestTestCommand(t *testing.T) {
+func TestLogCommandLimit(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	// Add and commit a prompt (Go templates use .field syntax)
-	addTestPrompt(t, tmpDir, "greeting", `---
-name: greeting
-description: A greeting prompt
----
-Hello {{.name}}! Welcome to PromptSmith.
-`)
-	commitMessage = "Initial commit"
-	runCommit(&cobra.Command{}, []string{})
-
-	// Create a test suite
-	createTestSuite(t, tmpDir, "greeting", `
-name: greeting-tests
-prompt: greeting
-tests:
-  - name: basic-test
-    inputs:
-      name: World
-    assertions:
-      - type: not_empty
-      - type: contains
-        value: Hello
-      - type: contains
-        value: World
-`)
+	// Create multiple versions
+	promptPath := filepath.Join(tmpDir, "prompts", "limited.prompt")
+	os.WriteFile(promptPath, []byte("V1"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/limited.prompt"})
 
-	// Reset flags
-	testFilter = ""
-	testVersion = ""
-	testOutput = ""
-	testLive = false
-	testWatch = false
+	for i := 1; i <= 5; i++ {
+		os.WriteFile(promptPath, []byte(fmt.Sprintf("V%d", i)), 0644)
+		commitMessage = fmt.Sprintf("Version %d", i)
+		runCommit(&cobra.Command{}, []string{})
+	}
 
-	// Run test command
-	err := runTest(&cobra.Command{}, []string{})
+	// Test with limit
+	logPrompt = "limited"
+	logLimit = 2
+	err := runLog(&cobra.Command{}, []string{})
 	if err != nil {
-		t.Fatalf("runTest failed: %v", err)
+		t.Fatalf("runLog failed: %v", err)
 	}
+	// The log should only show 2 entries (limit applies to display, not verification)
 }
 
-func TestTestCommandWithFilter(t *testing.T) {
+func TestLogCommandRange(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
+	defer func() { jsonOut = false }()
 
-	// Add and commit a prompt (Go templates use .field syntax)
-	addTestPrompt(t, tmpDir, "filtered", `---
-name: filtered
+	promptPath := filepath.Join(tmpDir, "prompts", "ranged.prompt")
+	os.WriteFile(promptPath, []byte("V0"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/ranged.prompt"})
+	for i := 1; i <= 5; i++ {
+		os.WriteFile(promptPath, []byte(fmt.Sprintf("V%d", i)), 0644)
+		commitMessage = fmt.Sprintf("Version %d", i)
+		runCommit(&cobra.Command{}, []string{})
+	}
+
+	logVersions := func(rangeSpec string) []string {
+		t.Helper()
+		logPrompt = ""
+		logLimit = 2 // A range is not cut short by the default limit
+		jsonOut = true
+		output := captureStdout(t, func() {
+			if err := runLog(&cobra.Command{}, []string{"ranged", rangeSpec}); err != nil {
+				t.Fatalf("runLog %s failed: %v", rangeSpec, err)
+			}
+		})
+		var entries []logEntry
+		if err := json.Unmarshal([]byte(output), &entries); err != nil {
+			t.Fatalf("failed to parse log output %q: %v", output, err)
+		}
+		var versions []string
+		for _, e := range entries {
+			versions = append(versions, e.Version)
+		}
+		return versions
+	}
+
+	want := []string{"1.0.3", "1.0.2", "1.0.1"}
+	if got := logVersions("1.0.1..1.0.3"); !reflect.DeepEqual(got, want) {
+		t.Errorf("log 1.0.1..1.0.3 = %v, want %v", got, want)
+	}
+	if got := logVersions("1.0.3..1.0.1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected a reversed range to list the same versions, got %v", got)
+	}
+	if got := logVersions("HEAD~1..HEAD"); !reflect.DeepEqual(got, []string{"1.0.4", "1.0.3"}) {
+		t.Errorf("log HEAD~1..HEAD = %v, want [1.0.4 1.0.3]", got)
+	}
+
+	for _, bad := range []string{"1.0.1", "1.0.1..", "1.0.1..9.9.9"} {
+		if err := runLog(&cobra.Command{}, []string{"ranged", bad}); err == nil {
+			t.Errorf("expected an error for range %q", bad)
+		}
+	}
+}
+
+func TestExportImportCommandsRoundTrip(t *testing.T) {
+	srcDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() { exportOutput = "" }()
+
+	promptPath := filepath.Join(srcDir, "prompts", "portable.prompt")
+	os.WriteFile(promptPath, []byte("V0 {{topic}}"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/portable.prompt"})
+	for i := 1; i <= 3; i++ {
+		os.WriteFile(promptPath, []byte(fmt.Sprintf("V%d {{topic}}", i)), 0644)
+		commitMessage = fmt.Sprintf("Version %d", i)
+		if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+			t.Fatalf("runCommit failed: %v", err)
+		}
+	}
+	if err := runTag(&cobra.Command{}, []string{"portable", "prod", "1.0.1"}); err != nil {
+		t.Fatalf("runTag failed: %v", err)
+	}
+
+	bundlePath := filepath.Join(t.TempDir(), "portable.json")
+	exportOutput = bundlePath
+	if err := runExport(&cobra.Command{}, []string{"portable"}); err != nil {
+		t.Fatalf("runExport failed: %v", err)
+	}
+	if err := runImport(&cobra.Command{}, []string{bundlePath}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected importing into the source project to fail, got %v", err)
+	}
+
+	history := func(dir string) (*db.PromptExport, string) {
+		t.Helper()
+		database, err := db.Open(dir)
+		if err != nil {
+			t.Fatalf("failed to open db: %v", err)
+		}
+		defer database.Close()
+		export, err := database.ExportPrompt("portable")
+		if err != nil || export == nil {
+			t.Fatalf("ExportPrompt failed: %v", err)
+		}
+		data, _ := json.Marshal(struct {
+			Versions []db.ExportedVersion
+			Tags     []db.ExportedTag
+		}{export.Versions, export.Tags})
+		return export, string(data)
+	}
+	_, want := history(srcDir)
+
+	// Import into a fresh project, which has no copy of the prompt file
+	dstDir, cleanupDst := initTestProject(t)
+	defer cleanupDst()
+	if err := runImport(&cobra.Command{}, []string{bundlePath}); err != nil {
+		t.Fatalf("runImport failed: %v", err)
+	}
+
+	imported, got := history(dstDir)
+	if got != want {
+		t.Errorf("imported history = %s, want %s", got, want)
+	}
+	if len(imported.Versions) != 3 || imported.Versions[2].Parent != "1.0.1" {
+		t.Errorf("expected three versions ending with a child of 1.0.1, got %+v", imported.Versions)
+	}
+	content, err := os.ReadFile(filepath.Join(dstDir, "prompts", "portable.prompt"))
+	if err != nil || string(content) != "V3 {{topic}}" {
+		t.Errorf("expected the prompt file to hold the latest version, got %q (%v)", content, err)
+	}
+}
+
+func TestImportCommandRefusesDifferingFile(t *testing.T) {
+	srcDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() { exportOutput = "" }()
+
+	addTestPrompt(t, srcDir, "portable", "Original")
+	commitMessage = "Initial"
+	runCommit(&cobra.Command{}, []string{})
+	bundlePath := filepath.Join(t.TempDir(), "portable.json")
+	exportOutput = bundlePath
+	if err := runExport(&cobra.Command{}, []string{"portable"}); err != nil {
+		t.Fatalf("runExport failed: %v", err)
+	}
+
+	dstDir, cleanupDst := initTestProject(t)
+	defer cleanupDst()
+	promptPath := filepath.Join(dstDir, "prompts", "portable.prompt")
+	os.WriteFile(promptPath, []byte("Local edits"), 0644)
+
+	if err := runImport(&cobra.Command{}, []string{bundlePath}); err == nil || !strings.Contains(err.Error(), "differs") {
+		t.Errorf("expected import onto a differing file to fail, got %v", err)
+	}
+	if content, _ := os.ReadFile(promptPath); string(content) != "Local edits" {
+		t.Errorf("expected the local file to be left alone, got %q", content)
+	}
+}
+
+func TestExportAllIsDeterministic(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() { exportAll, exportDir = false, "" }()
+
+	addTestPrompt(t, tmpDir, "greeting", "Hello {{name}}")
+	addTestPrompt(t, tmpDir, "farewell", "Bye <b>{{name}}</b>")
+	commitMessage = "Initial"
+	runCommit(&cobra.Command{}, []string{})
+	os.WriteFile(filepath.Join(tmpDir, "prompts", "greeting.prompt"), []byte("Hi {{name}}"), 0644)
+	commitMessage = "Shorter greeting"
+	runCommit(&cobra.Command{}, []string{})
+	runTag(&cobra.Command{}, []string{"greeting", "prod", "1.0.0"})
+
+	readTree := func(dir string) map[string]string {
+		t.Helper()
+		files := make(map[string]string)
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			rel, _ := filepath.Rel(dir, path)
+			files[filepath.ToSlash(rel)] = string(data)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("failed to read export: %v", err)
+		}
+		return files
+	}
+	export := func(dir string) map[string]string {
+		t.Helper()
+		exportAll, exportDir = true, dir
+		if err := runExport(&cobra.Command{}, nil); err != nil {
+			t.Fatalf("runExport --all failed: %v", err)
+		}
+		return readTree(dir)
+	}
+
+	outDir := filepath.Join(t.TempDir(), "out")
+	first := export(outDir)
+	second := export(outDir)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("expected re-exporting an unchanged project to produce identical files")
+	}
+	if fresh := export(filepath.Join(t.TempDir(), "out")); !reflect.DeepEqual(first, fresh) {
+		t.Errorf("expected an export to a new directory to match")
+	}
+
+	wantFiles := []string{
+		"farewell.history.jsonl",
+		"greeting.history.jsonl",
+		"manifest.json",
+		"prompts/farewell.prompt",
+		"prompts/greeting.prompt",
+	}
+	if got := slices.Sorted(maps.Keys(first)); !reflect.DeepEqual(got, wantFiles) {
+		t.Errorf("exported files = %v, want %v", got, wantFiles)
+	}
+	if got := first["prompts/greeting.prompt"]; got != "Hi {{name}}" {
+		t.Errorf("expected greeting.prompt to hold the latest version, got %q", got)
+	}
+	if got := first["prompts/farewell.prompt"]; got != "Bye <b>{{name}}</b>" {
+		t.Errorf("expected farewell.prompt to hold the latest version, got %q", got)
+	}
+	if lines := strings.Split(strings.TrimSpace(first["greeting.history.jsonl"]), "\n"); len(lines) != 2 {
+		t.Errorf("expected two history lines for greeting, got %d", len(lines))
+	}
+	if !strings.Contains(first["farewell.history.jsonl"], "<b>") {
+		t.Errorf("expected history to keep markup unescaped, got %s", first["farewell.history.jsonl"])
+	}
+
+	var manifest projectExportManifest
+	if err := json.Unmarshal([]byte(first["manifest.json"]), &manifest); err != nil {
+		t.Fatalf("failed to parse manifest: %v", err)
+	}
+	if len(manifest.Prompts) != 2 || manifest.Prompts[0].Name != "farewell" || manifest.Prompts[1].LatestVersion != "1.0.1" {
+		t.Errorf("unexpected manifest: %+v", manifest)
+	}
+	if tags := manifest.Prompts[1].Tags; len(tags) != 1 || tags[0].Version != "1.0.0" {
+		t.Errorf("expected greeting's prod tag in the manifest, got %+v", tags)
+	}
+
+	// A prompt that no longer exists is dropped from the next export
+	database, _ := db.Open(tmpDir)
+	farewell, _ := database.GetPromptByName("farewell")
+	database.DeletePrompt(farewell.ID)
+	database.Close()
+	after := export(outDir)
+	if _, ok := after["farewell.history.jsonl"]; ok {
+		t.Error("expected a deleted prompt's history to be removed from the export")
+	}
+	if _, ok := after["prompts/farewell.prompt"]; ok {
+		t.Error("expected a deleted prompt's file to be removed from the export")
+	}
+}
+
+func TestLogCommandGraph(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() { logGraph, logPrompt = false, "" }()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "graphed.prompt")
+	os.WriteFile(promptPath, []byte("V0"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/graphed.prompt"})
+	for i := 1; i <= 3; i++ {
+		os.WriteFile(promptPath, []byte(fmt.Sprintf("V%d", i)), 0644)
+		commitMessage = fmt.Sprintf("Version %d", i)
+		runCommit(&cobra.Command{}, []string{})
+	}
+	if err := runTag(&cobra.Command{}, []string{"graphed", "prod", "1.0.1"}); err != nil {
+		t.Fatalf("runTag failed: %v", err)
+	}
+
+	logGraph = true
+	logPrompt = ""
+	output := captureStdout(t, func() {
+		if err := runLog(logCmd, []string{"graphed"}); err != nil {
+			t.Fatalf("runLog --graph failed: %v", err)
+		}
+	})
+
+	var graph []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "* ") || strings.HasPrefix(line, "|") {
+			graph = append(graph, line)
+		}
+	}
+	wantPrefixes := []string{"* 1.0.2 Version 3", "* 1.0.1 (prod) Version 2", "* 1.0.0 Version 1"}
+	if len(graph) != len(wantPrefixes) {
+		t.Fatalf("expected %d graph lines, got %q", len(wantPrefixes), output)
+	}
+	for i, want := range wantPrefixes {
+		if !strings.HasPrefix(graph[i], want) {
+			t.Errorf("graph line %d = %q, want it to start with %q", i, graph[i], want)
+		}
+	}
+
+	logAuthor = "alice"
+	defer func() { logAuthor = "" }()
+	if err := runLog(logCmd, []string{"graphed"}); err == nil {
+		t.Error("expected --graph with --author to fail")
+	}
+}
+
+func TestVersionGraph(t *testing.T) {
+	id := func(s string) *string { return &s }
+	// 1.1.0 and 1.0.1 both branch from 1.0.0; 0.9.0's parent is gone
+	versions := []*db.PromptVersion{
+		{ID: "d", Version: "1.1.0", ParentVersionID: id("a"), CommitMessage: "Branch"},
+		{ID: "c", Version: "1.0.2", ParentVersionID: id("b"), CommitMessage: "Main"},
+		{ID: "b", Version: "1.0.1", ParentVersionID: id("a")},
+		{ID: "a", Version: "1.0.0"},
+		{ID: "z", Version: "0.9.0", ParentVersionID: id("deleted")},
+	}
+	lines := versionGraph(versions, map[string][]string{"b": {"prod", "staging"}}, 0)
+
+	want := []string{
+		"* 1.1.0 Branch",
+		"| * 1.0.2 Main",
+		"| * 1.0.1 (prod, staging)",
+		"|/",
+		"* 1.0.0",
+		"* 0.9.0",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %q", len(want), lines)
+	}
+	for i := range want {
+		if !strings.HasPrefix(lines[i], want[i]) {
+			t.Errorf("line %d = %q, want it to start with %q", i, lines[i], want[i])
+		}
+	}
+	if !strings.Contains(lines[5], "(orphan: parent missing)") {
+		t.Errorf("expected 0.9.0 to be marked as an orphan, got %q", lines[5])
+	}
+	if strings.Contains(lines[4], "orphan") {
+		t.Errorf("expected a root version not to be an orphan, got %q", lines[4])
+	}
+
+	if got := versionGraph(versions, nil, 2); len(got) != 2 {
+		t.Errorf("expected a limit of 2 to draw 2 versions, got %q", got)
+	}
+}
+
+func TestLogCommandAuthorFilter(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "authored.prompt")
+	os.WriteFile(promptPath, []byte("V1"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/authored.prompt"})
+
+	for i, author := range []string{"alice", "bob", "alice"} {
+		t.Setenv(authorEnvVar, author)
+		os.WriteFile(promptPath, []byte(fmt.Sprintf("V%d", i+1)), 0644)
+		commitMessage = fmt.Sprintf("Version %d by %s", i+1, author)
+		runCommit(&cobra.Command{}, []string{})
+	}
+
+	for _, prompt := range []string{"authored", ""} {
+		logPrompt = prompt
+		logLimit = 10
+		logAuthor = "alice"
+		jsonOut = true
+
+		output := captureStdout(t, func() {
+			if err := runLog(&cobra.Command{}, []string{}); err != nil {
+				t.Fatalf("runLog failed: %v", err)
+			}
+		})
+		logAuthor = ""
+		jsonOut = false
+
+		var entries []logEntry
+		if err := json.Unmarshal([]byte(output), &entries); err != nil {
+			t.Fatalf("failed to parse log output %q: %v", output, err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("prompt %q: expected 2 entries by alice, got %d", prompt, len(entries))
+		}
+		for _, e := range entries {
+			if e.CreatedBy != "alice" {
+				t.Errorf("prompt %q: expected only alice's versions, got %s", prompt, e.CreatedBy)
+			}
+		}
+	}
+}
+
+func TestShowCommandDiffFrom(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "reviewed.prompt")
+	os.WriteFile(promptPath, []byte("Intro\nHello v1"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/reviewed.prompt"})
+	commitMessage = "v1"
+	runCommit(&cobra.Command{}, []string{})
+	os.WriteFile(promptPath, []byte("Intro\nHello v2"), 0644)
+	commitMessage = "v2"
+	runCommit(&cobra.Command{}, []string{})
+
+	defer func() { showDiffFrom, showVersion = "", "" }()
+
+	for _, ref := range []string{showDiffFromParent, "1.0.0"} {
+		showVersion = ""
+		showDiffFrom = ref
+		output := captureStdout(t, func() {
+			if err := runShow(&cobra.Command{}, []string{"reviewed"}); err != nil {
+				t.Fatalf("runShow failed: %v", err)
+			}
+		})
+
+		for _, want := range []string{"Content:", "Hello v2", "--- reviewed@1.0.0", "+++ reviewed@1.0.1", "-Hello v1", "+Hello v2"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("ref %q: expected output to contain %q, got:\n%s", ref, want, output)
+			}
+		}
+	}
+
+	// The first version has no parent to diff against
+	showVersion = "1.0.0"
+	showDiffFrom = showDiffFromParent
+	output := captureStdout(t, func() {
+		if err := runShow(&cobra.Command{}, []string{"reviewed"}); err != nil {
+			t.Fatalf("runShow failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "No parent version") {
+		t.Errorf("expected no-parent notice, got:\n%s", output)
+	}
+
+	// The ref is the flag's value, not a second prompt argument
+	showDiffFrom = ""
+	if err := showCmd.ParseFlags([]string{"reviewed", "--diff-from", "1.0.0"}); err != nil {
+		t.Fatalf("ParseFlags failed: %v", err)
+	}
+	if err := showCmd.ValidateArgs(showCmd.Flags().Args()); err != nil || showDiffFrom != "1.0.0" {
+		t.Errorf("expected --diff-from 1.0.0 to take the ref, got %q (%v)", showDiffFrom, err)
+	}
+}
+
+func TestListCommandSummary(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		addTestPrompt(t, tmpDir, name, "Hello "+name)
+	}
+
+	output := captureStdout(t, func() {
+		if err := runList(&cobra.Command{}, []string{}); err != nil {
+			t.Fatalf("runList failed: %v", err)
+		}
+	})
+	if !strings.HasPrefix(output, "Found 3 prompt(s):") {
+		t.Errorf("expected the header line, got:\n%s", output)
+	}
+	listed := 0
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "  ") && strings.Contains(line, "@") {
+			listed++
+		}
+	}
+	if listed != 3 {
+		t.Errorf("expected 3 listed prompts, got %d:\n%s", listed, output)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(output), fmt.Sprintf("%d prompt(s)", listed)) {
+		t.Errorf("expected trailing summary matching listed count, got:\n%s", output)
+	}
+
+	// JSON output has no summary line
+	jsonOut = true
+	defer func() { jsonOut = false }()
+	output = captureStdout(t, func() { runList(&cobra.Command{}, []string{}) })
+	var items []listItem
+	if err := json.Unmarshal([]byte(output), &items); err != nil {
+		t.Fatalf("expected only JSON under --json, got %q: %v", output, err)
+	}
+	if len(items) != 3 {
+		t.Errorf("expected 3 items, got %d", len(items))
+	}
+}
+
+func TestJSONOutputForLogShowList(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() { jsonOut, logPrompt, showVersion = false, "", "" }()
+
+	jsonOut = true
+	runJSON := func(run func(*cobra.Command, []string) error, args ...string) string {
+		t.Helper()
+		return captureStdout(t, func() {
+			if err := run(&cobra.Command{}, args); err != nil {
+				t.Fatalf("command failed: %v", err)
+			}
+		})
+	}
+
+	// An empty project still produces JSON
+	for name, run := range map[string]func(*cobra.Command, []string) error{"list": runList, "log": runLog} {
+		if got := strings.TrimSpace(runJSON(run)); got != "[]" {
+			t.Errorf("expected %s --json on an empty project to print [], got %q", name, got)
+		}
+	}
+
+	addTestPrompt(t, tmpDir, "greeting", `---
+name: greeting
+description: Greets a user
 ---
-Hello {{.name}}!
-`)
-	commitMessage = "Initial commit"
+Hello {{name}}!`)
+	jsonOut = false
+	commitMessage = "Initial"
 	runCommit(&cobra.Command{}, []string{})
+	os.WriteFile(filepath.Join(tmpDir, "prompts", "greeting.prompt"), []byte("---\nname: greeting\ndescription: Greets a user\n---\nHi {{name}}!"), 0644)
+	commitMessage = "Shorter"
+	runCommit(&cobra.Command{}, []string{})
+	runTag(&cobra.Command{}, []string{"greeting", "prod", "1.0.0"})
+	jsonOut = true
 
-	// Create a test suite with multiple tests
-	createTestSuite(t, tmpDir, "filtered", `
-name: filtered-tests
-prompt: filtered
-tests:
-  - name: basic-hello
-    inputs:
-      name: Alice
-    assertions:
-      - type: not_empty
-  - name: basic-world
-    inputs:
-      name: Bob
-    assertions:
-      - type: not_empty
-  - name: advanced-check
-    inputs:
-      name: Charlie
-    assertions:
-      - type: not_empty
-`)
+	var listed []listItem
+	if err := json.Unmarshal([]byte(runJSON(runList)), &listed); err != nil {
+		t.Fatalf("failed to decode list --json: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Name != "greeting" || listed[0].Description != "Greets a user" ||
+		listed[0].Version != "1.0.1" || !reflect.DeepEqual(listed[0].Tags, []string{"prod"}) {
+		t.Errorf("unexpected list --json output: %+v", listed)
+	}
 
-	// Reset and set filter
-	testFilter = "basic"
-	testVersion = ""
-	testOutput = ""
-	testLive = false
-	testWatch = false
+	showVersion = "1.0.0"
+	var shown showOutput
+	if err := json.Unmarshal([]byte(runJSON(runShow, "greeting")), &shown); err != nil {
+		t.Fatalf("failed to decode show --json: %v", err)
+	}
+	if shown.Name != "greeting" || shown.Version != "1.0.0" || shown.Description != "Greets a user" ||
+		!reflect.DeepEqual(shown.Tags, []string{"prod"}) || !strings.Contains(shown.Content, "Hello {{name}}!") {
+		t.Errorf("unexpected show --json output: %+v", shown)
+	}
+
+	for _, args := range [][]string{{"greeting"}, {}} {
+		var entries []logEntry
+		if err := json.Unmarshal([]byte(runJSON(runLog, args...)), &entries); err != nil {
+			t.Fatalf("failed to decode log %v --json: %v", args, err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("expected 2 log entries for %v, got %+v", args, entries)
+		}
+		if entries[0].PromptName != "greeting" || entries[0].Version != "1.0.1" || entries[0].CommitMessage != "Shorter" || entries[0].Tags != nil {
+			t.Errorf("unexpected newest log entry for %v: %+v", args, entries[0])
+		}
+		if entries[1].Version != "1.0.0" || !reflect.DeepEqual(entries[1].Tags, []string{"prod"}) {
+			t.Errorf("expected 1.0.0 to carry its prod tag for %v, got %+v", args, entries[1])
+		}
+	}
+}
+
+func TestCompletionFunctions(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	addTestPrompt(t, tmpDir, "greeting", "---\nname: greeting\ndescription: Greets a user\n---\nHello")
+	addTestPrompt(t, tmpDir, "grader", "Grade this")
+	addTestPrompt(t, tmpDir, "summarizer", "Summarize this")
+	commitMessage = "Initial"
+	runCommit(&cobra.Command{}, []string{})
+	os.WriteFile(filepath.Join(tmpDir, "prompts", "greeting.prompt"), []byte("---\nname: greeting\ndescription: Greets a user\n---\nHi"), 0644)
+	commitMessage = "Shorter\n\nLonger explanation"
+	runCommit(&cobra.Command{}, []string{})
+	runTag(&cobra.Command{}, []string{"greeting", "prod", "1.0.0"})
+
+	complete := func(cmd *cobra.Command, args []string, toComplete string) []cobra.Completion {
+		t.Helper()
+		got, directive := cmd.ValidArgsFunction(cmd, args, toComplete)
+		if directive != cobra.ShellCompDirectiveNoFileComp {
+			t.Errorf("%s %v: expected file completion to be off, got directive %d", cmd.Name(), args, directive)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name       string
+		cmd        *cobra.Command
+		args       []string
+		toComplete string
+		want       []cobra.Completion
+	}{
+		{"prompt names", showCmd, nil, "", []cobra.Completion{"grader", "greeting\tGreets a user", "summarizer"}},
+		{"prompt name prefix", checkoutCmd, nil, "gre", []cobra.Completion{"greeting\tGreets a user"}},
+		{"checkout refs", checkoutCmd, []string{"greeting"}, "", []cobra.Completion{"1.0.1\tShorter", "1.0.0\tInitial", "prod\ttag → 1.0.0"}},
+		{"diff second ref", diffCmd, []string{"greeting", "1.0.0"}, "p", []cobra.Completion{"prod\ttag → 1.0.0"}},
+		{"tag names", tagCmd, []string{"greeting"}, "", []cobra.Completion{"prod\ttag → 1.0.0"}},
+		{"tag versions", tagCmd, []string{"greeting", "staging"}, "", []cobra.Completion{"1.0.1\tShorter", "1.0.0\tInitial"}},
+		{"too many args", showCmd, []string{"greeting"}, "", nil},
+		{"unknown prompt", checkoutCmd, []string{"missing"}, "", nil},
+	}
+	for _, tt := range tests {
+		if got := complete(tt.cmd, tt.args, tt.toComplete); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	versionFlag, ok := showCmd.GetFlagCompletionFunc("version")
+	if !ok {
+		t.Fatal("expected show --version to have a completion function")
+	}
+	if got, _ := versionFlag(showCmd, []string{"greeting"}, "1.0.0"); !reflect.DeepEqual(got, []cobra.Completion{"1.0.0\tInitial"}) {
+		t.Errorf("show --version completions = %q", got)
+	}
+
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		var buf bytes.Buffer
+		c := &cobra.Command{}
+		c.SetOut(&buf)
+		if err := runCompletion(c, []string{shell}); err != nil {
+			t.Fatalf("completion %s failed: %v", shell, err)
+		}
+		if !strings.Contains(buf.String(), "promptsmith") {
+			t.Errorf("expected a %s completion script for promptsmith", shell)
+		}
+	}
+	if err := runCompletion(&cobra.Command{}, []string{"tcsh"}); err == nil {
+		t.Error("expected an unsupported shell to fail")
+	}
+}
+
+// ============================================================================
+// Diff Command Integration Tests
+// ============================================================================
+
+func TestDiffCommand(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Create and commit a prompt
+	promptPath := filepath.Join(tmpDir, "prompts", "difftest.prompt")
+	os.WriteFile(promptPath, []byte("Line 1\nLine 2\nLine 3"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/difftest.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Modify the file
+	os.WriteFile(promptPath, []byte("Line 1\nModified Line 2\nLine 3"), 0644)
+
+	// Run diff (working vs latest)
+	err := runDiff(&cobra.Command{}, []string{"difftest"})
+	if err != nil {
+		t.Fatalf("runDiff failed: %v", err)
+	}
+}
+
+func TestDiffCommandTwoVersions(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "twover.prompt")
+
+	// Create v1
+	os.WriteFile(promptPath, []byte("Version 1 content"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/twover.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Create v2
+	os.WriteFile(promptPath, []byte("Version 2 content"), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Diff between two versions
+	err := runDiff(&cobra.Command{}, []string{"twover", "1.0.0", "1.0.1"})
+	if err != nil {
+		t.Fatalf("runDiff failed: %v", err)
+	}
+}
+
+func TestDiffCommandWordDiff(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() { diffWordDiff = false }()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "worddiff.prompt")
+
+	os.WriteFile(promptPath, []byte("Summarize the article in three sentences."), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/worddiff.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	os.WriteFile(promptPath, []byte("Summarize the article in two sentences."), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+
+	diffWordDiff = true
+	out := captureStdout(t, func() {
+		if err := runDiff(&cobra.Command{}, []string{"worddiff", "1.0.0", "1.0.1"}); err != nil {
+			t.Fatalf("runDiff failed: %v", err)
+		}
+	})
+
+	if !strings.Contains(out, "-Summarize the article in three sentences.") {
+		t.Errorf("expected removed line in output, got:\n%s", out)
+	}
+	if !strings.Contains(out, "+Summarize the article in two sentences.") {
+		t.Errorf("expected added line in output, got:\n%s", out)
+	}
+}
+
+func TestDiffCommandJSON(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() { jsonOut = false }()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "jsondiff.prompt")
+
+	os.WriteFile(promptPath, []byte("line 1\nline 2\nline 3"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/jsondiff.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	os.WriteFile(promptPath, []byte("line 1\nline two\nline 3\nline 4"), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+
+	jsonOut = true
+	out := captureStdout(t, func() {
+		if err := runDiff(&cobra.Command{}, []string{"jsondiff", "1.0.0", "1.0.1"}); err != nil {
+			t.Fatalf("runDiff failed: %v", err)
+		}
+	})
+
+	var result struct {
+		Prompt   string `json:"prompt"`
+		Version1 string `json:"version1"`
+		Version2 string `json:"version2"`
+		From     string `json:"from"`
+		To       string `json:"to"`
+		Hunks    []struct {
+			OldStart int      `json:"old_start"`
+			Start    int      `json:"start"`
+			Lines    []string `json:"lines"`
+		} `json:"hunks"`
+		Stats struct {
+			Insertions int `json:"insertions"`
+			Deletions  int `json:"deletions"`
+		} `json:"stats"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out)
+	}
+
+	if result.Prompt != "jsondiff" || result.From != "jsondiff@1.0.0" || result.To != "jsondiff@1.0.1" {
+		t.Errorf("unexpected header: %+v", result)
+	}
+	// The field names from before from/to and start were added still work
+	if result.Version1 != result.From || result.Version2 != result.To {
+		t.Errorf("expected version1/version2 to match from/to, got %+v", result)
+	}
+	if len(result.Hunks) != 1 {
+		t.Fatalf("expected 1 hunk, got %d", len(result.Hunks))
+	}
+	if result.Hunks[0].Start != 1 || result.Hunks[0].OldStart != 1 {
+		t.Errorf("expected hunk start and old_start 1, got %+v", result.Hunks[0])
+	}
+	wantLines := []string{" line 1", "-line 2", "+line two", " line 3", "+line 4"}
+	if !reflect.DeepEqual(result.Hunks[0].Lines, wantLines) {
+		t.Errorf("hunk lines = %q, want %q", result.Hunks[0].Lines, wantLines)
+	}
+	if result.Stats.Insertions != 2 || result.Stats.Deletions != 1 {
+		t.Errorf("expected 2 insertions and 1 deletion, got %+v", result.Stats)
+	}
+}
+
+func TestDiffCommandIgnoreWhitespace(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() {
+		jsonOut = false
+		diffIgnoreWhitespace = false
+		diffIgnoreBlankLines = false
+	}()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "reformat.prompt")
+	os.WriteFile(promptPath, []byte("Summarize the text.\nRules:\n- be brief\n- be kind"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/reformat.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Only indentation, spacing and blank lines change
+	os.WriteFile(promptPath, []byte("Summarize  the text.\n\nRules:\n    - be brief\t\n    - be kind\n"), 0644)
+
+	hunks := func() []diff.Hunk {
+		t.Helper()
+		jsonOut = true
+		out := captureStdout(t, func() {
+			if err := runDiff(&cobra.Command{}, []string{"reformat"}); err != nil {
+				t.Fatalf("runDiff failed: %v", err)
+			}
+		})
+		var result diffOutput
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			t.Fatalf("invalid JSON output: %v\n%s", err, out)
+		}
+		return result.Hunks
+	}
+
+	if len(hunks()) == 0 {
+		t.Fatal("expected the reformatting to show without the flags")
+	}
+
+	diffIgnoreWhitespace = true
+	diffIgnoreBlankLines = true
+	if h := hunks(); len(h) != 0 {
+		t.Errorf("expected no hunks for a whitespace-only change, got %+v", h)
+	}
+
+	// A real change still shows
+	os.WriteFile(promptPath, []byte("Summarize  the text.\n\nRules:\n    - be very brief\n    - be kind\n"), 0644)
+	h := hunks()
+	if len(h) != 1 || !reflect.DeepEqual(h[0].Lines, []string{" Summarize the text.", " Rules:", "-- be brief", "+- be very brief", " - be kind"}) {
+		t.Errorf("expected one hunk with the wording change, got %+v", h)
+	}
+}
+
+func TestDiffCommandJSONNoDifferences(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() { jsonOut = false }()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "jsonsame.prompt")
+	os.WriteFile(promptPath, []byte("Same"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/jsonsame.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	jsonOut = true
+	out := captureStdout(t, func() {
+		if err := runDiff(&cobra.Command{}, []string{"jsonsame"}); err != nil {
+			t.Fatalf("runDiff failed: %v", err)
+		}
+	})
+
+	var result diffOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out)
+	}
+	if len(result.Hunks) != 0 || result.Stats.Insertions != 0 || result.Stats.Deletions != 0 {
+		t.Errorf("expected empty diff, got %+v", result)
+	}
+}
+
+func TestDiffCommandHeadNotation(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "headtest.prompt")
+
+	// Create multiple versions
+	os.WriteFile(promptPath, []byte("V1"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/headtest.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	os.WriteFile(promptPath, []byte("V2"), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+
+	os.WriteFile(promptPath, []byte("V3"), 0644)
+	commitMessage = "V3"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Diff using HEAD notation
+	err := runDiff(&cobra.Command{}, []string{"headtest", "HEAD~2", "HEAD"})
+	if err != nil {
+		t.Fatalf("runDiff failed: %v", err)
+	}
+}
+
+func TestDiffCommandNoDifferences(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "nodiff.prompt")
+	os.WriteFile(promptPath, []byte("Same content"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/nodiff.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	// File unchanged, diff should show "No differences"
+	err := runDiff(&cobra.Command{}, []string{"nodiff"})
+	if err != nil {
+		t.Fatalf("runDiff failed: %v", err)
+	}
+}
+
+func TestDiffCommandExitCode(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() { diffExitCode = false }()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "gated.prompt")
+	os.WriteFile(promptPath, []byte("Same content"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/gated.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	diffExitCode = true
+	if code := exitCode(runDiff(&cobra.Command{}, []string{"gated"})); code != 0 {
+		t.Errorf("expected exit code 0 for unchanged prompt, got %d", code)
+	}
+
+	os.WriteFile(promptPath, []byte("Changed content"), 0644)
+	var err error
+	captureStdout(t, func() {
+		err = runDiff(&cobra.Command{}, []string{"gated"})
+	})
+	if code := exitCode(err); code != 1 {
+		t.Errorf("expected exit code 1 for changed prompt, got %d (%v)", code, err)
+	}
+
+	// Without the flag, differences are not an error
+	diffExitCode = false
+	captureStdout(t, func() {
+		err = runDiff(&cobra.Command{}, []string{"gated"})
+	})
+	if err != nil {
+		t.Errorf("expected no error without --exit-code, got %v", err)
+	}
+}
+
+func TestDiffCommandCrossPrompt(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	addTestPrompt(t, tmpDir, "summarizer", "Summarize the text.\nKeep it short.")
+	addTestPrompt(t, tmpDir, "summarizer-v2", "Summarize the text.\nUse three bullet points.")
+	commitMessage = "Fork"
+	runCommit(&cobra.Command{}, []string{})
+
+	jsonOut = true
+	defer func() { jsonOut = false }()
+	var err error
+	out := captureStdout(t, func() {
+		err = runDiff(&cobra.Command{}, []string{"summarizer:HEAD", "summarizer-v2:1.0.0"})
+	})
+	if err != nil {
+		t.Fatalf("runDiff failed: %v", err)
+	}
+
+	var result diffOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("failed to parse output: %v\n%s", err, out)
+	}
+	if result.From != "summarizer@1.0.0" || result.To != "summarizer-v2@1.0.0" {
+		t.Errorf("unexpected labels %q and %q", result.From, result.To)
+	}
+	if len(result.Hunks) != 1 {
+		t.Fatalf("expected 1 hunk, got %d", len(result.Hunks))
+	}
+	want := []string{" Summarize the text.", "-Keep it short.", "+Use three bullet points."}
+	if !reflect.DeepEqual(result.Hunks[0].Lines, want) {
+		t.Errorf("expected lines %q, got %q", want, result.Hunks[0].Lines)
+	}
+	if result.Stats.Insertions != 1 || result.Stats.Deletions != 1 {
+		t.Errorf("unexpected stats %+v", result.Stats)
+	}
+
+	// Both sides need a ref, and each prompt must exist
+	if err := runDiff(&cobra.Command{}, []string{"summarizer:HEAD", "summarizer-v2"}); err == nil {
+		t.Error("expected error when only one side uses <prompt>:<ref>")
+	}
+	err = runDiff(&cobra.Command{}, []string{"summarizer:HEAD", "missing:HEAD"})
+	if err == nil || !strings.Contains(err.Error(), "prompt 'missing' not found") {
+		t.Errorf("expected missing prompt error, got: %v", err)
+	}
+	err = runDiff(&cobra.Command{}, []string{"summarizer:9.9.9", "summarizer-v2:HEAD"})
+	if err == nil || !strings.Contains(err.Error(), "version '9.9.9' not found") {
+		t.Errorf("expected missing version error, got: %v", err)
+	}
+}
+
+func TestDiffCommandPromptNotFound(t *testing.T) {
+	_, cleanup := initTestProject(t)
+	defer cleanup()
+
+	err := runDiff(&cobra.Command{}, []string{"nonexistent"})
+	if err == nil {
+		t.Error("expected error for non-existent prompt")
+	}
+	if !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected 'not found' error, got: %v", err)
+	}
+}
+
+func TestDiffCommandVersionNotFound(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "vernotfound.prompt")
+	os.WriteFile(promptPath, []byte("Content"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/vernotfound.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Try to diff with non-existent version
+	err := runDiff(&cobra.Command{}, []string{"vernotfound", "9.9.9"})
+	if err == nil {
+		t.Error("expected error for non-existent version")
+	}
+}
+
+// ============================================================================
+// Tag Command Integration Tests
+// ============================================================================
+
+func TestTagCommand(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "tagtest.prompt")
+	os.WriteFile(promptPath, []byte("Content"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/tagtest.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Create a tag
+	tagList = false
+	tagDelete = false
+	err := runTag(&cobra.Command{}, []string{"tagtest", "prod"})
+	if err != nil {
+		t.Fatalf("runTag failed: %v", err)
+	}
+
+	// Verify tag was created
+	database, err := db.Open(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer database.Close()
+
+	prompt, _ := database.GetPromptByName("tagtest")
+	tags, _ := database.ListTags(prompt.ID)
+	if len(tags) != 1 {
+		t.Fatalf("expected 1 tag, got %d", len(tags))
+	}
+	if tags[0].Name != "prod" {
+		t.Errorf("expected tag 'prod', got %s", tags[0].Name)
+	}
+}
+
+func TestTagCommandWithVersion(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "tagver.prompt")
+
+	// Create multiple versions
+	os.WriteFile(promptPath, []byte("V1"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/tagver.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	os.WriteFile(promptPath, []byte("V2"), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Tag specific version
+	tagList = false
+	tagDelete = false
+	err := runTag(&cobra.Command{}, []string{"tagver", "stable", "1.0.0"})
+	if err != nil {
+		t.Fatalf("runTag failed: %v", err)
+	}
+
+	// Verify tag points to correct version
+	database, err := db.Open(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer database.Close()
+
+	prompt, _ := database.GetPromptByName("tagver")
+	tags, _ := database.ListTags(prompt.ID)
+	if len(tags) != 1 {
+		t.Fatalf("expected 1 tag, got %d", len(tags))
+	}
+
+	// Get version for tag
+	version, _ := database.GetVersionByID(tags[0].VersionID)
+	if version.Version != "1.0.0" {
+		t.Errorf("expected tag to point to 1.0.0, got %s", version.Version)
+	}
+}
+
+func TestTagCommandList(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "taglist.prompt")
+	os.WriteFile(promptPath, []byte("Content"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/taglist.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Create multiple tags
+	tagList = false
+	tagDelete = false
+	runTag(&cobra.Command{}, []string{"taglist", "prod"})
+	runTag(&cobra.Command{}, []string{"taglist", "staging"})
+
+	// List tags
+	tagList = true
+	err := runTag(&cobra.Command{}, []string{"taglist"})
+	if err != nil {
+		t.Fatalf("runTag --list failed: %v", err)
+	}
+}
+
+func TestTagCommandDelete(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "tagdel.prompt")
+	os.WriteFile(promptPath, []byte("Content"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/tagdel.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Create tag
+	tagList = false
+	tagDelete = false
+	runTag(&cobra.Command{}, []string{"tagdel", "temp"})
+
+	// Delete tag
+	tagDelete = true
+	err := runTag(&cobra.Command{}, []string{"tagdel", "temp"})
+	if err != nil {
+		t.Fatalf("runTag --delete failed: %v", err)
+	}
+
+	// Verify tag was deleted
+	database, err := db.Open(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer database.Close()
+
+	prompt, _ := database.GetPromptByName("tagdel")
+	tags, _ := database.ListTags(prompt.ID)
+	if len(tags) != 0 {
+		t.Errorf("expected 0 tags after deletion, got %d", len(tags))
+	}
+}
+
+func TestTagCommandPromptNotFound(t *testing.T) {
+	_, cleanup := initTestProject(t)
+	defer cleanup()
+
+	tagList = false
+	tagDelete = false
+	err := runTag(&cobra.Command{}, []string{"nonexistent", "tag"})
+	if err == nil {
+		t.Error("expected error for non-existent prompt")
+	}
+}
+
+// ============================================================================
+// Checkout Command Integration Tests
+// ============================================================================
+
+func TestCheckoutCommand(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "checkout.prompt")
+
+	// Create multiple versions
+	os.WriteFile(promptPath, []byte("Version 1 content"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/checkout.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	os.WriteFile(promptPath, []byte("Version 2 content"), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Checkout first version
+	err := runCheckout(&cobra.Command{}, []string{"checkout", "1.0.0"})
+	if err != nil {
+		t.Fatalf("runCheckout failed: %v", err)
+	}
+
+	// Verify file content was restored
+	content, err := os.ReadFile(promptPath)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(content) != "Version 1 content" {
+		t.Errorf("expected 'Version 1 content', got %q", string(content))
+	}
+}
+
+func TestCheckoutCommandByTag(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "checkoutag.prompt")
+
+	// Create versions
+	os.WriteFile(promptPath, []byte("Production content"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/checkoutag.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Tag as prod
+	tagList = false
+	tagDelete = false
+	runTag(&cobra.Command{}, []string{"checkoutag", "prod"})
+
+	// Create another version
+	os.WriteFile(promptPath, []byte("Development content"), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Checkout by tag
+	err := runCheckout(&cobra.Command{}, []string{"checkoutag", "prod"})
+	if err != nil {
+		t.Fatalf("runCheckout failed: %v", err)
+	}
+
+	// Verify file content
+	content, err := os.ReadFile(promptPath)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(content) != "Production content" {
+		t.Errorf("expected 'Production content', got %q", string(content))
+	}
+}
+
+func TestCheckoutCommandHeadNotation(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "checkhead.prompt")
+
+	// Create multiple versions
+	os.WriteFile(promptPath, []byte("V1"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/checkhead.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	os.WriteFile(promptPath, []byte("V2"), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+
+	os.WriteFile(promptPath, []byte("V3"), 0644)
+	commitMessage = "V3"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Checkout HEAD~2 (first version)
+	err := runCheckout(&cobra.Command{}, []string{"checkhead", "HEAD~2"})
+	if err != nil {
+		t.Fatalf("runCheckout failed: %v", err)
+	}
+
+	// Verify file content
+	content, err := os.ReadFile(promptPath)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(content) != "V1" {
+		t.Errorf("expected 'V1', got %q", string(content))
+	}
+}
+
+func TestCheckoutCommandDirtyWorkingFile(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "checkdirty.prompt")
+	os.WriteFile(promptPath, []byte("V1"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/checkdirty.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	os.WriteFile(promptPath, []byte("V2"), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Clean working file
+	if err := runCheckout(&cobra.Command{}, []string{"checkdirty", "1.0.0"}); err != nil {
+		t.Fatalf("expected checkout of a clean file to succeed: %v", err)
+	}
+
+	// Dirty working file without --force
+	os.WriteFile(promptPath, []byte("V2 with local edits"), 0644)
+	err := runCheckout(&cobra.Command{}, []string{"checkdirty", "1.0.0"})
+	if err == nil {
+		t.Fatal("expected checkout over uncommitted changes to fail")
+	}
+	if !strings.Contains(err.Error(), "checkdirty") || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("expected error naming the prompt and --force, got: %v", err)
+	}
+	content, _ := os.ReadFile(promptPath)
+	if string(content) != "V2 with local edits" {
+		t.Errorf("expected local edits to be kept, got %q", content)
+	}
+
+	// Dirty working file with --force
+	checkoutForce = true
+	defer func() { checkoutForce = false }()
+	if err := runCheckout(&cobra.Command{}, []string{"checkdirty", "HEAD"}); err != nil {
+		t.Fatalf("expected --force to overwrite: %v", err)
+	}
+	content, _ = os.ReadFile(promptPath)
+	if string(content) != "V2" {
+		t.Errorf("expected 'V2' after forced checkout, got %q", content)
+	}
+}
+
+func TestCheckoutCommandPromptNotFound(t *testing.T) {
+	_, cleanup := initTestProject(t)
+	defer cleanup()
+
+	err := runCheckout(&cobra.Command{}, []string{"nonexistent", "1.0.0"})
+	if err == nil {
+		t.Error("expected error for non-existent prompt")
+	}
+	if !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected 'not found' error, got: %v", err)
+	}
+}
+
+func TestCheckoutCommandVersionNotFound(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "checkver.prompt")
+	os.WriteFile(promptPath, []byte("Content"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/checkver.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	err := runCheckout(&cobra.Command{}, []string{"checkver", "9.9.9"})
+	if err == nil {
+		t.Error("expected error for non-existent version")
+	}
+}
+
+// ============================================================================
+// Revert Command Integration Tests
+// ============================================================================
+
+func TestRevertCommand(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() { revertMessage = "" }()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "revert.prompt")
+
+	os.WriteFile(promptPath, []byte("Version 1 content"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/revert.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	os.WriteFile(promptPath, []byte("Version 2 content"), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+
+	if err := runRevert(&cobra.Command{}, []string{"revert", "1.0.0"}); err != nil {
+		t.Fatalf("runRevert failed: %v", err)
+	}
+
+	content, err := os.ReadFile(promptPath)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(content) != "Version 1 content" {
+		t.Errorf("expected 'Version 1 content', got %q", string(content))
+	}
+
+	database, err := db.Open(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer database.Close()
+
+	p, _ := database.GetPromptByName("revert")
+	versions, _ := database.ListVersions(p.ID)
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 versions after revert, got %d", len(versions))
+	}
+
+	reverted := versions[0]
+	if reverted.Version != "1.0.2" {
+		t.Errorf("expected new version 1.0.2, got %s", reverted.Version)
+	}
+	if reverted.Content != "Version 1 content" {
+		t.Errorf("expected reverted content, got %q", reverted.Content)
+	}
+	if reverted.CommitMessage != "Revert to 1.0.0" {
+		t.Errorf("expected default commit message, got %q", reverted.CommitMessage)
+	}
+	if reverted.ParentVersionID == nil || *reverted.ParentVersionID != versions[1].ID {
+		t.Errorf("expected parent to be previous latest %s, got %v", versions[1].ID, reverted.ParentVersionID)
+	}
+}
+
+func TestRevertCommandCustomMessage(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() { revertMessage = "" }()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "revertmsg.prompt")
+
+	os.WriteFile(promptPath, []byte("Stable"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/revertmsg.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	os.WriteFile(promptPath, []byte("Broken"), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+
+	revertMessage = "Roll back broken prompt"
+	if err := runRevert(&cobra.Command{}, []string{"revertmsg", "HEAD~1"}); err != nil {
+		t.Fatalf("runRevert failed: %v", err)
+	}
+
+	database, err := db.Open(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer database.Close()
+
+	p, _ := database.GetPromptByName("revertmsg")
+	latest, _ := database.GetLatestVersion(p.ID)
+	if latest.CommitMessage != "Roll back broken prompt" {
+		t.Errorf("expected custom commit message, got %q", latest.CommitMessage)
+	}
+	if latest.Content != "Stable" {
+		t.Errorf("expected 'Stable', got %q", latest.Content)
+	}
+}
+
+func TestRevertCommandUncommittedChanges(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "revertdirty.prompt")
+
+	os.WriteFile(promptPath, []byte("V1"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/revertdirty.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	os.WriteFile(promptPath, []byte("V2"), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+
+	os.WriteFile(promptPath, []byte("work in progress"), 0644)
+
+	err := runRevert(&cobra.Command{}, []string{"revertdirty", "1.0.0"})
+	if err == nil || !strings.Contains(err.Error(), "uncommitted changes") {
+		t.Fatalf("expected uncommitted changes error, got: %v", err)
+	}
+
+	content, _ := os.ReadFile(promptPath)
+	if string(content) != "work in progress" {
+		t.Errorf("expected working file to be untouched, got %q", string(content))
+	}
+}
+
+// ============================================================================
+// Blame Command Tests
+// ============================================================================
+
+func TestBlameVersions(t *testing.T) {
+	chain := []*db.PromptVersion{
+		{Version: "1.0.0", CreatedBy: "alice", CommitMessage: "Initial", Content: "line a\nline b\nline c"},
+		{Version: "1.0.1", CreatedBy: "bob", CommitMessage: "Reword b", Content: "line a\nline B\nline c\nline d"},
+		{Version: "1.0.2", CreatedBy: "carol", CommitMessage: "Add intro", Content: "intro\nline a\nline B\nline d"},
+	}
+
+	lines := blameVersions(chain)
+
+	want := map[string]string{
+		"intro":  "1.0.2",
+		"line a": "1.0.0",
+		"line B": "1.0.1",
+		"line d": "1.0.1",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %d: %+v", len(want), len(lines), lines)
+	}
+	for i, l := range lines {
+		if l.Line != i+1 {
+			t.Errorf("line %d numbered %d", i+1, l.Line)
+		}
+		if want[l.Content] != l.Version {
+			t.Errorf("%q attributed to %s, want %s", l.Content, l.Version, want[l.Content])
+		}
+	}
+	if lines[2].Author != "bob" || lines[2].CommitMessage != "Reword b" {
+		t.Errorf("expected 'line B' by bob with message 'Reword b', got %+v", lines[2])
+	}
+}
+
+func TestBlameVersionsSingleVersion(t *testing.T) {
+	chain := []*db.PromptVersion{
+		{Version: "1.0.0", CreatedBy: "alice", Content: "one\ntwo"},
+	}
+
+	for _, l := range blameVersions(chain) {
+		if l.Version != "1.0.0" || l.Author != "alice" {
+			t.Errorf("expected all lines attributed to 1.0.0 by alice, got %+v", l)
+		}
+	}
+}
+
+func TestBlameCommand(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() { jsonOut = false }()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "blame.prompt")
+
+	os.WriteFile(promptPath, []byte("first\nsecond\nthird"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/blame.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	os.WriteFile(promptPath, []byte("first\nSECOND\nthird"), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+
+	os.WriteFile(promptPath, []byte("first\nSECOND\nthird\nfourth"), 0644)
+	commitMessage = "V3"
+	runCommit(&cobra.Command{}, []string{})
+
+	jsonOut = true
+	out := captureStdout(t, func() {
+		if err := runBlame(&cobra.Command{}, []string{"blame"}); err != nil {
+			t.Fatalf("runBlame failed: %v", err)
+		}
+	})
+
+	var lines []blameLine
+	if err := json.Unmarshal([]byte(out), &lines); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out)
+	}
+
+	got := make([]string, len(lines))
+	for i, l := range lines {
+		got[i] = l.Content + "@" + l.Version
+	}
+	want := []string{"first@1.0.0", "SECOND@1.0.1", "third@1.0.0", "fourth@1.0.2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("attribution = %v, want %v", got, want)
+	}
+}
+
+func TestBlameCommandPromptNotFound(t *testing.T) {
+	_, cleanup := initTestProject(t)
+	defer cleanup()
+
+	err := runBlame(&cobra.Command{}, []string{"nonexistent"})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected 'not found' error, got: %v", err)
+	}
+}
+
+// ============================================================================
+// Verify Command Integration Tests
+// ============================================================================
+
+func TestVerifyCommand(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	addTestPrompt(t, tmpDir, "verified", "Hello")
+
+	// Tracked but never committed
+	if err := runVerify(&cobra.Command{}, []string{}); err == nil {
+		t.Error("expected error for prompt without a committed version")
+	}
+
+	commitMessage = "Initial"
+	runCommit(&cobra.Command{}, []string{})
+
+	if err := runVerify(&cobra.Command{}, []string{}); err != nil {
+		t.Errorf("expected clean project to verify, got: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(tmpDir, "prompts", "verified.prompt"), []byte("Hello again"), 0644)
+
+	err := runVerify(&cobra.Command{}, []string{})
+	if err == nil || !strings.Contains(err.Error(), "1 prompt(s) not committed") {
+		t.Errorf("expected uncommitted changes error, got: %v", err)
+	}
+}
+
+// ============================================================================
+// Test Command Integration Tests
+// ============================================================================
+
+// createTestSuite creates a test suite YAML file for testing
+func createTestSuite(t *testing.T, tmpDir, name, content string) {
+	t.Helper()
+	testsDir := filepath.Join(tmpDir, "tests")
+	suitePath := filepath.Join(testsDir, name+".test.yaml")
+	if err := os.WriteFile(suitePath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test suite file: %v", err)
+	}
+}
+
+func TestTestCommand(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Add and commit a prompt (Go templates use .field syntax)
+	addTestPrompt(t, tmpDir, "greeting", `---
+name: greeting
+description: A greeting prompt
+---
+Hello {{.name}}! Welcome to PromptSmith.
+`)
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Create a test suite
+	createTestSuite(t, tmpDir, "greeting", `
+name: greeting-tests
+prompt: greeting
+tests:
+  - name: basic-test
+    inputs:
+      name: World
+    assertions:
+      - type: not_empty
+      - type: contains
+        value: Hello
+      - type: contains
+        value: World
+`)
+
+	// Reset flags
+	testFilter = ""
+	testVersion = ""
+	testOutput = ""
+	testLive = false
+	testWatch = false
+
+	// Run test command
+	err := runTest(&cobra.Command{}, []string{})
+	if err != nil {
+		t.Fatalf("runTest failed: %v", err)
+	}
+}
+
+func TestReplayCommand(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	addTestPrompt(t, tmpDir, "replayed", "Hello {{.name}}!")
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	database, err := db.Open(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer database.Close()
+	p, _ := database.GetPromptByName("replayed")
+	v1, _ := database.GetLatestVersion(p.ID)
+
+	os.WriteFile(filepath.Join(tmpDir, "prompts", "replayed.prompt"), []byte("Goodbye {{.name}}!"), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+
+	createTestSuite(t, tmpDir, "replayed", `
+name: replayed-tests
+prompt: replayed
+tests:
+  - name: says-hello
+    inputs:
+      name: World
+    assertions:
+      - type: contains
+        value: Hello
+`)
+
+	if err := database.EnsureTestSuite("replayed-tests", p.ID, "replayed-tests", "{}"); err != nil {
+		t.Fatalf("EnsureTestSuite failed: %v", err)
+	}
+
+	// A stored failure at v1 passes on replay, since v1 is what gets tested
+	run, err := database.SaveTestRun("replayed-tests", "", "failed", fmt.Sprintf(
+		`{"suite_name":"replayed-tests","prompt_name":"replayed","version":%q,"results":[{"test_name":"says-hello","passed":false},{"test_name":"dropped","passed":true}]}`,
+		v1.Version))
+	if err != nil {
+		t.Fatalf("SaveTestRun failed: %v", err)
+	}
+
+	jsonOut = true
+	defer func() { jsonOut = false }()
+	out := captureStdout(t, func() {
+		err = runReplay(&cobra.Command{}, []string{run.ID})
+	})
+	if err != nil {
+		t.Fatalf("runReplay failed: %v", err)
+	}
+
+	var result replayOutput
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("failed to parse output: %v\n%s", err, out)
+	}
+	if result.Version != v1.Version {
+		t.Errorf("expected replay of version %s, got %s", v1.Version, result.Version)
+	}
+	want := []replayChange{
+		{TestName: "says-hello", Before: "failed", After: "passed"},
+		{TestName: "dropped", Before: "passed"},
+	}
+	if !reflect.DeepEqual(result.Changes, want) {
+		t.Errorf("expected changes %+v, got %+v", want, result.Changes)
+	}
+
+	// A stored pass against the latest version is now a regression
+	run, _ = database.SaveTestRun("replayed-tests", "", "passed",
+		`{"suite_name":"replayed-tests","prompt_name":"replayed","results":[{"test_name":"says-hello","passed":true}]}`)
+	jsonOut = false
+	out = captureStdout(t, func() {
+		err = runReplay(&cobra.Command{}, []string{run.ID})
+	})
+	if code := exitCode(err); code != 1 {
+		t.Errorf("expected exit code 1 for regression, got %d (%v)", code, err)
+	}
+	if !strings.Contains(out, "says-hello passed → failed") {
+		t.Errorf("expected regression in output, got:\n%s", out)
+	}
+
+	if err := runReplay(&cobra.Command{}, []string{"nonexistent"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
+
+func TestTestCommandWithFilter(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Add and commit a prompt (Go templates use .field syntax)
+	addTestPrompt(t, tmpDir, "filtered", `---
+name: filtered
+---
+Hello {{.name}}!
+`)
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Create a test suite with multiple tests
+	createTestSuite(t, tmpDir, "filtered", `
+name: filtered-tests
+prompt: filtered
+tests:
+  - name: basic-hello
+    inputs:
+      name: Alice
+    assertions:
+      - type: not_empty
+  - name: basic-world
+    inputs:
+      name: Bob
+    assertions:
+      - type: not_empty
+  - name: advanced-check
+    inputs:
+      name: Charlie
+    assertions:
+      - type: not_empty
+`)
+
+	// Reset and set filter
+	testFilter = "basic"
+	testVersion = ""
+	testOutput = ""
+	testLive = false
+	testWatch = false
+
+	// Run test command with filter - should only run "basic" tests
+	err := runTest(&cobra.Command{}, []string{})
+	if err != nil {
+		t.Fatalf("runTest with filter failed: %v", err)
+	}
+}
+
+func TestTestCommandUpdateSnapshotsRespectsSelection(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() {
+		testFilter = ""
+		testOnly = ""
+		testUpdateSnapshots = false
+	}()
+
+	addTestPrompt(t, tmpDir, "snap", "Hello {{.name}}!")
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
+
+	suite := func(name string) string {
+		return `
+name: ` + name + `
+prompt: snap
+tests:
+  - name: fixed-test
+    inputs:
+      name: Alice
+    expected_output: stale
+    assertions:
+      - type: snapshot
+  - name: other-test
+    inputs:
+      name: Bob
+    expected_output: stale
+    assertions:
+      - type: snapshot
+`
+	}
+	createTestSuite(t, tmpDir, "first", suite("first-suite"))
+	createTestSuite(t, tmpDir, "second", suite("second-suite"))
+
+	testVersion = ""
+	testOutput = ""
+	testLive = false
+	testWatch = false
+	testUpdateSnapshots = true
+	testFilter = "fixed"
+	testOnly = "first-suite"
+
+	if err := runTest(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runTest failed: %v", err)
+	}
+
+	snapshots := func(name string) map[string]string {
+		data, err := os.ReadFile(filepath.Join(tmpDir, "tests", name+".test.yaml"))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		var parsed struct {
+			Tests []struct {
+				Name           string `yaml:"name"`
+				ExpectedOutput string `yaml:"expected_output"`
+			} `yaml:"tests"`
+		}
+		if err := yaml.Unmarshal(data, &parsed); err != nil {
+			t.Fatalf("failed to parse %s: %v", name, err)
+		}
+		got := map[string]string{}
+		for _, tc := range parsed.Tests {
+			got[tc.Name] = strings.TrimSpace(tc.ExpectedOutput)
+		}
+		return got
+	}
+
+	first := snapshots("first")
+	if first["fixed-test"] != "Hello Alice!" {
+		t.Errorf("expected the selected test's snapshot to be updated, got %q", first["fixed-test"])
+	}
+	if first["other-test"] != "stale" {
+		t.Errorf("expected a test outside --filter to keep its snapshot, got %q", first["other-test"])
+	}
+	second := snapshots("second")
+	if second["fixed-test"] != "stale" || second["other-test"] != "stale" {
+		t.Errorf("expected a suite outside --only to keep its snapshots, got %v", second)
+	}
+}
+
+func TestTestCommandOnlySuite(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	addTestPrompt(t, tmpDir, "only", `---
+name: only
+---
+Hello {{.name}}!
+`)
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
+
+	createTestSuite(t, tmpDir, "first", `
+name: first-suite
+prompt: only
+tests:
+  - name: first-test
+    assertions:
+      - type: not_empty
+`)
+	createTestSuite(t, tmpDir, "second", `
+name: second-suite
+prompt: only
+tests:
+  - name: second-test
+    assertions:
+      - type: not_empty
+`)
+
+	testFilter = ""
+	testVersion = ""
+	testOutput = ""
+	testLive = false
+	testWatch = false
+	testOnly = "second-suite"
+	defer func() { testOnly = "" }()
+
+	ctx, err := setupTestContext([]string{})
+	if err != nil {
+		t.Fatalf("setupTestContext failed: %v", err)
+	}
+	defer ctx.database.Close()
+
+	if len(ctx.suiteFiles) != 1 {
+		t.Fatalf("expected 1 suite file, got %d", len(ctx.suiteFiles))
+	}
+
+	_, _, _, results := executeTests(ctx)
+	if len(results) != 1 {
+		t.Fatalf("expected 1 suite result, got %d", len(results))
+	}
+	if results[0].SuiteName != "second-suite" {
+		t.Errorf("expected second-suite to run, got %s", results[0].SuiteName)
+	}
+
+	testOnly = "missing-suite"
+	if _, err := setupTestContext([]string{}); err == nil {
+		t.Error("expected error for unknown suite name")
+	}
+}
+
+func TestTestCommandCoverage(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	addTestPrompt(t, tmpDir, "tested", "---\nname: tested\n---\nHello")
+	addTestPrompt(t, tmpDir, "untested", "---\nname: untested\n---\nBye")
+
+	createTestSuite(t, tmpDir, "tested", `
+name: tested-suite
+prompt: tested
+tests:
+  - name: basic
+    assertions:
+      - type: not_empty
+`)
+
+	testFilter = ""
+	testVersion = ""
+	testLive = false
+	testWatch = false
+	testCoverage = true
+	defer func() { testCoverage, testMinCoverage = false, 0 }()
+
+	ctx, err := setupTestContext([]string{})
+	if err != nil {
+		t.Fatalf("setupTestContext failed: %v", err)
+	}
+	report, err := computeTestCoverage(ctx.database, ctx.suiteFiles)
+	ctx.database.Close()
+	if err != nil {
+		t.Fatalf("computeTestCoverage failed: %v", err)
+	}
+
+	if report.Total != 2 || report.Tested != 1 {
+		t.Errorf("expected 1/2 prompts tested, got %d/%d", report.Tested, report.Total)
+	}
+	if len(report.Untested) != 1 || report.Untested[0] != "untested" {
+		t.Errorf("expected untested prompt 'untested', got %v", report.Untested)
+	}
+	if report.Percent != 50 {
+		t.Errorf("expected 50%% coverage, got %.1f", report.Percent)
+	}
+
+	testMinCoverage = 50
+	if err := runTest(&cobra.Command{}, []string{}); err != nil {
+		t.Errorf("expected coverage at threshold to pass, got %v", err)
+	}
+
+	testMinCoverage = 80
+	if err := runTest(&cobra.Command{}, []string{}); err == nil {
+		t.Error("expected error when coverage is below --min-coverage")
+	}
+}
+
+func TestTestCommandWithVersion(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "versioned.prompt")
+
+	// Create v1
+	os.WriteFile(promptPath, []byte("---\nname: versioned\n---\nVersion ONE"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/versioned.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Create v2
+	os.WriteFile(promptPath, []byte("---\nname: versioned\n---\nVersion TWO"), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Create test suite
+	createTestSuite(t, tmpDir, "versioned", `
+name: versioned-tests
+prompt: versioned
+tests:
+  - name: version-test
+    assertions:
+      - type: not_empty
+`)
+
+	// Test against specific version
+	testFilter = ""
+	testVersion = "1.0.0"
+	testOutput = ""
+	testLive = false
+	testWatch = false
+
+	err := runTest(&cobra.Command{}, []string{})
+	if err != nil {
+		t.Fatalf("runTest with version failed: %v", err)
+	}
+}
+
+func TestTestCommandNoSuites(t *testing.T) {
+	_, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Reset flags
+	testFilter = ""
+	testVersion = ""
+	testOutput = ""
+	testLive = false
+	testWatch = false
+
+	// Run test command with no suites - should not error
+	err := runTest(&cobra.Command{}, []string{})
+	if err != nil {
+		t.Fatalf("runTest with no suites failed: %v", err)
+	}
+}
+
+func TestTestCommandWithOutput(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Add and commit a prompt
+	addTestPrompt(t, tmpDir, "output", `---
+name: output
+---
+Hello!
+`)
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Create a test suite
+	createTestSuite(t, tmpDir, "output", `
+name: output-tests
+prompt: output
+tests:
+  - name: output-test
+    assertions:
+      - type: not_empty
+`)
+
+	// Set output file
+	outputPath := filepath.Join(tmpDir, "results.json")
+	testFilter = ""
+	testVersion = ""
+	testOutput = outputPath
+	testLive = false
+	testWatch = false
+
+	// Run test command
+	err := runTest(&cobra.Command{}, []string{})
+	if err != nil {
+		t.Fatalf("runTest with output failed: %v", err)
+	}
+
+	// Verify output file was created
+	if _, err := os.Stat(outputPath); os.IsNotExist(err) {
+		t.Error("expected results.json to be created")
+	}
+}
+
+func TestTestCommandAppendOutput(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	addTestPrompt(t, tmpDir, "history", `---
+name: history
+---
+Hello!
+`)
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
+
+	createTestSuite(t, tmpDir, "history", `
+name: history-tests
+prompt: history
+tests:
+  - name: history-test
+    assertions:
+      - type: not_empty
+`)
+
+	outputPath := filepath.Join(tmpDir, "history.json")
+	testFilter = ""
+	testVersion = ""
+	testOutput = outputPath
+	testLive = false
+	testWatch = false
+	testAppend = true
+	defer func() {
+		testOutput = ""
+		testAppend = false
+	}()
+
+	readReports := func() []testReport {
+		t.Helper()
+		data, err := os.ReadFile(outputPath)
+		if err != nil {
+			t.Fatalf("failed to read output: %v", err)
+		}
+		var reports []testReport
+		if err := json.Unmarshal(data, &reports); err != nil {
+			t.Fatalf("expected a JSON array of reports: %v\n%s", err, data)
+		}
+		return reports
+	}
+
+	for i := 0; i < 2; i++ {
+		captureStdout(t, func() {
+			if err := runTest(&cobra.Command{}, []string{}); err != nil {
+				t.Fatalf("runTest failed: %v", err)
+			}
+		})
+	}
+	reports := readReports()
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 appended reports, got %d", len(reports))
+	}
+	for _, r := range reports {
+		if r.Summary.Passed != 1 {
+			t.Errorf("expected each report to record 1 passed test, got %+v", r.Summary)
+		}
+	}
+
+	// A file written without --append holds one report; appending keeps it
+	testAppend = false
+	captureStdout(t, func() { runTest(&cobra.Command{}, []string{}) })
+	testAppend = true
+	captureStdout(t, func() { runTest(&cobra.Command{}, []string{}) })
+	if reports := readReports(); len(reports) != 2 {
+		t.Errorf("expected the single report to become the first of 2, got %d", len(reports))
+	}
+
+	testOutput = ""
+	if err := runTest(&cobra.Command{}, []string{}); err == nil {
+		t.Error("expected --append without --output to fail")
+	}
+}
+
+func TestTestCommandFormatJSONL(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	addTestPrompt(t, tmpDir, "stream", `---
+name: stream
+---
+Hello {{.name}}!
+`)
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
+
+	createTestSuite(t, tmpDir, "stream", `
+name: stream-tests
+prompt: stream
+tests:
+  - name: passes
+    inputs:
+      name: World
+    assertions:
+      - type: contains
+        value: World
+  - name: fails
+    inputs:
+      name: World
+    assertions:
+      - type: contains
+        value: Goodbye
+  - name: skipped
+    skip: true
+    assertions:
+      - type: not_empty
+`)
+
+	testFilter = ""
+	testVersion = ""
+	testOutput = ""
+	testLive = false
+	testWatch = false
+	testFormat = "jsonl"
+	defer func() { testFormat = "text" }()
+
+	ctx, err := setupTestContext([]string{})
+	if err != nil {
+		t.Fatalf("setupTestContext failed: %v", err)
+	}
+	defer ctx.database.Close()
+
+	output := captureStdout(t, func() {
+		passed, failed, skipped, results := executeTests(ctx)
+		printTestSummary(passed, failed, skipped, results)
+	})
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 JSON lines, got %d:\n%s", len(lines), output)
+	}
+
+	var results []testResultLine
+	for i, line := range lines {
+		var r testResultLine
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v\n%s", i+1, err, line)
+		}
+		results = append(results, r)
+	}
+
+	if results[0].TestName != "passes" || !results[0].Passed {
+		t.Errorf("expected first line to be passing 'passes', got %+v", results[0])
+	}
+	if results[1].TestName != "fails" || results[1].Passed || len(results[1].Failures) == 0 {
+		t.Errorf("expected second line to be failing 'fails', got %+v", results[1])
+	}
+	if results[2].TestName != "skipped" || !results[2].Skipped {
+		t.Errorf("expected third line to be skipped, got %+v", results[2])
+	}
+	for _, r := range results {
+		if r.Suite != "stream-tests" || r.Prompt != "stream" || r.Version != "1.0.0" {
+			t.Errorf("expected suite metadata on every line, got %+v", r)
+		}
+	}
+}
+
+func TestTestCommandFormatJUnit(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	addTestPrompt(t, tmpDir, "junit", `---
+name: junit
+---
+Hello {{.name}}!
+`)
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
+
+	createTestSuite(t, tmpDir, "junit", `
+name: junit-tests
+prompt: junit
+tests:
+  - name: passes
+    inputs:
+      name: World
+    assertions:
+      - type: contains
+        value: World
+  - name: fails
+    inputs:
+      name: World
+    assertions:
+      - type: contains
+        value: Goodbye
+`)
+
+	testFilter = ""
+	testVersion = ""
+	testOutput = ""
+	testLive = false
+	testWatch = false
+	testFormat = "junit"
+	defer func() {
+		testFormat = "text"
+		testOutput = ""
+	}()
+
+	type junitReport struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Name     string `xml:"name,attr"`
+				Failures []struct {
+					Message string `xml:"message,attr"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+
+	ctx, err := setupTestContext([]string{})
+	if err != nil {
+		t.Fatalf("setupTestContext failed: %v", err)
+	}
+	defer ctx.database.Close()
+
+	// Without --output the XML is the only thing on stdout
+	output := captureStdout(t, func() {
+		passed, failed, skipped, results := executeTests(ctx)
+		printTestSummary(passed, failed, skipped, results)
+	})
+
+	var report junitReport
+	if err := xml.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("stdout is not valid JUnit XML: %v\n%s", err, output)
+	}
+	if report.Tests != 2 || report.Failures != 1 {
+		t.Errorf("expected 2 tests and 1 failure, got %d and %d", report.Tests, report.Failures)
+	}
+	if len(report.Suites) != 1 || report.Suites[0].Name != "junit" {
+		t.Fatalf("expected one testsuite named junit, got %+v", report.Suites)
+	}
+	failures := report.Suites[0].Cases[1].Failures
+	if len(failures) != 1 || !strings.Contains(failures[0].Message, "Goodbye") {
+		t.Errorf("expected failure message mentioning Goodbye, got %+v", failures)
+	}
+
+	// With --output the report goes to the file and stdout is the usual summary
+	outputPath := filepath.Join(tmpDir, "report.xml")
+	testOutput = outputPath
+	output = captureStdout(t, func() {
+		passed, failed, skipped, results := executeTests(ctx)
+		printTestSummary(passed, failed, skipped, results)
+	})
+	if !strings.Contains(output, "1 failed") {
+		t.Errorf("expected text summary on stdout, got: %s", output)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("expected report file: %v", err)
+	}
+	report = junitReport{}
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("report file is not valid JUnit XML: %v", err)
+	}
+	if report.Tests != 2 {
+		t.Errorf("expected 2 tests in report file, got %d", report.Tests)
+	}
+}
+
+func TestWatchLoopSerializesRuns(t *testing.T) {
+	events := make(chan fsnotify.Event, 100)
+	errs := make(chan error)
+	started := make(chan struct{}, 10)
+
+	var mu gosync.Mutex
+	runs, inFlight, maxInFlight := 0, 0, 0
+	run := func([]string) {
+		mu.Lock()
+		runs++
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		started <- struct{}{}
+
+		time.Sleep(50 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	}
+	runCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return runs
+	}
+
+	done := make(chan error)
+	go func() { done <- watchLoop(events, errs, 10*time.Millisecond, run) }()
+
+	save := func(n int) {
+		for i := 0; i < n; i++ {
+			events <- fsnotify.Event{Name: "tests/greeting.test.yaml", Op: fsnotify.Write}
+		}
+	}
+
+	// A burst of saves settles into a single run
+	save(20)
+	<-started
+	time.Sleep(200 * time.Millisecond)
+	if got := runCount(); got != 1 {
+		t.Fatalf("expected 1 run for a burst of saves, got %d", got)
+	}
+
+	// Saves made while a run is in flight wait for it and coalesce into one more
+	save(1)
+	<-started
+	save(20)
+	events <- fsnotify.Event{Name: "notes.txt", Op: fsnotify.Write}
+	<-started
+	time.Sleep(200 * time.Millisecond)
+	if got := runCount(); got != 3 {
+		t.Errorf("expected saves during a run to coalesce into 1 more run, got %d runs", got)
+	}
+
+	close(events)
+	if err := <-done; err != nil {
+		t.Errorf("watchLoop returned %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if maxInFlight != 1 {
+		t.Errorf("expected runs never to overlap, got %d at once", maxInFlight)
+	}
+}
+
+func TestTestCommandFormatValidation(t *testing.T) {
+	_, cleanup := initTestProject(t)
+	defer cleanup()
+
+	defer func() {
+		testFormat = "text"
+		jsonOut = false
+	}()
+
+	testFormat = "xml"
+	if _, err := setupTestContext([]string{}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+
+	testFormat = "jsonl"
+	jsonOut = true
+	if _, err := setupTestContext([]string{}); err == nil {
+		t.Error("expected error combining --format jsonl with --json")
+	}
+}
+
+func TestTestCommandPromptNotFound(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Create a test suite for non-existent prompt
+	createTestSuite(t, tmpDir, "missing", `
+name: missing-tests
+prompt: nonexistent
+tests:
+  - name: test
+    assertions:
+      - type: not_empty
+`)
+
+	// Reset flags
+	testFilter = ""
+	testVersion = ""
+	testOutput = ""
+	testLive = false
+	testWatch = false
+
+	// Run test command - should handle missing prompt gracefully
+	err := runTest(&cobra.Command{}, []string{})
+	if err != nil {
+		t.Fatalf("runTest should handle missing prompt gracefully: %v", err)
+	}
+}
+
+// ============================================================================
+// Benchmark Command Integration Tests
+// ============================================================================
+
+// createBenchmarkSuite creates a benchmark suite YAML file for testing
+func createBenchmarkSuite(t *testing.T, tmpDir, name, content string) {
+	t.Helper()
+	benchDir := filepath.Join(tmpDir, "benchmarks")
+	suitePath := filepath.Join(benchDir, name+".bench.yaml")
+	if err := os.WriteFile(suitePath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write benchmark suite file: %v", err)
+	}
+}
+
+func TestWatchLoopDeduplicatesChanges(t *testing.T) {
+	events := make(chan fsnotify.Event)
+	runs := make(chan []string)
+	done := make(chan error)
+	go func() {
+		done <- watchLoop(events, make(chan error), 10*time.Millisecond, func(changed []string) { runs <- changed })
+	}()
+
+	// Repeated saves of the same file, and events the watch ignores
+	for _, e := range []fsnotify.Event{
+		{Name: "/p/prompts/b.prompt", Op: fsnotify.Write},
+		{Name: "/p/benchmarks/a.bench.yaml", Op: fsnotify.Create},
+		{Name: "/p/prompts/b.prompt", Op: fsnotify.Write},
+		{Name: "/p/prompts/b.prompt", Op: fsnotify.Write | fsnotify.Chmod},
+		{Name: "/p/notes.txt", Op: fsnotify.Write},
+		{Name: "/p/prompts/c.prompt", Op: fsnotify.Remove},
+		{Name: "/p/prompts/d.prompt", Op: fsnotify.Chmod},
+	} {
+		events <- e
+	}
+	if got, want := <-runs, []string{"/p/benchmarks/a.bench.yaml", "/p/prompts/b.prompt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("first run changed = %v, want %v", got, want)
+	}
+
+	// The next run only sees what changed after the previous one
+	events <- fsnotify.Event{Name: "/p/prompts/d.prompt", Op: fsnotify.Write}
+	if got, want := <-runs, []string{"/p/prompts/d.prompt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("second run changed = %v, want %v", got, want)
+	}
+
+	close(events)
+	if err := <-done; err != nil {
+		t.Errorf("watchLoop returned %v", err)
+	}
+}
+
+func TestAffectedBenchmarkSuites(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	addTestPrompt(t, tmpDir, "greeting", "Hello {{name}}")
+	addTestPrompt(t, tmpDir, "summarizer", "Summarize {{text}}")
+	createBenchmarkSuite(t, tmpDir, "greeting", "name: greeting-bench\nprompt: greeting\nmodels: [gpt-4o]\n")
+	createBenchmarkSuite(t, tmpDir, "summarizer", "name: summarizer-bench\nprompt: summarizer\nmodels: [gpt-4o]\n")
+	createBenchmarkSuite(t, tmpDir, "broken", "prompt: [not a name\n")
+
+	database, err := db.Open(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer database.Close()
+
+	suites, err := findBenchmarkSuites(tmpDir)
+	if err != nil {
+		t.Fatalf("findBenchmarkSuites failed: %v", err)
+	}
+	targets := benchmarkWatchTargets(database, tmpDir, suites)
+
+	benchPath := func(name string) string { return filepath.Join(tmpDir, "benchmarks", name+".bench.yaml") }
+	promptPath := func(name string) string { return filepath.Join(tmpDir, "prompts", name+".prompt") }
+	tests := []struct {
+		name    string
+		changed []string
+		want    []string
+	}{
+		{"prompt file", []string{promptPath("greeting")}, []string{benchPath("greeting")}},
+		{"suite file", []string{benchPath("summarizer")}, []string{benchPath("summarizer")}},
+		{"prompt and suite", []string{promptPath("greeting"), benchPath("summarizer")}, []string{benchPath("greeting"), benchPath("summarizer")}},
+		{"unparseable suite", []string{benchPath("broken")}, []string{benchPath("broken")}},
+		{"unrelated prompt", []string{promptPath("other")}, nil},
+	}
+	for _, tt := range tests {
+		if got := affectedBenchmarkSuites(targets, tt.changed); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: affected = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBenchmarkRenamedPrompt(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	aliasHintOutput = io.Discard
+	defer func() { aliasHintOutput = os.Stderr }()
+
+	addTestPrompt(t, tmpDir, "summarizer", "Summarize {{text}}")
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+	createBenchmarkSuite(t, tmpDir, "summarizer", "name: summarizer-bench\nprompt: summarizer\nmodels: [gpt-4o]\n")
+	if err := runRename(&cobra.Command{}, []string{"summarizer", "digest"}); err != nil {
+		t.Fatalf("runRename failed: %v", err)
+	}
+
+	database, err := db.Open(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	defer database.Close()
+
+	suitePath := filepath.Join(tmpDir, "benchmarks", "summarizer.bench.yaml")
+	targets := benchmarkWatchTargets(database, tmpDir, []string{suitePath})
+	if want := filepath.Join(tmpDir, "prompts", "digest.prompt"); len(targets) != 1 || targets[0].prompt != want {
+		t.Errorf("expected the suite to watch %s, got %+v", want, targets)
+	}
+
+	suite, err := benchmark.ParseSuiteFile(suitePath)
+	if err != nil {
+		t.Fatalf("ParseSuiteFile failed: %v", err)
+	}
+	saved, err := saveBenchmarkResult(database, suite, &benchmark.BenchmarkResult{SuiteName: suite.Name, PromptName: suite.Prompt})
+	if err != nil {
+		t.Fatalf("saveBenchmarkResult failed for a suite naming an alias: %v", err)
+	}
+
+	// With no runs of a suite called summarizer, the prompt's latest run is found
+	latest, err := latestBenchmarkRun(database, "summarizer")
+	if err != nil || latest == nil || latest.ID != saved.ID {
+		t.Errorf("expected the latest run by the old prompt name to be %s, got %+v (err %v)", saved.ID, latest, err)
+	}
+}
+
+func TestBenchmarkWatchFlagValidation(t *testing.T) {
+	_, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() { benchWatch, benchLive, benchFormat, benchOutput = false, false, "text", "" }()
+
+	benchWatch, benchLive = false, true
+	if err := runBenchmark(&cobra.Command{}, []string{}); err == nil || !strings.Contains(err.Error(), "requires --watch") {
+		t.Errorf("expected --watch-live without --watch to fail, got %v", err)
+	}
+
+	benchWatch, benchLive, benchFormat = true, false, "csv"
+	if err := runBenchmark(&cobra.Command{}, []string{}); err == nil || !strings.Contains(err.Error(), "--watch") {
+		t.Errorf("expected --format csv with --watch to fail, got %v", err)
+	}
+}
+
+func TestBenchmarkCommandNoSuites(t *testing.T) {
+	_, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Reset flags
+	benchModels = ""
+	benchRuns = 0
+	benchVersion = ""
+	benchOutput = ""
+
+	// Run benchmark command with no suites - should not error
+	err := runBenchmark(&cobra.Command{}, []string{})
+	if err != nil {
+		t.Fatalf("runBenchmark with no suites failed: %v", err)
+	}
+}
+
+func TestBenchmarkCommandSuiteDiscovery(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Add and commit a prompt
+	addTestPrompt(t, tmpDir, "benchable", `---
+name: benchable
+---
+Hello!
+`)
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Create a benchmark suite
+	createBenchmarkSuite(t, tmpDir, "benchable", `
+name: benchable-benchmark
+prompt: benchable
+models:
+  - gpt-4o-mini
+runs_per_model: 1
+`)
+
+	// Reset flags
+	benchModels = ""
+	benchRuns = 0
+	benchVersion = ""
+	benchOutput = ""
+
+	// Run benchmark command
+	// Note: This will fail gracefully since we don't have API keys
+	err := runBenchmark(&cobra.Command{}, []string{})
+	if err != nil {
+		t.Fatalf("runBenchmark failed: %v", err)
+	}
+}
+
+func TestBenchmarkCommandModelOverride(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Add and commit a prompt
+	addTestPrompt(t, tmpDir, "override", `---
+name: override
+---
+Hello!
+`)
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Create a benchmark suite
+	createBenchmarkSuite(t, tmpDir, "override", `
+name: override-benchmark
+prompt: override
+models:
+  - gpt-4o
+runs_per_model: 1
+`)
+
+	// Override models via flag
+	benchModels = "gpt-4o-mini"
+	benchRuns = 0
+	benchVersion = ""
+	benchOutput = ""
+
+	// Run benchmark command
+	err := runBenchmark(&cobra.Command{}, []string{})
+	if err != nil {
+		t.Fatalf("runBenchmark with model override failed: %v", err)
+	}
+}
+
+func TestBenchmarkCommandRunsOverride(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Add and commit a prompt
+	addTestPrompt(t, tmpDir, "runs", `---
+name: runs
+---
+Hello!
+`)
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Create a benchmark suite
+	createBenchmarkSuite(t, tmpDir, "runs", `
+name: runs-benchmark
+prompt: runs
+models:
+  - gpt-4o-mini
+runs_per_model: 5
+`)
+
+	// Override runs via flag
+	benchModels = ""
+	benchRuns = 2
+	benchVersion = ""
+	benchOutput = ""
+
+	// Run benchmark command
+	err := runBenchmark(&cobra.Command{}, []string{})
+	if err != nil {
+		t.Fatalf("runBenchmark with runs override failed: %v", err)
+	}
+}
+
+func TestBenchmarkCommandVersionOverride(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	promptPath := filepath.Join(tmpDir, "prompts", "version.prompt")
+
+	// Create v1
+	os.WriteFile(promptPath, []byte("---\nname: version\n---\nV1"), 0644)
+	runAdd(&cobra.Command{}, []string{"prompts/version.prompt"})
+	commitMessage = "V1"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Create v2
+	os.WriteFile(promptPath, []byte("---\nname: version\n---\nV2"), 0644)
+	commitMessage = "V2"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Create a benchmark suite
+	createBenchmarkSuite(t, tmpDir, "version", `
+name: version-benchmark
+prompt: version
+models:
+  - gpt-4o-mini
+runs_per_model: 1
+`)
+
+	// Override version via flag
+	benchModels = ""
+	benchRuns = 0
+	benchVersion = "1.0.0"
+	benchOutput = ""
+
+	// Run benchmark command
+	err := runBenchmark(&cobra.Command{}, []string{})
+	if err != nil {
+		t.Fatalf("runBenchmark with version override failed: %v", err)
+	}
+}
+
+func TestBenchmarkCommandPromptNotFound(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Create a benchmark suite for non-existent prompt
+	createBenchmarkSuite(t, tmpDir, "missing", `
+name: missing-benchmark
+prompt: nonexistent
+models:
+  - gpt-4o-mini
+runs_per_model: 1
+`)
+
+	// Reset flags
+	benchModels = ""
+	benchRuns = 0
+	benchVersion = ""
+	benchOutput = ""
+
+	// Run benchmark command - should handle missing prompt gracefully
+	err := runBenchmark(&cobra.Command{}, []string{})
+	if err != nil {
+		t.Fatalf("runBenchmark should handle missing prompt gracefully: %v", err)
+	}
+}
+
+// ============================================================================
+// Generate Command Integration Tests
+// ============================================================================
+
+func TestGenerateCommandPromptNotFound(t *testing.T) {
+	_, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Reset flags
+	genCount = 3
+	genGoal = ""
+	genModel = "gpt-4o-mini"
+	genType = "variations"
+	genOutput = ""
+	genVersion = ""
+
+	// Run generate command with non-existent prompt
+	err := runGenerate(&cobra.Command{}, []string{"nonexistent"})
+	if err == nil {
+		t.Error("expected error for non-existent prompt")
+	}
+	if !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected 'not found' error, got: %v", err)
+	}
+}
+
+func TestGenerateCommandVersionNotFound(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Add and commit a prompt
+	addTestPrompt(t, tmpDir, "genver", `---
+name: genver
+---
+Hello!
+`)
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Reset flags with non-existent version
+	genCount = 3
+	genGoal = ""
+	genModel = "gpt-4o-mini"
+	genType = "variations"
+	genOutput = ""
+	genVersion = "9.9.9"
+
+	// Run generate command
+	err := runGenerate(&cobra.Command{}, []string{"genver"})
+	if err == nil {
+		t.Error("expected error for non-existent version")
+	}
+	if !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected 'not found' error, got: %v", err)
+	}
+}
+
+func TestGenerateCommandNoVersion(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Add a prompt but don't commit
+	addTestPrompt(t, tmpDir, "nover", `Hello!`)
+
+	// Reset flags
+	genCount = 3
+	genGoal = ""
+	genModel = "gpt-4o-mini"
+	genType = "variations"
+	genOutput = ""
+	genVersion = ""
+
+	// Run generate command - should fail because no versions exist
+	err := runGenerate(&cobra.Command{}, []string{"nover"})
+	if err == nil {
+		t.Error("expected error when no versions exist")
+	}
+	if !strings.Contains(err.Error(), "no versions found") {
+		t.Errorf("expected 'no versions found' error, got: %v", err)
+	}
+}
+
+func TestGenerateCommandInvalidType(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Add and commit a prompt
+	addTestPrompt(t, tmpDir, "invalidtype", `Hello!`)
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
+
+	// Reset flags with invalid type
+	genCount = 3
+	genGoal = ""
+	genModel = "gpt-4o-mini"
+	genType = "invalid_type"
+	genOutput = ""
+	genVersion = ""
+
+	// Run generate command - should fail (either due to invalid type or missing API key)
+	err := runGenerate(&cobra.Command{}, []string{"invalidtype"})
+	if err == nil {
+		t.Error("expected error for invalid generation type or missing API key")
+	}
+	// The error could be about missing API key (checked first) or unknown generation type
+	// Both are valid failures - the command should not succeed
+}
+
+func TestGenerateCommandValidTypes(t *testing.T) {
+	// Test that valid generation types are accepted (they'll fail at API level but type parsing works)
+	validTypes := []string{"variations", "compress", "expand", "rephrase"}
+
+	for _, genTypeVal := range validTypes {
+		t.Run(genTypeVal, func(t *testing.T) {
+			tmpDir, cleanup := initTestProject(t)
+			defer cleanup()
+
+			// Add and commit a prompt
+			addTestPrompt(t, tmpDir, "typtest", `Hello!`)
+			commitMessage = "Initial commit"
+			runCommit(&cobra.Command{}, []string{})
+
+			// Reset flags
+			genCount = 1
+			genGoal = ""
+			genModel = "gpt-4o-mini"
+			genType = genTypeVal
+			genOutput = ""
+			genVersion = ""
+
+			// Run generate command - will fail at API level but type should be valid
+			err := runGenerate(&cobra.Command{}, []string{"typtest"})
+			// Error should NOT be about unknown generation type
+			if err != nil && strings.Contains(err.Error(), "unknown generation type") {
+				t.Errorf("type '%s' should be valid but got: %v", genTypeVal, err)
+			}
+		})
+	}
+}
+
+// ============================================================================
+// Config Command Integration Tests
+// ============================================================================
+
+func TestConfigCommandListAll(t *testing.T) {
+	_, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Run config command with no args - lists all config
+	err := runConfig(&cobra.Command{}, []string{})
+	if err != nil {
+		t.Fatalf("runConfig (list all) failed: %v", err)
+	}
+}
+
+func TestConfigCommandGetValue(t *testing.T) {
+	_, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Get a specific value
+	err := runConfig(&cobra.Command{}, []string{"project.name"})
+	if err != nil {
+		t.Fatalf("runConfig (get project.name) failed: %v", err)
+	}
+}
+
+func TestConfigCommandSetValue(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Set a value
+	err := runConfig(&cobra.Command{}, []string{"defaults.model", "claude-sonnet"})
+	if err != nil {
+		t.Fatalf("runConfig (set defaults.model) failed: %v", err)
+	}
+
+	// Verify it was set
+	config, err := loadConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if config.Defaults.Model != "claude-sonnet" {
+		t.Errorf("expected defaults.model 'claude-sonnet', got %q", config.Defaults.Model)
+	}
+}
+
+func TestConfigCommandModelAliases(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	if err := runConfig(&cobra.Command{}, []string{"model_aliases.fast", "gpt-4o-mini"}); err != nil {
+		t.Fatalf("runConfig (set model_aliases.fast) failed: %v", err)
+	}
+
+	config, err := loadConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if value, err := getConfigValue(config, "model_aliases.fast"); err != nil || value != "gpt-4o-mini" {
+		t.Errorf("expected model_aliases.fast 'gpt-4o-mini', got %q (%v)", value, err)
+	}
+	if _, err := getConfigValue(config, "model_aliases.slow"); err == nil {
+		t.Error("expected error for unknown alias")
+	}
+
+	// --model fast resolves to the mapped model wherever a registry is used
+	registry := benchmark.NewProviderRegistry()
+	if err := applyModelAliases(tmpDir, registry); err != nil {
+		t.Fatalf("applyModelAliases failed: %v", err)
+	}
+	if got := registry.ResolveModel("fast"); got != "gpt-4o-mini" {
+		t.Errorf("expected fast to resolve to gpt-4o-mini, got %q", got)
+	}
+
+	// Aliases may stand in for defaults.model, but must map to a known model
+	config.Defaults.Model = "fast"
+	config.ModelAliases["broken"] = "not-a-model"
+	problems := validateConfig(tmpDir, config)
+	if len(problems) != 1 || !strings.Contains(problems[0], "model_aliases.broken") {
+		t.Errorf("expected only the broken alias to be reported, got %v", problems)
+	}
+
+	if err := runConfig(&cobra.Command{}, []string{"model_aliases.fast", ""}); err != nil {
+		t.Fatalf("runConfig (unset model_aliases.fast) failed: %v", err)
+	}
+	config, _ = loadConfig(tmpDir)
+	if _, ok := config.ModelAliases["fast"]; ok {
+		t.Error("expected empty value to remove the alias")
+	}
+}
+
+func TestConfigCommandSetTemperature(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Set temperature
+	err := runConfig(&cobra.Command{}, []string{"defaults.temperature", "0.5"})
+	if err != nil {
+		t.Fatalf("runConfig (set temperature) failed: %v", err)
+	}
+
+	// Verify it was set
+	config, err := loadConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if config.Defaults.Temperature != 0.5 {
+		t.Errorf("expected temperature 0.5, got %f", config.Defaults.Temperature)
+	}
+}
+
+func TestConfigCommandSetProjectName(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Set project name
+	err := runConfig(&cobra.Command{}, []string{"project.name", "new-project-name"})
+	if err != nil {
+		t.Fatalf("runConfig (set project.name) failed: %v", err)
+	}
+
+	// Verify it was set
+	config, err := loadConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if config.Project.Name != "new-project-name" {
+		t.Errorf("expected project.name 'new-project-name', got %q", config.Project.Name)
+	}
+}
+
+func TestConfigCommandSetDirs(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	tests := []struct {
+		key   string
+		value string
+		check func(*Config) string
+	}{
+		{"prompts_dir", "./custom-prompts", func(c *Config) string { return c.PromptsDir }},
+		{"tests_dir", "./custom-tests", func(c *Config) string { return c.TestsDir }},
+		{"benchmarks_dir", "./custom-benchmarks", func(c *Config) string { return c.BenchmarksDir }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			err := runConfig(&cobra.Command{}, []string{tt.key, tt.value})
+			if err != nil {
+				t.Fatalf("runConfig (set %s) failed: %v", tt.key, err)
+			}
+
+			config, err := loadConfig(tmpDir)
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+			if tt.check(config) != tt.value {
+				t.Errorf("expected %s %q, got %q", tt.key, tt.value, tt.check(config))
+			}
+		})
+	}
+}
+
+func TestConfigCommandGetUnknownKey(t *testing.T) {
+	_, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Try to get an unknown key
+	err := runConfig(&cobra.Command{}, []string{"unknown.key"})
+	if err == nil {
+		t.Error("expected error for unknown config key")
+	}
+	if !strings.Contains(err.Error(), "unknown") {
+		t.Errorf("expected 'unknown' in error, got: %v", err)
+	}
+}
+
+func TestConfigCommandSetUnknownKey(t *testing.T) {
+	_, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Try to set an unknown key
+	err := runConfig(&cobra.Command{}, []string{"unknown.key", "value"})
+	if err == nil {
+		t.Error("expected error for unknown config key")
+	}
+	if !strings.Contains(err.Error(), "unknown") {
+		t.Errorf("expected 'unknown' in error, got: %v", err)
+	}
+}
+
+func TestConfigCommandSetReadOnlyKey(t *testing.T) {
+	_, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Try to set version (read-only)
+	err := runConfig(&cobra.Command{}, []string{"version", "2"})
+	if err == nil {
+		t.Error("expected error when setting read-only version")
+	}
+
+	// Try to set project.id (read-only)
+	err = runConfig(&cobra.Command{}, []string{"project.id", "new-id"})
+	if err == nil {
+		t.Error("expected error when setting read-only project.id")
+	}
+}
+
+func TestConfigCommandInvalidTemperature(t *testing.T) {
+	_, cleanup := initTestProject(t)
+	defer cleanup()
+
+	// Try to set invalid temperature
+	err := runConfig(&cobra.Command{}, []string{"defaults.temperature", "invalid"})
+	if err == nil {
+		t.Error("expected error for invalid temperature")
+	}
+
+	// Try to set temperature out of range
+	err = runConfig(&cobra.Command{}, []string{"defaults.temperature", "3.0"})
+	if err == nil {
+		t.Error("expected error for temperature > 2")
+	}
+
+	err = runConfig(&cobra.Command{}, []string{"defaults.temperature", "-1"})
+	if err == nil {
+		t.Error("expected error for negative temperature")
+	}
+}
+
+func TestBenchmarkCompareRequiresTwoArgs(t *testing.T) {
+	cmd := benchmarkCompareCmd
+	// No args
+	err := cmd.Args(cmd, []string{})
+	if err == nil {
+		t.Error("expected error with no args")
+	}
+	// One arg
+	err = cmd.Args(cmd, []string{"a.json"})
+	if err == nil {
+		t.Error("expected error with one arg")
+	}
+	// Two args is valid
+	err = cmd.Args(cmd, []string{"a.json", "b.json"})
+	if err != nil {
+		t.Errorf("expected no error with two args, got: %v", err)
+	}
+	// Three args name a suite and two stored runs
+	err = cmd.Args(cmd, []string{"suite", "run-a", "run-b"})
+	if err != nil {
+		t.Errorf("expected no error with three args, got: %v", err)
+	}
+	err = cmd.Args(cmd, []string{"a", "b", "c", "d"})
+	if err == nil {
+		t.Error("expected error with four args")
+	}
+}
+
+func TestBenchmarkCompareReadFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	results1 := `[{"suite_name":"test","prompt_name":"p","version":"1.0","models":[{"model":"gpt-4o","runs":5,"errors":0,"error_rate":0,"latency_p50_ms":200,"latency_p99_ms":400,"total_tokens_avg":150,"cost_per_request":0.005}],"duration_ms":1000}]`
+	results2 := `[{"suite_name":"test","prompt_name":"p","version":"1.1","models":[{"model":"gpt-4o","runs":5,"errors":1,"error_rate":0.2,"latency_p50_ms":180,"latency_p99_ms":350,"total_tokens_avg":140,"cost_per_request":0.004}],"duration_ms":900}]`
+
+	f1 := filepath.Join(dir, "baseline.json")
+	f2 := filepath.Join(dir, "latest.json")
+	os.WriteFile(f1, []byte(results1), 0644)
+	os.WriteFile(f2, []byte(results2), 0644)
 
-	// Run test command with filter - should only run "basic" tests
-	err := runTest(&cobra.Command{}, []string{})
+	err := runBenchmarkCompare(&cobra.Command{}, []string{f1, f2})
 	if err != nil {
-		t.Fatalf("runTest with filter failed: %v", err)
+		t.Errorf("expected no error, got: %v", err)
 	}
 }
 
-func TestTestCommandWithVersion(t *testing.T) {
+func TestBenchmarkCompareMissingFile(t *testing.T) {
+	err := runBenchmarkCompare(&cobra.Command{}, []string{"/nonexistent/a.json", "/nonexistent/b.json"})
+	if err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestBenchmarkCompareStoredRuns(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	promptPath := filepath.Join(tmpDir, "prompts", "versioned.prompt")
-
-	// Create v1
-	os.WriteFile(promptPath, []byte("---\nname: versioned\n---\nVersion ONE"), 0644)
-	runAdd(&cobra.Command{}, []string{"prompts/versioned.prompt"})
-	commitMessage = "V1"
-	runCommit(&cobra.Command{}, []string{})
-
-	// Create v2
-	os.WriteFile(promptPath, []byte("---\nname: versioned\n---\nVersion TWO"), 0644)
-	commitMessage = "V2"
-	runCommit(&cobra.Command{}, []string{})
-
-	// Create test suite
-	createTestSuite(t, tmpDir, "versioned", `
-name: versioned-tests
-prompt: versioned
-tests:
-  - name: version-test
-    assertions:
-      - type: not_empty
+	addTestPrompt(t, tmpDir, "compared", `---
+name: compared
+---
+Hello!
 `)
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
 
-	// Test against specific version
-	testFilter = ""
-	testVersion = "1.0.0"
-	testOutput = ""
-	testLive = false
-	testWatch = false
-
-	err := runTest(&cobra.Command{}, []string{})
+	database, err := db.Open(tmpDir)
 	if err != nil {
-		t.Fatalf("runTest with version failed: %v", err)
+		t.Fatalf("failed to open db: %v", err)
 	}
-}
+	p, _ := database.GetPromptByName("compared")
+	if err := database.EnsureBenchmark("compared-benchmark", p.ID, "{}"); err != nil {
+		t.Fatalf("EnsureBenchmark failed: %v", err)
+	}
+	before, err := database.SaveBenchmarkRun("compared-benchmark", "", `{"models":[
+		{"model":"gpt-4o","latency_p50_ms":200,"cost_per_request":0.0100},
+		{"model":"gpt-4o-mini","latency_p50_ms":400,"cost_per_request":0.0005}
+	]}`)
+	if err != nil {
+		t.Fatalf("SaveBenchmarkRun failed: %v", err)
+	}
+	after, err := database.SaveBenchmarkRun("compared-benchmark", "", `{"models":[
+		{"model":"gpt-4o","latency_p50_ms":260,"cost_per_request":0.0100},
+		{"model":"claude-3-haiku","latency_p50_ms":300,"cost_per_request":0.0010}
+	]}`)
+	if err != nil {
+		t.Fatalf("SaveBenchmarkRun failed: %v", err)
+	}
+	database.Close()
 
-func TestTestCommandNoSuites(t *testing.T) {
-	_, cleanup := initTestProject(t)
-	defer cleanup()
+	jsonOut = true
+	defer func() {
+		jsonOut = false
+		benchCompareThreshold = 10
+	}()
+
+	compare := func(threshold float64) benchmarkCompareOutput {
+		t.Helper()
+		benchCompareThreshold = threshold
+		output := captureStdout(t, func() {
+			if err := runBenchmarkCompare(&cobra.Command{}, []string{"compared-benchmark", before.ID, after.ID}); err != nil {
+				t.Fatalf("runBenchmarkCompare failed: %v", err)
+			}
+		})
+		var out benchmarkCompareOutput
+		if err := json.Unmarshal([]byte(output), &out); err != nil {
+			t.Fatalf("failed to parse output: %v\n%s", err, output)
+		}
+		return out
+	}
 
-	// Reset flags
-	testFilter = ""
-	testVersion = ""
-	testOutput = ""
-	testLive = false
-	testWatch = false
+	// p50 latency grew 30%
+	out := compare(10)
+	if !out.Regressed {
+		t.Error("expected a regression at 10%")
+	}
+	statuses := map[string]string{}
+	for _, m := range out.Models {
+		statuses[m.Model] = m.Status
+	}
+	want := map[string]string{"gpt-4o": "compared", "claude-3-haiku": "added", "gpt-4o-mini": "removed"}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("expected model statuses %v, got %v", want, statuses)
+	}
 
-	// Run test command with no suites - should not error
-	err := runTest(&cobra.Command{}, []string{})
-	if err != nil {
-		t.Fatalf("runTest with no suites failed: %v", err)
+	if out := compare(50); out.Regressed {
+		t.Error("expected no regression at 50%")
+	}
+
+	if err := runBenchmarkCompare(&cobra.Command{}, []string{"other-benchmark", before.ID, after.ID}); err == nil {
+		t.Error("expected error for runs of another benchmark")
+	}
+	if err := runBenchmarkCompare(&cobra.Command{}, []string{"compared-benchmark", before.ID, "missing"}); err == nil {
+		t.Error("expected error for an unknown run")
 	}
 }
 
-func TestTestCommandWithOutput(t *testing.T) {
+func TestBenchmarkCommandStoresRun(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	// Add and commit a prompt
-	addTestPrompt(t, tmpDir, "output", `---
-name: output
+	addTestPrompt(t, tmpDir, "stored", `---
+name: stored
 ---
 Hello!
 `)
 	commitMessage = "Initial commit"
 	runCommit(&cobra.Command{}, []string{})
 
-	// Create a test suite
-	createTestSuite(t, tmpDir, "output", `
-name: output-tests
-prompt: output
-tests:
-  - name: output-test
-    assertions:
-      - type: not_empty
+	createBenchmarkSuite(t, tmpDir, "stored", `
+name: stored-benchmark
+prompt: stored
+models:
+  - gpt-4o-mini
+runs_per_model: 1
 `)
 
-	// Set output file
-	outputPath := filepath.Join(tmpDir, "results.json")
-	testFilter = ""
-	testVersion = ""
-	testOutput = outputPath
-	testLive = false
-	testWatch = false
+	benchModels = ""
+	benchRuns = 0
+	benchVersion = ""
+	benchOutput = ""
 
-	// Run test command
-	err := runTest(&cobra.Command{}, []string{})
+	if err := runBenchmark(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runBenchmark failed: %v", err)
+	}
+
+	database, err := db.Open(tmpDir)
 	if err != nil {
-		t.Fatalf("runTest with output failed: %v", err)
+		t.Fatalf("failed to open db: %v", err)
 	}
+	defer database.Close()
 
-	// Verify output file was created
-	if _, err := os.Stat(outputPath); os.IsNotExist(err) {
-		t.Error("expected results.json to be created")
+	runs, err := database.ListBenchmarkRuns("stored-benchmark")
+	if err != nil {
+		t.Fatalf("ListBenchmarkRuns failed: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 stored run, got %d", len(runs))
+	}
+	if runs[0].VersionID == "" {
+		t.Error("expected stored run to record the benchmarked version")
 	}
 }
 
-func TestTestCommandPromptNotFound(t *testing.T) {
+func TestBenchmarkCommandAppendOutput(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	// Create a test suite for non-existent prompt
-	createTestSuite(t, tmpDir, "missing", `
-name: missing-tests
-prompt: nonexistent
-tests:
-  - name: test
-    assertions:
-      - type: not_empty
+	addTestPrompt(t, tmpDir, "appended", `---
+name: appended
+---
+Hello!
 `)
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
 
-	// Reset flags
-	testFilter = ""
-	testVersion = ""
-	testOutput = ""
-	testLive = false
-	testWatch = false
-
-	// Run test command - should handle missing prompt gracefully
-	err := runTest(&cobra.Command{}, []string{})
-	if err != nil {
-		t.Fatalf("runTest should handle missing prompt gracefully: %v", err)
-	}
-}
-
-// ============================================================================
-// Benchmark Command Integration Tests
-// ============================================================================
-
-// createBenchmarkSuite creates a benchmark suite YAML file for testing
-func createBenchmarkSuite(t *testing.T, tmpDir, name, content string) {
-	t.Helper()
-	benchDir := filepath.Join(tmpDir, "benchmarks")
-	suitePath := filepath.Join(benchDir, name+".bench.yaml")
-	if err := os.WriteFile(suitePath, []byte(content), 0644); err != nil {
-		t.Fatalf("failed to write benchmark suite file: %v", err)
-	}
-}
-
-func TestBenchmarkCommandNoSuites(t *testing.T) {
-	_, cleanup := initTestProject(t)
-	defer cleanup()
+	createBenchmarkSuite(t, tmpDir, "appended", `
+name: appended-benchmark
+prompt: appended
+models:
+  - gpt-4o-mini
+runs_per_model: 1
+`)
 
-	// Reset flags
+	outputPath := filepath.Join(tmpDir, "history.json")
 	benchModels = ""
 	benchRuns = 0
 	benchVersion = ""
-	benchOutput = ""
+	benchOutput = outputPath
+	benchAppend = true
+	defer func() {
+		benchOutput = ""
+		benchAppend = false
+	}()
+
+	for i := 0; i < 2; i++ {
+		captureStdout(t, func() {
+			if err := runBenchmark(&cobra.Command{}, []string{}); err != nil {
+				t.Fatalf("runBenchmark failed: %v", err)
+			}
+		})
+	}
 
-	// Run benchmark command with no suites - should not error
-	err := runBenchmark(&cobra.Command{}, []string{})
+	data, err := os.ReadFile(outputPath)
 	if err != nil {
-		t.Fatalf("runBenchmark with no suites failed: %v", err)
+		t.Fatalf("failed to read output: %v", err)
+	}
+	var results []*benchmark.BenchmarkResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		t.Fatalf("expected a JSON array of results: %v\n%s", err, data)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 appended results, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.SuiteName != "appended-benchmark" {
+			t.Errorf("expected results of appended-benchmark, got %s", r.SuiteName)
+		}
 	}
 }
 
-func TestBenchmarkCommandSuiteDiscovery(t *testing.T) {
+func TestBenchmarkCommandFormatCSV(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	// Add and commit a prompt
-	addTestPrompt(t, tmpDir, "benchable", `---
-name: benchable
+	addTestPrompt(t, tmpDir, "spreadsheet", `---
+name: spreadsheet
 ---
 Hello!
 `)
 	commitMessage = "Initial commit"
 	runCommit(&cobra.Command{}, []string{})
 
-	// Create a benchmark suite
-	createBenchmarkSuite(t, tmpDir, "benchable", `
-name: benchable-benchmark
-prompt: benchable
+	createBenchmarkSuite(t, tmpDir, "spreadsheet", `
+name: spreadsheet-benchmark
+prompt: spreadsheet
 models:
   - gpt-4o-mini
-runs_per_model: 1
+runs_per_model: 2
 `)
 
-	// Reset flags
+	outputPath := filepath.Join(tmpDir, "runs.csv")
 	benchModels = ""
 	benchRuns = 0
 	benchVersion = ""
-	benchOutput = ""
+	benchOutput = outputPath
+	benchFormat = "csv"
+	defer func() {
+		benchOutput = ""
+		benchFormat = "text"
+	}()
+
+	captureStdout(t, func() {
+		if err := runBenchmark(&cobra.Command{}, []string{}); err != nil {
+			t.Fatalf("runBenchmark failed: %v", err)
+		}
+	})
 
-	// Run benchmark command
-	// Note: This will fail gracefully since we don't have API keys
-	err := runBenchmark(&cobra.Command{}, []string{})
+	data, err := os.ReadFile(outputPath)
 	if err != nil {
-		t.Fatalf("runBenchmark failed: %v", err)
+		t.Fatalf("failed to read output: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "suite,started_at,model,temperature,run_index,") {
+		t.Fatalf("expected a header and a row per run, got:\n%s", data)
+	}
+	if !strings.HasPrefix(lines[2], "spreadsheet-benchmark,") || !strings.Contains(lines[2], ",gpt-4o-mini,,2,") {
+		t.Errorf("unexpected second run row: %s", lines[2])
+	}
+
+	jsonOut = true
+	defer func() { jsonOut = false }()
+	if err := runBenchmark(&cobra.Command{}, []string{}); err == nil {
+		t.Error("expected --format csv with --json to fail")
 	}
 }
 
-func TestBenchmarkCommandModelOverride(t *testing.T) {
+func TestAppendJSONOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+
+	if err := appendJSONOutput(path, json.RawMessage(`{"n":1}`)); err != nil {
+		t.Fatalf("appendJSONOutput failed: %v", err)
+	}
+	if err := appendJSONOutput(path, json.RawMessage(`{"n":2}`), json.RawMessage(`{"n":3}`)); err != nil {
+		t.Fatalf("appendJSONOutput failed: %v", err)
+	}
+	var entries []struct{ N int }
+	data, _ := os.ReadFile(path)
+	if err := json.Unmarshal(data, &entries); err != nil || len(entries) != 3 || entries[2].N != 3 {
+		t.Errorf("expected 3 entries in order, got %s", data)
+	}
+
+	// A single object is converted into the first element
+	os.WriteFile(path, []byte(`{"n":1}`), 0644)
+	if err := appendJSONOutput(path, json.RawMessage(`{"n":2}`)); err != nil {
+		t.Fatalf("appendJSONOutput failed: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if err := json.Unmarshal(data, &entries); err != nil || len(entries) != 2 || entries[0].N != 1 {
+		t.Errorf("expected the object to become the first entry, got %s", data)
+	}
+
+	os.WriteFile(path, []byte(`<testsuites/>`), 0644)
+	if err := appendJSONOutput(path, json.RawMessage(`{"n":1}`)); err == nil {
+		t.Error("expected error appending to a non-JSON file")
+	}
+}
+
+func TestBenchmarkDiffModels(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	// Add and commit a prompt
-	addTestPrompt(t, tmpDir, "override", `---
-name: override
+	addTestPrompt(t, tmpDir, "ranked", `---
+name: ranked
 ---
 Hello!
 `)
 	commitMessage = "Initial commit"
 	runCommit(&cobra.Command{}, []string{})
 
-	// Create a benchmark suite
-	createBenchmarkSuite(t, tmpDir, "override", `
-name: override-benchmark
-prompt: override
-models:
-  - gpt-4o
-runs_per_model: 1
-`)
+	database, err := db.Open(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	p, _ := database.GetPromptByName("ranked")
+	if err := database.EnsureBenchmark("ranked-benchmark", p.ID, "{}"); err != nil {
+		t.Fatalf("EnsureBenchmark failed: %v", err)
+	}
+	results := `{"suite_name":"ranked-benchmark","prompt_name":"ranked","version":"1.0.0","models":[
+		{"model":"gpt-4o","latency_p50_ms":300,"cost_per_request":0.0100},
+		{"model":"gpt-4o-mini","latency_p50_ms":400,"cost_per_request":0.0005},
+		{"model":"claude-3-opus","latency_p50_ms":2000,"cost_per_request":0.0300},
+		{"model":"claude-3-haiku","error_rate":1}
+	]}`
+	if _, err := database.SaveBenchmarkRun("ranked-benchmark", "", results); err != nil {
+		t.Fatalf("SaveBenchmarkRun failed: %v", err)
+	}
+	database.Close()
 
-	// Override models via flag
-	benchModels = "gpt-4o-mini"
-	benchRuns = 0
-	benchVersion = ""
-	benchOutput = ""
+	jsonOut = true
+	defer func() {
+		jsonOut = false
+		rankWeightCost, rankWeightLatency, rankWeightErrors = 0.5, 0.5, 0
+	}()
+
+	rank := func(name string) []string {
+		t.Helper()
+		output := captureStdout(t, func() {
+			if err := runBenchmarkDiffModels(&cobra.Command{}, []string{name}); err != nil {
+				t.Fatalf("runBenchmarkDiffModels failed: %v", err)
+			}
+		})
+		var out diffModelsOutput
+		if err := json.Unmarshal([]byte(output), &out); err != nil {
+			t.Fatalf("failed to parse output: %v\n%s", err, output)
+		}
+		if out.Benchmark != "ranked-benchmark" {
+			t.Errorf("expected benchmark ranked-benchmark, got %s", out.Benchmark)
+		}
+		models := make([]string, len(out.Models))
+		for i, m := range out.Models {
+			models[i] = m.Model
+		}
+		return models
+	}
 
-	// Run benchmark command
-	err := runBenchmark(&cobra.Command{}, []string{})
-	if err != nil {
-		t.Fatalf("runBenchmark with model override failed: %v", err)
+	rankWeightCost, rankWeightLatency, rankWeightErrors = 0.5, 0.5, 0
+	want := []string{"gpt-4o-mini", "gpt-4o", "claude-3-opus"}
+	if got := rank("ranked-benchmark"); !reflect.DeepEqual(got, want) {
+		t.Errorf("balanced ranking: expected %v, got %v", want, got)
+	}
+
+	// Latency only favours the fastest model
+	rankWeightCost, rankWeightLatency = 0, 1
+	want = []string{"gpt-4o", "gpt-4o-mini", "claude-3-opus"}
+	if got := rank("ranked-benchmark"); !reflect.DeepEqual(got, want) {
+		t.Errorf("latency ranking: expected %v, got %v", want, got)
+	}
+
+	// A prompt name resolves to its latest benchmark run
+	if got := rank("ranked"); !reflect.DeepEqual(got, want) {
+		t.Errorf("ranking by prompt: expected %v, got %v", want, got)
+	}
+
+	if err := runBenchmarkDiffModels(&cobra.Command{}, []string{"unknown"}); err == nil {
+		t.Error("expected error when no runs exist")
+	}
+
+	rankWeightCost, rankWeightLatency = 0, 0
+	if err := runBenchmarkDiffModels(&cobra.Command{}, []string{"ranked-benchmark"}); err == nil {
+		t.Error("expected error when all weights are zero")
 	}
 }
 
-func TestBenchmarkCommandRunsOverride(t *testing.T) {
-	tmpDir, cleanup := initTestProject(t)
-	defer cleanup()
+// ============================================================================
+// Sync Command Integration Tests
+// ============================================================================
 
-	// Add and commit a prompt
-	addTestPrompt(t, tmpDir, "runs", `---
-name: runs
----
-Hello!
-`)
-	commitMessage = "Initial commit"
-	runCommit(&cobra.Command{}, []string{})
+// mockSyncServer is an in-process stand-in for the PromptSmith sync API
+type mockSyncServer struct {
+	*httptest.Server
+	loggedOut    bool
+	pushed       *sync.PushRequest
+	pullResponse *sync.PullResponse
+}
 
-	// Create a benchmark suite
-	createBenchmarkSuite(t, tmpDir, "runs", `
-name: runs-benchmark
-prompt: runs
-models:
-  - gpt-4o-mini
-runs_per_model: 5
-`)
+func newMockSyncServer(t *testing.T) *mockSyncServer {
+	t.Helper()
+	m := &mockSyncServer{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["email"] != "dev@example.com" || body["password"] != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(sync.APIError{Code: "unauthorized", Message: "invalid credentials"})
+			return
+		}
+		json.NewEncoder(w).Encode(sync.AuthResponse{
+			Token: "test-token",
+			User:  sync.UserInfo{ID: "u1", Email: "dev@example.com", Name: "Dev"},
+		})
+	})
+	mux.HandleFunc("/api/auth/logout", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		m.loggedOut = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/api/sync/push", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req sync.PushRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		m.pushed = &req
+		json.NewEncoder(w).Encode(sync.PushResponse{Synced: len(req.Versions)})
+	})
+	mux.HandleFunc("/api/sync/pull/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		resp := m.pullResponse
+		if resp == nil {
+			resp = &sync.PullResponse{}
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
 
-	// Override runs via flag
-	benchModels = ""
-	benchRuns = 2
-	benchVersion = ""
-	benchOutput = ""
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
 
-	// Run benchmark command
-	err := runBenchmark(&cobra.Command{}, []string{})
-	if err != nil {
-		t.Fatalf("runBenchmark with runs override failed: %v", err)
+// withStdin replaces os.Stdin with a file containing input for the test
+func withStdin(t *testing.T, input string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, []byte(input), 0600); err != nil {
+		t.Fatalf("failed to write stdin file: %v", err)
 	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open stdin file: %v", err)
+	}
+	original := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() {
+		os.Stdin = original
+		f.Close()
+	})
 }
 
-func TestBenchmarkCommandVersionOverride(t *testing.T) {
+func TestLoginAndLogoutCommands(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
+	t.Setenv(sync.TokenEnvVar, "")
 
-	promptPath := filepath.Join(tmpDir, "prompts", "version.prompt")
+	server := newMockSyncServer(t)
+	withStdin(t, "secret\n")
 
-	// Create v1
-	os.WriteFile(promptPath, []byte("---\nname: version\n---\nV1"), 0644)
-	runAdd(&cobra.Command{}, []string{"prompts/version.prompt"})
-	commitMessage = "V1"
-	runCommit(&cobra.Command{}, []string{})
+	loginToken = ""
+	loginEmail = "dev@example.com"
+	loginRemote = server.URL
+	defer func() { loginEmail, loginRemote = "", "" }()
 
-	// Create v2
-	os.WriteFile(promptPath, []byte("---\nname: version\n---\nV2"), 0644)
-	commitMessage = "V2"
-	runCommit(&cobra.Command{}, []string{})
+	if err := runLogin(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runLogin failed: %v", err)
+	}
 
-	// Create a benchmark suite
-	createBenchmarkSuite(t, tmpDir, "version", `
-name: version-benchmark
-prompt: version
-models:
-  - gpt-4o-mini
-runs_per_model: 1
-`)
+	tokenPath := filepath.Join(tmpDir, db.ConfigDir, sync.TokenFileName)
+	info, err := os.Stat(tokenPath)
+	if err != nil {
+		t.Fatalf("expected token file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected token file mode 0600, got %o", info.Mode().Perm())
+	}
+	data, _ := os.ReadFile(tokenPath)
+	if string(data) != "test-token" {
+		t.Errorf("expected saved token 'test-token', got %q", string(data))
+	}
 
-	// Override version via flag
-	benchModels = ""
-	benchRuns = 0
-	benchVersion = "1.0.0"
-	benchOutput = ""
+	logoutRemote = server.URL
+	defer func() { logoutRemote = "" }()
 
-	// Run benchmark command
-	err := runBenchmark(&cobra.Command{}, []string{})
-	if err != nil {
-		t.Fatalf("runBenchmark with version override failed: %v", err)
+	if err := runLogout(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runLogout failed: %v", err)
+	}
+	if !server.loggedOut {
+		t.Error("expected logout request to reach the server")
+	}
+	if _, err := os.Stat(tokenPath); !os.IsNotExist(err) {
+		t.Error("expected token file to be removed")
 	}
 }
 
-func TestBenchmarkCommandPromptNotFound(t *testing.T) {
+func TestLoginCommandBadCredentials(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
+	t.Setenv(sync.TokenEnvVar, "")
 
-	// Create a benchmark suite for non-existent prompt
-	createBenchmarkSuite(t, tmpDir, "missing", `
-name: missing-benchmark
-prompt: nonexistent
-models:
-  - gpt-4o-mini
-runs_per_model: 1
-`)
+	server := newMockSyncServer(t)
+	withStdin(t, "wrong\n")
 
-	// Reset flags
-	benchModels = ""
-	benchRuns = 0
-	benchVersion = ""
-	benchOutput = ""
+	loginToken = ""
+	loginEmail = "dev@example.com"
+	loginRemote = server.URL
+	defer func() { loginEmail, loginRemote = "", "" }()
 
-	// Run benchmark command - should handle missing prompt gracefully
-	err := runBenchmark(&cobra.Command{}, []string{})
-	if err != nil {
-		t.Fatalf("runBenchmark should handle missing prompt gracefully: %v", err)
+	if err := runLogin(&cobra.Command{}, []string{}); err == nil {
+		t.Fatal("expected login to fail with bad credentials")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, db.ConfigDir, sync.TokenFileName)); !os.IsNotExist(err) {
+		t.Error("expected no token file after failed login")
 	}
 }
 
-// ============================================================================
-// Generate Command Integration Tests
-// ============================================================================
-
-func TestGenerateCommandPromptNotFound(t *testing.T) {
-	_, cleanup := initTestProject(t)
-	defer cleanup()
-
-	// Reset flags
-	genCount = 3
-	genGoal = ""
-	genModel = "gpt-4o-mini"
-	genType = "variations"
-	genOutput = ""
-	genVersion = ""
-
-	// Run generate command with non-existent prompt
-	err := runGenerate(&cobra.Command{}, []string{"nonexistent"})
-	if err == nil {
-		t.Error("expected error for non-existent prompt")
+// configureSync points the project at remote and stores the mock token
+func configureSync(t *testing.T, tmpDir, remote string) {
+	t.Helper()
+	config, err := loadConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
 	}
-	if !strings.Contains(err.Error(), "not found") {
-		t.Errorf("expected 'not found' error, got: %v", err)
+	config.Sync.Remote = remote
+	if err := saveConfig(tmpDir, config); err != nil {
+		t.Fatalf("saveConfig failed: %v", err)
+	}
+	tokenPath := filepath.Join(tmpDir, db.ConfigDir, sync.TokenFileName)
+	if err := os.WriteFile(tokenPath, []byte("test-token"), 0600); err != nil {
+		t.Fatalf("failed to write token: %v", err)
 	}
 }
 
-func TestGenerateCommandVersionNotFound(t *testing.T) {
+func TestPushCommand(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
+	t.Setenv(sync.TokenEnvVar, "")
 
-	// Add and commit a prompt
-	addTestPrompt(t, tmpDir, "genver", `---
-name: genver
----
-Hello!
-`)
-	commitMessage = "Initial commit"
+	server := newMockSyncServer(t)
+	configureSync(t, tmpDir, server.URL)
+
+	addTestPrompt(t, tmpDir, "greeting", "---\nname: greeting\n---\nHello v1")
+	commitMessage = "v1"
+	runCommit(&cobra.Command{}, []string{})
+	os.WriteFile(filepath.Join(tmpDir, "prompts", "greeting.prompt"), []byte("---\nname: greeting\n---\nHello v2"), 0644)
+	commitMessage = "v2"
 	runCommit(&cobra.Command{}, []string{})
 
-	// Reset flags with non-existent version
-	genCount = 3
-	genGoal = ""
-	genModel = "gpt-4o-mini"
-	genType = "variations"
-	genOutput = ""
-	genVersion = "9.9.9"
+	if err := runPush(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runPush failed: %v", err)
+	}
 
-	// Run generate command
-	err := runGenerate(&cobra.Command{}, []string{"genver"})
-	if err == nil {
-		t.Error("expected error for non-existent version")
+	if server.pushed == nil {
+		t.Fatal("expected push request to reach the server")
 	}
-	if !strings.Contains(err.Error(), "not found") {
-		t.Errorf("expected 'not found' error, got: %v", err)
+	if len(server.pushed.Prompts) != 1 || server.pushed.Prompts[0].Name != "greeting" {
+		t.Errorf("expected 1 pushed prompt 'greeting', got %+v", server.pushed.Prompts)
+	}
+	if len(server.pushed.Versions) != 2 {
+		t.Errorf("expected 2 pushed versions, got %d", len(server.pushed.Versions))
 	}
 }
 
-func TestGenerateCommandNoVersion(t *testing.T) {
+func TestPushCommandNotLoggedIn(t *testing.T) {
+	_, cleanup := initTestProject(t)
+	defer cleanup()
+	t.Setenv(sync.TokenEnvVar, "")
+
+	err := runPush(&cobra.Command{}, []string{})
+	if err == nil || !strings.Contains(err.Error(), "not logged in") {
+		t.Errorf("expected not logged in error, got %v", err)
+	}
+}
+
+func TestPullCommand(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
+	t.Setenv(sync.TokenEnvVar, "")
 
-	// Add a prompt but don't commit
-	addTestPrompt(t, tmpDir, "nover", `Hello!`)
+	server := newMockSyncServer(t)
+	configureSync(t, tmpDir, server.URL)
 
-	// Reset flags
-	genCount = 3
-	genGoal = ""
-	genModel = "gpt-4o-mini"
-	genType = "variations"
-	genOutput = ""
-	genVersion = ""
+	now := time.Now().UTC()
+	parentID := "remote-v1"
+	server.pullResponse = &sync.PullResponse{
+		Prompts: []sync.Prompt{
+			{ID: "remote-p1", Name: "remote-prompt", Description: "From the cloud", FilePath: "prompts/remote-prompt.prompt"},
+		},
+		// Newest first, as push sends them
+		Versions: []sync.PromptVersion{
+			{ID: "remote-v2", PromptID: "remote-p1", Version: "1.0.1", Content: "Remote v2", Variables: "[]", Metadata: "{}", ParentVersionID: &parentID, CommitMessage: "second", CreatedAt: now, CreatedBy: "alice"},
+			{ID: "remote-v1", PromptID: "remote-p1", Version: "1.0.0", Content: "Remote v1", Variables: "[]", Metadata: "{}", CommitMessage: "first", CreatedAt: now.Add(-time.Hour), CreatedBy: "alice"},
+		},
+		Tags: []sync.Tag{
+			{ID: "remote-t1", PromptID: "remote-p1", VersionID: "remote-v2", Name: "prod"},
+		},
+	}
 
-	// Run generate command - should fail because no versions exist
-	err := runGenerate(&cobra.Command{}, []string{"nover"})
-	if err == nil {
-		t.Error("expected error when no versions exist")
+	if err := runPull(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runPull failed: %v", err)
 	}
-	if !strings.Contains(err.Error(), "no versions found") {
-		t.Errorf("expected 'no versions found' error, got: %v", err)
+
+	database, err := db.Open(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
 	}
-}
+	defer database.Close()
 
-func TestGenerateCommandInvalidType(t *testing.T) {
-	tmpDir, cleanup := initTestProject(t)
-	defer cleanup()
+	prompt, err := database.GetPromptByName("remote-prompt")
+	if err != nil || prompt == nil {
+		t.Fatalf("expected pulled prompt, got %v (err %v)", prompt, err)
+	}
 
-	// Add and commit a prompt
-	addTestPrompt(t, tmpDir, "invalidtype", `Hello!`)
-	commitMessage = "Initial commit"
-	runCommit(&cobra.Command{}, []string{})
+	v1, _ := database.GetVersionByString(prompt.ID, "1.0.0")
+	v2, _ := database.GetVersionByString(prompt.ID, "1.0.1")
+	if v1 == nil || v2 == nil {
+		t.Fatal("expected both pulled versions to exist locally")
+	}
+	if v2.ParentVersionID == nil || *v2.ParentVersionID != v1.ID {
+		t.Error("expected pulled v2 to be linked to the local v1")
+	}
 
-	// Reset flags with invalid type
-	genCount = 3
-	genGoal = ""
-	genModel = "gpt-4o-mini"
-	genType = "invalid_type"
-	genOutput = ""
-	genVersion = ""
+	tag, _ := database.GetTagByName(prompt.ID, "prod")
+	if tag == nil || tag.VersionID != v2.ID {
+		t.Error("expected prod tag on pulled v2")
+	}
 
-	// Run generate command - should fail (either due to invalid type or missing API key)
-	err := runGenerate(&cobra.Command{}, []string{"invalidtype"})
-	if err == nil {
-		t.Error("expected error for invalid generation type or missing API key")
+	content, err := os.ReadFile(filepath.Join(tmpDir, "prompts", "remote-prompt.prompt"))
+	if err != nil {
+		t.Fatalf("expected prompt file to be written: %v", err)
+	}
+	if string(content) != "Remote v2" {
+		t.Errorf("expected latest content in prompt file, got %q", string(content))
 	}
-	// The error could be about missing API key (checked first) or unknown generation type
-	// Both are valid failures - the command should not succeed
 }
 
-func TestGenerateCommandValidTypes(t *testing.T) {
-	// Test that valid generation types are accepted (they'll fail at API level but type parsing works)
-	validTypes := []string{"variations", "compress", "expand", "rephrase"}
+func TestPullCommandStrategies(t *testing.T) {
+	localContent := "---\nname: greeting\n---\nLocal edit"
+	remoteContent := "---\nname: greeting\n---\nRemote edit"
 
-	for _, genTypeVal := range validTypes {
-		t.Run(genTypeVal, func(t *testing.T) {
+	tests := []struct {
+		strategy   string
+		wantLatest string // Version the prompt is at after the pull
+		wantFile   string
+		wantTheirs bool
+		wantReview bool
+	}{
+		{strategy: "ours", wantLatest: "1.0.0", wantFile: localContent},
+		{strategy: "theirs", wantLatest: "1.0.1", wantFile: remoteContent},
+		{strategy: "manual", wantLatest: "1.0.0", wantFile: localContent, wantTheirs: true, wantReview: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
 			tmpDir, cleanup := initTestProject(t)
 			defer cleanup()
+			t.Setenv(sync.TokenEnvVar, "")
 
-			// Add and commit a prompt
-			addTestPrompt(t, tmpDir, "typtest", `Hello!`)
-			commitMessage = "Initial commit"
-			runCommit(&cobra.Command{}, []string{})
+			server := newMockSyncServer(t)
+			configureSync(t, tmpDir, server.URL)
 
-			// Reset flags
-			genCount = 1
-			genGoal = ""
-			genModel = "gpt-4o-mini"
-			genType = genTypeVal
-			genOutput = ""
-			genVersion = ""
+			addTestPrompt(t, tmpDir, "greeting", localContent)
+			commitMessage = "local"
+			if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+				t.Fatalf("runCommit failed: %v", err)
+			}
 
-			// Run generate command - will fail at API level but type should be valid
-			err := runGenerate(&cobra.Command{}, []string{"typtest"})
-			// Error should NOT be about unknown generation type
-			if err != nil && strings.Contains(err.Error(), "unknown generation type") {
-				t.Errorf("type '%s' should be valid but got: %v", genTypeVal, err)
+			server.pullResponse = &sync.PullResponse{
+				Prompts: []sync.Prompt{
+					{ID: "remote-p1", Name: "greeting", FilePath: "prompts/greeting.prompt"},
+				},
+				Versions: []sync.PromptVersion{
+					{ID: "remote-v1", PromptID: "remote-p1", Version: "1.0.0", Content: remoteContent, Variables: "[]", Metadata: "{}", CreatedBy: "remote-user", CreatedAt: time.Now()},
+				},
+			}
+
+			pullStrategy = tt.strategy
+			pullForce = false
+			defer func() { pullStrategy = "ours" }()
+
+			out := captureStdout(t, func() {
+				if err := runPull(&cobra.Command{}, []string{}); err != nil {
+					t.Fatalf("runPull failed: %v", err)
+				}
+			})
+			if !strings.Contains(out, "greeting@1.0.0") {
+				t.Errorf("expected the divergence to be reported, got:\n%s", out)
+			}
+
+			database, err := db.Open(tmpDir)
+			if err != nil {
+				t.Fatalf("failed to open db: %v", err)
+			}
+			defer database.Close()
+
+			// The local version is never rewritten
+			prompt, _ := database.GetPromptByName("greeting")
+			version, _ := database.GetVersionByString(prompt.ID, "1.0.0")
+			if version.Content != localContent {
+				t.Errorf("1.0.0 content = %q, want the local %q", version.Content, localContent)
+			}
+			latest, _ := database.GetLatestVersion(prompt.ID)
+			if latest.Version != tt.wantLatest {
+				t.Errorf("latest version = %s, want %s", latest.Version, tt.wantLatest)
+			}
+			if tt.strategy == "theirs" {
+				if latest.Content != remoteContent || latest.ParentVersionID == nil || *latest.ParentVersionID != version.ID || latest.CreatedBy != "remote-user" {
+					t.Errorf("expected remote content in a child of 1.0.0, got %+v", latest)
+				}
+			}
+
+			promptPath := filepath.Join(tmpDir, "prompts", "greeting.prompt")
+			data, _ := os.ReadFile(promptPath)
+			if string(data) != tt.wantFile {
+				t.Errorf("file content = %q, want %q", string(data), tt.wantFile)
+			}
+
+			theirs, err := os.ReadFile(promptPath + ".theirs")
+			if tt.wantTheirs {
+				if err != nil || string(theirs) != remoteContent {
+					t.Errorf("expected remote copy in .theirs file, got %q (err %v)", string(theirs), err)
+				}
+			} else if err == nil {
+				t.Error("expected no .theirs file")
 			}
-		})
-	}
-}
 
-// ============================================================================
-// Config Command Integration Tests
-// ============================================================================
+			status := captureStdout(t, func() {
+				statusPorcelain = true
+				defer func() { statusPorcelain = false }()
+				if err := runStatus(&cobra.Command{}, []string{}); err != nil {
+					t.Fatalf("runStatus failed: %v", err)
+				}
+			})
+			if got := strings.Contains(status, "U prompts/greeting.prompt"); got != tt.wantReview {
+				t.Errorf("expected review flag %v in status, got:\n%s", tt.wantReview, status)
+			}
 
-func TestConfigCommandListAll(t *testing.T) {
-	_, cleanup := initTestProject(t)
-	defer cleanup()
+			// Committing after review settles a manual conflict
+			if tt.wantReview {
+				os.WriteFile(promptPath, []byte(localContent+"\nReviewed"), 0644)
+				commitMessage = "reviewed"
+				captureStdout(t, func() {
+					if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+						t.Fatalf("runCommit failed: %v", err)
+					}
+				})
+				if conflicts, _ := database.ListUnresolvedPullConflicts(); len(conflicts) != 0 {
+					t.Errorf("expected the commit to settle the conflict, got %+v", conflicts)
+				}
+			}
 
-	// Run config command with no args - lists all config
-	err := runConfig(&cobra.Command{}, []string{})
-	if err != nil {
-		t.Fatalf("runConfig (list all) failed: %v", err)
+			// A settled divergence is not resolved again on the next pull
+			if tt.strategy != "ours" {
+				versions, _ := database.ListVersions(prompt.ID)
+				out := captureStdout(t, func() {
+					if err := runPull(&cobra.Command{}, []string{}); err != nil {
+						t.Fatalf("runPull failed: %v", err)
+					}
+				})
+				if strings.Contains(out, "Diverged") {
+					t.Errorf("expected a settled divergence to stay quiet, got:\n%s", out)
+				}
+				if again, _ := database.ListVersions(prompt.ID); len(again) != len(versions) {
+					t.Errorf("expected no new versions on a repeat pull, got %d then %d", len(versions), len(again))
+				}
+			}
+		})
 	}
-}
-
-func TestConfigCommandGetValue(t *testing.T) {
-	_, cleanup := initTestProject(t)
-	defer cleanup()
 
-	// Get a specific value
-	err := runConfig(&cobra.Command{}, []string{"project.name"})
-	if err != nil {
-		t.Fatalf("runConfig (get project.name) failed: %v", err)
+	pullStrategy = "bogus"
+	defer func() { pullStrategy = "ours" }()
+	if err := runPull(&cobra.Command{}, []string{}); err == nil {
+		t.Error("expected error for invalid strategy")
 	}
 }
 
-func TestConfigCommandSetValue(t *testing.T) {
+func TestPullTheirsWithLaterRemoteVersions(t *testing.T) {
 	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
+	t.Setenv(sync.TokenEnvVar, "")
 
-	// Set a value
-	err := runConfig(&cobra.Command{}, []string{"defaults.model", "claude-sonnet"})
-	if err != nil {
-		t.Fatalf("runConfig (set defaults.model) failed: %v", err)
-	}
+	server := newMockSyncServer(t)
+	configureSync(t, tmpDir, server.URL)
 
-	// Verify it was set
-	config, err := loadConfig(tmpDir)
-	if err != nil {
-		t.Fatalf("failed to load config: %v", err)
+	localContent := "---\nname: greeting\n---\nLocal edit"
+	addTestPrompt(t, tmpDir, "greeting", localContent)
+	commitMessage = "local"
+	if err := runCommit(&cobra.Command{}, []string{}); err != nil {
+		t.Fatalf("runCommit failed: %v", err)
 	}
-	if config.Defaults.Model != "claude-sonnet" {
-		t.Errorf("expected defaults.model 'claude-sonnet', got %q", config.Defaults.Model)
+
+	// The remote diverged at 1.0.0 and has moved on since
+	base := time.Now()
+	parent1, parent2 := "remote-v1", "remote-v2"
+	server.pullResponse = &sync.PullResponse{
+		Prompts: []sync.Prompt{
+			{ID: "remote-p1", Name: "greeting", FilePath: "prompts/greeting.prompt"},
+		},
+		Versions: []sync.PromptVersion{
+			{ID: "remote-v1", PromptID: "remote-p1", Version: "1.0.0", Content: "Remote 1", Variables: "[]", Metadata: "{}", CreatedAt: base},
+			{ID: "remote-v2", PromptID: "remote-p1", Version: "1.0.1", Content: "Remote 2", Variables: "[]", Metadata: "{}", ParentVersionID: &parent1, CreatedAt: base.Add(time.Second)},
+			{ID: "remote-v3", PromptID: "remote-p1", Version: "1.0.2", Content: "Remote 3", Variables: "[]", Metadata: "{}", ParentVersionID: &parent2, CreatedAt: base.Add(2 * time.Second)},
+		},
 	}
-}
 
-func TestConfigCommandSetTemperature(t *testing.T) {
-	tmpDir, cleanup := initTestProject(t)
-	defer cleanup()
+	pullStrategy = "theirs"
+	pullForce = false
+	defer func() { pullStrategy = "ours" }()
 
-	// Set temperature
-	err := runConfig(&cobra.Command{}, []string{"defaults.temperature", "0.5"})
-	if err != nil {
-		t.Fatalf("runConfig (set temperature) failed: %v", err)
+	out := captureStdout(t, func() {
+		if err := runPull(&cobra.Command{}, []string{}); err != nil {
+			t.Fatalf("runPull failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "greeting@1.0.0: remote content committed as 1.0.3") {
+		t.Errorf("expected the remote content of 1.0.0 numbered past the incoming versions, got:\n%s", out)
+	}
+	if strings.Contains(out, "greeting@1.0.1") || strings.Contains(out, "greeting@1.0.2") {
+		t.Errorf("expected later remote versions to be pulled, not reported as diverged, got:\n%s", out)
 	}
 
-	// Verify it was set
-	config, err := loadConfig(tmpDir)
+	database, err := db.Open(tmpDir)
 	if err != nil {
-		t.Fatalf("failed to load config: %v", err)
+		t.Fatalf("failed to open db: %v", err)
 	}
-	if config.Defaults.Temperature != 0.5 {
-		t.Errorf("expected temperature 0.5, got %f", config.Defaults.Temperature)
+	defer database.Close()
+
+	prompt, _ := database.GetPromptByName("greeting")
+	for version, want := range map[string]string{"1.0.0": localContent, "1.0.1": "Remote 2", "1.0.2": "Remote 3", "1.0.3": "Remote 1"} {
+		v, _ := database.GetVersionByString(prompt.ID, version)
+		if v == nil || v.Content != want {
+			t.Errorf("expected %s to hold %q, got %+v", version, want, v)
+		}
 	}
-}
 
-func TestConfigCommandSetProjectName(t *testing.T) {
-	tmpDir, cleanup := initTestProject(t)
-	defer cleanup()
+	// Nothing is left to resolve on the next pull
+	out = captureStdout(t, func() {
+		if err := runPull(&cobra.Command{}, []string{}); err != nil {
+			t.Fatalf("runPull failed: %v", err)
+		}
+	})
+	if strings.Contains(out, "Diverged") {
+		t.Errorf("expected no divergence on a repeat pull, got:\n%s", out)
+	}
+}
 
-	// Set project name
-	err := runConfig(&cobra.Command{}, []string{"project.name", "new-project-name"})
-	if err != nil {
-		t.Fatalf("runConfig (set project.name) failed: %v", err)
+func TestSlowestTests(t *testing.T) {
+	results := []*pstesting.SuiteResult{
+		{
+			SuiteName: "greeting",
+			Results: []pstesting.TestResult{
+				{TestName: "fast", Passed: true, DurationMs: 5},
+				{TestName: "slowest", Passed: true, DurationMs: 900},
+				{TestName: "skipped", Skipped: true, DurationMs: 5000},
+			},
+		},
+		{
+			SuiteName: "summary",
+			Results: []pstesting.TestResult{
+				{TestName: "medium", Passed: true, DurationMs: 120},
+				{TestName: "slow", Passed: false, DurationMs: 450},
+				{TestName: "also-medium", Passed: true, DurationMs: 120},
+			},
+		},
 	}
 
-	// Verify it was set
-	config, err := loadConfig(tmpDir)
-	if err != nil {
-		t.Fatalf("failed to load config: %v", err)
+	got := slowestTests(results, 4)
+	var names []string
+	for _, pt := range got {
+		names = append(names, pt.Suite+"/"+pt.Result.TestName)
 	}
-	if config.Project.Name != "new-project-name" {
-		t.Errorf("expected project.name 'new-project-name', got %q", config.Project.Name)
+	want := []string{"greeting/slowest", "summary/slow", "summary/medium", "summary/also-medium"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("expected slowest tests %v, got %v", want, names)
 	}
-}
-
-func TestConfigCommandSetDirs(t *testing.T) {
-	tmpDir, cleanup := initTestProject(t)
-	defer cleanup()
 
-	tests := []struct {
-		key   string
-		value string
-		check func(*Config) string
-	}{
-		{"prompts_dir", "./custom-prompts", func(c *Config) string { return c.PromptsDir }},
-		{"tests_dir", "./custom-tests", func(c *Config) string { return c.TestsDir }},
-		{"benchmarks_dir", "./custom-benchmarks", func(c *Config) string { return c.BenchmarksDir }},
+	if got := slowestTests(results, 10); len(got) != 5 {
+		t.Errorf("expected every run test when n exceeds the count, got %d", len(got))
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.key, func(t *testing.T) {
-			err := runConfig(&cobra.Command{}, []string{tt.key, tt.value})
-			if err != nil {
-				t.Fatalf("runConfig (set %s) failed: %v", tt.key, err)
-			}
+	defer func() {
+		testProfile = false
+		testProfileCount = 10
+	}()
 
-			config, err := loadConfig(tmpDir)
-			if err != nil {
-				t.Fatalf("failed to load config: %v", err)
-			}
-			if tt.check(config) != tt.value {
-				t.Errorf("expected %s %q, got %q", tt.key, tt.value, tt.check(config))
-			}
-		})
+	// The count is its own flag, so a value is never read as a suite file
+	if err := testCmd.ParseFlags([]string{"--profile", "--profile-count", "5", "tests/a.test.yaml"}); err != nil {
+		t.Fatalf("ParseFlags failed: %v", err)
+	}
+	if !testProfile || testProfileCount != 5 || !reflect.DeepEqual(testCmd.Flags().Args(), []string{"tests/a.test.yaml"}) {
+		t.Errorf("expected --profile with count 5 and one suite file, got %v, %d, %v", testProfile, testProfileCount, testCmd.Flags().Args())
 	}
-}
 
-func TestConfigCommandGetUnknownKey(t *testing.T) {
-	_, cleanup := initTestProject(t)
-	defer cleanup()
+	testProfile = true
+	testProfileCount = 2
 
-	// Try to get an unknown key
-	err := runConfig(&cobra.Command{}, []string{"unknown.key"})
-	if err == nil {
-		t.Error("expected error for unknown config key")
+	output := captureStdout(t, func() {
+		printTestSummary(3, 1, 1, results)
+	})
+	if !strings.Contains(output, "Slowest tests:") {
+		t.Fatalf("expected a slowest tests section, got:\n%s", output)
 	}
-	if !strings.Contains(err.Error(), "unknown") {
-		t.Errorf("expected 'unknown' in error, got: %v", err)
+	slowest := strings.Index(output, "slowest")
+	slow := strings.Index(output, "slow (summary)")
+	if slowest < 0 || slow < 0 || slowest > slow {
+		t.Errorf("expected slowest before slow in profile, got:\n%s", output)
+	}
+	if strings.Contains(output, "medium") {
+		t.Errorf("expected profile cut to 2 tests, got:\n%s", output)
 	}
 }
 
-func TestConfigCommandSetUnknownKey(t *testing.T) {
-	_, cleanup := initTestProject(t)
-	defer cleanup()
+func TestLoadEnvFile(t *testing.T) {
+	// Start from a known environment; t.Setenv restores it afterwards
+	t.Setenv("OPENAI_API_KEY", "")
+	os.Unsetenv("OPENAI_API_KEY")
+	t.Setenv("PROMPTSMITH_TEST_SHELL", "from-shell")
+	t.Setenv("PROMPTSMITH_TEST_QUOTED", "")
+	os.Unsetenv("PROMPTSMITH_TEST_QUOTED")
 
-	// Try to set an unknown key
-	err := runConfig(&cobra.Command{}, []string{"unknown.key", "value"})
-	if err == nil {
-		t.Error("expected error for unknown config key")
+	if _, err := benchmark.NewOpenAIProvider(); err == nil {
+		t.Fatal("expected no OpenAI provider without a key")
 	}
-	if !strings.Contains(err.Error(), "unknown") {
-		t.Errorf("expected 'unknown' in error, got: %v", err)
+
+	envPath := filepath.Join(t.TempDir(), ".env")
+	content := `# Provider keys
+export OPENAI_API_KEY=sk-test-123
+
+PROMPTSMITH_TEST_SHELL=from-file
+PROMPTSMITH_TEST_QUOTED="two words"
+`
+	os.WriteFile(envPath, []byte(content), 0644)
+
+	if err := loadEnvFile(envPath); err != nil {
+		t.Fatalf("loadEnvFile failed: %v", err)
+	}
+	if _, err := benchmark.NewOpenAIProvider(); err != nil {
+		t.Errorf("expected the key from the env file to construct a provider: %v", err)
+	}
+	if got := os.Getenv("PROMPTSMITH_TEST_SHELL"); got != "from-shell" {
+		t.Errorf("expected a variable already set to win, got %q", got)
+	}
+	if got := os.Getenv("PROMPTSMITH_TEST_QUOTED"); got != "two words" {
+		t.Errorf("expected quotes to be stripped, got %q", got)
+	}
+
+	badPath := filepath.Join(t.TempDir(), "bad.env")
+	os.WriteFile(badPath, []byte("# keys\nnot a pair\n"), 0644)
+	if err := loadEnvFile(badPath); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("expected an error naming line 2, got %v", err)
+	}
+	if err := loadEnvFile(filepath.Join(t.TempDir(), "missing.env")); err == nil {
+		t.Error("expected an error for a missing env file")
 	}
 }
 
-func TestConfigCommandSetReadOnlyKey(t *testing.T) {
-	_, cleanup := initTestProject(t)
-	defer cleanup()
+func TestParseTemperatures(t *testing.T) {
+	temps, err := parseTemperatures("0, 0.5,1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(temps) != 3 || temps[0] != 0 || temps[1] != 0.5 || temps[2] != 1 {
+		t.Errorf("expected [0 0.5 1], got %v", temps)
+	}
 
-	// Try to set version (read-only)
-	err := runConfig(&cobra.Command{}, []string{"version", "2"})
-	if err == nil {
-		t.Error("expected error when setting read-only version")
+	if temps, err := parseTemperatures(""); err != nil || temps != nil {
+		t.Errorf("expected no override for an empty list, got %v, %v", temps, err)
 	}
 
-	// Try to set project.id (read-only)
-	err = runConfig(&cobra.Command{}, []string{"project.id", "new-id"})
-	if err == nil {
-		t.Error("expected error when setting read-only project.id")
+	for _, list := range []string{"0,hot", "0.5,0.5", "3"} {
+		if _, err := parseTemperatures(list); err == nil {
+			t.Errorf("expected error for %q", list)
+		}
 	}
 }
 
-func TestConfigCommandInvalidTemperature(t *testing.T) {
-	_, cleanup := initTestProject(t)
+func TestBenchmarkCommandInputFile(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
 	defer cleanup()
 
-	// Try to set invalid temperature
-	err := runConfig(&cobra.Command{}, []string{"defaults.temperature", "invalid"})
-	if err == nil {
-		t.Error("expected error for invalid temperature")
+	addTestPrompt(t, tmpDir, "summarizer", `---
+name: summarizer
+---
+Summarize: {{.text}}
+`)
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
+
+	createBenchmarkSuite(t, tmpDir, "summarizer", `
+name: summarizer-benchmark
+prompt: summarizer
+models:
+  - gpt-4o-mini
+runs_per_model: 1
+inputs:
+  text: from the suite
+`)
+
+	inputPath := filepath.Join(tmpDir, "inputs.yaml")
+	if err := os.WriteFile(inputPath, []byte("text: from the file\n"), 0644); err != nil {
+		t.Fatal(err)
 	}
 
-	// Try to set temperature out of range
-	err = runConfig(&cobra.Command{}, []string{"defaults.temperature", "3.0"})
-	if err == nil {
-		t.Error("expected error for temperature > 2")
+	outDir := filepath.Join(tmpDir, "bench-out")
+	benchModels = ""
+	benchRuns = 0
+	benchVersion = ""
+	benchInput = inputPath
+	benchOutDir = outDir
+	defer func() {
+		benchInput = ""
+		benchOutDir = ""
+	}()
+
+	captureStdout(t, func() {
+		if err := runBenchmark(&cobra.Command{}, []string{}); err != nil {
+			t.Fatalf("runBenchmark failed: %v", err)
+		}
+	})
+
+	// The raw output is written even when no provider is configured
+	data, err := os.ReadFile(filepath.Join(outDir, "gpt-4o-mini", "1.txt"))
+	if err != nil {
+		t.Fatalf("expected raw output file: %v", err)
+	}
+	if !strings.Contains(string(data), "Summarize: from the file") {
+		t.Errorf("expected the prompt rendered with the file's inputs, got:\n%s", data)
 	}
 
-	err = runConfig(&cobra.Command{}, []string{"defaults.temperature", "-1"})
-	if err == nil {
-		t.Error("expected error for negative temperature")
+	benchInput = filepath.Join(tmpDir, "missing.yaml")
+	if err := runBenchmark(&cobra.Command{}, []string{}); err == nil {
+		t.Error("expected a missing inputs file to fail")
 	}
 }
 
-func TestBenchmarkCompareRequiresTwoArgs(t *testing.T) {
-	cmd := benchmarkCompareCmd
-	// No args
-	err := cmd.Args(cmd, []string{})
-	if err == nil {
-		t.Error("expected error with no args")
-	}
-	// One arg
-	err = cmd.Args(cmd, []string{"a.json"})
-	if err == nil {
-		t.Error("expected error with one arg")
+func TestTestCommandOutputDiffOnFail(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+
+	addTestPrompt(t, tmpDir, "greeter", `---
+name: greeter
+---
+Hello there!
+`)
+	commitMessage = "Initial commit"
+	runCommit(&cobra.Command{}, []string{})
+
+	createTestSuite(t, tmpDir, "greeter", `
+name: greeter-tests
+prompt: greeter
+tests:
+  - name: says goodbye
+    assertions:
+      - type: contains
+        value: Goodbye
+`)
+
+	testFilter = ""
+	testVersion = ""
+	testLive = false
+	testWatch = false
+	testFailureDiffs = true
+	defer func() { testFailureDiffs = false }()
+
+	// runTest exits on failures, so run the suites directly
+	ctx, err := setupTestContext([]string{})
+	if err != nil {
+		t.Fatalf("setupTestContext failed: %v", err)
 	}
-	// Two args is valid
-	err = cmd.Args(cmd, []string{"a.json", "b.json"})
+	defer ctx.database.Close()
+	captureStdout(t, func() {
+		if _, failed, _, _ := executeTests(ctx); failed != 1 {
+			t.Errorf("expected 1 failed test, got %d", failed)
+		}
+	})
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "tests", "__failures__", "greeter-tests", "says goodbye.txt"))
 	if err != nil {
-		t.Errorf("expected no error with two args, got: %v", err)
+		t.Fatalf("expected failure artifact: %v", err)
+	}
+	if !strings.Contains(string(data), "Hello there!") || !strings.Contains(string(data), "expected: Goodbye") {
+		t.Errorf("unexpected failure artifact:\n%s", data)
 	}
 }
 
-func TestBenchmarkCompareReadFiles(t *testing.T) {
-	dir := t.TempDir()
+func TestValidateCommand(t *testing.T) {
+	tmpDir, cleanup := initTestProject(t)
+	defer cleanup()
+	defer func() {
+		validateFormat = "text"
+		jsonOut = false
+	}()
 
-	results1 := `[{"suite_name":"test","prompt_name":"p","version":"1.0","models":[{"model":"gpt-4o","runs":5,"errors":0,"error_rate":0,"latency_p50_ms":200,"latency_p99_ms":400,"total_tokens_avg":150,"cost_per_request":0.005}],"duration_ms":1000}]`
-	results2 := `[{"suite_name":"test","prompt_name":"p","version":"1.1","models":[{"model":"gpt-4o","runs":5,"errors":1,"error_rate":0.2,"latency_p50_ms":180,"latency_p99_ms":350,"total_tokens_avg":140,"cost_per_request":0.004}],"duration_ms":900}]`
+	addTestPrompt(t, tmpDir, "greeting", "---\nname: greeting\n---\n\nHello {{.name}}!\n")
+	addTestPrompt(t, tmpDir, "broken", "---\nname: broken\n---\n\nSummarize:\n{{.article\n")
 
-	f1 := filepath.Join(dir, "baseline.json")
-	f2 := filepath.Join(dir, "latest.json")
-	os.WriteFile(f1, []byte(results1), 0644)
-	os.WriteFile(f2, []byte(results2), 0644)
+	output := captureStdout(t, func() {
+		if err := runValidate(&cobra.Command{}, []string{}); err == nil {
+			t.Error("expected an error for a template error")
+		}
+	})
+	if !strings.Contains(output, "prompts/broken.prompt:6: unclosed action (template-syntax)") {
+		t.Errorf("expected the issue with file, line and rule, got:\n%s", output)
+	}
+	if strings.Contains(output, "greeting") {
+		t.Errorf("expected no issue for a valid prompt, got:\n%s", output)
+	}
 
-	err := runBenchmarkCompare(&cobra.Command{}, []string{f1, f2})
-	if err != nil {
-		t.Errorf("expected no error, got: %v", err)
+	validateFormat = "sarif"
+	output = captureStdout(t, func() {
+		runValidate(&cobra.Command{}, []string{})
+	})
+	var report sarifLog
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("expected a SARIF document, got %v:\n%s", err, output)
+	}
+	if report.Version != "2.1.0" || len(report.Runs) != 1 || len(report.Runs[0].Results) != 1 {
+		t.Fatalf("expected one run with one result, got %+v", report)
+	}
+	result := report.Runs[0].Results[0]
+	location := result.Locations[0].PhysicalLocation
+	if result.RuleID != "template-syntax" || result.Level != "error" || result.Message.Text != "unclosed action" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if location.ArtifactLocation.URI != "prompts/broken.prompt" || location.ArtifactLocation.URIBaseID != "%SRCROOT%" || location.Region.StartLine != 6 {
+		t.Errorf("expected prompts/broken.prompt line 6, got %+v", location)
+	}
+	if rules := report.Runs[0].Tool.Driver.Rules; len(rules) != 2 {
+		t.Errorf("expected every rule described in the driver, got %+v", rules)
 	}
-}
 
-func TestBenchmarkCompareMissingFile(t *testing.T) {
-	err := runBenchmarkCompare(&cobra.Command{}, []string{"/nonexistent/a.json", "/nonexistent/b.json"})
-	if err == nil {
-		t.Error("expected error for missing file")
+	// Named files are checked whether tracked or not
+	validateFormat = "text"
+	os.WriteFile(filepath.Join(tmpDir, "draft.prompt"), []byte("---\nname: draft\n"), 0644)
+	output = captureStdout(t, func() {
+		if err := runValidate(&cobra.Command{}, []string{"prompts/greeting.prompt", "draft.prompt"}); err == nil {
+			t.Error("expected an error for unclosed frontmatter")
+		}
+	})
+	if !strings.Contains(output, "draft.prompt:1: frontmatter is not closed by --- (frontmatter)") || strings.Contains(output, "broken") {
+		t.Errorf("expected only the named files checked, got:\n%s", output)
+	}
+	if err := runValidate(&cobra.Command{}, []string{"../outside.prompt"}); err == nil {
+		t.Error("expected an error for a path outside the project")
+	}
+
+	validateFormat = "xml"
+	if err := runValidate(&cobra.Command{}, []string{}); err == nil {
+		t.Error("expected an error for an unknown format")
 	}
 }
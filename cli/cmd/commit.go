@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/promptsmith/cli/internal/db"
@@ -15,31 +19,65 @@ import (
 )
 
 var (
-	commitMessage string
-	commitAll     bool
+	commitMessage  string
+	commitAll      bool
+	commitMeta     []string
+	commitNoVerify bool
+	commitAuthor   string
 )
 
 var commitCmd = &cobra.Command{
 	Use:   "commit",
 	Short: "Record changes to prompts",
-	Long:  `Create a new version for all prompts that have changed since the last commit.`,
-	RunE:  runCommit,
+	Long: `Create a new version for each staged prompt, using the content it had when
+it was staged. With --all, every prompt whose file has changed since the last
+commit is committed instead, staged or not.
+
+If commit_message_pattern is configured, the message must match it unless
+--no-verify is given. Without -m, the message is built from
+commit_message_template, which can use {{.Prompts}}, {{.User}} and {{.Date}}.
+
+Versions are attributed to --author if given, otherwise $PROMPTSMITH_AUTHOR,
+then git's user.name (or user.email), then $USER.
+
+Examples:
+  promptsmith stage summarizer && promptsmith commit -m "Tighten summary length"
+  promptsmith commit --all -m "Update all prompts"
+  promptsmith commit --all -m "Fix tone" --meta ticket=ABC-1 --meta reviewer=sam
+  promptsmith commit -m "wip" --no-verify  # Skip commit_message_pattern
+  promptsmith commit --all -m "Fix tone" --author "Sam Lee"`,
+	RunE: runCommit,
 }
 
 func init() {
-	commitCmd.Flags().StringVarP(&commitMessage, "message", "m", "", "commit message (required)")
-	commitCmd.Flags().BoolVarP(&commitAll, "all", "a", false, "commit all tracked prompts")
-	commitCmd.MarkFlagRequired("message")
+	commitCmd.Flags().StringVarP(&commitMessage, "message", "m", "", "commit message (required unless commit_message_template is set)")
+	commitCmd.Flags().BoolVarP(&commitAll, "all", "a", false, "commit every changed prompt, staged or not")
+	commitCmd.Flags().StringArrayVar(&commitMeta, "meta", nil, "attach key=value metadata to the new versions (repeatable)")
+	commitCmd.Flags().BoolVar(&commitNoVerify, "no-verify", false, "skip checking the message against commit_message_pattern")
+	commitCmd.Flags().StringVar(&commitAuthor, "author", "", "record this author on the new versions instead of the detected one")
 	rootCmd.AddCommand(commitCmd)
 }
 
 func runCommit(cmd *cobra.Command, args []string) error {
+	extraMeta, err := parseCommitMeta(commitMeta)
+	if err != nil {
+		return err
+	}
+
 	// Find project root
 	projectRoot, err := db.FindProjectRoot()
 	if err != nil {
 		return err
 	}
 
+	config, err := loadConfig(projectRoot)
+	if err != nil {
+		return err
+	}
+	if commitMessage == "" && config.CommitMessageTemplate == "" {
+		return fmt.Errorf("a commit message is required (use -m, or set commit_message_template)")
+	}
+
 	// Open database
 	database, err := db.Open(projectRoot)
 	if err != nil {
@@ -61,20 +99,31 @@ func runCommit(cmd *cobra.Command, args []string) error {
 	yellow := color.New(color.FgYellow).SprintFunc()
 	cyan := color.New(color.FgCyan).SprintFunc()
 
-	var committed int
+	// Every prompt is read and parsed before anything is written, and the new
+	// versions are stored in a single transaction, so a failure part way
+	// through leaves the database as it was
+	var pending []*db.PromptVersion
+	var pendingNames []string
+	// Staged snapshots that match their latest version are dropped from the
+	// staging area once the commit goes through
+	var unchanged []string
 	secretScanner := scanner.New()
 
-	for _, p := range prompts {
-		// Read current file content
-		absPath := filepath.Join(projectRoot, p.FilePath)
-		content, err := os.ReadFile(absPath)
-		if err != nil {
-			if os.IsNotExist(err) {
-				fmt.Printf("%s %s: file not found\n", yellow("!"), p.Name)
-				continue
-			}
-			return fmt.Errorf("failed to read %s: %w", p.FilePath, err)
-		}
+	user := resolveAuthor(projectRoot, commitAuthor)
+
+	candidates, err := commitCandidates(database, projectRoot, prompts)
+	if err != nil {
+		return err
+	}
+	if candidates == nil && !commitAll {
+		fmt.Println("Nothing staged to commit.")
+		fmt.Printf("Use %s to stage a prompt, or %s to commit every changed prompt.\n",
+			cyan("promptsmith stage <prompt>"), cyan("promptsmith commit --all"))
+		return nil
+	}
+
+	for _, c := range candidates {
+		p, content := c.prompt, c.content
 
 		// Get latest version
 		latest, err := database.GetLatestVersion(p.ID)
@@ -87,9 +136,16 @@ func runCommit(cmd *cobra.Command, args []string) error {
 			if verbose {
 				fmt.Printf("  %s: no changes\n", p.Name)
 			}
+			if !commitAll {
+				unchanged = append(unchanged, p.ID)
+			}
 			continue
 		}
 
+		if p.Frozen {
+			return fmt.Errorf("prompt '%s' is frozen; run 'promptsmith unfreeze %s' to commit changes", p.Name, p.Name)
+		}
+
 		// Scan for secrets
 		secrets := secretScanner.Scan(string(content))
 		if len(secrets) > 0 {
@@ -106,6 +162,11 @@ func runCommit(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("failed to parse %s: %w", p.FilePath, err)
 		}
 
+		metadata, err := mergeMetadataJSON(parsed.MetadataJSON(), extraMeta)
+		if err != nil {
+			return fmt.Errorf("failed to add --meta to %s: %w", p.FilePath, err)
+		}
+
 		// Calculate new version
 		newVersion := "1.0.0"
 		var parentID *string
@@ -114,40 +175,197 @@ func runCommit(cmd *cobra.Command, args []string) error {
 			parentID = &latest.ID
 		}
 
-		// Get current user
-		user := os.Getenv("USER")
-		if user == "" {
-			user = "unknown"
+		pending = append(pending, &db.PromptVersion{
+			PromptID:        p.ID,
+			Version:         newVersion,
+			Content:         string(content),
+			Variables:       parsed.VariablesJSON(),
+			Metadata:        metadata,
+			ParentVersionID: parentID,
+			CreatedBy:       user,
+		})
+		pendingNames = append(pendingNames, p.Name)
+	}
+
+	if len(pending) == 0 {
+		if err := unstagePrompts(database, unchanged); err != nil {
+			return err
 		}
+		fmt.Println("No changes to commit.")
+		return nil
+	}
 
-		// Create version
-		v, err := database.CreateVersion(
-			p.ID,
-			newVersion,
-			string(content),
-			parsed.VariablesJSON(),
-			parsed.MetadataJSON(),
-			commitMessage,
-			user,
-			parentID,
-		)
+	message := commitMessage
+	if message == "" {
+		message, err = expandCommitTemplate(config.CommitMessageTemplate, pendingNames, user)
 		if err != nil {
 			return err
 		}
+	}
+	if !commitNoVerify {
+		if err := checkCommitMessage(config.CommitMessagePattern, message); err != nil {
+			return err
+		}
+	}
+	for _, v := range pending {
+		v.CommitMessage = message
+	}
 
-		fmt.Printf("%s %s@%s\n", green("✓"), cyan(p.Name), v.Version)
-		committed++
+	if err := database.CreateVersions(pending); err != nil {
+		return err
+	}
+	if err := unstagePrompts(database, unchanged); err != nil {
+		return err
+	}
+	// Committing a prompt flagged by pull --strategy manual settles the review
+	for _, v := range pending {
+		if err := database.ResolvePullConflicts(v.PromptID, v.ID); err != nil {
+			return err
+		}
 	}
 
-	if committed == 0 {
-		fmt.Println("No changes to commit.")
-	} else {
-		fmt.Printf("\n%d prompt(s) committed.\n", committed)
+	for i, v := range pending {
+		fmt.Printf("%s %s@%s\n", green("✓"), cyan(pendingNames[i]), v.Version)
+	}
+	fmt.Printf("\n%d prompt(s) committed.\n", len(pending))
+
+	return nil
+}
+
+// commitTemplateData is the data available to commit_message_template
+type commitTemplateData struct {
+	Prompts string // Committed prompt names, comma separated
+	User    string
+	Date    string // YYYY-MM-DD
+}
+
+// expandCommitTemplate builds a commit message from commit_message_template
+func expandCommitTemplate(tmpl string, promptNames []string, user string) (string, error) {
+	t, err := template.New("commit_message_template").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid commit_message_template: %w", err)
+	}
+	var buf strings.Builder
+	err = t.Execute(&buf, commitTemplateData{
+		Prompts: strings.Join(promptNames, ", "),
+		User:    user,
+		Date:    time.Now().Format("2006-01-02"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("invalid commit_message_template: %w", err)
+	}
+	message := strings.TrimSpace(buf.String())
+	if message == "" {
+		return "", fmt.Errorf("commit_message_template produced an empty message")
+	}
+	return message, nil
+}
+
+// checkCommitMessage rejects a message that doesn't match pattern. An empty
+// pattern accepts every message.
+func checkCommitMessage(pattern, message string) error {
+	if pattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid commit_message_pattern: %w", err)
+	}
+	if !re.MatchString(message) {
+		return fmt.Errorf("commit message %q does not match commit_message_pattern %q (use --no-verify to skip)", message, pattern)
+	}
+	return nil
+}
+
+type commitCandidate struct {
+	prompt  *db.Prompt
+	content []byte
+}
+
+// commitCandidates returns the content to consider committing for each
+// prompt: the staged snapshots, or with --all the working files. It returns
+// nil when nothing is staged.
+func commitCandidates(database *db.DB, projectRoot string, prompts []*db.Prompt) ([]commitCandidate, error) {
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	var candidates []commitCandidate
+	if !commitAll {
+		staged, err := database.ListStagedPrompts()
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[string]*db.Prompt, len(prompts))
+		for _, p := range prompts {
+			byID[p.ID] = p
+		}
+		for _, s := range staged {
+			if p, ok := byID[s.PromptID]; ok {
+				candidates = append(candidates, commitCandidate{prompt: p, content: []byte(s.Content)})
+			}
+		}
+		return candidates, nil
 	}
 
+	for _, p := range prompts {
+		// Read current file content
+		absPath := filepath.Join(projectRoot, p.FilePath)
+		content, err := os.ReadFile(absPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				fmt.Printf("%s %s: file not found\n", yellow("!"), p.Name)
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", p.FilePath, err)
+		}
+		candidates = append(candidates, commitCandidate{prompt: p, content: content})
+	}
+	return candidates, nil
+}
+
+// unstagePrompts discards the staged snapshots of the given prompts
+func unstagePrompts(database *db.DB, promptIDs []string) error {
+	for _, id := range promptIDs {
+		if err := database.UnstagePrompt(id); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// parseCommitMeta turns --meta key=value pairs into a map
+func parseCommitMeta(pairs []string) (map[string]string, error) {
+	meta := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --meta value '%s' (expected key=value)", pair)
+		}
+		meta[key] = value
+	}
+	return meta, nil
+}
+
+// mergeMetadataJSON adds extra to the metadata JSON object in base. Keys in
+// extra take precedence over those parsed from the prompt.
+func mergeMetadataJSON(base string, extra map[string]string) (string, error) {
+	if len(extra) == 0 {
+		return base, nil
+	}
+	merged := map[string]any{}
+	if err := json.Unmarshal([]byte(base), &merged); err != nil {
+		return "", fmt.Errorf("invalid metadata: %w", err)
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
 func bumpVersion(version string) string {
 	parts := strings.Split(version, ".")
 	if len(parts) != 3 {
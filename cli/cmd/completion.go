@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/promptsmith/cli/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion <bash|zsh|fish|powershell>",
+	Short: "Generate a shell completion script",
+	Long: `Print a script that makes your shell complete PromptSmith commands, flags,
+prompt names, versions and tags.
+
+Examples:
+  # Bash, for the current session and for new ones
+  source <(promptsmith completion bash)
+  promptsmith completion bash > /etc/bash_completion.d/promptsmith
+
+  # Zsh
+  promptsmith completion zsh > "${fpath[1]}/_promptsmith"
+
+  # Fish
+  promptsmith completion fish > ~/.config/fish/completions/promptsmith.fish
+
+  # PowerShell
+  promptsmith completion powershell | Out-String | Invoke-Expression`,
+	Args:                  cobra.ExactArgs(1),
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	DisableFlagsInUseLine: true,
+	RunE:                  runCompletion,
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
+
+func runCompletion(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	switch args[0] {
+	case "bash":
+		return rootCmd.GenBashCompletionV2(out, true)
+	case "zsh":
+		return rootCmd.GenZshCompletion(out)
+	case "fish":
+		return rootCmd.GenFishCompletion(out, true)
+	case "powershell":
+		return rootCmd.GenPowerShellCompletionWithDesc(out)
+	}
+	return fmt.Errorf("unsupported shell '%s' (expected bash, zsh, fish or powershell)", args[0])
+}
+
+// refCompletions lists the completions for a ref argument of a prompt
+type refCompletions func(database *db.DB, promptID string) ([]cobra.Completion, error)
+
+// completePromptArgs completes a prompt name as the first argument, and each
+// argument after it with the matching refs function for that prompt
+func completePromptArgs(refs ...refCompletions) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return withCompletionDatabase(toComplete, func(database *db.DB) ([]cobra.Completion, error) {
+				return listPromptCompletions(database)
+			})
+		}
+		if len(args) > len(refs) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completePromptRefs(args[0], toComplete, refs[len(args)-1])
+	}
+}
+
+// completePromptFlag completes a flag with refs of the prompt given as the
+// command's first argument
+func completePromptFlag(refs refCompletions) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completePromptRefs(args[0], toComplete, refs)
+	}
+}
+
+func completePromptRefs(promptName, toComplete string, refs refCompletions) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return withCompletionDatabase(toComplete, func(database *db.DB) ([]cobra.Completion, error) {
+		p, err := database.GetPromptByName(promptName)
+		if err != nil || p == nil {
+			return nil, err
+		}
+		return refs(database, p.ID)
+	})
+}
+
+// withCompletionDatabase opens the project's database for list and returns
+// its completions that start with toComplete. Completion is best effort:
+// outside a project or on any error there are simply no candidates.
+func withCompletionDatabase(toComplete string, list func(database *db.DB) ([]cobra.Completion, error)) ([]cobra.Completion, cobra.ShellCompDirective) {
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	database, err := db.Open(projectRoot)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer database.Close()
+
+	all, err := list(database)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var matches []cobra.Completion
+	for _, c := range all {
+		if strings.HasPrefix(c, toComplete) {
+			matches = append(matches, c)
+		}
+	}
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionWithDesc is a completion described by the first line of desc,
+// if there is one
+func completionWithDesc(choice, desc string) cobra.Completion {
+	desc, _, _ = strings.Cut(strings.TrimSpace(desc), "\n")
+	if desc == "" {
+		return choice
+	}
+	return cobra.CompletionWithDesc(choice, desc)
+}
+
+// listPromptCompletions lists every prompt name, described by the prompt's
+// description
+func listPromptCompletions(database *db.DB) ([]cobra.Completion, error) {
+	prompts, err := database.ListPrompts()
+	if err != nil {
+		return nil, err
+	}
+	completions := make([]cobra.Completion, 0, len(prompts))
+	for _, p := range prompts {
+		completions = append(completions, completionWithDesc(p.Name, p.Description))
+	}
+	return completions, nil
+}
+
+// listVersionCompletions lists a prompt's versions, newest first, described
+// by their commit messages
+func listVersionCompletions(database *db.DB, promptID string) ([]cobra.Completion, error) {
+	versions, err := database.ListVersions(promptID)
+	if err != nil {
+		return nil, err
+	}
+	completions := make([]cobra.Completion, 0, len(versions))
+	for _, v := range versions {
+		completions = append(completions, completionWithDesc(v.Version, v.CommitMessage))
+	}
+	return completions, nil
+}
+
+// listTagCompletions lists a prompt's tags, described by the version each
+// points at
+func listTagCompletions(database *db.DB, promptID string) ([]cobra.Completion, error) {
+	tags, err := database.ListTags(promptID)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := database.ListVersions(promptID)
+	if err != nil {
+		return nil, err
+	}
+	versionByID := make(map[string]string, len(versions))
+	for _, v := range versions {
+		versionByID[v.ID] = v.Version
+	}
+	completions := make([]cobra.Completion, 0, len(tags))
+	for _, t := range tags {
+		completions = append(completions, completionWithDesc(t.Name, "tag → "+versionByID[t.VersionID]))
+	}
+	return completions, nil
+}
+
+// listRefCompletions lists everything a version ref can name: a prompt's
+// versions, then its tags
+func listRefCompletions(database *db.DB, promptID string) ([]cobra.Completion, error) {
+	versions, err := listVersionCompletions(database, promptID)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := listTagCompletions(database, promptID)
+	if err != nil {
+		return nil, err
+	}
+	return append(versions, tags...), nil
+}
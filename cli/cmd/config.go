@@ -4,11 +4,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/fatih/color"
+	"github.com/promptsmith/cli/internal/benchmark"
 	"github.com/promptsmith/cli/internal/db"
+	"github.com/promptsmith/cli/internal/logging"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -22,11 +27,25 @@ Examples:
   promptsmith config                    # List all config
   promptsmith config get defaults.model # Get specific value
   promptsmith config set defaults.model gpt-4o-mini
-  promptsmith config set defaults.temperature 0.5`,
+  promptsmith config set defaults.temperature 0.5
+  promptsmith config set model_aliases.fast gpt-4o-mini`,
 	Args: cobra.MaximumNArgs(2),
 	RunE: runConfig,
 }
 
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the project configuration for problems",
+	Long: `Check that configured directories exist and default settings are valid.
+
+Reports every problem found and exits with an error if any exist.
+
+Examples:
+  promptsmith config validate`,
+	Args: cobra.NoArgs,
+	RunE: runConfigValidate,
+}
+
 var (
 	configGetFlag bool
 	configSetFlag bool
@@ -34,6 +53,7 @@ var (
 
 func init() {
 	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
 	configCmd.Flags().BoolVar(&configGetFlag, "get", false, "Get a config value")
 	configCmd.Flags().BoolVar(&configSetFlag, "set", false, "Set a config value")
 }
@@ -53,6 +73,26 @@ func loadConfig(projectRoot string) (*Config, error) {
 	return &config, nil
 }
 
+// applyModelAliases loads the project's model_aliases into registry
+func applyModelAliases(projectRoot string, registry *benchmark.ProviderRegistry) error {
+	config, err := loadConfig(projectRoot)
+	if err != nil {
+		return err
+	}
+	registry.SetAliases(config.ModelAliases)
+	return nil
+}
+
+// sortedModelAliases returns the configured alias names in order
+func sortedModelAliases(config *Config) []string {
+	aliases := make([]string, 0, len(config.ModelAliases))
+	for alias := range config.ModelAliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
 func saveConfig(projectRoot string, config *Config) error {
 	configPath := filepath.Join(projectRoot, db.ConfigDir, db.ConfigFile)
 	data, err := yaml.Marshal(config)
@@ -63,6 +103,7 @@ func saveConfig(projectRoot string, config *Config) error {
 	if err := os.WriteFile(configPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
+	logging.Debug("wrote config", "path", configPath)
 
 	return nil
 }
@@ -91,6 +132,12 @@ func getConfigValue(config *Config, key string) (string, error) {
 		return config.TestsDir, nil
 	case "benchmarks_dir":
 		return config.BenchmarksDir, nil
+	case "commit_message_pattern":
+		return config.CommitMessagePattern, nil
+	case "commit_message_template":
+		return config.CommitMessageTemplate, nil
+	case "db_path":
+		return config.DBPath, nil
 	case "defaults":
 		if len(parts) < 2 {
 			return "", fmt.Errorf("specify defaults.model or defaults.temperature")
@@ -117,6 +164,16 @@ func getConfigValue(config *Config, key string) (string, error) {
 		default:
 			return "", fmt.Errorf("unknown sync key: %s", parts[1])
 		}
+	case "model_aliases":
+		if len(parts) < 2 {
+			return "", fmt.Errorf("specify model_aliases.<alias>")
+		}
+		alias := strings.Join(parts[1:], ".")
+		model, ok := config.ModelAliases[alias]
+		if !ok {
+			return "", fmt.Errorf("unknown model alias: %s", alias)
+		}
+		return model, nil
 	default:
 		return "", fmt.Errorf("unknown config key: %s", key)
 	}
@@ -142,6 +199,19 @@ func setConfigValue(config *Config, key, value string) error {
 		config.TestsDir = value
 	case "benchmarks_dir":
 		config.BenchmarksDir = value
+	case "db_path":
+		// Pointing it elsewhere would silently start an empty database
+		return fmt.Errorf("cannot set db_path (move the database file and edit %s/%s instead)", db.ConfigDir, db.ConfigFile)
+	case "commit_message_pattern":
+		if _, err := regexp.Compile(value); err != nil {
+			return fmt.Errorf("invalid commit_message_pattern: %w", err)
+		}
+		config.CommitMessagePattern = value
+	case "commit_message_template":
+		if _, err := template.New("").Parse(value); err != nil {
+			return fmt.Errorf("invalid commit_message_template: %w", err)
+		}
+		config.CommitMessageTemplate = value
 	case "defaults":
 		if len(parts) < 2 {
 			return fmt.Errorf("specify defaults.model or defaults.temperature")
@@ -179,6 +249,20 @@ func setConfigValue(config *Config, key, value string) error {
 		default:
 			return fmt.Errorf("unknown sync key: %s", parts[1])
 		}
+	case "model_aliases":
+		if len(parts) < 2 {
+			return fmt.Errorf("specify model_aliases.<alias>")
+		}
+		alias := strings.Join(parts[1:], ".")
+		// An empty value removes the alias
+		if value == "" {
+			delete(config.ModelAliases, alias)
+			return nil
+		}
+		if config.ModelAliases == nil {
+			config.ModelAliases = make(map[string]string)
+		}
+		config.ModelAliases[alias] = value
 	default:
 		return fmt.Errorf("unknown or read-only config key: %s", key)
 	}
@@ -186,6 +270,95 @@ func setConfigValue(config *Config, key, value string) error {
 	return nil
 }
 
+// validateConfig returns a description of each problem found in config.
+// Relative directories are resolved against projectRoot.
+func validateConfig(projectRoot string, config *Config) []string {
+	var problems []string
+
+	dirs := []struct {
+		key   string
+		value string
+	}{
+		{"prompts_dir", config.PromptsDir},
+		{"tests_dir", config.TestsDir},
+		{"benchmarks_dir", config.BenchmarksDir},
+	}
+	for _, d := range dirs {
+		if d.value == "" {
+			problems = append(problems, fmt.Sprintf("%s is not set", d.key))
+			continue
+		}
+		path := d.value
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(projectRoot, path)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				problems = append(problems, fmt.Sprintf("%s: %s does not exist", d.key, d.value))
+			} else {
+				problems = append(problems, fmt.Sprintf("%s: %v", d.key, err))
+			}
+			continue
+		}
+		if !info.IsDir() {
+			problems = append(problems, fmt.Sprintf("%s: %s is not a directory", d.key, d.value))
+		}
+	}
+
+	if config.Defaults.Temperature < 0 || config.Defaults.Temperature > 2 {
+		problems = append(problems, fmt.Sprintf("defaults.temperature: %.2f is out of range (0-2)", config.Defaults.Temperature))
+	}
+
+	if config.Defaults.Model == "" {
+		problems = append(problems, "defaults.model is not set")
+	} else if _, isAlias := config.ModelAliases[config.Defaults.Model]; !isAlias &&
+		benchmark.GetProviderForModel(config.Defaults.Model) == "unknown" {
+		problems = append(problems, fmt.Sprintf("defaults.model: %s is not a known provider model", config.Defaults.Model))
+	}
+
+	if _, err := regexp.Compile(config.CommitMessagePattern); err != nil {
+		problems = append(problems, fmt.Sprintf("commit_message_pattern: %v", err))
+	}
+	if _, err := template.New("").Parse(config.CommitMessageTemplate); err != nil {
+		problems = append(problems, fmt.Sprintf("commit_message_template: %v", err))
+	}
+
+	for _, alias := range sortedModelAliases(config) {
+		model := config.ModelAliases[alias]
+		if benchmark.GetProviderForModel(model) == "unknown" {
+			problems = append(problems, fmt.Sprintf("model_aliases.%s: %s is not a known provider model", alias, model))
+		}
+	}
+
+	return problems
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	config, err := loadConfig(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	problems := validateConfig(projectRoot, config)
+	if len(problems) == 0 {
+		green := color.New(color.FgGreen).SprintFunc()
+		fmt.Printf("%s Configuration is valid\n", green("✓"))
+		return nil
+	}
+
+	red := color.New(color.FgRed).SprintFunc()
+	for _, p := range problems {
+		fmt.Printf("%s %s\n", red("✗"), p)
+	}
+	return fmt.Errorf("configuration has %d problem(s)", len(problems))
+}
+
 func runConfig(cmd *cobra.Command, args []string) error {
 	projectRoot, err := db.FindProjectRoot()
 	if err != nil {
@@ -208,6 +381,12 @@ func runConfig(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  prompts_dir:        %s\n", config.PromptsDir)
 		fmt.Printf("  tests_dir:          %s\n", config.TestsDir)
 		fmt.Printf("  benchmarks_dir:     %s\n", config.BenchmarksDir)
+		if config.CommitMessagePattern != "" {
+			fmt.Printf("  commit_message_pattern:  %s\n", config.CommitMessagePattern)
+		}
+		if config.CommitMessageTemplate != "" {
+			fmt.Printf("  commit_message_template: %s\n", config.CommitMessageTemplate)
+		}
 		fmt.Printf("\n%s\n", cyan("Defaults"))
 		fmt.Printf("  defaults.model:       %s\n", config.Defaults.Model)
 		fmt.Printf("  defaults.temperature: %.1f\n", config.Defaults.Temperature)
@@ -223,6 +402,12 @@ func runConfig(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  sync.remote:        %s\n", remoteDisplay)
 		fmt.Printf("  sync.auto_push:     %v\n", config.Sync.AutoPush)
 		fmt.Printf("  sync.team:          %s\n", teamDisplay)
+		if len(config.ModelAliases) > 0 {
+			fmt.Printf("\n%s\n", cyan("Model Aliases"))
+			for _, alias := range sortedModelAliases(config) {
+				fmt.Printf("  model_aliases.%s: %s\n", alias, config.ModelAliases[alias])
+			}
+		}
 		return nil
 	}
 
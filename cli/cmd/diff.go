@@ -8,14 +8,20 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/fatih/color"
 	"github.com/promptsmith/cli/internal/db"
+	"github.com/promptsmith/cli/internal/diff"
 	"github.com/spf13/cobra"
 )
 
 var (
-	diffFormat string
+	diffFormat           string
+	diffWordDiff         bool
+	diffExitCode         bool
+	diffIgnoreWhitespace bool
+	diffIgnoreBlankLines bool
 )
 
 var diffCmd = &cobra.Command{
@@ -23,32 +29,78 @@ var diffCmd = &cobra.Command{
 	Short: "Show changes between versions",
 	Long: `Show differences between prompt versions.
 
+To compare two different prompts, give each side as <prompt>:<ref>, where
+ref is a version or HEAD notation.
+
 Examples:
   promptsmith diff summarizer              # Compare working file vs latest
   promptsmith diff summarizer 1.0.0 1.0.1  # Compare two versions
-  promptsmith diff summarizer HEAD~1 HEAD  # Compare using HEAD notation`,
+  promptsmith diff summarizer HEAD~1 HEAD  # Compare using HEAD notation
+  promptsmith diff summarizer:HEAD summarizer-v2:HEAD  # Compare two prompts
+  promptsmith diff summarizer --word-diff  # Highlight changed words within lines
+  promptsmith diff summarizer --exit-code  # Exit 1 if the working file has changed
+  promptsmith diff summarizer --ignore-whitespace --ignore-blank-lines  # Ignore reformatting`,
 	Args: cobra.RangeArgs(1, 3),
 	RunE: runDiff,
 }
 
 func init() {
 	diffCmd.Flags().StringVar(&diffFormat, "format", "unified", "output format: unified, side-by-side")
+	diffCmd.Flags().BoolVar(&diffWordDiff, "word-diff", false, "highlight changed words within modified lines")
+	diffCmd.Flags().BoolVar(&diffExitCode, "exit-code", false, "exit with status 1 if there are differences, 0 if not")
+	diffCmd.Flags().BoolVarP(&diffIgnoreWhitespace, "ignore-whitespace", "w", false, "ignore changes in indentation and spacing within lines")
+	diffCmd.Flags().BoolVar(&diffIgnoreBlankLines, "ignore-blank-lines", false, "ignore added or removed blank lines")
+	diffCmd.ValidArgsFunction = completePromptArgs(listRefCompletions, listRefCompletions)
 	rootCmd.AddCommand(diffCmd)
 }
 
+// diffOutput is the --json shape of a diff. From, To and hunk Start repeat
+// Version1, Version2 and OldStart under the names editors expect; the older
+// names stay for existing consumers.
 type diffOutput struct {
-	Prompt   string `json:"prompt"`
-	Version1 string `json:"version1"`
-	Version2 string `json:"version2"`
-	Hunks    []hunk `json:"hunks"`
+	Prompt   string      `json:"prompt"`
+	Version1 string      `json:"version1"`
+	Version2 string      `json:"version2"`
+	From     string      `json:"from"`
+	To       string      `json:"to"`
+	Hunks    []diff.Hunk `json:"hunks"`
+	Stats    diffStats   `json:"stats"`
 }
 
-type hunk struct {
-	OldStart int      `json:"old_start"`
-	OldCount int      `json:"old_count"`
-	NewStart int      `json:"new_start"`
-	NewCount int      `json:"new_count"`
-	Lines    []string `json:"lines"`
+// diffContextLines is how many unchanged lines surround each hunk
+const diffContextLines = 3
+
+type diffStats struct {
+	Insertions int `json:"insertions"`
+	Deletions  int `json:"deletions"`
+}
+
+// newDiffOutput diffs two contents line by line and counts the changes
+func newDiffOutput(promptName, from, to, content1, content2 string) *diffOutput {
+	output := &diffOutput{
+		Prompt:   promptName,
+		Version1: from,
+		Version2: to,
+		From:     from,
+		To:       to,
+		Hunks:    []diff.Hunk{},
+	}
+	if content1 == content2 {
+		return output
+	}
+
+	output.Hunks = diff.Compute(strings.Split(content1, "\n"), strings.Split(content2, "\n"), diffContextLines)
+	for _, h := range output.Hunks {
+		for _, line := range h.Lines {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				output.Stats.Insertions++
+			case strings.HasPrefix(line, "-"):
+				output.Stats.Deletions++
+			}
+		}
+	}
+	return output
 }
 
 func runDiff(cmd *cobra.Command, args []string) error {
@@ -65,7 +117,15 @@ func runDiff(cmd *cobra.Command, args []string) error {
 	}
 	defer database.Close()
 
-	p, err := database.GetPromptByName(promptName)
+	crossPrompt, err := isCrossPromptDiff(args)
+	if err != nil {
+		return err
+	}
+	if crossPrompt {
+		return runCrossPromptDiff(cmd, database, args[0], args[1])
+	}
+
+	p, err := resolvePrompt(database, promptName)
 	if err != nil {
 		return err
 	}
@@ -143,31 +203,126 @@ func runDiff(cmd *cobra.Command, args []string) error {
 		label2 = fmt.Sprintf("%s@%s", promptName, v2.Version)
 	}
 
-	if content1 == content2 {
-		fmt.Println("No differences.")
-		return nil
+	return finishDiff(cmd, promptName, label1, label2, content1, content2)
+}
+
+// runCrossPromptDiff compares two <prompt>:<ref> sides, which may name
+// different prompts
+func runCrossPromptDiff(cmd *cobra.Command, database *db.DB, spec1, spec2 string) error {
+	name1, v1, err := resolvePromptRef(database, spec1)
+	if err != nil {
+		return err
+	}
+	name2, v2, err := resolvePromptRef(database, spec2)
+	if err != nil {
+		return err
+	}
+
+	promptName := name1
+	if name2 != name1 {
+		promptName = name1 + ".." + name2
 	}
+	return finishDiff(cmd, promptName,
+		fmt.Sprintf("%s@%s", name1, v1.Version), fmt.Sprintf("%s@%s", name2, v2.Version),
+		v1.Content, v2.Content)
+}
 
-	lines1 := strings.Split(content1, "\n")
-	lines2 := strings.Split(content2, "\n")
-	hunks := computeDiff(lines1, lines2)
+// finishDiff prints the diff of two resolved contents and applies --exit-code
+func finishDiff(cmd *cobra.Command, promptName, label1, label2, content1, content2 string) error {
+	content1 = normalizeDiffContent(content1, diffIgnoreWhitespace, diffIgnoreBlankLines)
+	content2 = normalizeDiffContent(content2, diffIgnoreWhitespace, diffIgnoreBlankLines)
+	output := newDiffOutput(promptName, label1, label2, content1, content2)
 
 	if jsonOut {
-		output := diffOutput{
-			Prompt:   promptName,
-			Version1: label1,
-			Version2: label2,
-			Hunks:    hunks,
-		}
 		data, _ := json.MarshalIndent(output, "", "  ")
 		fmt.Println(string(data))
-		return nil
+	} else if content1 == content2 {
+		fmt.Println("No differences.")
+	} else {
+		printUnifiedDiff(label1, label2, output.Hunks, diffWordDiff)
 	}
 
-	printUnifiedDiff(label1, label2, hunks)
+	if diffExitCode && content1 != content2 {
+		// The status is the result, not a failure worth reporting
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &exitError{code: 1}
+	}
 	return nil
 }
 
+// normalizeDiffContent rewrites content so that differences being ignored
+// compare equal: with ignoreWhitespace each line's runs of spaces and tabs
+// collapse to one space and its indentation is dropped, and with
+// ignoreBlankLines blank lines are removed
+func normalizeDiffContent(content string, ignoreWhitespace, ignoreBlankLines bool) string {
+	if !ignoreWhitespace && !ignoreBlankLines {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if ignoreWhitespace {
+			line = strings.Join(strings.Fields(line), " ")
+		}
+		if ignoreBlankLines && strings.TrimSpace(line) == "" {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// isCrossPromptDiff reports whether args are two <prompt>:<ref> sides rather
+// than one prompt and its versions
+func isCrossPromptDiff(args []string) (bool, error) {
+	colons := 0
+	for _, arg := range args {
+		if strings.Contains(arg, ":") {
+			colons++
+		}
+	}
+	if colons == 0 {
+		return false, nil
+	}
+	if len(args) != 2 || colons != 2 {
+		return false, fmt.Errorf("to compare two prompts, give both sides as <prompt>:<ref>")
+	}
+	return true, nil
+}
+
+// resolvePromptRef resolves a <prompt>:<ref> argument to the prompt's name
+// and the referenced version
+func resolvePromptRef(database *db.DB, spec string) (string, *db.PromptVersion, error) {
+	idx := strings.LastIndex(spec, ":")
+	name, ref := spec[:idx], spec[idx+1:]
+	if name == "" || ref == "" {
+		return "", nil, fmt.Errorf("invalid reference '%s' (expected <prompt>:<ref>)", spec)
+	}
+
+	p, err := resolvePrompt(database, name)
+	if err != nil {
+		return "", nil, err
+	}
+	if p == nil {
+		return "", nil, fmt.Errorf("prompt '%s' not found", name)
+	}
+
+	versions, err := database.ListVersions(p.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	v, err := resolveVersion(database, p.ID, versions, ref)
+	if err != nil {
+		return "", nil, err
+	}
+	if v == nil {
+		return "", nil, fmt.Errorf("version '%s' not found for prompt '%s'", ref, name)
+	}
+	return name, v, nil
+}
+
 func resolveVersion(database *db.DB, promptID string, versions []*db.PromptVersion, ref string) (*db.PromptVersion, error) {
 	// Handle HEAD notation
 	headRegex := regexp.MustCompile(`^HEAD(~(\d+))?$`)
@@ -194,144 +349,10 @@ func resolveVersion(database *db.DB, promptID string, versions []*db.PromptVersi
 	return v, nil
 }
 
-func computeDiff(lines1, lines2 []string) []hunk {
-	// Simple LCS-based diff algorithm
-	m, n := len(lines1), len(lines2)
-
-	// Build LCS table
-	lcs := make([][]int, m+1)
-	for i := range lcs {
-		lcs[i] = make([]int, n+1)
-	}
-	for i := 1; i <= m; i++ {
-		for j := 1; j <= n; j++ {
-			if lines1[i-1] == lines2[j-1] {
-				lcs[i][j] = lcs[i-1][j-1] + 1
-			} else {
-				if lcs[i-1][j] > lcs[i][j-1] {
-					lcs[i][j] = lcs[i-1][j]
-				} else {
-					lcs[i][j] = lcs[i][j-1]
-				}
-			}
-		}
-	}
-
-	// Backtrack to find diff
-	var diffLines []struct {
-		op   rune
-		line string
-		old  int
-		new  int
-	}
-
-	i, j := m, n
-	for i > 0 || j > 0 {
-		if i > 0 && j > 0 && lines1[i-1] == lines2[j-1] {
-			diffLines = append([]struct {
-				op   rune
-				line string
-				old  int
-				new  int
-			}{{' ', lines1[i-1], i, j}}, diffLines...)
-			i--
-			j--
-		} else if j > 0 && (i == 0 || lcs[i][j-1] >= lcs[i-1][j]) {
-			diffLines = append([]struct {
-				op   rune
-				line string
-				old  int
-				new  int
-			}{{'+', lines2[j-1], 0, j}}, diffLines...)
-			j--
-		} else if i > 0 {
-			diffLines = append([]struct {
-				op   rune
-				line string
-				old  int
-				new  int
-			}{{'-', lines1[i-1], i, 0}}, diffLines...)
-			i--
-		}
-	}
-
-	// Group into hunks with context
-	const contextLines = 3
-	var hunks []hunk
-	var currentHunk *hunk
-
-	for idx, dl := range diffLines {
-		if dl.op != ' ' {
-			// Start or extend hunk
-			if currentHunk == nil {
-				currentHunk = &hunk{
-					OldStart: max(1, dl.old),
-					NewStart: max(1, dl.new),
-				}
-				// Add preceding context
-				start := max(0, idx-contextLines)
-				for k := start; k < idx; k++ {
-					if diffLines[k].op == ' ' {
-						currentHunk.Lines = append(currentHunk.Lines, " "+diffLines[k].line)
-						if currentHunk.OldStart == 0 || diffLines[k].old < currentHunk.OldStart {
-							currentHunk.OldStart = diffLines[k].old
-						}
-						if currentHunk.NewStart == 0 || diffLines[k].new < currentHunk.NewStart {
-							currentHunk.NewStart = diffLines[k].new
-						}
-					}
-				}
-			}
-
-			switch dl.op {
-			case '+':
-				currentHunk.Lines = append(currentHunk.Lines, "+"+dl.line)
-				currentHunk.NewCount++
-			case '-':
-				currentHunk.Lines = append(currentHunk.Lines, "-"+dl.line)
-				currentHunk.OldCount++
-			}
-		} else if currentHunk != nil {
-			// Context line after change
-			currentHunk.Lines = append(currentHunk.Lines, " "+dl.line)
-			currentHunk.OldCount++
-			currentHunk.NewCount++
-
-			// Check if we should close hunk
-			nextChange := -1
-			for k := idx + 1; k < len(diffLines) && k <= idx+contextLines+1; k++ {
-				if diffLines[k].op != ' ' {
-					nextChange = k
-					break
-				}
-			}
-			if nextChange == -1 || nextChange > idx+contextLines*2 {
-				// Add trailing context up to contextLines
-				added := 1 // We already added current
-				for k := idx + 1; k < len(diffLines) && added < contextLines; k++ {
-					if diffLines[k].op == ' ' {
-						currentHunk.Lines = append(currentHunk.Lines, " "+diffLines[k].line)
-						currentHunk.OldCount++
-						currentHunk.NewCount++
-						added++
-					} else {
-						break
-					}
-				}
-				hunks = append(hunks, *currentHunk)
-				currentHunk = nil
-			}
-		}
-	}
-
-	if currentHunk != nil {
-		hunks = append(hunks, *currentHunk)
-	}
-
-	return hunks
-}
-
-func printUnifiedDiff(label1, label2 string, hunks []hunk) {
+// printUnifiedDiff prints hunks in unified format. With wordDiff set, a single
+// removed line directly followed by a single added line is printed with only
+// the changed words highlighted.
+func printUnifiedDiff(label1, label2 string, hunks []diff.Hunk, wordDiff bool) {
 	red := color.New(color.FgRed).SprintFunc()
 	green := color.New(color.FgGreen).SprintFunc()
 	cyan := color.New(color.FgCyan).SprintFunc()
@@ -341,7 +362,15 @@ func printUnifiedDiff(label1, label2 string, hunks []hunk) {
 
 	for _, h := range hunks {
 		fmt.Printf("%s\n", cyan(fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldCount, h.NewStart, h.NewCount)))
-		for _, line := range h.Lines {
+		for idx := 0; idx < len(h.Lines); idx++ {
+			line := h.Lines[idx]
+			if wordDiff && isSingleLineChange(h.Lines, idx) {
+				oldSpans, newSpans := computeWordDiff(line[1:], h.Lines[idx+1][1:])
+				printWordDiffLine('-', oldSpans)
+				printWordDiffLine('+', newSpans)
+				idx++
+				continue
+			}
 			if len(line) == 0 {
 				fmt.Println()
 				continue
@@ -357,3 +386,127 @@ func printUnifiedDiff(label1, label2 string, hunks []hunk) {
 		}
 	}
 }
+
+// wordSpan is a run of text within a line that is unchanged (' '), removed
+// ('-') or added ('+')
+type wordSpan struct {
+	Op   rune
+	Text string
+}
+
+// isSingleLineChange reports whether lines[idx] is a lone removed line
+// immediately followed by a lone added line
+func isSingleLineChange(lines []string, idx int) bool {
+	opAt := func(i int) byte {
+		if i < 0 || i >= len(lines) || len(lines[i]) == 0 {
+			return ' '
+		}
+		return lines[i][0]
+	}
+	return opAt(idx) == '-' && opAt(idx+1) == '+' && opAt(idx-1) != '-' && opAt(idx+2) != '+'
+}
+
+// tokenizeWords splits a line into words, whitespace runs and punctuation.
+// Runes from scripts written without spaces (Han, Hiragana, Katakana) are
+// kept as individual tokens so changes inside them stay narrow.
+func tokenizeWords(s string) []string {
+	var tokens []string
+	var current []rune
+	currentKind := 0
+
+	flush := func() {
+		if len(current) > 0 {
+			tokens = append(tokens, string(current))
+			current = current[:0]
+		}
+	}
+
+	for _, r := range s {
+		kind := 0
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			kind = 0
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			kind = 1
+		case unicode.IsSpace(r):
+			kind = 2
+		}
+		if kind == 0 || kind != currentKind {
+			flush()
+		}
+		current = append(current, r)
+		currentKind = kind
+	}
+	flush()
+
+	return tokens
+}
+
+// computeWordDiff compares two versions of a line word by word and returns
+// the spans of each side. Unchanged text appears in both with Op ' '.
+func computeWordDiff(oldLine, newLine string) ([]wordSpan, []wordSpan) {
+	a, b := tokenizeWords(oldLine), tokenizeWords(newLine)
+	m, n := len(a), len(b)
+
+	lcs := make([][]int, m+1)
+	for i := range lcs {
+		lcs[i] = make([]int, n+1)
+	}
+	for i := m - 1; i >= 0; i-- {
+		for j := n - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var oldSpans, newSpans []wordSpan
+	appendSpan := func(spans []wordSpan, op rune, text string) []wordSpan {
+		if len(spans) > 0 && spans[len(spans)-1].Op == op {
+			spans[len(spans)-1].Text += text
+			return spans
+		}
+		return append(spans, wordSpan{Op: op, Text: text})
+	}
+
+	i, j := 0, 0
+	for i < m || j < n {
+		switch {
+		case i < m && j < n && a[i] == b[j]:
+			oldSpans = appendSpan(oldSpans, ' ', a[i])
+			newSpans = appendSpan(newSpans, ' ', b[j])
+			i++
+			j++
+		case i < m && (j == n || lcs[i+1][j] >= lcs[i][j+1]):
+			oldSpans = appendSpan(oldSpans, '-', a[i])
+			i++
+		default:
+			newSpans = appendSpan(newSpans, '+', b[j])
+			j++
+		}
+	}
+
+	return oldSpans, newSpans
+}
+
+func printWordDiffLine(op rune, spans []wordSpan) {
+	lineColor := color.New(color.FgRed)
+	changedColor := color.New(color.FgRed, color.ReverseVideo)
+	if op == '+' {
+		lineColor = color.New(color.FgGreen)
+		changedColor = color.New(color.FgGreen, color.ReverseVideo)
+	}
+
+	var b strings.Builder
+	b.WriteString(lineColor.Sprint(string(op)))
+	for _, span := range spans {
+		if span.Op == ' ' {
+			b.WriteString(lineColor.Sprint(span.Text))
+		} else {
+			b.WriteString(changedColor.Sprint(span.Text))
+		}
+	}
+	fmt.Println(b.String())
+}
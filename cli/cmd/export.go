@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/promptsmith/cli/internal/db"
+	"github.com/promptsmith/cli/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOutput string
+	exportAll    bool
+	exportDir    string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <prompt> | --all --dir <dir>",
+	Short: "Export a prompt's full history as a bundle",
+	Long: `Write a prompt's metadata, every version (content, variables, commit
+message and parent) and its tags to a single JSON bundle. The bundle can be
+recreated in another project with 'promptsmith import'.
+
+Without --output the bundle is written to stdout.
+
+With --all, every prompt is exported to a directory laid out for review in
+git: prompts/<name>.prompt holds the latest content, <name>.history.jsonl
+holds one version per line (oldest first), and manifest.json lists the
+prompts and their tags. The output is deterministic, so exporting an
+unchanged project again produces identical files. Files left by a previous
+export of a prompt that no longer exists are removed.
+
+Examples:
+  promptsmith export summarizer -o summarizer.json
+  promptsmith export summarizer > summarizer.json
+  promptsmith export --all --dir out/`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExport,
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "write the bundle to this file instead of stdout")
+	exportCmd.Flags().BoolVar(&exportAll, "all", false, "export every prompt to the directory given by --dir")
+	exportCmd.Flags().StringVar(&exportDir, "dir", "", "directory to write a project export to (with --all)")
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	if exportAll {
+		if len(args) > 0 {
+			return fmt.Errorf("give either a prompt name or --all, not both")
+		}
+		if exportDir == "" {
+			return fmt.Errorf("--all requires --dir")
+		}
+		if exportOutput != "" {
+			return fmt.Errorf("--output cannot be used with --all; use --dir")
+		}
+		return runExportAll()
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("a prompt name is required (or use --all --dir <dir>)")
+	}
+	if exportDir != "" {
+		return fmt.Errorf("--dir requires --all; use --output for a single prompt")
+	}
+	promptName := args[0]
+
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	database, err := db.Open(projectRoot)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	export, err := database.ExportPrompt(promptName)
+	if err != nil {
+		return err
+	}
+	if export == nil {
+		return fmt.Errorf("prompt '%s' not found", promptName)
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle: %w", err)
+	}
+	data = append(data, '\n')
+
+	if exportOutput == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(exportOutput, data, 0644); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	logging.Debug("wrote export bundle", "path", exportOutput, "prompt", export.Prompt.Name)
+
+	green := color.New(color.FgGreen).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	fmt.Printf("%s Exported %s (%d versions, %d tags) to %s\n", green("✓"), cyan(export.Prompt.Name), len(export.Versions), len(export.Tags), exportOutput)
+	return nil
+}
+
+// projectExportManifest is manifest.json in a project export directory
+type projectExportManifest struct {
+	FormatVersion int                     `json:"format_version"`
+	Prompts       []projectExportedPrompt `json:"prompts"` // By name
+}
+
+type projectExportedPrompt struct {
+	Name          string           `json:"name"`
+	Description   string           `json:"description,omitempty"`
+	FilePath      string           `json:"file_path"` // Path in the project
+	File          string           `json:"file"`      // Latest content, relative to the export directory
+	History       string           `json:"history"`   // Version history, relative to the export directory
+	LatestVersion string           `json:"latest_version"`
+	Versions      int              `json:"versions"`
+	Tags          []db.ExportedTag `json:"tags,omitempty"`
+}
+
+const projectExportManifestFile = "manifest.json"
+
+func runExportAll() error {
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	database, err := db.Open(projectRoot)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	prompts, err := database.ListPrompts()
+	if err != nil {
+		return err
+	}
+
+	manifest := projectExportManifest{
+		FormatVersion: db.ExportFormatVersion,
+		Prompts:       make([]projectExportedPrompt, 0, len(prompts)),
+	}
+	yellow := color.New(color.FgYellow).SprintFunc()
+	written := make(map[string]bool)
+	for _, p := range prompts {
+		export, err := database.ExportPrompt(p.Name)
+		if err != nil {
+			return err
+		}
+		if export == nil || len(export.Versions) == 0 {
+			fmt.Printf("%s Skipped %s: no versions\n", yellow("!"), p.Name)
+			continue
+		}
+
+		entry, err := writePromptExport(exportDir, export)
+		if err != nil {
+			return err
+		}
+		manifest.Prompts = append(manifest.Prompts, *entry)
+		written[entry.File] = true
+		written[entry.History] = true
+	}
+
+	if err := removeStaleExportFiles(exportDir, written); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	manifestPath := filepath.Join(exportDir, projectExportManifestFile)
+	if err := os.WriteFile(manifestPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	logging.Debug("wrote export manifest", "path", manifestPath, "prompts", len(manifest.Prompts))
+
+	green := color.New(color.FgGreen).SprintFunc()
+	fmt.Printf("%s Exported %d prompt(s) to %s\n", green("✓"), len(manifest.Prompts), exportDir)
+	return nil
+}
+
+// writePromptExport writes a prompt's latest content and its history under
+// dir and returns its manifest entry
+func writePromptExport(dir string, export *db.PromptExport) (*projectExportedPrompt, error) {
+	latest := export.Versions[len(export.Versions)-1]
+	entry := &projectExportedPrompt{
+		Name:          export.Prompt.Name,
+		Description:   export.Prompt.Description,
+		FilePath:      export.Prompt.FilePath,
+		File:          filepath.ToSlash(filepath.Join("prompts", export.Prompt.Name+".prompt")),
+		History:       export.Prompt.Name + ".history.jsonl",
+		LatestVersion: latest.Version,
+		Versions:      len(export.Versions),
+		Tags:          export.Tags,
+	}
+
+	filePath, err := safeProjectPath(dir, entry.File)
+	if err != nil {
+		return nil, fmt.Errorf("cannot export prompt %s: %w", export.Prompt.Name, err)
+	}
+	historyPath, err := safeProjectPath(dir, entry.History)
+	if err != nil {
+		return nil, fmt.Errorf("cannot export prompt %s: %w", export.Prompt.Name, err)
+	}
+
+	// One version per line, so a new version shows up in a diff as one
+	// added line. HTML escaping is off to keep prompt markup readable.
+	var history strings.Builder
+	enc := json.NewEncoder(&history)
+	enc.SetEscapeHTML(false)
+	for _, v := range export.Versions {
+		if err := enc.Encode(v); err != nil {
+			return nil, fmt.Errorf("failed to encode version %s: %w", v.Version, err)
+		}
+	}
+
+	for path, content := range map[string]string{filePath: latest.Content, historyPath: history.String()} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		logging.Debug("wrote export file", "path", path, "prompt", export.Prompt.Name)
+	}
+	return entry, nil
+}
+
+// removeStaleExportFiles deletes the files listed in dir's existing
+// manifest that this export did not write, so a deleted or renamed prompt
+// does not linger in the export
+func removeStaleExportFiles(dir string, written map[string]bool) error {
+	data, err := os.ReadFile(filepath.Join(dir, projectExportManifestFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read previous manifest: %w", err)
+	}
+	var previous projectExportManifest
+	if err := json.Unmarshal(data, &previous); err != nil {
+		return fmt.Errorf("failed to parse previous manifest: %w", err)
+	}
+
+	for _, p := range previous.Prompts {
+		for _, file := range []string{p.File, p.History} {
+			if file == "" || written[file] {
+				continue
+			}
+			path, err := safeProjectPath(dir, file)
+			if err != nil {
+				continue
+			}
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove stale %s: %w", file, err)
+			}
+			logging.Debug("removed stale export file", "path", path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/promptsmith/cli/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var freezeCmd = &cobra.Command{
+	Use:   "freeze <prompt>",
+	Short: "Lock a prompt against new versions",
+	Long: `Freeze a prompt so no new versions can be committed until it is unfrozen.
+
+Commits, reverts and the web editor refuse to create versions of a frozen
+prompt. Tags can still be moved.
+
+Examples:
+  promptsmith freeze summarizer
+  promptsmith unfreeze summarizer`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFreeze,
+}
+
+var unfreezeCmd = &cobra.Command{
+	Use:   "unfreeze <prompt>",
+	Short: "Allow new versions of a frozen prompt",
+	Long: `Unfreeze a prompt so new versions can be committed again.
+
+Examples:
+  promptsmith unfreeze summarizer`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUnfreeze,
+}
+
+func init() {
+	rootCmd.AddCommand(freezeCmd)
+	rootCmd.AddCommand(unfreezeCmd)
+}
+
+func runFreeze(cmd *cobra.Command, args []string) error {
+	return setFrozen(args[0], true)
+}
+
+func runUnfreeze(cmd *cobra.Command, args []string) error {
+	return setFrozen(args[0], false)
+}
+
+func setFrozen(promptName string, frozen bool) error {
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	database, err := db.Open(projectRoot)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	p, err := database.GetPromptByName(promptName)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return fmt.Errorf("prompt '%s' not found", promptName)
+	}
+
+	if err := database.SetPromptFrozen(p.ID, frozen); err != nil {
+		return err
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	if frozen {
+		fmt.Printf("%s Froze %s\n", green("✓"), cyan(promptName))
+	} else {
+		fmt.Printf("%s Unfroze %s\n", green("✓"), cyan(promptName))
+	}
+	return nil
+}
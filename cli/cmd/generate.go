@@ -211,6 +211,10 @@ func getProvider(model string) (benchmark.Provider, error) {
 		return benchmark.NewOpenAIProvider()
 	case "anthropic":
 		return benchmark.NewAnthropicProvider()
+	case "google":
+		return benchmark.NewGeminiProvider()
+	case "ollama":
+		return benchmark.NewOllamaProvider()
 	default:
 		return nil, fmt.Errorf("unsupported model: %s (provider: %s)", model, providerName)
 	}
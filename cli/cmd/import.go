@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/promptsmith/cli/internal/db"
+	"github.com/promptsmith/cli/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <bundle.json>",
+	Short: "Import a prompt's history from an export bundle",
+	Long: `Recreate a prompt exported with 'promptsmith export', including every
+version, its commit message and parent, and the prompt's tags. Version
+strings are kept; the prompt and its versions get new IDs in this project.
+
+The import is refused if a prompt with the same name, or a prompt tracking
+the same file, already exists. If the prompt file is missing it is written
+with the latest version's content; an existing file must already match it.
+
+Examples:
+  promptsmith import summarizer.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read bundle: %w", err)
+	}
+	var export db.PromptExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return fmt.Errorf("failed to parse bundle %s: %w", args[0], err)
+	}
+	if len(export.Versions) == 0 {
+		return fmt.Errorf("bundle %s has no versions", args[0])
+	}
+
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	absPath, err := safeProjectPath(projectRoot, export.Prompt.FilePath)
+	if err != nil {
+		return fmt.Errorf("invalid prompt file path '%s': %w", export.Prompt.FilePath, err)
+	}
+
+	latest := export.Versions[len(export.Versions)-1]
+	currentContent, err := os.ReadFile(absPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read current file: %w", err)
+	}
+	fileExists := err == nil
+	if fileExists && hashContent(string(currentContent)) != hashContent(latest.Content) {
+		return fmt.Errorf("%s already exists and differs from %s@%s; move it aside before importing", export.Prompt.FilePath, export.Prompt.Name, latest.Version)
+	}
+
+	database, err := db.Open(projectRoot)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	project, err := database.GetProject()
+	if err != nil {
+		return err
+	}
+	if project == nil {
+		return fmt.Errorf("no project found in database")
+	}
+
+	p, err := database.ImportPrompt(project.ID, &export)
+	if err != nil {
+		return err
+	}
+
+	if !fileExists {
+		if err := os.MkdirAll(filepath.Dir(absPath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(absPath, []byte(latest.Content), 0644); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		logging.Debug("wrote prompt file", "path", absPath, "version", latest.Version)
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	fmt.Printf("%s Imported %s (%d versions, %d tags)\n", green("✓"), cyan(p.Name), len(export.Versions), len(export.Tags))
+	fmt.Printf("  File: %s\n", p.FilePath)
+	fmt.Printf("  Latest: %s\n", latest.Version)
+	return nil
+}
@@ -11,18 +11,49 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+var (
+	initGitHook bool
+	initForce   bool
+	initDBPath  string
+)
+
 var initCmd = &cobra.Command{
 	Use:   "init [project-name]",
 	Short: "Initialize a new PromptSmith project",
-	Long:  `Creates a new PromptSmith project in the current directory with version control for prompts.`,
-	Args:  cobra.MaximumNArgs(1),
-	RunE:  runInit,
+	Long: `Creates a new PromptSmith project in the current directory with version control for prompts.
+
+With --git-hook, also installs a git pre-commit hook that runs
+'promptsmith verify', so git commits are blocked while prompts have
+uncommitted changes. The hook can be added to an existing project by
+running init again with --git-hook.
+
+With --db-path, the SQLite database is created at the given path instead
+of in .promptsmith/, for example on faster local storage when the project
+lives in a synced directory. The path is saved as db_path in the project
+config, and prompts, tests and config stay in the project.
+
+Examples:
+  promptsmith init
+  promptsmith init --db-path ~/.cache/promptsmith/my-project.db
+  promptsmith init my-project --git-hook
+  promptsmith init --git-hook --force   # Replace an existing pre-commit hook`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runInit,
 }
 
 func init() {
+	initCmd.Flags().BoolVar(&initGitHook, "git-hook", false, "install a git pre-commit hook that runs 'promptsmith verify'")
+	initCmd.Flags().StringVar(&initDBPath, "db-path", "", "create the database at this path instead of in .promptsmith/")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "overwrite an existing pre-commit hook")
 	rootCmd.AddCommand(initCmd)
 }
 
+const gitHookScript = `#!/bin/sh
+# Installed by 'promptsmith init --git-hook'.
+# Blocks git commits while tracked prompts have uncommitted changes.
+exec promptsmith verify
+`
+
 type Config struct {
 	Version       int            `yaml:"version"`
 	Project       ProjectConfig  `yaml:"project"`
@@ -31,6 +62,16 @@ type Config struct {
 	BenchmarksDir string         `yaml:"benchmarks_dir"`
 	Defaults      DefaultsConfig `yaml:"defaults"`
 	Sync          SyncConfig     `yaml:"sync,omitempty"`
+	// ModelAliases maps names like "fast" to concrete model IDs, usable
+	// anywhere a model is given
+	ModelAliases map[string]string `yaml:"model_aliases,omitempty"`
+	// CommitMessagePattern is a regexp every commit message must match
+	CommitMessagePattern string `yaml:"commit_message_pattern,omitempty"`
+	// CommitMessageTemplate is used when commit is given no message
+	CommitMessageTemplate string `yaml:"commit_message_template,omitempty"`
+	// DBPath is where the database is stored when not in the config
+	// directory, absolute or relative to the project root
+	DBPath string `yaml:"db_path,omitempty"`
 }
 
 type ProjectConfig struct {
@@ -58,9 +99,19 @@ func runInit(cmd *cobra.Command, args []string) error {
 	// Check if already initialized
 	configDir := filepath.Join(cwd, db.ConfigDir)
 	if _, err := os.Stat(configDir); err == nil {
+		if initGitHook {
+			return installGitHook(cwd)
+		}
 		return fmt.Errorf("project already initialized in %s", cwd)
 	}
 
+	// Refuse up front rather than leave a half-finished init behind
+	if initGitHook && !initForce {
+		if _, err := os.Stat(gitHookPath(cwd)); err == nil {
+			return fmt.Errorf("pre-commit hook already exists (use --force to overwrite)")
+		}
+	}
+
 	// Determine project name
 	projectName := filepath.Base(cwd)
 	if len(args) > 0 {
@@ -68,7 +119,17 @@ func runInit(cmd *cobra.Command, args []string) error {
 	}
 
 	// Initialize database
-	database, err := db.Initialize(cwd)
+	dbPath := ""
+	if initDBPath != "" {
+		dbPath, err = filepath.Abs(initDBPath)
+		if err != nil {
+			return fmt.Errorf("invalid database path: %w", err)
+		}
+		if _, err := os.Stat(dbPath); err == nil {
+			return fmt.Errorf("database already exists at %s", dbPath)
+		}
+	}
+	database, err := db.InitializeAt(cwd, dbPath)
 	if err != nil {
 		return err
 	}
@@ -94,6 +155,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 			Model:       "gpt-4o",
 			Temperature: 0.7,
 		},
+		DBPath: dbPath,
 	}
 
 	configPath := filepath.Join(configDir, db.ConfigFile)
@@ -132,13 +194,56 @@ func runInit(cmd *cobra.Command, args []string) error {
 	fmt.Printf("%s Initialized PromptSmith project %s\n", green("✓"), cyan(projectName))
 	fmt.Printf("\nCreated:\n")
 	fmt.Printf("  %s/\n", db.ConfigDir)
+	if dbPath != "" {
+		fmt.Printf("  %s\n", dbPath)
+	}
 	fmt.Printf("  prompts/\n")
 	fmt.Printf("  tests/\n")
 	fmt.Printf("  benchmarks/\n")
 	fmt.Printf("\nNext steps:\n")
 	fmt.Printf("  1. Create a prompt file in prompts/\n")
 	fmt.Printf("  2. Run %s to track it\n", cyan("promptsmith add <file>"))
-	fmt.Printf("  3. Run %s to commit changes\n", cyan("promptsmith commit -m \"message\""))
+	fmt.Printf("  3. Run %s to commit changes\n", cyan("promptsmith commit --all -m \"message\""))
+
+	if initGitHook {
+		fmt.Println()
+		return installGitHook(cwd)
+	}
+
+	return nil
+}
+
+func gitHookPath(root string) string {
+	return filepath.Join(root, ".git", "hooks", "pre-commit")
+}
+
+// installGitHook writes a pre-commit hook running 'promptsmith verify'. An
+// existing hook is only replaced with --force.
+func installGitHook(root string) error {
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	if info, err := os.Stat(filepath.Join(root, ".git")); err != nil || !info.IsDir() {
+		fmt.Printf("%s No .git directory found, skipping pre-commit hook\n", yellow("!"))
+		return nil
+	}
 
+	hookPath := gitHookPath(root)
+	if _, err := os.Stat(hookPath); err == nil && !initForce {
+		return fmt.Errorf("pre-commit hook already exists (use --force to overwrite)")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	if err := os.WriteFile(hookPath, []byte(gitHookScript), 0755); err != nil {
+		return fmt.Errorf("failed to write pre-commit hook: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file, so set it explicitly
+	if err := os.Chmod(hookPath, 0755); err != nil {
+		return fmt.Errorf("failed to make pre-commit hook executable: %w", err)
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	fmt.Printf("%s Installed git pre-commit hook\n", green("✓"))
 	return nil
 }
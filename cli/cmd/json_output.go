@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// appendJSONOutput adds entries to the JSON array stored at path, creating
+// the file if it does not exist. A file holding a single JSON object, as
+// written without --append, becomes the first element of the array.
+func appendJSONOutput(path string, entries ...json.RawMessage) error {
+	var existing []json.RawMessage
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	data = bytes.TrimSpace(data)
+	switch {
+	case len(data) == 0:
+	case data[0] == '[':
+		if err := json.Unmarshal(data, &existing); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	case data[0] == '{':
+		if !json.Valid(data) {
+			return fmt.Errorf("failed to parse %s: invalid JSON", path)
+		}
+		existing = []json.RawMessage{data}
+	default:
+		return fmt.Errorf("cannot append to %s: it does not hold a JSON object or array", path)
+	}
+
+	out, err := json.MarshalIndent(append(existing, entries...), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
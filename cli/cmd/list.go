@@ -55,6 +55,10 @@ func runList(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(prompts) == 0 {
+		if jsonOut {
+			fmt.Println("[]")
+			return nil
+		}
 		fmt.Println("No prompts tracked yet.")
 		fmt.Printf("Use %s to start tracking prompts.\n", "promptsmith add <file>")
 		return nil
@@ -113,5 +117,7 @@ func runList(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	fmt.Printf("\n%s\n", dim(fmt.Sprintf("%d prompt(s)", len(items))))
+
 	return nil
 }
@@ -3,6 +3,9 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/promptsmith/cli/internal/db"
@@ -12,30 +15,72 @@ import (
 var (
 	logLimit  int
 	logPrompt string
+	logAuthor string
+	logGraph  bool
 )
 
 var logCmd = &cobra.Command{
-	Use:   "log",
+	Use:   "log [prompt] [from..to]",
 	Short: "Show commit history",
-	Long:  `Display the version history of prompts with commit messages and timestamps.`,
-	RunE:  runLog,
+	Long: `Display the version history of prompts with commit messages and timestamps.
+
+A range of two versions, as versions or HEAD notation, lists a prompt's
+versions from one to the other, both included. A range is not cut short by
+the default --limit.
+
+With --graph, each prompt's versions are drawn as a graph linking every
+version to its parent, with tags shown next to the versions they point at.
+Versions whose parent is missing are marked as orphans. The graph shows
+every version unless --limit is given.
+
+Examples:
+  promptsmith log                      # Recent commits across all prompts
+  promptsmith log -p summarizer        # History of one prompt
+  promptsmith log summarizer           # The same
+  promptsmith log summarizer 1.0.0..1.0.3  # Versions 1.0.0 to 1.0.3
+  promptsmith log summarizer HEAD~3..HEAD  # The last four versions
+  promptsmith log --author alice       # Only commits by alice
+  promptsmith log summarizer --graph   # Draw the version graph`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: runLog,
 }
 
 func init() {
 	logCmd.Flags().IntVarP(&logLimit, "limit", "n", 10, "number of entries to show")
 	logCmd.Flags().StringVarP(&logPrompt, "prompt", "p", "", "filter by prompt name")
+	logCmd.Flags().StringVar(&logAuthor, "author", "", "only show versions created by this author")
+	logCmd.Flags().BoolVar(&logGraph, "graph", false, "draw each prompt's versions as a graph of parent links")
 	rootCmd.AddCommand(logCmd)
 }
 
 type logEntry struct {
-	PromptName    string `json:"prompt_name"`
-	Version       string `json:"version"`
-	CommitMessage string `json:"commit_message"`
-	CreatedAt     string `json:"created_at"`
-	CreatedBy     string `json:"created_by"`
+	PromptName    string   `json:"prompt_name"`
+	Version       string   `json:"version"`
+	CommitMessage string   `json:"commit_message"`
+	CreatedAt     string   `json:"created_at"`
+	CreatedBy     string   `json:"created_by"`
+	Tags          []string `json:"tags,omitempty"`
 }
 
 func runLog(cmd *cobra.Command, args []string) error {
+	promptName := logPrompt
+	if len(args) > 0 {
+		if logPrompt != "" && logPrompt != args[0] {
+			return fmt.Errorf("prompt given both as an argument and with --prompt")
+		}
+		promptName = args[0]
+	}
+	if logGraph {
+		switch {
+		case jsonOut:
+			return fmt.Errorf("--graph cannot be used with --json")
+		case len(args) == 2:
+			return fmt.Errorf("--graph cannot be used with a version range")
+		case logAuthor != "":
+			return fmt.Errorf("--graph cannot be used with --author, which would break the parent links")
+		}
+	}
+
 	// Find project root
 	projectRoot, err := db.FindProjectRoot()
 	if err != nil {
@@ -53,25 +98,49 @@ func runLog(cmd *cobra.Command, args []string) error {
 	cyan := color.New(color.FgCyan).SprintFunc()
 	dim := color.New(color.Faint).SprintFunc()
 
-	if logPrompt != "" {
+	if logGraph {
+		limit := 0
+		if cmd.Flags().Changed("limit") {
+			limit = logLimit
+		}
+		return printLogGraph(database, promptName, limit)
+	}
+
+	if promptName != "" {
 		// Show history for specific prompt
-		p, err := database.GetPromptByName(logPrompt)
+		p, err := resolvePrompt(database, promptName)
 		if err != nil {
 			return err
 		}
 		if p == nil {
-			return fmt.Errorf("prompt %s not found", logPrompt)
+			return fmt.Errorf("prompt %s not found", promptName)
 		}
 
-		versions, err := database.ListVersions(p.ID)
-		if err != nil {
-			return err
+		limit := logLimit
+		var versions []*db.PromptVersion
+		if len(args) == 2 {
+			versions, err = listVersionRange(database, p.ID, args[1])
+			if err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("limit") {
+				limit = len(versions)
+			}
+		} else {
+			versions, err = database.ListVersionsByAuthor(p.ID, logAuthor)
+			if err != nil {
+				return err
+			}
 		}
 
 		if jsonOut {
+			tagsByVersion, err := versionTagNames(database, p.ID)
+			if err != nil {
+				return err
+			}
 			entries := make([]logEntry, 0, len(versions))
 			for i, v := range versions {
-				if i >= logLimit {
+				if i >= limit {
 					break
 				}
 				entries = append(entries, logEntry{
@@ -80,6 +149,7 @@ func runLog(cmd *cobra.Command, args []string) error {
 					CommitMessage: v.CommitMessage,
 					CreatedAt:     v.CreatedAt.Format("2006-01-02 15:04:05"),
 					CreatedBy:     v.CreatedBy,
+					Tags:          tagsByVersion[v.ID],
 				})
 			}
 			data, _ := json.MarshalIndent(entries, "", "  ")
@@ -89,7 +159,7 @@ func runLog(cmd *cobra.Command, args []string) error {
 
 		fmt.Printf("History for %s:\n\n", cyan(p.Name))
 		for i, v := range versions {
-			if i >= logLimit {
+			if i >= limit {
 				break
 			}
 			fmt.Printf("%s %s\n", yellow(v.Version), v.CommitMessage)
@@ -104,23 +174,37 @@ func runLog(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if len(results) == 0 {
-		fmt.Println("No commits yet.")
-		return nil
+	if logAuthor != "" {
+		filtered := results[:0]
+		for _, r := range results {
+			if r.Version.CreatedBy == logAuthor {
+				filtered = append(filtered, r)
+			}
+		}
+		results = filtered
 	}
 
 	if jsonOut {
 		entries := make([]logEntry, 0, len(results))
+		tagsByPrompt := make(map[string]map[string][]string)
 		for i, r := range results {
 			if i >= logLimit {
 				break
 			}
+			tagsByVersion, ok := tagsByPrompt[r.Prompt.ID]
+			if !ok {
+				if tagsByVersion, err = versionTagNames(database, r.Prompt.ID); err != nil {
+					return err
+				}
+				tagsByPrompt[r.Prompt.ID] = tagsByVersion
+			}
 			entries = append(entries, logEntry{
 				PromptName:    r.Prompt.Name,
 				Version:       r.Version.Version,
 				CommitMessage: r.Version.CommitMessage,
 				CreatedAt:     r.Version.CreatedAt.Format("2006-01-02 15:04:05"),
 				CreatedBy:     r.Version.CreatedBy,
+				Tags:          tagsByVersion[r.Version.ID],
 			})
 		}
 		data, _ := json.MarshalIndent(entries, "", "  ")
@@ -128,6 +212,11 @@ func runLog(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if len(results) == 0 {
+		fmt.Println("No commits yet.")
+		return nil
+	}
+
 	for i, r := range results {
 		if i >= logLimit {
 			break
@@ -138,3 +227,212 @@ func runLog(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// listVersionRange lists a prompt's versions in a "from..to" range of version
+// refs, keeping only those by --author if it is set
+func listVersionRange(database *db.DB, promptID, spec string) ([]*db.PromptVersion, error) {
+	fromRef, toRef, ok := strings.Cut(spec, "..")
+	if !ok || fromRef == "" || toRef == "" {
+		return nil, fmt.Errorf("invalid range '%s' (expected <from>..<to>, e.g. 1.0.0..1.0.3)", spec)
+	}
+
+	all, err := database.ListVersions(promptID)
+	if err != nil {
+		return nil, err
+	}
+	var endpoints [2]*db.PromptVersion
+	for i, ref := range []string{fromRef, toRef} {
+		v, err := resolveVersion(database, promptID, all, ref)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			return nil, fmt.Errorf("version '%s' not found", ref)
+		}
+		endpoints[i] = v
+	}
+
+	versions, err := database.ListVersionsBetween(promptID, endpoints[0].ID, endpoints[1].ID)
+	if err != nil {
+		return nil, err
+	}
+	if logAuthor == "" {
+		return versions, nil
+	}
+	filtered := versions[:0]
+	for _, v := range versions {
+		if v.CreatedBy == logAuthor {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered, nil
+}
+
+// printLogGraph prints the version graph of the named prompt, or of every
+// prompt if promptName is empty. A positive limit caps the versions drawn
+// per prompt.
+func printLogGraph(database *db.DB, promptName string, limit int) error {
+	var prompts []*db.Prompt
+	if promptName != "" {
+		p, err := resolvePrompt(database, promptName)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			return fmt.Errorf("prompt %s not found", promptName)
+		}
+		prompts = []*db.Prompt{p}
+	} else {
+		var err error
+		if prompts, err = database.ListPrompts(); err != nil {
+			return err
+		}
+	}
+
+	cyan := color.New(color.FgCyan).SprintFunc()
+	printed := 0
+	for _, p := range prompts {
+		versions, err := database.ListVersions(p.ID)
+		if err != nil {
+			return err
+		}
+		if len(versions) == 0 {
+			continue
+		}
+		tagsByVersion, err := versionTagNames(database, p.ID)
+		if err != nil {
+			return err
+		}
+
+		if printed > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("History for %s:\n\n", cyan(p.Name))
+		for _, line := range versionGraph(versions, tagsByVersion, limit) {
+			fmt.Println(line)
+		}
+		printed++
+	}
+
+	if printed == 0 {
+		fmt.Println("No commits yet.")
+	}
+	return nil
+}
+
+// versionTagNames returns the names of a prompt's tags by the ID of the
+// version they point at
+func versionTagNames(database *db.DB, promptID string) (map[string][]string, error) {
+	tags, err := database.ListTags(promptID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string][]string)
+	for _, t := range tags {
+		names[t.VersionID] = append(names[t.VersionID], t.Name)
+	}
+	return names, nil
+}
+
+// versionGraph draws versions, newest first, as a graph in the style of
+// git log --graph. Each version is a * in a column, and | carries a column
+// down to the version's parent. Columns waiting on the same parent are
+// joined with / just above it. A version whose parent is not among versions
+// is marked as an orphan. A positive limit stops after that many versions.
+func versionGraph(versions []*db.PromptVersion, tagsByVersion map[string][]string, limit int) []string {
+	yellow := color.New(color.FgYellow).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+
+	pending := make(map[string]bool, len(versions)) // Versions not yet drawn
+	for _, v := range versions {
+		pending[v.ID] = true
+	}
+	known := maps.Clone(pending)
+
+	// Each column holds the ID of the version it is waiting for
+	var columns []string
+	var lines []string
+	for n, v := range versions {
+		if limit > 0 && n >= limit {
+			break
+		}
+		delete(pending, v.ID)
+
+		var waiting []int
+		for i, id := range columns {
+			if id == v.ID {
+				waiting = append(waiting, i)
+			}
+		}
+		if len(waiting) > 1 {
+			lines = append(lines, graphShiftLine(len(columns), waiting[1:], false))
+			columns = removeColumns(columns, waiting[1:])
+		}
+		col := len(columns)
+		if len(waiting) > 0 {
+			col = waiting[0]
+		} else {
+			columns = append(columns, v.ID)
+		}
+
+		cells := make([]string, len(columns))
+		for i := range cells {
+			cells[i] = "|"
+		}
+		cells[col] = "*"
+		line := strings.Join(cells, " ") + " " + yellow(v.Version)
+		if names := tagsByVersion[v.ID]; len(names) > 0 {
+			line += " " + green("("+strings.Join(names, ", ")+")")
+		}
+		if v.CommitMessage != "" {
+			line += " " + v.CommitMessage
+		}
+		line += " " + dim(fmt.Sprintf("%s by %s", v.CreatedAt.Format("2006-01-02 15:04"), v.CreatedBy))
+		if v.ParentVersionID != nil && !known[*v.ParentVersionID] {
+			line += " " + red("(orphan: parent missing)")
+		}
+		lines = append(lines, line)
+
+		// The column moves on to the parent, or ends at a root or orphan
+		if v.ParentVersionID != nil && pending[*v.ParentVersionID] {
+			columns[col] = *v.ParentVersionID
+			continue
+		}
+		if col < len(columns)-1 {
+			lines = append(lines, graphShiftLine(len(columns), []int{col}, true))
+		}
+		columns = removeColumns(columns, []int{col})
+	}
+	return lines
+}
+
+// graphShiftLine draws the line below which the dropped columns, in
+// ascending order, are removed. Every column right of the first dropped one
+// moves left, drawn as a / between it and its neighbour, except a column
+// whose line ends there, which is left blank.
+func graphShiftLine(width int, dropped []int, ends bool) string {
+	cells := []byte(strings.Repeat(" ", 2*width))
+	for i := range width {
+		switch {
+		case i < dropped[0]:
+			cells[2*i] = '|'
+		case ends && i == dropped[0]:
+		default:
+			cells[2*i-1] = '/'
+		}
+	}
+	return strings.TrimRight(string(cells), " ")
+}
+
+// removeColumns returns columns without the given indexes
+func removeColumns(columns []string, indexes []int) []string {
+	kept := make([]string, 0, len(columns))
+	for i, id := range columns {
+		if !slices.Contains(indexes, i) {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
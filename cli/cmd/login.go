@@ -24,19 +24,28 @@ You can authenticate using:
   1. Interactive email/password login
   2. API token (--token flag or PROMPTSMITH_TOKEN env var)
 
+When stdin is not a terminal, the password is read from the first line of
+stdin so login can be scripted.
+
 Examples:
   promptsmith login                    # Interactive login
-  promptsmith login --token <token>    # Token-based login`,
+  promptsmith login --email me@example.com
+  promptsmith login --token <token>    # Token-based login
+  promptsmith login --remote https://sync.example.com`,
 	RunE: runLogin,
 }
 
 var (
-	loginToken string
+	loginToken  string
+	loginEmail  string
+	loginRemote string
 )
 
 func init() {
 	rootCmd.AddCommand(loginCmd)
 	loginCmd.Flags().StringVar(&loginToken, "token", "", "API token for authentication")
+	loginCmd.Flags().StringVar(&loginEmail, "email", "", "email address (skips the email prompt)")
+	loginCmd.Flags().StringVar(&loginRemote, "remote", "", "sync server URL (defaults to sync.remote from config)")
 }
 
 func runLogin(cmd *cobra.Command, args []string) error {
@@ -46,14 +55,7 @@ func runLogin(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	// Determine remote URL
-	remote := sync.DefaultRemote
-	if projectRoot, err := db.FindProjectRoot(); err == nil {
-		if config, err := loadConfig(projectRoot); err == nil && config.Sync.Remote != "" {
-			remote = config.Sync.Remote
-		}
-	}
-
+	remote := resolveSyncRemote(loginRemote)
 	client := sync.NewClient(remote)
 
 	green := color.New(color.FgGreen).SprintFunc()
@@ -79,20 +81,22 @@ func runLogin(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Log in to %s\n\n", cyan(remote))
 
-	fmt.Print("Email: ")
-	email, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("failed to read email: %w", err)
+	email := loginEmail
+	if email == "" {
+		fmt.Print("Email: ")
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read email: %w", err)
+		}
+		email = strings.TrimSpace(line)
 	}
-	email = strings.TrimSpace(email)
 
 	fmt.Print("Password: ")
-	passwordBytes, err := term.ReadPassword(int(syscall.Stdin))
+	password, err := readPassword(reader)
 	if err != nil {
 		return fmt.Errorf("failed to read password: %w", err)
 	}
 	fmt.Println()
-	password := string(passwordBytes)
 
 	auth, err := client.Login(email, password)
 	if err != nil {
@@ -107,6 +111,38 @@ func runLogin(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// readPassword reads a password without echo from a terminal, or the next
+// line of stdin when input is piped.
+func readPassword(reader *bufio.Reader) (string, error) {
+	if term.IsTerminal(int(syscall.Stdin)) {
+		passwordBytes, err := term.ReadPassword(int(syscall.Stdin))
+		if err != nil {
+			return "", err
+		}
+		return string(passwordBytes), nil
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// resolveSyncRemote returns override if set, otherwise the project's
+// sync.remote, falling back to the default remote.
+func resolveSyncRemote(override string) string {
+	if override != "" {
+		return override
+	}
+	if projectRoot, err := db.FindProjectRoot(); err == nil {
+		if config, err := loadConfig(projectRoot); err == nil && config.Sync.Remote != "" {
+			return config.Sync.Remote
+		}
+	}
+	return sync.DefaultRemote
+}
+
 func getGlobalConfigDir() string {
 	// Try to use project-local config dir first
 	if projectRoot, err := db.FindProjectRoot(); err == nil {
@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/fatih/color"
-	"github.com/promptsmith/cli/internal/db"
 	"github.com/promptsmith/cli/internal/sync"
 	"github.com/spf13/cobra"
 )
@@ -12,26 +11,27 @@ import (
 var logoutCmd = &cobra.Command{
 	Use:   "logout",
 	Short: "Log out from PromptSmith cloud",
-	Long:  `Remove stored authentication credentials.`,
-	RunE:  runLogout,
+	Long: `Remove stored authentication credentials.
+
+Examples:
+  promptsmith logout
+  promptsmith logout --remote https://sync.example.com`,
+	RunE: runLogout,
 }
 
+var (
+	logoutRemote string
+)
+
 func init() {
 	rootCmd.AddCommand(logoutCmd)
+	logoutCmd.Flags().StringVar(&logoutRemote, "remote", "", "sync server URL (defaults to sync.remote from config)")
 }
 
 func runLogout(cmd *cobra.Command, args []string) error {
 	configDir := getGlobalConfigDir()
 
-	// Determine remote URL
-	remote := sync.DefaultRemote
-	if projectRoot, err := db.FindProjectRoot(); err == nil {
-		if config, err := loadConfig(projectRoot); err == nil && config.Sync.Remote != "" {
-			remote = config.Sync.Remote
-		}
-	}
-
-	client := sync.NewClient(remote)
+	client := sync.NewClient(resolveSyncRemote(logoutRemote))
 
 	// Try to load token and logout from server
 	if err := client.LoadToken(configDir); err == nil {
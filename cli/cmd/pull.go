@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/fatih/color"
 	"github.com/promptsmith/cli/internal/db"
+	"github.com/promptsmith/cli/internal/logging"
 	"github.com/promptsmith/cli/internal/sync"
 	"github.com/spf13/cobra"
 )
@@ -19,22 +21,58 @@ var pullCmd = &cobra.Command{
 This command pulls all prompts, versions, and tags from the remote server,
 updating your local project with any changes from collaborators.
 
+A version that exists both locally and remotely with different content has
+diverged. --strategy controls how it is resolved:
+  ours     keep the local content (default)
+  theirs   commit the remote content as a new version whose parent is the
+           local one, and update the prompt file unless it has edits
+  manual   keep the local content, write the remote copy next to the prompt
+           file as <file>.theirs, and flag the version for review until
+           the prompt is next committed; status lists flagged versions
+
+Local versions are never rewritten. A divergence resolved with theirs, or
+reviewed with a commit, is not reported again unless the remote copy
+changes.
+
 Examples:
-  promptsmith pull              # Pull all changes
-  promptsmith pull --force      # Force pull, overwriting local changes`,
+  promptsmith pull                     # Pull all changes
+  promptsmith pull --strategy theirs   # Prefer remote content on divergence
+  promptsmith pull --strategy manual   # Write both copies for review
+  promptsmith pull --force             # Same as --strategy theirs`,
 	RunE: runPull,
 }
 
 var (
-	pullForce bool
+	pullForce    bool
+	pullStrategy string
+)
+
+const (
+	pullStrategyOurs   = "ours"
+	pullStrategyTheirs = "theirs"
+	pullStrategyManual = "manual"
 )
 
 func init() {
 	rootCmd.AddCommand(pullCmd)
-	pullCmd.Flags().BoolVar(&pullForce, "force", false, "Force pull, overwriting local changes")
+	pullCmd.Flags().BoolVar(&pullForce, "force", false, "same as --strategy theirs")
+	pullCmd.Flags().StringVar(&pullStrategy, "strategy", pullStrategyOurs, "resolution for diverged versions: ours, theirs, or manual")
 }
 
 func runPull(cmd *cobra.Command, args []string) error {
+	strategy := pullStrategy
+	if strategy == "" {
+		strategy = pullStrategyOurs
+	}
+	if pullForce {
+		strategy = pullStrategyTheirs
+	}
+	switch strategy {
+	case pullStrategyOurs, pullStrategyTheirs, pullStrategyManual:
+	default:
+		return fmt.Errorf("invalid strategy '%s' (use ours, theirs, or manual)", strategy)
+	}
+
 	projectRoot, err := db.FindProjectRoot()
 	if err != nil {
 		return err
@@ -90,6 +128,7 @@ func runPull(cmd *cobra.Command, args []string) error {
 	var promptsAdded, promptsUpdated int
 	var versionsAdded int
 	var tagsAdded int
+	var diverged []string
 
 	// Sync prompts
 	for _, rp := range resp.Prompts {
@@ -122,17 +161,39 @@ func runPull(cmd *cobra.Command, args []string) error {
 						if err := os.WriteFile(safeFilePath, []byte(v.Content), 0644); err != nil {
 							return fmt.Errorf("failed to write prompt file %s: %w", rp.Name, err)
 						}
+						logging.Debug("wrote prompt file", "path", safeFilePath, "version", v.Version)
 						break
 					}
 				}
 			}
-		} else {
+		} else if localPrompt.Description != rp.Description {
+			if _, err := database.UpdatePrompt(localPrompt.ID, localPrompt.Name, rp.Description); err != nil {
+				return fmt.Errorf("failed to update prompt %s: %w", rp.Name, err)
+			}
 			promptsUpdated++
 		}
 	}
 
-	// Sync versions
-	for _, rv := range resp.Versions {
+	// Sync versions oldest first so parents exist before their children.
+	// Remote version IDs are mapped to local ones to rebuild parent links.
+	remoteVersions := make([]sync.PromptVersion, len(resp.Versions))
+	copy(remoteVersions, resp.Versions)
+	sort.SliceStable(remoteVersions, func(i, j int) bool {
+		return remoteVersions[i].CreatedAt.Before(remoteVersions[j].CreatedAt)
+	})
+	localVersionIDs := make(map[string]string)
+
+	// Versions arriving in this pull, by remote prompt ID. Remote content
+	// taken over a diverged version must not be numbered as one of them.
+	incomingVersions := make(map[string]map[string]bool)
+	for _, rv := range remoteVersions {
+		if incomingVersions[rv.PromptID] == nil {
+			incomingVersions[rv.PromptID] = make(map[string]bool)
+		}
+		incomingVersions[rv.PromptID][rv.Version] = true
+	}
+
+	for _, rv := range remoteVersions {
 		// Get local prompt by finding matching remote prompt
 		var promptName string
 		for _, rp := range resp.Prompts {
@@ -156,23 +217,50 @@ func runPull(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("failed to check version %s: %w", rv.Version, err)
 		}
 
-		if existingVersion == nil {
-			// Create version
-			_, err := database.CreateVersion(
-				localPrompt.ID,
-				rv.Version,
-				rv.Content,
-				rv.Variables,
-				rv.Metadata,
-				rv.CommitMessage,
-				rv.CreatedBy,
-				rv.ParentVersionID,
-			)
+		if existingVersion != nil {
+			localVersionIDs[rv.ID] = existingVersion.ID
+			if existingVersion.Content == rv.Content {
+				continue
+			}
+			// A divergence settled by an earlier pull or a reviewed commit
+			// stays settled until the remote content changes again
+			conflict, err := database.GetPullConflict(localPrompt.ID, rv.Version)
+			if err != nil {
+				return err
+			}
+			if conflict != nil && conflict.ResolvedVersionID != nil && conflict.RemoteContent == rv.Content {
+				continue
+			}
+			resolution, err := resolveDivergedVersion(database, projectRoot, localPrompt, existingVersion, rv, incomingVersions[rv.PromptID], strategy)
 			if err != nil {
-				return fmt.Errorf("failed to create version %s: %w", rv.Version, err)
+				return err
+			}
+			diverged = append(diverged, fmt.Sprintf("%s@%s: %s", localPrompt.Name, rv.Version, resolution))
+			continue
+		}
+
+		var parentID *string
+		if rv.ParentVersionID != nil {
+			if localID, ok := localVersionIDs[*rv.ParentVersionID]; ok {
+				parentID = &localID
 			}
-			versionsAdded++
 		}
+
+		created, err := database.CreateVersion(
+			localPrompt.ID,
+			rv.Version,
+			rv.Content,
+			rv.Variables,
+			rv.Metadata,
+			rv.CommitMessage,
+			rv.CreatedBy,
+			parentID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create version %s: %w", rv.Version, err)
+		}
+		localVersionIDs[rv.ID] = created.ID
+		versionsAdded++
 	}
 
 	// Sync tags
@@ -227,7 +315,16 @@ func runPull(cmd *cobra.Command, args []string) error {
 	}
 
 	// Report results
-	if promptsAdded == 0 && versionsAdded == 0 && tagsAdded == 0 {
+	if len(diverged) > 0 {
+		yellow := color.New(color.FgYellow).SprintFunc()
+		fmt.Printf("%s Diverged versions (strategy: %s):\n", yellow("⚠"), strategy)
+		for _, d := range diverged {
+			fmt.Printf("  %s\n", d)
+		}
+		fmt.Println()
+	}
+
+	if promptsAdded == 0 && promptsUpdated == 0 && versionsAdded == 0 && tagsAdded == 0 {
 		fmt.Printf("%s Already up to date\n", green("✓"))
 	} else {
 		fmt.Printf("%s Pulled changes:\n", green("✓"))
@@ -235,7 +332,7 @@ func runPull(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  %d new prompt(s)\n", promptsAdded)
 		}
 		if promptsUpdated > 0 {
-			fmt.Printf("  %d prompt(s) checked\n", promptsUpdated)
+			fmt.Printf("  %d updated prompt(s)\n", promptsUpdated)
 		}
 		if versionsAdded > 0 {
 			fmt.Printf("  %d new version(s)\n", versionsAdded)
@@ -251,3 +348,112 @@ func runPull(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// resolveDivergedVersion applies strategy to a version whose local content
+// differs from the remote copy and describes what was done. Local versions
+// are never rewritten: theirs commits the remote content as a new version
+// on top of the local one, and manual records the conflict for review.
+// incoming holds the remote version strings of this pull, which theirs
+// skips when numbering the new version.
+func resolveDivergedVersion(database *db.DB, projectRoot string, prompt *db.Prompt, local *db.PromptVersion, remote sync.PromptVersion, incoming map[string]bool, strategy string) (string, error) {
+	promptPath, err := safeProjectPath(projectRoot, prompt.FilePath)
+	if err != nil {
+		return "", fmt.Errorf("invalid path for prompt %s: %w", prompt.Name, err)
+	}
+
+	switch strategy {
+	case pullStrategyTheirs:
+		latest, err := database.GetLatestVersion(prompt.ID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get latest version: %w", err)
+		}
+		version, err := nextFreeVersion(database, prompt.ID, latest.Version, incoming)
+		if err != nil {
+			return "", err
+		}
+		author := remote.CreatedBy
+		if author == "" {
+			author = resolveAuthor(projectRoot, "")
+		}
+		v, err := database.CreateVersion(
+			prompt.ID,
+			version,
+			remote.Content,
+			remote.Variables,
+			remote.Metadata,
+			fmt.Sprintf("Take remote content of %s", remote.Version),
+			author,
+			&local.ID,
+		)
+		if err != nil {
+			return "", fmt.Errorf("failed to store remote content of %s: %w", remote.Version, err)
+		}
+		if err := database.RecordPullConflict(&db.PullConflict{
+			PromptID:          prompt.ID,
+			Version:           remote.Version,
+			RemoteContent:     remote.Content,
+			Strategy:          strategy,
+			ResolvedVersionID: &v.ID,
+		}); err != nil {
+			return "", err
+		}
+
+		// Update the prompt file unless it has uncommitted edits
+		current, err := os.ReadFile(promptPath)
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read prompt file %s: %w", prompt.Name, err)
+		}
+		if err == nil && string(current) != latest.Content {
+			return fmt.Sprintf("remote content committed as %s; %s has uncommitted edits and was left alone", v.Version, prompt.FilePath), nil
+		}
+		if err := os.WriteFile(promptPath, []byte(remote.Content), 0644); err != nil {
+			return "", fmt.Errorf("failed to write prompt file %s: %w", prompt.Name, err)
+		}
+		logging.Debug("wrote prompt file", "path", promptPath, "version", v.Version)
+		return fmt.Sprintf("remote content committed as %s", v.Version), nil
+
+	case pullStrategyManual:
+		theirsPath := promptPath + ".theirs"
+		if err := os.MkdirAll(filepath.Dir(theirsPath), 0755); err != nil {
+			return "", fmt.Errorf("failed to create directory for %s: %w", prompt.Name, err)
+		}
+		if err := os.WriteFile(theirsPath, []byte(remote.Content), 0644); err != nil {
+			return "", fmt.Errorf("failed to write remote copy of %s: %w", prompt.Name, err)
+		}
+		logging.Debug("wrote prompt file", "path", theirsPath, "version", remote.Version)
+		if err := database.RecordPullConflict(&db.PullConflict{
+			PromptID:      prompt.ID,
+			Version:       remote.Version,
+			RemoteContent: remote.Content,
+			Strategy:      strategy,
+		}); err != nil {
+			return "", err
+		}
+		rel, err := filepath.Rel(projectRoot, theirsPath)
+		if err != nil {
+			rel = theirsPath
+		}
+		return fmt.Sprintf("needs review, remote copy written to %s", rel), nil
+
+	default:
+		return "kept local", nil
+	}
+}
+
+// nextFreeVersion bumps from until the result is neither a local version of
+// the prompt nor one of the reserved remote versions
+func nextFreeVersion(database *db.DB, promptID, from string, reserved map[string]bool) (string, error) {
+	version := bumpVersion(from)
+	for {
+		if !reserved[version] {
+			existing, err := database.GetVersionByString(promptID, version)
+			if err != nil {
+				return "", fmt.Errorf("failed to check version %s: %w", version, err)
+			}
+			if existing == nil {
+				return version, nil
+			}
+		}
+		version = bumpVersion(version)
+	}
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/promptsmith/cli/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var renameNoFile bool
+
+var renameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a prompt, keeping its history",
+	Long: `Rename a tracked prompt. Versions, tags and comments stay with the prompt.
+
+The prompt file is renamed to match (e.g. prompts/old.prompt becomes
+prompts/new.prompt) unless --no-file is given. A name: in the file's front
+matter is left as is.
+
+The old name is kept as an alias, so tests, benchmarks and chains that
+still use it keep working. See 'promptsmith alias'.
+
+Examples:
+  promptsmith rename summarizer summarizer-v1
+  promptsmith rename summarizer digest --no-file  # Keep the file where it is`,
+	Args: cobra.ExactArgs(2),
+	RunE: runRename,
+}
+
+func init() {
+	renameCmd.Flags().BoolVar(&renameNoFile, "no-file", false, "rename only the tracked prompt, not its file")
+	rootCmd.AddCommand(renameCmd)
+}
+
+func runRename(cmd *cobra.Command, args []string) error {
+	oldName, newName := args[0], args[1]
+
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	database, err := db.Open(projectRoot)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	p, err := database.GetPromptByName(oldName)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return fmt.Errorf("prompt '%s' not found", oldName)
+	}
+
+	existing, err := database.GetPromptByName(newName)
+	if err != nil {
+		return err
+	}
+	// Renaming back to one of the prompt's own aliases is allowed
+	if existing != nil && existing.ID != p.ID {
+		return fmt.Errorf("prompt '%s' already exists", newName)
+	}
+
+	newPath := p.FilePath
+	if !renameNoFile {
+		newPath = filepath.Join(filepath.Dir(p.FilePath), newName+filepath.Ext(p.FilePath))
+		if err := movePromptFile(projectRoot, p.FilePath, newPath); err != nil {
+			return err
+		}
+	}
+
+	// Put the file back if the database can't follow it
+	if err := renamePromptRecord(database, p, newName, newPath); err != nil {
+		if newPath != p.FilePath {
+			movePromptFile(projectRoot, newPath, p.FilePath)
+		}
+		return err
+	}
+	if err := database.RecordRename(p.ID, p.Name, newName); err != nil {
+		return err
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	fmt.Printf("%s Renamed %s to %s\n", green("✓"), cyan(p.Name), cyan(newName))
+	if newPath != p.FilePath {
+		fmt.Printf("  File: %s → %s\n", p.FilePath, newPath)
+	}
+	fmt.Printf("  Alias: %s → %s\n", p.Name, newName)
+
+	return nil
+}
+
+// renamePromptRecord updates a prompt's name and file path, restoring the
+// old name if the path can't be changed
+func renamePromptRecord(database *db.DB, p *db.Prompt, newName, newPath string) error {
+	if _, err := database.UpdatePrompt(p.ID, newName, p.Description); err != nil {
+		return err
+	}
+	if newPath == p.FilePath {
+		return nil
+	}
+	if err := database.UpdatePromptFilePath(p.ID, newPath); err != nil {
+		database.UpdatePrompt(p.ID, p.Name, p.Description)
+		return err
+	}
+	return nil
+}
+
+// movePromptFile renames a prompt file within the project, refusing to
+// overwrite an existing file
+func movePromptFile(projectRoot, from, to string) error {
+	src, err := safeProjectPath(projectRoot, from)
+	if err != nil {
+		return err
+	}
+	dst, err := safeProjectPath(projectRoot, to)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("file '%s' already exists", to)
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("failed to rename %s: %w", from, err)
+	}
+	return nil
+}
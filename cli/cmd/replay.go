@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/promptsmith/cli/internal/db"
+	"github.com/promptsmith/cli/internal/testing"
+	"github.com/spf13/cobra"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <run-id>",
+	Short: "Re-run a stored test run and compare the results",
+	Long: `Re-run the test suite of a stored test run against the prompt version it
+originally tested, and show which tests changed between pass and fail.
+
+The suite is loaded from tests/ by name, so edits to its test cases since the
+run are picked up. Exits with status 1 if a test that passed in the stored run
+now fails.
+
+Examples:
+  promptsmith replay 3f2b9c4e-8a1d-4c6e-9b7f-2d5e8a1c4b6f
+  promptsmith replay 3f2b9c4e-8a1d-4c6e-9b7f-2d5e8a1c4b6f --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReplay,
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+}
+
+// replayChange compares one test's outcome in the stored run with the replay.
+// Before or After is empty when the test only exists on one side.
+type replayChange struct {
+	TestName string `json:"test_name"`
+	Row      int    `json:"row,omitempty"`
+	Before   string `json:"before"`
+	After    string `json:"after"`
+}
+
+type replayOutput struct {
+	RunID       string               `json:"run_id"`
+	Suite       string               `json:"suite"`
+	Version     string               `json:"version"`
+	Changes     []replayChange       `json:"changes"`
+	Regressions int                  `json:"regressions"`
+	Result      *testing.SuiteResult `json:"result"`
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	runID := args[0]
+
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	database, err := db.Open(projectRoot)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	run, err := database.GetTestRun(runID)
+	if err != nil {
+		return err
+	}
+	if run == nil {
+		return fmt.Errorf("test run '%s' not found", runID)
+	}
+
+	var stored testing.SuiteResult
+	if err := json.Unmarshal([]byte(run.Results), &stored); err != nil {
+		return fmt.Errorf("failed to parse results of run %s: %w", runID, err)
+	}
+
+	suiteFiles, err := filepath.Glob(filepath.Join(projectRoot, "tests", "*.test.yaml"))
+	if err != nil {
+		return fmt.Errorf("failed to find test files: %w", err)
+	}
+	suiteFiles, err = filterSuitesByName(suiteFiles, run.SuiteID)
+	if err != nil {
+		return err
+	}
+	suite, err := testing.ParseSuiteFile(suiteFiles[0])
+	if err != nil {
+		return err
+	}
+
+	// Test the version the stored run tested, not whatever is latest now
+	if stored.Version != "" {
+		suite.Version = stored.Version
+	}
+
+	runner := testing.NewRunner(database, nil)
+	result, err := runner.Run(context.Background(), suite)
+	if err != nil {
+		return err
+	}
+
+	changes := compareSuiteResults(&stored, result)
+	regressions := 0
+	for _, c := range changes {
+		if c.Before == "passed" && c.After == "failed" {
+			regressions++
+		}
+	}
+
+	if jsonOut {
+		data, _ := json.MarshalIndent(replayOutput{
+			RunID:       run.ID,
+			Suite:       run.SuiteID,
+			Version:     result.Version,
+			Changes:     changes,
+			Regressions: regressions,
+			Result:      result,
+		}, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		printReplay(run, result, changes)
+	}
+
+	if regressions > 0 {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+// testOutcome names a result as stored in a run: passed, failed or skipped
+func testOutcome(tr testing.TestResult) string {
+	switch {
+	case tr.Skipped:
+		return "skipped"
+	case tr.Passed:
+		return "passed"
+	default:
+		return "failed"
+	}
+}
+
+// compareSuiteResults pairs tests by name and dataset row, in replay order
+// followed by any tests that no longer exist
+func compareSuiteResults(before, after *testing.SuiteResult) []replayChange {
+	type key struct {
+		name string
+		row  int
+	}
+
+	previous := make(map[key]string, len(before.Results))
+	for _, tr := range before.Results {
+		previous[key{tr.TestName, tr.Row}] = testOutcome(tr)
+	}
+
+	changes := make([]replayChange, 0, len(after.Results))
+	seen := make(map[key]bool, len(after.Results))
+	for _, tr := range after.Results {
+		k := key{tr.TestName, tr.Row}
+		seen[k] = true
+		changes = append(changes, replayChange{
+			TestName: tr.TestName,
+			Row:      tr.Row,
+			Before:   previous[k],
+			After:    testOutcome(tr),
+		})
+	}
+	for _, tr := range before.Results {
+		k := key{tr.TestName, tr.Row}
+		if !seen[k] {
+			changes = append(changes, replayChange{
+				TestName: tr.TestName,
+				Row:      tr.Row,
+				Before:   previous[k],
+			})
+		}
+	}
+	return changes
+}
+
+func printReplay(run *db.TestRun, result *testing.SuiteResult, changes []replayChange) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+
+	fmt.Printf("Replaying run %s %s\n", run.ID, dim("("+run.StartedAt.Format("2006-01-02 15:04")+")"))
+	fmt.Printf("\n%s %s@%s\n", cyan("▶"), result.PromptName, result.Version)
+
+	changed := 0
+	for _, c := range changes {
+		name := c.TestName
+		if c.Row > 0 {
+			name = fmt.Sprintf("%s [row %d]", name, c.Row)
+		}
+
+		switch {
+		case c.Before == "":
+			fmt.Printf("  %s %s %s\n", yellow("+"), name, dim("(new, "+c.After+")"))
+		case c.After == "":
+			fmt.Printf("  %s %s %s\n", yellow("-"), name, dim("(removed, was "+c.Before+")"))
+		case c.Before == c.After:
+			fmt.Printf("  %s %s\n", dim("="), dim(name+" "+c.After))
+			continue
+		case c.After == "failed":
+			fmt.Printf("  %s %s %s → %s\n", red("✗"), name, c.Before, red(c.After))
+		case c.After == "passed":
+			fmt.Printf("  %s %s %s → %s\n", green("✓"), name, c.Before, green(c.After))
+		default:
+			fmt.Printf("  %s %s %s → %s\n", yellow("○"), name, c.Before, yellow(c.After))
+		}
+		changed++
+	}
+
+	fmt.Println()
+	if changed == 0 {
+		fmt.Printf("%s Same results as the stored run\n", green("✓"))
+	} else {
+		fmt.Printf("%d test(s) changed since the stored run\n", changed)
+	}
+}
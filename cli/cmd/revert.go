@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/promptsmith/cli/internal/db"
+	"github.com/promptsmith/cli/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+var revertMessage string
+
+var revertCmd = &cobra.Command{
+	Use:   "revert <prompt> <version|tag>",
+	Short: "Restore a prior version as a new commit",
+	Long: `Restore a prompt to an earlier version by committing its content again.
+
+Unlike checkout, revert records a new version on top of the latest one, so
+the newer versions stay in history. References are resolved the same way as
+checkout: version number, tag name, or HEAD notation.
+
+Examples:
+  promptsmith revert summarizer 1.0.1                 # Revert to version 1.0.1
+  promptsmith revert summarizer prod                  # Revert to the tagged version
+  promptsmith revert summarizer HEAD~1 -m "Roll back"  # Custom commit message`,
+	Args: cobra.ExactArgs(2),
+	RunE: runRevert,
+}
+
+func init() {
+	revertCmd.Flags().StringVarP(&revertMessage, "message", "m", "", "commit message (default \"Revert to <version>\")")
+	rootCmd.AddCommand(revertCmd)
+}
+
+func runRevert(cmd *cobra.Command, args []string) error {
+	promptName := args[0]
+	ref := args[1]
+
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	database, err := db.Open(projectRoot)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	p, err := database.GetPromptByName(promptName)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return fmt.Errorf("prompt '%s' not found", promptName)
+	}
+
+	versions, err := database.ListVersions(p.ID)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("no versions found for prompt '%s'", promptName)
+	}
+
+	targetVersion, err := resolveCheckoutRef(database, p.ID, versions, ref)
+	if err != nil {
+		return err
+	}
+	if targetVersion == nil {
+		return fmt.Errorf("version or tag '%s' not found", ref)
+	}
+
+	latest := versions[0]
+	if targetVersion.Content == latest.Content {
+		return fmt.Errorf("%s@%s already matches %s", p.Name, latest.Version, targetVersion.Version)
+	}
+
+	absPath := filepath.Join(projectRoot, p.FilePath)
+
+	// Refuse to clobber uncommitted edits, same as checkout
+	currentContent, err := os.ReadFile(absPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read current file: %w", err)
+	}
+	if err == nil && string(currentContent) != latest.Content {
+		yellow := color.New(color.FgYellow).SprintFunc()
+		fmt.Printf("%s Warning: You have uncommitted changes in %s\n", yellow("!"), p.FilePath)
+		fmt.Println("  Use 'promptsmith commit' to save changes before reverting.")
+		return fmt.Errorf("uncommitted changes would be overwritten")
+	}
+
+	message := revertMessage
+	if message == "" {
+		message = fmt.Sprintf("Revert to %s", targetVersion.Version)
+	}
+
+	user := resolveAuthor(projectRoot, "")
+
+	v, err := database.CreateVersion(
+		p.ID,
+		bumpVersion(latest.Version),
+		targetVersion.Content,
+		targetVersion.Variables,
+		targetVersion.Metadata,
+		message,
+		user,
+		&latest.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(absPath, []byte(targetVersion.Content), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	logging.Debug("wrote prompt file", "path", absPath, "version", targetVersion.Version)
+
+	green := color.New(color.FgGreen).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	fmt.Printf("%s Reverted %s to %s as %s\n", green("✓"), cyan(p.Name), targetVersion.Version, v.Version)
+
+	return nil
+}
@@ -1,15 +1,19 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/promptsmith/cli/internal/logging"
 	"github.com/spf13/cobra"
 )
 
 var (
-	verbose bool
-	jsonOut bool
+	verbose   bool
+	jsonOut   bool
+	logLevel  string
+	logFormat string
 )
 
 // version is the build version, overridden at release time via
@@ -21,17 +25,48 @@ var rootCmd = &cobra.Command{
 	Short: "The GitHub Copilot for Prompt Engineering",
 	Long: `PromptSmith brings software engineering best practices to prompt engineering.
 Version, test, iterate, and benchmark your LLM prompts with the same rigor you apply to code.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Diagnostic events go to stderr so they never mix with --json output
+		return logging.Configure(cmd.ErrOrStderr(), logLevel, logFormat)
+	},
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		var ee *exitError
+		if !errors.As(err, &ee) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(exitCode(err))
 	}
 }
 
+// exitError ends the command with a status code and no error message, for
+// commands whose exit status is itself the result (e.g. diff --exit-code)
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// exitCode returns the process exit status for a command's error
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return ee.code
+	}
+	return 1
+}
+
 func init() {
 	rootCmd.Version = version
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "V", false, "verbose output")
 	rootCmd.PersistentFlags().BoolVar(&jsonOut, "json", false, "output as JSON")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", logging.LevelOff, "log internal events to stderr at this level (debug, info, warn, error or off)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "format of logged events (text or json)")
 }
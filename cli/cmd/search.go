@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/promptsmith/cli/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Find prompt versions containing a phrase",
+	Long: `Search the content of every committed prompt version for a phrase.
+
+Matching is case-insensitive. Each match is shown with the prompt name,
+version, and a snippet of the content with the phrase highlighted.
+
+Examples:
+  promptsmith search "refund policy"
+  promptsmith search json schema --json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	query := strings.Join(args, " ")
+
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	database, err := db.Open(projectRoot)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	results, err := database.SearchVersions(query)
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		if results == nil {
+			results = []db.SearchResult{}
+		}
+		data, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No prompt versions contain %q.\n", query)
+		return nil
+	}
+
+	cyan := color.New(color.FgCyan).SprintFunc()
+	highlight := color.New(color.FgYellow, color.Bold).SprintFunc()
+
+	for _, r := range results {
+		fmt.Printf("  %s@%s\n", cyan(r.PromptName), r.Version)
+		fmt.Printf("    %s\n", highlightSnippet(r.Snippet, highlight))
+	}
+	return nil
+}
+
+// highlightSnippet fits a search snippet on one line and replaces its match
+// markers with highlighting
+func highlightSnippet(snippet string, highlight func(a ...interface{}) string) string {
+	snippet = strings.Join(strings.Fields(snippet), " ")
+
+	var b strings.Builder
+	for {
+		start := strings.Index(snippet, db.SnippetMatchStart)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(snippet[start:], db.SnippetMatchEnd)
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(snippet[:start])
+		b.WriteString(highlight(snippet[start+len(db.SnippetMatchStart) : end]))
+		snippet = snippet[end+len(db.SnippetMatchEnd):]
+	}
+	b.WriteString(snippet)
+	return b.String()
+}
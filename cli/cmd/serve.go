@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"log"
+	"os"
 
 	"github.com/fatih/color"
 	"github.com/promptsmith/cli/internal/api"
@@ -9,7 +11,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var servePort int
+var (
+	servePort    int
+	serveEnvFile string
+)
 
 var serveCmd = &cobra.Command{
 	Use:   "serve",
@@ -19,18 +24,29 @@ var serveCmd = &cobra.Command{
 This allows the web UI to connect to your local project and display
 real data instead of mock data.
 
+Set PROMPTSMITH_API_TOKEN to require every API request to send
+"Authorization: Bearer <token>".
+
 Examples:
   promptsmith serve              # Start on default port 8080
-  promptsmith serve --port 3000  # Start on custom port`,
+  promptsmith serve --port 3000  # Start on custom port
+  promptsmith serve --env-file .env  # Load API keys from .env`,
 	RunE: runServe,
 }
 
 func init() {
 	serveCmd.Flags().IntVarP(&servePort, "port", "p", 8080, "port to listen on")
+	serveCmd.Flags().StringVar(&serveEnvFile, "env-file", "", "load provider API keys from this dotenv file (variables already set win)")
 	rootCmd.AddCommand(serveCmd)
 }
 
 func runServe(cmd *cobra.Command, args []string) error {
+	if serveEnvFile != "" {
+		if err := loadEnvFile(serveEnvFile); err != nil {
+			return err
+		}
+	}
+
 	projectRoot, err := db.FindProjectRoot()
 	if err != nil {
 		return err
@@ -43,6 +59,10 @@ func runServe(cmd *cobra.Command, args []string) error {
 	defer database.Close()
 
 	server := api.NewServer(database, projectRoot)
+	server.SetAuthToken(os.Getenv(api.TokenEnvVar))
+	if verbose {
+		server.SetLogger(log.New(os.Stderr, "", log.LstdFlags))
+	}
 
 	cyan := color.New(color.FgCyan).SprintFunc()
 	dim := color.New(color.Faint).SprintFunc()
@@ -51,6 +71,9 @@ func runServe(cmd *cobra.Command, args []string) error {
 	fmt.Printf("%s API server started\n", cyan("▶"))
 	fmt.Printf("  Local:   %s\n", cyan(fmt.Sprintf("http://localhost:%d", servePort)))
 	fmt.Printf("  Project: %s\n", dim(projectRoot))
+	if os.Getenv(api.TokenEnvVar) != "" {
+		fmt.Printf("  Auth:    %s\n", dim("bearer token required"))
+	}
 	fmt.Printf("\n%s\n", dim("Press Ctrl+C to stop"))
 
 	return server.ListenAndServe(addr)
@@ -11,7 +11,14 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var showVersion string
+var (
+	showVersion  string
+	showDiffFrom string
+)
+
+// showDiffFromParent is the --diff-from ref naming the shown version's
+// parent
+const showDiffFromParent = "parent"
 
 var showCmd = &cobra.Command{
 	Use:   "show <prompt>",
@@ -22,6 +29,8 @@ variables, and metadata.
 Examples:
   promptsmith show summarizer
   promptsmith show summarizer --version 1.0.0
+  promptsmith show summarizer --diff-from parent   # Also diff against the parent version
+  promptsmith show summarizer --diff-from 1.0.0    # Also diff against a specific ref
   promptsmith show summarizer --json`,
 	Args: cobra.ExactArgs(1),
 	RunE: runShow,
@@ -29,6 +38,9 @@ Examples:
 
 func init() {
 	showCmd.Flags().StringVarP(&showVersion, "version", "v", "", "show specific version")
+	showCmd.Flags().StringVar(&showDiffFrom, "diff-from", "", "append a diff from this ref, or 'parent' for the parent version")
+	showCmd.ValidArgsFunction = completePromptArgs()
+	showCmd.RegisterFlagCompletionFunc("version", completePromptFlag(listVersionCompletions))
 	rootCmd.AddCommand(showCmd)
 }
 
@@ -42,6 +54,7 @@ type showOutput struct {
 	Content     string         `json:"content"`
 	CreatedAt   string         `json:"created_at,omitempty"`
 	CreatedBy   string         `json:"created_by,omitempty"`
+	Diff        *diffOutput    `json:"diff,omitempty"`
 }
 
 type variableInfo struct {
@@ -66,7 +79,7 @@ func runShow(cmd *cobra.Command, args []string) error {
 	defer database.Close()
 
 	// Get the prompt
-	p, err := database.GetPromptByName(promptName)
+	p, err := resolvePrompt(database, promptName)
 	if err != nil {
 		return err
 	}
@@ -140,6 +153,23 @@ func runShow(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	var diffFrom *db.PromptVersion
+	if showDiffFrom != "" {
+		diffFrom, err = resolveShowDiffBase(database, p.ID, version, showDiffFrom)
+		if err != nil {
+			return err
+		}
+		if diffFrom != nil {
+			output.Diff = newDiffOutput(
+				p.Name,
+				fmt.Sprintf("%s@%s", p.Name, diffFrom.Version),
+				fmt.Sprintf("%s@%s", p.Name, version.Version),
+				diffFrom.Content,
+				version.Content,
+			)
+		}
+	}
+
 	// JSON output
 	if jsonOut {
 		data, _ := json.MarshalIndent(output, "", "  ")
@@ -183,5 +213,41 @@ func runShow(cmd *cobra.Command, args []string) error {
 	fmt.Println(output.Content)
 	fmt.Printf("%s\n", dim(strings.Repeat("─", 60)))
 
+	if showDiffFrom != "" {
+		fmt.Println()
+		switch {
+		case diffFrom == nil:
+			fmt.Println(dim("No parent version to diff against."))
+		case len(output.Diff.Hunks) == 0:
+			fmt.Println("No differences.")
+		default:
+			printUnifiedDiff(output.Diff.From, output.Diff.To, output.Diff.Hunks, false)
+		}
+	}
+
 	return nil
 }
+
+// resolveShowDiffBase returns the version to diff against for --diff-from.
+// The parent ref yields nil when the version has no parent.
+func resolveShowDiffBase(database *db.DB, promptID string, version *db.PromptVersion, ref string) (*db.PromptVersion, error) {
+	if ref == showDiffFromParent {
+		if version.ParentVersionID == nil {
+			return nil, nil
+		}
+		return database.GetVersionByID(*version.ParentVersionID)
+	}
+
+	versions, err := database.ListVersions(promptID)
+	if err != nil {
+		return nil, err
+	}
+	base, err := resolveVersion(database, promptID, versions, ref)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
+		return nil, fmt.Errorf("version '%s' not found", ref)
+	}
+	return base, nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/promptsmith/cli/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var stageCmd = &cobra.Command{
+	Use:   "stage <prompt>...",
+	Short: "Stage prompt changes for the next commit",
+	Long: `Snapshot the current content of one or more prompts for the next commit.
+
+Only staged prompts are committed by 'promptsmith commit', using the content
+they had when staged; later edits need staging again. Staging a prompt whose
+file matches its latest version removes it from the staging area.
+
+Examples:
+  promptsmith stage summarizer
+  promptsmith stage summarizer classifier
+  promptsmith commit -m "Tighten summary length"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runStage,
+}
+
+func init() {
+	rootCmd.AddCommand(stageCmd)
+}
+
+func runStage(cmd *cobra.Command, args []string) error {
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	database, err := db.Open(projectRoot)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	green := color.New(color.FgGreen).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+
+	// Resolve every prompt before staging any, so a typo stages nothing
+	prompts := make([]*db.Prompt, len(args))
+	for i, name := range args {
+		p, err := database.GetPromptByName(name)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			return fmt.Errorf("prompt '%s' not found", name)
+		}
+		prompts[i] = p
+	}
+
+	for _, p := range prompts {
+		content, err := os.ReadFile(filepath.Join(projectRoot, p.FilePath))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", p.FilePath, err)
+		}
+
+		latest, err := database.GetLatestVersion(p.ID)
+		if err != nil {
+			return err
+		}
+		if latest != nil && latest.Content == string(content) {
+			if err := database.UnstagePrompt(p.ID); err != nil {
+				return err
+			}
+			fmt.Printf("  %s %s\n", cyan(p.Name), dim("no changes"))
+			continue
+		}
+
+		if err := database.StagePrompt(p.ID, string(content)); err != nil {
+			return err
+		}
+		fmt.Printf("%s staged %s\n", green("✓"), cyan(p.Name))
+	}
+
+	return nil
+}
@@ -7,12 +7,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/promptsmith/cli/internal/db"
 	"github.com/spf13/cobra"
 )
 
+var statusPorcelain bool
+
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show project status",
@@ -21,21 +24,31 @@ var statusCmd = &cobra.Command{
 Displays tracked prompts, their versions, and whether they have
 uncommitted changes.
 
+With --porcelain, prints one line per prompt file that is not clean, in a
+stable format for scripts: a status code, a space and the file path. Codes
+are M (modified), D (deleted), A (tracked but never committed),
+U (diverged from the remote on pull, awaiting review) and ?? (untracked).
+A prompt can have both an M and a U line.
+
 Examples:
-  promptsmith status`,
+  promptsmith status
+  promptsmith status --porcelain`,
 	RunE: runStatus,
 }
 
 func init() {
+	statusCmd.Flags().BoolVar(&statusPorcelain, "porcelain", false, "machine-readable output")
 	rootCmd.AddCommand(statusCmd)
 }
 
 type promptStatus struct {
-	Name        string `json:"name"`
-	FilePath    string `json:"file_path"`
-	Version     string `json:"version"`
-	Status      string `json:"status"` // clean, modified, untracked
-	Description string `json:"description,omitempty"`
+	Name        string   `json:"name"`
+	FilePath    string   `json:"file_path"`
+	Version     string   `json:"version"`
+	Status      string   `json:"status"` // clean, modified, untracked
+	Staged      bool     `json:"staged,omitempty"`
+	Description string   `json:"description,omitempty"`
+	NeedsReview []string `json:"needs_review,omitempty"` // Versions pulled with --strategy manual, awaiting review
 }
 
 func runStatus(cmd *cobra.Command, args []string) error {
@@ -61,65 +74,12 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Find prompt files in prompts/ directory
-	promptsDir := filepath.Join(projectRoot, "prompts")
-	var untrackedFiles []string
-
-	if _, err := os.Stat(promptsDir); err == nil {
-		matches, _ := filepath.Glob(filepath.Join(promptsDir, "*.prompt"))
-		for _, m := range matches {
-			relPath, _ := filepath.Rel(projectRoot, m)
-			found := false
-			for _, p := range prompts {
-				if p.FilePath == relPath || p.FilePath == m {
-					found = true
-					break
-				}
-			}
-			if !found {
-				untrackedFiles = append(untrackedFiles, relPath)
-			}
-		}
-	}
-
-	var statuses []promptStatus
-
-	// Check each tracked prompt
-	for _, p := range prompts {
-		ps := promptStatus{
-			Name:        p.Name,
-			FilePath:    p.FilePath,
-			Description: p.Description,
-			Status:      "clean",
-		}
-
-		// Get latest version
-		latestVersion, err := database.GetLatestVersion(p.ID)
-		if err == nil && latestVersion != nil {
-			ps.Version = latestVersion.Version
-
-			// Check if file has changed
-			fullPath := filepath.Join(projectRoot, p.FilePath)
-			if _, err := os.Stat(fullPath); os.IsNotExist(err) {
-				ps.Status = "deleted"
-			} else {
-				// Read current file content
-				fileContent, err := os.ReadFile(fullPath)
-				if err == nil {
-					// Compare content hashes (full file content)
-					currentHash := hashContent(string(fileContent))
-					storedHash := hashContent(latestVersion.Content)
-					if currentHash != storedHash {
-						ps.Status = "modified"
-					}
-				}
-			}
-		} else {
-			ps.Version = "0.0.0"
-			ps.Status = "new"
-		}
+	untrackedFiles := findUntrackedFiles(projectRoot, prompts)
+	statuses := collectPromptStatuses(database, projectRoot, prompts)
 
-		statuses = append(statuses, ps)
+	if statusPorcelain {
+		printPorcelainStatus(statuses, untrackedFiles)
+		return nil
 	}
 
 	// JSON output
@@ -176,6 +136,12 @@ func runStatus(cmd *cobra.Command, args []string) error {
 			if statusColor != "" {
 				fmt.Printf(" %s", statusColor)
 			}
+			if ps.Staged {
+				fmt.Printf(" %s", green("(staged)"))
+			}
+			if len(ps.NeedsReview) > 0 {
+				fmt.Printf(" %s", red(fmt.Sprintf("(diverged from remote at %s)", strings.Join(ps.NeedsReview, ", "))))
+			}
 			fmt.Println()
 		}
 	}
@@ -198,12 +164,129 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 	if modified > 0 {
 		fmt.Printf("\n%d prompt(s) with uncommitted changes.\n", modified)
-		fmt.Printf("Use %s to commit.\n", cyan("promptsmith commit -m \"message\""))
+		fmt.Printf("Use %s to stage and %s to commit.\n", cyan("promptsmith stage <prompt>"), cyan("promptsmith commit -m \"message\""))
+	}
+
+	review := 0
+	for _, ps := range statuses {
+		if len(ps.NeedsReview) > 0 {
+			review++
+		}
+	}
+	if review > 0 {
+		fmt.Printf("\n%d prompt(s) diverged from the remote and need review.\n", review)
+		fmt.Printf("Compare each with its %s file, then commit the result to mark it reviewed.\n", cyan(".theirs"))
 	}
 
 	return nil
 }
 
+// findUntrackedFiles lists prompt files in the prompts/ directory that no
+// tracked prompt refers to
+func findUntrackedFiles(projectRoot string, prompts []*db.Prompt) []string {
+	promptsDir := filepath.Join(projectRoot, "prompts")
+	if _, err := os.Stat(promptsDir); err != nil {
+		return nil
+	}
+
+	var untrackedFiles []string
+	matches, _ := filepath.Glob(filepath.Join(promptsDir, "*.prompt"))
+	for _, m := range matches {
+		relPath, _ := filepath.Rel(projectRoot, m)
+		found := false
+		for _, p := range prompts {
+			if p.FilePath == relPath || p.FilePath == m {
+				found = true
+				break
+			}
+		}
+		if !found {
+			untrackedFiles = append(untrackedFiles, relPath)
+		}
+	}
+	return untrackedFiles
+}
+
+// porcelainCodes maps a prompt status to its --porcelain code. Clean prompts
+// have none and are not printed.
+var porcelainCodes = map[string]string{
+	"modified": "M",
+	"deleted":  "D",
+	"new":      "A",
+}
+
+func printPorcelainStatus(statuses []promptStatus, untrackedFiles []string) {
+	for _, ps := range statuses {
+		if code, ok := porcelainCodes[ps.Status]; ok {
+			fmt.Printf("%s %s\n", code, filepath.ToSlash(ps.FilePath))
+		}
+		if len(ps.NeedsReview) > 0 {
+			fmt.Printf("U %s\n", filepath.ToSlash(ps.FilePath))
+		}
+	}
+	for _, f := range untrackedFiles {
+		fmt.Printf("?? %s\n", filepath.ToSlash(f))
+	}
+}
+
+// collectPromptStatuses compares each tracked prompt's working file with its
+// latest committed version
+func collectPromptStatuses(database *db.DB, projectRoot string, prompts []*db.Prompt) []promptStatus {
+	var statuses []promptStatus
+
+	conflicts := make(map[string][]string)
+	if unresolved, err := database.ListUnresolvedPullConflicts(); err == nil {
+		for _, c := range unresolved {
+			conflicts[c.PromptID] = append(conflicts[c.PromptID], c.Version)
+		}
+	}
+
+	// Check each tracked prompt
+	for _, p := range prompts {
+		ps := promptStatus{
+			Name:        p.Name,
+			FilePath:    p.FilePath,
+			Description: p.Description,
+			Status:      "clean",
+		}
+
+		// Get latest version
+		latestVersion, err := database.GetLatestVersion(p.ID)
+		if err == nil && latestVersion != nil {
+			ps.Version = latestVersion.Version
+
+			// Check if file has changed
+			fullPath := filepath.Join(projectRoot, p.FilePath)
+			if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+				ps.Status = "deleted"
+			} else {
+				// Read current file content
+				fileContent, err := os.ReadFile(fullPath)
+				if err == nil {
+					// Compare content hashes (full file content)
+					currentHash := hashContent(string(fileContent))
+					storedHash := hashContent(latestVersion.Content)
+					if currentHash != storedHash {
+						ps.Status = "modified"
+					}
+				}
+			}
+		} else {
+			ps.Version = "0.0.0"
+			ps.Status = "new"
+		}
+
+		if staged, err := database.GetStagedPrompt(p.ID); err == nil && staged != nil {
+			ps.Staged = true
+		}
+		ps.NeedsReview = conflicts[p.ID]
+
+		statuses = append(statuses, ps)
+	}
+
+	return statuses
+}
+
 func hashContent(content string) string {
 	h := sha256.New()
 	h.Write([]byte(content))
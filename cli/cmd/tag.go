@@ -37,6 +37,7 @@ Examples:
 func init() {
 	tagCmd.Flags().BoolVarP(&tagDelete, "delete", "d", false, "delete the specified tag")
 	tagCmd.Flags().BoolVarP(&tagList, "list", "l", false, "list all tags for the prompt")
+	tagCmd.ValidArgsFunction = completePromptArgs(listTagCompletions, listVersionCompletions)
 	rootCmd.AddCommand(tagCmd)
 }
 
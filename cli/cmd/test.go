@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -24,6 +28,19 @@ var (
 	testModel           string
 	testWatch           bool
 	testUpdateSnapshots bool
+	testRecord          string
+	testReplay          string
+	testOnly            string
+	testCoverage        bool
+	testMinCoverage     float64
+	testTimeout         time.Duration
+	testFormat          string
+	testEnvFile         string
+	testProfile         bool
+	testProfileCount    int
+	testAppend          bool
+	testMaxRetries      int
+	testFailureDiffs    bool
 )
 
 var testCmd = &cobra.Command{
@@ -41,22 +58,50 @@ Examples:
   promptsmith test                           # Run all tests in tests/
   promptsmith test tests/summarizer.test.yaml
   promptsmith test --filter "basic"          # Run tests matching filter
+  promptsmith test --only greeting-tests     # Run only the suite with this name
   promptsmith test --version 1.0.0           # Test specific prompt version
   promptsmith test --live                    # Run with real LLM
   promptsmith test --live --model gpt-4o     # Use specific model
+  promptsmith test --live --timeout 30s      # Fail any test case taking over 30s
+  promptsmith test --live --max-retries 5    # Retry rate-limited calls up to 5 times
+  promptsmith test --live --env-file .env    # Load API keys from .env
   promptsmith test --watch                   # Re-run tests on file changes
-  promptsmith test --update-snapshots        # Update snapshot assertions`,
+  promptsmith test --update-snapshots        # Update snapshot assertions
+  promptsmith test --update-snapshots -f tone  # Update only the snapshots of matching tests
+  promptsmith test --coverage                # Report prompts without test suites
+  promptsmith test --coverage --min-coverage 80
+  promptsmith test --live --output-diff-on-fail  # Save failing outputs to tests/__failures__/
+  promptsmith test --profile                 # Also list the 10 slowest tests
+  promptsmith test --profile --profile-count 5  # List the 5 slowest tests
+  promptsmith test --format jsonl | jq .      # Stream one JSON object per test
+  promptsmith test --format junit -o report.xml  # JUnit XML report for CI
+  promptsmith test -o history.json --append  # Keep earlier results in the file
+  promptsmith test --live --record tests/fixtures.json  # Record live outputs
+  promptsmith test --replay tests/fixtures.json         # Replay recorded outputs`,
 	RunE: runTest,
 }
 
 func init() {
 	testCmd.Flags().StringVarP(&testFilter, "filter", "f", "", "only run tests matching this pattern")
 	testCmd.Flags().StringVarP(&testVersion, "version", "v", "", "test against specific prompt version")
-	testCmd.Flags().StringVarP(&testOutput, "output", "o", "", "write results to file (JSON format)")
+	testCmd.Flags().StringVarP(&testOutput, "output", "o", "", "write results to file (JSON, or XML with --format junit)")
 	testCmd.Flags().BoolVar(&testLive, "live", false, "run tests against real LLMs (requires API keys)")
 	testCmd.Flags().StringVarP(&testModel, "model", "m", "gpt-4o-mini", "model to use for live testing")
 	testCmd.Flags().BoolVarP(&testWatch, "watch", "w", false, "watch for file changes and re-run tests")
-	testCmd.Flags().BoolVar(&testUpdateSnapshots, "update-snapshots", false, "update snapshot assertions with current output")
+	testCmd.Flags().BoolVar(&testUpdateSnapshots, "update-snapshots", false, "update snapshot assertions and snapshot files with current output")
+	testCmd.Flags().StringVar(&testOnly, "only", "", "only run the suite with this name")
+	testCmd.Flags().BoolVar(&testCoverage, "coverage", false, "report tracked prompts that have no test suite instead of running tests")
+	testCmd.Flags().Float64Var(&testMinCoverage, "min-coverage", 0, "with --coverage, fail if the percentage of tested prompts is below this")
+	testCmd.Flags().StringVar(&testRecord, "record", "", "record live LLM outputs to a fixtures file (requires --live)")
+	testCmd.Flags().DurationVar(&testTimeout, "timeout", 0, "fail test cases whose execution exceeds this duration (overrides suite timeouts)")
+	testCmd.Flags().StringVar(&testEnvFile, "env-file", "", "load provider API keys from this dotenv file (variables already set win)")
+	testCmd.Flags().IntVar(&testMaxRetries, "max-retries", 2, "with --live, retry LLM calls that hit rate limits or transient server errors up to this many times")
+	testCmd.Flags().BoolVar(&testFailureDiffs, "output-diff-on-fail", false, "write the prompt, output and failed assertions of each failing test to tests/__failures__/<suite>/<test>.txt")
+	testCmd.Flags().BoolVar(&testAppend, "append", false, "with --output, append results to the JSON array in the file instead of overwriting it")
+	testCmd.Flags().StringVar(&testFormat, "format", "text", "output format: text, jsonl (one JSON object per test as it completes), junit (XML report)")
+	testCmd.Flags().StringVar(&testReplay, "replay", "", "replay LLM outputs from a fixtures file instead of calling an LLM")
+	testCmd.Flags().BoolVar(&testProfile, "profile", false, "after the summary, list the slowest tests across all suites")
+	testCmd.Flags().IntVar(&testProfileCount, "profile-count", 10, "with --profile, how many of the slowest tests to list")
 	rootCmd.AddCommand(testCmd)
 }
 
@@ -65,9 +110,44 @@ type testRunContext struct {
 	database    *db.DB
 	suiteFiles  []string
 	executor    testing.OutputExecutor
+	embedder    benchmark.EmbeddingProvider
+	fixtures    *testing.Fixtures
 }
 
 func setupTestContext(args []string) (*testRunContext, error) {
+	if testRecord != "" && !testLive {
+		return nil, fmt.Errorf("--record requires --live")
+	}
+	if testReplay != "" && testLive {
+		return nil, fmt.Errorf("--replay cannot be combined with --live")
+	}
+	if testMaxRetries < 0 {
+		return nil, fmt.Errorf("--max-retries must not be negative")
+	}
+	if testProfileCount < 1 {
+		return nil, fmt.Errorf("--profile-count must be at least 1")
+	}
+	if testAppend {
+		if testOutput == "" {
+			return nil, fmt.Errorf("--append requires --output")
+		}
+		if testFormat == "junit" {
+			return nil, fmt.Errorf("--append cannot be combined with --format junit")
+		}
+	}
+	switch testFormat {
+	case "", "text":
+	case "jsonl", "junit":
+		if jsonOut {
+			return nil, fmt.Errorf("--format %s cannot be combined with --json", testFormat)
+		}
+		if testWatch {
+			return nil, fmt.Errorf("--format %s cannot be combined with --watch", testFormat)
+		}
+	default:
+		return nil, fmt.Errorf("unknown format '%s' (expected text, jsonl or junit)", testFormat)
+	}
+
 	projectRoot, err := db.FindProjectRoot()
 	if err != nil {
 		return nil, err
@@ -94,9 +174,26 @@ func setupTestContext(args []string) (*testRunContext, error) {
 		}
 	}
 
+	if testOnly != "" {
+		suiteFiles, err = filterSuitesByName(suiteFiles, testOnly)
+		if err != nil {
+			database.Close()
+			return nil, err
+		}
+	}
+
 	// Set up executor
 	var executor testing.OutputExecutor
-	if testLive {
+	var embedder benchmark.EmbeddingProvider
+	var fixtures *testing.Fixtures
+	if testReplay != "" {
+		fixtures, err = testing.LoadFixtures(testReplay)
+		if err != nil {
+			database.Close()
+			return nil, err
+		}
+		executor = testing.NewReplayExecutor(fixtures)
+	} else if testLive {
 		// Use real LLM executor
 		registry := benchmark.NewProviderRegistry()
 
@@ -105,6 +202,10 @@ func setupTestContext(args []string) (*testRunContext, error) {
 			if p, err := benchmark.NewOpenAIProvider(); err == nil {
 				registry.Register(p)
 			}
+			// Similarity assertions compare OpenAI embeddings
+			if e, err := benchmark.NewOpenAIEmbeddingProvider(); err == nil {
+				embedder = e
+			}
 		}
 
 		// Register Anthropic if API key available
@@ -114,7 +215,29 @@ func setupTestContext(args []string) (*testRunContext, error) {
 			}
 		}
 
-		executor = testing.NewLLMExecutor(registry, testing.WithModel(testModel))
+		// Register Gemini if API key available
+		if os.Getenv("GEMINI_API_KEY") != "" {
+			if p, err := benchmark.NewGeminiProvider(); err == nil {
+				registry.Register(p)
+			}
+		}
+
+		// Register Ollama if a local server is running
+		if p, err := benchmark.NewOllamaProvider(); err == nil {
+			registry.Register(p)
+		}
+
+		if err := applyModelAliases(projectRoot, registry); err != nil {
+			database.Close()
+			return nil, err
+		}
+
+		opts := []testing.LLMExecutorOption{testing.WithModel(testModel), testing.WithMaxRetries(testMaxRetries)}
+		if testRecord != "" {
+			fixtures = testing.NewFixtures()
+			opts = append(opts, testing.WithRecorder(fixtures))
+		}
+		executor = testing.NewLLMExecutor(registry, opts...)
 	}
 
 	return &testRunContext{
@@ -122,9 +245,138 @@ func setupTestContext(args []string) (*testRunContext, error) {
 		database:    database,
 		suiteFiles:  suiteFiles,
 		executor:    executor,
+		embedder:    embedder,
+		fixtures:    fixtures,
 	}, nil
 }
 
+// testCoverageReport summarizes which tracked prompts have a test suite
+type testCoverageReport struct {
+	Total    int      `json:"total"`
+	Tested   int      `json:"tested"`
+	Untested []string `json:"untested"`
+	Percent  float64  `json:"percent"`
+}
+
+// computeTestCoverage cross-references tracked prompts against the prompt:
+// field of each suite file.
+func computeTestCoverage(database *db.DB, suiteFiles []string) (*testCoverageReport, error) {
+	prompts, err := database.ListPrompts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompts: %w", err)
+	}
+
+	covered := make(map[string]bool)
+	for _, file := range suiteFiles {
+		suite, err := testing.ParseSuiteFile(file)
+		if err != nil {
+			continue
+		}
+		covered[suite.Prompt] = true
+	}
+
+	report := &testCoverageReport{Total: len(prompts), Untested: []string{}}
+	for _, p := range prompts {
+		if covered[p.Name] {
+			report.Tested++
+		} else {
+			report.Untested = append(report.Untested, p.Name)
+		}
+	}
+	if report.Total > 0 {
+		report.Percent = float64(report.Tested) / float64(report.Total) * 100
+	} else {
+		report.Percent = 100
+	}
+
+	return report, nil
+}
+
+func runTestCoverage(ctx *testRunContext) error {
+	report, err := computeTestCoverage(ctx.database, ctx.suiteFiles)
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		green := color.New(color.FgGreen).SprintFunc()
+		red := color.New(color.FgRed).SprintFunc()
+		dim := color.New(color.Faint).SprintFunc()
+
+		fmt.Printf("Test coverage: %d/%d prompts (%.1f%%)\n", report.Tested, report.Total, report.Percent)
+		if len(report.Untested) == 0 {
+			fmt.Printf("%s Every prompt has a test suite\n", green("✓"))
+		} else {
+			fmt.Printf("\n%s\n", dim("Prompts without tests:"))
+			for _, name := range report.Untested {
+				fmt.Printf("  %s %s\n", red("✗"), name)
+			}
+		}
+	}
+
+	if report.Percent < testMinCoverage {
+		return fmt.Errorf("test coverage %.1f%% is below minimum %.1f%%", report.Percent, testMinCoverage)
+	}
+	return nil
+}
+
+// filterSuitesByName returns the suite files whose name: matches name.
+// Files that fail to parse are skipped.
+func filterSuitesByName(files []string, name string) ([]string, error) {
+	var matched []string
+	for _, file := range files {
+		suite, err := testing.ParseSuiteFile(file)
+		if err != nil {
+			continue
+		}
+		if suite.Name == name {
+			matched = append(matched, file)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no test suite named '%s' found", name)
+	}
+	return matched, nil
+}
+
+// saveRecordedFixtures writes outputs captured during a --record run
+func saveRecordedFixtures(ctx *testRunContext) {
+	if testRecord == "" || ctx.fixtures == nil {
+		return
+	}
+	if err := ctx.fixtures.Save(testRecord); err != nil {
+		fmt.Printf("Failed to save fixtures: %v\n", err)
+		return
+	}
+	if !jsonOut && !testQuiet() {
+		fmt.Printf("Recorded %d outputs to %s\n", ctx.fixtures.Len(), testRecord)
+	}
+}
+
+// testResultLine is one line of --format jsonl output: a test result tagged
+// with the suite it belongs to
+type testResultLine struct {
+	Suite   string `json:"suite"`
+	Prompt  string `json:"prompt"`
+	Version string `json:"version"`
+	testing.TestResult
+}
+
+// testStreaming reports whether results are streamed as JSON lines
+func testStreaming() bool {
+	return testFormat == "jsonl"
+}
+
+// testQuiet reports whether stdout is reserved for --format output, so the
+// human-readable progress and summary are suppressed. A JUnit report written
+// to --output leaves stdout free.
+func testQuiet() bool {
+	return testStreaming() || (testFormat == "junit" && testOutput == "")
+}
+
 func executeTests(ctx *testRunContext) (passed, failed, skipped int, results []*testing.SuiteResult) {
 	green := color.New(color.FgGreen).SprintFunc()
 	red := color.New(color.FgRed).SprintFunc()
@@ -134,11 +386,34 @@ func executeTests(ctx *testRunContext) (passed, failed, skipped int, results []*
 
 	runner := testing.NewRunner(ctx.database, ctx.executor)
 	runner.UpdateSnapshots = testUpdateSnapshots
+	if testFailureDiffs {
+		runner.FailuresDir = filepath.Join(ctx.projectRoot, "tests", "__failures__")
+	}
+	if ctx.embedder != nil {
+		runner.Embedder = ctx.embedder
+	}
+
+	// Keep stdout parseable when it carries --format output
+	errOut := os.Stdout
+	if testQuiet() {
+		errOut = os.Stderr
+	}
+	if testStreaming() {
+		runner.OnResult = func(suite *testing.SuiteResult, tr testing.TestResult) {
+			data, _ := json.Marshal(testResultLine{
+				Suite:      suite.SuiteName,
+				Prompt:     suite.PromptName,
+				Version:    suite.Version,
+				TestResult: tr,
+			})
+			fmt.Println(string(data))
+		}
+	}
 
 	for _, file := range ctx.suiteFiles {
 		suite, err := testing.ParseSuiteFile(file)
 		if err != nil {
-			fmt.Printf("%s Error parsing %s: %v\n", red("✗"), file, err)
+			fmt.Fprintf(errOut, "%s Error parsing %s: %v\n", red("✗"), file, err)
 			continue
 		}
 
@@ -146,6 +421,9 @@ func executeTests(ctx *testRunContext) (passed, failed, skipped int, results []*
 		if testVersion != "" {
 			suite.Version = testVersion
 		}
+		if testTimeout > 0 {
+			suite.Timeout = testTimeout.String()
+		}
 
 		// Apply filter if specified
 		if testFilter != "" {
@@ -162,9 +440,9 @@ func executeTests(ctx *testRunContext) (passed, failed, skipped int, results []*
 			continue
 		}
 
-		result, err := runner.Run(suite)
+		result, err := runner.Run(context.Background(), suite)
 		if err != nil {
-			fmt.Printf("%s Error running %s: %v\n", red("✗"), file, err)
+			fmt.Fprintf(errOut, "%s Error running %s: %v\n", red("✗"), file, err)
 			continue
 		}
 
@@ -174,7 +452,7 @@ func executeTests(ctx *testRunContext) (passed, failed, skipped int, results []*
 		skipped += result.Skipped
 
 		// Print results
-		if !jsonOut {
+		if !jsonOut && !testQuiet() {
 			fmt.Printf("\n%s %s@%s\n", cyan("▶"), result.PromptName, result.Version)
 
 			for _, tr := range result.Results {
@@ -189,12 +467,26 @@ func executeTests(ctx *testRunContext) (passed, failed, skipped int, results []*
 					}
 					for _, f := range tr.Failures {
 						fmt.Printf("    %s %s\n", dim("├"), f.Message)
+						for _, line := range strings.Split(strings.TrimRight(f.Diff, "\n"), "\n") {
+							switch {
+							case line == "":
+							case strings.HasPrefix(line, "- "):
+								fmt.Printf("    %s %s\n", dim("│"), red(line))
+							case strings.HasPrefix(line, "+ "):
+								fmt.Printf("    %s %s\n", dim("│"), green(line))
+							default:
+								fmt.Printf("    %s %s\n", dim("│"), dim(line))
+							}
+						}
 						if verbose {
 							fmt.Printf("    %s expected: %s\n", dim("│"), f.Expected)
 							fmt.Printf("    %s actual: %s\n", dim("└"), f.Actual)
 						}
 					}
 				}
+				for _, sa := range tr.SkippedAssertions {
+					fmt.Printf("    %s %s\n", yellow("○"), dim(fmt.Sprintf("%s skipped: %s", sa.Type, sa.Message)))
+				}
 			}
 		}
 	}
@@ -202,6 +494,26 @@ func executeTests(ctx *testRunContext) (passed, failed, skipped int, results []*
 	return passed, failed, skipped, results
 }
 
+// testReport is the document written by --json and --output
+type testReport struct {
+	Suites  []*testing.SuiteResult `json:"suites"`
+	Summary struct {
+		Passed  int `json:"passed"`
+		Failed  int `json:"failed"`
+		Skipped int `json:"skipped"`
+		Total   int `json:"total"`
+	} `json:"summary"`
+}
+
+func newTestReport(passed, failed, skipped int, results []*testing.SuiteResult) *testReport {
+	report := &testReport{Suites: results}
+	report.Summary.Passed = passed
+	report.Summary.Failed = failed
+	report.Summary.Skipped = skipped
+	report.Summary.Total = passed + failed + skipped
+	return report
+}
+
 func printTestSummary(passed, failed, skipped int, results []*testing.SuiteResult) {
 	green := color.New(color.FgGreen).SprintFunc()
 	red := color.New(color.FgRed).SprintFunc()
@@ -210,27 +522,31 @@ func printTestSummary(passed, failed, skipped int, results []*testing.SuiteResul
 
 	total := passed + failed + skipped
 
-	if jsonOut {
-		output := struct {
-			Suites  []*testing.SuiteResult `json:"suites"`
-			Summary struct {
-				Passed  int `json:"passed"`
-				Failed  int `json:"failed"`
-				Skipped int `json:"skipped"`
-				Total   int `json:"total"`
-			} `json:"summary"`
-		}{
-			Suites: results,
-		}
-		output.Summary.Passed = passed
-		output.Summary.Failed = failed
-		output.Summary.Skipped = skipped
-		output.Summary.Total = total
-
-		data, _ := json.MarshalIndent(output, "", "  ")
+	// The report is JUnit XML with --format junit, JSON otherwise
+	var data []byte
+	if testFormat == "junit" {
+		var err error
+		data, err = testing.MarshalJUnit(results)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to build JUnit report: %v\n", err)
+			return
+		}
+	} else {
+		data, _ = json.MarshalIndent(newTestReport(passed, failed, skipped, results), "", "  ")
+	}
 
+	if testQuiet() {
 		if testOutput != "" {
-			if err := os.WriteFile(testOutput, data, 0644); err != nil {
+			// Streamed results were already printed; only the file remains
+			if err := writeTestOutput(data); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to write output: %v\n", err)
+			}
+		} else if !testStreaming() {
+			fmt.Print(string(data))
+		}
+	} else if jsonOut {
+		if testOutput != "" {
+			if err := writeTestOutput(data); err != nil {
 				fmt.Printf("Failed to write output: %v\n", err)
 			} else {
 				fmt.Printf("Results written to %s\n", testOutput)
@@ -250,25 +566,12 @@ func printTestSummary(passed, failed, skipped int, results []*testing.SuiteResul
 		}
 		fmt.Printf(" %s\n", dim(fmt.Sprintf("(%d total)", total)))
 
-		if testOutput != "" {
-			output := struct {
-				Suites  []*testing.SuiteResult `json:"suites"`
-				Summary struct {
-					Passed  int `json:"passed"`
-					Failed  int `json:"failed"`
-					Skipped int `json:"skipped"`
-					Total   int `json:"total"`
-				} `json:"summary"`
-			}{
-				Suites: results,
-			}
-			output.Summary.Passed = passed
-			output.Summary.Failed = failed
-			output.Summary.Skipped = skipped
-			output.Summary.Total = total
+		if testProfile {
+			printSlowestTests(slowestTests(results, testProfileCount), dim)
+		}
 
-			data, _ := json.MarshalIndent(output, "", "  ")
-			if err := os.WriteFile(testOutput, data, 0644); err != nil {
+		if testOutput != "" {
+			if err := writeTestOutput(data); err != nil {
 				fmt.Printf("Failed to write output: %v\n", err)
 			} else {
 				fmt.Printf("Results written to %s\n", testOutput)
@@ -277,6 +580,52 @@ func printTestSummary(passed, failed, skipped int, results []*testing.SuiteResul
 	}
 }
 
+// writeTestOutput writes the report to --output, adding it to the results
+// already there with --append
+func writeTestOutput(data []byte) error {
+	if testAppend {
+		return appendJSONOutput(testOutput, data)
+	}
+	return os.WriteFile(testOutput, data, 0644)
+}
+
+// profiledTest is a test result together with the suite it ran in
+type profiledTest struct {
+	Suite  string
+	Result testing.TestResult
+}
+
+// slowestTests returns the n tests with the longest duration across all
+// suites, slowest first. Ties keep the order the tests ran in.
+func slowestTests(results []*testing.SuiteResult, n int) []profiledTest {
+	var tests []profiledTest
+	for _, sr := range results {
+		for _, tr := range sr.Results {
+			if tr.Skipped {
+				continue
+			}
+			tests = append(tests, profiledTest{Suite: sr.SuiteName, Result: tr})
+		}
+	}
+	sort.SliceStable(tests, func(i, j int) bool {
+		return tests[i].Result.DurationMs > tests[j].Result.DurationMs
+	})
+	if len(tests) > n {
+		tests = tests[:n]
+	}
+	return tests
+}
+
+func printSlowestTests(tests []profiledTest, dim func(a ...interface{}) string) {
+	if len(tests) == 0 {
+		return
+	}
+	fmt.Printf("\nSlowest tests:\n")
+	for _, t := range tests {
+		fmt.Printf("  %8dms  %s %s\n", t.Result.DurationMs, t.Result.TestName, dim("("+t.Suite+")"))
+	}
+}
+
 func runTestWatch(ctx *testRunContext) error {
 	cyan := color.New(color.FgCyan).SprintFunc()
 	dim := color.New(color.Faint).SprintFunc()
@@ -304,36 +653,45 @@ func runTestWatch(ctx *testRunContext) error {
 	fmt.Printf("%s Watching for changes... %s\n", cyan("👁"), dim("(Ctrl+C to stop)"))
 	passed, failed, skipped, results := executeTests(ctx)
 	printTestSummary(passed, failed, skipped, results)
+	saveRecordedFixtures(ctx)
+
+	return watchLoop(watcher.Events, watcher.Errors, 100*time.Millisecond, func([]string) {
+		// Clear screen and re-run
+		fmt.Print("\033[H\033[2J")
+		fmt.Printf("%s File changed, re-running tests...\n", cyan("↻"))
+		passed, failed, skipped, results := executeTests(ctx)
+		printTestSummary(passed, failed, skipped, results)
+		saveRecordedFixtures(ctx)
+		fmt.Printf("\n%s Watching for changes... %s\n", cyan("👁"), dim("(Ctrl+C to stop)"))
+	})
+}
 
-	// Debounce timer to avoid multiple rapid triggers
-	var debounce <-chan time.Time
+// watchLoop calls run once relevant file events have settled for debounce,
+// with the files changed since the last run, deduplicated and sorted. Runs
+// happen on the loop's goroutine, so they never overlap: changes saved
+// during a run queue up and are coalesced into a single run after it.
+func watchLoop(events <-chan fsnotify.Event, errs <-chan error, debounce time.Duration, run func(changed []string)) error {
+	var settled <-chan time.Time
+	changed := make(map[string]bool)
 
 	for {
 		select {
-		case event, ok := <-watcher.Events:
+		case event, ok := <-events:
 			if !ok {
 				return nil
 			}
-
-			// Only react to writes and creates
-			if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
-				// Check if it's a relevant file
-				ext := filepath.Ext(event.Name)
-				if ext == ".yaml" || ext == ".yml" || ext == ".prompt" {
-					// Debounce - wait 100ms before running
-					debounce = time.After(100 * time.Millisecond)
-				}
+			if isWatchedChange(event) {
+				changed[event.Name] = true
+				settled = time.After(debounce)
 			}
 
-		case <-debounce:
-			// Clear screen and re-run
-			fmt.Print("\033[H\033[2J")
-			fmt.Printf("%s File changed, re-running tests...\n", cyan("↻"))
-			passed, failed, skipped, results := executeTests(ctx)
-			printTestSummary(passed, failed, skipped, results)
-			fmt.Printf("\n%s Watching for changes... %s\n", cyan("👁"), dim("(Ctrl+C to stop)"))
+		case <-settled:
+			settled = nil
+			files := slices.Sorted(maps.Keys(changed))
+			clear(changed)
+			run(files)
 
-		case err, ok := <-watcher.Errors:
+		case err, ok := <-errs:
 			if !ok {
 				return nil
 			}
@@ -342,20 +700,40 @@ func runTestWatch(ctx *testRunContext) error {
 	}
 }
 
+// isWatchedChange reports whether event writes or creates a prompt, test or
+// benchmark file
+func isWatchedChange(event fsnotify.Event) bool {
+	if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+		return false
+	}
+	ext := filepath.Ext(event.Name)
+	return ext == ".yaml" || ext == ".yml" || ext == ".prompt"
+}
+
 func runTest(cmd *cobra.Command, args []string) error {
+	if testEnvFile != "" {
+		if err := loadEnvFile(testEnvFile); err != nil {
+			return err
+		}
+	}
+
 	ctx, err := setupTestContext(args)
 	if err != nil {
 		return err
 	}
 	defer ctx.database.Close()
 
+	if testCoverage {
+		return runTestCoverage(ctx)
+	}
+
 	if len(ctx.suiteFiles) == 0 {
 		fmt.Println("No test suites found.")
 		fmt.Println("Create test files in tests/*.test.yaml or specify files directly.")
 		return nil
 	}
 
-	if testLive && !jsonOut {
+	if testLive && !jsonOut && !testQuiet() {
 		fmt.Printf("Running tests with live LLM (%s)\n", testModel)
 	}
 
@@ -367,6 +745,7 @@ func runTest(cmd *cobra.Command, args []string) error {
 	// Single run mode
 	passed, failed, skipped, results := executeTests(ctx)
 	printTestSummary(passed, failed, skipped, results)
+	saveRecordedFixtures(ctx)
 
 	// Exit with error code if tests failed
 	if failed > 0 {
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/promptsmith/cli/internal/db"
+	"github.com/promptsmith/cli/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+var validateFormat string
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [file...]",
+	Short: "Check prompt files for frontmatter and template errors",
+	Long: `Lint prompt files and report each problem with its file, line and rule.
+Without arguments every tracked prompt is checked.
+
+Rules:
+  frontmatter       The YAML frontmatter does not parse or is not closed
+  template-syntax   The body is not a valid Go template, so tests and
+                    benchmarks cannot render it
+
+Exits with an error if any issue is found.
+
+Examples:
+  promptsmith validate
+  promptsmith validate prompts/greeting.prompt
+  promptsmith validate --json
+  promptsmith validate --format sarif > promptsmith.sarif`,
+	RunE: runValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringVar(&validateFormat, "format", "text", "output format: text, sarif (SARIF 2.1.0 for code scanning)")
+}
+
+// validateIssue is a lint issue in a prompt file. File is relative to the
+// project root with forward slashes.
+type validateIssue struct {
+	File string `json:"file"`
+	prompt.Issue
+}
+
+// validateRules describes each lint rule for the SARIF tool driver
+var validateRules = []struct {
+	ID          string
+	Description string
+}{
+	{prompt.RuleFrontmatter, "Prompt frontmatter must be valid YAML closed by ---"},
+	{prompt.RuleTemplate, "Prompt body must parse as a Go template"},
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	switch validateFormat {
+	case "text":
+	case "sarif":
+		if jsonOut {
+			return fmt.Errorf("--format sarif cannot be combined with --json")
+		}
+	default:
+		return fmt.Errorf("unknown format '%s' (expected text or sarif)", validateFormat)
+	}
+
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	files, err := validateTargets(projectRoot, args)
+	if err != nil {
+		return err
+	}
+
+	issues := []validateIssue{}
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(projectRoot, file))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		for _, issue := range prompt.Lint(string(content)) {
+			issues = append(issues, validateIssue{File: filepath.ToSlash(file), Issue: issue})
+		}
+	}
+
+	switch {
+	case validateFormat == "sarif":
+		data, err := json.MarshalIndent(sarifReport(issues), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode SARIF report: %w", err)
+		}
+		fmt.Println(string(data))
+	case jsonOut:
+		data, _ := json.MarshalIndent(issues, "", "  ")
+		fmt.Println(string(data))
+	default:
+		red := color.New(color.FgRed).SprintFunc()
+		green := color.New(color.FgGreen).SprintFunc()
+		dim := color.New(color.Faint).SprintFunc()
+		for _, issue := range issues {
+			fmt.Printf("%s %s:%d: %s %s\n", red("✗"), issue.File, issue.Line, issue.Message, dim("("+issue.Rule+")"))
+		}
+		if len(issues) == 0 {
+			fmt.Printf("%s %d prompt file(s) valid\n", green("✓"), len(files))
+		}
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("%d issue(s) found in prompt files", len(issues))
+	}
+	return nil
+}
+
+// validateTargets returns the files to lint relative to the project root:
+// the given paths, or the file of every tracked prompt
+func validateTargets(projectRoot string, args []string) ([]string, error) {
+	if len(args) > 0 {
+		var files []string
+		for _, arg := range args {
+			abs, err := filepath.Abs(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %s: %w", arg, err)
+			}
+			rel, err := filepath.Rel(projectRoot, abs)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %s: %w", arg, err)
+			}
+			if _, err := safeProjectPath(projectRoot, rel); err != nil {
+				return nil, fmt.Errorf("invalid path %s: %w", arg, err)
+			}
+			files = append(files, rel)
+		}
+		return files, nil
+	}
+
+	database, err := db.Open(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	prompts, err := database.ListPrompts()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, p := range prompts {
+		if _, err := safeProjectPath(projectRoot, p.FilePath); err != nil {
+			return nil, fmt.Errorf("invalid path for prompt %s: %w", p.Name, err)
+		}
+		files = append(files, p.FilePath)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// SARIF 2.1.0 log, reduced to the fields code scanning reads
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifReport builds a log with one result per issue, located relative to
+// the project root (%SRCROOT%)
+func sarifReport(issues []validateIssue) sarifLog {
+	driver := sarifDriver{Name: "promptsmith", Version: version}
+	for _, r := range validateRules {
+		driver.Rules = append(driver.Rules, sarifRule{ID: r.ID, ShortDescription: sarifMessage{Text: r.Description}})
+	}
+
+	results := []sarifResult{}
+	for _, issue := range issues {
+		results = append(results, sarifResult{
+			RuleID:  issue.Rule,
+			Level:   "error",
+			Message: sarifMessage{Text: issue.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: issue.File, URIBaseID: "%SRCROOT%"},
+					Region:           sarifRegion{StartLine: issue.Line},
+				},
+			}},
+		})
+	}
+
+	return sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+}
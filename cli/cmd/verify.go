@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/promptsmith/cli/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that every tracked prompt is committed",
+	Long: `Exit with an error if any tracked prompt has uncommitted changes, has no
+committed version, or is missing from disk.
+
+Designed for scripts and git hooks (see 'promptsmith init --git-hook').
+
+Examples:
+  promptsmith verify`,
+	Args: cobra.NoArgs,
+	RunE: runVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	projectRoot, err := db.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	database, err := db.Open(projectRoot)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	prompts, err := database.ListPrompts()
+	if err != nil {
+		return err
+	}
+
+	yellow := color.New(color.FgYellow).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+
+	var dirty int
+	for _, ps := range collectPromptStatuses(database, projectRoot, prompts) {
+		if ps.Status == "clean" {
+			continue
+		}
+		fmt.Printf("%s %s (%s): %s\n", yellow("!"), ps.Name, ps.FilePath, ps.Status)
+		dirty++
+	}
+
+	if dirty > 0 {
+		fmt.Printf("\nRun %s to commit prompt changes.\n", "promptsmith commit --all -m \"message\"")
+		return fmt.Errorf("%d prompt(s) not committed", dirty)
+	}
+
+	fmt.Printf("%s All %d prompt(s) committed\n", green("✓"), len(prompts))
+	return nil
+}
@@ -3,12 +3,14 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/promptsmith/cli/internal/benchmark"
+	"github.com/promptsmith/cli/internal/db"
 )
 
 // Benchmark handlers
@@ -83,7 +85,7 @@ func (s *Server) createBenchmarkSuite(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check prompt exists
-	prompt, err := s.db.GetPromptByName(req.Prompt)
+	prompt, _, err := s.db.ResolvePromptName(req.Prompt)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -239,15 +241,14 @@ func (s *Server) runBenchmark(w http.ResponseWriter, r *http.Request, benchName
 		return
 	}
 
-	// Create provider registry
-	registry := benchmark.NewProviderRegistry()
-	if openai, err := benchmark.NewOpenAIProvider(); err == nil {
-		registry.Register(openai)
-	}
-	if anthropic, err := benchmark.NewAnthropicProvider(); err == nil {
-		registry.Register(anthropic)
+	// Benchmark a specific version instead of the suite's pinned or latest one
+	if version := r.URL.Query().Get("version"); version != "" {
+		suite.Version = version
 	}
 
+	// Create provider registry
+	registry := s.providerRegistry()
+
 	// Run the benchmark suite
 	runner := benchmark.NewRunner(s.db, registry)
 	ctx, cancel := llmContext(r)
@@ -259,7 +260,7 @@ func (s *Server) runBenchmark(w http.ResponseWriter, r *http.Request, benchName
 	}
 
 	// Persist run results
-	prompt, err := s.db.GetPromptByName(suite.Prompt)
+	prompt, _, err := s.db.ResolvePromptName(suite.Prompt)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -273,7 +274,7 @@ func (s *Server) runBenchmark(w http.ResponseWriter, r *http.Request, benchName
 		return
 	}
 	resultsJSON, _ := json.Marshal(result)
-	if _, err := s.db.SaveBenchmarkRun(benchName, "", string(resultsJSON)); err != nil {
+	if _, err := s.db.SaveBenchmarkRun(benchName, result.VersionID, string(resultsJSON)); err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -284,6 +285,8 @@ func (s *Server) runBenchmark(w http.ResponseWriter, r *http.Request, benchName
 type BenchmarkRunResponse struct {
 	ID          string          `json:"id"`
 	BenchmarkID string          `json:"benchmark_id"`
+	VersionID   string          `json:"version_id,omitempty"`
+	Version     string          `json:"version,omitempty"`
 	Results     json.RawMessage `json:"results"`
 	CreatedAt   string          `json:"created_at"`
 }
@@ -294,25 +297,75 @@ func (s *Server) listBenchmarkRuns(w http.ResponseWriter, r *http.Request, bench
 		return
 	}
 
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown format '%s' (expected json or csv)", format))
+		return
+	}
+
 	runs, err := s.db.ListBenchmarkRuns(benchName)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	if format == "csv" {
+		s.writeBenchmarkRunsCSV(w, benchName, runs)
+		return
+	}
+
 	response := make([]BenchmarkRunResponse, 0, len(runs))
 	for _, run := range runs {
-		response = append(response, BenchmarkRunResponse{
+		resp := BenchmarkRunResponse{
 			ID:          run.ID,
 			BenchmarkID: run.BenchmarkID,
+			VersionID:   run.VersionID,
 			Results:     json.RawMessage(run.Results),
 			CreatedAt:   run.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		})
+		}
+		if run.VersionID != "" {
+			version, err := s.db.GetVersionByID(run.VersionID)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			if version != nil {
+				resp.Version = version.Version
+			}
+		}
+		response = append(response, resp)
 	}
 
 	writeJSON(w, http.StatusOK, response)
 }
 
+// writeBenchmarkRunsCSV sends the runs as a CSV download with one row per
+// model run, newest run first
+func (s *Server) writeBenchmarkRunsCSV(w http.ResponseWriter, benchName string, runs []*db.BenchmarkRun) {
+	results := make([]*benchmark.BenchmarkResult, 0, len(runs))
+	for _, run := range runs {
+		var result benchmark.BenchmarkResult
+		if err := json.Unmarshal([]byte(run.Results), &result); err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to parse benchmark run %s: %v", run.ID, err))
+			return
+		}
+		results = append(results, &result)
+	}
+
+	data, err := benchmark.MarshalCSV(results)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": benchName + "-runs.csv",
+	}))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
 type BenchmarkSuiteResponse struct {
 	Name         string   `json:"name"`
 	FilePath     string   `json:"file_path"`
@@ -1,13 +1,19 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/promptsmith/cli/internal/benchmark"
+	"github.com/promptsmith/cli/internal/condition"
 	"github.com/promptsmith/cli/internal/db"
 )
 
@@ -37,6 +43,9 @@ type ChainStepResponse struct {
 	PromptName   string          `json:"prompt_name"`
 	InputMapping json.RawMessage `json:"input_mapping"`
 	OutputKey    string          `json:"output_key"`
+	Version      string          `json:"version,omitempty"`
+	DependsOn    []string        `json:"depends_on,omitempty"`
+	Condition    string          `json:"condition,omitempty"`
 }
 
 type CreateChainRequest struct {
@@ -58,6 +67,14 @@ type ChainStepInput struct {
 	PromptName   string          `json:"prompt_name"`
 	InputMapping json.RawMessage `json:"input_mapping"`
 	OutputKey    string          `json:"output_key"`
+	Version      string          `json:"version,omitempty"` // Version or tag to pin; empty for the latest
+	// DependsOn lists output keys of other steps this step waits for, on top
+	// of those its input mapping refers to. Once any step declares one, the
+	// chain runs as a graph instead of strictly in step order.
+	DependsOn []string `json:"depends_on,omitempty"`
+	// Condition, if set, is an expression such as
+	// steps.classify.output == "spam"; the step is skipped when it is false
+	Condition string `json:"condition,omitempty"`
 }
 
 type RunChainRequest struct {
@@ -71,17 +88,33 @@ type ChainRunResponse struct {
 	Inputs      json.RawMessage `json:"inputs"`
 	Results     json.RawMessage `json:"results"`
 	FinalOutput string          `json:"final_output"`
+	TotalTokens int             `json:"total_tokens"`
+	TotalCost   float64         `json:"total_cost"`
 	StartedAt   string          `json:"started_at"`
 	CompletedAt string          `json:"completed_at"`
 }
 
 type ChainStepRunResult struct {
-	StepOrder      int    `json:"step_order"`
-	PromptName     string `json:"prompt_name"`
-	OutputKey      string `json:"output_key"`
-	RenderedPrompt string `json:"rendered_prompt"`
-	Output         string `json:"output"`
-	DurationMs     int64  `json:"duration_ms"`
+	StepOrder      int     `json:"step_order"`
+	PromptName     string  `json:"prompt_name"`
+	Version        string  `json:"version"` // Version of the prompt the step ran
+	OutputKey      string  `json:"output_key"`
+	RenderedPrompt string  `json:"rendered_prompt"`
+	Output         string  `json:"output"`
+	DurationMs     int64   `json:"duration_ms"`
+	PromptTokens   int     `json:"prompt_tokens"`
+	OutputTokens   int     `json:"output_tokens"`
+	Cost           float64 `json:"cost"`
+	Skipped        bool    `json:"skipped,omitempty"` // The step's condition was false
+}
+
+// chainRunTotals sums the tokens and cost of a run's steps
+func chainRunTotals(steps []ChainStepRunResult) (tokens int, cost float64) {
+	for _, step := range steps {
+		tokens += step.PromptTokens + step.OutputTokens
+		cost += step.Cost
+	}
+	return tokens, cost
 }
 
 func (s *Server) handleChains(w http.ResponseWriter, r *http.Request) {
@@ -178,6 +211,10 @@ func (s *Server) handleChainByName(w http.ResponseWriter, r *http.Request) {
 			s.handleChainSteps(w, r, chainName)
 			return
 		case "run":
+			if len(parts) >= 3 && parts[2] == "stream" {
+				s.handleChainRunStream(w, r, chainName)
+				return
+			}
 			s.handleChainRun(w, r, chainName)
 			return
 		case "runs":
@@ -218,6 +255,9 @@ func (s *Server) getChain(w http.ResponseWriter, r *http.Request, chainName stri
 			PromptName:   st.PromptName,
 			InputMapping: json.RawMessage(st.InputMapping),
 			OutputKey:    st.OutputKey,
+			Version:      st.Version,
+			DependsOn:    st.DeclaredDependencies(),
+			Condition:    st.Condition,
 		})
 	}
 
@@ -324,7 +364,6 @@ func (s *Server) handleChainSteps(w http.ResponseWriter, r *http.Request, chainN
 	}
 
 	// Validate step references
-	outputKeys := make(map[string]int)
 	for _, step := range req.Steps {
 		if step.PromptName == "" {
 			writeError(w, http.StatusBadRequest, fmt.Sprintf("step %d: prompt_name is required", step.StepOrder))
@@ -334,21 +373,63 @@ func (s *Server) handleChainSteps(w http.ResponseWriter, r *http.Request, chainN
 			writeError(w, http.StatusBadRequest, fmt.Sprintf("step %d: output_key is required", step.StepOrder))
 			return
 		}
-		outputKeys[step.OutputKey] = step.StepOrder
+	}
+	if err := validateChainStepRefs(req.Steps); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, step := range req.Steps {
+		if step.Version == "" {
+			continue
+		}
+		prompt, _, err := s.db.ResolvePromptName(step.PromptName)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		var version *db.PromptVersion
+		if prompt != nil {
+			version, err = s.resolveVersionOrTag(prompt.ID, step.Version)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+		if version == nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("step %d: version '%s' of prompt '%s' not found", step.StepOrder, step.Version, step.PromptName))
+			return
+		}
 	}
 
 	// Convert to db structs
 	dbSteps := make([]db.ChainStep, len(req.Steps))
 	for i, step := range req.Steps {
 		mappingJSON, _ := json.Marshal(step.InputMapping)
+		var dependsOn string
+		if len(step.DependsOn) > 0 {
+			dependsJSON, _ := json.Marshal(step.DependsOn)
+			dependsOn = string(dependsJSON)
+		}
 		dbSteps[i] = db.ChainStep{
 			StepOrder:    step.StepOrder,
 			PromptName:   step.PromptName,
 			InputMapping: string(mappingJSON),
 			OutputKey:    step.OutputKey,
+			Version:      step.Version,
+			DependsOn:    dependsOn,
+			Condition:    step.Condition,
 		}
 	}
 
+	planned := make([]*db.ChainStep, len(dbSteps))
+	for i := range dbSteps {
+		planned[i] = &dbSteps[i]
+	}
+	if _, err := db.PlanChainSteps(planned); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	if err := s.db.ReplaceChainSteps(chain.ID, dbSteps); err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -364,153 +445,373 @@ func (s *Server) handleChainSteps(w http.ResponseWriter, r *http.Request, chainN
 			PromptName:   st.PromptName,
 			InputMapping: json.RawMessage(st.InputMapping),
 			OutputKey:    st.OutputKey,
+			Version:      st.Version,
+			DependsOn:    st.DeclaredDependencies(),
+			Condition:    st.Condition,
 		})
 	}
 
 	writeJSON(w, http.StatusOK, stepResponses)
 }
 
+// validateChainStepRefs checks that every step output an input mapping
+// refers to is produced by some step. A linear chain runs in step_order, so
+// there it must be a strictly earlier step: a reference to the same or a
+// later step would resolve to empty input. A chain whose steps declare
+// depends_on runs in dependency order instead, which PlanChainSteps checks.
+// A step's condition must parse, and is held to the same rules.
+func validateChainStepRefs(steps []ChainStepInput) error {
+	producers := make(map[string][]int)
+	for _, step := range steps {
+		producers[step.OutputKey] = append(producers[step.OutputKey], step.StepOrder)
+	}
+	graph := slices.ContainsFunc(steps, func(step ChainStepInput) bool { return len(step.DependsOn) > 0 })
+
+	for _, step := range steps {
+		// checkRef checks a reference to steps.key from the step's where
+		checkRef := func(where, key string) error {
+			orders, ok := producers[key]
+			if !ok {
+				return fmt.Errorf("step %d: %s refers to steps.%s, but no step has output_key '%s'", step.StepOrder, where, key, key)
+			}
+			if !graph && !slices.ContainsFunc(orders, func(order int) bool { return order < step.StepOrder }) {
+				return fmt.Errorf("step %d: %s refers to steps.%s, which is produced by step %d; only earlier steps' outputs can be used", step.StepOrder, where, key, orders[0])
+			}
+			return nil
+		}
+
+		if step.Condition != "" {
+			expr, err := condition.Parse(step.Condition)
+			if err != nil {
+				return fmt.Errorf("step %d: %w", step.StepOrder, err)
+			}
+			for _, key := range expr.StepRefs() {
+				if err := checkRef("condition", key); err != nil {
+					return err
+				}
+			}
+		}
+
+		var mapping map[string]string
+		if len(step.InputMapping) == 0 || json.Unmarshal(step.InputMapping, &mapping) != nil {
+			continue
+		}
+
+		vars := make([]string, 0, len(mapping))
+		for v := range mapping {
+			vars = append(vars, v)
+		}
+		sort.Strings(vars)
+
+		for _, v := range vars {
+			for _, m := range db.ChainStepRef.FindAllStringSubmatch(mapping[v], -1) {
+				if err := checkRef(fmt.Sprintf("input '%s'", v), m[1]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (s *Server) handleChainRun(w http.ResponseWriter, r *http.Request, chainName string) {
+	run, ok := s.prepareChainRun(w, r, chainName)
+	if !ok {
+		return
+	}
+
+	// A single bounded context spans the whole run so a hung step cannot
+	// block the request indefinitely.
+	ctx, cancel := llmContext(r)
+	defer cancel()
+
+	resp, err := s.executeChainRun(ctx, run, nil)
+	if err != nil {
+		writeError(w, err.status, err.message)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleChainRunStream runs a chain like handleChainRun but replies with
+// Server-Sent Events: a "step" event carrying each step's ChainStepRunResult
+// as the step finishes, then a "done" event with the same ChainRunResponse
+// handleChainRun returns. A step that fails ends the stream with an "error"
+// event.
+func (s *Server) handleChainRunStream(w http.ResponseWriter, r *http.Request, chainName string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	run, ok := s.prepareChainRun(w, r, chainName)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx, cancel := llmContext(r)
+	defer cancel()
+
+	resp, runErr := s.executeChainRun(ctx, run, func(step ChainStepRunResult) error {
+		if err := writeSSE(w, "step", step); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	if runErr != nil {
+		writeSSE(w, "error", map[string]string{"error": runErr.message})
+		flusher.Flush()
+		return
+	}
+
+	writeSSE(w, "done", resp)
+	flusher.Flush()
+}
+
+// chainRun is a validated request to run a chain, ready to execute
+type chainRun struct {
+	chain    *db.Chain
+	waves    [][]*db.ChainStep // from db.PlanChainSteps
+	inputs   map[string]string
+	provider benchmark.Provider
+	model    string
+}
+
+// prepareChainRun reads and validates a chain run request. On failure it
+// has already written the error response and returns false.
+func (s *Server) prepareChainRun(w http.ResponseWriter, r *http.Request, chainName string) (*chainRun, bool) {
 	if r.Method != http.MethodPost {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
-		return
+		return nil, false
 	}
 
 	chain, err := s.db.GetChainByName(chainName)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
-		return
+		return nil, false
 	}
 	if chain == nil {
 		writeError(w, http.StatusNotFound, fmt.Sprintf("chain '%s' not found", chainName))
-		return
+		return nil, false
 	}
 
 	var req RunChainRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
-		return
+		return nil, false
 	}
 
 	if req.Model == "" {
 		writeError(w, http.StatusBadRequest, "model is required")
-		return
+		return nil, false
 	}
 
 	steps, err := s.db.ListChainSteps(chain.ID)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
-		return
+		return nil, false
 	}
 	if len(steps) == 0 {
 		writeError(w, http.StatusBadRequest, "chain has no steps")
-		return
+		return nil, false
+	}
+	waves, err := db.PlanChainSteps(steps)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil, false
 	}
 
 	// Create provider
-	registry := benchmark.NewProviderRegistry()
-	if openai, err := benchmark.NewOpenAIProvider(); err == nil {
-		registry.Register(openai)
-	}
-	if anthropic, err := benchmark.NewAnthropicProvider(); err == nil {
-		registry.Register(anthropic)
-	}
+	registry := s.providerRegistry()
+	model := registry.ResolveModel(req.Model)
 
-	provider, err := registry.GetForModel(req.Model)
+	provider, err := registry.GetForModel(model)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
-		return
+		return nil, false
 	}
 
-	// Execute chain steps. A single bounded context spans the whole run so a
-	// hung step cannot block the request indefinitely.
-	ctx, cancel := llmContext(r)
-	defer cancel()
+	return &chainRun{chain: chain, waves: waves, inputs: req.Inputs, provider: provider, model: model}, true
+}
+
+// chainRunError is why a chain run stopped, with the HTTP status it is
+// reported with when the response is not streamed
+type chainRunError struct {
+	status  int
+	message string
+	// providerFailed is set when the step's completion failed, after which
+	// the run is saved as failed
+	providerFailed bool
+}
 
+// executeChainRun runs the chain's steps wave by wave, feeding each step's
+// output to the steps after it, and saves the run. The steps of a wave run
+// concurrently. onStep, if not nil, is called with each step's result as
+// soon as the step finishes; calls are never concurrent.
+func (s *Server) executeChainRun(ctx context.Context, run *chainRun, onStep func(ChainStepRunResult) error) (*ChainRunResponse, *chainRunError) {
 	stepOutputs := make(map[string]string)
 	var stepResults []ChainStepRunResult
 	var finalOutput string
-
-	for _, step := range steps {
-		// Resolve input mapping
-		var inputMap map[string]string
-		if err := json.Unmarshal([]byte(step.InputMapping), &inputMap); err != nil {
-			inputMap = map[string]string{}
+	var onStepMu sync.Mutex
+
+	for _, wave := range run.waves {
+		// Steps in a wave only read outputs of earlier waves, so stepOutputs
+		// is not written until the whole wave is done
+		results := make([]ChainStepRunResult, len(wave))
+		errs := make([]*chainRunError, len(wave))
+		var wg sync.WaitGroup
+		for i, step := range wave {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := s.runChainStep(ctx, run, step, stepOutputs)
+				if err == nil && onStep != nil {
+					onStepMu.Lock()
+					if writeErr := onStep(result); writeErr != nil {
+						// The client has gone away, so stop rather than pay for the remaining steps
+						err = &chainRunError{status: http.StatusInternalServerError, message: fmt.Sprintf("step %d: %v", step.StepOrder, writeErr)}
+					}
+					onStepMu.Unlock()
+				}
+				results[i], errs[i] = result, err
+			}()
 		}
-
-		resolvedVars := make(map[string]any)
-		for varName, source := range inputMap {
-			resolved := resolveChainInput(source, req.Inputs, stepOutputs)
-			resolvedVars[varName] = resolved
+		wg.Wait()
+
+		var failure *chainRunError
+		for i, step := range wave {
+			if errs[i] != nil {
+				if failure == nil {
+					failure = errs[i]
+				}
+				continue
+			}
+			stepResults = append(stepResults, results[i])
+			if results[i].Skipped {
+				continue
+			}
+			stepOutputs[step.OutputKey] = results[i].Output
+			finalOutput = results[i].Output
 		}
-
-		// Load prompt and render
-		prompt, err := s.db.GetPromptByName(step.PromptName)
-		if err != nil || prompt == nil {
-			writeError(w, http.StatusBadRequest, fmt.Sprintf("step %d: prompt '%s' not found", step.StepOrder, step.PromptName))
-			return
+		if failure != nil {
+			if failure.providerFailed {
+				inputsJSON, _ := json.Marshal(run.inputs)
+				resultsJSON, _ := json.Marshal(stepResults)
+				totalTokens, totalCost := chainRunTotals(stepResults)
+				s.db.SaveChainRun(run.chain.ID, "failed", string(inputsJSON), string(resultsJSON), "", totalTokens, totalCost)
+			}
+			return nil, failure
 		}
+	}
 
-		version, err := s.db.GetLatestVersion(prompt.ID)
-		if err != nil || version == nil {
-			writeError(w, http.StatusBadRequest, fmt.Sprintf("step %d: no version for prompt '%s'", step.StepOrder, step.PromptName))
-			return
-		}
+	// Save successful run
+	inputsJSON, _ := json.Marshal(run.inputs)
+	resultsJSON, _ := json.Marshal(stepResults)
+	totalTokens, totalCost := chainRunTotals(stepResults)
+	saved, err := s.db.SaveChainRun(run.chain.ID, "completed", string(inputsJSON), string(resultsJSON), finalOutput, totalTokens, totalCost)
+	if err != nil {
+		return nil, &chainRunError{status: http.StatusInternalServerError, message: err.Error()}
+	}
 
-		rendered, err := renderPlaygroundPrompt(version.Content, resolvedVars)
+	return &ChainRunResponse{
+		ID:          saved.ID,
+		Status:      saved.Status,
+		Inputs:      json.RawMessage(inputsJSON),
+		Results:     json.RawMessage(resultsJSON),
+		FinalOutput: finalOutput,
+		TotalTokens: saved.TotalTokens,
+		TotalCost:   saved.TotalCost,
+		StartedAt:   saved.StartedAt.Format("2006-01-02T15:04:05Z"),
+		CompletedAt: saved.CompletedAt.Format("2006-01-02T15:04:05Z"),
+	}, nil
+}
+
+// runChainStep renders one step's prompt from the run's inputs and the
+// outputs of the steps before it, and sends it to the provider. A step whose
+// condition is false is skipped without calling the provider.
+func (s *Server) runChainStep(ctx context.Context, run *chainRun, step *db.ChainStep, stepOutputs map[string]string) (ChainStepRunResult, *chainRunError) {
+	if step.Condition != "" {
+		expr, err := condition.Parse(step.Condition)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, fmt.Sprintf("step %d: render failed: %v", step.StepOrder, err))
-			return
+			return ChainStepRunResult{}, &chainRunError{status: http.StatusBadRequest, message: fmt.Sprintf("step %d: %v", step.StepOrder, err)}
 		}
+		if !expr.Eval(run.inputs, stepOutputs) {
+			return ChainStepRunResult{
+				StepOrder:  step.StepOrder,
+				PromptName: step.PromptName,
+				OutputKey:  step.OutputKey,
+				Skipped:    true,
+			}, nil
+		}
+	}
 
-		start := time.Now()
-		resp, err := provider.Complete(ctx, benchmark.CompletionRequest{
-			Model:       req.Model,
-			Prompt:      rendered,
-			MaxTokens:   1024,
-			Temperature: 1.0,
-		})
-		if err != nil {
-			// Save failed run
-			inputsJSON, _ := json.Marshal(req.Inputs)
-			resultsJSON, _ := json.Marshal(stepResults)
-			s.db.SaveChainRun(chain.ID, "failed", string(inputsJSON), string(resultsJSON), "")
-			writeError(w, http.StatusInternalServerError, fmt.Sprintf("step %d failed: %v", step.StepOrder, err))
-			return
+	// Resolve input mapping
+	var inputMap map[string]string
+	if err := json.Unmarshal([]byte(step.InputMapping), &inputMap); err != nil {
+		inputMap = map[string]string{}
+	}
+
+	resolvedVars := make(map[string]any)
+	for varName, source := range inputMap {
+		resolved := resolveChainInput(source, run.inputs, stepOutputs)
+		resolvedVars[varName] = resolved
+	}
+
+	// Load prompt and render
+	prompt, _, err := s.db.ResolvePromptName(step.PromptName)
+	if err != nil || prompt == nil {
+		return ChainStepRunResult{}, &chainRunError{status: http.StatusBadRequest, message: fmt.Sprintf("step %d: prompt '%s' not found", step.StepOrder, step.PromptName)}
+	}
+
+	var version *db.PromptVersion
+	if step.Version != "" {
+		version, err = s.resolveVersionOrTag(prompt.ID, step.Version)
+	} else {
+		version, err = s.db.GetLatestVersion(prompt.ID)
+	}
+	if err != nil || version == nil {
+		if step.Version != "" {
+			return ChainStepRunResult{}, &chainRunError{status: http.StatusBadRequest, message: fmt.Sprintf("step %d: version '%s' of prompt '%s' not found", step.StepOrder, step.Version, step.PromptName)}
 		}
-		duration := time.Since(start).Milliseconds()
-
-		stepOutputs[step.OutputKey] = resp.Content
-		finalOutput = resp.Content
-
-		stepResults = append(stepResults, ChainStepRunResult{
-			StepOrder:      step.StepOrder,
-			PromptName:     step.PromptName,
-			OutputKey:      step.OutputKey,
-			RenderedPrompt: rendered,
-			Output:         resp.Content,
-			DurationMs:     duration,
-		})
+		return ChainStepRunResult{}, &chainRunError{status: http.StatusBadRequest, message: fmt.Sprintf("step %d: no version for prompt '%s'", step.StepOrder, step.PromptName)}
 	}
 
-	// Save successful run
-	inputsJSON, _ := json.Marshal(req.Inputs)
-	resultsJSON, _ := json.Marshal(stepResults)
-	run, err := s.db.SaveChainRun(chain.ID, "completed", string(inputsJSON), string(resultsJSON), finalOutput)
+	rendered, err := renderPlaygroundPrompt(version.Content, resolvedVars)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
-		return
+		return ChainStepRunResult{}, &chainRunError{status: http.StatusBadRequest, message: fmt.Sprintf("step %d: render failed: %v", step.StepOrder, err)}
 	}
 
-	writeJSON(w, http.StatusOK, ChainRunResponse{
-		ID:          run.ID,
-		Status:      run.Status,
-		Inputs:      json.RawMessage(inputsJSON),
-		Results:     json.RawMessage(resultsJSON),
-		FinalOutput: finalOutput,
-		StartedAt:   run.StartedAt.Format("2006-01-02T15:04:05Z"),
-		CompletedAt: run.CompletedAt.Format("2006-01-02T15:04:05Z"),
+	start := time.Now()
+	resp, err := run.provider.Complete(ctx, benchmark.CompletionRequest{
+		Model:       run.model,
+		Prompt:      rendered,
+		MaxTokens:   1024,
+		Temperature: 1.0,
 	})
+	if err != nil {
+		return ChainStepRunResult{}, &chainRunError{status: http.StatusInternalServerError, message: fmt.Sprintf("step %d failed: %v", step.StepOrder, err), providerFailed: true}
+	}
+
+	return ChainStepRunResult{
+		StepOrder:      step.StepOrder,
+		PromptName:     step.PromptName,
+		Version:        version.Version,
+		OutputKey:      step.OutputKey,
+		RenderedPrompt: rendered,
+		Output:         resp.Content,
+		DurationMs:     time.Since(start).Milliseconds(),
+		PromptTokens:   resp.PromptTokens,
+		OutputTokens:   resp.OutputTokens,
+		Cost:           resp.Cost,
+	}, nil
 }
 
 func resolveChainInput(source string, inputs map[string]string, stepOutputs map[string]string) string {
@@ -551,7 +852,24 @@ func (s *Server) handleChainRuns(w http.ResponseWriter, r *http.Request, chainNa
 		return
 	}
 
-	runs, err := s.db.ListChainRuns(chain.ID)
+	filter := db.ChainRunFilter{Status: r.URL.Query().Get("status")}
+	for _, param := range []struct {
+		name  string
+		value *int
+	}{{"limit", &filter.Limit}, {"offset", &filter.Offset}} {
+		raw := r.URL.Query().Get(param.name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("%s must be a non-negative integer", param.name))
+			return
+		}
+		*param.value = n
+	}
+
+	runs, err := s.db.ListChainRuns(chain.ID, filter)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -565,6 +883,8 @@ func (s *Server) handleChainRuns(w http.ResponseWriter, r *http.Request, chainNa
 			Inputs:      json.RawMessage(run.Inputs),
 			Results:     json.RawMessage(run.Results),
 			FinalOutput: run.FinalOutput,
+			TotalTokens: run.TotalTokens,
+			TotalCost:   run.TotalCost,
 			StartedAt:   run.StartedAt.Format("2006-01-02T15:04:05Z"),
 			CompletedAt: run.CompletedAt.Format("2006-01-02T15:04:05Z"),
 		})
@@ -83,6 +83,16 @@ func (s *Server) createComment(w http.ResponseWriter, r *http.Request, promptID
 		return
 	}
 
+	version, err := s.db.GetVersionByID(req.VersionID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if version == nil || version.PromptID != promptID {
+		writeError(w, http.StatusBadRequest, "version_id must be a version of this prompt")
+		return
+	}
+
 	comment, err := s.db.CreateComment(promptID, req.VersionID, req.LineNumber, req.Content)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
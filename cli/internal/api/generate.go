@@ -57,6 +57,10 @@ func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
 		provider, err = benchmark.NewOpenAIProvider()
 	} else if strings.HasPrefix(req.Model, "claude") {
 		provider, err = benchmark.NewAnthropicProvider()
+	} else if strings.HasPrefix(req.Model, "gemini") {
+		provider, err = benchmark.NewGeminiProvider()
+	} else if strings.HasPrefix(req.Model, benchmark.OllamaModelPrefix) {
+		provider, err = benchmark.NewOllamaProvider()
 	} else {
 		// Default to OpenAI
 		provider, err = benchmark.NewOpenAIProvider()
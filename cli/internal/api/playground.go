@@ -34,21 +34,31 @@ type PlaygroundRunResponse struct {
 	Cost           float64 `json:"cost"`
 }
 
-func (s *Server) handlePlaygroundRun(w http.ResponseWriter, r *http.Request) {
+// playgroundRun is a validated playground request, ready to send
+type playgroundRun struct {
+	provider benchmark.Provider
+	request  benchmark.CompletionRequest
+	rendered string
+}
+
+// preparePlaygroundRun decodes and validates a playground request, resolves
+// the prompt and renders it. On failure it writes the error response and
+// returns false.
+func (s *Server) preparePlaygroundRun(w http.ResponseWriter, r *http.Request) (*playgroundRun, bool) {
 	if r.Method != http.MethodPost {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
-		return
+		return nil, false
 	}
 
 	var req PlaygroundRunRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
-		return
+		return nil, false
 	}
 
 	if req.Model == "" {
 		writeError(w, http.StatusBadRequest, "model is required")
-		return
+		return nil, false
 	}
 
 	// Resolve prompt content
@@ -57,51 +67,44 @@ func (s *Server) handlePlaygroundRun(w http.ResponseWriter, r *http.Request) {
 		prompt, err := s.db.GetPromptByName(req.PromptName)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, err.Error())
-			return
+			return nil, false
 		}
 		if prompt == nil {
 			writeError(w, http.StatusNotFound, fmt.Sprintf("prompt '%s' not found", req.PromptName))
-			return
+			return nil, false
 		}
 
 		var version *db.PromptVersion
 		if req.Version != "" {
-			version, err = s.db.GetVersionByString(prompt.ID, req.Version)
+			version, err = s.resolveVersionOrTag(prompt.ID, req.Version)
 		} else {
 			version, err = s.db.GetLatestVersion(prompt.ID)
 		}
 		if err != nil || version == nil {
 			writeError(w, http.StatusNotFound, "version not found")
-			return
+			return nil, false
 		}
 		promptContent = version.Content
 	}
 
 	if promptContent == "" {
 		writeError(w, http.StatusBadRequest, "prompt content or prompt_name is required")
-		return
+		return nil, false
 	}
 
 	// Render variables into prompt
 	rendered, err := renderPlaygroundPrompt(promptContent, req.Variables)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, fmt.Sprintf("failed to render prompt: %v", err))
-		return
+		return nil, false
 	}
 
-	// Create provider
-	registry := benchmark.NewProviderRegistry()
-	if openai, err := benchmark.NewOpenAIProvider(); err == nil {
-		registry.Register(openai)
-	}
-	if anthropic, err := benchmark.NewAnthropicProvider(); err == nil {
-		registry.Register(anthropic)
-	}
-
-	provider, err := registry.GetForModel(req.Model)
+	registry := s.providerRegistry()
+	model := registry.ResolveModel(req.Model)
+	provider, err := registry.GetForModel(model)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
-		return
+		return nil, false
 	}
 
 	maxTokens := req.MaxTokens
@@ -113,15 +116,43 @@ func (s *Server) handlePlaygroundRun(w http.ResponseWriter, r *http.Request) {
 		temperature = *req.Temperature
 	}
 
+	return &playgroundRun{
+		provider: provider,
+		request: benchmark.CompletionRequest{
+			Model:       model,
+			Prompt:      rendered,
+			MaxTokens:   maxTokens,
+			Temperature: temperature,
+		},
+		rendered: rendered,
+	}, true
+}
+
+// resolveVersionOrTag finds a version by version string, falling back to a
+// tag of that name. It returns nil if neither exists.
+func (s *Server) resolveVersionOrTag(promptID, ref string) (*db.PromptVersion, error) {
+	v, err := s.db.GetVersionByString(promptID, ref)
+	if err != nil || v != nil {
+		return v, err
+	}
+
+	tag, err := s.db.GetTagByName(promptID, ref)
+	if err != nil || tag == nil {
+		return nil, err
+	}
+	return s.db.GetVersionByID(tag.VersionID)
+}
+
+func (s *Server) handlePlaygroundRun(w http.ResponseWriter, r *http.Request) {
+	run, ok := s.preparePlaygroundRun(w, r)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := llmContext(r)
 	defer cancel()
 	start := time.Now()
-	resp, err := provider.Complete(ctx, benchmark.CompletionRequest{
-		Model:       req.Model,
-		Prompt:      rendered,
-		MaxTokens:   maxTokens,
-		Temperature: temperature,
-	})
+	resp, err := run.provider.Complete(ctx, run.request)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, fmt.Sprintf("completion failed: %v", err))
 		return
@@ -130,7 +161,7 @@ func (s *Server) handlePlaygroundRun(w http.ResponseWriter, r *http.Request) {
 
 	writeJSON(w, http.StatusOK, PlaygroundRunResponse{
 		Output:         resp.Content,
-		RenderedPrompt: rendered,
+		RenderedPrompt: run.rendered,
 		Model:          resp.Model,
 		PromptTokens:   resp.PromptTokens,
 		OutputTokens:   resp.OutputTokens,
@@ -139,6 +170,73 @@ func (s *Server) handlePlaygroundRun(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handlePlaygroundStream runs a playground request like handlePlaygroundRun
+// but replies with Server-Sent Events: a data event with {"content": ...}
+// for each chunk of output as it arrives, then a "done" event carrying the
+// same summary handlePlaygroundRun returns. A failure after streaming has
+// begun is sent as an "error" event.
+func (s *Server) handlePlaygroundStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	run, ok := s.preparePlaygroundRun(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx, cancel := llmContext(r)
+	defer cancel()
+	start := time.Now()
+	resp, err := benchmark.CompleteStream(ctx, run.provider, run.request, func(chunk string) error {
+		if err := writeSSE(w, "", map[string]string{"content": chunk}); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	if err != nil {
+		writeSSE(w, "error", map[string]string{"error": fmt.Sprintf("completion failed: %v", err)})
+		flusher.Flush()
+		return
+	}
+
+	writeSSE(w, "done", PlaygroundRunResponse{
+		Output:         resp.Content,
+		RenderedPrompt: run.rendered,
+		Model:          resp.Model,
+		PromptTokens:   resp.PromptTokens,
+		OutputTokens:   resp.OutputTokens,
+		LatencyMs:      time.Since(start).Milliseconds(),
+		Cost:           resp.Cost,
+	})
+	flusher.Flush()
+}
+
+// writeSSE writes one Server-Sent Event with data encoded as JSON. An empty
+// event name sends a plain message event.
+func writeSSE(w http.ResponseWriter, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if event != "" {
+		if _, err := fmt.Fprintf(w, "event: %s\n", event); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", payload)
+	return err
+}
+
 func renderPlaygroundPrompt(tmplBody string, vars map[string]any) (string, error) {
 	if vars == nil || len(vars) == 0 {
 		return tmplBody, nil
@@ -185,5 +283,17 @@ func (s *Server) handleProviderModels(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if gemini, err := benchmark.NewGeminiProvider(); err == nil {
+		for _, m := range gemini.Models() {
+			models = append(models, ModelInfo{ID: m, Provider: "google"})
+		}
+	}
+
+	if ollama, err := benchmark.NewOllamaProvider(); err == nil {
+		for _, m := range ollama.Models() {
+			models = append(models, ModelInfo{ID: benchmark.OllamaModelPrefix + m, Provider: "ollama"})
+		}
+	}
+
 	writeJSON(w, http.StatusOK, ProviderModelsResponse{Models: models})
 }
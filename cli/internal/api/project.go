@@ -1,10 +1,14 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
-	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Handlers
@@ -46,33 +50,61 @@ func (s *Server) handleSyncConfig(w http.ResponseWriter, r *http.Request) {
 
 	configPath := filepath.Join(s.root, ".promptsmith", "config.yaml")
 	data, err := os.ReadFile(configPath)
-	if err != nil {
+	if errors.Is(err, fs.ErrNotExist) {
 		// No config file — return defaults
 		writeJSON(w, http.StatusOK, SyncConfigResponse{Status: "not_configured"})
 		return
 	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to read config: %v", err))
+		return
+	}
 
-	// Simple YAML key-value parsing (avoids yaml dependency)
-	cfg := SyncConfigResponse{Status: "configured"}
-	for _, line := range strings.Split(string(data), "\n") {
-		line = strings.TrimSpace(line)
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		key := strings.TrimSpace(parts[0])
-		val := strings.TrimSpace(parts[1])
-		switch key {
-		case "team":
-			cfg.Team = val
-		case "remote":
-			cfg.Remote = val
-		case "auto_push":
-			cfg.AutoPush = val == "true"
-		}
+	var file syncConfigFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to parse config: %v", err))
+		return
+	}
+
+	settings := file.Sync
+	if settings == nil {
+		settings = &file.syncSettings
 	}
+	writeJSON(w, http.StatusOK, SyncConfigResponse{
+		Team:     settings.Team,
+		Remote:   settings.Remote,
+		AutoPush: settings.AutoPush,
+		Status:   "configured",
+	})
+}
+
+// syncConfigFile is the part of config.yaml describing sync. The settings
+// live in a sync: block; older configs put them at the top level.
+type syncConfigFile struct {
+	Sync         *syncSettings `yaml:"sync"`
+	syncSettings `yaml:",inline"`
+}
+
+type syncSettings struct {
+	Team     string `yaml:"team"`
+	Remote   string `yaml:"remote"`
+	AutoPush bool   `yaml:"auto_push"`
+}
 
-	writeJSON(w, http.StatusOK, cfg)
+// loadModelAliases reads the model_aliases mapping from the project config.
+// A missing or unreadable config means no aliases.
+func loadModelAliases(root string) map[string]string {
+	data, err := os.ReadFile(filepath.Join(root, ".promptsmith", "config.yaml"))
+	if err != nil {
+		return nil
+	}
+	var cfg struct {
+		ModelAliases map[string]string `yaml:"model_aliases"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil
+	}
+	return cfg.ModelAliases
 }
 
 type ProjectResponse struct {
@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -124,7 +125,11 @@ func (s *Server) createPrompt(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) listPrompts(w http.ResponseWriter, r *http.Request) {
-	prompts, err := s.db.ListPromptsWithLatestVersion()
+	// ?include=content returns each prompt's latest content in the same query,
+	// saving list views a request per prompt
+	includeContent := r.URL.Query().Get("include") == "content"
+
+	prompts, err := s.db.ListPromptsWithLatestVersion(includeContent)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -133,12 +138,15 @@ func (s *Server) listPrompts(w http.ResponseWriter, r *http.Request) {
 	response := make([]PromptResponse, 0, len(prompts))
 	for _, p := range prompts {
 		response = append(response, PromptResponse{
-			ID:          p.ID,
-			Name:        p.Name,
-			Description: p.Description,
-			FilePath:    p.FilePath,
-			Version:     p.LatestVersion,
-			CreatedAt:   p.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			ID:              p.ID,
+			Name:            p.Name,
+			Description:     p.Description,
+			FilePath:        p.FilePath,
+			Frozen:          p.Frozen,
+			Version:         p.LatestVersion,
+			Content:         p.LatestContent,
+			EstimatedTokens: p.LatestTokens,
+			CreatedAt:       p.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		})
 	}
 
@@ -160,6 +168,10 @@ func (s *Server) handlePromptByID(w http.ResponseWriter, r *http.Request) {
 	if len(parts) >= 2 {
 		switch parts[1] {
 		case "versions":
+			if len(parts) >= 3 && parts[2] != "" {
+				s.getVersion(w, r, promptID, parts[2])
+				return
+			}
 			s.handleVersions(w, r, promptID)
 			return
 		case "diff":
@@ -171,6 +183,9 @@ func (s *Server) handlePromptByID(w http.ResponseWriter, r *http.Request) {
 		case "comments":
 			s.handleComments(w, r, promptID)
 			return
+		case "export":
+			s.exportPrompt(w, r, promptID)
+			return
 		}
 	}
 
@@ -221,7 +236,7 @@ func (s *Server) updatePrompt(w http.ResponseWriter, r *http.Request, promptName
 			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
-		if existing != nil {
+		if existing != nil && existing.ID != prompt.ID {
 			writeError(w, http.StatusConflict, fmt.Sprintf("prompt '%s' already exists", req.Name))
 			return
 		}
@@ -232,6 +247,12 @@ func (s *Server) updatePrompt(w http.ResponseWriter, r *http.Request, promptName
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if updated.Name != prompt.Name {
+		if err := s.db.RecordRename(prompt.ID, prompt.Name, updated.Name); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
 
 	latestVersion, _ := s.db.GetLatestVersion(updated.ID)
 	var versionStr string
@@ -244,6 +265,7 @@ func (s *Server) updatePrompt(w http.ResponseWriter, r *http.Request, promptName
 		Name:        updated.Name,
 		Description: updated.Description,
 		FilePath:    updated.FilePath,
+		Frozen:      updated.Frozen,
 		Version:     versionStr,
 		CreatedAt:   updated.CreatedAt.Format("2006-01-02T15:04:05Z"),
 	})
@@ -348,6 +370,7 @@ func (s *Server) getPrompt(w http.ResponseWriter, r *http.Request, promptID stri
 		Name:        prompt.Name,
 		Description: prompt.Description,
 		FilePath:    prompt.FilePath,
+		Frozen:      prompt.Frozen,
 		CreatedAt:   prompt.CreatedAt.Format("2006-01-02T15:04:05Z"),
 	}
 
@@ -358,6 +381,116 @@ func (s *Server) getPrompt(w http.ResponseWriter, r *http.Request, promptID stri
 	writeJSON(w, http.StatusOK, response)
 }
 
+// exportPrompt handles GET /api/prompts/:name/export, returning the prompt's
+// full history as a JSON download
+func (s *Server) exportPrompt(w http.ResponseWriter, r *http.Request, promptName string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	export, err := s.db.ExportPrompt(promptName)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if export == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("prompt '%s' not found", promptName))
+		return
+	}
+
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": export.Prompt.Name + ".json",
+	}))
+	writeJSON(w, http.StatusOK, export)
+}
+
+// getVersion handles GET /api/prompts/:name/versions/:version. The version may
+// be a version string or ID; ?ancestry=true includes the chain of parents.
+func (s *Server) getVersion(w http.ResponseWriter, r *http.Request, promptName, versionRef string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	prompt, err := s.db.GetPromptByName(promptName)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if prompt == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("prompt '%s' not found", promptName))
+		return
+	}
+
+	version, err := s.db.GetVersionByString(prompt.ID, versionRef)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if version == nil {
+		version, err = s.db.GetVersionByID(versionRef)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if version != nil && version.PromptID != prompt.ID {
+			version = nil
+		}
+	}
+	if version == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("version '%s' not found", versionRef))
+		return
+	}
+
+	tags, err := s.db.ListTags(prompt.ID)
+	if err != nil {
+		tags = []*db.Tag{}
+	}
+	tagMap := make(map[string][]string)
+	for _, t := range tags {
+		tagMap[t.VersionID] = append(tagMap[t.VersionID], t.Name)
+	}
+
+	toResponse := func(v *db.PromptVersion) VersionResponse {
+		return VersionResponse{
+			ID:            v.ID,
+			Version:       v.Version,
+			Content:       v.Content,
+			CommitMessage: v.CommitMessage,
+			CreatedAt:     v.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			Tags:          tagMap[v.ID],
+			Metadata:      versionMetadata(v.Metadata),
+		}
+	}
+
+	response := toResponse(version)
+
+	if r.URL.Query().Get("ancestry") == "true" {
+		ancestry, err := s.db.GetVersionAncestry(version.ID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		response.Ancestry = make([]VersionResponse, 0, len(ancestry))
+		for _, v := range ancestry {
+			response.Ancestry = append(response.Ancestry, toResponse(v))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// versionMetadata decodes a version's stored metadata JSON, returning nil
+// when it is empty or malformed.
+func versionMetadata(raw string) map[string]any {
+	var meta map[string]any
+	if err := json.Unmarshal([]byte(raw), &meta); err != nil || len(meta) == 0 {
+		return nil
+	}
+	return meta
+}
+
 type CreateVersionRequest struct {
 	Content       string `json:"content"`
 	CommitMessage string `json:"commit_message"`
@@ -413,6 +546,7 @@ func (s *Server) handleVersions(w http.ResponseWriter, r *http.Request, promptID
 			CommitMessage: v.CommitMessage,
 			CreatedAt:     v.CreatedAt.Format("2006-01-02T15:04:05Z"),
 			Tags:          tagMap[v.ID],
+			Metadata:      versionMetadata(v.Metadata),
 		}
 		response = append(response, vr)
 	}
@@ -445,6 +579,10 @@ func (s *Server) createVersion(w http.ResponseWriter, r *http.Request, promptNam
 		writeError(w, http.StatusNotFound, fmt.Sprintf("prompt '%s' not found", promptName))
 		return
 	}
+	if prompt.Frozen {
+		writeError(w, http.StatusConflict, fmt.Sprintf("prompt '%s' is frozen", promptName))
+		return
+	}
 
 	// Get latest version to compute next version
 	latest, _ := s.db.GetLatestVersion(prompt.ID)
@@ -567,19 +705,25 @@ func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request, promptID str
 }
 
 type PromptResponse struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	FilePath    string `json:"file_path"`
-	Version     string `json:"version,omitempty"`
-	CreatedAt   string `json:"created_at"`
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	FilePath        string `json:"file_path"`
+	Frozen          bool   `json:"frozen"`
+	Version         string `json:"version,omitempty"`
+	Content         string `json:"content,omitempty"` // Latest content, when requested
+	EstimatedTokens int    `json:"estimated_tokens,omitempty"`
+	CreatedAt       string `json:"created_at"`
 }
 
 type VersionResponse struct {
-	ID            string   `json:"id"`
-	Version       string   `json:"version"`
-	Content       string   `json:"content"`
-	CommitMessage string   `json:"commit_message"`
-	CreatedAt     string   `json:"created_at"`
-	Tags          []string `json:"tags,omitempty"`
+	ID            string         `json:"id"`
+	Version       string         `json:"version"`
+	Content       string         `json:"content"`
+	CommitMessage string         `json:"commit_message"`
+	CreatedAt     string         `json:"created_at"`
+	Tags          []string       `json:"tags,omitempty"`
+	Metadata      map[string]any `json:"metadata,omitempty"`
+	// Ancestry lists this version and its parents back to the root
+	Ancestry []VersionResponse `json:"ancestry,omitempty"`
 }
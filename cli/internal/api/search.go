@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/promptsmith/cli/internal/db"
+)
+
+// Search handlers
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeError(w, http.StatusBadRequest, "q is required")
+		return
+	}
+
+	results, err := s.db.SearchVersions(query)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if results == nil {
+		results = []db.SearchResult{}
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
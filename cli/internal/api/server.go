@@ -2,22 +2,39 @@ package api
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/promptsmith/cli/internal/benchmark"
 	"github.com/promptsmith/cli/internal/db"
 )
 
 type Server struct {
-	db   *db.DB
-	root string
-	mux  *http.ServeMux
+	db        *db.DB
+	root      string
+	mux       *http.ServeMux
+	logger    *log.Logger
+	authToken string
 }
 
+// TokenEnvVar names the environment variable holding the bearer token that
+// API requests must present. Unset means no authentication.
+const TokenEnvVar = "PROMPTSMITH_API_TOKEN"
+
+// requestIDHeader carries a per-request correlation ID. Clients may supply
+// their own; otherwise the server generates one.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied request IDs so they can be
+// echoed into logs safely.
+const maxRequestIDLength = 128
+
 const maxRequestBodyBytes int64 = 10 << 20 // 10 MiB
 
 // llmRequestTimeout bounds long-running LLM operations (benchmarks, generation,
@@ -31,6 +48,34 @@ func llmContext(r *http.Request) (context.Context, context.CancelFunc) {
 	return context.WithTimeout(r.Context(), llmRequestTimeout)
 }
 
+// newProviderRegistry returns a registry of every provider that is
+// configured, for handlers that call an LLM. Tests replace it to stub
+// providers.
+var newProviderRegistry = func() *benchmark.ProviderRegistry {
+	registry := benchmark.NewProviderRegistry()
+	if openai, err := benchmark.NewOpenAIProvider(); err == nil {
+		registry.Register(openai)
+	}
+	if anthropic, err := benchmark.NewAnthropicProvider(); err == nil {
+		registry.Register(anthropic)
+	}
+	if gemini, err := benchmark.NewGeminiProvider(); err == nil {
+		registry.Register(gemini)
+	}
+	if ollama, err := benchmark.NewOllamaProvider(); err == nil {
+		registry.Register(ollama)
+	}
+	return registry
+}
+
+// providerRegistry returns a provider registry that resolves the project's
+// model aliases
+func (s *Server) providerRegistry() *benchmark.ProviderRegistry {
+	registry := newProviderRegistry()
+	registry.SetAliases(loadModelAliases(s.root))
+	return registry
+}
+
 var allowedCORSOrigins = map[string]struct{}{
 	"http://localhost:8080": {},
 	"http://127.0.0.1:8080": {},
@@ -62,8 +107,10 @@ func (s *Server) setupRoutes() {
 	s.mux.HandleFunc("/api/generate/", s.corsMiddleware(s.handleGenerateAlias))
 	s.mux.HandleFunc("/api/comments/", s.corsMiddleware(s.handleCommentByID))
 	s.mux.HandleFunc("/api/playground/run", s.corsMiddleware(s.handlePlaygroundRun))
+	s.mux.HandleFunc("/api/playground/stream", s.corsMiddleware(s.handlePlaygroundStream))
 	s.mux.HandleFunc("/api/providers/models", s.corsMiddleware(s.handleProviderModels))
 	s.mux.HandleFunc("/api/dashboard/", s.corsMiddleware(s.handleDashboard))
+	s.mux.HandleFunc("/api/search", s.corsMiddleware(s.handleSearch))
 	s.mux.HandleFunc("/api/chains", s.corsMiddleware(s.handleChains))
 	s.mux.HandleFunc("/api/chains/", s.corsMiddleware(s.handleChainByName))
 }
@@ -80,13 +127,21 @@ func (s *Server) corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 			w.Header().Add("Vary", "Origin")
 		}
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, "+requestIDHeader)
+		w.Header().Set("Access-Control-Expose-Headers", requestIDHeader)
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 
+		// Preflights above carry no credentials, so they are let through
+		if !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
 		if r.Body != nil {
 			r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
 		}
@@ -95,8 +150,71 @@ func (s *Server) corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// SetAuthToken requires every API request to present token as a bearer
+// token. An empty token turns authentication off.
+func (s *Server) SetAuthToken(token string) {
+	s.authToken = token
+}
+
+// authorized reports whether r carries the configured bearer token
+func (s *Server) authorized(r *http.Request) bool {
+	if s.authToken == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.authToken)) == 1
+}
+
+// SetLogger enables request logging. Each line includes the request ID.
+func (s *Server) SetLogger(logger *log.Logger) {
+	s.logger = logger
+}
+
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	s.mux.ServeHTTP(w, r)
+	requestID := requestIDFrom(r)
+	w.Header().Set(requestIDHeader, requestID)
+
+	if s.logger == nil {
+		s.mux.ServeHTTP(w, r)
+		return
+	}
+
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	s.mux.ServeHTTP(rec, r)
+	s.logger.Printf("%s %s %d %s request_id=%s", r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Millisecond), requestID)
+}
+
+// requestIDFrom echoes a well-formed incoming request ID or generates a new one
+func requestIDFrom(r *http.Request) string {
+	id := strings.TrimSpace(r.Header.Get(requestIDHeader))
+	if id == "" || len(id) > maxRequestIDLength {
+		return db.NewUUID()
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return db.NewUUID()
+		}
+	}
+	return id
+}
+
+// statusRecorder captures the status code written by a handler for logging
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush passes flushes through so streamed responses work with logging on
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
 }
 
 func (s *Server) ListenAndServe(addr string) error {
@@ -1,15 +1,27 @@
 package api
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/promptsmith/cli/internal/benchmark"
 	"github.com/promptsmith/cli/internal/db"
+	"github.com/promptsmith/cli/internal/prompt"
 )
 
 // Test helper to set up a test project
@@ -181,6 +193,92 @@ func TestListPrompts(t *testing.T) {
 	if len(response) > 0 && response[0].Version != "1.0.1" {
 		t.Errorf("prompt version = %q, want %q", response[0].Version, "1.0.1")
 	}
+	if len(response) > 0 && response[0].Content != "" {
+		t.Errorf("expected no content without include=content, got %q", response[0].Content)
+	}
+}
+
+func TestExportPrompt(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	prompt, _ := database.GetPromptByName("summarizer")
+	v1, _ := database.CreateVersion(prompt.ID, "1.0.0", "content v1", `["text"]`, "{}", "Initial", "user", nil)
+	v2, _ := database.CreateVersion(prompt.ID, "1.0.1", "content v2", `["text"]`, "{}", "Update", "user", &v1.ID)
+	database.CreateVersion(prompt.ID, "1.0.2", "content v3", `["text"]`, "{}", "Again", "user", &v2.ID)
+	if _, err := database.CreateTag(prompt.ID, v2.ID, "prod"); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+
+	server := NewServer(database, tmpDir)
+
+	req := httptest.NewRequest("GET", "/api/prompts/summarizer/export", nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename=summarizer.json` {
+		t.Errorf("unexpected Content-Disposition %q", cd)
+	}
+
+	var export db.PromptExport
+	if err := json.NewDecoder(rec.Body).Decode(&export); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if export.Prompt.Name != "summarizer" {
+		t.Errorf("prompt name = %q, want summarizer", export.Prompt.Name)
+	}
+
+	var got []string
+	for _, v := range export.Versions {
+		got = append(got, v.Version+":"+v.Content+":"+v.Parent)
+	}
+	want := []string{"1.0.0:content v1:", "1.0.1:content v2:1.0.0", "1.0.2:content v3:1.0.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("versions = %v, want %v", got, want)
+	}
+	if string(export.Versions[0].Variables) != `["text"]` {
+		t.Errorf("expected variables to be embedded as JSON, got %s", export.Versions[0].Variables)
+	}
+	if len(export.Tags) != 1 || export.Tags[0] != (db.ExportedTag{Name: "prod", Version: "1.0.1"}) {
+		t.Errorf("unexpected tags %+v", export.Tags)
+	}
+
+	req = httptest.NewRequest("GET", "/api/prompts/missing/export", nil)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d for missing prompt", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestListPromptsIncludeContent(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	prompt, _ := database.GetPromptByName("summarizer")
+	v1, _ := database.CreateVersion(prompt.ID, "1.0.0", "content v1", "[]", "{}", "Initial", "user", nil)
+	database.CreateVersion(prompt.ID, "1.0.1", "content v2", "[]", "{}", "Update", "user", &v1.ID)
+
+	server := NewServer(database, tmpDir)
+
+	req := httptest.NewRequest("GET", "/api/prompts?include=content", nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var response []PromptResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response) != 1 || response[0].Content != "content v2" {
+		t.Errorf("expected latest content 'content v2', got %+v", response)
+	}
 }
 
 func TestCreatePromptRejectsPathTraversal(t *testing.T) {
@@ -320,6 +418,97 @@ func TestGetPromptVersions(t *testing.T) {
 	}
 }
 
+func TestGetPromptVersionsMetadata(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	prompt, _ := database.GetPromptByName("summarizer")
+	v1, _ := database.CreateVersion(prompt.ID, "1.0.0", "content v1", "[]", "{}", "First", "user", nil)
+	database.CreateVersion(prompt.ID, "1.0.1", "content v2", "[]", `{"ticket":"ABC-1"}`, "Second", "user", &v1.ID)
+
+	server := NewServer(database, tmpDir)
+
+	req := httptest.NewRequest("GET", "/api/prompts/summarizer/versions", nil)
+	rec := httptest.NewRecorder()
+
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var response []VersionResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	for _, v := range response {
+		if v.Version == "1.0.1" && v.Metadata["ticket"] != "ABC-1" {
+			t.Errorf("expected ticket metadata on version 1.0.1, got %v", v.Metadata)
+		}
+		if v.Version == "1.0.0" && v.Metadata != nil {
+			t.Errorf("expected no metadata on version 1.0.0, got %v", v.Metadata)
+		}
+	}
+}
+
+func TestGetVersionWithAncestry(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	prompt, _ := database.GetPromptByName("summarizer")
+	v1, _ := database.CreateVersion(prompt.ID, "1.0.0", "content v1", "[]", "{}", "First", "user", nil)
+	v2, _ := database.CreateVersion(prompt.ID, "1.0.1", "content v2", "[]", "{}", "Second", "user", &v1.ID)
+	database.CreateVersion(prompt.ID, "1.0.2", "content v3", "[]", "{}", "Third", "user", &v2.ID)
+
+	server := NewServer(database, tmpDir)
+
+	req := httptest.NewRequest("GET", "/api/prompts/summarizer/versions/1.0.2?ancestry=true", nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var response VersionResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Version != "1.0.2" {
+		t.Errorf("version = %s, want 1.0.2", response.Version)
+	}
+	if len(response.Ancestry) != 3 {
+		t.Fatalf("got %d ancestors, want 3", len(response.Ancestry))
+	}
+	if response.Ancestry[2].Version != "1.0.0" {
+		t.Errorf("root = %s, want 1.0.0", response.Ancestry[2].Version)
+	}
+
+	// Without the flag, ancestry is omitted
+	req = httptest.NewRequest("GET", "/api/prompts/summarizer/versions/"+v2.ID, nil)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	response = VersionResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Version != "1.0.1" || len(response.Ancestry) != 0 {
+		t.Errorf("got version %s with %d ancestors, want 1.0.1 with none", response.Version, len(response.Ancestry))
+	}
+
+	req = httptest.NewRequest("GET", "/api/prompts/summarizer/versions/9.9.9", nil)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
 func TestGetPromptDiff(t *testing.T) {
 	tmpDir, database, cleanup := setupTestProject(t)
 	defer cleanup()
@@ -414,6 +603,65 @@ func TestCORSHeaders(t *testing.T) {
 	}
 }
 
+func TestRequestIDHeader(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	server := NewServer(database, tmpDir)
+
+	// Generated when the client does not send one
+	req := httptest.NewRequest("GET", "/api/prompts", nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	generated := rec.Header().Get("X-Request-ID")
+	if generated == "" {
+		t.Fatal("missing X-Request-ID header")
+	}
+
+	// Echoed when supplied
+	req = httptest.NewRequest("GET", "/api/prompts", nil)
+	req.Header.Set("X-Request-ID", "client-abc-123")
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("X-Request-ID"); got != "client-abc-123" {
+		t.Errorf("X-Request-ID = %q, want echoed client-abc-123", got)
+	}
+
+	// Malformed IDs are replaced rather than echoed into logs
+	req = httptest.NewRequest("GET", "/api/prompts", nil)
+	req.Header.Set("X-Request-ID", strings.Repeat("x", 200))
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("X-Request-ID"); got == "" || len(got) > 128 {
+		t.Errorf("X-Request-ID = %q, want a generated ID", got)
+	}
+}
+
+func TestRequestIDInLogs(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	var buf bytes.Buffer
+	server := NewServer(database, tmpDir)
+	server.SetLogger(log.New(&buf, "", 0))
+
+	req := httptest.NewRequest("GET", "/api/prompts", nil)
+	req.Header.Set("X-Request-ID", "trace-42")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	line := buf.String()
+	if !strings.Contains(line, "GET /api/prompts 200") {
+		t.Errorf("log line missing method/path/status: %q", line)
+	}
+	if !strings.Contains(line, "request_id=trace-42") {
+		t.Errorf("log line missing request ID: %q", line)
+	}
+}
+
 func TestCORSRejectsDisallowedOrigin(t *testing.T) {
 	tmpDir, database, cleanup := setupTestProject(t)
 	defer cleanup()
@@ -434,6 +682,60 @@ func TestCORSRejectsDisallowedOrigin(t *testing.T) {
 	}
 }
 
+func TestAuthToken(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	server := NewServer(database, tmpDir)
+	server.SetAuthToken("s3cret")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		auth   string
+		want   int
+	}{
+		{"valid token", "GET", "/api/prompts", "Bearer s3cret", http.StatusOK},
+		{"no token", "GET", "/api/prompts", "", http.StatusUnauthorized},
+		{"wrong token", "GET", "/api/prompts", "Bearer nope", http.StatusUnauthorized},
+		{"not a bearer token", "GET", "/api/prompts", "s3cret", http.StatusUnauthorized},
+		{"delete without token", "DELETE", "/api/prompts/summarizer", "", http.StatusUnauthorized},
+		{"preflight without token", "OPTIONS", "/api/prompts", "", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("expected a WWW-Authenticate challenge, got %q", rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+
+	if p, _ := database.GetPromptByName("summarizer"); p == nil {
+		t.Error("expected the unauthorized delete to leave the prompt in place")
+	}
+
+	// Without a token, requests are not checked
+	server.SetAuthToken("")
+	req := httptest.NewRequest("GET", "/api/prompts", nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d with auth off", rec.Code, http.StatusOK)
+	}
+}
+
 func TestRejectsOversizedRequestBody(t *testing.T) {
 	tmpDir, database, cleanup := setupTestProject(t)
 	defer cleanup()
@@ -909,6 +1211,113 @@ runs_per_model: 1
 	}
 }
 
+func TestRunSuitesWithRenamedPrompt(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	// Without provider keys every benchmark run errors, but it is still recorded
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+
+	prompt, _ := database.GetPromptByName("summarizer")
+	database.CreateVersion(prompt.ID, "1.0.0", "Summarize: {{.text}}", "[]", "{}", "Initial", "user", nil)
+	renamed := renameSummarizer(t, database)
+
+	files := map[string]string{
+		filepath.Join("benchmarks", "old.bench.yaml"): "name: old-bench\nprompt: summarizer\nmodels:\n  - gpt-4o-mini\nruns_per_model: 1\n",
+		filepath.Join("tests", "old.test.yaml"):       "name: old-test\nprompt: summarizer\ntests:\n  - name: simple\n    inputs:\n      text: hello\n    assertions:\n      - type: not_empty\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, path), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	server := NewServer(database, tmpDir)
+
+	// Suites naming the prompt by its old name run and save their results
+	for _, path := range []string{"/api/benchmarks/old-bench/run", "/api/tests/old-test/run"} {
+		req := httptest.NewRequest("POST", path, nil)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("POST %s status = %d, want %d, body: %s", path, rec.Code, http.StatusOK, rec.Body.String())
+		}
+	}
+
+	if runs, _ := database.ListBenchmarkRuns("old-bench"); len(runs) != 1 {
+		t.Errorf("expected the benchmark run to be saved, got %d runs", len(runs))
+	}
+	if run, _ := database.GetLatestBenchmarkRunForPrompt(renamed.ID); run == nil {
+		t.Error("expected the benchmark run to be saved against the renamed prompt")
+	}
+	if runs, _ := database.ListTestRuns("old-test"); len(runs) != 1 {
+		t.Errorf("expected the test run to be saved, got %d runs", len(runs))
+	}
+}
+
+func TestBenchmarkRunRecordsVersion(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	// Without provider keys every run errors, but the run is still recorded
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+
+	prompt, _ := database.GetPromptByName("summarizer")
+	v1, _ := database.CreateVersion(prompt.ID, "1.0.0", "First", "[]", "{}", "Initial", "user", nil)
+	v2, _ := database.CreateVersion(prompt.ID, "1.0.1", "Second", "[]", "{}", "Update", "user", &v1.ID)
+
+	benchContent := `name: versioned-bench
+prompt: summarizer
+models:
+  - gpt-4o-mini
+runs_per_model: 1
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "benchmarks", "versioned.bench.yaml"), []byte(benchContent), 0644); err != nil {
+		t.Fatalf("failed to write benchmark file: %v", err)
+	}
+
+	server := NewServer(database, tmpDir)
+
+	for _, path := range []string{"/api/benchmarks/versioned-bench/run", "/api/benchmarks/versioned-bench/run?version=1.0.0"} {
+		req := httptest.NewRequest("POST", path, nil)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("POST %s status = %d, want %d, body: %s", path, rec.Code, http.StatusOK, rec.Body.String())
+		}
+	}
+
+	runs, err := database.ListBenchmarkRuns("versioned-bench")
+	if err != nil {
+		t.Fatalf("ListBenchmarkRuns failed: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("got %d runs, want 2", len(runs))
+	}
+	got := map[string]bool{runs[0].VersionID: true, runs[1].VersionID: true}
+	if !got[v1.ID] || !got[v2.ID] {
+		t.Errorf("expected runs for versions %s and %s, got %v", v1.ID, v2.ID, got)
+	}
+
+	req := httptest.NewRequest("GET", "/api/benchmarks/versioned-bench/runs", nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	var response []BenchmarkRunResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	versions := map[string]string{}
+	for _, run := range response {
+		versions[run.VersionID] = run.Version
+	}
+	if versions[v1.ID] != "1.0.0" || versions[v2.ID] != "1.0.1" {
+		t.Errorf("expected run versions 1.0.0 and 1.0.1, got %v", versions)
+	}
+}
+
 func TestMissingPromptID(t *testing.T) {
 	tmpDir, database, cleanup := setupTestProject(t)
 	defer cleanup()
@@ -1062,21 +1471,59 @@ func TestCreateVersionFirstVersion(t *testing.T) {
 	}
 }
 
-func TestCreateVersionValidation(t *testing.T) {
+func TestCreateVersionFrozenPrompt(t *testing.T) {
 	tmpDir, database, cleanup := setupTestProject(t)
 	defer cleanup()
 
+	prompt, _ := database.GetPromptByName("summarizer")
+	database.CreateVersion(prompt.ID, "1.0.0", "initial content", "[]", "{}", "Initial", "user", nil)
+	if err := database.SetPromptFrozen(prompt.ID, true); err != nil {
+		t.Fatalf("SetPromptFrozen failed: %v", err)
+	}
+
 	server := NewServer(database, tmpDir)
 
-	// Empty content
-	body := `{"content": ""}`
+	body := `{"content": "changed content"}`
 	req := httptest.NewRequest("POST", "/api/prompts/summarizer/versions", strings.NewReader(body))
 	rec := httptest.NewRecorder()
-
 	server.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusBadRequest {
-		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d, body: %s", rec.Code, http.StatusConflict, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "frozen") {
+		t.Errorf("expected the error to say the prompt is frozen, got %s", rec.Body.String())
+	}
+	if versions, _ := database.ListVersions(prompt.ID); len(versions) != 1 {
+		t.Errorf("expected no new version, got %d versions", len(versions))
+	}
+
+	req = httptest.NewRequest("GET", "/api/prompts/summarizer", nil)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	var response PromptResponse
+	json.NewDecoder(rec.Body).Decode(&response)
+	if !response.Frozen {
+		t.Error("expected the prompt to be reported as frozen")
+	}
+}
+
+func TestCreateVersionValidation(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	server := NewServer(database, tmpDir)
+
+	// Empty content
+	body := `{"content": ""}`
+	req := httptest.NewRequest("POST", "/api/prompts/summarizer/versions", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
 	}
 
 	// Invalid JSON
@@ -1448,6 +1895,47 @@ func TestListBenchmarkRuns(t *testing.T) {
 	}
 }
 
+func TestListBenchmarkRunsCSV(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	ensureRunParents(t, database, "", "my-bench")
+	results := `{"suite_name":"my-bench","started_at":"2026-01-02T03:04:05Z","runs":[
+		{"model":"gpt-4o","latency_ms":120,"prompt_tokens":10,"output_tokens":5,"cost":0.001,"output":"Hello, world"}
+	]}`
+	if _, err := database.SaveBenchmarkRun("my-bench", "", results); err != nil {
+		t.Fatalf("failed to save benchmark run: %v", err)
+	}
+
+	server := NewServer(database, tmpDir)
+
+	req := httptest.NewRequest("GET", "/api/benchmarks/my-bench/runs?format=csv", nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q, want text/csv", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, "my-bench-runs.csv") {
+		t.Errorf("Content-Disposition = %q, want the runs file name", cd)
+	}
+	want := "suite,started_at,model,temperature,run_index,latency_ms,prompt_tokens,output_tokens,cost,output_preview,error\n" +
+		"my-bench,2026-01-02T03:04:05Z,gpt-4o,,1,120,10,5,0.001,\"Hello, world\",\n"
+	if rec.Body.String() != want {
+		t.Errorf("body = %q, want %q", rec.Body.String(), want)
+	}
+
+	req = httptest.NewRequest("GET", "/api/benchmarks/my-bench/runs?format=xml", nil)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown format: status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
 func TestListTestRuns(t *testing.T) {
 	tmpDir, database, cleanup := setupTestProject(t)
 	defer cleanup()
@@ -1683,6 +2171,51 @@ func TestSyncConfigNotConfigured(t *testing.T) {
 	}
 }
 
+func TestSearch(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	prompt, _ := database.GetPromptByName("summarizer")
+	database.CreateVersion(prompt.ID, "1.0.0", "Summarize in one paragraph.", "[]", "{}", "Initial", "user", nil)
+	database.CreateVersion(prompt.ID, "1.0.1", "Summarize in bullet points, citing sources.", "[]", "{}", "Bullets", "user", nil)
+
+	server := NewServer(database, tmpDir)
+
+	req := httptest.NewRequest("GET", "/api/search?q=bullet+points", nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	var results []db.SearchResult
+	if err := json.NewDecoder(rec.Body).Decode(&results); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(results) != 1 || results[0].PromptName != "summarizer" || results[0].Version != "1.0.1" {
+		t.Fatalf("expected summarizer@1.0.1 only, got %+v", results)
+	}
+	if !strings.Contains(results[0].Snippet, db.SnippetMatchStart+"bullet points"+db.SnippetMatchEnd) {
+		t.Errorf("expected the match to be highlighted, got %q", results[0].Snippet)
+	}
+
+	// No matches is an empty list, not null
+	req = httptest.NewRequest("GET", "/api/search?q=haiku", nil)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("expected an empty list, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/search", nil)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without q, got %d", rec.Code)
+	}
+}
+
 func TestDashboardActivity(t *testing.T) {
 	tmpDir, database, cleanup := setupTestProject(t)
 	defer cleanup()
@@ -1807,6 +2340,87 @@ func TestDashboardHealth(t *testing.T) {
 	}
 }
 
+func TestDashboardHealthEstimatedTokens(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	summarizer, _ := database.GetPromptByName("summarizer")
+	v1, _ := database.CreateVersion(summarizer.ID, "1.0.0", "short", "[]", "{}", "Initial", "user", nil)
+	latest := strings.Repeat("word ", 20)
+	database.CreateVersion(summarizer.ID, "1.0.1", latest, "[]", "{}", "Longer", "user", &v1.ID)
+
+	server := NewServer(database, tmpDir)
+
+	req := httptest.NewRequest("GET", "/api/dashboard/health", nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	var health []db.PromptHealth
+	if err := json.NewDecoder(rec.Body).Decode(&health); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(health) != 1 {
+		t.Fatalf("got %d prompts, want 1", len(health))
+	}
+	if want := prompt.EstimateTokens(latest); health[0].EstimatedTokens != want {
+		t.Errorf("estimated_tokens = %d, want %d for the latest version", health[0].EstimatedTokens, want)
+	}
+
+	req = httptest.NewRequest("GET", "/api/prompts", nil)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	var prompts []PromptResponse
+	if err := json.NewDecoder(rec.Body).Decode(&prompts); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(prompts) != 1 || prompts[0].EstimatedTokens != prompt.EstimateTokens(latest) {
+		t.Errorf("expected the prompt list to carry %d estimated tokens, got %+v", prompt.EstimateTokens(latest), prompts)
+	}
+}
+
+func TestDashboardHealthCommitsLast30d(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	prompt, _ := database.GetPromptByName("summarizer")
+	old, _ := database.CreateVersion(prompt.ID, "1.0.0", "v1", "[]", "{}", "Initial", "user", nil)
+	v2, _ := database.CreateVersion(prompt.ID, "1.0.1", "v2", "[]", "{}", "Second", "user", &old.ID)
+	v3, _ := database.CreateVersion(prompt.ID, "1.0.2", "v3", "[]", "{}", "Third", "user", &v2.ID)
+	database.CreateVersion(prompt.ID, "1.0.3", "v4", "[]", "{}", "Fourth", "user", &v3.ID)
+
+	// Backdate the first version outside the 30 day window
+	if _, err := database.Exec("UPDATE prompt_versions SET created_at = ? WHERE id = ?", time.Now().AddDate(0, 0, -45), old.ID); err != nil {
+		t.Fatalf("failed to backdate version: %v", err)
+	}
+
+	server := NewServer(database, tmpDir)
+
+	req := httptest.NewRequest("GET", "/api/dashboard/health", nil)
+	rec := httptest.NewRecorder()
+
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var response []db.PromptHealth
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(response) != 1 {
+		t.Fatalf("got %d prompts, want 1", len(response))
+	}
+	if response[0].VersionCount != 4 {
+		t.Errorf("version_count = %d, want 4", response[0].VersionCount)
+	}
+	if response[0].CommitsLast30d != 3 {
+		t.Errorf("commits_last_30d = %d, want 3", response[0].CommitsLast30d)
+	}
+}
+
 func TestDashboardHealthEmpty(t *testing.T) {
 	tmpDir, database, cleanup := setupTestProject(t)
 	defer cleanup()
@@ -1902,6 +2516,92 @@ func TestSyncConfigConfigured(t *testing.T) {
 	}
 }
 
+func TestSyncConfigParsesYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		team     string
+		remote   string
+		autoPush bool
+	}{
+		{
+			name: "nested sync block",
+			config: `version: 1
+project:
+  name: demo
+  remote: not-the-sync-remote
+sync:
+  remote: https://sync.example.com
+  auto_push: true
+  team: acme-team
+`,
+			team:     "acme-team",
+			remote:   "https://sync.example.com",
+			autoPush: true,
+		},
+		{
+			name: "quoted values and comments",
+			config: `# team settings
+sync:
+  team: "acme: platform"   # quoted, contains a colon
+  remote: 'https://sync.example.com/a#b'
+  auto_push: false
+`,
+			team:   "acme: platform",
+			remote: "https://sync.example.com/a#b",
+		},
+		{
+			name:   "sync block absent",
+			config: "version: 1\nproject:\n  name: demo\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir, database, cleanup := setupTestProject(t)
+			defer cleanup()
+
+			configDir := filepath.Join(tmpDir, ".promptsmith")
+			os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(tt.config), 0o644)
+
+			server := NewServer(database, tmpDir)
+			req := httptest.NewRequest("GET", "/api/config/sync", nil)
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+			}
+			var resp SyncConfigResponse
+			json.NewDecoder(rec.Body).Decode(&resp)
+			want := SyncConfigResponse{Team: tt.team, Remote: tt.remote, AutoPush: tt.autoPush, Status: "configured"}
+			if resp != want {
+				t.Errorf("got %+v, want %+v", resp, want)
+			}
+		})
+	}
+}
+
+func TestSyncConfigUnparseable(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	configDir := filepath.Join(tmpDir, ".promptsmith")
+	os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte("sync:\n  remote: [unclosed\n"), 0o644)
+
+	server := NewServer(database, tmpDir)
+	req := httptest.NewRequest("GET", "/api/config/sync", nil)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d, body = %s", rec.Code, http.StatusInternalServerError, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "failed to parse config") {
+		t.Errorf("expected a parse error, got %s", rec.Body.String())
+	}
+}
+
 func TestListChainsIncludesStepCounts(t *testing.T) {
 	tmpDir, database, cleanup := setupTestProject(t)
 	defer cleanup()
@@ -1949,3 +2649,842 @@ func TestListChainsIncludesStepCounts(t *testing.T) {
 		t.Errorf("step_count = %d, want %d", response[0].StepCount, 2)
 	}
 }
+
+func TestSaveChainStepsValidatesStepReferences(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	project, _ := database.GetProject()
+	if _, err := database.CreateChain(project.ID, "content-pipeline", ""); err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	server := NewServer(database, tmpDir)
+
+	tests := []struct {
+		name       string
+		steps      string
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name: "earlier step",
+			steps: `[
+				{"step_order": 1, "prompt_name": "summarizer", "input_mapping": {"text": "{{input.text}}"}, "output_key": "summary"},
+				{"step_order": 2, "prompt_name": "summarizer", "input_mapping": {"text": "{{steps.summary.output}}"}, "output_key": "rewrite"}
+			]`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "forward reference",
+			steps: `[
+				{"step_order": 1, "prompt_name": "summarizer", "input_mapping": {"text": "{{steps.rewrite.output}}"}, "output_key": "summary"},
+				{"step_order": 2, "prompt_name": "summarizer", "input_mapping": {"text": "{{input.text}}"}, "output_key": "rewrite"}
+			]`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "produced by step 2",
+		},
+		{
+			name: "self reference",
+			steps: `[
+				{"step_order": 1, "prompt_name": "summarizer", "input_mapping": {"text": "{{steps.summary.output}}"}, "output_key": "summary"}
+			]`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "produced by step 1",
+		},
+		{
+			name: "unknown key",
+			steps: `[
+				{"step_order": 1, "prompt_name": "summarizer", "input_mapping": {"text": "{{input.text}}"}, "output_key": "summary"},
+				{"step_order": 2, "prompt_name": "summarizer", "input_mapping": {"text": "{{steps.sumary.output}}"}, "output_key": "rewrite"}
+			]`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "no step has output_key 'sumary'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/api/chains/content-pipeline/steps", strings.NewReader(`{"steps": `+tt.steps+`}`))
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantError != "" && !strings.Contains(rec.Body.String(), tt.wantError) {
+				t.Errorf("expected error containing %q, got %s", tt.wantError, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestListChainRunsFiltersByStatus(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	project, _ := database.GetProject()
+	chain, err := database.CreateChain(project.ID, "content-pipeline", "pipeline")
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	for _, status := range []string{"completed", "failed", "failed", "completed", "failed"} {
+		if _, err := database.SaveChainRun(chain.ID, status, `{}`, `[]`, "", 0, 0); err != nil {
+			t.Fatalf("failed to save chain run: %v", err)
+		}
+	}
+
+	server := NewServer(database, tmpDir)
+	get := func(query string) (*httptest.ResponseRecorder, []ChainRunResponse) {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/api/chains/content-pipeline/runs"+query, nil)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		var response []ChainRunResponse
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		return rec, response
+	}
+
+	rec, runs := get("?status=failed")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 failed runs, got %d", len(runs))
+	}
+	for _, run := range runs {
+		if run.Status != "failed" {
+			t.Errorf("expected only failed runs, got %q", run.Status)
+		}
+	}
+
+	if _, runs := get("?status=failed&limit=2&offset=2"); len(runs) != 1 {
+		t.Errorf("expected 1 failed run on the second page, got %d", len(runs))
+	}
+	if _, runs := get(""); len(runs) != 5 {
+		t.Errorf("expected all 5 runs without filters, got %d", len(runs))
+	}
+	if rec, _ := get("?limit=-1"); rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d for a negative limit, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+// usageStubProvider answers every completion with fixed token counts and cost
+type usageStubProvider struct {
+	calls int
+}
+
+func (p *usageStubProvider) Name() string                    { return "openai" }
+func (p *usageStubProvider) Models() []string                { return []string{"gpt-4o-mini"} }
+func (p *usageStubProvider) SupportsModel(model string) bool { return model == "gpt-4o-mini" }
+
+func (p *usageStubProvider) Complete(ctx context.Context, req benchmark.CompletionRequest) (*benchmark.CompletionResponse, error) {
+	p.calls++
+	return &benchmark.CompletionResponse{
+		Content:      fmt.Sprintf("output %d", p.calls),
+		Model:        req.Model,
+		PromptTokens: 10 * p.calls,
+		OutputTokens: 5,
+		Cost:         0.25,
+	}, nil
+}
+
+func TestRunChainTotalsTokensAndCost(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+	useProviders(t, &usageStubProvider{})
+
+	project, _ := database.GetProject()
+	prompt, _ := database.GetPromptByName("summarizer")
+	database.CreateVersion(prompt.ID, "1.0.0", "Summarize: {{.text}}", "[]", "{}", "Initial", "user", nil)
+	chain, _ := database.CreateChain(project.ID, "content-pipeline", "")
+	database.CreateChainStep(chain.ID, 1, "summarizer", `{"text":"{{input.text}}"}`, "summary")
+	database.CreateChainStep(chain.ID, 2, "summarizer", `{"text":"{{steps.summary.output}}"}`, "rewrite")
+
+	server := NewServer(database, tmpDir)
+
+	req := httptest.NewRequest("POST", "/api/chains/content-pipeline/run", strings.NewReader(`{"inputs":{"text":"hello"},"model":"gpt-4o-mini"}`))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var run ChainRunResponse
+	if err := json.NewDecoder(rec.Body).Decode(&run); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	// Steps use 10+5 and 20+5 tokens at 0.25 each
+	if run.TotalTokens != 40 || run.TotalCost != 0.5 {
+		t.Errorf("totals = %d tokens, %v cost, want 40 and 0.5", run.TotalTokens, run.TotalCost)
+	}
+	var steps []ChainStepRunResult
+	if err := json.Unmarshal(run.Results, &steps); err != nil {
+		t.Fatalf("failed to decode step results: %v", err)
+	}
+	if len(steps) != 2 || steps[1].PromptTokens != 20 || steps[1].OutputTokens != 5 || steps[1].Cost != 0.25 {
+		t.Errorf("unexpected step usage %+v", steps)
+	}
+
+	// The stored run keeps the same totals
+	req = httptest.NewRequest("GET", "/api/chains/content-pipeline/runs", nil)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	var runs []ChainRunResponse
+	if err := json.NewDecoder(rec.Body).Decode(&runs); err != nil {
+		t.Fatalf("failed to decode runs: %v", err)
+	}
+	if len(runs) != 1 || runs[0].TotalTokens != 40 || runs[0].TotalCost != 0.5 {
+		t.Errorf("unexpected stored run totals %+v", runs)
+	}
+}
+
+// renameSummarizer renames the summarizer prompt to digest, leaving
+// summarizer as an alias as the rename command does
+func renameSummarizer(t *testing.T, database *db.DB) *db.Prompt {
+	t.Helper()
+	prompt, _ := database.GetPromptByName("summarizer")
+	renamed, err := database.UpdatePrompt(prompt.ID, "digest", prompt.Description)
+	if err != nil {
+		t.Fatalf("UpdatePrompt failed: %v", err)
+	}
+	if err := database.RecordRename(prompt.ID, "summarizer", "digest"); err != nil {
+		t.Fatalf("RecordRename failed: %v", err)
+	}
+	return renamed
+}
+
+func TestRunChainRenamedPrompt(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+	useProviders(t, &usageStubProvider{})
+
+	project, _ := database.GetProject()
+	prompt, _ := database.GetPromptByName("summarizer")
+	database.CreateVersion(prompt.ID, "1.0.0", "Summarize: {{.text}}", "[]", "{}", "Initial", "user", nil)
+	database.CreateChain(project.ID, "content-pipeline", "")
+	renameSummarizer(t, database)
+
+	server := NewServer(database, tmpDir)
+
+	// Steps saved with the old name, pinned or not, still resolve
+	req := httptest.NewRequest("PUT", "/api/chains/content-pipeline/steps", strings.NewReader(`{"steps": [
+		{"step_order": 1, "prompt_name": "summarizer", "input_mapping": {"text": "{{input.text}}"}, "output_key": "summary", "version": "1.0.0"},
+		{"step_order": 2, "prompt_name": "summarizer", "input_mapping": {"text": "{{steps.summary.output}}"}, "output_key": "rewrite"}
+	]}`))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("save status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	req = httptest.NewRequest("POST", "/api/chains/content-pipeline/run", strings.NewReader(`{"inputs":{"text":"hello"},"model":"gpt-4o-mini"}`))
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("run status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var run ChainRunResponse
+	if err := json.NewDecoder(rec.Body).Decode(&run); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	var steps []ChainStepRunResult
+	if err := json.Unmarshal(run.Results, &steps); err != nil {
+		t.Fatalf("failed to decode step results: %v", err)
+	}
+	if len(steps) != 2 || !strings.HasPrefix(steps[0].RenderedPrompt, "Summarize: hello") {
+		t.Errorf("expected both steps to run the renamed prompt, got %+v", steps)
+	}
+}
+
+func TestRunChainUsesPinnedVersion(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+	useProviders(t, &usageStubProvider{})
+
+	project, _ := database.GetProject()
+	prompt, _ := database.GetPromptByName("summarizer")
+	v1, _ := database.CreateVersion(prompt.ID, "1.0.0", "Old: {{.text}}", "[]", "{}", "Initial", "user", nil)
+	database.CreateVersion(prompt.ID, "1.0.1", "New: {{.text}}", "[]", "{}", "Reword", "user", &v1.ID)
+	database.CreateChain(project.ID, "content-pipeline", "")
+
+	server := NewServer(database, tmpDir)
+
+	// A pin to a version that does not exist is rejected when saving
+	req := httptest.NewRequest("PUT", "/api/chains/content-pipeline/steps", strings.NewReader(`{"steps": [
+		{"step_order": 1, "prompt_name": "summarizer", "input_mapping": {"text": "{{input.text}}"}, "output_key": "summary", "version": "9.9.9"}
+	]}`))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d for a missing pinned version", rec.Code, http.StatusBadRequest)
+	}
+
+	req = httptest.NewRequest("PUT", "/api/chains/content-pipeline/steps", strings.NewReader(`{"steps": [
+		{"step_order": 1, "prompt_name": "summarizer", "input_mapping": {"text": "{{input.text}}"}, "output_key": "summary", "version": "1.0.0"},
+		{"step_order": 2, "prompt_name": "summarizer", "input_mapping": {"text": "{{steps.summary.output}}"}, "output_key": "rewrite"}
+	]}`))
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("save status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var saved []ChainStepResponse
+	if err := json.NewDecoder(rec.Body).Decode(&saved); err != nil {
+		t.Fatalf("failed to decode steps: %v", err)
+	}
+	if len(saved) != 2 || saved[0].Version != "1.0.0" || saved[1].Version != "" {
+		t.Fatalf("unexpected saved steps %+v", saved)
+	}
+
+	req = httptest.NewRequest("POST", "/api/chains/content-pipeline/run", strings.NewReader(`{"inputs":{"text":"hello"},"model":"gpt-4o-mini"}`))
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("run status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var run ChainRunResponse
+	if err := json.NewDecoder(rec.Body).Decode(&run); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	var steps []ChainStepRunResult
+	if err := json.Unmarshal(run.Results, &steps); err != nil {
+		t.Fatalf("failed to decode step results: %v", err)
+	}
+	if len(steps) != 2 {
+		t.Fatalf("expected 2 step results, got %d", len(steps))
+	}
+	if steps[0].Version != "1.0.0" || !strings.HasPrefix(steps[0].RenderedPrompt, "Old: hello") {
+		t.Errorf("pinned step ran %s with %q, want 1.0.0 with the old content", steps[0].Version, steps[0].RenderedPrompt)
+	}
+	if steps[1].Version != "1.0.1" || !strings.HasPrefix(steps[1].RenderedPrompt, "New: ") {
+		t.Errorf("unpinned step ran %s with %q, want the latest version", steps[1].Version, steps[1].RenderedPrompt)
+	}
+}
+
+// barrierStubProvider holds each call for a "Branch" prompt until two are in
+// flight, so a chain whose independent steps run one at a time fails. Other
+// calls reply straight away. Each reply echoes the prompt.
+type barrierStubProvider struct {
+	mu      sync.Mutex
+	waiting int
+	both    chan struct{}
+}
+
+func (p *barrierStubProvider) Name() string                    { return "openai" }
+func (p *barrierStubProvider) Models() []string                { return []string{"gpt-4o-mini"} }
+func (p *barrierStubProvider) SupportsModel(model string) bool { return model == "gpt-4o-mini" }
+
+func (p *barrierStubProvider) Complete(ctx context.Context, req benchmark.CompletionRequest) (*benchmark.CompletionResponse, error) {
+	if strings.HasPrefix(req.Prompt, "Branch") {
+		p.mu.Lock()
+		p.waiting++
+		if p.waiting == 2 {
+			close(p.both)
+		}
+		p.mu.Unlock()
+
+		select {
+		case <-p.both:
+		case <-time.After(2 * time.Second):
+			return nil, fmt.Errorf("independent steps did not run concurrently")
+		}
+	}
+	return &benchmark.CompletionResponse{Content: "<" + req.Prompt + ">", Model: req.Model}, nil
+}
+
+func TestRunChainDiamondRunsBranchesConcurrently(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+	useProviders(t, &barrierStubProvider{both: make(chan struct{})})
+
+	project, _ := database.GetProject()
+	for name, content := range map[string]string{
+		"branch": "Branch {{.side}}",
+		"merge":  "Merge {{.a}} and {{.b}}",
+	} {
+		p, _ := database.CreatePrompt(project.ID, name, "", "prompts/"+name+".prompt")
+		database.CreateVersion(p.ID, "1.0.0", content, "[]", "{}", "Initial", "user", nil)
+	}
+	database.CreateChain(project.ID, "diamond", "")
+
+	server := NewServer(database, tmpDir)
+
+	// The merge comes first in step order; its dependencies decide when it runs
+	req := httptest.NewRequest("PUT", "/api/chains/diamond/steps", strings.NewReader(`{"steps": [
+		{"step_order": 1, "prompt_name": "merge", "input_mapping": {"a": "{{steps.left.output}}", "b": "{{steps.right.output}}"}, "output_key": "merged", "depends_on": ["left", "right"]},
+		{"step_order": 2, "prompt_name": "branch", "input_mapping": {"side": "{{input.left}}"}, "output_key": "left"},
+		{"step_order": 3, "prompt_name": "branch", "input_mapping": {"side": "{{input.right}}"}, "output_key": "right"}
+	]}`))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("save status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	req = httptest.NewRequest("POST", "/api/chains/diamond/run", strings.NewReader(`{"inputs":{"left":"L","right":"R"},"model":"gpt-4o-mini"}`))
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("run status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var run ChainRunResponse
+	if err := json.NewDecoder(rec.Body).Decode(&run); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	var steps []ChainStepRunResult
+	if err := json.Unmarshal(run.Results, &steps); err != nil {
+		t.Fatalf("failed to decode step results: %v", err)
+	}
+	if len(steps) != 3 || steps[2].OutputKey != "merged" {
+		t.Fatalf("expected the merge to run last, got %+v", steps)
+	}
+	if want := "Merge <Branch L> and <Branch R>"; steps[2].RenderedPrompt != want {
+		t.Errorf("merge prompt = %q, want %q", steps[2].RenderedPrompt, want)
+	}
+	if run.FinalOutput != "<Merge <Branch L> and <Branch R>>" {
+		t.Errorf("final output = %q, want the merge's output", run.FinalOutput)
+	}
+}
+
+func TestRunChainConditionalSteps(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+	useProviders(t, &barrierStubProvider{both: make(chan struct{})})
+
+	project, _ := database.GetProject()
+	p, _ := database.CreatePrompt(project.ID, "echo", "", "prompts/echo.prompt")
+	database.CreateVersion(p.ID, "1.0.0", "{{.text}}", "[]", "{}", "Initial", "user", nil)
+	database.CreateChain(project.ID, "triage", "")
+
+	server := NewServer(database, tmpDir)
+
+	save := func(steps string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", "/api/chains/triage/steps", strings.NewReader(`{"steps": `+steps+`}`))
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, tt := range []struct{ condition, wantError string }{
+		{`steps.label.output = "spam"`, "invalid condition"},
+		{`run(steps.label.output)`, "unknown function"},
+		{`steps.later.output == "spam"`, "no step has output_key 'later'"},
+	} {
+		rec := save(`[
+			{"step_order": 1, "prompt_name": "echo", "input_mapping": {"text": "{{input.text}}"}, "output_key": "label"},
+			{"step_order": 2, "prompt_name": "echo", "input_mapping": {"text": "ok"}, "output_key": "reply", "condition": ` + strconv.Quote(tt.condition) + `}
+		]`)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), tt.wantError) {
+			t.Errorf("condition %s: status = %d, body %s; want 400 mentioning %q", tt.condition, rec.Code, rec.Body.String(), tt.wantError)
+		}
+	}
+
+	rec := save(`[
+		{"step_order": 1, "prompt_name": "echo", "input_mapping": {"text": "{{input.text}}"}, "output_key": "label"},
+		{"step_order": 2, "prompt_name": "echo", "input_mapping": {"text": "Thanks!"}, "output_key": "reply", "condition": "!contains(steps.label.output, \"spam\")"},
+		{"step_order": 3, "prompt_name": "echo", "input_mapping": {"text": "{{steps.label.output}}"}, "output_key": "report", "condition": "contains(steps.label.output, \"spam\")"}
+	]`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("save status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	req := httptest.NewRequest("POST", "/api/chains/triage/run", strings.NewReader(`{"inputs":{"text":"spam"},"model":"gpt-4o-mini"}`))
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("run status = %d, want %d, body: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var run ChainRunResponse
+	if err := json.NewDecoder(rec.Body).Decode(&run); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	var steps []ChainStepRunResult
+	if err := json.Unmarshal(run.Results, &steps); err != nil {
+		t.Fatalf("failed to decode step results: %v", err)
+	}
+	if len(steps) != 3 {
+		t.Fatalf("expected 3 step results, got %+v", steps)
+	}
+	if !steps[1].Skipped || steps[1].Output != "" || steps[1].RenderedPrompt != "" {
+		t.Errorf("expected the reply step to be skipped without running, got %+v", steps[1])
+	}
+	if steps[2].Skipped || steps[2].RenderedPrompt != "<spam>" {
+		t.Errorf("expected the report step to run, got %+v", steps[2])
+	}
+	if run.FinalOutput != "<<spam>>" {
+		t.Errorf("final output = %q, want the report's output", run.FinalOutput)
+	}
+}
+
+func TestSaveChainStepsRejectsDependencyCycle(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	project, _ := database.GetProject()
+	database.CreateChain(project.ID, "loop", "")
+	server := NewServer(database, tmpDir)
+
+	req := httptest.NewRequest("PUT", "/api/chains/loop/steps", strings.NewReader(`{"steps": [
+		{"step_order": 1, "prompt_name": "summarizer", "input_mapping": {"text": "{{steps.b.output}}"}, "output_key": "a"},
+		{"step_order": 2, "prompt_name": "summarizer", "input_mapping": {}, "output_key": "b", "depends_on": ["a"]}
+	]}`))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if !strings.Contains(rec.Body.String(), "dependency cycle") {
+		t.Errorf("expected a dependency cycle error, got %s", rec.Body.String())
+	}
+}
+
+// streamingStubProvider sends its chunks one at a time, waiting for release
+// before finishing, so a test can observe events arriving mid-completion
+type streamingStubProvider struct {
+	chunks  []string
+	release chan struct{}
+}
+
+func (p *streamingStubProvider) Name() string                    { return "openai" }
+func (p *streamingStubProvider) Models() []string                { return []string{"gpt-4o-mini"} }
+func (p *streamingStubProvider) SupportsModel(model string) bool { return model == "gpt-4o-mini" }
+
+func (p *streamingStubProvider) Complete(ctx context.Context, req benchmark.CompletionRequest) (*benchmark.CompletionResponse, error) {
+	return nil, fmt.Errorf("Complete should not be called when streaming")
+}
+
+func (p *streamingStubProvider) CompleteStream(ctx context.Context, req benchmark.CompletionRequest, onChunk func(string) error) (*benchmark.CompletionResponse, error) {
+	for _, chunk := range p.chunks {
+		if err := onChunk(chunk); err != nil {
+			return nil, err
+		}
+	}
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &benchmark.CompletionResponse{
+		Content:      strings.Join(p.chunks, ""),
+		Model:        req.Model,
+		PromptTokens: 7,
+		OutputTokens: len(p.chunks),
+		Cost:         0.0001,
+	}, nil
+}
+
+func useProviders(t *testing.T, providers ...benchmark.Provider) {
+	t.Helper()
+	original := newProviderRegistry
+	newProviderRegistry = func() *benchmark.ProviderRegistry {
+		registry := benchmark.NewProviderRegistry()
+		for _, p := range providers {
+			registry.Register(p)
+		}
+		return registry
+	}
+	t.Cleanup(func() { newProviderRegistry = original })
+}
+
+type sseEvent struct {
+	name string
+	data string
+}
+
+// readSSEEvent reads the next event from an SSE stream
+func readSSEEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	var ev sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended before event was complete: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			return ev
+		case strings.HasPrefix(line, "event: "):
+			ev.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			ev.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestPlaygroundStream(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	provider := &streamingStubProvider{chunks: []string{"Hello", ", ", "world"}, release: make(chan struct{})}
+	useProviders(t, provider)
+
+	api := NewServer(database, tmpDir)
+	api.SetLogger(log.New(io.Discard, "", 0)) // flushes must pass through the logging wrapper
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	body := `{"content": "Say hello to {{.name}}", "model": "gpt-4o-mini", "variables": {"name": "world"}}`
+	resp, err := http.Post(server.URL+"/api/playground/stream", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	// Every chunk arrives while the provider is still blocked, before the
+	// completion has finished
+	reader := bufio.NewReader(resp.Body)
+	for _, want := range provider.chunks {
+		ev := readSSEEvent(t, reader)
+		var chunk map[string]string
+		if err := json.Unmarshal([]byte(ev.data), &chunk); err != nil {
+			t.Fatalf("invalid chunk data %q: %v", ev.data, err)
+		}
+		if ev.name != "" || chunk["content"] != want {
+			t.Errorf("expected chunk %q, got event %q data %q", want, ev.name, ev.data)
+		}
+	}
+	close(provider.release)
+
+	done := readSSEEvent(t, reader)
+	if done.name != "done" {
+		t.Fatalf("expected done event, got %q", done.name)
+	}
+	var summary PlaygroundRunResponse
+	if err := json.Unmarshal([]byte(done.data), &summary); err != nil {
+		t.Fatalf("invalid summary %q: %v", done.data, err)
+	}
+	if summary.Output != "Hello, world" || summary.RenderedPrompt != "Say hello to world" {
+		t.Errorf("unexpected summary output %+v", summary)
+	}
+	if summary.PromptTokens != 7 || summary.OutputTokens != 3 || summary.Cost != 0.0001 {
+		t.Errorf("unexpected summary usage %+v", summary)
+	}
+}
+
+func TestPlaygroundStreamFallback(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+
+	// A provider without streaming support sends its output as one chunk
+	useProviders(t, &nonStreamingStubProvider{})
+
+	server := httptest.NewServer(NewServer(database, tmpDir))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/playground/stream", "application/json",
+		strings.NewReader(`{"content": "Hi", "model": "claude-haiku"}`))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	if ev := readSSEEvent(t, reader); ev.data != `{"content":"Full reply"}` {
+		t.Errorf("expected the whole reply as one chunk, got %q", ev.data)
+	}
+	if ev := readSSEEvent(t, reader); ev.name != "done" {
+		t.Errorf("expected done event, got %q", ev.name)
+	}
+}
+
+func TestRunChainStream(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+	useProviders(t, &usageStubProvider{})
+
+	project, _ := database.GetProject()
+	prompt, _ := database.GetPromptByName("summarizer")
+	database.CreateVersion(prompt.ID, "1.0.0", "Summarize: {{.text}}", "[]", "{}", "Initial", "user", nil)
+	chain, _ := database.CreateChain(project.ID, "content-pipeline", "")
+	database.CreateChainStep(chain.ID, 1, "summarizer", `{"text":"{{input.text}}"}`, "summary")
+	database.CreateChainStep(chain.ID, 2, "summarizer", `{"text":"{{steps.summary.output}}"}`, "rewrite")
+	broken, _ := database.CreateChain(project.ID, "broken-pipeline", "")
+	database.CreateChainStep(broken.ID, 1, "summarizer", `{"text":"{{input.text}}"}`, "summary")
+	database.CreateChainStep(broken.ID, 2, "missing", `{}`, "rewrite")
+
+	server := httptest.NewServer(NewServer(database, tmpDir))
+	defer server.Close()
+
+	stream := func(chainName string) *bufio.Reader {
+		t.Helper()
+		resp, err := http.Post(server.URL+"/api/chains/"+chainName+"/run/stream", "application/json",
+			strings.NewReader(`{"inputs":{"text":"hello"},"model":"gpt-4o-mini"}`))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want 200", resp.StatusCode)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("Content-Type = %q, want text/event-stream", ct)
+		}
+		return bufio.NewReader(resp.Body)
+	}
+
+	reader := stream("content-pipeline")
+	for i, wantKey := range []string{"summary", "rewrite"} {
+		ev := readSSEEvent(t, reader)
+		var step ChainStepRunResult
+		if err := json.Unmarshal([]byte(ev.data), &step); err != nil {
+			t.Fatalf("invalid step data %q: %v", ev.data, err)
+		}
+		if ev.name != "step" || step.StepOrder != i+1 || step.OutputKey != wantKey {
+			t.Errorf("event %d = %q %+v, want step %d (%s)", i, ev.name, step, i+1, wantKey)
+		}
+	}
+	done := readSSEEvent(t, reader)
+	if done.name != "done" {
+		t.Fatalf("expected done event, got %q", done.name)
+	}
+	var run ChainRunResponse
+	if err := json.Unmarshal([]byte(done.data), &run); err != nil {
+		t.Fatalf("invalid run %q: %v", done.data, err)
+	}
+	if run.Status != "completed" || run.FinalOutput != "output 2" || run.TotalTokens != 40 {
+		t.Errorf("unexpected run %+v", run)
+	}
+	if _, err := reader.ReadString('\n'); err != io.EOF {
+		t.Errorf("expected the stream to end after the done event")
+	}
+
+	// A failing step ends the stream with an error event
+	reader = stream("broken-pipeline")
+	if ev := readSSEEvent(t, reader); ev.name != "step" {
+		t.Errorf("expected the first step to finish, got %q", ev.name)
+	}
+	ev := readSSEEvent(t, reader)
+	if ev.name != "error" || !strings.Contains(ev.data, "prompt 'missing' not found") {
+		t.Errorf("expected an error event for step 2, got %q %q", ev.name, ev.data)
+	}
+}
+
+func TestPlaygroundRunResolvesTag(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+	useProviders(t, &nonStreamingStubProvider{})
+
+	prompt, _ := database.GetPromptByName("summarizer")
+	v1, _ := database.CreateVersion(prompt.ID, "1.0.0", "tagged content", "[]", "{}", "Initial", "user", nil)
+	database.CreateVersion(prompt.ID, "1.0.1", "latest content", "[]", "{}", "Update", "user", &v1.ID)
+	if _, err := database.CreateTag(prompt.ID, v1.ID, "prod"); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+
+	server := NewServer(database, tmpDir)
+
+	tests := []struct {
+		version    string
+		wantStatus int
+		wantPrompt string
+	}{
+		{"prod", http.StatusOK, "tagged content"},
+		{"1.0.1", http.StatusOK, "latest content"},
+		{"staging", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			body := fmt.Sprintf(`{"prompt_name": "summarizer", "version": %q, "model": "claude-haiku"}`, tt.version)
+			req := httptest.NewRequest("POST", "/api/playground/run", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp PlaygroundRunResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to parse response: %v", err)
+			}
+			if resp.RenderedPrompt != tt.wantPrompt {
+				t.Errorf("rendered prompt = %q, want %q", resp.RenderedPrompt, tt.wantPrompt)
+			}
+		})
+	}
+}
+
+func TestPlaygroundRunModelAlias(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+	useProviders(t, &nonStreamingStubProvider{})
+
+	configPath := filepath.Join(tmpDir, ".promptsmith", "config.yaml")
+	if err := os.WriteFile(configPath, []byte("model_aliases:\n  cheap: claude-haiku\n"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	server := NewServer(database, tmpDir)
+	req := httptest.NewRequest("POST", "/api/playground/run", strings.NewReader(`{"content": "Hi", "model": "cheap"}`))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp PlaygroundRunResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if resp.Model != "claude-haiku" {
+		t.Errorf("expected alias to resolve to claude-haiku, got %q", resp.Model)
+	}
+}
+
+type nonStreamingStubProvider struct{}
+
+func (p *nonStreamingStubProvider) Name() string                    { return "anthropic" }
+func (p *nonStreamingStubProvider) Models() []string                { return []string{"claude-haiku"} }
+func (p *nonStreamingStubProvider) SupportsModel(model string) bool { return model == "claude-haiku" }
+
+func (p *nonStreamingStubProvider) Complete(ctx context.Context, req benchmark.CompletionRequest) (*benchmark.CompletionResponse, error) {
+	return &benchmark.CompletionResponse{Content: "Full reply", Model: req.Model}, nil
+}
+
+func TestPlaygroundStreamValidation(t *testing.T) {
+	tmpDir, database, cleanup := setupTestProject(t)
+	defer cleanup()
+	useProviders(t)
+
+	server := NewServer(database, tmpDir)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"missing model", `{"content": "Hi"}`, http.StatusBadRequest},
+		{"missing content", `{"model": "gpt-4o-mini"}`, http.StatusBadRequest},
+		{"no provider", `{"content": "Hi", "model": "gpt-4o-mini"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/playground/stream", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("expected a JSON error before streaming starts, got %q", ct)
+			}
+		})
+	}
+}
@@ -147,7 +147,7 @@ func (s *Server) runTest(w http.ResponseWriter, r *http.Request, testName string
 
 	// Run the test suite
 	runner := testing.NewRunner(s.db, nil) // Using mock executor
-	result, err := runner.Run(suite)
+	result, err := runner.Run(r.Context(), suite)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -158,7 +158,7 @@ func (s *Server) runTest(w http.ResponseWriter, r *http.Request, testName string
 	if result.Failed > 0 {
 		status = "failed"
 	}
-	prompt, err := s.db.GetPromptByName(suite.Prompt)
+	prompt, _, err := s.db.ResolvePromptName(suite.Prompt)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -203,7 +203,7 @@ func (s *Server) createTestSuite(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check prompt exists
-	prompt, err := s.db.GetPromptByName(req.Prompt)
+	prompt, _, err := s.db.ResolvePromptName(req.Prompt)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
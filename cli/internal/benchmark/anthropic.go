@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/promptsmith/cli/internal/logging"
 )
 
 // AnthropicProvider implements the Provider interface for Anthropic
@@ -62,7 +64,8 @@ func NewAnthropicProvider() (*AnthropicProvider, error) {
 		apiKey:  apiKey,
 		baseURL: "https://api.anthropic.com/v1",
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Transport: logging.Transport(nil),
+			Timeout:   60 * time.Second,
 		},
 	}, nil
 }
@@ -150,11 +153,14 @@ func (p *AnthropicProvider) Complete(ctx context.Context, req CompletionRequest)
 
 	var anthropicResp anthropicResponse
 	if err := json.Unmarshal(respBody, &anthropicResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, newAPIError(resp, "")
+		}
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	if anthropicResp.Error != nil {
-		return nil, fmt.Errorf("API error: %s", anthropicResp.Error.Message)
+		return nil, newAPIError(resp, anthropicResp.Error.Message)
 	}
 
 	if len(anthropicResp.Content) == 0 {
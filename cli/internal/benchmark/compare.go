@@ -0,0 +1,113 @@
+package benchmark
+
+import (
+	"sort"
+	"strings"
+)
+
+// Model statuses in a comparison
+const (
+	ModelCompared = "compared"
+	ModelAdded    = "added"   // only in the later run
+	ModelRemoved  = "removed" // only in the earlier run
+)
+
+// MetricDelta is how one per-model aggregate changed between two runs
+type MetricDelta struct {
+	Metric    string  `json:"metric"`
+	Before    float64 `json:"before"`
+	After     float64 `json:"after"`
+	ChangePct float64 `json:"change_pct"`
+	Regressed bool    `json:"regressed"`
+}
+
+// ModelComparison is how a model fared in a later run compared to an
+// earlier one
+type ModelComparison struct {
+	Model         string        `json:"model"`
+	Status        string        `json:"status"`
+	Metrics       []MetricDelta `json:"metrics,omitempty"`
+	OutputChanged bool          `json:"output_changed,omitempty"`
+	Regressed     bool          `json:"regressed"`
+}
+
+// comparedMetrics are the aggregates a comparison checks. Lower is better
+// for all of them.
+var comparedMetrics = []struct {
+	name  string
+	value func(ModelResult) float64
+}{
+	{"latency_p50_ms", func(m ModelResult) float64 { return m.LatencyP50Ms }},
+	{"latency_p95_ms", func(m ModelResult) float64 { return m.LatencyP95Ms }},
+	{"cost_per_request", func(m ModelResult) float64 { return m.CostPerRequest }},
+	{"total_tokens_avg", func(m ModelResult) float64 { return m.TotalTokensAvg }},
+	{"error_rate", func(m ModelResult) float64 { return m.ErrorRate }},
+}
+
+// CompareResults diffs the per-model aggregates of two runs of a benchmark.
+// A temperature sweep's models are compared per temperature.
+// A metric regressed when it grew by more than thresholdPct percent; a
+// metric growing from zero always counts, since no percentage describes it.
+// Models in only one run are reported as added or removed, after the
+// models both runs share, which keep the later run's order.
+func CompareResults(before, after *BenchmarkResult, thresholdPct float64) []ModelComparison {
+	earlier := make(map[string]ModelResult, len(before.Models))
+	for _, m := range before.Models {
+		earlier[m.Label()] = m
+	}
+	later := make(map[string]bool, len(after.Models))
+
+	var comparisons []ModelComparison
+	var added []ModelComparison
+	for _, m := range after.Models {
+		later[m.Label()] = true
+		prev, ok := earlier[m.Label()]
+		if !ok {
+			added = append(added, ModelComparison{Model: m.Label(), Status: ModelAdded})
+			continue
+		}
+
+		c := ModelComparison{
+			Model:         m.Label(),
+			Status:        ModelCompared,
+			OutputChanged: runOutputs(before, m) != runOutputs(after, m),
+		}
+		for _, metric := range comparedMetrics {
+			d := MetricDelta{
+				Metric: metric.name,
+				Before: metric.value(prev),
+				After:  metric.value(m),
+			}
+			if d.Before != 0 {
+				d.ChangePct = (d.After - d.Before) / d.Before * 100
+				d.Regressed = d.ChangePct > thresholdPct
+			} else {
+				d.Regressed = d.After > 0
+			}
+			c.Regressed = c.Regressed || d.Regressed
+			c.Metrics = append(c.Metrics, d)
+		}
+		comparisons = append(comparisons, c)
+	}
+	comparisons = append(comparisons, added...)
+
+	for _, m := range before.Models {
+		if !later[m.Label()] {
+			comparisons = append(comparisons, ModelComparison{Model: m.Label(), Status: ModelRemoved})
+		}
+	}
+	return comparisons
+}
+
+// runOutputs joins a model's successful outputs in a fixed order, so two
+// runs that produced the same completions compare equal
+func runOutputs(result *BenchmarkResult, model ModelResult) string {
+	var outputs []string
+	for _, r := range result.Runs {
+		if model.Includes(r) && r.Error == "" {
+			outputs = append(outputs, r.Output)
+		}
+	}
+	sort.Strings(outputs)
+	return strings.Join(outputs, "\x00")
+}
@@ -0,0 +1,131 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const compareBeforeJSON = `{
+	"suite_name": "summarizer-benchmark",
+	"models": [
+		{"model": "gpt-4o", "latency_p50_ms": 200, "latency_p95_ms": 300, "cost_per_request": 0.0050, "total_tokens_avg": 150, "error_rate": 0},
+		{"model": "claude-sonnet", "latency_p50_ms": 400, "latency_p95_ms": 600, "cost_per_request": 0.0040, "total_tokens_avg": 120, "error_rate": 0.1},
+		{"model": "gemini-pro", "latency_p50_ms": 300, "latency_p95_ms": 500, "cost_per_request": 0.0010, "total_tokens_avg": 100, "error_rate": 0}
+	],
+	"runs": [
+		{"model": "gpt-4o", "output": "A short summary."},
+		{"model": "claude-sonnet", "output": "Summary one."}
+	]
+}`
+
+const compareAfterJSON = `{
+	"suite_name": "summarizer-benchmark",
+	"models": [
+		{"model": "gpt-4o", "latency_p50_ms": 230, "latency_p95_ms": 310, "cost_per_request": 0.0050, "total_tokens_avg": 180, "error_rate": 0.2},
+		{"model": "claude-sonnet", "latency_p50_ms": 360, "latency_p95_ms": 640, "cost_per_request": 0.0040, "total_tokens_avg": 120, "error_rate": 0.1},
+		{"model": "llama3", "latency_p50_ms": 900, "latency_p95_ms": 1200, "total_tokens_avg": 110}
+	],
+	"runs": [
+		{"model": "gpt-4o", "output": "A short summary."},
+		{"model": "claude-sonnet", "output": "Summary two."}
+	]
+}`
+
+func compareFixtures(t *testing.T) (*BenchmarkResult, *BenchmarkResult) {
+	t.Helper()
+	var before, after BenchmarkResult
+	if err := json.Unmarshal([]byte(compareBeforeJSON), &before); err != nil {
+		t.Fatalf("failed to parse before run: %v", err)
+	}
+	if err := json.Unmarshal([]byte(compareAfterJSON), &after); err != nil {
+		t.Fatalf("failed to parse after run: %v", err)
+	}
+	return &before, &after
+}
+
+func regressedMetrics(c ModelComparison) map[string]bool {
+	regressed := map[string]bool{}
+	for _, d := range c.Metrics {
+		if d.Regressed {
+			regressed[d.Metric] = true
+		}
+	}
+	return regressed
+}
+
+func TestCompareResults(t *testing.T) {
+	before, after := compareFixtures(t)
+
+	comparisons := CompareResults(before, after, 10)
+	if len(comparisons) != 4 {
+		t.Fatalf("expected 4 models, got %d: %+v", len(comparisons), comparisons)
+	}
+
+	// gpt-4o: p50 +15% and tokens +20% pass 10%, p95 +3.3% does not, and
+	// the error rate grew from zero
+	gpt := comparisons[0]
+	if gpt.Model != "gpt-4o" || gpt.Status != ModelCompared || !gpt.Regressed {
+		t.Fatalf("expected gpt-4o to regress, got %+v", gpt)
+	}
+	want := map[string]bool{"latency_p50_ms": true, "total_tokens_avg": true, "error_rate": true}
+	if got := regressedMetrics(gpt); len(got) != len(want) || !got["latency_p50_ms"] || !got["total_tokens_avg"] || !got["error_rate"] {
+		t.Errorf("expected regressed metrics %v, got %v", want, got)
+	}
+	if gpt.OutputChanged {
+		t.Error("expected gpt-4o output unchanged")
+	}
+	for _, d := range gpt.Metrics {
+		if d.Metric == "latency_p50_ms" && (d.Before != 200 || d.After != 230 || d.ChangePct < 14.9 || d.ChangePct > 15.1) {
+			t.Errorf("unexpected p50 delta: %+v", d)
+		}
+	}
+
+	// claude-sonnet: faster p50, p95 +6.7% stays under 10%
+	claude := comparisons[1]
+	if claude.Model != "claude-sonnet" || claude.Regressed {
+		t.Errorf("expected claude-sonnet not to regress, got %+v", claude)
+	}
+	if !claude.OutputChanged {
+		t.Error("expected claude-sonnet output changed")
+	}
+
+	if comparisons[2].Model != "llama3" || comparisons[2].Status != ModelAdded {
+		t.Errorf("expected llama3 added, got %+v", comparisons[2])
+	}
+	if comparisons[3].Model != "gemini-pro" || comparisons[3].Status != ModelRemoved {
+		t.Errorf("expected gemini-pro removed, got %+v", comparisons[3])
+	}
+}
+
+func TestCompareResultsThreshold(t *testing.T) {
+	before, after := compareFixtures(t)
+
+	tests := []struct {
+		threshold float64
+		gpt       map[string]bool
+		claude    bool
+	}{
+		{threshold: 0, gpt: map[string]bool{"latency_p50_ms": true, "latency_p95_ms": true, "total_tokens_avg": true, "error_rate": true}, claude: true},
+		{threshold: 5, gpt: map[string]bool{"latency_p50_ms": true, "total_tokens_avg": true, "error_rate": true}, claude: true},
+		{threshold: 18, gpt: map[string]bool{"total_tokens_avg": true, "error_rate": true}, claude: false},
+		// Growth from zero has no percentage and is always flagged
+		{threshold: 1000, gpt: map[string]bool{"error_rate": true}, claude: false},
+	}
+
+	for _, tt := range tests {
+		comparisons := CompareResults(before, after, tt.threshold)
+		got := regressedMetrics(comparisons[0])
+		if len(got) != len(tt.gpt) {
+			t.Errorf("threshold %g: expected gpt-4o regressions %v, got %v", tt.threshold, tt.gpt, got)
+			continue
+		}
+		for metric := range tt.gpt {
+			if !got[metric] {
+				t.Errorf("threshold %g: expected %s to regress, got %v", tt.threshold, metric, got)
+			}
+		}
+		if comparisons[1].Regressed != tt.claude {
+			t.Errorf("threshold %g: expected claude-sonnet regressed=%v", tt.threshold, tt.claude)
+		}
+	}
+}
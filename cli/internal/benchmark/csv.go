@@ -0,0 +1,72 @@
+package benchmark
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+)
+
+// csvPreviewRunes is how much of each completion a CSV row keeps
+const csvPreviewRunes = 100
+
+// CSVHeader is the header row of MarshalCSV
+var CSVHeader = []string{
+	"suite", "started_at", "model", "temperature", "run_index", "latency_ms", "prompt_tokens", "output_tokens", "cost", "output_preview", "error",
+}
+
+// MarshalCSV flattens benchmark results into one CSV row per run, for
+// spreadsheets. Rows of different runs of a suite are told apart by
+// started_at. temperature is empty unless the suite sweeps temperatures.
+// run_index counts the runs of each model and temperature from 1, matching
+// the files written to Runner.OutputDir, and output_preview keeps the start of
+// the completion.
+func MarshalCSV(results []*BenchmarkResult) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(CSVHeader); err != nil {
+		return nil, fmt.Errorf("failed to encode CSV: %w", err)
+	}
+
+	for _, result := range results {
+		runIndex := map[string]int{}
+		for _, run := range result.Runs {
+			temperature := ""
+			if run.Temperature != nil {
+				temperature = strconv.FormatFloat(*run.Temperature, 'f', -1, 64)
+			}
+			group := run.Model + "\x00" + temperature
+			runIndex[group]++
+			row := []string{
+				result.SuiteName,
+				result.StartedAt,
+				run.Model,
+				temperature,
+				strconv.Itoa(runIndex[group]),
+				strconv.FormatInt(run.LatencyMs, 10),
+				strconv.Itoa(run.PromptTokens),
+				strconv.Itoa(run.OutputTokens),
+				strconv.FormatFloat(run.Cost, 'f', -1, 64),
+				outputPreview(run.Output),
+				run.Error,
+			}
+			if err := w.Write(row); err != nil {
+				return nil, fmt.Errorf("failed to encode CSV: %w", err)
+			}
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to encode CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func outputPreview(output string) string {
+	runes := []rune(output)
+	if len(runes) <= csvPreviewRunes {
+		return output
+	}
+	return string(runes[:csvPreviewRunes]) + "…"
+}
@@ -0,0 +1,58 @@
+package benchmark
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshalCSV(t *testing.T) {
+	results := []*BenchmarkResult{
+		{
+			SuiteName: "summarizer-benchmark",
+			StartedAt: "2026-01-02T03:04:05Z",
+			Runs: []RunResult{
+				{Model: "gpt-4o", LatencyMs: 210, PromptTokens: 40, OutputTokens: 12, Cost: 0.0025, Output: "Short, sweet\nand on two lines"},
+				{Model: "claude-sonnet", LatencyMs: 340, PromptTokens: 42, OutputTokens: 15, Cost: 0.003, Output: `A "quoted" summary`},
+				{Model: "gpt-4o", Error: "rate limited"},
+				{Model: "gpt-4o", LatencyMs: 190, Output: strings.Repeat("ab", 80)},
+			},
+		},
+	}
+
+	data, err := MarshalCSV(results)
+	if err != nil {
+		t.Fatalf("MarshalCSV failed: %v", err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v\n%s", err, data)
+	}
+	if len(records) != 5 {
+		t.Fatalf("expected header and 4 rows, got %d records", len(records))
+	}
+	if !reflect.DeepEqual(records[0], CSVHeader) {
+		t.Errorf("header = %v, want %v", records[0], CSVHeader)
+	}
+
+	want := []string{"summarizer-benchmark", "2026-01-02T03:04:05Z", "gpt-4o", "", "1", "210", "40", "12", "0.0025", "Short, sweet\nand on two lines", ""}
+	if !reflect.DeepEqual(records[1], want) {
+		t.Errorf("first row = %q, want %q", records[1], want)
+	}
+	if records[2][2] != "claude-sonnet" || records[2][4] != "1" || records[2][9] != `A "quoted" summary` {
+		t.Errorf("unexpected second row: %q", records[2])
+	}
+
+	// Run indexes count per model, and a failed run keeps its error
+	if records[3][4] != "2" || records[3][10] != "rate limited" {
+		t.Errorf("unexpected failed run row: %q", records[3])
+	}
+
+	preview := records[4][9]
+	if records[4][4] != "3" || !strings.HasSuffix(preview, "…") || len([]rune(preview)) != csvPreviewRunes+1 {
+		t.Errorf("expected a truncated preview for the third gpt-4o run, got %q", records[4])
+	}
+}
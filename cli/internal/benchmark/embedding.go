@@ -0,0 +1,141 @@
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/promptsmith/cli/internal/logging"
+)
+
+// DefaultEmbeddingModel is the OpenAI model used for embeddings
+const DefaultEmbeddingModel = "text-embedding-3-small"
+
+// EmbeddingProvider turns text into vectors for semantic comparison
+type EmbeddingProvider interface {
+	// Name returns the provider name (e.g., "openai")
+	Name() string
+	// Embed returns one vector per text, in the same order
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// OpenAIEmbeddingProvider implements EmbeddingProvider for OpenAI
+type OpenAIEmbeddingProvider struct {
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error,omitempty"`
+}
+
+// NewOpenAIEmbeddingProvider creates a new OpenAI embedding provider
+func NewOpenAIEmbeddingProvider() (*OpenAIEmbeddingProvider, error) {
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("OPENAI_API_KEY environment variable not set")
+	}
+
+	return &OpenAIEmbeddingProvider{
+		apiKey:  apiKey,
+		baseURL: "https://api.openai.com/v1",
+		model:   DefaultEmbeddingModel,
+		client: &http.Client{
+			Transport: logging.Transport(nil),
+			Timeout:   30 * time.Second,
+		},
+	}, nil
+}
+
+// Name returns the provider name
+func (p *OpenAIEmbeddingProvider) Name() string {
+	return "openai"
+}
+
+// Embed sends an embeddings request to OpenAI
+func (p *OpenAIEmbeddingProvider) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	body, err := json.Marshal(openAIEmbeddingRequest{Model: p.model, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var embResp openAIEmbeddingResponse
+	if err := json.Unmarshal(respBody, &embResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if embResp.Error != nil {
+		return nil, fmt.Errorf("API error: %s", embResp.Error.Message)
+	}
+
+	if len(embResp.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(embResp.Data))
+	}
+
+	vectors := make([][]float64, len(texts))
+	for _, d := range embResp.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b, from -1
+// (opposite) to 1 (same direction)
+func CosineSimilarity(a, b []float64) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("vector lengths differ (%d and %d)", len(a), len(b))
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0, fmt.Errorf("cannot compare a zero vector")
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB)), nil
+}
@@ -0,0 +1,104 @@
+package benchmark
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestNewOpenAIEmbeddingProvider_NoAPIKey(t *testing.T) {
+	originalKey := os.Getenv("OPENAI_API_KEY")
+	os.Unsetenv("OPENAI_API_KEY")
+	defer func() {
+		if originalKey != "" {
+			os.Setenv("OPENAI_API_KEY", originalKey)
+		}
+	}()
+
+	_, err := NewOpenAIEmbeddingProvider()
+	if err == nil {
+		t.Error("expected error when OPENAI_API_KEY is not set")
+	}
+}
+
+func TestOpenAIEmbeddingProvider_Embed(t *testing.T) {
+	var got openAIEmbeddingRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("unexpected authorization header %q", r.Header.Get("Authorization"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		// Out of order to check vectors are placed by index
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	p := &OpenAIEmbeddingProvider{apiKey: "test-key", baseURL: server.URL, model: DefaultEmbeddingModel, client: server.Client()}
+	vectors, err := p.Embed(context.Background(), []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+
+	if got.Model != "text-embedding-3-small" {
+		t.Errorf("expected model text-embedding-3-small, got %q", got.Model)
+	}
+	if len(got.Input) != 2 || got.Input[0] != "first" {
+		t.Errorf("unexpected input %v", got.Input)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("unexpected vectors %v", vectors)
+	}
+}
+
+func TestOpenAIEmbeddingProvider_EmbedAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"invalid key","type":"invalid_request_error"}}`))
+	}))
+	defer server.Close()
+
+	p := &OpenAIEmbeddingProvider{apiKey: "bad", baseURL: server.URL, model: DefaultEmbeddingModel, client: server.Client()}
+	if _, err := p.Embed(context.Background(), []string{"text"}); err == nil {
+		t.Error("expected error from API error response")
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		want float64
+	}{
+		{"identical", []float64{1, 2, 3}, []float64{1, 2, 3}, 1},
+		{"scaled", []float64{1, 2, 3}, []float64{2, 4, 6}, 1},
+		{"orthogonal", []float64{1, 0}, []float64{0, 1}, 0},
+		{"opposite", []float64{1, 0}, []float64{-1, 0}, -1},
+		{"partial", []float64{1, 0}, []float64{1, 1}, 1 / math.Sqrt2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CosineSimilarity(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("CosineSimilarity failed: %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("expected %.4f, got %.4f", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := CosineSimilarity([]float64{1}, []float64{1, 2}); err == nil {
+		t.Error("expected error for mismatched lengths")
+	}
+	if _, err := CosineSimilarity([]float64{0, 0}, []float64{1, 2}); err == nil {
+		t.Error("expected error for zero vector")
+	}
+}
@@ -0,0 +1,197 @@
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/promptsmith/cli/internal/logging"
+)
+
+// GeminiProvider implements the Provider interface for Google Gemini
+type GeminiProvider struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// Gemini API types
+type geminiRequest struct {
+	Contents         []geminiContent        `json:"contents"`
+	GenerationConfig geminiGenerationConfig `json:"generationConfig"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiGenerationConfig struct {
+	MaxOutputTokens int     `json:"maxOutputTokens,omitempty"`
+	Temperature     float64 `json:"temperature,omitempty"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		TotalTokenCount      int `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
+	ModelVersion string `json:"modelVersion"`
+	Error        *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error,omitempty"`
+}
+
+// NewGeminiProvider creates a new Gemini provider
+func NewGeminiProvider() (*GeminiProvider, error) {
+	apiKey := os.Getenv("GEMINI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("GEMINI_API_KEY environment variable not set")
+	}
+
+	return &GeminiProvider{
+		apiKey:  apiKey,
+		baseURL: "https://generativelanguage.googleapis.com/v1beta",
+		client: &http.Client{
+			Transport: logging.Transport(nil),
+			Timeout:   60 * time.Second,
+		},
+	}, nil
+}
+
+// Name returns the provider name. It matches what GetProviderForModel
+// returns for gemini models.
+func (p *GeminiProvider) Name() string {
+	return "google"
+}
+
+// Models returns supported models
+func (p *GeminiProvider) Models() []string {
+	return []string{
+		"gemini-2.5-pro",
+		"gemini-2.5-flash",
+		"gemini-2.0-flash",
+		"gemini-2.0-flash-lite",
+		"gemini-1.5-pro",
+		"gemini-1.5-flash",
+	}
+}
+
+// SupportsModel checks if model is supported
+func (p *GeminiProvider) SupportsModel(model string) bool {
+	for _, m := range p.Models() {
+		if m == model {
+			return true
+		}
+	}
+	return false
+}
+
+// Complete sends a generateContent request to Gemini
+func (p *GeminiProvider) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	startTime := time.Now()
+
+	maxTokens := req.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = 1024
+	}
+
+	temperature := req.Temperature
+	if temperature == 0 {
+		temperature = 0.7
+	}
+
+	geminiReq := geminiRequest{
+		Contents: []geminiContent{
+			{Role: "user", Parts: []geminiPart{{Text: req.Prompt}}},
+		},
+		GenerationConfig: geminiGenerationConfig{
+			MaxOutputTokens: maxTokens,
+			Temperature:     temperature,
+		},
+	}
+
+	body, err := json.Marshal(geminiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/models/%s:generateContent", p.baseURL, url.PathEscape(req.Model))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-goog-api-key", p.apiKey)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var geminiResp geminiResponse
+	if err := json.Unmarshal(respBody, &geminiResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, newAPIError(resp, "")
+		}
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if geminiResp.Error != nil {
+		return nil, newAPIError(resp, geminiResp.Error.Message)
+	}
+
+	if len(geminiResp.Candidates) == 0 {
+		return nil, fmt.Errorf("no candidates in response")
+	}
+
+	// A candidate's text may be split across several parts
+	var content strings.Builder
+	for _, part := range geminiResp.Candidates[0].Content.Parts {
+		content.WriteString(part.Text)
+	}
+
+	model := geminiResp.ModelVersion
+	if model == "" {
+		model = req.Model
+	}
+
+	usage := geminiResp.UsageMetadata
+	latencyMs := time.Since(startTime).Milliseconds()
+	cost := CalculateCost(req.Model, usage.PromptTokenCount, usage.CandidatesTokenCount)
+
+	return &CompletionResponse{
+		Content:      content.String(),
+		Model:        model,
+		PromptTokens: usage.PromptTokenCount,
+		OutputTokens: usage.CandidatesTokenCount,
+		TotalTokens:  usage.TotalTokenCount,
+		LatencyMs:    latencyMs,
+		Cost:         cost,
+	}, nil
+}
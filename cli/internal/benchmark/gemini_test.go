@@ -0,0 +1,126 @@
+package benchmark
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestNewGeminiProvider_NoAPIKey(t *testing.T) {
+	originalKey := os.Getenv("GEMINI_API_KEY")
+	os.Unsetenv("GEMINI_API_KEY")
+	defer func() {
+		if originalKey != "" {
+			os.Setenv("GEMINI_API_KEY", originalKey)
+		}
+	}()
+
+	_, err := NewGeminiProvider()
+	if err == nil {
+		t.Error("expected error when GEMINI_API_KEY is not set")
+	}
+}
+
+func TestGeminiProvider_Registry(t *testing.T) {
+	p := &GeminiProvider{apiKey: "test-key"}
+
+	registry := NewProviderRegistry()
+	registry.Register(p)
+	for _, model := range p.Models() {
+		got, err := registry.GetForModel(model)
+		if err != nil {
+			t.Fatalf("GetForModel(%s) failed: %v", model, err)
+		}
+		if got != p {
+			t.Errorf("expected %s to route to the Gemini provider", model)
+		}
+		if _, ok := pricingForModel(model); !ok {
+			t.Errorf("expected pricing for %s", model)
+		}
+	}
+
+	if !p.SupportsModel("gemini-2.0-flash") {
+		t.Error("expected gemini-2.0-flash to be supported")
+	}
+	if p.SupportsModel("gpt-4o") {
+		t.Error("expected gpt-4o not to be supported")
+	}
+}
+
+func TestGeminiProvider_Complete(t *testing.T) {
+	var got geminiRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-2.0-flash:generateContent" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("x-goog-api-key") != "test-key" {
+			t.Errorf("unexpected api key header %q", r.Header.Get("x-goog-api-key"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{
+			"candidates": [{
+				"content": {"role": "model", "parts": [{"text": "Hello, "}, {"text": "world"}]},
+				"finishReason": "STOP"
+			}],
+			"usageMetadata": {"promptTokenCount": 1000, "candidatesTokenCount": 500, "totalTokenCount": 1500},
+			"modelVersion": "gemini-2.0-flash-001"
+		}`))
+	}))
+	defer server.Close()
+
+	p := &GeminiProvider{apiKey: "test-key", baseURL: server.URL, client: server.Client()}
+	resp, err := p.Complete(context.Background(), CompletionRequest{Model: "gemini-2.0-flash", Prompt: "Say hello", MaxTokens: 256})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	if len(got.Contents) != 1 || got.Contents[0].Parts[0].Text != "Say hello" {
+		t.Errorf("unexpected request contents %+v", got.Contents)
+	}
+	if got.GenerationConfig.MaxOutputTokens != 256 {
+		t.Errorf("expected maxOutputTokens 256, got %d", got.GenerationConfig.MaxOutputTokens)
+	}
+
+	if resp.Content != "Hello, world" {
+		t.Errorf("expected joined content 'Hello, world', got %q", resp.Content)
+	}
+	if resp.Model != "gemini-2.0-flash-001" {
+		t.Errorf("expected model version from response, got %q", resp.Model)
+	}
+	if resp.PromptTokens != 1000 || resp.OutputTokens != 500 || resp.TotalTokens != 1500 {
+		t.Errorf("unexpected token counts %+v", resp)
+	}
+	// 1000 input at $0.10/1M plus 500 output at $0.40/1M
+	if want := 0.0003; resp.Cost < want-1e-12 || resp.Cost > want+1e-12 {
+		t.Errorf("expected cost %.6f, got %.6f", want, resp.Cost)
+	}
+}
+
+func TestGeminiProvider_CompleteAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"code": 400, "message": "API key not valid", "status": "INVALID_ARGUMENT"}}`))
+	}))
+	defer server.Close()
+
+	p := &GeminiProvider{apiKey: "bad", baseURL: server.URL, client: server.Client()}
+	_, err := p.Complete(context.Background(), CompletionRequest{Model: "gemini-2.0-flash", Prompt: "Hi"})
+	if err == nil || err.Error() != "API error: API key not valid" {
+		t.Errorf("expected API error, got %v", err)
+	}
+}
+
+func TestGeminiProvider_CompleteNoCandidates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"candidates": [], "usageMetadata": {"promptTokenCount": 5}}`))
+	}))
+	defer server.Close()
+
+	p := &GeminiProvider{apiKey: "test-key", baseURL: server.URL, client: server.Client()}
+	if _, err := p.Complete(context.Background(), CompletionRequest{Model: "gemini-2.0-flash", Prompt: "Hi"}); err == nil {
+		t.Error("expected error when response has no candidates")
+	}
+}
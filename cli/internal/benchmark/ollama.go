@@ -0,0 +1,220 @@
+package benchmark
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/promptsmith/cli/internal/logging"
+)
+
+// OllamaModelPrefix marks a model ID as one served by a local Ollama
+// instance, e.g. "ollama/llama3.2"
+const OllamaModelPrefix = "ollama/"
+
+const defaultOllamaURL = "http://localhost:11434"
+
+// OllamaProvider implements the Provider interface for a local Ollama server
+type OllamaProvider struct {
+	baseURL string
+	client  *http.Client
+	models  []string
+}
+
+// Ollama API types
+type ollamaRequest struct {
+	Model   string        `json:"model"`
+	Prompt  string        `json:"prompt"`
+	Stream  bool          `json:"stream"`
+	Options ollamaOptions `json:"options"`
+}
+
+type ollamaOptions struct {
+	NumPredict  int     `json:"num_predict,omitempty"`
+	Temperature float64 `json:"temperature,omitempty"`
+}
+
+// ollamaResponse is one object of a /api/generate reply. A streamed reply is
+// a sequence of them, one per line; the last has Done set and the counts.
+type ollamaResponse struct {
+	Model           string `json:"model"`
+	Response        string `json:"response"`
+	Done            bool   `json:"done"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+	Error           string `json:"error,omitempty"`
+}
+
+type ollamaTagsResponse struct {
+	Models []struct {
+		Name string `json:"name"`
+	} `json:"models"`
+}
+
+// NewOllamaProvider creates a provider for the Ollama server at OLLAMA_HOST,
+// or http://localhost:11434 if unset. It fails if the server can't be
+// reached, so a registry only gets Ollama when it is running.
+func NewOllamaProvider() (*OllamaProvider, error) {
+	baseURL := os.Getenv("OLLAMA_HOST")
+	if baseURL == "" {
+		baseURL = defaultOllamaURL
+	}
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+
+	p := &OllamaProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client: &http.Client{
+			Transport: logging.Transport(nil),
+			// Local models can be slow to load on first use
+			Timeout: 5 * time.Minute,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	models, err := p.fetchModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ollama not reachable at %s: %w", p.baseURL, err)
+	}
+	p.models = models
+	return p, nil
+}
+
+// fetchModels lists the models pulled into the Ollama server
+func (p *OllamaProvider) fetchModels(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var tags ollamaTagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	models := make([]string, len(tags.Models))
+	for i, m := range tags.Models {
+		models[i] = m.Name
+	}
+	return models, nil
+}
+
+// Name returns the provider name
+func (p *OllamaProvider) Name() string {
+	return "ollama"
+}
+
+// Models returns the models available on the Ollama server
+func (p *OllamaProvider) Models() []string {
+	return p.models
+}
+
+// SupportsModel checks if model is available locally. The "ollama/" prefix
+// and the default ":latest" tag are optional.
+func (p *OllamaProvider) SupportsModel(model string) bool {
+	model = strings.TrimPrefix(model, OllamaModelPrefix)
+	for _, m := range p.models {
+		if m == model || m == model+":latest" {
+			return true
+		}
+	}
+	return false
+}
+
+// Complete sends a generate request to Ollama. Local models cost nothing.
+func (p *OllamaProvider) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	startTime := time.Now()
+
+	maxTokens := req.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = 1024
+	}
+
+	temperature := req.Temperature
+	if temperature == 0 {
+		temperature = 0.7
+	}
+
+	ollamaReq := ollamaRequest{
+		Model:  strings.TrimPrefix(req.Model, OllamaModelPrefix),
+		Prompt: req.Prompt,
+		Stream: false,
+		Options: ollamaOptions{
+			NumPredict:  maxTokens,
+			Temperature: temperature,
+		},
+	}
+
+	body, err := json.Marshal(ollamaReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read a single object or a stream of them the same way
+	var content strings.Builder
+	var final ollamaResponse
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk ollamaResponse
+		if err := decoder.Decode(&chunk); err == io.EOF {
+			break
+		} else if err != nil {
+			if resp.StatusCode != http.StatusOK {
+				return nil, newAPIError(resp, "")
+			}
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		if chunk.Error != "" {
+			return nil, newAPIError(resp, chunk.Error)
+		}
+		content.WriteString(chunk.Response)
+		final = chunk
+		if chunk.Done {
+			break
+		}
+	}
+
+	if !final.Done {
+		return nil, fmt.Errorf("incomplete response")
+	}
+
+	return &CompletionResponse{
+		Content:      content.String(),
+		Model:        final.Model,
+		PromptTokens: final.PromptEvalCount,
+		OutputTokens: final.EvalCount,
+		TotalTokens:  final.PromptEvalCount + final.EvalCount,
+		LatencyMs:    time.Since(startTime).Milliseconds(),
+	}, nil
+}
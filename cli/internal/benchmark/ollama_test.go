@@ -0,0 +1,185 @@
+package benchmark
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newOllamaServer mimics an Ollama server with two pulled models. generate
+// writes the body of /api/generate replies.
+func newOllamaServer(t *testing.T, generate func(w http.ResponseWriter, req ollamaRequest)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models": [{"name": "llama3.2:latest"}, {"name": "qwen2.5:7b"}]}`))
+		case "/api/generate":
+			var req ollamaRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			generate(w, req)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewOllamaProvider_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	t.Setenv("OLLAMA_HOST", url)
+	if _, err := NewOllamaProvider(); err == nil {
+		t.Error("expected error when Ollama is not reachable")
+	}
+}
+
+func TestNewOllamaProvider_Models(t *testing.T) {
+	server := newOllamaServer(t, nil)
+	t.Setenv("OLLAMA_HOST", server.URL)
+
+	p, err := NewOllamaProvider()
+	if err != nil {
+		t.Fatalf("NewOllamaProvider failed: %v", err)
+	}
+	if p.Name() != "ollama" {
+		t.Errorf("expected name 'ollama', got '%s'", p.Name())
+	}
+	if models := p.Models(); len(models) != 2 || models[0] != "llama3.2:latest" {
+		t.Errorf("expected models from /api/tags, got %v", models)
+	}
+
+	for _, model := range []string{"llama3.2:latest", "llama3.2", "ollama/llama3.2", "ollama/qwen2.5:7b"} {
+		if !p.SupportsModel(model) {
+			t.Errorf("expected %s to be supported", model)
+		}
+	}
+	for _, model := range []string{"qwen2.5", "gpt-4o", "ollama/mistral"} {
+		if p.SupportsModel(model) {
+			t.Errorf("expected %s not to be supported", model)
+		}
+	}
+}
+
+func TestOllamaProvider_Routing(t *testing.T) {
+	server := newOllamaServer(t, nil)
+	t.Setenv("OLLAMA_HOST", server.URL)
+
+	ollama, err := NewOllamaProvider()
+	if err != nil {
+		t.Fatalf("NewOllamaProvider failed: %v", err)
+	}
+	registry := NewProviderRegistry()
+	registry.Register(&MockProvider{name: "groq"})
+	registry.Register(&MockProvider{name: "openai"})
+	registry.Register(ollama)
+
+	// A local tag wins over the groq prefix route
+	for _, model := range []string{"ollama/qwen2.5:7b", "llama3.2"} {
+		p, err := registry.GetForModel(model)
+		if err != nil || p.Name() != "ollama" {
+			t.Errorf("expected %s to route to ollama, got %v, %v", model, p, err)
+		}
+	}
+	if p, _ := registry.GetForModel("llama-3.1-70b"); p == nil || p.Name() != "groq" {
+		t.Errorf("expected model not pulled locally to keep its usual route, got %v", p)
+	}
+	if p, _ := registry.GetForModel("gpt-4o"); p == nil || p.Name() != "openai" {
+		t.Errorf("expected gpt-4o to route to openai, got %v", p)
+	}
+	if got := GetProviderForModel("ollama/mistral"); got != "ollama" {
+		t.Errorf("GetProviderForModel(ollama/mistral) = %s, want ollama", got)
+	}
+}
+
+func TestOllamaProvider_Complete(t *testing.T) {
+	var got ollamaRequest
+	server := newOllamaServer(t, func(w http.ResponseWriter, req ollamaRequest) {
+		got = req
+		w.Write([]byte(`{"model": "llama3.2", "response": "Hello there", "done": true, "prompt_eval_count": 12, "eval_count": 4}`))
+	})
+	t.Setenv("OLLAMA_HOST", server.URL)
+
+	p, err := NewOllamaProvider()
+	if err != nil {
+		t.Fatalf("NewOllamaProvider failed: %v", err)
+	}
+	resp, err := p.Complete(context.Background(), CompletionRequest{Model: "ollama/llama3.2", Prompt: "Say hello", MaxTokens: 64})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	if got.Model != "llama3.2" {
+		t.Errorf("expected prefix stripped from model, got %q", got.Model)
+	}
+	if got.Prompt != "Say hello" || got.Stream || got.Options.NumPredict != 64 {
+		t.Errorf("unexpected request %+v", got)
+	}
+	if resp.Content != "Hello there" {
+		t.Errorf("expected content 'Hello there', got %q", resp.Content)
+	}
+	if resp.PromptTokens != 12 || resp.OutputTokens != 4 || resp.TotalTokens != 16 {
+		t.Errorf("unexpected token counts %+v", resp)
+	}
+	if resp.Cost != 0 {
+		t.Errorf("expected local model to cost nothing, got %f", resp.Cost)
+	}
+}
+
+func TestOllamaProvider_CompleteStreaming(t *testing.T) {
+	server := newOllamaServer(t, func(w http.ResponseWriter, req ollamaRequest) {
+		w.Write([]byte(`{"model": "llama3.2", "response": "Hel", "done": false}
+{"model": "llama3.2", "response": "lo ", "done": false}
+{"model": "llama3.2", "response": "there", "done": true, "prompt_eval_count": 12, "eval_count": 3}
+`))
+	})
+	t.Setenv("OLLAMA_HOST", server.URL)
+
+	p, err := NewOllamaProvider()
+	if err != nil {
+		t.Fatalf("NewOllamaProvider failed: %v", err)
+	}
+	resp, err := p.Complete(context.Background(), CompletionRequest{Model: "llama3.2", Prompt: "Say hello"})
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if resp.Content != "Hello there" {
+		t.Errorf("expected chunks joined into 'Hello there', got %q", resp.Content)
+	}
+	if resp.OutputTokens != 3 {
+		t.Errorf("expected counts from final chunk, got %+v", resp)
+	}
+}
+
+func TestOllamaProvider_CompleteErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"api error", `{"error": "model 'mistral' not found, try pulling it first"}`},
+		{"stream cut short", `{"model": "llama3.2", "response": "Hel", "done": false}`},
+		{"malformed", `not json`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newOllamaServer(t, func(w http.ResponseWriter, req ollamaRequest) {
+				w.Write([]byte(tt.body))
+			})
+			t.Setenv("OLLAMA_HOST", server.URL)
+
+			p, err := NewOllamaProvider()
+			if err != nil {
+				t.Fatalf("NewOllamaProvider failed: %v", err)
+			}
+			if _, err := p.Complete(context.Background(), CompletionRequest{Model: "llama3.2", Prompt: "Hi"}); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
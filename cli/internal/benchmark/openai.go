@@ -1,6 +1,7 @@
 package benchmark
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -8,7 +9,10 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
+
+	"github.com/promptsmith/cli/internal/logging"
 )
 
 // OpenAIProvider implements the Provider interface for OpenAI
@@ -20,10 +24,16 @@ type OpenAIProvider struct {
 
 // OpenAI API types
 type openAIRequest struct {
-	Model       string          `json:"model"`
-	Messages    []openAIMessage `json:"messages"`
-	MaxTokens   int             `json:"max_tokens,omitempty"`
-	Temperature float64         `json:"temperature,omitempty"`
+	Model         string               `json:"model"`
+	Messages      []openAIMessage      `json:"messages"`
+	MaxTokens     int                  `json:"max_tokens,omitempty"`
+	Temperature   float64              `json:"temperature,omitempty"`
+	Stream        bool                 `json:"stream,omitempty"`
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type openAIMessage struct {
@@ -56,6 +66,22 @@ type openAIResponse struct {
 	} `json:"error,omitempty"`
 }
 
+// openAIStreamChunk is one server-sent event of a streamed completion. With
+// include_usage set, the last chunk has no choices and carries the usage.
+type openAIStreamChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
 // NewOpenAIProvider creates a new OpenAI provider
 func NewOpenAIProvider() (*OpenAIProvider, error) {
 	apiKey := os.Getenv("OPENAI_API_KEY")
@@ -67,7 +93,8 @@ func NewOpenAIProvider() (*OpenAIProvider, error) {
 		apiKey:  apiKey,
 		baseURL: "https://api.openai.com/v1",
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Transport: logging.Transport(nil),
+			Timeout:   60 * time.Second,
 		},
 	}, nil
 }
@@ -150,11 +177,14 @@ func (p *OpenAIProvider) Complete(ctx context.Context, req CompletionRequest) (*
 
 	var openAIResp openAIResponse
 	if err := json.Unmarshal(respBody, &openAIResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, newAPIError(resp, "")
+		}
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
 	if openAIResp.Error != nil {
-		return nil, fmt.Errorf("API error: %s", openAIResp.Error.Message)
+		return nil, newAPIError(resp, openAIResp.Error.Message)
 	}
 
 	if len(openAIResp.Choices) == 0 {
@@ -174,3 +204,110 @@ func (p *OpenAIProvider) Complete(ctx context.Context, req CompletionRequest) (*
 		Cost:         cost,
 	}, nil
 }
+
+// CompleteStream sends a streaming completion request to OpenAI
+func (p *OpenAIProvider) CompleteStream(ctx context.Context, req CompletionRequest, onChunk func(string) error) (*CompletionResponse, error) {
+	startTime := time.Now()
+
+	maxTokens := req.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = 1024
+	}
+
+	temperature := req.Temperature
+	if temperature == 0 {
+		temperature = 0.7
+	}
+
+	openAIReq := openAIRequest{
+		Model: req.Model,
+		Messages: []openAIMessage{
+			{Role: "user", Content: req.Prompt},
+		},
+		MaxTokens:     maxTokens,
+		Temperature:   temperature,
+		Stream:        true,
+		StreamOptions: &openAIStreamOptions{IncludeUsage: true},
+	}
+
+	body, err := json.Marshal(openAIReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Errors come back as a plain JSON body rather than a stream
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		var openAIResp openAIResponse
+		if err := json.Unmarshal(respBody, &openAIResp); err == nil && openAIResp.Error != nil {
+			return nil, newAPIError(resp, openAIResp.Error.Message)
+		}
+		return nil, newAPIError(resp, "")
+	}
+
+	result := &CompletionResponse{Model: req.Model}
+	var content strings.Builder
+	done := false
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			done = true
+			break
+		}
+
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to parse stream event: %w", err)
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			result.PromptTokens = chunk.Usage.PromptTokens
+			result.OutputTokens = chunk.Usage.CompletionTokens
+			result.TotalTokens = chunk.Usage.TotalTokens
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if err := onChunk(choice.Delta.Content); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+	if !done {
+		return nil, fmt.Errorf("stream ended before completion")
+	}
+
+	result.Content = content.String()
+	result.LatencyMs = time.Since(startTime).Milliseconds()
+	result.Cost = CalculateCost(req.Model, result.PromptTokens, result.OutputTokens)
+	return result, nil
+}
@@ -1,8 +1,14 @@
 package benchmark
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestNewOpenAIProvider_NoAPIKey(t *testing.T) {
@@ -118,3 +124,82 @@ func TestOpenAIProvider_SupportsModel(t *testing.T) {
 		})
 	}
 }
+
+func TestOpenAIProvider_CompleteStream(t *testing.T) {
+	firstSeen := make(chan struct{})
+	var got openAIRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+
+		fmt.Fprint(w, `data: {"model":"gpt-4o-mini-2024-07-18","choices":[{"delta":{"role":"assistant","content":""}}]}`+"\n\n")
+		fmt.Fprint(w, `data: {"choices":[{"delta":{"content":"Hello"}}]}`+"\n\n")
+		flusher.Flush()
+
+		// Hold the rest back until the client has handled the first chunk
+		select {
+		case <-firstSeen:
+		case <-time.After(5 * time.Second):
+			t.Error("first chunk was not delivered before the stream finished")
+		}
+
+		fmt.Fprint(w, `data: {"choices":[{"delta":{"content":" world"}}]}`+"\n\n")
+		fmt.Fprint(w, `data: {"choices":[],"usage":{"prompt_tokens":1000,"completion_tokens":2,"total_tokens":1002}}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	p := &OpenAIProvider{apiKey: "test-key", baseURL: server.URL, client: server.Client()}
+	var chunks []string
+	resp, err := p.CompleteStream(context.Background(), CompletionRequest{Model: "gpt-4o-mini", Prompt: "Hi"}, func(chunk string) error {
+		if len(chunks) == 0 {
+			close(firstSeen)
+		}
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("CompleteStream failed: %v", err)
+	}
+
+	if !got.Stream || got.StreamOptions == nil || !got.StreamOptions.IncludeUsage {
+		t.Errorf("expected a streaming request with usage, got %+v", got)
+	}
+	if len(chunks) != 2 || chunks[0] != "Hello" || chunks[1] != " world" {
+		t.Errorf("unexpected chunks %q", chunks)
+	}
+	if resp.Content != "Hello world" || resp.Model != "gpt-4o-mini-2024-07-18" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if resp.PromptTokens != 1000 || resp.OutputTokens != 2 || resp.Cost == 0 {
+		t.Errorf("expected usage and cost from the final chunk, got %+v", resp)
+	}
+}
+
+func TestOpenAIProvider_CompleteStreamErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"api error", http.StatusUnauthorized, `{"error":{"message":"Incorrect API key","type":"invalid_request_error"}}`},
+		{"stream cut short", http.StatusOK, `data: {"choices":[{"delta":{"content":"Hel"}}]}` + "\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			p := &OpenAIProvider{apiKey: "test-key", baseURL: server.URL, client: server.Client()}
+			_, err := p.CompleteStream(context.Background(), CompletionRequest{Model: "gpt-4o-mini", Prompt: "Hi"}, func(string) error { return nil })
+			if err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
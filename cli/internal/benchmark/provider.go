@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 )
 
@@ -20,6 +21,34 @@ type Provider interface {
 	SupportsModel(model string) bool
 }
 
+// StreamingProvider is implemented by providers that can deliver a
+// completion as it is generated
+type StreamingProvider interface {
+	Provider
+	// CompleteStream calls onChunk with each piece of output as it arrives
+	// and returns the full response once the completion ends. An error from
+	// onChunk stops the stream.
+	CompleteStream(ctx context.Context, req CompletionRequest, onChunk func(string) error) (*CompletionResponse, error)
+}
+
+// CompleteStream streams a completion from p if it supports streaming.
+// Otherwise it waits for the full completion and delivers it as one chunk.
+func CompleteStream(ctx context.Context, p Provider, req CompletionRequest, onChunk func(string) error) (*CompletionResponse, error) {
+	if sp, ok := p.(StreamingProvider); ok {
+		return sp.CompleteStream(ctx, req, onChunk)
+	}
+	resp, err := p.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Content != "" {
+		if err := onChunk(resp.Content); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
 // CompletionRequest represents a request to an LLM
 type CompletionRequest struct {
 	Model       string
@@ -67,9 +96,12 @@ var modelPricing = map[string]ModelPricing{
 	"claude-haiku":  {InputPer1M: 0.80, OutputPer1M: 4.00},
 	"claude-opus":   {InputPer1M: 15.00, OutputPer1M: 75.00},
 	// Google
-	"gemini-1.5-pro":   {InputPer1M: 1.25, OutputPer1M: 5.00},
-	"gemini-1.5-flash": {InputPer1M: 0.075, OutputPer1M: 0.30},
-	"gemini-2.0-flash": {InputPer1M: 0.10, OutputPer1M: 0.40},
+	"gemini-2.5-pro":        {InputPer1M: 1.25, OutputPer1M: 10.00},
+	"gemini-2.5-flash":      {InputPer1M: 0.30, OutputPer1M: 2.50},
+	"gemini-2.0-flash":      {InputPer1M: 0.10, OutputPer1M: 0.40},
+	"gemini-2.0-flash-lite": {InputPer1M: 0.075, OutputPer1M: 0.30},
+	"gemini-1.5-pro":        {InputPer1M: 1.25, OutputPer1M: 5.00},
+	"gemini-1.5-flash":      {InputPer1M: 0.075, OutputPer1M: 0.30},
 }
 
 // CalculateCost calculates the cost for a completion
@@ -131,6 +163,8 @@ func effectiveModelPricing() map[string]ModelPricing {
 func GetProviderForModel(model string) string {
 	model = strings.ToLower(model)
 	switch {
+	case strings.HasPrefix(model, OllamaModelPrefix):
+		return "ollama"
 	case strings.HasPrefix(model, "gpt-") || strings.HasPrefix(model, "o1"):
 		return "openai"
 	case strings.HasPrefix(model, "claude"):
@@ -147,6 +181,22 @@ func GetProviderForModel(model string) string {
 // ProviderRegistry holds registered providers
 type ProviderRegistry struct {
 	providers map[string]Provider
+	aliases   map[string]string
+}
+
+// NewModelCatalog returns a registry of every provider whose models can be
+// listed, for checking model names rather than calling them. Cloud providers
+// are included whether or not an API key is set; Ollama only if it is
+// reachable.
+func NewModelCatalog() *ProviderRegistry {
+	registry := NewProviderRegistry()
+	registry.Register(&OpenAIProvider{})
+	registry.Register(&AnthropicProvider{})
+	registry.Register(&GeminiProvider{})
+	if ollama, err := NewOllamaProvider(); err == nil {
+		registry.Register(ollama)
+	}
+	return registry
 }
 
 // NewProviderRegistry creates a new provider registry
@@ -161,14 +211,51 @@ func (r *ProviderRegistry) Register(p Provider) {
 	r.providers[p.Name()] = p
 }
 
+// Models returns the models of every registered provider, sorted
+func (r *ProviderRegistry) Models() []string {
+	seen := make(map[string]bool)
+	var models []string
+	for _, p := range r.providers {
+		for _, m := range p.Models() {
+			if !seen[m] {
+				seen[m] = true
+				models = append(models, m)
+			}
+		}
+	}
+	sort.Strings(models)
+	return models
+}
+
 // Get returns a provider by name
 func (r *ProviderRegistry) Get(name string) (Provider, bool) {
 	p, ok := r.providers[name]
 	return p, ok
 }
 
-// GetForModel returns the provider that supports the given model
+// SetAliases sets the model aliases (e.g. "fast" -> "gpt-4o-mini") that
+// ResolveModel and GetForModel substitute for concrete model IDs
+func (r *ProviderRegistry) SetAliases(aliases map[string]string) {
+	r.aliases = aliases
+}
+
+// ResolveModel returns the model an alias stands for, or model itself if it
+// is not an alias. Aliases are not chained.
+func (r *ProviderRegistry) ResolveModel(model string) string {
+	if concrete, ok := r.aliases[model]; ok && concrete != "" {
+		return concrete
+	}
+	return model
+}
+
+// GetForModel returns the provider that supports the given model or alias. A
+// model pulled into a registered Ollama server is served locally, even when
+// its name would otherwise route to a cloud provider.
 func (r *ProviderRegistry) GetForModel(model string) (Provider, error) {
+	model = r.ResolveModel(model)
+	if ollama, ok := r.Get("ollama"); ok && ollama.SupportsModel(model) {
+		return ollama, nil
+	}
 	providerName := GetProviderForModel(model)
 	p, ok := r.Get(providerName)
 	if !ok {
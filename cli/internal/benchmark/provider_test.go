@@ -3,6 +3,7 @@ package benchmark
 import (
 	"context"
 	"math"
+	"reflect"
 	"testing"
 )
 
@@ -195,6 +196,13 @@ func TestProviderRegistry(t *testing.T) {
 		}
 	})
 
+	t.Run("list models", func(t *testing.T) {
+		want := []string{"claude-opus", "claude-sonnet", "gpt-4o", "gpt-4o-mini"}
+		if got := registry.Models(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Models() = %v, want %v", got, want)
+		}
+	})
+
 	t.Run("get provider for unregistered model provider", func(t *testing.T) {
 		_, err := registry.GetForModel("gemini-1.5-pro")
 		if err == nil {
@@ -202,3 +210,51 @@ func TestProviderRegistry(t *testing.T) {
 		}
 	})
 }
+
+func TestProviderRegistryAliases(t *testing.T) {
+	registry := NewProviderRegistry()
+	registry.Register(&MockProvider{name: "openai", models: []string{"gpt-4o-mini"}})
+	registry.Register(&MockProvider{name: "anthropic", models: []string{"claude-sonnet"}})
+	registry.SetAliases(map[string]string{
+		"fast":  "gpt-4o-mini",
+		"smart": "claude-sonnet",
+		"chain": "fast",
+	})
+
+	if got := registry.ResolveModel("fast"); got != "gpt-4o-mini" {
+		t.Errorf("expected fast to resolve to gpt-4o-mini, got %s", got)
+	}
+	if got := registry.ResolveModel("gpt-4o"); got != "gpt-4o" {
+		t.Errorf("expected a concrete model to be unchanged, got %s", got)
+	}
+	if got := registry.ResolveModel("chain"); got != "fast" {
+		t.Errorf("expected aliases not to chain, got %s", got)
+	}
+
+	p, err := registry.GetForModel("smart")
+	if err != nil {
+		t.Fatalf("GetForModel failed: %v", err)
+	}
+	if p.Name() != "anthropic" {
+		t.Errorf("expected smart to route to anthropic, got %s", p.Name())
+	}
+}
+
+func TestCompleteStreamFallback(t *testing.T) {
+	p := &MockProvider{name: "mock", response: &CompletionResponse{Content: "Full reply", OutputTokens: 2}}
+
+	var chunks []string
+	resp, err := CompleteStream(context.Background(), p, CompletionRequest{Model: "mock"}, func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("CompleteStream failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0] != "Full reply" {
+		t.Errorf("expected the whole reply as one chunk, got %q", chunks)
+	}
+	if resp.OutputTokens != 2 {
+		t.Errorf("expected the provider's response, got %+v", resp)
+	}
+}
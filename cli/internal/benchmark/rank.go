@@ -0,0 +1,103 @@
+package benchmark
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RankWeights sets how much each metric counts towards a model's score.
+// Weights are relative; they need not sum to 1.
+type RankWeights struct {
+	Cost    float64 `json:"cost"`
+	Latency float64 `json:"latency"`
+	Errors  float64 `json:"errors"`
+}
+
+// ModelRank is a model's position in a ranking
+type ModelRank struct {
+	Rank           int     `json:"rank"`
+	Model          string  `json:"model"`
+	Score          float64 `json:"score"`
+	CostPerRequest float64 `json:"cost_per_request"`
+	LatencyP50Ms   float64 `json:"latency_p50_ms"`
+	ErrorRate      float64 `json:"error_rate"`
+}
+
+// RankModels scores each model in result between 0 and 100, higher being
+// better, and returns them best first. Each metric is normalized across the
+// models so the cheapest (or fastest, or most reliable) model gets full marks
+// for it and the most expensive gets none; the score is the weighted average.
+// Models where every run failed have no meaningful cost or latency and are
+// left out.
+func RankModels(result *BenchmarkResult, weights RankWeights) ([]ModelRank, error) {
+	if weights.Cost < 0 || weights.Latency < 0 || weights.Errors < 0 {
+		return nil, fmt.Errorf("weights must not be negative")
+	}
+	total := weights.Cost + weights.Latency + weights.Errors
+	if total == 0 {
+		return nil, fmt.Errorf("at least one weight must be positive")
+	}
+
+	var models []ModelResult
+	for _, m := range result.Models {
+		if m.ErrorRate >= 1.0 {
+			continue
+		}
+		models = append(models, m)
+	}
+
+	cost := metricRange(models, func(m ModelResult) float64 { return m.CostPerRequest })
+	latency := metricRange(models, func(m ModelResult) float64 { return m.LatencyP50Ms })
+	errors := metricRange(models, func(m ModelResult) float64 { return m.ErrorRate })
+
+	ranks := make([]ModelRank, 0, len(models))
+	for _, m := range models {
+		score := weights.Cost*cost.goodness(m.CostPerRequest) +
+			weights.Latency*latency.goodness(m.LatencyP50Ms) +
+			weights.Errors*errors.goodness(m.ErrorRate)
+
+		ranks = append(ranks, ModelRank{
+			Model:          m.Label(),
+			Score:          score / total * 100,
+			CostPerRequest: m.CostPerRequest,
+			LatencyP50Ms:   m.LatencyP50Ms,
+			ErrorRate:      m.ErrorRate,
+		})
+	}
+
+	// Ties keep the benchmark's model order
+	sort.SliceStable(ranks, func(i, j int) bool {
+		return ranks[i].Score > ranks[j].Score
+	})
+	for i := range ranks {
+		ranks[i].Rank = i + 1
+	}
+	return ranks, nil
+}
+
+type valueRange struct {
+	min, max float64
+}
+
+func metricRange(models []ModelResult, value func(ModelResult) float64) valueRange {
+	var r valueRange
+	for i, m := range models {
+		v := value(m)
+		if i == 0 || v < r.min {
+			r.min = v
+		}
+		if i == 0 || v > r.max {
+			r.max = v
+		}
+	}
+	return r
+}
+
+// goodness maps v onto 1 (the lowest value seen) through 0 (the highest).
+// When every model has the same value they all get full marks.
+func (r valueRange) goodness(v float64) float64 {
+	if r.max == r.min {
+		return 1
+	}
+	return (r.max - v) / (r.max - r.min)
+}
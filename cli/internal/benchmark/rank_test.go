@@ -0,0 +1,121 @@
+package benchmark
+
+import (
+	"math"
+	"testing"
+)
+
+func rankingResult() *BenchmarkResult {
+	return &BenchmarkResult{
+		Models: []ModelResult{
+			{Model: "expensive-fast", CostPerRequest: 0.0100, LatencyP50Ms: 200},
+			{Model: "cheap-slow", CostPerRequest: 0.0010, LatencyP50Ms: 1800},
+			{Model: "balanced", CostPerRequest: 0.0030, LatencyP50Ms: 500},
+			{Model: "broken", ErrorRate: 1.0},
+		},
+	}
+}
+
+func rankedModels(ranks []ModelRank) []string {
+	names := make([]string, len(ranks))
+	for i, r := range ranks {
+		names[i] = r.Model
+	}
+	return names
+}
+
+func TestRankModels(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights RankWeights
+		want    []string
+	}{
+		{
+			// expensive-fast and cheap-slow tie; benchmark order breaks it
+			name:    "balanced weights",
+			weights: RankWeights{Cost: 0.5, Latency: 0.5},
+			want:    []string{"balanced", "expensive-fast", "cheap-slow"},
+		},
+		{
+			name:    "cost only",
+			weights: RankWeights{Cost: 1},
+			want:    []string{"cheap-slow", "balanced", "expensive-fast"},
+		},
+		{
+			name:    "latency only",
+			weights: RankWeights{Latency: 1},
+			want:    []string{"expensive-fast", "balanced", "cheap-slow"},
+		},
+		{
+			name:    "weights are relative",
+			weights: RankWeights{Cost: 3, Latency: 1},
+			want:    []string{"balanced", "cheap-slow", "expensive-fast"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranks, err := RankModels(rankingResult(), tt.weights)
+			if err != nil {
+				t.Fatalf("RankModels failed: %v", err)
+			}
+			got := rankedModels(ranks)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("expected %v, got %v", tt.want, got)
+				}
+				if ranks[i].Rank != i+1 {
+					t.Errorf("expected rank %d for %s, got %d", i+1, got[i], ranks[i].Rank)
+				}
+			}
+		})
+	}
+}
+
+func TestRankModelsScores(t *testing.T) {
+	ranks, err := RankModels(rankingResult(), RankWeights{Cost: 1})
+	if err != nil {
+		t.Fatalf("RankModels failed: %v", err)
+	}
+
+	// cheapest scores 100, most expensive 0, the rest in proportion
+	want := map[string]float64{"cheap-slow": 100, "balanced": 700.0 / 9, "expensive-fast": 0}
+	for _, r := range ranks {
+		if math.Abs(r.Score-want[r.Model]) > 1e-9 {
+			t.Errorf("%s: expected score %.4f, got %.4f", r.Model, want[r.Model], r.Score)
+		}
+	}
+}
+
+func TestRankModelsInvalidWeights(t *testing.T) {
+	if _, err := RankModels(rankingResult(), RankWeights{}); err == nil {
+		t.Error("expected error when all weights are zero")
+	}
+	if _, err := RankModels(rankingResult(), RankWeights{Cost: -1, Latency: 1}); err == nil {
+		t.Error("expected error for negative weight")
+	}
+}
+
+func TestRankModelsIdenticalMetrics(t *testing.T) {
+	result := &BenchmarkResult{
+		Models: []ModelResult{
+			{Model: "a", CostPerRequest: 0.001, LatencyP50Ms: 300},
+			{Model: "b", CostPerRequest: 0.001, LatencyP50Ms: 300},
+		},
+	}
+	ranks, err := RankModels(result, RankWeights{Cost: 0.5, Latency: 0.5})
+	if err != nil {
+		t.Fatalf("RankModels failed: %v", err)
+	}
+	if got := rankedModels(ranks); got[0] != "a" || got[1] != "b" {
+		t.Errorf("expected ties to keep benchmark order, got %v", got)
+	}
+	for _, r := range ranks {
+		if r.Score != 100 {
+			t.Errorf("expected full score for tied model %s, got %.2f", r.Model, r.Score)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Backoff between retries doubles from retryBaseDelay up to retryMaxDelay.
+// They are variables so tests can shorten them.
+var (
+	retryBaseDelay = time.Second
+	retryMaxDelay  = 30 * time.Second
+)
+
+// APIError is an error response from a provider's API
+type APIError struct {
+	StatusCode int
+	Message    string
+	// RetryAfter is how long the provider asked to wait before retrying,
+	// or zero if it did not say
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API error: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("API error: %s", e.Message)
+}
+
+// Retryable reports whether the request may succeed if sent again: the
+// provider was rate limiting, overloaded or briefly unavailable
+func (e *APIError) Retryable() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout,
+		529: // Anthropic's "overloaded"
+		return true
+	}
+	return false
+}
+
+// newAPIError describes an error response, with message taken from its body
+// if the provider gave one
+func newAPIError(resp *http.Response, message string) *APIError {
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    message,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter reads a Retry-After header, given either as seconds or as
+// an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
+}
+
+// WithRetries wraps p so a completion that fails with a retryable API error
+// is sent again, up to maxRetries more times, after an exponential backoff
+// with jitter or the delay the provider asked for, up to retryMaxDelay. With
+// maxRetries of zero or less, p is returned as is.
+func WithRetries(p Provider, maxRetries int) Provider {
+	if maxRetries <= 0 {
+		return p
+	}
+	return &retryingProvider{Provider: p, maxRetries: maxRetries}
+}
+
+type retryingProvider struct {
+	Provider
+	maxRetries int
+}
+
+func (p *retryingProvider) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := p.Provider.Complete(ctx, req)
+		var apiErr *APIError
+		if err == nil || attempt == p.maxRetries || !errors.As(err, &apiErr) || !apiErr.Retryable() {
+			return resp, err
+		}
+
+		timer := time.NewTimer(retryDelay(attempt, apiErr.RetryAfter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// retryDelay is how long to wait before retrying after the given attempt
+// (counted from zero) failed. A provider's Retry-After is capped at
+// retryMaxDelay, so a bad or hostile header cannot stall a run.
+func retryDelay(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, retryMaxDelay)
+	}
+	delay := retryMaxDelay
+	if attempt < 30 {
+		delay = min(retryBaseDelay<<attempt, retryMaxDelay)
+	}
+	// Spread retries of concurrent requests over the second half of the delay
+	half := delay / 2
+	return half + rand.N(half+1)
+}
@@ -0,0 +1,146 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// shortRetryDelays makes backoff between retries negligible for a test
+func shortRetryDelays(t *testing.T) {
+	base, maxDelay := retryBaseDelay, retryMaxDelay
+	retryBaseDelay, retryMaxDelay = time.Millisecond, 4*time.Millisecond
+	t.Cleanup(func() { retryBaseDelay, retryMaxDelay = base, maxDelay })
+}
+
+// flakyOpenAIServer answers with status for the first failures requests,
+// then with a completion
+func flakyOpenAIServer(t *testing.T, failures int, status int) (*OpenAIProvider, *atomic.Int32) {
+	t.Helper()
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(attempts.Add(1)) <= failures {
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"error":{"message":"status %d"}}`, status)
+			return
+		}
+		fmt.Fprint(w, `{"model":"gpt-4o-mini","choices":[{"message":{"content":"Hello"}}],"usage":{"prompt_tokens":5,"completion_tokens":1,"total_tokens":6}}`)
+	}))
+	t.Cleanup(server.Close)
+	return &OpenAIProvider{apiKey: "test-key", baseURL: server.URL, client: server.Client()}, &attempts
+}
+
+func TestWithRetries_RetriesRateLimits(t *testing.T) {
+	shortRetryDelays(t)
+	p, attempts := flakyOpenAIServer(t, 2, http.StatusTooManyRequests)
+
+	resp, err := WithRetries(p, 3).Complete(context.Background(), CompletionRequest{Model: "gpt-4o-mini", Prompt: "Hi"})
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if resp.Content != "Hello" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts.Load())
+	}
+}
+
+func TestWithRetries_GivesUp(t *testing.T) {
+	shortRetryDelays(t)
+	p, attempts := flakyOpenAIServer(t, 5, http.StatusServiceUnavailable)
+
+	_, err := WithRetries(p, 2).Complete(context.Background(), CompletionRequest{Model: "gpt-4o-mini", Prompt: "Hi"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected the last 503 error, got %v", err)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("expected 1 attempt and 2 retries, got %d attempts", attempts.Load())
+	}
+}
+
+func TestWithRetries_FailsFastOnClientErrors(t *testing.T) {
+	shortRetryDelays(t)
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			p, attempts := flakyOpenAIServer(t, 1, status)
+
+			_, err := WithRetries(p, 3).Complete(context.Background(), CompletionRequest{Model: "gpt-4o-mini", Prompt: "Hi"})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if attempts.Load() != 1 {
+				t.Errorf("expected no retries, got %d attempts", attempts.Load())
+			}
+		})
+	}
+}
+
+func TestWithRetries_StopsWhenCancelled(t *testing.T) {
+	p, attempts := flakyOpenAIServer(t, 5, http.StatusTooManyRequests)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// The default backoff of about a second outlasts the context
+	_, err := WithRetries(p, 3).Complete(ctx, CompletionRequest{Model: "gpt-4o-mini", Prompt: "Hi"})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("expected no retry after cancellation, got %d attempts", attempts.Load())
+	}
+}
+
+func TestWithRetries_ZeroIsNoop(t *testing.T) {
+	p := &mockBenchmarkProvider{}
+	if WithRetries(p, 0) != Provider(p) {
+		t.Error("expected the provider itself with no retries")
+	}
+	if WithRetries(p, 2).Name() != "openai" {
+		t.Error("expected the wrapper to keep the provider's name")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"-1", 0},
+		{"Wed, 01 Jan 2025 12:00:30 GMT", 30 * time.Second},
+		{"Wed, 01 Jan 2025 11:00:00 GMT", 0},
+		{"soon", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	if got := retryDelay(0, 7*time.Second); got != 7*time.Second {
+		t.Errorf("expected Retry-After to be respected, got %v", got)
+	}
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		got := retryDelay(attempt, 0)
+		if got < want/2 || got > want {
+			t.Errorf("attempt %d: delay %v outside [%v, %v]", attempt, got, want/2, want)
+		}
+	}
+	if got := retryDelay(100, 0); got > retryMaxDelay {
+		t.Errorf("expected delay capped at %v, got %v", retryMaxDelay, got)
+	}
+	if got := retryDelay(0, 24*time.Hour); got != retryMaxDelay {
+		t.Errorf("expected Retry-After capped at %v, got %v", retryMaxDelay, got)
+	}
+}
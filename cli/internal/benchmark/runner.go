@@ -4,7 +4,11 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -16,6 +20,27 @@ import (
 type Runner struct {
 	db       *db.DB
 	registry *ProviderRegistry
+
+	// OutputDir, when set, receives each run's raw prompt and completion
+	// as <OutputDir>/<model>/<run>.txt
+	OutputDir string
+
+	// Concurrency caps how many completions are in flight at once, across
+	// all models of a suite. Zero or less runs them one at a time.
+	Concurrency int
+
+	// Tracer, when set, receives a span around every provider call
+	Tracer Tracer
+
+	// MaxRetries is how many times a completion that failed with a
+	// transient API error is retried. See WithRetries.
+	MaxRetries int
+
+	// WorkingTree, when set, is the project root whose prompt files are
+	// benchmarked for suites that pin no version, instead of the latest
+	// committed version. A result whose content differs from that version
+	// is marked Uncommitted.
+	WorkingTree string
 }
 
 // NewRunner creates a new benchmark runner
@@ -42,7 +67,7 @@ func (r *Runner) Run(ctx context.Context, suite *Suite) (*BenchmarkResult, error
 	}
 
 	// Get the prompt
-	p, err := r.db.GetPromptByName(suite.Prompt)
+	p, _, err := r.db.ResolvePromptName(suite.Prompt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get prompt: %w", err)
 	}
@@ -70,24 +95,43 @@ func (r *Runner) Run(ctx context.Context, suite *Suite) (*BenchmarkResult, error
 		}
 	}
 	result.Version = version.Version
+	result.VersionID = version.ID
+	content := version.Content
+
+	// Benchmark the prompt file as saved, if asked to
+	if r.WorkingTree != "" && suite.Version == "" {
+		data, err := os.ReadFile(filepath.Join(r.WorkingTree, p.FilePath))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt file: %w", err)
+		}
+		if string(data) != content {
+			content = string(data)
+			result.Uncommitted = true
+		}
+	}
 
 	// Parse the prompt template
-	parsed, err := prompt.Parse(version.Content)
+	parsed, err := prompt.Parse(content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse prompt: %w", err)
 	}
 
-	// Render the prompt with any variables
-	rendered, err := renderPrompt(parsed.Content, suite.Variables)
+	// Render the prompt with the suite's inputs, once for every run
+	rendered, err := renderPrompt(parsed.Content, suite.TemplateInputs())
 	if err != nil {
 		return nil, fmt.Errorf("failed to render prompt: %w", err)
 	}
 
-	// Run benchmarks for each model
-	for _, model := range suite.Models {
-		modelResult, runs := r.benchmarkModel(ctx, model, rendered, suite.RunsPerModel)
+	// Run benchmarks for each model, at each temperature of a sweep
+	modelResults, modelRuns := r.benchmarkModels(ctx, suite.Models, suite.Temperatures, rendered, suite.RunsPerModel)
+	for i, modelResult := range modelResults {
+		if r.OutputDir != "" {
+			if err := writeRunOutputs(r.OutputDir, modelResult, rendered, modelRuns[i]); err != nil {
+				return nil, err
+			}
+		}
 		result.Models = append(result.Models, modelResult)
-		result.Runs = append(result.Runs, runs...)
+		result.Runs = append(result.Runs, modelRuns[i]...)
 	}
 
 	result.DurationMs = time.Since(startTime).Milliseconds()
@@ -97,9 +141,130 @@ func (r *Runner) Run(ctx context.Context, suite *Suite) (*BenchmarkResult, error
 }
 
 func (r *Runner) benchmarkModel(ctx context.Context, model, prompt string, runs int) (ModelResult, []RunResult) {
+	results, runResults := r.benchmarkModels(ctx, []string{model}, nil, prompt, runs)
+	return results[0], runResults[0]
+}
+
+// defaultTemperature is used for every completion unless the suite sweeps
+// temperatures
+const defaultTemperature = 0.7
+
+// completion is the outcome of a single run of a model
+type completion struct {
+	resp *CompletionResponse
+	err  error
+}
+
+// modelGroup is the runs of a model at one temperature, aggregated together
+type modelGroup struct {
+	model       string
+	temperature *float64 // nil without a sweep
+	provider    Provider
+	providerErr error
+	completions []completion
+}
+
+// benchmarkModels runs every model the given number of times, at each of
+// temperatures if any are given, and returns an aggregate and the runs for
+// each (model, temperature) pair in model then temperature order.
+// Completions are started in that order, at most Concurrency at a time, and
+// each is stored in its own slot so the outcome does not depend on the order
+// they finish in.
+func (r *Runner) benchmarkModels(ctx context.Context, models []string, temperatures []float64, prompt string, runs int) ([]ModelResult, [][]RunResult) {
+	var groups []*modelGroup
+	for _, model := range models {
+		// Report the concrete model an alias stands for
+		model = r.registry.ResolveModel(model)
+		provider, err := r.registry.GetForModel(model)
+		if err == nil {
+			provider = WithRetries(provider, r.MaxRetries)
+		}
+		newGroup := func(temperature *float64) *modelGroup {
+			return &modelGroup{
+				model:       model,
+				temperature: temperature,
+				provider:    provider,
+				providerErr: err,
+				completions: make([]completion, runs),
+			}
+		}
+		if len(temperatures) == 0 {
+			groups = append(groups, newGroup(nil))
+			continue
+		}
+		for _, t := range temperatures {
+			groups = append(groups, newGroup(&t))
+		}
+	}
+
+	slots := make(chan struct{}, max(r.Concurrency, 1))
+	var wg sync.WaitGroup
+	for _, g := range groups {
+		if g.providerErr != nil {
+			continue
+		}
+		temperature := defaultTemperature
+		if g.temperature != nil {
+			temperature = *g.temperature
+		}
+		for run := 0; run < runs; run++ {
+			slots <- struct{}{}
+			wg.Add(1)
+			go func(g *modelGroup, run int) {
+				defer func() {
+					<-slots
+					wg.Done()
+				}()
+				resp, err := TracedComplete(ctx, r.Tracer, g.provider, CompletionRequest{
+					Model:       g.model,
+					Prompt:      prompt,
+					MaxTokens:   1024,
+					Temperature: temperature,
+				})
+				g.completions[run] = completion{resp: resp, err: err}
+			}(g, run)
+		}
+	}
+	wg.Wait()
+
+	results := make([]ModelResult, len(groups))
+	runResults := make([][]RunResult, len(groups))
+	for i, g := range groups {
+		if g.providerErr != nil {
+			results[i], runResults[i] = unavailableModel(g, runs)
+			continue
+		}
+		results[i], runResults[i] = aggregateModel(g)
+	}
+	return results, runResults
+}
+
+// unavailableModel reports every run of a model with no provider as failed
+func unavailableModel(g *modelGroup, runs int) (ModelResult, []RunResult) {
 	result := ModelResult{
-		Model: model,
-		Runs:  runs,
+		Model:       g.model,
+		Temperature: g.temperature,
+		Runs:        runs,
+		Errors:      runs,
+		ErrorRate:   1.0,
+	}
+	runResults := make([]RunResult, 0, runs)
+	for i := 0; i < runs; i++ {
+		runResults = append(runResults, RunResult{
+			Model:       g.model,
+			Temperature: g.temperature,
+			Error:       g.providerErr.Error(),
+		})
+	}
+	return result, runResults
+}
+
+func aggregateModel(g *modelGroup) (ModelResult, []RunResult) {
+	runs := len(g.completions)
+	result := ModelResult{
+		Model:       g.model,
+		Temperature: g.temperature,
+		Runs:        runs,
 	}
 
 	runResults := make([]RunResult, 0, runs)
@@ -108,35 +273,14 @@ func (r *Runner) benchmarkModel(ctx context.Context, model, prompt string, runs
 	var totalCost float64
 	var promptTokens int
 
-	provider, err := r.registry.GetForModel(model)
-	if err != nil {
-		// No provider registered, return empty results
-		result.Errors = runs
-		result.ErrorRate = 1.0
-		for i := 0; i < runs; i++ {
-			runResults = append(runResults, RunResult{
-				Model: model,
-				Error: err.Error(),
-			})
-		}
-		return result, runResults
-	}
-
-	for i := 0; i < runs; i++ {
-		req := CompletionRequest{
-			Model:       model,
-			Prompt:      prompt,
-			MaxTokens:   1024,
-			Temperature: 0.7,
-		}
+	for _, c := range g.completions {
+		runResult := RunResult{Model: g.model, Temperature: g.temperature}
 
-		resp, err := provider.Complete(ctx, req)
-		runResult := RunResult{Model: model}
-
-		if err != nil {
-			runResult.Error = err.Error()
+		if c.err != nil {
+			runResult.Error = c.err.Error()
 			errors++
 		} else {
+			resp := c.resp
 			runResult.LatencyMs = resp.LatencyMs
 			runResult.PromptTokens = resp.PromptTokens
 			runResult.OutputTokens = resp.OutputTokens
@@ -159,6 +303,7 @@ func (r *Runner) benchmarkModel(ctx context.Context, model, prompt string, runs
 		// Calculate percentiles
 		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
 		result.LatencyP50Ms = float64(percentile(latencies, 50))
+		result.LatencyP95Ms = float64(percentile(latencies, 95))
 		result.LatencyP99Ms = float64(percentile(latencies, 99))
 		result.LatencyAvgMs = avg(latencies)
 
@@ -170,11 +315,61 @@ func (r *Runner) benchmarkModel(ctx context.Context, model, prompt string, runs
 	}
 
 	result.Errors = errors
-	result.ErrorRate = float64(errors) / float64(runs)
+	if runs > 0 {
+		result.ErrorRate = float64(errors) / float64(runs)
+	}
 
 	return result, runResults
 }
 
+// writeRunOutputs persists the raw prompt and completion of each run so
+// model outputs can be inspected after the benchmark finishes. Runs of a
+// temperature sweep go in a t<temperature> directory under the model's.
+func writeRunOutputs(dir string, model ModelResult, prompt string, runs []RunResult) error {
+	modelDir, err := modelOutputDir(dir, model.Model)
+	if err != nil {
+		return err
+	}
+	if model.Temperature != nil {
+		modelDir = filepath.Join(modelDir, fmt.Sprintf("t%g", *model.Temperature))
+	}
+	if err := os.MkdirAll(modelDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for i, run := range runs {
+		var buf bytes.Buffer
+		buf.WriteString("=== PROMPT ===\n")
+		buf.WriteString(prompt)
+		buf.WriteString("\n\n=== COMPLETION ===\n")
+		if run.Error != "" {
+			buf.WriteString("ERROR: " + run.Error)
+		} else {
+			buf.WriteString(run.Output)
+		}
+		buf.WriteString("\n")
+
+		path := filepath.Join(modelDir, fmt.Sprintf("%d.txt", i+1))
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write run output: %w", err)
+		}
+	}
+	return nil
+}
+
+// modelOutputDir returns the directory in dir for a model's run outputs.
+// Path separators in the name are replaced, and the result is checked to
+// stay inside dir, since model names come from suite files.
+func modelOutputDir(dir, model string) (string, error) {
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(model)
+	path := filepath.Join(dir, name)
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("model '%s' does not name a directory inside %s", model, dir)
+	}
+	return path, nil
+}
+
 func renderPrompt(tmplBody string, vars map[string]any) (string, error) {
 	if vars == nil || len(vars) == 0 {
 		return tmplBody, nil
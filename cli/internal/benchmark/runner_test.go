@@ -3,7 +3,15 @@ package benchmark
 import (
 	"context"
 	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/promptsmith/cli/internal/db"
 )
 
 func TestPercentile(t *testing.T) {
@@ -152,6 +160,7 @@ type mockBenchmarkProvider struct {
 	responses []*CompletionResponse
 	errors    []error
 	callCount int
+	prompts   []string
 }
 
 func (m *mockBenchmarkProvider) Name() string {
@@ -169,6 +178,7 @@ func (m *mockBenchmarkProvider) SupportsModel(model string) bool {
 func (m *mockBenchmarkProvider) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
 	idx := m.callCount
 	m.callCount++
+	m.prompts = append(m.prompts, req.Prompt)
 
 	if idx < len(m.errors) && m.errors[idx] != nil {
 		return nil, m.errors[idx]
@@ -302,6 +312,242 @@ func TestBenchmarkModelCostCalculation(t *testing.T) {
 	}
 }
 
+func TestBenchmarkModelLatencyStats(t *testing.T) {
+	// Latencies 10ms..200ms in steps of 10, shuffled, with known costs
+	var responses []*CompletionResponse
+	for _, i := range []int{7, 19, 2, 14, 0, 11, 5, 17, 9, 3, 15, 1, 12, 18, 6, 10, 4, 16, 8, 13} {
+		responses = append(responses, &CompletionResponse{
+			LatencyMs:    int64(i+1) * 10,
+			PromptTokens: 40,
+			OutputTokens: i + 1,
+			TotalTokens:  40 + i + 1,
+			Cost:         0.001,
+		})
+	}
+	registry := NewProviderRegistry()
+	registry.Register(&mockBenchmarkProvider{responses: responses})
+
+	runner := NewRunner(nil, registry)
+	m, _ := runner.benchmarkModel(context.Background(), "gpt-4o", "test prompt", len(responses))
+
+	if m.LatencyP50Ms != 110 || m.LatencyP95Ms != 200 || m.LatencyP99Ms != 200 {
+		t.Errorf("p50/p95/p99 = %v/%v/%v, want 110/200/200", m.LatencyP50Ms, m.LatencyP95Ms, m.LatencyP99Ms)
+	}
+	if m.LatencyAvgMs != 105 {
+		t.Errorf("mean latency = %v, want 105", m.LatencyAvgMs)
+	}
+	if m.OutputTokensAvg != 10.5 || m.TotalTokensAvg != 50.5 || m.PromptTokens != 40 {
+		t.Errorf("token averages = %v output, %v total, %d prompt", m.OutputTokensAvg, m.TotalTokensAvg, m.PromptTokens)
+	}
+	if math.Abs(m.TotalCost-0.02) > 1e-9 || math.Abs(m.CostPerRequest-0.001) > 1e-9 {
+		t.Errorf("cost = %v total, %v per request, want 0.02 and 0.001", m.TotalCost, m.CostPerRequest)
+	}
+}
+
+func TestBenchmarkModelSingleRunStats(t *testing.T) {
+	registry := NewProviderRegistry()
+	registry.Register(&mockBenchmarkProvider{responses: []*CompletionResponse{{LatencyMs: 321, Cost: 0.004}}})
+
+	runner := NewRunner(nil, registry)
+	m, _ := runner.benchmarkModel(context.Background(), "gpt-4o", "test prompt", 1)
+
+	for name, got := range map[string]float64{"p50": m.LatencyP50Ms, "p95": m.LatencyP95Ms, "p99": m.LatencyP99Ms, "mean": m.LatencyAvgMs} {
+		if got != 321 {
+			t.Errorf("%s = %v, want the single run's 321", name, got)
+		}
+	}
+	if m.TotalCost != 0.004 || m.CostPerRequest != 0.004 {
+		t.Errorf("cost = %v total, %v per request, want 0.004", m.TotalCost, m.CostPerRequest)
+	}
+}
+
+// concurrentProvider is safe for concurrent use and records how many
+// completions were in flight at once. As "ollama" supporting every model,
+// the registry routes all models to it.
+type concurrentProvider struct {
+	mu          sync.Mutex
+	calls       map[string]int
+	inFlight    int
+	maxInFlight int
+}
+
+func (p *concurrentProvider) Name() string                { return "ollama" }
+func (p *concurrentProvider) Models() []string            { return nil }
+func (p *concurrentProvider) SupportsModel(_ string) bool { return true }
+
+func (p *concurrentProvider) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	p.mu.Lock()
+	p.calls[req.Model]++
+	call := p.calls[req.Model]
+	p.inFlight++
+	p.maxInFlight = max(p.maxInFlight, p.inFlight)
+	p.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	p.mu.Lock()
+	p.inFlight--
+	p.mu.Unlock()
+
+	// Every other call of the flaky model fails
+	if req.Model == "flaky" && call%2 == 0 {
+		return nil, fmt.Errorf("rate limited")
+	}
+	return &CompletionResponse{
+		Content:     req.Model,
+		LatencyMs:   int64(len(req.Model)) * 10,
+		TotalTokens: 100,
+		Cost:        0.001,
+	}, nil
+}
+
+func TestBenchmarkModelsConcurrency(t *testing.T) {
+	models := []string{"gpt-4o", "claude-sonnet", "flaky"}
+
+	for _, concurrency := range []int{0, 1, 4} {
+		t.Run(fmt.Sprintf("concurrency %d", concurrency), func(t *testing.T) {
+			provider := &concurrentProvider{calls: map[string]int{}}
+			registry := NewProviderRegistry()
+			registry.Register(provider)
+			runner := NewRunner(nil, registry)
+			runner.Concurrency = concurrency
+
+			results, runs := runner.benchmarkModels(context.Background(), models, nil, "test prompt", 5)
+
+			if len(results) != len(models) || len(runs) != len(models) {
+				t.Fatalf("expected results for %d models, got %d and %d", len(models), len(results), len(runs))
+			}
+			for i, model := range models {
+				if results[i].Model != model {
+					t.Errorf("result %d: expected model %s, got %s", i, model, results[i].Model)
+				}
+				if provider.calls[model] != 5 {
+					t.Errorf("%s: expected 5 completions, got %d", model, provider.calls[model])
+				}
+				if len(runs[i]) != 5 {
+					t.Errorf("%s: expected 5 runs, got %d", model, len(runs[i]))
+				}
+				for _, run := range runs[i] {
+					if run.Model != model {
+						t.Errorf("%s: run grouped under the wrong model: %+v", model, run)
+					}
+				}
+			}
+
+			if results[0].Errors != 0 || results[0].TotalCost < 0.0049 || results[0].LatencyP50Ms != 60 {
+				t.Errorf("unexpected gpt-4o aggregate: %+v", results[0])
+			}
+			// Calls 2 and 4 of 5 fail
+			if results[2].Errors != 2 || results[2].ErrorRate != 0.4 {
+				t.Errorf("expected 2 flaky errors, got %+v", results[2])
+			}
+
+			limit := max(concurrency, 1)
+			if provider.maxInFlight > limit {
+				t.Errorf("expected at most %d completions in flight, saw %d", limit, provider.maxInFlight)
+			}
+			if limit > 1 && provider.maxInFlight < 2 {
+				t.Errorf("expected completions to overlap with concurrency %d", concurrency)
+			}
+		})
+	}
+}
+
+// temperatureProvider records the temperature of every completion per model
+type temperatureProvider struct {
+	mu    sync.Mutex
+	calls map[string][]float64
+}
+
+func (p *temperatureProvider) Name() string                { return "ollama" }
+func (p *temperatureProvider) Models() []string            { return nil }
+func (p *temperatureProvider) SupportsModel(_ string) bool { return true }
+
+func (p *temperatureProvider) Complete(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	p.mu.Lock()
+	p.calls[req.Model] = append(p.calls[req.Model], req.Temperature)
+	p.mu.Unlock()
+
+	// Latency tracks temperature so each group's aggregate is distinct
+	return &CompletionResponse{
+		Content:     fmt.Sprintf("%s at %g", req.Model, req.Temperature),
+		LatencyMs:   100 + int64(req.Temperature*100),
+		TotalTokens: 100,
+	}, nil
+}
+
+func TestBenchmarkModelsTemperatureSweep(t *testing.T) {
+	suite, err := ParseSuite([]byte(`name: sweep
+prompt: test
+models: [gpt-4o, claude-sonnet]
+runs_per_model: 2
+temperatures: [0.0, 0.5, 1.0]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	provider := &temperatureProvider{calls: map[string][]float64{}}
+	registry := NewProviderRegistry()
+	registry.Register(provider)
+	runner := NewRunner(nil, registry)
+	runner.Concurrency = 4
+
+	results, runs := runner.benchmarkModels(context.Background(), suite.Models, suite.Temperatures, "test prompt", suite.RunsPerModel)
+
+	for _, model := range suite.Models {
+		if len(provider.calls[model]) != 6 {
+			t.Errorf("%s: expected 6 completions, got %d", model, len(provider.calls[model]))
+		}
+	}
+	if len(results) != 6 || len(runs) != 6 {
+		t.Fatalf("expected 6 (model, temperature) groups, got %d and %d", len(results), len(runs))
+	}
+
+	i := 0
+	for _, model := range suite.Models {
+		for _, temp := range suite.Temperatures {
+			r := results[i]
+			if r.Model != model || r.Temperature == nil || *r.Temperature != temp {
+				t.Errorf("group %d: expected %s at %g, got %+v", i, model, temp, r)
+			}
+			if r.Runs != 2 || r.LatencyP50Ms != 100+temp*100 {
+				t.Errorf("group %d: unexpected aggregate %+v", i, r)
+			}
+			for _, run := range runs[i] {
+				if run.Model != model || run.Temperature == nil || *run.Temperature != temp {
+					t.Errorf("group %d: run tagged wrongly: %+v", i, run)
+				}
+				if !r.Includes(run) {
+					t.Errorf("group %d: aggregate does not include its run %+v", i, run)
+				}
+			}
+			i++
+		}
+	}
+	if results[1].Label() != "gpt-4o t=0.5" {
+		t.Errorf("unexpected label %q", results[1].Label())
+	}
+}
+
+func TestBenchmarkModelsDefaultTemperature(t *testing.T) {
+	provider := &temperatureProvider{calls: map[string][]float64{}}
+	registry := NewProviderRegistry()
+	registry.Register(provider)
+	runner := NewRunner(nil, registry)
+
+	results, runs := runner.benchmarkModels(context.Background(), []string{"gpt-4o"}, nil, "test prompt", 2)
+
+	if results[0].Temperature != nil || runs[0][0].Temperature != nil {
+		t.Errorf("expected no temperature tag without a sweep: %+v", results[0])
+	}
+	for _, temp := range provider.calls["gpt-4o"] {
+		if temp != defaultTemperature {
+			t.Errorf("expected default temperature %g, got %g", defaultTemperature, temp)
+		}
+	}
+}
+
 func TestPercentileEdgeCases(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -323,3 +569,199 @@ func TestPercentileEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestRunWritesRawOutputs(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := db.Initialize(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to initialize db: %v", err)
+	}
+	defer database.Close()
+
+	project, _ := database.CreateProject("test-project")
+	p, _ := database.CreatePrompt(project.ID, "greeter", "", "prompts/greeter.prompt")
+	database.CreateVersion(p.ID, "1.0.0", "Say hello to {{.name}}", "[]", "{}", "Initial", "test", nil)
+
+	registry := NewProviderRegistry()
+	registry.Register(&mockBenchmarkProvider{
+		responses: []*CompletionResponse{
+			{Content: "Hello, Ada!", LatencyMs: 100},
+			{Content: "Hi Ada.", LatencyMs: 120},
+		},
+	})
+
+	outDir := filepath.Join(tmpDir, "out")
+	runner := NewRunner(database, registry)
+	runner.OutputDir = outDir
+
+	suite := &Suite{
+		Name:         "greeter-bench",
+		Prompt:       "greeter",
+		Models:       []string{"gpt-4o"},
+		RunsPerModel: 2,
+		Variables:    map[string]any{"name": "Ada"},
+	}
+	if _, err := runner.Run(context.Background(), suite); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	for i, want := range []string{"Hello, Ada!", "Hi Ada."} {
+		data, err := os.ReadFile(filepath.Join(outDir, "gpt-4o", fmt.Sprintf("%d.txt", i+1)))
+		if err != nil {
+			t.Fatalf("expected output file for run %d: %v", i+1, err)
+		}
+		content := string(data)
+		if !strings.Contains(content, "Say hello to Ada") {
+			t.Errorf("run %d: expected rendered prompt in output file, got %q", i+1, content)
+		}
+		if !strings.Contains(content, want) {
+			t.Errorf("run %d: expected completion %q in output file, got %q", i+1, want, content)
+		}
+	}
+}
+
+func TestRunOutputsStayInOutputDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := db.Initialize(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to initialize db: %v", err)
+	}
+	defer database.Close()
+
+	project, _ := database.CreateProject("test-project")
+	p, _ := database.CreatePrompt(project.ID, "greeter", "", "prompts/greeter.prompt")
+	database.CreateVersion(p.ID, "1.0.0", "Say hello", "[]", "{}", "Initial", "test", nil)
+
+	registry := NewProviderRegistry()
+	registry.Register(&mockBenchmarkProvider{})
+
+	outDir := filepath.Join(tmpDir, "out")
+	runner := NewRunner(database, registry)
+	runner.OutputDir = outDir
+
+	suite := &Suite{
+		Name:         "greeter-bench",
+		Prompt:       "greeter",
+		Models:       []string{".."},
+		RunsPerModel: 1,
+	}
+	if _, err := runner.Run(context.Background(), suite); err == nil || !strings.Contains(err.Error(), "does not name a directory") {
+		t.Errorf("expected a model name escaping the output directory to be refused, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "1.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected nothing written outside the output directory, got %v", err)
+	}
+
+	for model, want := range map[string]string{
+		"openai/gpt-4o": "openai_gpt-4o",
+		"../etc":        ".._etc",
+		`..\etc`:        ".._etc",
+	} {
+		got, err := modelOutputDir(outDir, model)
+		if err != nil || got != filepath.Join(outDir, want) {
+			t.Errorf("modelOutputDir(%q) = %q, %v; want %q", model, got, err, filepath.Join(outDir, want))
+		}
+	}
+	for _, model := range []string{"", ".", ".."} {
+		if _, err := modelOutputDir(outDir, model); err == nil {
+			t.Errorf("expected modelOutputDir(%q) to be refused", model)
+		}
+	}
+}
+
+func TestRunRendersSuiteInputs(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := db.Initialize(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to initialize db: %v", err)
+	}
+	defer database.Close()
+
+	project, _ := database.CreateProject("test-project")
+	p, _ := database.CreatePrompt(project.ID, "summarizer", "", "prompts/summarizer.prompt")
+	database.CreateVersion(p.ID, "1.0.0", "Summarize in {{.points}} points: {{.text}}", "[]", "{}", "Initial", "test", nil)
+
+	provider := &mockBenchmarkProvider{}
+	registry := NewProviderRegistry()
+	registry.Register(provider)
+
+	suite, err := ParseSuite([]byte(`name: summarizer-bench
+prompt: summarizer
+models: [gpt-4o]
+runs_per_model: 2
+variables:
+  points: 3
+  text: overridden
+inputs:
+  text: The quick brown fox.
+`))
+	if err != nil {
+		t.Fatalf("ParseSuite failed: %v", err)
+	}
+	if _, err := NewRunner(database, registry).Run(context.Background(), suite); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(provider.prompts) != 2 {
+		t.Fatalf("expected 2 completions, got %d", len(provider.prompts))
+	}
+	for i, prompt := range provider.prompts {
+		if prompt != "Summarize in 3 points: The quick brown fox." {
+			t.Errorf("run %d: unexpected prompt %q", i+1, prompt)
+		}
+	}
+}
+
+func TestRunBenchmarksWorkingTree(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := db.Initialize(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to initialize db: %v", err)
+	}
+	defer database.Close()
+
+	project, _ := database.CreateProject("test-project")
+	p, _ := database.CreatePrompt(project.ID, "greeting", "", "prompts/greeting.prompt")
+	database.CreateVersion(p.ID, "1.0.0", "Hello", "[]", "{}", "Initial", "test", nil)
+	os.MkdirAll(filepath.Join(tmpDir, "prompts"), 0755)
+	promptFile := filepath.Join(tmpDir, "prompts", "greeting.prompt")
+
+	run := func(workingTree, version string) (*BenchmarkResult, string) {
+		t.Helper()
+		provider := &mockBenchmarkProvider{}
+		registry := NewProviderRegistry()
+		registry.Register(provider)
+		runner := NewRunner(database, registry)
+		runner.WorkingTree = workingTree
+		suite := &Suite{Name: "greeting-bench", Prompt: "greeting", Version: version, Models: []string{"gpt-4o"}, RunsPerModel: 1}
+		result, err := runner.Run(context.Background(), suite)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		return result, provider.prompts[0]
+	}
+
+	os.WriteFile(promptFile, []byte("Hello, edited"), 0644)
+
+	// Without a working tree the committed version runs
+	if result, prompt := run("", ""); prompt != "Hello" || result.Uncommitted {
+		t.Errorf("expected the committed content, got %q (uncommitted %v)", prompt, result.Uncommitted)
+	}
+
+	// With one, the saved edits run, based on the latest version
+	result, prompt := run(tmpDir, "")
+	if prompt != "Hello, edited" || !result.Uncommitted || result.Version != "1.0.0" {
+		t.Errorf("expected the edited file based on 1.0.0, got %q (uncommitted %v, version %s)", prompt, result.Uncommitted, result.Version)
+	}
+
+	// A pinned version still runs the committed content
+	if result, prompt := run(tmpDir, "1.0.0"); prompt != "Hello" || result.Uncommitted {
+		t.Errorf("expected the pinned version's content, got %q (uncommitted %v)", prompt, result.Uncommitted)
+	}
+
+	// A file matching the latest version is not uncommitted
+	os.WriteFile(promptFile, []byte("Hello"), 0644)
+	if result, _ := run(tmpDir, ""); result.Uncommitted {
+		t.Error("expected an unchanged file not to be marked uncommitted")
+	}
+}
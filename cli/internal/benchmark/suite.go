@@ -16,8 +16,10 @@ type Suite struct {
 	Models       []string       `yaml:"models" json:"models"`
 	Dataset      string         `yaml:"dataset,omitempty" json:"dataset,omitempty"`
 	RunsPerModel int            `yaml:"runs_per_model,omitempty" json:"runs_per_model,omitempty"`
+	Temperatures []float64      `yaml:"temperatures,omitempty" json:"temperatures,omitempty"`
 	Metrics      []Metric       `yaml:"metrics,omitempty" json:"metrics,omitempty"`
 	Variables    map[string]any `yaml:"variables,omitempty" json:"variables,omitempty"`
+	Inputs       map[string]any `yaml:"inputs,omitempty" json:"inputs,omitempty"`
 }
 
 // Metric defines what to measure in the benchmark
@@ -45,30 +47,33 @@ const (
 
 // ModelResult holds benchmark results for a single model
 type ModelResult struct {
-	Model           string  `json:"model"`
-	Runs            int     `json:"runs"`
-	LatencyP50Ms    float64 `json:"latency_p50_ms"`
-	LatencyP99Ms    float64 `json:"latency_p99_ms"`
-	LatencyAvgMs    float64 `json:"latency_avg_ms"`
-	TotalTokensAvg  float64 `json:"total_tokens_avg"`
-	PromptTokens    int     `json:"prompt_tokens"`
-	OutputTokensAvg float64 `json:"output_tokens_avg"`
-	CostPerRequest  float64 `json:"cost_per_request"`
-	TotalCost       float64 `json:"total_cost"`
-	Errors          int     `json:"errors"`
-	ErrorRate       float64 `json:"error_rate"`
+	Model           string   `json:"model"`
+	Temperature     *float64 `json:"temperature,omitempty"` // set when the suite sweeps temperatures
+	Runs            int      `json:"runs"`
+	LatencyP50Ms    float64  `json:"latency_p50_ms"`
+	LatencyP95Ms    float64  `json:"latency_p95_ms"`
+	LatencyP99Ms    float64  `json:"latency_p99_ms"`
+	LatencyAvgMs    float64  `json:"latency_avg_ms"`
+	TotalTokensAvg  float64  `json:"total_tokens_avg"`
+	PromptTokens    int      `json:"prompt_tokens"`
+	OutputTokensAvg float64  `json:"output_tokens_avg"`
+	CostPerRequest  float64  `json:"cost_per_request"`
+	TotalCost       float64  `json:"total_cost"`
+	Errors          int      `json:"errors"`
+	ErrorRate       float64  `json:"error_rate"`
 }
 
 // RunResult holds individual run data
 type RunResult struct {
-	Model        string  `json:"model"`
-	LatencyMs    int64   `json:"latency_ms"`
-	PromptTokens int     `json:"prompt_tokens"`
-	OutputTokens int     `json:"output_tokens"`
-	TotalTokens  int     `json:"total_tokens"`
-	Cost         float64 `json:"cost"`
-	Output       string  `json:"output,omitempty"`
-	Error        string  `json:"error,omitempty"`
+	Model        string   `json:"model"`
+	Temperature  *float64 `json:"temperature,omitempty"`
+	LatencyMs    int64    `json:"latency_ms"`
+	PromptTokens int      `json:"prompt_tokens"`
+	OutputTokens int      `json:"output_tokens"`
+	TotalTokens  int      `json:"total_tokens"`
+	Cost         float64  `json:"cost"`
+	Output       string   `json:"output,omitempty"`
+	Error        string   `json:"error,omitempty"`
 }
 
 // BenchmarkResult holds the complete benchmark results
@@ -76,6 +81,8 @@ type BenchmarkResult struct {
 	SuiteName   string        `json:"suite_name"`
 	PromptName  string        `json:"prompt_name"`
 	Version     string        `json:"version"`
+	VersionID   string        `json:"version_id,omitempty"`
+	Uncommitted bool          `json:"uncommitted,omitempty"` // Ran the prompt file's uncommitted edits to Version
 	Models      []ModelResult `json:"models"`
 	Runs        []RunResult   `json:"runs,omitempty"`
 	DurationMs  int64         `json:"duration_ms"`
@@ -120,6 +127,71 @@ func ParseSuite(data []byte) (*Suite, error) {
 			return nil, fmt.Errorf("model at index %d is empty", i)
 		}
 	}
+	if err := CheckTemperatures(suite.Temperatures); err != nil {
+		return nil, err
+	}
 
 	return &suite, nil
 }
+
+// ParseInputsFile reads prompt variable values from a YAML or JSON file
+// holding a single mapping of names to values
+func ParseInputsFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inputs file: %w", err)
+	}
+	var inputs map[string]any
+	if err := yaml.Unmarshal(data, &inputs); err != nil {
+		return nil, fmt.Errorf("failed to parse inputs file: %w", err)
+	}
+	return inputs, nil
+}
+
+// TemplateInputs returns the values the prompt is rendered with: the suite's
+// variables, overridden by its inputs
+func (s *Suite) TemplateInputs() map[string]any {
+	if len(s.Inputs) == 0 {
+		return s.Variables
+	}
+	inputs := make(map[string]any, len(s.Variables)+len(s.Inputs))
+	for k, v := range s.Variables {
+		inputs[k] = v
+	}
+	for k, v := range s.Inputs {
+		inputs[k] = v
+	}
+	return inputs
+}
+
+// CheckTemperatures rejects temperature sweeps providers would refuse
+func CheckTemperatures(temperatures []float64) error {
+	seen := map[float64]bool{}
+	for _, t := range temperatures {
+		if t < 0 || t > 2 {
+			return fmt.Errorf("temperature %g is out of range (expected 0 to 2)", t)
+		}
+		if seen[t] {
+			return fmt.Errorf("temperature %g is listed twice", t)
+		}
+		seen[t] = true
+	}
+	return nil
+}
+
+// Label names the model in tables and comparisons, with its temperature
+// when the suite sweeps them
+func (m ModelResult) Label() string {
+	if m.Temperature == nil {
+		return m.Model
+	}
+	return fmt.Sprintf("%s t=%g", m.Model, *m.Temperature)
+}
+
+// Includes reports whether run is one of the runs m aggregates
+func (m ModelResult) Includes(run RunResult) bool {
+	if run.Model != m.Model || (run.Temperature == nil) != (m.Temperature == nil) {
+		return false
+	}
+	return m.Temperature == nil || *run.Temperature == *m.Temperature
+}
@@ -188,6 +188,70 @@ models:
 	}
 }
 
+func TestParseInputsFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "inputs.json")
+	if err := os.WriteFile(path, []byte(`{"text": "hello", "points": 3}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	inputs, err := ParseInputsFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inputs["text"] != "hello" || inputs["points"] != 3 {
+		t.Errorf("unexpected inputs: %v", inputs)
+	}
+
+	if err := os.WriteFile(path, []byte("- not\n- a mapping\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseInputsFile(path); err == nil {
+		t.Error("expected error for a list")
+	}
+	if _, err := ParseInputsFile(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
+
+func TestSuiteTemplateInputs(t *testing.T) {
+	suite := &Suite{
+		Variables: map[string]any{"text": "old", "points": 3},
+		Inputs:    map[string]any{"text": "new"},
+	}
+	inputs := suite.TemplateInputs()
+	if inputs["text"] != "new" || inputs["points"] != 3 {
+		t.Errorf("expected inputs layered over variables, got %v", inputs)
+	}
+	if suite.Variables["text"] != "old" {
+		t.Error("expected suite variables to be left alone")
+	}
+}
+
+func TestParseSuiteTemperatures(t *testing.T) {
+	yaml := `name: test
+prompt: test
+models:
+  - gpt-4o
+temperatures: [0.0, 0.5, 1.0]
+`
+	suite, err := ParseSuite([]byte(yaml))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(suite.Temperatures) != 3 || suite.Temperatures[0] != 0 || suite.Temperatures[1] != 0.5 || suite.Temperatures[2] != 1 {
+		t.Errorf("expected temperatures [0 0.5 1], got %v", suite.Temperatures)
+	}
+
+	for _, temps := range []string{"[0.5, 0.5]", "[-0.1]", "[2.5]"} {
+		_, err := ParseSuite([]byte("name: test\nprompt: test\nmodels: [gpt-4o]\ntemperatures: " + temps + "\n"))
+		if err == nil {
+			t.Errorf("expected error for temperatures %s", temps)
+		}
+	}
+}
+
 func TestParseSuiteWithVariables(t *testing.T) {
 	yaml := `name: test
 prompt: test
@@ -0,0 +1,65 @@
+package benchmark
+
+import (
+	"context"
+	"time"
+)
+
+// SpanName names the span traced around each provider call
+const SpanName = "llm.complete"
+
+// Tracer starts a span around each provider call. It follows the shape of
+// an OpenTelemetry tracer without depending on one, so an adapter can hand
+// the spans to any tracing backend.
+type Tracer interface {
+	// Start opens a span for a call to model through provider. The returned
+	// context carries the span and is passed on to the provider.
+	Start(ctx context.Context, name string, attrs SpanStart) (context.Context, Span)
+}
+
+// Span is a single traced provider call
+type Span interface {
+	// End closes the span with what the call returned
+	End(attrs SpanEnd)
+}
+
+// SpanStart describes a provider call as it begins
+type SpanStart struct {
+	Provider string
+	Model    string
+}
+
+// SpanEnd describes how a provider call finished. Tokens are zero and Err
+// is set when the call failed.
+type SpanEnd struct {
+	PromptTokens int
+	OutputTokens int
+	TotalTokens  int
+	// LatencyMs is the latency the provider reported, or the measured
+	// duration of the call when it failed
+	LatencyMs int64
+	Err       error
+}
+
+// TracedComplete calls p.Complete inside a span started by tracer. With a
+// nil tracer it is p.Complete.
+func TracedComplete(ctx context.Context, tracer Tracer, p Provider, req CompletionRequest) (*CompletionResponse, error) {
+	if tracer == nil {
+		return p.Complete(ctx, req)
+	}
+
+	ctx, span := tracer.Start(ctx, SpanName, SpanStart{Provider: p.Name(), Model: req.Model})
+	start := time.Now()
+	resp, err := p.Complete(ctx, req)
+	if err != nil {
+		span.End(SpanEnd{LatencyMs: time.Since(start).Milliseconds(), Err: err})
+		return nil, err
+	}
+	span.End(SpanEnd{
+		PromptTokens: resp.PromptTokens,
+		OutputTokens: resp.OutputTokens,
+		TotalTokens:  resp.TotalTokens,
+		LatencyMs:    resp.LatencyMs,
+	})
+	return resp, nil
+}
@@ -0,0 +1,89 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+type recordedSpan struct {
+	name  string
+	start SpanStart
+	end   *SpanEnd
+}
+
+// recordingTracer keeps every span it starts. It is safe for concurrent use.
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs SpanStart) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := &recordedSpan{name: name, start: attrs}
+	t.spans = append(t.spans, s)
+	return ctx, &recordingSpan{tracer: t, span: s}
+}
+
+type recordingSpan struct {
+	tracer *recordingTracer
+	span   *recordedSpan
+}
+
+func (s *recordingSpan) End(attrs SpanEnd) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.span.end = &attrs
+}
+
+func TestRunnerTracesProviderCalls(t *testing.T) {
+	registry := NewProviderRegistry()
+	registry.Register(&concurrentProvider{calls: map[string]int{}})
+	tracer := &recordingTracer{}
+
+	runner := NewRunner(nil, registry)
+	runner.Concurrency = 3
+	runner.Tracer = tracer
+	runner.benchmarkModels(context.Background(), []string{"gpt-4o", "flaky"}, nil, "test prompt", 4)
+
+	if len(tracer.spans) != 8 {
+		t.Fatalf("expected a span per provider call (8), got %d", len(tracer.spans))
+	}
+	perModel := map[string]int{}
+	failed := 0
+	for _, s := range tracer.spans {
+		if s.name != SpanName || s.start.Provider != "ollama" {
+			t.Errorf("unexpected span start: %s %+v", s.name, s.start)
+		}
+		if s.end == nil {
+			t.Fatalf("span for %s was never ended", s.start.Model)
+		}
+		perModel[s.start.Model]++
+		if s.end.Err != nil {
+			failed++
+			continue
+		}
+		if s.end.TotalTokens != 100 || s.end.LatencyMs != int64(len(s.start.Model))*10 {
+			t.Errorf("unexpected span end for %s: %+v", s.start.Model, s.end)
+		}
+	}
+	if perModel["gpt-4o"] != 4 || perModel["flaky"] != 4 {
+		t.Errorf("expected 4 spans per model, got %v", perModel)
+	}
+	if failed != 2 {
+		t.Errorf("expected the 2 failed calls to end with an error, got %d", failed)
+	}
+}
+
+func TestTracedCompleteWithoutTracer(t *testing.T) {
+	provider := &mockBenchmarkProvider{errors: []error{errors.New("boom")}}
+	if _, err := TracedComplete(context.Background(), nil, provider, CompletionRequest{Model: "gpt-4o"}); err == nil {
+		t.Error("expected the provider error without a tracer")
+	}
+	resp, err := TracedComplete(context.Background(), nil, provider, CompletionRequest{Model: "gpt-4o"})
+	if err != nil || resp.Content != "default response" {
+		t.Errorf("expected the provider response without a tracer, got %v, %v", resp, err)
+	}
+}
@@ -0,0 +1,360 @@
+// Package condition parses and evaluates the conditions that decide whether
+// a chain step runs. A condition is a small expression over the chain's
+// inputs and earlier steps' outputs, for example:
+//
+//	steps.classify.output == "spam"
+//	contains(steps.review.output, "yes") && !startsWith(input.lang, "en")
+//
+// Values are strings: quoted literals, steps.KEY.output and input.KEY.
+// They can be compared with == and !=, tested with contains, startsWith and
+// endsWith, and combined with &&, || and ! and parentheses. Nothing else is
+// accepted, so evaluating a condition cannot run arbitrary code.
+package condition
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr is a parsed condition
+type Expr struct {
+	root node
+	src  string
+}
+
+// Parse parses a condition
+func Parse(src string) (*Expr, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", src, err)
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %s", p.tokens[p.pos])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", src, err)
+	}
+	return &Expr{root: root, src: src}, nil
+}
+
+// String returns the condition as it was written
+func (e *Expr) String() string {
+	return e.src
+}
+
+// StepRefs returns the output keys of the steps the condition refers to
+func (e *Expr) StepRefs() []string {
+	var keys []string
+	e.root.refs(&keys)
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// Eval evaluates the condition against the chain's inputs and the outputs
+// of the steps run so far, keyed by output key. A missing input or output
+// is empty. Step outputs are compared with surrounding whitespace trimmed,
+// since models often end a one-word answer with a newline.
+func (e *Expr) Eval(inputs, stepOutputs map[string]string) bool {
+	return e.root.eval(&env{inputs: inputs, stepOutputs: stepOutputs})
+}
+
+type env struct {
+	inputs      map[string]string
+	stepOutputs map[string]string
+}
+
+// node is a boolean expression
+type node interface {
+	eval(e *env) bool
+	refs(keys *[]string)
+}
+
+// value is a string operand
+type value interface {
+	value(e *env) string
+	refs(keys *[]string)
+}
+
+type (
+	literal  string // a quoted string
+	stepRef  string // steps.KEY.output
+	inputRef string // input.KEY
+)
+
+func (l literal) value(*env) string { return string(l) }
+func (l literal) refs(*[]string)    {}
+
+func (r stepRef) value(e *env) string { return strings.TrimSpace(e.stepOutputs[string(r)]) }
+func (r stepRef) refs(keys *[]string) { *keys = append(*keys, string(r)) }
+
+func (r inputRef) value(e *env) string { return e.inputs[string(r)] }
+func (r inputRef) refs(*[]string)      {}
+
+type notNode struct{ x node }
+
+func (n notNode) eval(e *env) bool    { return !n.x.eval(e) }
+func (n notNode) refs(keys *[]string) { n.x.refs(keys) }
+
+type logicNode struct {
+	and  bool
+	l, r node
+}
+
+func (n logicNode) eval(e *env) bool {
+	if n.and {
+		return n.l.eval(e) && n.r.eval(e)
+	}
+	return n.l.eval(e) || n.r.eval(e)
+}
+
+func (n logicNode) refs(keys *[]string) {
+	n.l.refs(keys)
+	n.r.refs(keys)
+}
+
+type compareNode struct {
+	equal bool
+	l, r  value
+}
+
+func (n compareNode) eval(e *env) bool {
+	return (n.l.value(e) == n.r.value(e)) == n.equal
+}
+
+func (n compareNode) refs(keys *[]string) {
+	n.l.refs(keys)
+	n.r.refs(keys)
+}
+
+// functions are the string tests a condition can call
+var functions = map[string]func(s, sub string) bool{
+	"contains":   strings.Contains,
+	"startsWith": strings.HasPrefix,
+	"endsWith":   strings.HasSuffix,
+}
+
+type callNode struct {
+	name string
+	args [2]value
+}
+
+func (n callNode) eval(e *env) bool {
+	return functions[n.name](n.args[0].value(e), n.args[1].value(e))
+}
+
+func (n callNode) refs(keys *[]string) {
+	n.args[0].refs(keys)
+	n.args[1].refs(keys)
+}
+
+// Tokens
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokOp // == != && || ! ( ) ,
+)
+
+type token struct {
+	kind tokenKind
+	text string // identifier, unquoted string or operator
+}
+
+func (t token) String() string {
+	if t.kind == tokString {
+		return strconv.Quote(t.text)
+	}
+	return "'" + t.text + "'"
+}
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(src) && src[end] != '"' {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			s, err := strconv.Unquote(src[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s", src[i:end+1])
+			}
+			tokens = append(tokens, token{tokString, s})
+			i = end + 1
+		case strings.HasPrefix(src[i:], "==") || strings.HasPrefix(src[i:], "!=") ||
+			strings.HasPrefix(src[i:], "&&") || strings.HasPrefix(src[i:], "||"):
+			tokens = append(tokens, token{tokOp, src[i : i+2]})
+			i += 2
+		case strings.ContainsRune("!(),", rune(c)):
+			tokens = append(tokens, token{tokOp, string(c)})
+			i++
+		case isIdentRune(rune(c)):
+			end := i
+			for end < len(src) && (isIdentRune(rune(src[end])) || src[end] == '.' || src[end] == '-') {
+				end++
+			}
+			tokens = append(tokens, token{tokIdent, src[i:end]})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// Parser
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// accept consumes the next token if it is the operator op
+func (p *parser) accept(op string) bool {
+	if t, ok := p.peek(); ok && t.kind == tokOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if p.accept(op) {
+		return nil
+	}
+	if t, ok := p.peek(); ok {
+		return fmt.Errorf("expected '%s', got %s", op, t)
+	}
+	return fmt.Errorf("expected '%s' at end of condition", op)
+}
+
+func (p *parser) parseOr() (node, error) {
+	l, err := p.parseAnd()
+	for err == nil && p.accept("||") {
+		var r node
+		if r, err = p.parseAnd(); err == nil {
+			l = logicNode{and: false, l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) parseAnd() (node, error) {
+	l, err := p.parseUnary()
+	for err == nil && p.accept("&&") {
+		var r node
+		if r, err = p.parseUnary(); err == nil {
+			l = logicNode{and: true, l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("!") {
+		x, err := p.parseUnary()
+		return notNode{x}, err
+	}
+	if p.accept("(") {
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expect(")")
+	}
+
+	// A function call
+	if t, ok := p.peek(); ok && t.kind == tokIdent && !strings.Contains(t.text, ".") {
+		if _, known := functions[t.text]; !known {
+			return nil, fmt.Errorf("unknown function '%s' (expected contains, startsWith or endsWith)", t.text)
+		}
+		p.pos++
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		var call callNode
+		call.name = t.text
+		for i := range call.args {
+			if i > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			v, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			call.args[i] = v
+		}
+		return call, p.expect(")")
+	}
+
+	// A comparison
+	l, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	var equal bool
+	switch {
+	case p.accept("=="):
+		equal = true
+	case p.accept("!="):
+	default:
+		return nil, fmt.Errorf("expected == or != after a value")
+	}
+	r, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return compareNode{equal: equal, l: l, r: r}, nil
+}
+
+func (p *parser) parseValue() (value, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("expected a value at end of condition")
+	}
+	p.pos++
+	switch t.kind {
+	case tokString:
+		return literal(t.text), nil
+	case tokIdent:
+		parts := strings.Split(t.text, ".")
+		if len(parts) == 3 && parts[0] == "steps" && parts[1] != "" && parts[2] == "output" {
+			return stepRef(parts[1]), nil
+		}
+		if len(parts) == 2 && parts[0] == "input" && parts[1] != "" {
+			return inputRef(parts[1]), nil
+		}
+		return nil, fmt.Errorf("unknown value '%s' (expected steps.KEY.output, input.KEY or a quoted string)", t.text)
+	}
+	return nil, fmt.Errorf("expected a value, got %s", t)
+}
@@ -0,0 +1,82 @@
+package condition
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	inputs := map[string]string{"lang": "en-GB"}
+	outputs := map[string]string{"classify": "spam\n", "review": "Yes, looks good"}
+
+	tests := []struct {
+		cond string
+		want bool
+	}{
+		{`steps.classify.output == "spam"`, true},
+		{`steps.classify.output != "spam"`, false},
+		{`"ham" == steps.classify.output`, false},
+		{`contains(steps.review.output, "Yes")`, true},
+		{`startsWith(input.lang, "en")`, true},
+		{`endsWith(input.lang, "US")`, false},
+		{`steps.missing.output == ""`, true},
+		{`input.missing == ""`, true},
+		{`steps.classify.output == "spam" && !contains(steps.review.output, "no")`, true},
+		{`steps.classify.output == "ham" || endsWith(steps.review.output, "good")`, true},
+		{`!(steps.classify.output == "spam" || input.lang == "fr")`, false},
+		{`steps.classify.output == "ham" || steps.classify.output == "spam" && input.lang == "fr"`, false},
+		{`contains(steps.review.output, "\"")`, false},
+	}
+
+	for _, tt := range tests {
+		expr, err := Parse(tt.cond)
+		if err != nil {
+			t.Errorf("Parse(%s) failed: %v", tt.cond, err)
+			continue
+		}
+		if got := expr.Eval(inputs, outputs); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.cond, got, tt.want)
+		}
+	}
+}
+
+func TestParseRejectsMalformedConditions(t *testing.T) {
+	tests := []struct {
+		cond    string
+		wantErr string
+	}{
+		{``, "expected a value"},
+		{`steps.classify.output`, "expected == or !="},
+		{`steps.classify.output = "spam"`, "unexpected character"},
+		{`steps.classify == "spam"`, "unknown value 'steps.classify'"},
+		{`outputs.classify == "spam"`, "unknown value"},
+		{`steps.classify.output == "spam`, "unterminated string"},
+		{`exec("rm -rf /")`, "unknown function 'exec'"},
+		{`contains(steps.review.output)`, "expected ','"},
+		{`(steps.classify.output == "spam"`, "expected ')'"},
+		{`steps.classify.output == "spam" "ham"`, `unexpected "ham"`},
+		{`steps.classify.output == "spam" &&`, "expected a value"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.cond)
+		if err == nil {
+			t.Errorf("Parse(%s) succeeded, want an error", tt.cond)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Parse(%s) error = %v, want it to mention %q", tt.cond, err, tt.wantErr)
+		}
+	}
+}
+
+func TestStepRefs(t *testing.T) {
+	expr, err := Parse(`steps.b.output == "x" || contains(steps.a.output, input.q) && steps.b.output != ""`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got, want := expr.StepRefs(), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("StepRefs() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Prompt alias methods
+
+// SetPromptAlias makes alias another name for a prompt, replacing any alias
+// of the same name. An alias cannot shadow a prompt's name.
+func (db *DB) SetPromptAlias(alias, promptID string) (*PromptAlias, error) {
+	existing, err := db.GetPromptByName(alias)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("'%s' is the name of a prompt", alias)
+	}
+
+	var promptName string
+	err = db.QueryRow("SELECT name FROM prompts WHERE id = ?", promptID).Scan(&promptName)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("prompt not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find prompt: %w", err)
+	}
+
+	a := &PromptAlias{
+		Alias:      alias,
+		PromptID:   promptID,
+		PromptName: promptName,
+		CreatedAt:  time.Now(),
+	}
+	_, err = db.Exec(
+		"INSERT OR REPLACE INTO prompt_aliases (alias, prompt_id, created_at) VALUES (?, ?, ?)",
+		a.Alias, a.PromptID, a.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create alias: %w", err)
+	}
+	return a, nil
+}
+
+// GetPromptByAlias returns the prompt alias names, or nil if there is no such
+// alias
+func (db *DB) GetPromptByAlias(alias string) (*Prompt, error) {
+	var prompt Prompt
+	err := db.QueryRow(
+		`SELECT p.id, p.project_id, p.name, p.description, p.file_path, p.created_at, p.frozen
+		FROM prompt_aliases a JOIN prompts p ON p.id = a.prompt_id
+		WHERE a.alias = ?`,
+		alias,
+	).Scan(&prompt.ID, &prompt.ProjectID, &prompt.Name, &prompt.Description, &prompt.FilePath, &prompt.CreatedAt, &prompt.Frozen)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &prompt, nil
+}
+
+// ListPromptAliases returns every alias, by alias name
+func (db *DB) ListPromptAliases() ([]*PromptAlias, error) {
+	rows, err := db.Query(
+		`SELECT a.alias, a.prompt_id, p.name, a.created_at
+		FROM prompt_aliases a JOIN prompts p ON p.id = a.prompt_id
+		ORDER BY a.alias`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var aliases []*PromptAlias
+	for rows.Next() {
+		var a PromptAlias
+		if err := rows.Scan(&a.Alias, &a.PromptID, &a.PromptName, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, &a)
+	}
+	return aliases, nil
+}
+
+// RecordRename keeps a renamed prompt's old name working as an alias. An
+// alias the prompt had under its new name is dropped, since the name now
+// resolves directly.
+func (db *DB) RecordRename(promptID, oldName, newName string) error {
+	if _, err := db.Exec("DELETE FROM prompt_aliases WHERE alias = ?", newName); err != nil {
+		return fmt.Errorf("failed to delete alias: %w", err)
+	}
+	_, err := db.SetPromptAlias(oldName, promptID)
+	return err
+}
+
+func (db *DB) DeletePromptAlias(alias string) error {
+	result, err := db.Exec("DELETE FROM prompt_aliases WHERE alias = ?", alias)
+	if err != nil {
+		return fmt.Errorf("failed to delete alias: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("alias '%s' not found", alias)
+	}
+	return nil
+}
@@ -2,8 +2,15 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/promptsmith/cli/internal/condition"
 )
 
 // Chain methods
@@ -164,7 +171,7 @@ func (db *DB) CreateChainStep(chainID string, stepOrder int, promptName, inputMa
 
 func (db *DB) ListChainSteps(chainID string) ([]*ChainStep, error) {
 	rows, err := db.Query(
-		`SELECT id, chain_id, step_order, prompt_name, input_mapping, output_key
+		`SELECT id, chain_id, step_order, prompt_name, input_mapping, output_key, version, depends_on, condition
 		FROM chain_steps WHERE chain_id = ? ORDER BY step_order`,
 		chainID,
 	)
@@ -176,7 +183,7 @@ func (db *DB) ListChainSteps(chainID string) ([]*ChainStep, error) {
 	var steps []*ChainStep
 	for rows.Next() {
 		var s ChainStep
-		if err := rows.Scan(&s.ID, &s.ChainID, &s.StepOrder, &s.PromptName, &s.InputMapping, &s.OutputKey); err != nil {
+		if err := rows.Scan(&s.ID, &s.ChainID, &s.StepOrder, &s.PromptName, &s.InputMapping, &s.OutputKey, &s.Version, &s.DependsOn, &s.Condition); err != nil {
 			return nil, err
 		}
 		steps = append(steps, &s)
@@ -198,9 +205,9 @@ func (db *DB) ReplaceChainSteps(chainID string, steps []ChainStep) error {
 	for _, s := range steps {
 		id := NewUUID()
 		if _, err := tx.Exec(
-			`INSERT INTO chain_steps (id, chain_id, step_order, prompt_name, input_mapping, output_key)
-			VALUES (?, ?, ?, ?, ?, ?)`,
-			id, chainID, s.StepOrder, s.PromptName, s.InputMapping, s.OutputKey,
+			`INSERT INTO chain_steps (id, chain_id, step_order, prompt_name, input_mapping, output_key, version, depends_on, condition)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, chainID, s.StepOrder, s.PromptName, s.InputMapping, s.OutputKey, s.Version, s.DependsOn, s.Condition,
 		); err != nil {
 			return fmt.Errorf("failed to insert step %d: %w", s.StepOrder, err)
 		}
@@ -211,7 +218,9 @@ func (db *DB) ReplaceChainSteps(chainID string, steps []ChainStep) error {
 
 // Chain Run methods
 
-func (db *DB) SaveChainRun(chainID, status, inputs, results, finalOutput string) (*ChainRun, error) {
+// SaveChainRun records a finished run. totalTokens and totalCost are the
+// usage summed over its steps, stored so listing runs need not parse results.
+func (db *DB) SaveChainRun(chainID, status, inputs, results, finalOutput string, totalTokens int, totalCost float64) (*ChainRun, error) {
 	run := &ChainRun{
 		ID:          NewUUID(),
 		ChainID:     chainID,
@@ -219,14 +228,16 @@ func (db *DB) SaveChainRun(chainID, status, inputs, results, finalOutput string)
 		Inputs:      inputs,
 		Results:     results,
 		FinalOutput: finalOutput,
+		TotalTokens: totalTokens,
+		TotalCost:   totalCost,
 		StartedAt:   time.Now(),
 		CompletedAt: time.Now(),
 	}
 
 	_, err := db.Exec(
-		`INSERT INTO chain_runs (id, chain_id, status, inputs, results, final_output, started_at, completed_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
-		run.ID, run.ChainID, run.Status, run.Inputs, run.Results, run.FinalOutput, run.StartedAt, run.CompletedAt,
+		`INSERT INTO chain_runs (id, chain_id, status, inputs, results, final_output, total_tokens, total_cost, started_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.ID, run.ChainID, run.Status, run.Inputs, run.Results, run.FinalOutput, run.TotalTokens, run.TotalCost, run.StartedAt, run.CompletedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save chain run: %w", err)
@@ -234,24 +245,163 @@ func (db *DB) SaveChainRun(chainID, status, inputs, results, finalOutput string)
 	return run, nil
 }
 
-func (db *DB) ListChainRuns(chainID string) ([]*ChainRun, error) {
-	rows, err := db.Query(
-		`SELECT id, chain_id, status, inputs, results, final_output, started_at, completed_at
-		FROM chain_runs WHERE chain_id = ? ORDER BY started_at DESC`,
-		chainID,
-	)
+// ListChainRuns returns a chain's runs that match filter, newest first
+func (db *DB) ListChainRuns(chainID string, filter ChainRunFilter) ([]*ChainRun, error) {
+	query := `SELECT id, chain_id, status, inputs, results, final_output, total_tokens, total_cost, started_at, completed_at
+		FROM chain_runs WHERE chain_id = ?`
+	args := []any{chainID}
+	if filter.Status != "" {
+		query += " AND status = ?"
+		args = append(args, filter.Status)
+	}
+	query += " ORDER BY started_at DESC"
+
+	// SQLite needs a LIMIT for OFFSET; -1 means no limit
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	query += " LIMIT ? OFFSET ?"
+	args = append(args, limit, max(filter.Offset, 0))
+
+	rows, err := db.Query(query, args...)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to list chain runs: %w", err)
 	}
 	defer rows.Close()
 
 	var runs []*ChainRun
 	for rows.Next() {
 		var r ChainRun
-		if err := rows.Scan(&r.ID, &r.ChainID, &r.Status, &r.Inputs, &r.Results, &r.FinalOutput, &r.StartedAt, &r.CompletedAt); err != nil {
+		if err := rows.Scan(&r.ID, &r.ChainID, &r.Status, &r.Inputs, &r.Results, &r.FinalOutput, &r.TotalTokens, &r.TotalCost, &r.StartedAt, &r.CompletedAt); err != nil {
 			return nil, err
 		}
 		runs = append(runs, &r)
 	}
 	return runs, nil
 }
+
+// Chain planning
+
+// ChainStepRef matches a {{steps.KEY.output}} reference in a step's input
+// mapping, capturing KEY
+var ChainStepRef = regexp.MustCompile(`\{\{steps\.([^.}]+)(\.[^}]*)?\}\}`)
+
+// DeclaredDependencies returns the output keys listed in the step's
+// DependsOn, or nil if it lists none
+func (s *ChainStep) DeclaredDependencies() []string {
+	var keys []string
+	if s.DependsOn == "" || json.Unmarshal([]byte(s.DependsOn), &keys) != nil {
+		return nil
+	}
+	return keys
+}
+
+// Dependencies returns the output keys the step consumes: those it declares
+// in DependsOn and those its input mapping or condition refers to
+func (s *ChainStep) Dependencies() []string {
+	keys := s.DeclaredDependencies()
+	if s.Condition != "" {
+		if expr, err := condition.Parse(s.Condition); err == nil {
+			keys = append(keys, expr.StepRefs()...)
+		}
+	}
+	var mapping map[string]string
+	if json.Unmarshal([]byte(s.InputMapping), &mapping) == nil {
+		for _, source := range mapping {
+			for _, m := range ChainStepRef.FindAllStringSubmatch(source, -1) {
+				keys = append(keys, m[1])
+			}
+		}
+	}
+	sort.Strings(keys)
+	return slices.Compact(keys)
+}
+
+// PlanChainSteps groups a chain's steps into waves to run one after another.
+// The steps within a wave consume only outputs of earlier waves, so they can
+// run concurrently. A chain where no step declares DependsOn is linear: each
+// step is a wave of its own, in step order. Otherwise each step waits only
+// for the steps producing the outputs it consumes, and a consumed output
+// that no step produces or a dependency cycle is an error.
+func PlanChainSteps(steps []*ChainStep) ([][]*ChainStep, error) {
+	ordered := slices.Clone(steps)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].StepOrder < ordered[j].StepOrder })
+
+	if !slices.ContainsFunc(ordered, func(s *ChainStep) bool { return len(s.DeclaredDependencies()) > 0 }) {
+		waves := make([][]*ChainStep, len(ordered))
+		for i, s := range ordered {
+			waves[i] = []*ChainStep{s}
+		}
+		return waves, nil
+	}
+
+	producers := make(map[string][]int)
+	for i, s := range ordered {
+		producers[s.OutputKey] = append(producers[s.OutputKey], i)
+	}
+
+	// deps[i] lists the steps step i waits for
+	deps := make([][]int, len(ordered))
+	for i, s := range ordered {
+		for _, key := range s.Dependencies() {
+			p, ok := producers[key]
+			if !ok {
+				return nil, fmt.Errorf("step %d depends on '%s', but no step has that output_key", s.StepOrder, key)
+			}
+			deps[i] = append(deps[i], p...)
+		}
+	}
+
+	done := make([]bool, len(ordered))
+	var waves [][]*ChainStep
+	for remaining := len(ordered); remaining > 0; {
+		var wave []int
+		for i := range ordered {
+			if !done[i] && !slices.ContainsFunc(deps[i], func(d int) bool { return !done[d] }) {
+				wave = append(wave, i)
+			}
+		}
+		if len(wave) == 0 {
+			return nil, chainCycleError(ordered, deps, done)
+		}
+
+		steps := make([]*ChainStep, len(wave))
+		for j, i := range wave {
+			done[i] = true
+			steps[j] = ordered[i]
+		}
+		waves = append(waves, steps)
+		remaining -= len(wave)
+	}
+	return waves, nil
+}
+
+// chainCycleError describes a dependency cycle among the steps not yet done.
+// Every such step waits for another one that is not done, so following
+// those dependencies from any of them must come back round.
+func chainCycleError(ordered []*ChainStep, deps [][]int, done []bool) error {
+	start := slices.Index(done, false)
+	seen := make(map[int]int) // step index -> position in path
+	var path []int
+	for i := start; ; {
+		if pos, ok := seen[i]; ok {
+			path = append(path[pos:], i)
+			break
+		}
+		seen[i] = len(path)
+		path = append(path, i)
+		for _, d := range deps[i] {
+			if !done[d] {
+				i = d
+				break
+			}
+		}
+	}
+
+	names := make([]string, len(path))
+	for j, i := range path {
+		names[j] = fmt.Sprintf("step %d (%s)", ordered[i].StepOrder, ordered[i].OutputKey)
+	}
+	return fmt.Errorf("dependency cycle: %s", strings.Join(names, " depends on "))
+}
@@ -1,13 +1,28 @@
 package db
 
 import (
+	"database/sql"
 	"fmt"
 	"time"
 )
 
 // Comment methods
 
+// CreateComment adds a comment on a line of one of a prompt's versions. The
+// version must belong to the prompt.
 func (db *DB) CreateComment(promptID, versionID string, lineNumber int, content string) (*Comment, error) {
+	var versionPromptID string
+	err := db.QueryRow("SELECT prompt_id FROM prompt_versions WHERE id = ?", versionID).Scan(&versionPromptID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("version %s not found", versionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find version: %w", err)
+	}
+	if versionPromptID != promptID {
+		return nil, fmt.Errorf("version %s does not belong to this prompt", versionID)
+	}
+
 	c := &Comment{
 		ID:         NewUUID(),
 		PromptID:   promptID,
@@ -17,7 +32,7 @@ func (db *DB) CreateComment(promptID, versionID string, lineNumber int, content
 		CreatedAt:  time.Now(),
 	}
 
-	_, err := db.Exec(
+	_, err = db.Exec(
 		`INSERT INTO comments (id, prompt_id, version_id, line_number, content, created_at)
 		VALUES (?, ?, ?, ?, ?, ?)`,
 		c.ID, c.PromptID, c.VersionID, c.LineNumber, c.Content, c.CreatedAt,
@@ -28,6 +43,23 @@ func (db *DB) CreateComment(promptID, versionID string, lineNumber int, content
 	return c, nil
 }
 
+// GetComment returns a comment by ID, or nil if there is no such comment
+func (db *DB) GetComment(commentID string) (*Comment, error) {
+	var c Comment
+	err := db.QueryRow(
+		`SELECT id, prompt_id, version_id, line_number, content, created_at
+		FROM comments WHERE id = ?`,
+		commentID,
+	).Scan(&c.ID, &c.PromptID, &c.VersionID, &c.LineNumber, &c.Content, &c.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
 func (db *DB) ListComments(promptID string) ([]*Comment, error) {
 	rows, err := db.Query(
 		`SELECT id, prompt_id, version_id, line_number, content, created_at
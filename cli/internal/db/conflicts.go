@@ -0,0 +1,86 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Pull conflict methods
+
+// RecordPullConflict stores a diverged version, replacing any earlier record
+// of the same version
+func (db *DB) RecordPullConflict(c *PullConflict) error {
+	c.CreatedAt = time.Now()
+	_, err := db.Exec(
+		`INSERT INTO pull_conflicts (prompt_id, version, remote_content, strategy, resolved_version_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(prompt_id, version) DO UPDATE SET
+			remote_content = excluded.remote_content,
+			strategy = excluded.strategy,
+			resolved_version_id = excluded.resolved_version_id,
+			created_at = excluded.created_at`,
+		c.PromptID, c.Version, c.RemoteContent, c.Strategy, c.ResolvedVersionID, c.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record pull conflict: %w", err)
+	}
+	return nil
+}
+
+// GetPullConflict returns the recorded conflict of a version, or nil if it
+// has none
+func (db *DB) GetPullConflict(promptID, version string) (*PullConflict, error) {
+	var c PullConflict
+	err := db.QueryRow(
+		`SELECT c.prompt_id, p.name, c.version, c.remote_content, c.strategy, c.resolved_version_id, c.created_at
+		FROM pull_conflicts c JOIN prompts p ON p.id = c.prompt_id
+		WHERE c.prompt_id = ? AND c.version = ?`,
+		promptID, version,
+	).Scan(&c.PromptID, &c.PromptName, &c.Version, &c.RemoteContent, &c.Strategy, &c.ResolvedVersionID, &c.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull conflict: %w", err)
+	}
+	return &c, nil
+}
+
+// ListUnresolvedPullConflicts returns the conflicts still needing review, by
+// prompt name and version
+func (db *DB) ListUnresolvedPullConflicts() ([]*PullConflict, error) {
+	rows, err := db.Query(
+		`SELECT c.prompt_id, p.name, c.version, c.remote_content, c.strategy, c.resolved_version_id, c.created_at
+		FROM pull_conflicts c JOIN prompts p ON p.id = c.prompt_id
+		WHERE c.resolved_version_id IS NULL
+		ORDER BY p.name, c.version`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull conflicts: %w", err)
+	}
+	defer rows.Close()
+
+	var conflicts []*PullConflict
+	for rows.Next() {
+		var c PullConflict
+		if err := rows.Scan(&c.PromptID, &c.PromptName, &c.Version, &c.RemoteContent, &c.Strategy, &c.ResolvedVersionID, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, &c)
+	}
+	return conflicts, rows.Err()
+}
+
+// ResolvePullConflicts marks the prompt's unresolved conflicts as settled by
+// versionID
+func (db *DB) ResolvePullConflicts(promptID, versionID string) error {
+	_, err := db.Exec(
+		"UPDATE pull_conflicts SET resolved_version_id = ? WHERE prompt_id = ? AND resolved_version_id IS NULL",
+		versionID, promptID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to resolve pull conflicts: %w", err)
+	}
+	return nil
+}
@@ -3,18 +3,44 @@ package db
 import (
 	"fmt"
 	"time"
+
+	"github.com/promptsmith/cli/internal/prompt"
 )
 
 // Dashboard methods
 
+// Activity event types. These values are part of the API and must not change.
+const (
+	// ActivityVersion is a committed version. Title is "v" and the version,
+	// Detail the commit message.
+	ActivityVersion = "version"
+	// ActivityTag is a tag placed on a version. Title is the tag name,
+	// Detail "v" and the tagged version.
+	ActivityTag = "tag"
+	// ActivityTestRun is a completed test run. Title is the run's status,
+	// Detail the suite name.
+	ActivityTestRun = "test_run"
+	// ActivityBenchmarkRun is a completed benchmark run. Title is
+	// "completed", Detail the benchmark's ID.
+	ActivityBenchmarkRun = "benchmark_run"
+	// ActivityComment is a comment on a version's line. Title is "v", the
+	// version and the line, Detail the comment.
+	ActivityComment = "comment"
+)
+
+// ActivityEvent is one entry in the dashboard's activity feed. PromptName is
+// the prompt the event concerns.
 type ActivityEvent struct {
-	Type       string    `json:"type"`
+	Type       string    `json:"type"` // One of the Activity constants
 	Title      string    `json:"title"`
 	Detail     string    `json:"detail"`
 	Timestamp  time.Time `json:"timestamp"`
 	PromptName string    `json:"prompt_name"`
 }
 
+// GetRecentActivity returns the newest limit events across versions, tags,
+// test runs, benchmark runs and comments, newest first. A limit of zero or
+// less means 10.
 func (db *DB) GetRecentActivity(limit int) ([]ActivityEvent, error) {
 	if limit <= 0 {
 		limit = 10
@@ -33,11 +59,12 @@ func (db *DB) GetRecentActivity(limit int) ([]ActivityEvent, error) {
 
 			SELECT 'test_run' AS type,
 				tr.status AS title,
-				tr.suite_id AS detail,
-				tr.completed_at AS timestamp,
-				COALESCE(ts.name, tr.suite_id) AS prompt_name
+				ts.name AS detail,
+				COALESCE(tr.completed_at, tr.started_at) AS timestamp,
+				p.name AS prompt_name
 			FROM test_runs tr
-			LEFT JOIN test_suites ts ON tr.suite_id = ts.id
+			JOIN test_suites ts ON tr.suite_id = ts.id
+			JOIN prompts p ON ts.prompt_id = p.id
 
 			UNION ALL
 
@@ -45,9 +72,32 @@ func (db *DB) GetRecentActivity(limit int) ([]ActivityEvent, error) {
 				'completed' AS title,
 				br.benchmark_id AS detail,
 				br.created_at AS timestamp,
-				COALESCE(b.id, br.benchmark_id) AS prompt_name
+				p.name AS prompt_name
 			FROM benchmark_runs br
-			LEFT JOIN benchmarks b ON br.benchmark_id = b.id
+			JOIN benchmarks b ON br.benchmark_id = b.id
+			JOIN prompts p ON b.prompt_id = p.id
+
+			UNION ALL
+
+			SELECT 'tag' AS type,
+				t.name AS title,
+				'v' || pv.version AS detail,
+				t.created_at AS timestamp,
+				p.name AS prompt_name
+			FROM tags t
+			JOIN prompts p ON t.prompt_id = p.id
+			JOIN prompt_versions pv ON t.version_id = pv.id
+
+			UNION ALL
+
+			SELECT 'comment' AS type,
+				'v' || pv.version || ' line ' || c.line_number AS title,
+				c.content AS detail,
+				c.created_at AS timestamp,
+				p.name AS prompt_name
+			FROM comments c
+			JOIN prompts p ON c.prompt_id = p.id
+			JOIN prompt_versions pv ON c.version_id = pv.id
 		) activity
 		ORDER BY timestamp DESC
 		LIMIT ?
@@ -70,19 +120,28 @@ func (db *DB) GetRecentActivity(limit int) ([]ActivityEvent, error) {
 	return events, nil
 }
 
+// PromptHealth summarizes a prompt's history for the dashboard.
+// LastTestStatus is "none" and LastTestAt empty if it has never been tested.
 type PromptHealth struct {
-	PromptName     string  `json:"prompt_name"`
-	VersionCount   int     `json:"version_count"`
-	LastTestStatus string  `json:"last_test_status"`
-	LastTestAt     string  `json:"last_test_at"`
-	TestPassRate   float64 `json:"test_pass_rate"`
+	PromptName      string  `json:"prompt_name"`
+	VersionCount    int     `json:"version_count"`
+	CommitsLast30d  int     `json:"commits_last_30d"`
+	LastTestStatus  string  `json:"last_test_status"`
+	LastTestAt      string  `json:"last_test_at"`
+	TestPassRate    float64 `json:"test_pass_rate"`
+	EstimatedTokens int     `json:"estimated_tokens"` // Of the latest version
 }
 
+// churnWindow is the period over which CommitsLast30d counts versions
+const churnWindow = 30 * 24 * time.Hour
+
+// GetPromptHealth returns the health of every prompt, by name
 func (db *DB) GetPromptHealth() ([]PromptHealth, error) {
 	query := `
 		SELECT
 			p.name,
 			(SELECT COUNT(*) FROM prompt_versions pv WHERE pv.prompt_id = p.id) AS version_count,
+			(SELECT COUNT(*) FROM prompt_versions pv WHERE pv.prompt_id = p.id AND pv.created_at >= ?) AS commits_last_30d,
 			COALESCE(
 				(SELECT tr.status FROM test_runs tr
 				 JOIN test_suites ts ON tr.suite_id = ts.id
@@ -103,12 +162,19 @@ func (db *DB) GetPromptHealth() ([]PromptHealth, error) {
 				 JOIN test_suites ts2 ON tr2.suite_id = ts2.id
 				 WHERE ts2.prompt_id = p.id),
 				0.0
-			) AS test_pass_rate
+			) AS test_pass_rate,
+			COALESCE(
+				(SELECT length(CAST(pv.content AS BLOB)) FROM prompt_versions pv
+				 WHERE pv.prompt_id = p.id
+				 ORDER BY pv.created_at DESC, ` + semverDesc("pv.version") + `
+				 LIMIT 1),
+				0
+			) AS latest_size
 		FROM prompts p
 		ORDER BY p.name
 	`
 
-	rows, err := db.Query(query)
+	rows, err := db.Query(query, time.Now().Add(-churnWindow))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query prompt health: %w", err)
 	}
@@ -117,9 +183,11 @@ func (db *DB) GetPromptHealth() ([]PromptHealth, error) {
 	var results []PromptHealth
 	for rows.Next() {
 		var h PromptHealth
-		if err := rows.Scan(&h.PromptName, &h.VersionCount, &h.LastTestStatus, &h.LastTestAt, &h.TestPassRate); err != nil {
+		var latestSize int
+		if err := rows.Scan(&h.PromptName, &h.VersionCount, &h.CommitsLast30d, &h.LastTestStatus, &h.LastTestAt, &h.TestPassRate, &latestSize); err != nil {
 			return nil, err
 		}
+		h.EstimatedTokens = prompt.TokensForSize(latestSize)
 		results = append(results, h)
 	}
 	return results, nil
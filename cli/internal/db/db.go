@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/promptsmith/cli/internal/logging"
+	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -53,8 +56,43 @@ func FindProjectRoot() (string, error) {
 // "database is locked" errors.
 const maxOpenConns = 8
 
+// Path returns where a project's database is stored: the db_path key of its
+// config, resolved against the project root if relative, or DBFile in the
+// config directory by default
+func Path(projectRoot string) (string, error) {
+	defaultPath := filepath.Join(projectRoot, ConfigDir, DBFile)
+	data, err := os.ReadFile(filepath.Join(projectRoot, ConfigDir, ConfigFile))
+	if os.IsNotExist(err) {
+		return defaultPath, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var config struct {
+		DBPath string `yaml:"db_path"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return "", fmt.Errorf("failed to parse config: %w", err)
+	}
+	if config.DBPath == "" {
+		return defaultPath, nil
+	}
+	if filepath.IsAbs(config.DBPath) {
+		return config.DBPath, nil
+	}
+	return filepath.Join(projectRoot, config.DBPath), nil
+}
+
 func Open(projectRoot string) (*DB, error) {
-	dbPath := filepath.Join(projectRoot, ConfigDir, DBFile)
+	dbPath, err := Path(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	return openPath(projectRoot, dbPath)
+}
+
+func openPath(projectRoot, dbPath string) (*DB, error) {
 	// Pragmas are encoded in the DSN so they apply to every connection in the
 	// pool. Executing PRAGMA on the *sql.DB handle would only configure a
 	// single connection, leaving the rest of the pool with default settings.
@@ -77,25 +115,112 @@ func Open(projectRoot string) (*DB, error) {
 		sqlDB.Close()
 		return nil, err
 	}
+	if err := db.ensureSearchIndex(); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	logging.Debug("opened database", "path", dbPath)
 	return db, nil
 }
 
 func Initialize(projectRoot string) (*DB, error) {
+	return InitializeAt(projectRoot, "")
+}
+
+// InitializeAt is Initialize with the database created at dbPath instead of
+// in the config directory. Open only finds it there once the project config
+// names it as db_path.
+func InitializeAt(projectRoot, dbPath string) (*DB, error) {
 	configDir := filepath.Join(projectRoot, ConfigDir)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create config directory: %w", err)
 	}
+	if dbPath == "" {
+		dbPath = filepath.Join(configDir, DBFile)
+	} else if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
 
-	// Open runs migrations, which create the schema on a fresh database.
-	return Open(projectRoot)
+	// Opening runs migrations, which create the schema on a fresh database.
+	return openPath(projectRoot, dbPath)
 }
 
+// migration advances the schema by one version within tx. Databases created
+// before versions were tracked start at version 0, so a migration must be
+// safe to apply when its changes are already present.
+type migration func(tx *sql.Tx) error
+
 // migrations is the ordered list of schema migrations. Each entry advances the
 // database by one version; the applied version is tracked in SQLite's
 // PRAGMA user_version. Append new migrations to the end — never edit or reorder
 // existing entries, as that would corrupt already-migrated databases.
-var migrations = []string{
-	schemaV1,
+var migrations = []migration{
+	execSQL(schemaV1),
+	execSQL(schemaV2),
+	func(*sql.Tx) error { return nil }, // Was the search index; see ensureSearchIndex
+	addColumn("prompts", "frozen", "INTEGER NOT NULL DEFAULT 0"),
+	execSQL(schemaV5),
+	execSQL(schemaV6),
+	addColumn("chain_steps", "version", "TEXT NOT NULL DEFAULT ''"),
+	addColumn("chain_steps", "depends_on", "TEXT NOT NULL DEFAULT ''"),
+	addColumn("chain_steps", "condition", "TEXT NOT NULL DEFAULT ''"),
+	execSQL(schemaV10),
+	execSQL(schemaV11),
+	addColumn("chain_runs", "total_tokens", "INTEGER NOT NULL DEFAULT 0"),
+	addColumn("chain_runs", "total_cost", "REAL NOT NULL DEFAULT 0"),
+	execSQL(schemaV14),
+}
+
+// execSQL returns a migration that runs idempotent statements such as
+// CREATE TABLE IF NOT EXISTS
+func execSQL(statements string) migration {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(statements)
+		return err
+	}
+}
+
+// addColumn returns a migration that adds a column unless the table already
+// has it. SQLite has no ADD COLUMN IF NOT EXISTS.
+func addColumn(table, column, definition string) migration {
+	return func(tx *sql.Tx) error {
+		exists, err := hasColumn(tx, table, column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return nil
+		}
+		// Identifiers come from the migrations list, never from user input
+		_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+		return err
+	}
+}
+
+// hasColumn reports whether table has a column named column
+func hasColumn(tx *sql.Tx, table, column string) (bool, error) {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name, kind string
+			notNull    int
+			dflt       sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &kind, &notNull, &dflt, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
 }
 
 // migrate applies any migrations newer than the database's current
@@ -111,7 +236,7 @@ func (db *DB) migrate() error {
 		if err != nil {
 			return fmt.Errorf("failed to begin migration %d: %w", v+1, err)
 		}
-		if _, err := tx.Exec(migrations[v]); err != nil {
+		if err := migrations[v](tx); err != nil {
 			tx.Rollback()
 			return fmt.Errorf("failed to apply migration %d: %w", v+1, err)
 		}
@@ -124,6 +249,7 @@ func (db *DB) migrate() error {
 		if err := tx.Commit(); err != nil {
 			return fmt.Errorf("failed to commit migration %d: %w", v+1, err)
 		}
+		logging.Info("applied schema migration", "version", v+1)
 	}
 	return nil
 }
@@ -256,6 +382,128 @@ const schemaV1 = `
 	CREATE INDEX IF NOT EXISTS idx_chain_runs_chain ON chain_runs(chain_id);
 	`
 
+// schemaV2 adds the staging area: a snapshot of each prompt's content as it
+// was when staged, waiting to be committed
+const schemaV2 = `
+	CREATE TABLE IF NOT EXISTS staging (
+		prompt_id TEXT PRIMARY KEY REFERENCES prompts(id) ON DELETE CASCADE,
+		content TEXT NOT NULL,
+		staged_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+// schemaV3 indexes version content for full-text search. The index is kept
+// in step with prompt_versions by triggers, so every way of creating, editing
+// or deleting a version is covered.
+const schemaV3 = `
+	CREATE VIRTUAL TABLE IF NOT EXISTS prompt_versions_fts USING fts5(version_id UNINDEXED, content);
+
+	DELETE FROM prompt_versions_fts;
+	INSERT INTO prompt_versions_fts (version_id, content) SELECT id, content FROM prompt_versions;
+
+	CREATE TRIGGER IF NOT EXISTS prompt_versions_fts_insert AFTER INSERT ON prompt_versions BEGIN
+		INSERT INTO prompt_versions_fts (version_id, content) VALUES (new.id, new.content);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS prompt_versions_fts_update AFTER UPDATE OF content ON prompt_versions BEGIN
+		DELETE FROM prompt_versions_fts WHERE version_id = old.id;
+		INSERT INTO prompt_versions_fts (version_id, content) VALUES (new.id, new.content);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS prompt_versions_fts_delete AFTER DELETE ON prompt_versions BEGIN
+		DELETE FROM prompt_versions_fts WHERE version_id = old.id;
+	END;
+	`
+
+// ensureSearchIndex creates the full-text index if it is missing. It runs on
+// every open rather than as a migration, so a database first opened by a
+// binary built without FTS5 (see the sqlite_fts5 build tag) gains the index
+// once a binary with it opens the database. Without FTS5 there is no index
+// and searches fall back to LIKE.
+func (db *DB) ensureSearchIndex() error {
+	indexed, err := db.hasSearchIndex()
+	if err != nil || indexed {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to create search index: %w", err)
+	}
+	if _, err := tx.Exec(schemaV3); err != nil {
+		tx.Rollback()
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			logging.Debug("sqlite built without FTS5; search uses LIKE")
+			return nil
+		}
+		return fmt.Errorf("failed to create search index: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create search index: %w", err)
+	}
+	logging.Debug("created search index")
+	return nil
+}
+
+// schemaV5 refuses new versions of frozen prompts, whichever path creates them
+const schemaV5 = `
+	CREATE TRIGGER IF NOT EXISTS prompt_versions_frozen BEFORE INSERT ON prompt_versions
+	WHEN (SELECT frozen FROM prompts WHERE id = new.prompt_id)
+	BEGIN
+		SELECT RAISE(ABORT, 'prompt is frozen');
+	END;
+	`
+
+// schemaV10 refuses edits to existing versions of frozen prompts, as
+// schemaV5 refuses new ones
+const schemaV10 = `
+	CREATE TRIGGER IF NOT EXISTS prompt_versions_frozen_update BEFORE UPDATE OF content, variables, metadata ON prompt_versions
+	WHEN (SELECT frozen FROM prompts WHERE id = old.prompt_id)
+	BEGIN
+		SELECT RAISE(ABORT, 'prompt is frozen');
+	END;
+	`
+
+// schemaV6 keeps old names of renamed prompts resolving to them
+const schemaV6 = `
+	CREATE TABLE IF NOT EXISTS prompt_aliases (
+		alias TEXT PRIMARY KEY,
+		prompt_id TEXT NOT NULL REFERENCES prompts(id) ON DELETE CASCADE,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_prompt_aliases_prompt ON prompt_aliases(prompt_id);
+	`
+
+// schemaV11 records versions that diverged from the remote copy on pull
+const schemaV11 = `
+	CREATE TABLE IF NOT EXISTS pull_conflicts (
+		prompt_id TEXT NOT NULL REFERENCES prompts(id) ON DELETE CASCADE,
+		version TEXT NOT NULL,
+		remote_content TEXT NOT NULL,
+		strategy TEXT NOT NULL,
+		resolved_version_id TEXT REFERENCES prompt_versions(id) ON DELETE SET NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (prompt_id, version)
+	);
+	`
+
+// schemaV14 fills in the totals of chain runs saved before they were stored,
+// from the usage their step results recorded
+const schemaV14 = `
+	UPDATE chain_runs SET
+		total_tokens = (
+			SELECT COALESCE(SUM(COALESCE(json_extract(value, '$.prompt_tokens'), 0) + COALESCE(json_extract(value, '$.output_tokens'), 0)), 0)
+			FROM json_each(chain_runs.results)
+		),
+		total_cost = (
+			SELECT COALESCE(SUM(COALESCE(json_extract(value, '$.cost'), 0)), 0)
+			FROM json_each(chain_runs.results)
+		)
+	WHERE total_tokens = 0 AND total_cost = 0
+		AND json_valid(results) AND json_type(results) = 'array';
+	`
+
 func (db *DB) ProjectRoot() string {
 	return db.projectRoot
 }
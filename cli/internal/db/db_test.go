@@ -1,11 +1,20 @@
 package db
 
 import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
+	"time"
+
+	"github.com/promptsmith/cli/internal/prompt"
 )
 
 func setupTestDB(t *testing.T) (*DB, string, func()) {
@@ -56,6 +65,80 @@ func TestInitialize(t *testing.T) {
 	}
 }
 
+func TestInitializeAtCustomPath(t *testing.T) {
+	projectRoot := t.TempDir()
+	dbPath := filepath.Join(t.TempDir(), "fast", "project.db")
+
+	db, err := InitializeAt(projectRoot, dbPath)
+	if err != nil {
+		t.Fatalf("InitializeAt failed: %v", err)
+	}
+	project, err := db.CreateProject("custom")
+	if err != nil {
+		t.Fatalf("CreateProject failed: %v", err)
+	}
+	db.Close()
+
+	if _, err := os.Stat(dbPath); err != nil {
+		t.Fatalf("expected database at %s: %v", dbPath, err)
+	}
+	if _, err := os.Stat(filepath.Join(projectRoot, ConfigDir, DBFile)); !os.IsNotExist(err) {
+		t.Error("expected no database in the config directory")
+	}
+
+	config := fmt.Sprintf("version: 1\ndb_path: %s\n", dbPath)
+	if err := os.WriteFile(filepath.Join(projectRoot, ConfigDir, ConfigFile), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	db, err = Open(projectRoot)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+	got, err := db.GetProject()
+	if err != nil || got == nil || got.ID != project.ID {
+		t.Errorf("expected Open to use the configured database, got %+v, %v", got, err)
+	}
+}
+
+func TestPath(t *testing.T) {
+	projectRoot := t.TempDir()
+	configDir := filepath.Join(projectRoot, ConfigDir)
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defaultPath := filepath.Join(configDir, DBFile)
+
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"no config", "", defaultPath},
+		{"no db_path", "version: 1\n", defaultPath},
+		{"absolute", "db_path: /var/cache/ps.db\n", "/var/cache/ps.db"},
+		{"relative", "db_path: ../data/ps.db\n", filepath.Join(projectRoot, "../data/ps.db")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(configDir, ConfigFile)
+			os.Remove(configPath)
+			if tt.config != "" {
+				if err := os.WriteFile(configPath, []byte(tt.config), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := Path(projectRoot)
+			if err != nil {
+				t.Fatalf("Path failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Path() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestOpenEnablesForeignKeys(t *testing.T) {
 	db, _, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -86,6 +169,75 @@ func TestMigrationsSetUserVersion(t *testing.T) {
 	}
 }
 
+func TestMigrateUpgradesOldDatabase(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "promptsmith-test-*")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// A database from before versioning: base tables only, user_version 0
+	os.MkdirAll(filepath.Join(tmpDir, ConfigDir), 0755)
+	raw, err := sql.Open("sqlite3", filepath.Join(tmpDir, ConfigDir, DBFile))
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	if _, err := raw.Exec(schemaV1); err != nil {
+		t.Fatalf("failed to create base tables: %v", err)
+	}
+	raw.Close()
+
+	applied := 0
+	addAuthor := addColumn("comments", "author", "TEXT NOT NULL DEFAULT ''")
+	original := migrations
+	migrations = append(append([]migration{}, original...), func(tx *sql.Tx) error {
+		applied++
+		return addAuthor(tx)
+	})
+	defer func() { migrations = original }()
+
+	for i := 0; i < 2; i++ {
+		db, err := Open(tmpDir)
+		if err != nil {
+			t.Fatalf("Open failed: %v", err)
+		}
+
+		var version int
+		db.QueryRow("PRAGMA user_version").Scan(&version)
+		if version != len(migrations) {
+			t.Errorf("user_version = %d, want %d", version, len(migrations))
+		}
+		if _, err := db.Exec("SELECT author FROM comments"); err != nil {
+			t.Errorf("expected comments.author to exist: %v", err)
+		}
+		if _, err := db.Exec("SELECT prompt_id FROM staging"); err != nil {
+			t.Errorf("expected staging table to exist: %v", err)
+		}
+		db.Close()
+	}
+
+	if applied != 1 {
+		t.Errorf("expected the new migration to run once, ran %d times", applied)
+	}
+}
+
+func TestAddColumnIsIdempotent(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for i := 0; i < 2; i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatalf("Begin failed: %v", err)
+		}
+		if err := addColumn("tags", "note", "TEXT")(tx); err != nil {
+			tx.Rollback()
+			t.Fatalf("addColumn run %d failed: %v", i+1, err)
+		}
+		tx.Commit()
+	}
+}
+
 func TestReopenIsIdempotent(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "promptsmith-test-*")
 	if err != nil {
@@ -164,6 +316,62 @@ func TestConcurrentReadsAndWrites(t *testing.T) {
 	}
 }
 
+func TestSeparateHandlesShareDatabase(t *testing.T) {
+	server, tmpDir, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var timeout int
+	if err := server.QueryRow("PRAGMA busy_timeout").Scan(&timeout); err != nil {
+		t.Fatalf("failed to read busy_timeout pragma: %v", err)
+	}
+	if timeout != 5000 {
+		t.Fatalf("busy_timeout = %d, want 5000", timeout)
+	}
+
+	// A second handle stands in for a CLI command running beside the server
+	cli, err := Open(tmpDir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer cli.Close()
+
+	project, _ := server.CreateProject("shared")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			name := fmt.Sprintf("cli-%d", i)
+			if _, err := cli.CreatePrompt(project.ID, name, "", "prompts/"+name+".prompt"); err != nil {
+				errs <- err
+			}
+		}
+	}()
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				if _, err := server.ListPromptsWithLatestVersion(false); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent access failed: %v", err)
+	}
+
+	prompts, _ := server.ListPrompts()
+	if len(prompts) != 20 {
+		t.Errorf("expected 20 prompts written through the second handle, got %d", len(prompts))
+	}
+}
+
 func TestCreateAndGetProject(t *testing.T) {
 	db, _, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -256,6 +464,115 @@ func TestPromptUniqueConstraints(t *testing.T) {
 	}
 }
 
+func TestUpdatePromptFilePath(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	prompt, _ := db.CreatePrompt(project.ID, "summarizer", "", "prompts/summarizer.prompt")
+	db.CreatePrompt(project.ID, "classifier", "", "prompts/classifier.prompt")
+
+	if err := db.UpdatePromptFilePath(prompt.ID, "prompts/digest.prompt"); err != nil {
+		t.Fatalf("UpdatePromptFilePath failed: %v", err)
+	}
+	byPath, err := db.GetPromptByPath("prompts/digest.prompt")
+	if err != nil {
+		t.Fatalf("GetPromptByPath failed: %v", err)
+	}
+	if byPath == nil || byPath.ID != prompt.ID {
+		t.Errorf("expected prompt at new path, got %+v", byPath)
+	}
+
+	if err := db.UpdatePromptFilePath(prompt.ID, "prompts/classifier.prompt"); err == nil {
+		t.Error("expected a path tracked by another prompt to fail")
+	}
+}
+
+func TestPromptAliases(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	digest, _ := db.CreatePrompt(project.ID, "digest", "", "prompts/digest.prompt")
+	db.CreatePrompt(project.ID, "classifier", "", "prompts/classifier.prompt")
+
+	if _, err := db.SetPromptAlias("summarizer", digest.ID); err != nil {
+		t.Fatalf("SetPromptAlias failed: %v", err)
+	}
+
+	if p, aliased, _ := db.ResolvePromptName("digest"); p == nil || aliased {
+		t.Fatalf("expected digest by name, got %+v (aliased %v)", p, aliased)
+	}
+
+	p, aliased, err := db.ResolvePromptName("summarizer")
+	if err != nil {
+		t.Fatalf("ResolvePromptName failed: %v", err)
+	}
+	if p == nil || p.ID != digest.ID || p.Name != "digest" || !aliased {
+		t.Fatalf("expected the alias to resolve to digest, got %+v (aliased %v)", p, aliased)
+	}
+	// Exact lookups, used to check whether a name is taken, ignore aliases
+	if p, _ := db.GetPromptByName("summarizer"); p != nil {
+		t.Errorf("expected GetPromptByName to ignore aliases, got %+v", p)
+	}
+
+	if p, _, _ := db.ResolvePromptName("missing"); p != nil {
+		t.Errorf("expected nil for an unknown name, got %+v", p)
+	}
+	if _, err := db.SetPromptAlias("classifier", digest.ID); err == nil {
+		t.Error("expected an alias shadowing a prompt name to fail")
+	}
+
+	aliases, err := db.ListPromptAliases()
+	if err != nil {
+		t.Fatalf("ListPromptAliases failed: %v", err)
+	}
+	if len(aliases) != 1 || aliases[0].Alias != "summarizer" || aliases[0].PromptName != "digest" {
+		t.Errorf("unexpected aliases %+v", aliases)
+	}
+
+	if err := db.DeletePromptAlias("summarizer"); err != nil {
+		t.Fatalf("DeletePromptAlias failed: %v", err)
+	}
+	if p, _, _ := db.ResolvePromptName("summarizer"); p != nil {
+		t.Error("expected a deleted alias to stop resolving")
+	}
+	if err := db.DeletePromptAlias("summarizer"); err == nil {
+		t.Error("expected deleting a missing alias to fail")
+	}
+}
+
+func TestRecordRename(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	prompt, _ := db.CreatePrompt(project.ID, "summarizer", "", "prompts/summarizer.prompt")
+
+	// summarizer -> digest -> summarizer
+	db.UpdatePrompt(prompt.ID, "digest", "")
+	if err := db.RecordRename(prompt.ID, "summarizer", "digest"); err != nil {
+		t.Fatalf("RecordRename failed: %v", err)
+	}
+	db.UpdatePrompt(prompt.ID, "summarizer", "")
+	if err := db.RecordRename(prompt.ID, "digest", "summarizer"); err != nil {
+		t.Fatalf("RecordRename failed: %v", err)
+	}
+
+	aliases, _ := db.ListPromptAliases()
+	if len(aliases) != 1 || aliases[0].Alias != "digest" || aliases[0].PromptName != "summarizer" {
+		t.Errorf("expected only digest to alias summarizer, got %+v", aliases)
+	}
+
+	// Deleting the prompt drops its aliases
+	if err := db.DeletePrompt(prompt.ID); err != nil {
+		t.Fatalf("DeletePrompt failed: %v", err)
+	}
+	if aliases, _ := db.ListPromptAliases(); len(aliases) != 0 {
+		t.Errorf("expected aliases to go with the prompt, got %+v", aliases)
+	}
+}
+
 func TestListPrompts(t *testing.T) {
 	db, _, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -294,7 +611,7 @@ func TestListPromptsWithLatestVersion(t *testing.T) {
 	db.CreateVersion(alpha.ID, "1.0.1", "alpha v2", "[]", "{}", "Update", "user", &v1.ID)
 	db.CreateVersion(beta.ID, "2.0.0", "beta v1", "[]", "{}", "Initial", "user", nil)
 
-	prompts, err := db.ListPromptsWithLatestVersion()
+	prompts, err := db.ListPromptsWithLatestVersion(false)
 	if err != nil {
 		t.Fatalf("ListPromptsWithLatestVersion failed: %v", err)
 	}
@@ -310,6 +627,71 @@ func TestListPromptsWithLatestVersion(t *testing.T) {
 	}
 }
 
+func TestListPromptsWithLatestVersionContent(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	alpha, _ := db.CreatePrompt(project.ID, "alpha", "", "prompts/alpha.prompt")
+	db.CreatePrompt(project.ID, "beta", "", "prompts/beta.prompt")
+
+	v1, _ := db.CreateVersion(alpha.ID, "1.0.0", "alpha v1", "[]", "{}", "Initial", "user", nil)
+	db.CreateVersion(alpha.ID, "1.0.1", "alpha v2", "[]", "{}", "Update", "user", &v1.ID)
+
+	prompts, err := db.ListPromptsWithLatestVersion(true)
+	if err != nil {
+		t.Fatalf("ListPromptsWithLatestVersion failed: %v", err)
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("expected 2 prompts, got %d", len(prompts))
+	}
+	if prompts[0].LatestVersion != "1.0.1" || prompts[0].LatestContent != "alpha v2" {
+		t.Errorf("alpha latest = %q %q, want 1.0.1 'alpha v2'", prompts[0].LatestVersion, prompts[0].LatestContent)
+	}
+
+	// A prompt without versions is still listed, with nothing to show
+	if prompts[1].Name != "beta" || prompts[1].LatestVersion != "" || prompts[1].LatestContent != "" {
+		t.Errorf("expected beta with no version or content, got %+v", prompts[1])
+	}
+
+	prompts, _ = db.ListPromptsWithLatestVersion(false)
+	if prompts[0].LatestContent != "" {
+		t.Errorf("expected no content unless requested, got %q", prompts[0].LatestContent)
+	}
+}
+
+// BenchmarkListPromptsWithLatestVersion lists 200 prompts with 5 versions
+// each, content included, in a single query.
+func BenchmarkListPromptsWithLatestVersion(b *testing.B) {
+	tmpDir := b.TempDir()
+	db, err := Initialize(tmpDir)
+	if err != nil {
+		b.Fatalf("failed to initialize db: %v", err)
+	}
+	defer db.Close()
+
+	project, _ := db.CreateProject("bench-project")
+	for i := 0; i < 200; i++ {
+		p, _ := db.CreatePrompt(project.ID, fmt.Sprintf("prompt-%03d", i), "", fmt.Sprintf("prompts/prompt-%03d.prompt", i))
+		var parent *string
+		for j := 0; j < 5; j++ {
+			v, _ := db.CreateVersion(p.ID, fmt.Sprintf("1.0.%d", j), fmt.Sprintf("content %d", j), "[]", "{}", "Update", "user", parent)
+			parent = &v.ID
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		prompts, err := db.ListPromptsWithLatestVersion(true)
+		if err != nil {
+			b.Fatalf("ListPromptsWithLatestVersion failed: %v", err)
+		}
+		if len(prompts) != 200 || prompts[0].LatestContent != "content 4" {
+			b.Fatalf("unexpected listing: %d prompts", len(prompts))
+		}
+	}
+}
+
 func TestCreateAndGetVersions(t *testing.T) {
 	db, _, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -383,6 +765,115 @@ func TestVersionUniqueConstraint(t *testing.T) {
 	}
 }
 
+func TestCreateVersions(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	first, _ := db.CreatePrompt(project.ID, "first", "", "prompts/first.prompt")
+	second, _ := db.CreatePrompt(project.ID, "second", "", "prompts/second.prompt")
+
+	versions := []*PromptVersion{
+		{PromptID: first.ID, Version: "1.0.0", Content: "First", Variables: "[]", Metadata: "{}", CommitMessage: "Initial", CreatedBy: "testuser"},
+		{PromptID: second.ID, Version: "1.0.0", Content: "Second", Variables: "[]", Metadata: "{}", CommitMessage: "Initial", CreatedBy: "testuser"},
+	}
+	if err := db.CreateVersions(versions); err != nil {
+		t.Fatalf("CreateVersions failed: %v", err)
+	}
+	for _, v := range versions {
+		if v.ID == "" || v.CreatedAt.IsZero() {
+			t.Errorf("expected ID and CreatedAt to be filled in, got %+v", v)
+		}
+		stored, _ := db.GetVersionByID(v.ID)
+		if stored == nil || stored.Content != v.Content {
+			t.Errorf("expected version %s to be stored", v.ID)
+		}
+	}
+}
+
+func TestCreateVersionsRollsBackOnFailure(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	first, _ := db.CreatePrompt(project.ID, "first", "", "prompts/first.prompt")
+	second, _ := db.CreatePrompt(project.ID, "second", "", "prompts/second.prompt")
+	if _, err := db.CreateVersion(second.ID, "1.0.0", "Existing", "[]", "{}", "Initial", "testuser", nil); err != nil {
+		t.Fatalf("CreateVersion failed: %v", err)
+	}
+
+	// The second insert collides with the existing 1.0.0 and fails mid-commit
+	err := db.CreateVersions([]*PromptVersion{
+		{PromptID: first.ID, Version: "1.0.0", Content: "First", Variables: "[]", Metadata: "{}", CommitMessage: "Batch", CreatedBy: "testuser"},
+		{PromptID: second.ID, Version: "1.0.0", Content: "Duplicate", Variables: "[]", Metadata: "{}", CommitMessage: "Batch", CreatedBy: "testuser"},
+	})
+	if err == nil {
+		t.Fatal("expected CreateVersions to fail on duplicate version")
+	}
+
+	versions, _ := db.ListVersions(first.ID)
+	if len(versions) != 0 {
+		t.Errorf("expected no versions for first prompt after rollback, got %d", len(versions))
+	}
+	versions, _ = db.ListVersions(second.ID)
+	if len(versions) != 1 || versions[0].Content != "Existing" {
+		t.Errorf("expected second prompt to keep only its existing version, got %d", len(versions))
+	}
+}
+
+func TestStaging(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	first, _ := db.CreatePrompt(project.ID, "first", "", "prompts/first.prompt")
+	second, _ := db.CreatePrompt(project.ID, "second", "", "prompts/second.prompt")
+
+	if staged, err := db.GetStagedPrompt(first.ID); err != nil || staged != nil {
+		t.Fatalf("expected nothing staged, got %+v, %v", staged, err)
+	}
+
+	if err := db.StagePrompt(first.ID, "Draft 1"); err != nil {
+		t.Fatalf("StagePrompt failed: %v", err)
+	}
+	// Staging again replaces the snapshot
+	if err := db.StagePrompt(first.ID, "Draft 2"); err != nil {
+		t.Fatalf("StagePrompt failed: %v", err)
+	}
+	if err := db.StagePrompt(second.ID, "Other"); err != nil {
+		t.Fatalf("StagePrompt failed: %v", err)
+	}
+
+	staged, err := db.GetStagedPrompt(first.ID)
+	if err != nil || staged == nil || staged.Content != "Draft 2" {
+		t.Fatalf("expected staged content 'Draft 2', got %+v, %v", staged, err)
+	}
+	all, err := db.ListStagedPrompts()
+	if err != nil {
+		t.Fatalf("ListStagedPrompts failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 staged prompts, got %d", len(all))
+	}
+
+	if err := db.UnstagePrompt(second.ID); err != nil {
+		t.Fatalf("UnstagePrompt failed: %v", err)
+	}
+	if staged, _ := db.GetStagedPrompt(second.ID); staged != nil {
+		t.Error("expected second prompt to be unstaged")
+	}
+
+	// Committing a prompt clears its staged snapshot
+	if err := db.CreateVersions([]*PromptVersion{
+		{PromptID: first.ID, Version: "1.0.0", Content: "Draft 2", Variables: "[]", Metadata: "{}", CommitMessage: "Initial", CreatedBy: "testuser"},
+	}); err != nil {
+		t.Fatalf("CreateVersions failed: %v", err)
+	}
+	if staged, _ := db.GetStagedPrompt(first.ID); staged != nil {
+		t.Error("expected commit to clear the staged snapshot")
+	}
+}
+
 func TestTags(t *testing.T) {
 	db, _, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -463,60 +954,227 @@ func TestCreateTagRejectsWrongPromptVersion(t *testing.T) {
 	}
 }
 
-func TestDeletePrompt(t *testing.T) {
-	db, _, cleanup := setupTestDB(t)
+func TestExportImportRoundTrip(t *testing.T) {
+	src, _, cleanup := setupTestDB(t)
 	defer cleanup()
 
-	project, _ := db.CreateProject("test-project")
-	prompt, _ := db.CreatePrompt(project.ID, "to-delete", "Will be deleted", "prompts/delete.prompt")
-	v1, _ := db.CreateVersion(prompt.ID, "1.0.0", "Content v1", "[]", "{}", "Initial", "user", nil)
-	v2, _ := db.CreateVersion(prompt.ID, "1.0.1", "Content v2", "[]", "{}", "Update", "user", &v1.ID)
-	db.CreateTag(prompt.ID, v1.ID, "prod")
-	db.CreateComment(prompt.ID, v2.ID, 1, "Needs revision")
+	project, _ := src.CreateProject("source")
+	prompt, _ := src.CreatePrompt(project.ID, "summarizer", "Summarizes text", "prompts/summarizer.prompt")
+	v1, _ := src.CreateVersion(prompt.ID, "1.0.0", "Content v1", `[{"name":"text"}]`, `{"model":"gpt-4"}`, "Initial", "alice", nil)
+	v2, _ := src.CreateVersion(prompt.ID, "1.0.1", "Content v2", "[]", "{}", "Tighten wording", "bob", &v1.ID)
+	src.CreateVersion(prompt.ID, "1.1.0", "Content v3", "[]", "{}", "Branch from 1.0.0", "alice", &v1.ID)
+	src.CreateTag(prompt.ID, v1.ID, "stable")
+	src.CreateTag(prompt.ID, v2.ID, "prod")
 
-	if err := db.EnsureTestSuite("suite-delete", prompt.ID, "suite-delete", "{}"); err != nil {
-		t.Fatalf("EnsureTestSuite failed: %v", err)
+	export, err := src.ExportPrompt("summarizer")
+	if err != nil {
+		t.Fatalf("ExportPrompt failed: %v", err)
 	}
-	if _, err := db.SaveTestRun("suite-delete", v2.ID, "passed", `{"passed":1}`); err != nil {
-		t.Fatalf("SaveTestRun failed: %v", err)
+
+	dst, _, cleanupDst := setupTestDB(t)
+	defer cleanupDst()
+	dstProject, _ := dst.CreateProject("destination")
+
+	imported, err := dst.ImportPrompt(dstProject.ID, export)
+	if err != nil {
+		t.Fatalf("ImportPrompt failed: %v", err)
 	}
-	if err := db.EnsureBenchmark("bench-delete", prompt.ID, "{}"); err != nil {
-		t.Fatalf("EnsureBenchmark failed: %v", err)
+	if imported.ID == prompt.ID {
+		t.Error("expected the imported prompt to get a new ID")
 	}
-	if _, err := db.SaveBenchmarkRun("bench-delete", v2.ID, `{"models":[]}`); err != nil {
-		t.Fatalf("SaveBenchmarkRun failed: %v", err)
+
+	reexport, err := dst.ExportPrompt("summarizer")
+	if err != nil || reexport == nil {
+		t.Fatalf("ExportPrompt after import failed: %v", err)
 	}
-	chain, _ := db.CreateChain(project.ID, "delete-chain", "")
-	if _, err := db.CreateChainStep(chain.ID, 1, prompt.Name, `{}`, "output"); err != nil {
-		t.Fatalf("CreateChainStep failed: %v", err)
+	if reexport.Prompt.Name != export.Prompt.Name || reexport.Prompt.Description != export.Prompt.Description || reexport.Prompt.FilePath != export.Prompt.FilePath {
+		t.Errorf("imported prompt = %+v, want %+v", reexport.Prompt, export.Prompt)
+	}
+	if !reexport.Prompt.CreatedAt.Equal(export.Prompt.CreatedAt) {
+		t.Errorf("imported created_at = %v, want %v", reexport.Prompt.CreatedAt, export.Prompt.CreatedAt)
 	}
 
-	// Delete prompt (should cascade)
-	err := db.DeletePrompt(prompt.ID)
-	if err != nil {
-		t.Fatalf("DeletePrompt failed: %v", err)
+	// Compare history as JSON, as timestamps read back from the database
+	// lose their monotonic clock reading
+	for _, part := range []struct {
+		name      string
+		got, want any
+	}{
+		{"versions", reexport.Versions, export.Versions},
+		{"tags", reexport.Tags, export.Tags},
+	} {
+		got, _ := json.Marshal(part.got)
+		want, _ := json.Marshal(part.want)
+		if !bytes.Equal(got, want) {
+			t.Errorf("imported %s = %s, want %s", part.name, got, want)
+		}
 	}
 
-	// Verify prompt is gone
-	found, err := db.GetPromptByName("to-delete")
-	if err != nil {
-		t.Fatalf("GetPromptByName failed: %v", err)
+	// Parent links point at the new IDs
+	versions, _ := dst.ListVersions(imported.ID)
+	byVersion := make(map[string]*PromptVersion)
+	for _, v := range versions {
+		byVersion[v.Version] = v
 	}
-	if found != nil {
-		t.Error("expected prompt to be deleted")
+	if p := byVersion["1.1.0"].ParentVersionID; p == nil || *p != byVersion["1.0.0"].ID {
+		t.Errorf("expected 1.1.0 to have parent %s, got %v", byVersion["1.0.0"].ID, p)
 	}
+}
 
-	// Verify versions are gone
-	versions, err := db.ListVersions(prompt.ID)
-	if err != nil {
-		t.Fatalf("ListVersions failed: %v", err)
+func TestImportPromptRefusesToClobber(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	prompt, _ := db.CreatePrompt(project.ID, "summarizer", "", "prompts/summarizer.prompt")
+	db.CreateVersion(prompt.ID, "1.0.0", "Content v1", "[]", "{}", "Initial", "testuser", nil)
+
+	export, _ := db.ExportPrompt("summarizer")
+	if _, err := db.ImportPrompt(project.ID, export); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected importing over an existing prompt to fail, got %v", err)
 	}
-	if len(versions) != 0 {
-		t.Errorf("expected 0 versions, got %d", len(versions))
+
+	export.Prompt.Name = "summarizer-copy"
+	if _, err := db.ImportPrompt(project.ID, export); err == nil || !strings.Contains(err.Error(), "already tracked") {
+		t.Errorf("expected importing onto a tracked file to fail, got %v", err)
 	}
+}
 
-	// Verify tags are gone
-	tags, err := db.ListTags(prompt.ID)
+func TestImportPromptRejectsInconsistentExport(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+	project, _ := db.CreateProject("test-project")
+
+	valid := func() *PromptExport {
+		return &PromptExport{
+			FormatVersion: ExportFormatVersion,
+			Prompt:        ExportedPrompt{Name: "summarizer", FilePath: "prompts/summarizer.prompt"},
+			Versions: []ExportedVersion{
+				{Version: "1.0.0", Content: "v1"},
+				{Version: "1.0.1", Parent: "1.0.0", Content: "v2"},
+			},
+			Tags: []ExportedTag{{Name: "prod", Version: "1.0.1"}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(e *PromptExport)
+		wantErr string
+	}{
+		{"future format", func(e *PromptExport) { e.FormatVersion = ExportFormatVersion + 1 }, "unsupported export format"},
+		{"no name", func(e *PromptExport) { e.Prompt.Name = "" }, "no prompt name"},
+		{"duplicate version", func(e *PromptExport) { e.Versions[1].Version = "1.0.0"; e.Versions[1].Parent = "" }, "twice"},
+		{"parent after child", func(e *PromptExport) { e.Versions[0].Parent = "1.0.1" }, "not listed before it"},
+		{"dangling tag", func(e *PromptExport) { e.Tags[0].Version = "2.0.0" }, "not in the export"},
+	}
+	for _, tt := range tests {
+		export := valid()
+		tt.mutate(export)
+		if _, err := db.ImportPrompt(project.ID, export); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected error mentioning %q, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+
+	if p, _ := db.GetPromptByName("summarizer"); p != nil {
+		t.Error("expected rejected imports to leave no prompt behind")
+	}
+}
+
+func TestSchemaCascadesPromptDelete(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	prompt, _ := db.CreatePrompt(project.ID, "cascaded", "", "prompts/cascaded.prompt")
+	v1, _ := db.CreateVersion(prompt.ID, "1.0.0", "Content v1", "[]", "{}", "Initial", "user", nil)
+	db.CreateVersion(prompt.ID, "1.0.1", "Content v2", "[]", "{}", "Update", "user", &v1.ID)
+	if _, err := db.CreateTag(prompt.ID, v1.ID, "prod"); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	if _, err := db.CreateComment(prompt.ID, v1.ID, 1, "Looks good"); err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+	if err := db.EnsureTestSuite("suite-cascaded", prompt.ID, "suite-cascaded", "{}"); err != nil {
+		t.Fatalf("EnsureTestSuite failed: %v", err)
+	}
+	if _, err := db.SaveTestRun("suite-cascaded", v1.ID, "passed", "{}"); err != nil {
+		t.Fatalf("SaveTestRun failed: %v", err)
+	}
+	if err := db.StagePrompt(prompt.ID, "Content v3"); err != nil {
+		t.Fatalf("StagePrompt failed: %v", err)
+	}
+
+	// Bypass DeletePrompt so only the schema's cascades remove the children
+	if _, err := db.Exec("DELETE FROM prompts WHERE id = ?", prompt.ID); err != nil {
+		t.Fatalf("failed to delete prompt: %v", err)
+	}
+
+	for _, table := range []string{"prompt_versions", "tags", "comments", "test_suites", "test_runs", "staging"} {
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+			t.Fatalf("failed to count %s: %v", table, err)
+		}
+		if count != 0 {
+			t.Errorf("expected %s to be emptied by cascade, found %d row(s)", table, count)
+		}
+	}
+}
+
+func TestDeletePrompt(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	prompt, _ := db.CreatePrompt(project.ID, "to-delete", "Will be deleted", "prompts/delete.prompt")
+	v1, _ := db.CreateVersion(prompt.ID, "1.0.0", "Content v1", "[]", "{}", "Initial", "user", nil)
+	v2, _ := db.CreateVersion(prompt.ID, "1.0.1", "Content v2", "[]", "{}", "Update", "user", &v1.ID)
+	db.CreateTag(prompt.ID, v1.ID, "prod")
+	db.CreateComment(prompt.ID, v2.ID, 1, "Needs revision")
+
+	if err := db.EnsureTestSuite("suite-delete", prompt.ID, "suite-delete", "{}"); err != nil {
+		t.Fatalf("EnsureTestSuite failed: %v", err)
+	}
+	if _, err := db.SaveTestRun("suite-delete", v2.ID, "passed", `{"passed":1}`); err != nil {
+		t.Fatalf("SaveTestRun failed: %v", err)
+	}
+	if err := db.EnsureBenchmark("bench-delete", prompt.ID, "{}"); err != nil {
+		t.Fatalf("EnsureBenchmark failed: %v", err)
+	}
+	if _, err := db.SaveBenchmarkRun("bench-delete", v2.ID, `{"models":[]}`); err != nil {
+		t.Fatalf("SaveBenchmarkRun failed: %v", err)
+	}
+	chain, _ := db.CreateChain(project.ID, "delete-chain", "")
+	if _, err := db.CreateChainStep(chain.ID, 1, prompt.Name, `{}`, "output"); err != nil {
+		t.Fatalf("CreateChainStep failed: %v", err)
+	}
+
+	// Delete prompt (should cascade)
+	err := db.DeletePrompt(prompt.ID)
+	if err != nil {
+		t.Fatalf("DeletePrompt failed: %v", err)
+	}
+
+	// Verify prompt is gone
+	found, err := db.GetPromptByName("to-delete")
+	if err != nil {
+		t.Fatalf("GetPromptByName failed: %v", err)
+	}
+	if found != nil {
+		t.Error("expected prompt to be deleted")
+	}
+
+	// Verify versions are gone
+	versions, err := db.ListVersions(prompt.ID)
+	if err != nil {
+		t.Fatalf("ListVersions failed: %v", err)
+	}
+	if len(versions) != 0 {
+		t.Errorf("expected 0 versions, got %d", len(versions))
+	}
+
+	// Verify tags are gone
+	tags, err := db.ListTags(prompt.ID)
 	if err != nil {
 		t.Fatalf("ListTags failed: %v", err)
 	}
@@ -579,6 +1237,73 @@ func TestDeletePrompt(t *testing.T) {
 	}
 }
 
+func TestDeletePromptLeavesNoOrphanedRuns(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	doomed, _ := db.CreatePrompt(project.ID, "doomed", "", "prompts/doomed.prompt")
+	kept, _ := db.CreatePrompt(project.ID, "kept", "", "prompts/kept.prompt")
+	dv, _ := db.CreateVersion(doomed.ID, "1.0.0", "Doomed", "[]", "{}", "Initial", "user", nil)
+	kv, _ := db.CreateVersion(kept.ID, "1.0.0", "Kept", "[]", "{}", "Initial", "user", nil)
+
+	for _, p := range []struct{ suffix, promptID, versionID string }{
+		{"doomed", doomed.ID, dv.ID},
+		{"kept", kept.ID, kv.ID},
+	} {
+		if err := db.EnsureTestSuite("suite-"+p.suffix, p.promptID, "suite-"+p.suffix, "{}"); err != nil {
+			t.Fatalf("EnsureTestSuite failed: %v", err)
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := db.SaveTestRun("suite-"+p.suffix, p.versionID, "passed", "{}"); err != nil {
+				t.Fatalf("SaveTestRun failed: %v", err)
+			}
+		}
+		if err := db.EnsureBenchmark("bench-"+p.suffix, p.promptID, "{}"); err != nil {
+			t.Fatalf("EnsureBenchmark failed: %v", err)
+		}
+		if _, err := db.SaveBenchmarkRun("bench-"+p.suffix, p.versionID, "{}"); err != nil {
+			t.Fatalf("SaveBenchmarkRun failed: %v", err)
+		}
+	}
+
+	if err := db.DeletePrompt(doomed.ID); err != nil {
+		t.Fatalf("DeletePrompt failed: %v", err)
+	}
+
+	orphanQueries := map[string]string{
+		"test_suites":    "SELECT COUNT(*) FROM test_suites WHERE prompt_id NOT IN (SELECT id FROM prompts)",
+		"benchmarks":     "SELECT COUNT(*) FROM benchmarks WHERE prompt_id NOT IN (SELECT id FROM prompts)",
+		"test_runs":      "SELECT COUNT(*) FROM test_runs WHERE suite_id NOT IN (SELECT id FROM test_suites)",
+		"benchmark_runs": "SELECT COUNT(*) FROM benchmark_runs WHERE benchmark_id NOT IN (SELECT id FROM benchmarks)",
+	}
+	for table, query := range orphanQueries {
+		var count int
+		if err := db.QueryRow(query).Scan(&count); err != nil {
+			t.Fatalf("failed to count orphaned %s: %v", table, err)
+		}
+		if count != 0 {
+			t.Errorf("expected 0 orphaned %s rows, got %d", table, count)
+		}
+	}
+
+	// Runs belonging to other prompts must be untouched
+	testRuns, err := db.ListTestRuns("suite-kept")
+	if err != nil {
+		t.Fatalf("ListTestRuns failed: %v", err)
+	}
+	if len(testRuns) != 2 {
+		t.Errorf("expected 2 test runs for kept prompt, got %d", len(testRuns))
+	}
+	benchmarkRuns, err := db.ListBenchmarkRuns("bench-kept")
+	if err != nil {
+		t.Fatalf("ListBenchmarkRuns failed: %v", err)
+	}
+	if len(benchmarkRuns) != 1 {
+		t.Errorf("expected 1 benchmark run for kept prompt, got %d", len(benchmarkRuns))
+	}
+}
+
 func TestFindProjectRoot(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "promptsmith-test-*")
 	if err != nil {
@@ -714,6 +1439,58 @@ func TestSaveAndListBenchmarkRuns(t *testing.T) {
 	if runs[0].CreatedAt.Before(runs[1].CreatedAt) {
 		t.Error("expected runs ordered newest first")
 	}
+
+	// Get a single run by ID
+	got, err := db.GetBenchmarkRun(run.ID)
+	if err != nil {
+		t.Fatalf("GetBenchmarkRun failed: %v", err)
+	}
+	if got == nil || got.BenchmarkID != "bench-1" || got.VersionID != v.ID || got.Results != `{"models": []}` {
+		t.Errorf("unexpected benchmark run: %+v", got)
+	}
+
+	notFound, err := db.GetBenchmarkRun("nonexistent")
+	if err != nil {
+		t.Fatalf("GetBenchmarkRun failed: %v", err)
+	}
+	if notFound != nil {
+		t.Error("expected nil for non-existent run")
+	}
+}
+
+func TestGetLatestBenchmarkRunForPrompt(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	prompt, _ := db.CreatePrompt(project.ID, "summarizer", "", "prompts/summarizer.prompt")
+	other, _ := db.CreatePrompt(project.ID, "classifier", "", "prompts/classifier.prompt")
+
+	run, err := db.GetLatestBenchmarkRunForPrompt(prompt.ID)
+	if err != nil {
+		t.Fatalf("GetLatestBenchmarkRunForPrompt failed: %v", err)
+	}
+	if run != nil {
+		t.Error("expected nil before any benchmark runs")
+	}
+
+	db.EnsureBenchmark("bench-a", prompt.ID, "{}")
+	db.EnsureBenchmark("bench-b", prompt.ID, "{}")
+	db.EnsureBenchmark("bench-other", other.ID, "{}")
+
+	db.SaveBenchmarkRun("bench-a", "", `{"suite_name": "bench-a"}`)
+	time.Sleep(10 * time.Millisecond)
+	db.SaveBenchmarkRun("bench-b", "", `{"suite_name": "bench-b"}`)
+	time.Sleep(10 * time.Millisecond)
+	db.SaveBenchmarkRun("bench-other", "", `{"suite_name": "bench-other"}`)
+
+	run, err = db.GetLatestBenchmarkRunForPrompt(prompt.ID)
+	if err != nil {
+		t.Fatalf("GetLatestBenchmarkRunForPrompt failed: %v", err)
+	}
+	if run == nil || run.BenchmarkID != "bench-b" {
+		t.Errorf("expected latest run from bench-b, got %+v", run)
+	}
 }
 
 func TestEnsureTestSuiteAndBenchmark(t *testing.T) {
@@ -898,6 +1675,66 @@ func TestChainSteps(t *testing.T) {
 	}
 }
 
+func TestPlanChainSteps(t *testing.T) {
+	step := func(order int, key, mapping, dependsOn string) *ChainStep {
+		return &ChainStep{StepOrder: order, OutputKey: key, InputMapping: mapping, DependsOn: dependsOn}
+	}
+	waveKeys := func(waves [][]*ChainStep) [][]string {
+		keys := make([][]string, len(waves))
+		for i, wave := range waves {
+			for _, s := range wave {
+				keys[i] = append(keys[i], s.OutputKey)
+			}
+		}
+		return keys
+	}
+
+	// Without declared dependencies every step waits for the one before,
+	// even when it does not use its output
+	waves, err := PlanChainSteps([]*ChainStep{
+		step(2, "b", `{"text":"{{input.text}}"}`, ""),
+		step(1, "a", `{"text":"{{input.text}}"}`, ""),
+		step(3, "c", `{"text":"{{steps.a.output}}"}`, ""),
+	})
+	if err != nil {
+		t.Fatalf("PlanChainSteps failed: %v", err)
+	}
+	if got, want := waveKeys(waves), [][]string{{"a"}, {"b"}, {"c"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("linear plan = %v, want %v", got, want)
+	}
+
+	// A diamond: both branches run together, then the merge
+	waves, err = PlanChainSteps([]*ChainStep{
+		step(1, "merged", `{"a":"{{steps.left.output}}"}`, `["right"]`),
+		step(2, "left", `{"text":"{{input.text}}"}`, ""),
+		step(3, "right", `{"text":"{{input.text}}"}`, ""),
+	})
+	if err != nil {
+		t.Fatalf("PlanChainSteps failed: %v", err)
+	}
+	if got, want := waveKeys(waves), [][]string{{"left", "right"}, {"merged"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("diamond plan = %v, want %v", got, want)
+	}
+
+	_, err = PlanChainSteps([]*ChainStep{
+		step(1, "a", `{}`, `["missing"]`),
+	})
+	if err == nil || !strings.Contains(err.Error(), "'missing'") {
+		t.Errorf("expected an error for an unknown dependency, got %v", err)
+	}
+
+	_, err = PlanChainSteps([]*ChainStep{
+		step(1, "root", `{}`, ""),
+		step(2, "a", `{"x":"{{steps.root.output}}"}`, `["c"]`),
+		step(3, "b", `{"x":"{{steps.a.output}}"}`, ""),
+		step(4, "c", `{"x":"{{steps.b.output}}"}`, ""),
+	})
+	want := "dependency cycle: step 2 (a) depends on step 4 (c) depends on step 3 (b) depends on step 2 (a)"
+	if err == nil || err.Error() != want {
+		t.Errorf("cycle error = %v, want %q", err, want)
+	}
+}
+
 func TestChainRuns(t *testing.T) {
 	db, _, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -906,7 +1743,7 @@ func TestChainRuns(t *testing.T) {
 	chain, _ := db.CreateChain(project.ID, "my-chain", "")
 
 	// Save run
-	run, err := db.SaveChainRun(chain.ID, "completed", `{"text":"hello"}`, `[{"step":"s1","output":"hi"}]`, "hi")
+	run, err := db.SaveChainRun(chain.ID, "completed", `{"text":"hello"}`, `[{"step":"s1","output":"hi"}]`, "hi", 0, 0)
 	if err != nil {
 		t.Fatalf("SaveChainRun failed: %v", err)
 	}
@@ -914,10 +1751,10 @@ func TestChainRuns(t *testing.T) {
 		t.Errorf("expected status 'completed', got '%s'", run.Status)
 	}
 
-	db.SaveChainRun(chain.ID, "failed", `{}`, `[]`, "")
+	db.SaveChainRun(chain.ID, "failed", `{}`, `[]`, "", 0, 0)
 
 	// List runs
-	runs, err := db.ListChainRuns(chain.ID)
+	runs, err := db.ListChainRuns(chain.ID, ChainRunFilter{})
 	if err != nil {
 		t.Fatalf("ListChainRuns failed: %v", err)
 	}
@@ -926,6 +1763,91 @@ func TestChainRuns(t *testing.T) {
 	}
 }
 
+func TestChainRunTotals(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	chain, _ := db.CreateChain(project.ID, "my-chain", "")
+
+	if _, err := db.SaveChainRun(chain.ID, "completed", `{}`, `[]`, "hi", 150, 0.0025); err != nil {
+		t.Fatalf("SaveChainRun failed: %v", err)
+	}
+	runs, _ := db.ListChainRuns(chain.ID, ChainRunFilter{})
+	if len(runs) != 1 || runs[0].TotalTokens != 150 || runs[0].TotalCost != 0.0025 {
+		t.Fatalf("expected stored totals, got %+v", runs)
+	}
+
+	// Runs saved before totals were stored get them from their step results
+	steps := `[{"prompt_tokens":10,"output_tokens":5,"cost":0.001},{"prompt_tokens":20,"output_tokens":7,"cost":0.002},{"output":"no usage"}]`
+	db.Exec(`INSERT INTO chain_runs (id, chain_id, status, inputs, results, final_output) VALUES ('old', ?, 'completed', '{}', ?, '')`, chain.ID, steps)
+	db.Exec(`INSERT INTO chain_runs (id, chain_id, status, inputs, results, final_output) VALUES ('broken', ?, 'failed', '{}', 'not json', '')`, chain.ID)
+
+	tx, _ := db.Begin()
+	if err := execSQL(schemaV14)(tx); err != nil {
+		tx.Rollback()
+		t.Fatalf("backfill failed: %v", err)
+	}
+	tx.Commit()
+
+	var tokens int
+	var cost float64
+	db.QueryRow("SELECT total_tokens, total_cost FROM chain_runs WHERE id = 'old'").Scan(&tokens, &cost)
+	if tokens != 42 || math.Abs(cost-0.003) > 1e-9 {
+		t.Errorf("expected 42 tokens and $0.003 backfilled, got %d and %g", tokens, cost)
+	}
+	db.QueryRow("SELECT total_tokens FROM chain_runs WHERE id = 'broken'").Scan(&tokens)
+	if tokens != 0 {
+		t.Errorf("expected unparseable results to keep zero totals, got %d", tokens)
+	}
+	// Totals already stored are left alone
+	db.QueryRow("SELECT total_tokens FROM chain_runs WHERE id = ?", runs[0].ID).Scan(&tokens)
+	if tokens != 150 {
+		t.Errorf("expected stored totals to be kept, got %d tokens", tokens)
+	}
+}
+
+func TestListChainRunsFilter(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	chain, _ := db.CreateChain(project.ID, "pipeline", "")
+	for _, status := range []string{"completed", "failed", "completed", "failed", "failed"} {
+		if _, err := db.SaveChainRun(chain.ID, status, `{}`, `[]`, "", 0, 0); err != nil {
+			t.Fatalf("SaveChainRun failed: %v", err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	failed, err := db.ListChainRuns(chain.ID, ChainRunFilter{Status: "failed"})
+	if err != nil {
+		t.Fatalf("ListChainRuns failed: %v", err)
+	}
+	if len(failed) != 3 {
+		t.Fatalf("expected 3 failed runs, got %d", len(failed))
+	}
+	for _, r := range failed {
+		if r.Status != "failed" {
+			t.Errorf("expected only failed runs, got %s", r.Status)
+		}
+	}
+
+	page, err := db.ListChainRuns(chain.ID, ChainRunFilter{Status: "failed", Limit: 2, Offset: 1})
+	if err != nil {
+		t.Fatalf("ListChainRuns failed: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != failed[1].ID || page[1].ID != failed[2].ID {
+		t.Errorf("expected the second and third failed runs, got %d runs", len(page))
+	}
+
+	// An offset alone skips runs without limiting the rest
+	rest, _ := db.ListChainRuns(chain.ID, ChainRunFilter{Offset: 3})
+	if len(rest) != 2 {
+		t.Errorf("expected 2 runs after offset 3, got %d", len(rest))
+	}
+}
+
 func TestDeleteChainCascade(t *testing.T) {
 	db, _, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -933,7 +1855,7 @@ func TestDeleteChainCascade(t *testing.T) {
 	project, _ := db.CreateProject("test-project")
 	chain, _ := db.CreateChain(project.ID, "to-delete", "")
 	db.CreateChainStep(chain.ID, 1, "prompt", `{}`, "out")
-	db.SaveChainRun(chain.ID, "completed", `{}`, `[]`, "result")
+	db.SaveChainRun(chain.ID, "completed", `{}`, `[]`, "result", 0, 0)
 
 	err := db.DeleteChain(chain.ID)
 	if err != nil {
@@ -951,7 +1873,7 @@ func TestDeleteChainCascade(t *testing.T) {
 		t.Errorf("expected 0 steps, got %d", len(steps))
 	}
 
-	runs, _ := db.ListChainRuns(chain.ID)
+	runs, _ := db.ListChainRuns(chain.ID, ChainRunFilter{})
 	if len(runs) != 0 {
 		t.Errorf("expected 0 runs, got %d", len(runs))
 	}
@@ -989,3 +1911,631 @@ func TestGetVersionByID(t *testing.T) {
 		t.Error("expected nil for non-existent version")
 	}
 }
+
+func TestGetVersionAncestry(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	prompt, _ := db.CreatePrompt(project.ID, "history", "", "prompts/history.prompt")
+	v1, _ := db.CreateVersion(prompt.ID, "1.0.0", "v1", "[]", "{}", "First", "user", nil)
+	v2, _ := db.CreateVersion(prompt.ID, "1.0.1", "v2", "[]", "{}", "Second", "user", &v1.ID)
+	v3, _ := db.CreateVersion(prompt.ID, "1.0.2", "v3", "[]", "{}", "Third", "user", &v2.ID)
+
+	ancestry, err := db.GetVersionAncestry(v3.ID)
+	if err != nil {
+		t.Fatalf("GetVersionAncestry failed: %v", err)
+	}
+	want := []string{"1.0.2", "1.0.1", "1.0.0"}
+	if len(ancestry) != len(want) {
+		t.Fatalf("expected %d versions, got %d", len(want), len(ancestry))
+	}
+	for i, v := range ancestry {
+		if v.Version != want[i] {
+			t.Errorf("ancestry[%d]: expected %s, got %s", i, want[i], v.Version)
+		}
+	}
+
+	ancestry, err = db.GetVersionAncestry("nonexistent")
+	if err != nil {
+		t.Fatalf("GetVersionAncestry failed: %v", err)
+	}
+	if len(ancestry) != 0 {
+		t.Errorf("expected empty ancestry for unknown version, got %d", len(ancestry))
+	}
+}
+
+func TestGetVersionAncestrySelfReference(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	prompt, _ := db.CreatePrompt(project.ID, "loop", "", "prompts/loop.prompt")
+	v1, _ := db.CreateVersion(prompt.ID, "1.0.0", "v1", "[]", "{}", "First", "user", nil)
+
+	if _, err := db.Exec("UPDATE prompt_versions SET parent_version_id = id WHERE id = ?", v1.ID); err != nil {
+		t.Fatalf("failed to create self-reference: %v", err)
+	}
+
+	ancestry, err := db.GetVersionAncestry(v1.ID)
+	if err != nil {
+		t.Fatalf("GetVersionAncestry failed: %v", err)
+	}
+	if len(ancestry) != 1 || ancestry[0].ID != v1.ID {
+		t.Errorf("expected ancestry to contain only the version itself, got %d entries", len(ancestry))
+	}
+}
+
+func TestListVersionsByAuthor(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	prompt, _ := db.CreatePrompt(project.ID, "authored", "", "prompts/authored.prompt")
+	v1, _ := db.CreateVersion(prompt.ID, "1.0.0", "v1", "[]", "{}", "First", "alice", nil)
+	v2, _ := db.CreateVersion(prompt.ID, "1.0.1", "v2", "[]", "{}", "Second", "bob", &v1.ID)
+	db.CreateVersion(prompt.ID, "1.0.2", "v3", "[]", "{}", "Third", "alice", &v2.ID)
+
+	versions, err := db.ListVersionsByAuthor(prompt.ID, "alice")
+	if err != nil {
+		t.Fatalf("ListVersionsByAuthor failed: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions by alice, got %d", len(versions))
+	}
+	for _, v := range versions {
+		if v.CreatedBy != "alice" {
+			t.Errorf("expected author alice, got %s", v.CreatedBy)
+		}
+	}
+
+	all, err := db.ListVersionsByAuthor(prompt.ID, "")
+	if err != nil {
+		t.Fatalf("ListVersionsByAuthor failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("expected 3 versions without filter, got %d", len(all))
+	}
+
+	none, err := db.ListVersionsByAuthor(prompt.ID, "carol")
+	if err != nil {
+		t.Fatalf("ListVersionsByAuthor failed: %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("expected 0 versions by carol, got %d", len(none))
+	}
+}
+
+func TestGetLatestVersionSameTimestamp(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	prompt, _ := db.CreatePrompt(project.ID, "fast", "", "prompts/fast.prompt")
+	v1, _ := db.CreateVersion(prompt.ID, "1.0.9", "v9", "[]", "{}", "Ninth", "", nil)
+	db.CreateVersion(prompt.ID, "1.0.10", "v10", "[]", "{}", "Tenth", "", &v1.ID)
+
+	// Force both versions onto the same timestamp so only the version
+	// number can decide which one is latest.
+	if _, err := db.Exec("UPDATE prompt_versions SET created_at = ? WHERE prompt_id = ?", v1.CreatedAt, prompt.ID); err != nil {
+		t.Fatalf("failed to align timestamps: %v", err)
+	}
+
+	latest, err := db.GetLatestVersion(prompt.ID)
+	if err != nil {
+		t.Fatalf("GetLatestVersion failed: %v", err)
+	}
+	if latest.Version != "1.0.10" {
+		t.Errorf("expected latest version 1.0.10, got %s", latest.Version)
+	}
+
+	versions, err := db.ListVersions(prompt.ID)
+	if err != nil {
+		t.Fatalf("ListVersions failed: %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != "1.0.10" {
+		t.Errorf("expected 1.0.10 listed first, got %v", versions[0].Version)
+	}
+
+	prompts, err := db.ListPromptsWithLatestVersion(false)
+	if err != nil {
+		t.Fatalf("ListPromptsWithLatestVersion failed: %v", err)
+	}
+	if len(prompts) != 1 || prompts[0].LatestVersion != "1.0.10" {
+		t.Errorf("expected prompt latest version 1.0.10, got %+v", prompts)
+	}
+}
+
+func seedSearchVersions(t *testing.T, db *DB) map[string]string {
+	t.Helper()
+
+	project, _ := db.CreateProject("test-project")
+	summarizer, _ := db.CreatePrompt(project.ID, "summarizer", "", "prompts/summarizer.prompt")
+	support, _ := db.CreatePrompt(project.ID, "support", "", "prompts/support.prompt")
+	greeter, _ := db.CreatePrompt(project.ID, "greeter", "", "prompts/greeter.prompt")
+
+	ids := map[string]string{}
+	for _, v := range []struct {
+		prompt  *Prompt
+		version string
+		content string
+	}{
+		{summarizer, "1.0.0", "Summarize the document in three sentences."},
+		{summarizer, "1.1.0", "Summarize the document. Mention the Refund Policy if the customer asks."},
+		{support, "1.0.0", "You are a support agent. Always follow the refund policy when answering."},
+		{greeter, "1.0.0", "Greet the user by name and offer help with refunds."},
+	} {
+		created, err := db.CreateVersion(v.prompt.ID, v.version, v.content, "[]", "{}", "", "user", nil)
+		if err != nil {
+			t.Fatalf("CreateVersion failed: %v", err)
+		}
+		ids[v.prompt.Name+"@"+v.version] = created.ID
+	}
+	return ids
+}
+
+func assertSearchMatches(t *testing.T, db *DB, query string, want ...string) {
+	t.Helper()
+
+	results, err := db.SearchVersions(query)
+	if err != nil {
+		t.Fatalf("SearchVersions(%q) failed: %v", query, err)
+	}
+
+	got := map[string]bool{}
+	for _, r := range results {
+		got[r.PromptName+"@"+r.Version] = true
+		if !strings.Contains(strings.ToLower(r.Snippet), strings.ToLower(SnippetMatchStart+query+SnippetMatchEnd)) {
+			t.Errorf("expected snippet for %s@%s to highlight %q, got %q", r.PromptName, r.Version, query, r.Snippet)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("SearchVersions(%q) matched %v, want %v", query, got, want)
+	}
+	for _, w := range want {
+		if !got[w] {
+			t.Errorf("SearchVersions(%q) did not match %s, got %v", query, w, got)
+		}
+	}
+}
+
+// assertSearchRanking checks match counts, the ordering by them, and that
+// snippets keep the words around a match
+func assertSearchRanking(t *testing.T, db *DB) {
+	t.Helper()
+
+	project, _ := db.GetProject()
+	faq, _ := db.CreatePrompt(project.ID, "faq", "", "prompts/faq.prompt")
+	if _, err := db.CreateVersion(faq.ID, "1.0.0", "Quote the refund policy. If unsure, re-read the refund policy.", "[]", "{}", "", "user", nil); err != nil {
+		t.Fatalf("CreateVersion failed: %v", err)
+	}
+
+	results, err := db.SearchVersionsWithSnippets("refund policy")
+	if err != nil {
+		t.Fatalf("SearchVersionsWithSnippets failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 matches, got %+v", results)
+	}
+	if results[0].Prompt != "faq" || results[0].MatchCount != 2 {
+		t.Errorf("expected faq with 2 matches first, got %+v", results[0])
+	}
+	for _, r := range results[1:] {
+		if r.MatchCount != 1 {
+			t.Errorf("expected 1 match for %s@%s, got %d", r.Prompt, r.Version, r.MatchCount)
+		}
+		if r.Prompt == "support" {
+			snippet := strings.ReplaceAll(strings.ReplaceAll(r.Snippet, SnippetMatchStart, ""), SnippetMatchEnd, "")
+			if !strings.Contains(snippet, "Always follow the refund policy when answering") {
+				t.Errorf("expected snippet to keep the context of the match, got %q", r.Snippet)
+			}
+		}
+	}
+
+	if results, err := db.SearchVersionsWithSnippets("invoice"); err != nil || results != nil {
+		t.Errorf("expected no matches, got %+v (err %v)", results, err)
+	}
+}
+
+func TestSearchVersions(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ids := seedSearchVersions(t, db)
+
+	assertSearchMatches(t, db, "refund policy", "summarizer@1.1.0", "support@1.0.0")
+	assertSearchMatches(t, db, "summarize the document", "summarizer@1.0.0", "summarizer@1.1.0")
+	assertSearchMatches(t, db, "invoice")
+	assertSearchRanking(t, db)
+
+	// Edited content is reindexed
+	if err := db.UpdateVersionContent(ids["support@1.0.0"], "You are a support agent. Escalate invoice questions.", "[]", "{}"); err != nil {
+		t.Fatalf("UpdateVersionContent failed: %v", err)
+	}
+	assertSearchMatches(t, db, "refund policy", "faq@1.0.0", "summarizer@1.1.0")
+	assertSearchMatches(t, db, "invoice", "support@1.0.0")
+
+	if _, err := db.SearchVersions("  "); err == nil {
+		t.Error("expected an error for an empty query")
+	}
+}
+
+func TestSearchVersionsWithoutIndex(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Databases built without FTS5 have no index table or triggers
+	if _, err := db.Exec(`
+		DROP TRIGGER IF EXISTS prompt_versions_fts_insert;
+		DROP TRIGGER IF EXISTS prompt_versions_fts_update;
+		DROP TRIGGER IF EXISTS prompt_versions_fts_delete;
+		DROP TABLE IF EXISTS prompt_versions_fts;
+	`); err != nil {
+		t.Fatalf("failed to drop search index: %v", err)
+	}
+
+	seedSearchVersions(t, db)
+
+	assertSearchMatches(t, db, "refund policy", "summarizer@1.1.0", "support@1.0.0")
+	assertSearchMatches(t, db, "100%")
+
+	results, err := db.SearchVersions("follow the refund")
+	if err != nil {
+		t.Fatalf("SearchVersions failed: %v", err)
+	}
+	want := "You are a support agent. Always " + SnippetMatchStart + "follow the refund" + SnippetMatchEnd + " policy when answering."
+	if len(results) != 1 || results[0].Snippet != want {
+		t.Errorf("expected snippet %q, got %+v", want, results)
+	}
+	assertSearchRanking(t, db)
+
+	// The substring scan stops at the same limit as the index
+	project, _ := db.GetProject()
+	bulk, _ := db.CreatePrompt(project.ID, "bulk", "", "prompts/bulk.prompt")
+	for i := 0; i <= searchLimit; i++ {
+		if _, err := db.CreateVersion(bulk.ID, fmt.Sprintf("1.0.%d", i), "Mention the warranty.", "[]", "{}", "", "user", nil); err != nil {
+			t.Fatalf("CreateVersion failed: %v", err)
+		}
+	}
+	if results, _ := db.SearchVersions("warranty"); len(results) != searchLimit {
+		t.Errorf("expected %d results, got %d", searchLimit, len(results))
+	}
+}
+
+func TestSearchIndexCreatedOnOpen(t *testing.T) {
+	db, tmpDir, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.Exec("CREATE VIRTUAL TABLE temp.fts5_probe USING fts5(content)"); err != nil {
+		t.Skip("sqlite built without FTS5; build with -tags sqlite_fts5")
+	}
+
+	// As left by a binary without FTS5: migrated, but with no index
+	if _, err := db.Exec(`
+		DROP TRIGGER IF EXISTS prompt_versions_fts_insert;
+		DROP TRIGGER IF EXISTS prompt_versions_fts_update;
+		DROP TRIGGER IF EXISTS prompt_versions_fts_delete;
+		DROP TABLE IF EXISTS prompt_versions_fts;
+	`); err != nil {
+		t.Fatalf("failed to drop search index: %v", err)
+	}
+	seedSearchVersions(t, db)
+	db.Close()
+
+	reopened, err := Open(tmpDir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer reopened.Close()
+
+	if indexed, _ := reopened.hasSearchIndex(); !indexed {
+		t.Fatal("expected Open to create the missing search index")
+	}
+	var count int
+	reopened.QueryRow("SELECT COUNT(*) FROM prompt_versions_fts").Scan(&count)
+	var versions int
+	reopened.QueryRow("SELECT COUNT(*) FROM prompt_versions").Scan(&versions)
+	if count != versions {
+		t.Errorf("expected existing versions to be indexed, got %d of %d", count, versions)
+	}
+	assertSearchMatches(t, reopened, "refund policy", "summarizer@1.1.0", "support@1.0.0")
+}
+
+func TestSetPromptFrozen(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	prompt, _ := db.CreatePrompt(project.ID, "summarizer", "", "prompts/summarizer.prompt")
+	v1, _ := db.CreateVersion(prompt.ID, "1.0.0", "v1", "[]", "{}", "Initial", "user", nil)
+
+	if err := db.SetPromptFrozen(prompt.ID, true); err != nil {
+		t.Fatalf("SetPromptFrozen failed: %v", err)
+	}
+	got, _ := db.GetPromptByName("summarizer")
+	if !got.Frozen {
+		t.Error("expected prompt to be frozen")
+	}
+
+	if _, err := db.CreateVersion(prompt.ID, "1.0.1", "v2", "[]", "{}", "Blocked", "user", &v1.ID); err == nil || !strings.Contains(err.Error(), "frozen") {
+		t.Errorf("expected CreateVersion to refuse a frozen prompt, got %v", err)
+	}
+	err := db.CreateVersions([]*PromptVersion{{PromptID: prompt.ID, Version: "1.0.1", Content: "v2", ParentVersionID: &v1.ID}})
+	if err == nil || !strings.Contains(err.Error(), "frozen") {
+		t.Errorf("expected CreateVersions to refuse a frozen prompt, got %v", err)
+	}
+	if versions, _ := db.ListVersions(prompt.ID); len(versions) != 1 {
+		t.Errorf("expected no new versions, got %d", len(versions))
+	}
+
+	// Existing versions cannot be rewritten either, e.g. by pull --strategy theirs
+	if err := db.UpdateVersionContent(v1.ID, "rewritten", "[]", "{}"); err == nil || !strings.Contains(err.Error(), "frozen") {
+		t.Errorf("expected UpdateVersionContent to refuse a frozen prompt, got %v", err)
+	}
+	if v, _ := db.GetVersionByID(v1.ID); v == nil || v.Content != "v1" {
+		t.Errorf("expected v1's content to be kept, got %+v", v)
+	}
+
+	if err := db.SetPromptFrozen(prompt.ID, false); err != nil {
+		t.Fatalf("SetPromptFrozen failed: %v", err)
+	}
+	if _, err := db.CreateVersion(prompt.ID, "1.0.1", "v2", "[]", "{}", "Unfrozen", "user", &v1.ID); err != nil {
+		t.Errorf("expected CreateVersion to succeed once unfrozen: %v", err)
+	}
+
+	if err := db.SetPromptFrozen("nonexistent-id", true); err == nil {
+		t.Error("expected error for a missing prompt")
+	}
+}
+
+func TestComments(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	prompt, _ := db.CreatePrompt(project.ID, "summarizer", "", "prompts/summarizer.prompt")
+	version, _ := db.CreateVersion(prompt.ID, "1.0.0", "line 1\nline 2", "[]", "{}", "Initial", "user", nil)
+
+	second, err := db.CreateComment(prompt.ID, version.ID, 2, "Too vague")
+	if err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+	first, err := db.CreateComment(prompt.ID, version.ID, 1, "Good opening")
+	if err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+
+	got, err := db.GetComment(second.ID)
+	if err != nil {
+		t.Fatalf("GetComment failed: %v", err)
+	}
+	if got == nil || got.Content != "Too vague" || got.LineNumber != 2 || got.VersionID != version.ID {
+		t.Errorf("unexpected comment %+v", got)
+	}
+	if missing, _ := db.GetComment("nonexistent"); missing != nil {
+		t.Error("expected nil for a nonexistent comment")
+	}
+
+	comments, err := db.ListComments(prompt.ID)
+	if err != nil {
+		t.Fatalf("ListComments failed: %v", err)
+	}
+	if len(comments) != 2 || comments[0].ID != first.ID || comments[1].ID != second.ID {
+		t.Errorf("expected comments ordered by line, got %d comments", len(comments))
+	}
+
+	if err := db.DeleteComment(first.ID); err != nil {
+		t.Fatalf("DeleteComment failed: %v", err)
+	}
+	if err := db.DeleteComment(first.ID); err == nil {
+		t.Error("expected error deleting a comment twice")
+	}
+	comments, _ = db.ListComments(prompt.ID)
+	if len(comments) != 1 {
+		t.Errorf("expected 1 comment after delete, got %d", len(comments))
+	}
+
+	// Comments go with their prompt
+	if err := db.DeletePrompt(prompt.ID); err != nil {
+		t.Fatalf("DeletePrompt failed: %v", err)
+	}
+	if c, _ := db.GetComment(second.ID); c != nil {
+		t.Error("expected comments to be deleted with their prompt")
+	}
+}
+
+func TestCreateCommentRejectsOtherPromptsVersion(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	summarizer, _ := db.CreatePrompt(project.ID, "summarizer", "", "prompts/summarizer.prompt")
+	translator, _ := db.CreatePrompt(project.ID, "translator", "", "prompts/translator.prompt")
+	version, _ := db.CreateVersion(translator.ID, "1.0.0", "Translate", "[]", "{}", "Initial", "user", nil)
+
+	if _, err := db.CreateComment(summarizer.ID, version.ID, 1, "Wrong prompt"); err == nil {
+		t.Error("expected error commenting on another prompt's version")
+	}
+	if _, err := db.CreateComment(summarizer.ID, "nonexistent", 1, "No version"); err == nil {
+		t.Error("expected error commenting on a nonexistent version")
+	}
+	if comments, _ := db.ListComments(summarizer.ID); len(comments) != 0 {
+		t.Errorf("expected no comments, got %d", len(comments))
+	}
+}
+
+func TestGetRecentActivityIncludesTagsAndComments(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	prompt, _ := db.CreatePrompt(project.ID, "summarizer", "", "prompts/summarizer.prompt")
+	v, _ := db.CreateVersion(prompt.ID, "1.0.0", "Summarize", "[]", "{}", "Initial", "user", nil)
+	time.Sleep(10 * time.Millisecond)
+	if _, err := db.CreateTag(prompt.ID, v.ID, "prod"); err != nil {
+		t.Fatalf("CreateTag failed: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err := db.CreateComment(prompt.ID, v.ID, 1, "Too terse?"); err != nil {
+		t.Fatalf("CreateComment failed: %v", err)
+	}
+
+	events, err := db.GetRecentActivity(10)
+	if err != nil {
+		t.Fatalf("GetRecentActivity failed: %v", err)
+	}
+
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Type+":"+e.Title+":"+e.Detail+":"+e.PromptName)
+	}
+	want := []string{
+		"comment:v1.0.0 line 1:Too terse?:summarizer",
+		"tag:prod:v1.0.0:summarizer",
+		"version:v1.0.0:Initial:summarizer",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("activity = %v, want %v", got, want)
+	}
+}
+
+func TestGetRecentActivityOrderAndLimit(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	prompt, _ := db.CreatePrompt(project.ID, "summarizer", "", "prompts/summarizer.prompt")
+	if err := db.EnsureTestSuite("suite-1", prompt.ID, "summarizer-tests", "{}"); err != nil {
+		t.Fatalf("EnsureTestSuite failed: %v", err)
+	}
+	if err := db.EnsureBenchmark("bench-1", prompt.ID, "{}"); err != nil {
+		t.Fatalf("EnsureBenchmark failed: %v", err)
+	}
+
+	step := func() { time.Sleep(10 * time.Millisecond) }
+	v1, _ := db.CreateVersion(prompt.ID, "1.0.0", "Summarize", "[]", "{}", "Initial", "user", nil)
+	step()
+	if _, err := db.SaveTestRun("suite-1", v1.ID, "failed", "[]"); err != nil {
+		t.Fatalf("SaveTestRun failed: %v", err)
+	}
+	step()
+	db.CreateVersion(prompt.ID, "1.0.1", "Summarize briefly", "[]", "{}", "Be brief", "user", nil)
+	step()
+	if _, err := db.SaveBenchmarkRun("bench-1", v1.ID, "{}"); err != nil {
+		t.Fatalf("SaveBenchmarkRun failed: %v", err)
+	}
+	step()
+	db.CreateTag(prompt.ID, v1.ID, "prod")
+
+	events, err := db.GetRecentActivity(10)
+	if err != nil {
+		t.Fatalf("GetRecentActivity failed: %v", err)
+	}
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Type+":"+e.Title+":"+e.Detail+":"+e.PromptName)
+	}
+	want := []string{
+		ActivityTag + ":prod:v1.0.0:summarizer",
+		ActivityBenchmarkRun + ":completed:bench-1:summarizer",
+		ActivityVersion + ":v1.0.1:Be brief:summarizer",
+		ActivityTestRun + ":failed:summarizer-tests:summarizer",
+		ActivityVersion + ":v1.0.0:Initial:summarizer",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("activity = %v, want %v", got, want)
+	}
+
+	events, _ = db.GetRecentActivity(2)
+	if len(events) != 2 || events[0].Type != ActivityTag || events[1].Type != ActivityBenchmarkRun {
+		t.Errorf("expected the 2 newest events, got %+v", events)
+	}
+}
+
+func TestGetPromptHealth(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	tested, _ := db.CreatePrompt(project.ID, "summarizer", "", "prompts/summarizer.prompt")
+	untested, _ := db.CreatePrompt(project.ID, "greeter", "", "prompts/greeter.prompt")
+
+	v1, _ := db.CreateVersion(tested.ID, "1.0.0", "Summarize", "[]", "{}", "Initial", "user", nil)
+	db.CreateVersion(tested.ID, "1.0.1", "Summarize this text", "[]", "{}", "Clarify", "user", nil)
+	db.CreateVersion(untested.ID, "1.0.0", "Hello", "[]", "{}", "Initial", "user", nil)
+
+	db.EnsureTestSuite("suite-1", tested.ID, "summarizer-tests", "{}")
+	for _, status := range []string{"passed", "passed", "failed"} {
+		db.SaveTestRun("suite-1", v1.ID, status, "[]")
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	health, err := db.GetPromptHealth()
+	if err != nil {
+		t.Fatalf("GetPromptHealth failed: %v", err)
+	}
+	if len(health) != 2 || health[0].PromptName != "greeter" || health[1].PromptName != "summarizer" {
+		t.Fatalf("expected health for greeter and summarizer, got %+v", health)
+	}
+
+	greeter, summarizer := health[0], health[1]
+	if greeter.VersionCount != 1 || greeter.LastTestStatus != "none" || greeter.LastTestAt != "" || greeter.TestPassRate != 0 {
+		t.Errorf("unexpected health for an untested prompt: %+v", greeter)
+	}
+	if summarizer.VersionCount != 2 || summarizer.CommitsLast30d != 2 {
+		t.Errorf("expected 2 versions, both recent, got %+v", summarizer)
+	}
+	if summarizer.LastTestStatus != "failed" || summarizer.LastTestAt == "" {
+		t.Errorf("expected the latest test run to have failed, got %+v", summarizer)
+	}
+	if math.Abs(summarizer.TestPassRate-2.0/3.0) > 1e-9 {
+		t.Errorf("expected a pass rate of 2/3, got %v", summarizer.TestPassRate)
+	}
+	if summarizer.EstimatedTokens != prompt.EstimateTokens("Summarize this text") {
+		t.Errorf("expected tokens of the latest version, got %d", summarizer.EstimatedTokens)
+	}
+}
+
+func TestPullConflicts(t *testing.T) {
+	db, _, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := db.CreateProject("test-project")
+	prompt, _ := db.CreatePrompt(project.ID, "greeting", "", "prompts/greeting.prompt")
+
+	if c, err := db.GetPullConflict(prompt.ID, "1.0.0"); err != nil || c != nil {
+		t.Fatalf("expected no conflict, got %+v (err %v)", c, err)
+	}
+
+	conflict := &PullConflict{PromptID: prompt.ID, Version: "1.0.0", RemoteContent: "Remote", Strategy: "manual"}
+	if err := db.RecordPullConflict(conflict); err != nil {
+		t.Fatalf("RecordPullConflict failed: %v", err)
+	}
+	// Recording the same version again replaces the earlier record
+	conflict.RemoteContent = "Remote again"
+	if err := db.RecordPullConflict(conflict); err != nil {
+		t.Fatalf("RecordPullConflict failed: %v", err)
+	}
+
+	unresolved, err := db.ListUnresolvedPullConflicts()
+	if err != nil {
+		t.Fatalf("ListUnresolvedPullConflicts failed: %v", err)
+	}
+	if len(unresolved) != 1 || unresolved[0].PromptName != "greeting" || unresolved[0].RemoteContent != "Remote again" {
+		t.Fatalf("expected one unresolved conflict for greeting, got %+v", unresolved)
+	}
+
+	version, _ := db.CreateVersion(prompt.ID, "1.0.1", "Reviewed", "[]", "{}", "reviewed", "tester", nil)
+	if err := db.ResolvePullConflicts(prompt.ID, version.ID); err != nil {
+		t.Fatalf("ResolvePullConflicts failed: %v", err)
+	}
+
+	unresolved, _ = db.ListUnresolvedPullConflicts()
+	if len(unresolved) != 0 {
+		t.Errorf("expected no unresolved conflicts, got %+v", unresolved)
+	}
+	c, _ := db.GetPullConflict(prompt.ID, "1.0.0")
+	if c == nil || c.ResolvedVersionID == nil || *c.ResolvedVersionID != version.ID {
+		t.Errorf("expected the conflict to be resolved by %s, got %+v", version.ID, c)
+	}
+}
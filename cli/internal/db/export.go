@@ -0,0 +1,237 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ExportFormatVersion is the version of the PromptExport document format
+const ExportFormatVersion = 1
+
+// PromptExport is a prompt's full history as a self-contained JSON document
+type PromptExport struct {
+	FormatVersion int               `json:"format_version"`
+	ExportedAt    time.Time         `json:"exported_at"`
+	Prompt        ExportedPrompt    `json:"prompt"`
+	Versions      []ExportedVersion `json:"versions"` // Oldest first
+	Tags          []ExportedTag     `json:"tags"`
+}
+
+type ExportedPrompt struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	FilePath    string    `json:"file_path"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type ExportedVersion struct {
+	Version       string          `json:"version"`
+	Parent        string          `json:"parent,omitempty"` // Version string of the parent
+	Content       string          `json:"content"`
+	Variables     json.RawMessage `json:"variables,omitempty"`
+	Metadata      json.RawMessage `json:"metadata,omitempty"`
+	CommitMessage string          `json:"commit_message"`
+	CreatedBy     string          `json:"created_by,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type ExportedTag struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ExportPrompt assembles the named prompt with all its versions and tags.
+// It returns nil if the prompt does not exist.
+func (db *DB) ExportPrompt(name string) (*PromptExport, error) {
+	p, err := db.GetPromptByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, nil
+	}
+
+	versions, err := db.ListVersions(p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	tags, err := db.ListTags(p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	versionByID := make(map[string]string, len(versions))
+	for _, v := range versions {
+		versionByID[v.ID] = v.Version
+	}
+
+	export := &PromptExport{
+		FormatVersion: ExportFormatVersion,
+		ExportedAt:    time.Now().UTC(),
+		Prompt: ExportedPrompt{
+			Name:        p.Name,
+			Description: p.Description,
+			FilePath:    p.FilePath,
+			CreatedAt:   p.CreatedAt,
+		},
+		Versions: make([]ExportedVersion, 0, len(versions)),
+		Tags:     make([]ExportedTag, 0, len(tags)),
+	}
+
+	// ListVersions is newest first; history reads oldest first
+	for i := len(versions) - 1; i >= 0; i-- {
+		v := versions[i]
+		ev := ExportedVersion{
+			Version:       v.Version,
+			Content:       v.Content,
+			Variables:     rawJSON(v.Variables),
+			Metadata:      rawJSON(v.Metadata),
+			CommitMessage: v.CommitMessage,
+			CreatedBy:     v.CreatedBy,
+			CreatedAt:     v.CreatedAt,
+		}
+		if v.ParentVersionID != nil {
+			ev.Parent = versionByID[*v.ParentVersionID]
+		}
+		export.Versions = append(export.Versions, ev)
+	}
+
+	for _, t := range tags {
+		export.Tags = append(export.Tags, ExportedTag{Name: t.Name, Version: versionByID[t.VersionID]})
+	}
+
+	return export, nil
+}
+
+// rawJSON returns a stored JSON column for embedding, or nil if it is empty
+// or not valid JSON
+func rawJSON(s string) json.RawMessage {
+	if s == "" || !json.Valid([]byte(s)) {
+		return nil
+	}
+	return json.RawMessage(s)
+}
+
+// ImportPrompt recreates an exported prompt in the project, with the same
+// version strings, parent links and tags under new IDs. Versions keep their
+// commit messages, authors and timestamps. It fails without changing
+// anything if the export is inconsistent or a prompt with the same name or
+// file path already exists.
+func (db *DB) ImportPrompt(projectID string, export *PromptExport) (*Prompt, error) {
+	if err := validateExport(export); err != nil {
+		return nil, err
+	}
+
+	existing, err := db.GetPromptByName(export.Prompt.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("a prompt named %s already exists", export.Prompt.Name)
+	}
+	existing, err = db.GetPromptByPath(export.Prompt.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%s is already tracked as prompt %s", export.Prompt.FilePath, existing.Name)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	p := &Prompt{
+		ID:          NewUUID(),
+		ProjectID:   projectID,
+		Name:        export.Prompt.Name,
+		Description: export.Prompt.Description,
+		FilePath:    export.Prompt.FilePath,
+		CreatedAt:   export.Prompt.CreatedAt,
+	}
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = time.Now()
+	}
+	if _, err := tx.Exec(
+		"INSERT INTO prompts (id, project_id, name, description, file_path, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		p.ID, p.ProjectID, p.Name, p.Description, p.FilePath, p.CreatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("failed to create prompt: %w", err)
+	}
+
+	// Versions are oldest first, so each parent is inserted before its children
+	idByVersion := make(map[string]string, len(export.Versions))
+	for _, v := range export.Versions {
+		id := NewUUID()
+		var parentID *string
+		if v.Parent != "" {
+			parent := idByVersion[v.Parent]
+			parentID = &parent
+		}
+		variables, metadata := string(v.Variables), string(v.Metadata)
+		if variables == "" {
+			variables = "[]"
+		}
+		if metadata == "" {
+			metadata = "{}"
+		}
+		if _, err := tx.Exec(insertVersionSQL,
+			id, p.ID, v.Version, v.Content, variables, metadata, parentID, v.CommitMessage, v.CreatedAt, v.CreatedBy,
+		); err != nil {
+			return nil, fmt.Errorf("failed to create version %s: %w", v.Version, err)
+		}
+		idByVersion[v.Version] = id
+	}
+
+	for _, t := range export.Tags {
+		if _, err := tx.Exec(
+			"INSERT INTO tags (id, prompt_id, version_id, name, created_at) VALUES (?, ?, ?, ?, ?)",
+			NewUUID(), p.ID, idByVersion[t.Version], t.Name, time.Now(),
+		); err != nil {
+			return nil, fmt.Errorf("failed to create tag %s: %w", t.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to import prompt: %w", err)
+	}
+	return p, nil
+}
+
+// validateExport checks that an export can be imported: a format this build
+// understands, a named prompt, and versions and tags that only refer to
+// versions listed before them
+func validateExport(export *PromptExport) error {
+	if export.FormatVersion < 1 || export.FormatVersion > ExportFormatVersion {
+		return fmt.Errorf("unsupported export format version %d (this build reads up to %d)", export.FormatVersion, ExportFormatVersion)
+	}
+	if export.Prompt.Name == "" {
+		return fmt.Errorf("export has no prompt name")
+	}
+	if export.Prompt.FilePath == "" {
+		return fmt.Errorf("export has no prompt file path")
+	}
+
+	seen := make(map[string]bool, len(export.Versions))
+	for _, v := range export.Versions {
+		if v.Version == "" {
+			return fmt.Errorf("export has a version without a version string")
+		}
+		if seen[v.Version] {
+			return fmt.Errorf("export lists version %s twice", v.Version)
+		}
+		if v.Parent != "" && !seen[v.Parent] {
+			return fmt.Errorf("version %s has parent %s, which is not listed before it", v.Version, v.Parent)
+		}
+		seen[v.Version] = true
+	}
+	for _, t := range export.Tags {
+		if !seen[t.Version] {
+			return fmt.Errorf("tag %s points at version %s, which is not in the export", t.Name, t.Version)
+		}
+	}
+	return nil
+}
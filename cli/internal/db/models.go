@@ -18,11 +18,14 @@ type Prompt struct {
 	Description string
 	FilePath    string
 	CreatedAt   time.Time
+	Frozen      bool // No new versions can be created while set
 }
 
 type PromptWithLatestVersion struct {
 	Prompt
 	LatestVersion string
+	LatestContent string // Only set when requested
+	LatestTokens  int    // Estimated tokens in the latest version
 }
 
 type PromptVersion struct {
@@ -38,6 +41,36 @@ type PromptVersion struct {
 	CreatedBy       string
 }
 
+// StagedPrompt is a prompt's content as it was staged for the next commit
+type StagedPrompt struct {
+	PromptID string
+	Content  string
+	StagedAt time.Time
+}
+
+// PromptAlias is another name a prompt can be looked up by, such as its
+// name before a rename
+type PromptAlias struct {
+	Alias      string
+	PromptID   string
+	PromptName string
+	CreatedAt  time.Time
+}
+
+// PullConflict is a version whose local content differed from the remote
+// copy on pull. ResolvedVersionID is the version that settled it: the new
+// version holding the remote content, or the next commit of the prompt
+// after a manual review. Without one the conflict still needs review.
+type PullConflict struct {
+	PromptID          string
+	PromptName        string
+	Version           string
+	RemoteContent     string
+	Strategy          string
+	ResolvedVersionID *string
+	CreatedAt         time.Time
+}
+
 type Tag struct {
 	ID        string
 	PromptID  string
@@ -94,6 +127,9 @@ type ChainStep struct {
 	PromptName   string
 	InputMapping string // JSON
 	OutputKey    string
+	Version      string // Version or tag the step is pinned to; empty runs the latest
+	DependsOn    string // JSON array of output keys the step waits for; empty if none
+	Condition    string // Expression the step runs only if true; empty to always run
 }
 
 type ChainRun struct {
@@ -103,6 +139,15 @@ type ChainRun struct {
 	Inputs      string // JSON
 	Results     string // JSON
 	FinalOutput string
+	TotalTokens int     // Prompt and output tokens over all steps
+	TotalCost   float64 // Dollar cost over all steps
 	StartedAt   time.Time
 	CompletedAt time.Time
 }
+
+// ChainRunFilter narrows ListChainRuns. Zero values match every run.
+type ChainRunFilter struct {
+	Status string // e.g. "completed" or "failed"; empty for any status
+	Limit  int    // maximum runs to return; zero for no limit
+	Offset int    // runs to skip, newest first
+}
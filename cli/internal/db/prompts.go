@@ -4,6 +4,9 @@ import (
 	"database/sql"
 	"fmt"
 	"time"
+
+	"github.com/promptsmith/cli/internal/logging"
+	"github.com/promptsmith/cli/internal/prompt"
 )
 
 // Prompt, version, and tag persistence.
@@ -32,9 +35,9 @@ func (db *DB) CreatePrompt(projectID, name, description, filePath string) (*Prom
 func (db *DB) GetPromptByPath(filePath string) (*Prompt, error) {
 	var prompt Prompt
 	err := db.QueryRow(
-		"SELECT id, project_id, name, description, file_path, created_at FROM prompts WHERE file_path = ?",
+		"SELECT id, project_id, name, description, file_path, created_at, frozen FROM prompts WHERE file_path = ?",
 		filePath,
-	).Scan(&prompt.ID, &prompt.ProjectID, &prompt.Name, &prompt.Description, &prompt.FilePath, &prompt.CreatedAt)
+	).Scan(&prompt.ID, &prompt.ProjectID, &prompt.Name, &prompt.Description, &prompt.FilePath, &prompt.CreatedAt, &prompt.Frozen)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -44,12 +47,14 @@ func (db *DB) GetPromptByPath(filePath string) (*Prompt, error) {
 	return &prompt, nil
 }
 
+// GetPromptByName finds a prompt by its exact name. Aliases are not
+// consulted; see ResolvePromptName.
 func (db *DB) GetPromptByName(name string) (*Prompt, error) {
 	var prompt Prompt
 	err := db.QueryRow(
-		"SELECT id, project_id, name, description, file_path, created_at FROM prompts WHERE name = ?",
+		"SELECT id, project_id, name, description, file_path, created_at, frozen FROM prompts WHERE name = ?",
 		name,
-	).Scan(&prompt.ID, &prompt.ProjectID, &prompt.Name, &prompt.Description, &prompt.FilePath, &prompt.CreatedAt)
+	).Scan(&prompt.ID, &prompt.ProjectID, &prompt.Name, &prompt.Description, &prompt.FilePath, &prompt.CreatedAt, &prompt.Frozen)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -59,8 +64,23 @@ func (db *DB) GetPromptByName(name string) (*Prompt, error) {
 	return &prompt, nil
 }
 
+// ResolvePromptName finds a prompt by its name or, failing that, by an alias
+// left by a rename, reporting whether name was an alias. It is for lookups
+// that read a prompt; checks for whether a name is taken use GetPromptByName.
+func (db *DB) ResolvePromptName(name string) (*Prompt, bool, error) {
+	p, err := db.GetPromptByName(name)
+	if err != nil || p != nil {
+		return p, false, err
+	}
+	p, err = db.GetPromptByAlias(name)
+	if err != nil || p == nil {
+		return p, false, err
+	}
+	return p, true, nil
+}
+
 func (db *DB) ListPrompts() ([]*Prompt, error) {
-	rows, err := db.Query("SELECT id, project_id, name, description, file_path, created_at FROM prompts ORDER BY name")
+	rows, err := db.Query("SELECT id, project_id, name, description, file_path, created_at, frozen FROM prompts ORDER BY name")
 	if err != nil {
 		return nil, err
 	}
@@ -69,7 +89,7 @@ func (db *DB) ListPrompts() ([]*Prompt, error) {
 	var prompts []*Prompt
 	for rows.Next() {
 		var p Prompt
-		if err := rows.Scan(&p.ID, &p.ProjectID, &p.Name, &p.Description, &p.FilePath, &p.CreatedAt); err != nil {
+		if err := rows.Scan(&p.ID, &p.ProjectID, &p.Name, &p.Description, &p.FilePath, &p.CreatedAt, &p.Frozen); err != nil {
 			return nil, err
 		}
 		prompts = append(prompts, &p)
@@ -77,18 +97,27 @@ func (db *DB) ListPrompts() ([]*Prompt, error) {
 	return prompts, nil
 }
 
-func (db *DB) ListPromptsWithLatestVersion() ([]*PromptWithLatestVersion, error) {
+// ListPromptsWithLatestVersion lists every prompt alongside its latest
+// version in a single query. With includeContent the latest version's content
+// is loaded too, which list views can use instead of fetching each prompt.
+func (db *DB) ListPromptsWithLatestVersion(includeContent bool) ([]*PromptWithLatestVersion, error) {
+	contentCol := "NULL"
+	if includeContent {
+		contentCol = "lv.content"
+	}
+
 	rows, err := db.Query(`
 		SELECT
-			p.id, p.project_id, p.name, p.description, p.file_path, p.created_at,
-			(
-				SELECT pv.version
-				FROM prompt_versions pv
-				WHERE pv.prompt_id = p.id
-				ORDER BY pv.created_at DESC
-				LIMIT 1
-			) AS latest_version
+			p.id, p.project_id, p.name, p.description, p.file_path, p.created_at, p.frozen,
+			lv.version, ` + contentCol + `, COALESCE(length(CAST(lv.content AS BLOB)), 0)
 		FROM prompts p
+		LEFT JOIN prompt_versions lv ON lv.id = (
+			SELECT pv.id
+			FROM prompt_versions pv
+			WHERE pv.prompt_id = p.id
+			ORDER BY pv.created_at DESC, ` + semverDesc("pv.version") + `
+			LIMIT 1
+		)
 		ORDER BY p.name
 	`)
 	if err != nil {
@@ -99,16 +128,17 @@ func (db *DB) ListPromptsWithLatestVersion() ([]*PromptWithLatestVersion, error)
 	var prompts []*PromptWithLatestVersion
 	for rows.Next() {
 		var p PromptWithLatestVersion
-		var latestVersion sql.NullString
-		if err := rows.Scan(&p.ID, &p.ProjectID, &p.Name, &p.Description, &p.FilePath, &p.CreatedAt, &latestVersion); err != nil {
+		var latestVersion, latestContent sql.NullString
+		var latestSize int
+		if err := rows.Scan(&p.ID, &p.ProjectID, &p.Name, &p.Description, &p.FilePath, &p.CreatedAt, &p.Frozen, &latestVersion, &latestContent, &latestSize); err != nil {
 			return nil, err
 		}
-		if latestVersion.Valid {
-			p.LatestVersion = latestVersion.String
-		}
+		p.LatestVersion = latestVersion.String
+		p.LatestContent = latestContent.String
+		p.LatestTokens = prompt.TokensForSize(latestSize)
 		prompts = append(prompts, &p)
 	}
-	return prompts, nil
+	return prompts, rows.Err()
 }
 
 func (db *DB) CreateVersion(promptID, version, content, variables, metadata, commitMessage, createdBy string, parentVersionID *string) (*PromptVersion, error) {
@@ -125,10 +155,7 @@ func (db *DB) CreateVersion(promptID, version, content, variables, metadata, com
 		CreatedBy:       createdBy,
 	}
 
-	_, err := db.Exec(
-		`INSERT INTO prompt_versions
-		(id, prompt_id, version, content, variables, metadata, parent_version_id, commit_message, created_at, created_by)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	_, err := db.Exec(insertVersionSQL,
 		v.ID, v.PromptID, v.Version, v.Content, v.Variables, v.Metadata, v.ParentVersionID, v.CommitMessage, v.CreatedAt, v.CreatedBy,
 	)
 	if err != nil {
@@ -138,12 +165,78 @@ func (db *DB) CreateVersion(promptID, version, content, variables, metadata, com
 	return v, nil
 }
 
+const insertVersionSQL = `INSERT INTO prompt_versions
+	(id, prompt_id, version, content, variables, metadata, parent_version_id, commit_message, created_at, created_by)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+// CreateVersions records several versions in one transaction, so either all
+// of them are stored or, if any insert fails, none are. ID and CreatedAt are
+// filled in on each version. Any staged content for the prompts is cleared,
+// since the new versions supersede it.
+func (db *DB) CreateVersions(versions []*PromptVersion) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, v := range versions {
+		v.ID = NewUUID()
+		v.CreatedAt = now
+		if _, err := tx.Exec(insertVersionSQL,
+			v.ID, v.PromptID, v.Version, v.Content, v.Variables, v.Metadata, v.ParentVersionID, v.CommitMessage, v.CreatedAt, v.CreatedBy,
+		); err != nil {
+			return fmt.Errorf("failed to create version %s: %w", v.Version, err)
+		}
+		if _, err := tx.Exec("DELETE FROM staging WHERE prompt_id = ?", v.PromptID); err != nil {
+			return fmt.Errorf("failed to clear staged content: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, v := range versions {
+		logging.Debug("created version", "prompt_id", v.PromptID, "version", v.Version)
+	}
+	return nil
+}
+
+// UpdateVersionContent replaces the content of an existing version. It is
+// used when a pull resolves a diverged version in favour of the remote copy.
+func (db *DB) UpdateVersionContent(versionID, content, variables, metadata string) error {
+	result, err := db.Exec(
+		"UPDATE prompt_versions SET content = ?, variables = ?, metadata = ? WHERE id = ?",
+		content, variables, metadata, versionID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update version: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("version not found")
+	}
+	return nil
+}
+
+// semverDesc returns an ORDER BY fragment sorting a MAJOR.MINOR.PATCH column
+// numerically, highest first. It breaks ties between versions created within
+// the same timestamp resolution.
+func semverDesc(col string) string {
+	rest := fmt.Sprintf("substr(%s, instr(%s, '.') + 1)", col, col)
+	return fmt.Sprintf(
+		"CAST(%s AS INTEGER) DESC, CAST(%s AS INTEGER) DESC, CAST(substr(%s, instr(%s, '.') + 1) AS INTEGER) DESC",
+		col, rest, rest, rest,
+	)
+}
+
 func (db *DB) GetLatestVersion(promptID string) (*PromptVersion, error) {
 	var v PromptVersion
 	var parentID sql.NullString
 	err := db.QueryRow(
 		`SELECT id, prompt_id, version, content, variables, metadata, parent_version_id, commit_message, created_at, created_by
-		FROM prompt_versions WHERE prompt_id = ? ORDER BY created_at DESC LIMIT 1`,
+		FROM prompt_versions WHERE prompt_id = ? ORDER BY created_at DESC, `+semverDesc("version")+` LIMIT 1`,
 		promptID,
 	).Scan(&v.ID, &v.PromptID, &v.Version, &v.Content, &v.Variables, &v.Metadata, &parentID, &v.CommitMessage, &v.CreatedAt, &v.CreatedBy)
 	if err == sql.ErrNoRows {
@@ -159,11 +252,43 @@ func (db *DB) GetLatestVersion(promptID string) (*PromptVersion, error) {
 }
 
 func (db *DB) ListVersions(promptID string) ([]*PromptVersion, error) {
-	rows, err := db.Query(
-		`SELECT id, prompt_id, version, content, variables, metadata, parent_version_id, commit_message, created_at, created_by
-		FROM prompt_versions WHERE prompt_id = ? ORDER BY created_at DESC`,
-		promptID,
-	)
+	return db.ListVersionsByAuthor(promptID, "")
+}
+
+// ListVersionsByAuthor lists a prompt's versions created by author, newest
+// first. An empty author matches every version.
+func (db *DB) ListVersionsByAuthor(promptID, author string) ([]*PromptVersion, error) {
+	query := `SELECT id, prompt_id, version, content, variables, metadata, parent_version_id, commit_message, created_at, created_by
+		FROM prompt_versions WHERE prompt_id = ?`
+	args := []any{promptID}
+	if author != "" {
+		query += " AND created_by = ?"
+		args = append(args, author)
+	}
+	query += " ORDER BY created_at DESC, " + semverDesc("version")
+	return db.queryVersions(query, args...)
+}
+
+// ListVersionsBetween lists a prompt's versions created from the earlier to
+// the later of two of its versions, both included, newest first. The
+// endpoints may be given in either order.
+func (db *DB) ListVersionsBetween(promptID, fromVersionID, toVersionID string) ([]*PromptVersion, error) {
+	query := `SELECT id, prompt_id, version, content, variables, metadata, parent_version_id, commit_message, created_at, created_by
+		FROM prompt_versions
+		WHERE prompt_id = ?
+			AND created_at >= (SELECT MIN(created_at) FROM prompt_versions WHERE id IN (?, ?))
+			AND created_at <= (SELECT MAX(created_at) FROM prompt_versions WHERE id IN (?, ?))
+		ORDER BY created_at DESC, ` + semverDesc("version")
+	versions, err := db.queryVersions(query, promptID, fromVersionID, toVersionID, fromVersionID, toVersionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	return versions, nil
+}
+
+// queryVersions runs a query selecting prompt_versions columns in table order
+func (db *DB) queryVersions(query string, args ...any) ([]*PromptVersion, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -224,6 +349,35 @@ func (db *DB) GetVersionByID(id string) (*PromptVersion, error) {
 	return &v, nil
 }
 
+// GetVersionAncestry returns the version with the given ID followed by its
+// ancestors, walking parent_version_id back to the root. A parent that was
+// already visited ends the walk, so malformed cyclic histories terminate.
+func (db *DB) GetVersionAncestry(versionID string) ([]*PromptVersion, error) {
+	var ancestry []*PromptVersion
+	visited := make(map[string]bool)
+
+	id := versionID
+	for id != "" && !visited[id] {
+		visited[id] = true
+
+		v, err := db.GetVersionByID(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get version: %w", err)
+		}
+		if v == nil {
+			break
+		}
+		ancestry = append(ancestry, v)
+
+		id = ""
+		if v.ParentVersionID != nil {
+			id = *v.ParentVersionID
+		}
+	}
+
+	return ancestry, nil
+}
+
 func (db *DB) CreateTag(promptID, versionID, name string) (*Tag, error) {
 	version, err := db.GetVersionByID(versionID)
 	if err != nil {
@@ -326,15 +480,38 @@ func (db *DB) UpdatePrompt(promptID, name, description string) (*Prompt, error)
 
 	var p Prompt
 	err = db.QueryRow(
-		"SELECT id, project_id, name, description, file_path, created_at FROM prompts WHERE id = ?",
+		"SELECT id, project_id, name, description, file_path, created_at, frozen FROM prompts WHERE id = ?",
 		promptID,
-	).Scan(&p.ID, &p.ProjectID, &p.Name, &p.Description, &p.FilePath, &p.CreatedAt)
+	).Scan(&p.ID, &p.ProjectID, &p.Name, &p.Description, &p.FilePath, &p.CreatedAt, &p.Frozen)
 	if err != nil {
 		return nil, err
 	}
 	return &p, nil
 }
 
+// UpdatePromptFilePath points a prompt at a moved file
+func (db *DB) UpdatePromptFilePath(promptID, filePath string) error {
+	_, err := db.Exec("UPDATE prompts SET file_path = ? WHERE id = ?", filePath, promptID)
+	if err != nil {
+		return fmt.Errorf("failed to update prompt file path: %w", err)
+	}
+	return nil
+}
+
+// SetPromptFrozen freezes or unfreezes a prompt. No new versions can be
+// created for a frozen prompt.
+func (db *DB) SetPromptFrozen(promptID string, frozen bool) error {
+	result, err := db.Exec("UPDATE prompts SET frozen = ? WHERE id = ?", frozen, promptID)
+	if err != nil {
+		return fmt.Errorf("failed to update prompt: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("prompt not found")
+	}
+	return nil
+}
+
 func (db *DB) DeletePrompt(promptID string) error {
 	var promptName string
 	var projectID string
@@ -358,6 +535,9 @@ func (db *DB) DeletePrompt(promptID string) error {
 	if _, err := tx.Exec("DELETE FROM tags WHERE prompt_id = ?", promptID); err != nil {
 		return fmt.Errorf("failed to delete tags: %w", err)
 	}
+	if _, err := tx.Exec("DELETE FROM prompt_aliases WHERE prompt_id = ?", promptID); err != nil {
+		return fmt.Errorf("failed to delete aliases: %w", err)
+	}
 	if _, err := tx.Exec("DELETE FROM test_runs WHERE suite_id IN (SELECT id FROM test_suites WHERE prompt_id = ?)", promptID); err != nil {
 		return fmt.Errorf("failed to delete test runs: %w", err)
 	}
@@ -53,7 +53,7 @@ func (db *DB) GetAllVersionsForLog() ([]struct {
 	Version *PromptVersion
 }, error) {
 	rows, err := db.Query(`
-		SELECT p.id, p.project_id, p.name, p.description, p.file_path, p.created_at,
+		SELECT p.id, p.project_id, p.name, p.description, p.file_path, p.created_at, p.frozen,
 			   v.id, v.prompt_id, v.version, v.content, v.variables, v.metadata, v.parent_version_id, v.commit_message, v.created_at, v.created_by
 		FROM prompt_versions v
 		JOIN prompts p ON v.prompt_id = p.id
@@ -73,7 +73,7 @@ func (db *DB) GetAllVersionsForLog() ([]struct {
 		var v PromptVersion
 		var parentID sql.NullString
 		if err := rows.Scan(
-			&p.ID, &p.ProjectID, &p.Name, &p.Description, &p.FilePath, &p.CreatedAt,
+			&p.ID, &p.ProjectID, &p.Name, &p.Description, &p.FilePath, &p.CreatedAt, &p.Frozen,
 			&v.ID, &v.PromptID, &v.Version, &v.Content, &v.Variables, &v.Metadata, &parentID, &v.CommitMessage, &v.CreatedAt, &v.CreatedBy,
 		); err != nil {
 			return nil, err
@@ -203,3 +203,45 @@ func (db *DB) ListBenchmarkRuns(benchmarkID string) ([]*BenchmarkRun, error) {
 	}
 	return runs, nil
 }
+
+func (db *DB) GetBenchmarkRun(runID string) (*BenchmarkRun, error) {
+	var r BenchmarkRun
+	var versionID sql.NullString
+	err := db.QueryRow(
+		`SELECT id, benchmark_id, version_id, results, created_at
+		FROM benchmark_runs WHERE id = ?`,
+		runID,
+	).Scan(&r.ID, &r.BenchmarkID, &versionID, &r.Results, &r.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get benchmark run: %w", err)
+	}
+	r.VersionID = stringFromNull(versionID)
+	return &r, nil
+}
+
+// GetLatestBenchmarkRunForPrompt returns the most recent run of any benchmark
+// suite targeting the prompt, or nil if it has never been benchmarked.
+func (db *DB) GetLatestBenchmarkRunForPrompt(promptID string) (*BenchmarkRun, error) {
+	var r BenchmarkRun
+	var versionID sql.NullString
+	err := db.QueryRow(
+		`SELECT r.id, r.benchmark_id, r.version_id, r.results, r.created_at
+		FROM benchmark_runs r
+		JOIN benchmarks b ON b.id = r.benchmark_id
+		WHERE b.prompt_id = ?
+		ORDER BY r.created_at DESC
+		LIMIT 1`,
+		promptID,
+	).Scan(&r.ID, &r.BenchmarkID, &versionID, &r.Results, &r.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest benchmark run: %w", err)
+	}
+	r.VersionID = stringFromNull(versionID)
+	return &r, nil
+}
@@ -0,0 +1,248 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Search methods
+
+// Snippets mark each match with these delimiters, whichever search backend
+// produced them
+const (
+	SnippetMatchStart = "<mark>"
+	SnippetMatchEnd   = "</mark>"
+)
+
+// searchLimit caps the number of matches a search returns
+const searchLimit = 50
+
+// snippetContext is how many bytes of content the LIKE fallback keeps on
+// either side of a match
+const snippetContext = 40
+
+type SearchResult struct {
+	PromptName string `json:"prompt_name"`
+	Version    string `json:"version"`
+	VersionID  string `json:"version_id"`
+	Snippet    string `json:"snippet"`
+}
+
+// SearchVersions finds prompt versions whose content contains query as a
+// phrase, best matches first. Each result carries a snippet of the content
+// around the match.
+func (db *DB) SearchVersions(query string) ([]SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("search query is empty")
+	}
+
+	indexed, err := db.hasSearchIndex()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search versions: %w", err)
+	}
+
+	var results []SearchResult
+	if indexed {
+		results, err = db.searchIndex(query)
+	} else {
+		results, err = db.searchLike(query)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to search versions: %w", err)
+	}
+	return results, nil
+}
+
+// SnippetResult is a search match with the number of times the phrase
+// occurs in the version
+type SnippetResult struct {
+	Prompt     string `json:"prompt"`
+	Version    string `json:"version"`
+	Snippet    string `json:"snippet"`
+	MatchCount int    `json:"match_count"`
+}
+
+// SearchVersionsWithSnippets runs SearchVersions and ranks its matches by how
+// often the phrase occurs, most first. Ties keep the SearchVersions order.
+func (db *DB) SearchVersionsWithSnippets(query string) ([]SnippetResult, error) {
+	matches, err := db.SearchVersions(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(matches))
+	for i, m := range matches {
+		ids[i] = m.VersionID
+	}
+	contents, err := db.versionContents(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search versions: %w", err)
+	}
+
+	results := make([]SnippetResult, len(matches))
+	for i, m := range matches {
+		results[i] = SnippetResult{
+			Prompt:  m.PromptName,
+			Version: m.Version,
+			Snippet: m.Snippet,
+			// FTS5 matches tokens, so it can find the phrase across
+			// punctuation a plain count would miss
+			MatchCount: max(countMatches(contents[m.VersionID], query), 1),
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].MatchCount > results[j].MatchCount
+	})
+	return results, nil
+}
+
+// versionContents loads the content of the given versions, keyed by ID
+func (db *DB) versionContents(ids []string) (map[string]string, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	rows, err := db.Query("SELECT id, content FROM prompt_versions WHERE id IN ("+placeholders+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contents := make(map[string]string, len(ids))
+	for rows.Next() {
+		var id, content string
+		if err := rows.Scan(&id, &content); err != nil {
+			return nil, err
+		}
+		contents[id] = content
+	}
+	return contents, rows.Err()
+}
+
+func (db *DB) hasSearchIndex() (bool, error) {
+	var count int
+	err := db.QueryRow(
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'prompt_versions_fts'",
+	).Scan(&count)
+	return count > 0, err
+}
+
+func (db *DB) searchIndex(query string) ([]SearchResult, error) {
+	// Quote the query as a single phrase so FTS5 operators and punctuation
+	// in it are matched literally
+	phrase := `"` + strings.ReplaceAll(query, `"`, `""`) + `"`
+
+	rows, err := db.Query(`
+		SELECT p.name, v.version, v.id, snippet(prompt_versions_fts, 1, ?, ?, '…', 12)
+		FROM prompt_versions_fts
+		JOIN prompt_versions v ON v.id = prompt_versions_fts.version_id
+		JOIN prompts p ON p.id = v.prompt_id
+		WHERE prompt_versions_fts MATCH ?
+		ORDER BY rank
+		LIMIT ?
+	`, SnippetMatchStart, SnippetMatchEnd, phrase, searchLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		if err := rows.Scan(&r.PromptName, &r.Version, &r.VersionID, &r.Snippet); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+func (db *DB) searchLike(query string) ([]SearchResult, error) {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(query)
+
+	rows, err := db.Query(`
+		SELECT p.name, v.version, v.id, v.content
+		FROM prompt_versions v
+		JOIN prompts p ON p.id = v.prompt_id
+		WHERE v.content LIKE ? ESCAPE '\'
+		ORDER BY p.name, v.created_at DESC
+		LIMIT ?
+	`, "%"+escaped+"%", searchLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		var content string
+		if err := rows.Scan(&r.PromptName, &r.Version, &r.VersionID, &content); err != nil {
+			return nil, err
+		}
+		r.Snippet = likeSnippet(content, query)
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// likeSnippet cuts the content around the first case-insensitive match of
+// query and marks the match, as FTS5's snippet() would
+func likeSnippet(content, query string) string {
+	// LIKE only folds ASCII case, and folding just ASCII keeps byte offsets
+	// in the folded copy valid in content
+	start := strings.Index(asciiLower(content), asciiLower(query))
+	if start < 0 {
+		return ""
+	}
+	end := start + len(query)
+
+	from := max(start-snippetContext, 0)
+	to := min(end+snippetContext, len(content))
+	// Keep the cut on rune boundaries
+	for from > 0 && !utf8.RuneStart(content[from]) {
+		from--
+	}
+	for to < len(content) && !utf8.RuneStart(content[to]) {
+		to++
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	b.WriteString(content[from:start])
+	b.WriteString(SnippetMatchStart)
+	b.WriteString(content[start:end])
+	b.WriteString(SnippetMatchEnd)
+	b.WriteString(content[end:to])
+	if to < len(content) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// countMatches counts the non-overlapping occurrences of query in content,
+// folding case as LIKE does
+func countMatches(content, query string) int {
+	query = strings.TrimSpace(query)
+	return strings.Count(asciiLower(content), asciiLower(query))
+}
+
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Staging methods
+
+// StagePrompt records content as the prompt's staged snapshot, replacing any
+// earlier one
+func (db *DB) StagePrompt(promptID, content string) error {
+	_, err := db.Exec(
+		`INSERT INTO staging (prompt_id, content, staged_at) VALUES (?, ?, ?)
+		ON CONFLICT(prompt_id) DO UPDATE SET content = excluded.content, staged_at = excluded.staged_at`,
+		promptID, content, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to stage prompt: %w", err)
+	}
+	return nil
+}
+
+// GetStagedPrompt returns the prompt's staged snapshot, or nil if it has none
+func (db *DB) GetStagedPrompt(promptID string) (*StagedPrompt, error) {
+	var s StagedPrompt
+	err := db.QueryRow(
+		"SELECT prompt_id, content, staged_at FROM staging WHERE prompt_id = ?",
+		promptID,
+	).Scan(&s.PromptID, &s.Content, &s.StagedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staged prompt: %w", err)
+	}
+	return &s, nil
+}
+
+// ListStagedPrompts returns every staged snapshot, oldest first
+func (db *DB) ListStagedPrompts() ([]*StagedPrompt, error) {
+	rows, err := db.Query("SELECT prompt_id, content, staged_at FROM staging ORDER BY staged_at, prompt_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staged prompts: %w", err)
+	}
+	defer rows.Close()
+
+	var staged []*StagedPrompt
+	for rows.Next() {
+		var s StagedPrompt
+		if err := rows.Scan(&s.PromptID, &s.Content, &s.StagedAt); err != nil {
+			return nil, err
+		}
+		staged = append(staged, &s)
+	}
+	return staged, rows.Err()
+}
+
+// UnstagePrompt discards the prompt's staged snapshot, if any
+func (db *DB) UnstagePrompt(promptID string) error {
+	if _, err := db.Exec("DELETE FROM staging WHERE prompt_id = ?", promptID); err != nil {
+		return fmt.Errorf("failed to unstage prompt: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+// Package diff compares texts line by line, as shown by 'promptsmith diff'
+// and in snapshot test failures.
+package diff
+
+// Hunk is a run of changed lines with the unchanged lines around them.
+// Lines are prefixed with ' ', '-' or '+'. Start repeats OldStart under the
+// name editors expect.
+type Hunk struct {
+	OldStart int      `json:"old_start"`
+	OldCount int      `json:"old_count"`
+	NewStart int      `json:"new_start"`
+	NewCount int      `json:"new_count"`
+	Start    int      `json:"start"`
+	Lines    []string `json:"lines"`
+}
+
+// Compute diffs lines1 against lines2 using their longest common
+// subsequence and groups the changes into hunks with up to context
+// unchanged lines on each side
+func Compute(lines1, lines2 []string, context int) []Hunk {
+	m, n := len(lines1), len(lines2)
+
+	// Build LCS table
+	lcs := make([][]int, m+1)
+	for i := range lcs {
+		lcs[i] = make([]int, n+1)
+	}
+	for i := 1; i <= m; i++ {
+		for j := 1; j <= n; j++ {
+			if lines1[i-1] == lines2[j-1] {
+				lcs[i][j] = lcs[i-1][j-1] + 1
+			} else {
+				if lcs[i-1][j] > lcs[i][j-1] {
+					lcs[i][j] = lcs[i-1][j]
+				} else {
+					lcs[i][j] = lcs[i][j-1]
+				}
+			}
+		}
+	}
+
+	// Backtrack to find diff
+	var diffLines []struct {
+		op   rune
+		line string
+		old  int
+		new  int
+	}
+
+	i, j := m, n
+	for i > 0 || j > 0 {
+		if i > 0 && j > 0 && lines1[i-1] == lines2[j-1] {
+			diffLines = append([]struct {
+				op   rune
+				line string
+				old  int
+				new  int
+			}{{' ', lines1[i-1], i, j}}, diffLines...)
+			i--
+			j--
+		} else if j > 0 && (i == 0 || lcs[i][j-1] >= lcs[i-1][j]) {
+			diffLines = append([]struct {
+				op   rune
+				line string
+				old  int
+				new  int
+			}{{'+', lines2[j-1], 0, j}}, diffLines...)
+			j--
+		} else if i > 0 {
+			diffLines = append([]struct {
+				op   rune
+				line string
+				old  int
+				new  int
+			}{{'-', lines1[i-1], i, 0}}, diffLines...)
+			i--
+		}
+	}
+
+	// Count the old and new lines preceding each diff line, so a hunk that
+	// opens with an insertion or deletion still gets correct start lines
+	oldBefore := make([]int, len(diffLines)+1)
+	newBefore := make([]int, len(diffLines)+1)
+	for idx, dl := range diffLines {
+		oldBefore[idx+1] = oldBefore[idx]
+		newBefore[idx+1] = newBefore[idx]
+		if dl.op != '+' {
+			oldBefore[idx+1]++
+		}
+		if dl.op != '-' {
+			newBefore[idx+1]++
+		}
+	}
+
+	// Group into hunks with context
+	var hunks []Hunk
+	var currentHunk *Hunk
+
+	for idx, dl := range diffLines {
+		if dl.op != ' ' {
+			// Start or extend hunk
+			if currentHunk == nil {
+				// Add preceding context
+				start := max(0, idx-context)
+				currentHunk = &Hunk{
+					OldStart: oldBefore[start] + 1,
+					NewStart: newBefore[start] + 1,
+					Start:    oldBefore[start] + 1,
+				}
+				for k := start; k < idx; k++ {
+					currentHunk.Lines = append(currentHunk.Lines, " "+diffLines[k].line)
+					currentHunk.OldCount++
+					currentHunk.NewCount++
+				}
+			}
+
+			switch dl.op {
+			case '+':
+				currentHunk.Lines = append(currentHunk.Lines, "+"+dl.line)
+				currentHunk.NewCount++
+			case '-':
+				currentHunk.Lines = append(currentHunk.Lines, "-"+dl.line)
+				currentHunk.OldCount++
+			}
+		} else if currentHunk != nil {
+			// Context line after change
+			currentHunk.Lines = append(currentHunk.Lines, " "+dl.line)
+			currentHunk.OldCount++
+			currentHunk.NewCount++
+
+			// Check if we should close hunk
+			nextChange := -1
+			for k := idx + 1; k < len(diffLines) && k <= idx+context*2; k++ {
+				if diffLines[k].op != ' ' {
+					nextChange = k
+					break
+				}
+			}
+			if nextChange == -1 {
+				// Add trailing context up to context
+				added := 1 // We already added current
+				for k := idx + 1; k < len(diffLines) && added < context; k++ {
+					if diffLines[k].op == ' ' {
+						currentHunk.Lines = append(currentHunk.Lines, " "+diffLines[k].line)
+						currentHunk.OldCount++
+						currentHunk.NewCount++
+						added++
+					} else {
+						break
+					}
+				}
+				hunks = append(hunks, *currentHunk)
+				currentHunk = nil
+			}
+		}
+	}
+
+	if currentHunk != nil {
+		hunks = append(hunks, *currentHunk)
+	}
+
+	return hunks
+}
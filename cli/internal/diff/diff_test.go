@@ -0,0 +1,98 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines1   []string
+		lines2   []string
+		hasHunks bool
+	}{
+		{
+			name:     "identical",
+			lines1:   []string{"line 1", "line 2", "line 3"},
+			lines2:   []string{"line 1", "line 2", "line 3"},
+			hasHunks: false,
+		},
+		{
+			name:     "added line",
+			lines1:   []string{"line 1", "line 2"},
+			lines2:   []string{"line 1", "line 2", "line 3"},
+			hasHunks: true,
+		},
+		{
+			name:     "removed line",
+			lines1:   []string{"line 1", "line 2", "line 3"},
+			lines2:   []string{"line 1", "line 3"},
+			hasHunks: true,
+		},
+		{
+			name:     "changed line",
+			lines1:   []string{"line 1", "OLD", "line 3"},
+			lines2:   []string{"line 1", "NEW", "line 3"},
+			hasHunks: true,
+		},
+		{
+			name:     "empty to content",
+			lines1:   []string{},
+			lines2:   []string{"new content"},
+			hasHunks: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hunks := Compute(tt.lines1, tt.lines2, 3)
+
+			if tt.hasHunks && len(hunks) == 0 {
+				t.Error("expected hunks but got none")
+			}
+			if !tt.hasHunks && len(hunks) > 0 {
+				t.Errorf("expected no hunks but got %d", len(hunks))
+			}
+		})
+	}
+}
+
+func TestComputeContent(t *testing.T) {
+	lines1 := []string{"line 1", "line 2", "line 3"}
+	lines2 := []string{"line 1", "modified line 2", "line 3"}
+
+	hunks := Compute(lines1, lines2, 3)
+
+	if len(hunks) == 0 {
+		t.Fatal("expected at least one hunk")
+	}
+
+	// Check that the hunk contains the expected changes
+	hunkContent := strings.Join(hunks[0].Lines, "\n")
+
+	if !strings.Contains(hunkContent, "-line 2") {
+		t.Error("expected hunk to contain removed line")
+	}
+	if !strings.Contains(hunkContent, "+modified line 2") {
+		t.Error("expected hunk to contain added line")
+	}
+}
+
+func TestComputeHunkStarts(t *testing.T) {
+	lines1 := []string{"1", "2", "3", "4", "5", "6"}
+	lines2 := []string{"1", "2", "3", "4", "5", "inserted", "6"}
+
+	hunks := Compute(lines1, lines2, 3)
+	if len(hunks) != 1 {
+		t.Fatalf("expected 1 hunk, got %d", len(hunks))
+	}
+
+	h := hunks[0]
+	if h.OldStart != 3 || h.NewStart != 3 {
+		t.Errorf("expected hunk to start at line 3 on both sides, got -%d +%d", h.OldStart, h.NewStart)
+	}
+	if h.OldCount != 4 || h.NewCount != 5 {
+		t.Errorf("expected counts -4 +5, got -%d +%d", h.OldCount, h.NewCount)
+	}
+}
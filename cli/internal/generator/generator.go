@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/promptsmith/cli/internal/benchmark"
+	"github.com/promptsmith/cli/internal/prompt"
 )
 
 // GenerationType defines the type of generation to perform
@@ -172,6 +173,5 @@ func (g *Generator) parseVariations(content string, expectedCount int) []Variati
 
 // EstimateTokens provides a rough token count estimate
 func EstimateTokens(text string) int {
-	// Rough estimate: ~4 characters per token on average
-	return len(text) / 4
+	return prompt.EstimateTokens(text)
 }
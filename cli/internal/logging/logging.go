@@ -0,0 +1,100 @@
+// Package logging is PromptSmith's diagnostic log: structured events about
+// what the CLI does internally, such as opening the database, calling a
+// provider or writing a file. Nothing is logged until Configure sets a level,
+// so the events cost next to nothing in normal use.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// LevelOff turns logging off. It is the default level.
+const LevelOff = "off"
+
+var logger atomic.Pointer[slog.Logger]
+
+func init() {
+	logger.Store(slog.New(slog.DiscardHandler))
+}
+
+// Configure sends events at level or above to w, as key=value text or as
+// JSON lines depending on format. level is one of debug, info, warn, error
+// or off.
+func Configure(w io.Writer, level, format string) error {
+	level = strings.ToLower(level)
+	if level == "" || level == LevelOff {
+		logger.Store(slog.New(slog.DiscardHandler))
+		return nil
+	}
+
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level '%s' (expected debug, info, warn, error or off)", level)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", "text":
+		handler = slog.NewTextHandler(w, opts)
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("invalid log format '%s' (expected text or json)", format)
+	}
+	logger.Store(slog.New(handler))
+	return nil
+}
+
+// Debug logs an event useful when tracking down a problem
+func Debug(msg string, args ...any) {
+	logger.Load().Debug(msg, args...)
+}
+
+// Info logs an event that changes state, such as a schema migration
+func Info(msg string, args ...any) {
+	logger.Load().Info(msg, args...)
+}
+
+// Warn logs a problem the CLI recovered from
+func Warn(msg string, args ...any) {
+	logger.Load().Warn(msg, args...)
+}
+
+// Transport wraps base so every HTTP request made through it is logged with
+// its method, host, path, status and duration. Headers and query strings are
+// left out, as they can carry API keys. A nil base uses
+// http.DefaultTransport.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &loggingTransport{base: base}
+}
+
+type loggingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	args := []any{
+		"method", req.Method,
+		"host", req.URL.Host,
+		"path", req.URL.Path,
+		"duration_ms", time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		Debug("http request failed", append(args, "error", err)...)
+		return nil, err
+	}
+	Debug("http request", append(args, "status", resp.StatusCode)...)
+	return resp, nil
+}
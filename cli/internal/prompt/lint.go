@@ -0,0 +1,89 @@
+package prompt
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Lint rule IDs
+const (
+	RuleFrontmatter = "frontmatter"
+	RuleTemplate    = "template-syntax"
+)
+
+// Issue is a problem found in a prompt file. Line is 1-based and counts
+// from the top of the file, frontmatter included.
+type Issue struct {
+	Line    int    `json:"line"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+var (
+	yamlErrorLine     = regexp.MustCompile(`line (\d+): ([^\n]*)`)
+	templateErrorLine = regexp.MustCompile(`^template: prompt:(\d+): (.*)$`)
+)
+
+// Lint checks a prompt file for frontmatter that does not parse and for a
+// body the test and benchmark runners cannot parse as a Go template.
+func Lint(content string) []Issue {
+	var issues []Issue
+
+	body, bodyOffset := content, 0
+	if strings.HasPrefix(strings.TrimSpace(content), frontmatterDelimiter) {
+		parts := strings.SplitN(content, frontmatterDelimiter, 3)
+		if len(parts) < 3 {
+			issues = append(issues, Issue{
+				Line:    lineAt(content, len(parts[0])),
+				Rule:    RuleFrontmatter,
+				Message: "frontmatter is not closed by ---",
+			})
+		} else {
+			fm := strings.TrimSpace(parts[1])
+			fmOffset := len(parts[0]) + len(frontmatterDelimiter) + strings.Index(parts[1], fm)
+			var parsed Frontmatter
+			if err := yaml.Unmarshal([]byte(fm), &parsed); err != nil {
+				line, msg := 1, err.Error()
+				if m := yamlErrorLine.FindStringSubmatch(msg); m != nil {
+					line, _ = strconv.Atoi(m[1])
+					msg = m[2]
+				}
+				issues = append(issues, Issue{
+					Line:    lineAt(content, fmOffset) + line - 1,
+					Rule:    RuleFrontmatter,
+					Message: msg,
+				})
+			}
+
+			// The body is trimmed the same way Parse trims it, so template
+			// line numbers are offset from its first non-blank line
+			rest := parts[2]
+			body = strings.TrimSpace(rest)
+			bodyOffset = len(content) - len(rest) + strings.Index(rest, body)
+		}
+	}
+
+	if _, err := template.New("prompt").Parse(body); err != nil {
+		line, msg := 1, err.Error()
+		if m := templateErrorLine.FindStringSubmatch(msg); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			msg = m[2]
+		}
+		issues = append(issues, Issue{
+			Line:    lineAt(content, bodyOffset) + line - 1,
+			Rule:    RuleTemplate,
+			Message: msg,
+		})
+	}
+
+	return issues
+}
+
+// lineAt returns the 1-based line holding the byte at offset
+func lineAt(content string, offset int) int {
+	return strings.Count(content[:offset], "\n") + 1
+}
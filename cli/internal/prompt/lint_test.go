@@ -0,0 +1,47 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Issue
+	}{
+		{
+			name:    "clean",
+			content: "---\nname: greeting\n---\n\nHello {{.name}}!\n",
+		},
+		{
+			name:    "unclosed action",
+			content: "---\nname: greeting\n---\n\nHello,\n{{.name\n",
+			want:    []Issue{{Line: 6, Rule: RuleTemplate, Message: "unclosed action"}},
+		},
+		{
+			name:    "no frontmatter",
+			content: "Line one\n{{if .x}}open",
+			want:    []Issue{{Line: 2, Rule: RuleTemplate, Message: "unexpected EOF"}},
+		},
+		{
+			name:    "bad frontmatter",
+			content: "---\nname: greeting\nvariables: [\n---\nHello",
+			want:    []Issue{{Line: 3, Rule: RuleFrontmatter, Message: "did not find expected node content"}},
+		},
+		{
+			name:    "unclosed frontmatter",
+			content: "\n---\nname: greeting\nHello",
+			want:    []Issue{{Line: 2, Rule: RuleFrontmatter, Message: "frontmatter is not closed by ---"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lint(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
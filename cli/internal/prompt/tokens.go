@@ -0,0 +1,16 @@
+package prompt
+
+// bytesPerToken is the rough average size of a token in English prose
+const bytesPerToken = 4
+
+// EstimateTokens approximates the number of tokens content will use, without
+// a model-specific tokenizer. Good enough for comparing prompts and budgeting.
+func EstimateTokens(content string) int {
+	return TokensForSize(len(content))
+}
+
+// TokensForSize estimates tokens from a content length in bytes, for queries
+// that compute the length in SQL instead of loading the content
+func TokensForSize(size int) int {
+	return size / bytesPerToken
+}
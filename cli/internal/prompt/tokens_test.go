@@ -0,0 +1,24 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		content string
+		want    int
+	}{
+		{"", 0},
+		{"abc", 0},
+		{"hello world", 2},
+		{strings.Repeat("a", 400), 100},
+		{"héllo", 1}, // counted in bytes
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.content); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.content, got, tt.want)
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/promptsmith/cli/internal/logging"
 )
 
 const (
@@ -110,7 +112,8 @@ func NewClient(remote string) *Client {
 	return &Client{
 		remote: remote,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Transport: logging.Transport(nil),
+			Timeout:   30 * time.Second,
 		},
 	}
 }
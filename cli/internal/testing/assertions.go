@@ -1,11 +1,14 @@
 package testing
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/promptsmith/cli/internal/benchmark"
 	"github.com/tidwall/gjson"
 )
 
@@ -54,6 +57,21 @@ func (a *Assertion) Evaluate(output string) AssertionResult {
 			result.Message = fmt.Sprintf("output does not match pattern '%s'", pattern)
 		}
 
+	case AssertNotMatches:
+		pattern := toString(a.Value)
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			result.Message = fmt.Sprintf("invalid regex pattern: %s", err)
+			return result
+		}
+		match := re.FindString(output)
+		result.Passed = !re.MatchString(output)
+		result.Expected = fmt.Sprintf("no match for '%s'", pattern)
+		result.Actual = truncate(output, 100)
+		if !result.Passed && result.Message == "" {
+			result.Message = fmt.Sprintf("output matches forbidden pattern '%s': '%s'", pattern, truncate(match, 50))
+		}
+
 	case AssertStartsWith:
 		prefix := toString(a.Value)
 		result.Passed = strings.HasPrefix(strings.TrimSpace(output), prefix)
@@ -177,6 +195,52 @@ func (a *Assertion) Evaluate(output string) AssertionResult {
 			result.Message = "output does not match snapshot; run with --update-snapshots to update"
 		}
 
+	case AssertOneOf:
+		actual := strings.TrimSpace(output)
+		for _, v := range a.Values {
+			if actual == v {
+				result.Passed = true
+				break
+			}
+		}
+		result.Expected = "one of [" + strings.Join(a.Values, ", ") + "]"
+		result.Actual = truncate(output, 100)
+		if !result.Passed && result.Message == "" {
+			result.Message = fmt.Sprintf("expected output to be one of [%s], got '%s'", strings.Join(a.Values, ", "), truncate(actual, 50))
+		}
+
+	case AssertJSONSchema:
+		result.Expected = "output matching JSON schema"
+		result.Actual = truncate(output, 100)
+		schema, err := schemaFor(*a)
+		if err != nil {
+			result.Message = err.Error()
+			return result
+		}
+		var data any
+		if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &data); err != nil {
+			result.Message = fmt.Sprintf("output is not valid JSON: %s", err)
+			return result
+		}
+		if err := validateSchema(schema, data); err != nil {
+			// Keep the schema violation visible even with a custom message
+			if result.Message == "" {
+				result.Message = fmt.Sprintf("schema validation failed at %s", err)
+			} else {
+				result.Message = fmt.Sprintf("%s (%s)", result.Message, err)
+			}
+			return result
+		}
+		result.Passed = true
+
+	case AssertMaxTokens, AssertMaxCost:
+		// Without usage from the executor there is nothing to check
+		return a.EvaluateUsage(nil)
+
+	case AssertSimilarity:
+		// Without an embedding provider there is nothing to compare
+		return a.EvaluateSimilarity(context.Background(), nil, output)
+
 	case AssertSentiment, AssertLanguage:
 		// These require LLM evaluation - mark as passed for now
 		// Will be implemented when LLM integration is added
@@ -190,6 +254,96 @@ func (a *Assertion) Evaluate(output string) AssertionResult {
 	return result
 }
 
+// needsUsage reports whether the assertion checks the cost of producing the
+// output rather than the output itself
+func (a *Assertion) needsUsage() bool {
+	return a.Type == AssertMaxTokens || a.Type == AssertMaxCost
+}
+
+// EvaluateUsage checks a max_tokens or max_cost assertion against the usage
+// reported for the output. With no usage, as in mock or replay runs, the
+// assertion is skipped.
+func (a *Assertion) EvaluateUsage(usage *Usage) AssertionResult {
+	result := AssertionResult{
+		Type:     a.Type,
+		Expected: fmt.Sprintf("%v", a.Value),
+		Message:  a.Message,
+	}
+
+	if usage == nil {
+		result.Passed = true
+		result.Skipped = true
+		result.Message = "no token usage available (run with --live)"
+		return result
+	}
+
+	limit, _ := toFloat(a.Value)
+
+	switch a.Type {
+	case AssertMaxTokens:
+		result.Passed = float64(usage.OutputTokens) <= limit
+		result.Expected = fmt.Sprintf("at most %g output tokens", limit)
+		result.Actual = fmt.Sprintf("%d output tokens", usage.OutputTokens)
+		if !result.Passed && result.Message == "" {
+			result.Message = fmt.Sprintf("expected at most %g output tokens, got %d", limit, usage.OutputTokens)
+		}
+
+	case AssertMaxCost:
+		result.Passed = usage.Cost <= limit
+		result.Expected = fmt.Sprintf("at most $%.6f", limit)
+		result.Actual = fmt.Sprintf("$%.6f", usage.Cost)
+		if !result.Passed && result.Message == "" {
+			result.Message = fmt.Sprintf("expected cost at most $%.6f, got $%.6f", limit, usage.Cost)
+		}
+
+	default:
+		result.Message = fmt.Sprintf("%s does not check usage", a.Type)
+	}
+
+	return result
+}
+
+// EvaluateSimilarity embeds the output and the expected text and passes when
+// their cosine similarity reaches the threshold. With no embedder, as in mock
+// or replay runs, the assertion is skipped.
+func (a *Assertion) EvaluateSimilarity(ctx context.Context, embedder benchmark.EmbeddingProvider, output string) AssertionResult {
+	result := AssertionResult{
+		Type:     a.Type,
+		Expected: fmt.Sprintf("similarity >= %.2f to '%s'", a.Threshold, truncate(toString(a.Value), 50)),
+		Actual:   truncate(output, 100),
+		Message:  a.Message,
+	}
+
+	if embedder == nil {
+		result.Passed = true
+		result.Skipped = true
+		result.Message = "no embedding provider available (run with --live and OPENAI_API_KEY set)"
+		return result
+	}
+
+	vectors, err := embedder.Embed(ctx, []string{output, toString(a.Value)})
+	if err != nil {
+		result.Message = fmt.Sprintf("failed to embed text: %s", err)
+		return result
+	}
+	if len(vectors) != 2 {
+		result.Message = fmt.Sprintf("expected 2 embeddings, got %d", len(vectors))
+		return result
+	}
+	similarity, err := benchmark.CosineSimilarity(vectors[0], vectors[1])
+	if err != nil {
+		result.Message = fmt.Sprintf("failed to compare embeddings: %s", err)
+		return result
+	}
+
+	result.Passed = similarity >= a.Threshold
+	result.Actual = fmt.Sprintf("similarity %.4f", similarity)
+	if !result.Passed && result.Message == "" {
+		result.Message = fmt.Sprintf("expected similarity at least %.2f, got %.4f", a.Threshold, similarity)
+	}
+	return result
+}
+
 func toString(v any) string {
 	if v == nil {
 		return ""
@@ -226,6 +380,20 @@ func toInt(v any) int {
 	}
 }
 
+func toFloat(v any) (float64, bool) {
+	switch val := v.(type) {
+	case int:
+		return float64(val), true
+	case float64:
+		return val, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -1,6 +1,9 @@
 package testing
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -69,6 +72,37 @@ func TestAssertionEvaluate(t *testing.T) {
 			output:     "test 123 test",
 			wantPassed: false,
 		},
+		{
+			name:       "matches - pass numbered list",
+			assertion:  Assertion{Type: AssertMatches, Value: `^1\. .+\n2\. `},
+			output:     "1. First point\n2. Second point",
+			wantPassed: true,
+		},
+		{
+			name:       "matches - fail malformed pattern",
+			assertion:  Assertion{Type: AssertMatches, Value: `(unclosed`},
+			output:     "(unclosed",
+			wantPassed: false,
+		},
+		// Not Matches (regex)
+		{
+			name:       "not_matches - pass",
+			assertion:  Assertion{Type: AssertNotMatches, Value: `sk-[A-Za-z0-9]{20,}`},
+			output:     "Your key is stored securely.",
+			wantPassed: true,
+		},
+		{
+			name:       "not_matches - fail",
+			assertion:  Assertion{Type: AssertNotMatches, Value: `sk-[A-Za-z0-9]{20,}`},
+			output:     "Use sk-abcdefghijklmnopqrstuvwx to call the API.",
+			wantPassed: false,
+		},
+		{
+			name:       "not_matches - fail malformed pattern",
+			assertion:  Assertion{Type: AssertNotMatches, Value: `[unclosed`},
+			output:     "anything",
+			wantPassed: false,
+		},
 		// Starts With
 		{
 			name:       "starts_with - pass",
@@ -261,6 +295,31 @@ func TestAssertionEvaluate(t *testing.T) {
 			output:     "  hello  ",
 			wantPassed: true,
 		},
+		// One of
+		{
+			name:       "one_of - matching label",
+			assertion:  Assertion{Type: AssertOneOf, Values: []string{"positive", "negative", "neutral"}},
+			output:     "negative",
+			wantPassed: true,
+		},
+		{
+			name:       "one_of - pass with whitespace trimming",
+			assertion:  Assertion{Type: AssertOneOf, Values: []string{"positive", "negative", "neutral"}},
+			output:     "  neutral\n",
+			wantPassed: true,
+		},
+		{
+			name:       "one_of - near miss fails",
+			assertion:  Assertion{Type: AssertOneOf, Values: []string{"positive", "negative", "neutral"}},
+			output:     "positive.",
+			wantPassed: false,
+		},
+		{
+			name:       "one_of - invalid label",
+			assertion:  Assertion{Type: AssertOneOf, Values: []string{"positive", "negative", "neutral"}},
+			output:     "mixed",
+			wantPassed: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -273,6 +332,266 @@ func TestAssertionEvaluate(t *testing.T) {
 	}
 }
 
+func TestOneOfFailureListsAllowedValues(t *testing.T) {
+	a := Assertion{Type: AssertOneOf, Values: []string{"yes", "no"}}
+	result := a.Evaluate("maybe")
+	if result.Passed {
+		t.Fatal("expected one_of to fail")
+	}
+	if !strings.Contains(result.Message, "[yes, no]") {
+		t.Errorf("expected message to list allowed values, got %q", result.Message)
+	}
+	if result.Expected != "one of [yes, no]" {
+		t.Errorf("expected allowed set in Expected, got %q", result.Expected)
+	}
+}
+
+func TestMatchesInvalidPatternMessage(t *testing.T) {
+	a := Assertion{Type: AssertMatches, Value: `[a-z`, Message: "should look like a slug"}
+	result := a.Evaluate("abc")
+
+	if result.Passed {
+		t.Fatal("expected malformed pattern to fail")
+	}
+	if !strings.Contains(result.Message, "invalid regex pattern") {
+		t.Errorf("expected invalid pattern message, got %q", result.Message)
+	}
+}
+
+func TestJSONSchemaAssertion(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"required": ["name", "tags"],
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"age": {"type": "integer"},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		}
+	}`
+
+	tests := []struct {
+		name        string
+		output      string
+		wantPassed  bool
+		wantMessage string
+	}{
+		{
+			name:       "valid object",
+			output:     `{"name": "Ada", "age": 36, "tags": ["math"]}`,
+			wantPassed: true,
+		},
+		{
+			name:        "missing required field",
+			output:      `{"name": "Ada"}`,
+			wantMessage: "$: missing properties: 'tags'",
+		},
+		{
+			name:        "wrong type field",
+			output:      `{"name": "Ada", "age": "thirty", "tags": []}`,
+			wantMessage: "$.age: expected integer, but got string",
+		},
+		{
+			name:        "wrong array item type",
+			output:      `{"name": "Ada", "tags": ["math", 3]}`,
+			wantMessage: "$.tags[1]: expected string, but got number",
+		},
+		{
+			name:        "non-JSON output",
+			output:      "Sure! Here is the JSON you asked for.",
+			wantMessage: "output is not valid JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Assertion{Type: AssertJSONSchema, Value: schema}
+			result := a.Evaluate(tt.output)
+
+			if result.Passed != tt.wantPassed {
+				t.Fatalf("expected passed=%v, got passed=%v, message: %s", tt.wantPassed, result.Passed, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.wantMessage) {
+				t.Errorf("expected message to contain %q, got %q", tt.wantMessage, result.Message)
+			}
+		})
+	}
+}
+
+func TestJSONSchemaKeywords(t *testing.T) {
+	tests := []struct {
+		name        string
+		schema      string
+		output      string
+		wantPassed  bool
+		wantMessage string
+	}{
+		{
+			name:       "pattern matches",
+			schema:     `{"type": "object", "properties": {"id": {"type": "string", "pattern": "^[a-z]+$"}}}`,
+			output:     `{"id": "abc"}`,
+			wantPassed: true,
+		},
+		{
+			name:        "pattern mismatch",
+			schema:      `{"type": "object", "properties": {"id": {"type": "string", "pattern": "^[a-z]+$"}}}`,
+			output:      `{"id": "ABC"}`,
+			wantMessage: "$.id: does not match pattern",
+		},
+		{
+			name:        "oneOf",
+			schema:      `{"oneOf": [{"type": "string"}, {"type": "integer"}]}`,
+			output:      `true`,
+			wantMessage: "$: ",
+		},
+		{
+			name:        "local $ref",
+			schema:      `{"$defs": {"tag": {"type": "string"}}, "type": "array", "items": {"$ref": "#/$defs/tag"}}`,
+			output:      `["a", 1]`,
+			wantMessage: "$[1]: expected string, but got number",
+		},
+		{
+			name:        "schema-valued additionalProperties",
+			schema:      `{"type": "object", "additionalProperties": {"type": "string"}}`,
+			output:      `{"a": "x", "b": 2}`,
+			wantMessage: "$.b: expected string, but got number",
+		},
+		{
+			name:        "external $ref",
+			schema:      `{"$ref": "other.json"}`,
+			output:      `{}`,
+			wantMessage: "invalid schema",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Assertion{Type: AssertJSONSchema, Value: tt.schema}
+			result := a.Evaluate(tt.output)
+			if result.Passed != tt.wantPassed {
+				t.Fatalf("expected passed=%v, got passed=%v, message: %s", tt.wantPassed, result.Passed, result.Message)
+			}
+			if !strings.Contains(result.Message, tt.wantMessage) {
+				t.Errorf("expected message to contain %q, got %q", tt.wantMessage, result.Message)
+			}
+		})
+	}
+}
+
+func TestUsageAssertions(t *testing.T) {
+	usage := &Usage{OutputTokens: 150, Cost: 0.0012}
+
+	tests := []struct {
+		name      string
+		assertion Assertion
+		usage     *Usage
+		passed    bool
+		skipped   bool
+	}{
+		{"max_tokens within limit", Assertion{Type: AssertMaxTokens, Value: 200}, usage, true, false},
+		{"max_tokens at limit", Assertion{Type: AssertMaxTokens, Value: 150}, usage, true, false},
+		{"max_tokens over limit", Assertion{Type: AssertMaxTokens, Value: 100}, usage, false, false},
+		{"max_cost within limit", Assertion{Type: AssertMaxCost, Value: 0.002}, usage, true, false},
+		{"max_cost over limit", Assertion{Type: AssertMaxCost, Value: 0.001}, usage, false, false},
+		{"max_cost from string", Assertion{Type: AssertMaxCost, Value: "0.01"}, usage, true, false},
+		{"no usage skips", Assertion{Type: AssertMaxTokens, Value: 1}, nil, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.assertion.EvaluateUsage(tt.usage)
+			if result.Passed != tt.passed || result.Skipped != tt.skipped {
+				t.Errorf("expected passed=%v skipped=%v, got passed=%v skipped=%v (%s)",
+					tt.passed, tt.skipped, result.Passed, result.Skipped, result.Message)
+			}
+		})
+	}
+
+	result := (&Assertion{Type: AssertMaxTokens, Value: 100}).EvaluateUsage(usage)
+	if result.Message != "expected at most 100 output tokens, got 150" {
+		t.Errorf("unexpected message: %q", result.Message)
+	}
+
+	// Evaluate has only the output to go on, so it skips
+	if result := (&Assertion{Type: AssertMaxCost, Value: 0.001}).Evaluate("output"); !result.Skipped {
+		t.Error("expected Evaluate to skip max_cost")
+	}
+}
+
+// stubEmbedder returns a fixed vector for each known text
+type stubEmbedder struct {
+	vectors map[string][]float64
+	err     error
+}
+
+func (e *stubEmbedder) Name() string { return "stub" }
+
+func (e *stubEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	out := make([][]float64, len(texts))
+	for i, text := range texts {
+		out[i] = e.vectors[text]
+	}
+	return out, nil
+}
+
+func newStubEmbedder() *stubEmbedder {
+	return &stubEmbedder{vectors: map[string][]float64{
+		"The meeting is on Monday":       {1, 0, 0},
+		"We meet on Monday":              {0.9, 0.1, 0},
+		"Bananas are rich in potassium":  {0, 0, 1},
+		"The meeting has been postponed": {0.6, 0.8, 0},
+	}}
+}
+
+func TestSimilarityAssertion(t *testing.T) {
+	expected := "The meeting is on Monday"
+
+	tests := []struct {
+		name      string
+		output    string
+		threshold float64
+		passed    bool
+	}{
+		{"paraphrase passes", "We meet on Monday", 0.9, true},
+		{"identical passes", expected, 1, true},
+		{"unrelated fails", "Bananas are rich in potassium", 0.5, false},
+		{"below threshold fails", "The meeting has been postponed", 0.8, false},
+		{"at threshold passes", "The meeting has been postponed", 0.6, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Assertion{Type: AssertSimilarity, Value: expected, Threshold: tt.threshold}
+			result := a.EvaluateSimilarity(context.Background(), newStubEmbedder(), tt.output)
+			if result.Passed != tt.passed || result.Skipped {
+				t.Errorf("expected passed=%v, got passed=%v skipped=%v (%s)",
+					tt.passed, result.Passed, result.Skipped, result.Message)
+			}
+		})
+	}
+
+	a := Assertion{Type: AssertSimilarity, Value: expected, Threshold: 0.8}
+	result := a.EvaluateSimilarity(context.Background(), newStubEmbedder(), "The meeting has been postponed")
+	if result.Message != "expected similarity at least 0.80, got 0.6000" {
+		t.Errorf("unexpected message: %q", result.Message)
+	}
+
+	result = a.EvaluateSimilarity(context.Background(), &stubEmbedder{err: errors.New("rate limited")}, "text")
+	if result.Passed || result.Skipped || !strings.Contains(result.Message, "rate limited") {
+		t.Errorf("expected embedding error to fail the assertion, got %+v", result)
+	}
+
+	// Mock mode has no embedder, so the assertion is skipped
+	if result := a.EvaluateSimilarity(context.Background(), nil, "text"); !result.Passed || !result.Skipped {
+		t.Errorf("expected skip without embedder, got %+v", result)
+	}
+	if result := a.Evaluate("text"); !result.Skipped {
+		t.Error("expected Evaluate to skip similarity")
+	}
+}
+
 func TestToString(t *testing.T) {
 	tests := []struct {
 		input    any
@@ -0,0 +1,136 @@
+package testing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadDataset reads the rows of a CSV or JSONL dataset. In a CSV file the
+// header row names the columns; in a JSONL file each line is an object.
+func LoadDataset(path string) ([]map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+
+	var rows []map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		rows, err = parseCSVDataset(data)
+	case ".jsonl":
+		rows, err = parseJSONLDataset(data)
+	default:
+		return nil, fmt.Errorf("unsupported dataset format '%s' (expected .csv or .jsonl)", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dataset %s: %w", filepath.Base(path), err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("dataset %s has no rows", filepath.Base(path))
+	}
+	return rows, nil
+}
+
+func parseCSVDataset(data []byte) ([]map[string]any, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+		if header[i] == "" {
+			return nil, fmt.Errorf("column %d has no name", i+1)
+		}
+	}
+
+	var rows []map[string]any
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]any, len(header))
+		for i, name := range header {
+			row[name] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseJSONLDataset(data []byte) ([]map[string]any, error) {
+	var rows []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var row map[string]any
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			return nil, fmt.Errorf("line %d: expected a JSON object: %w", line, err)
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// expandDataset turns a test case with a dataset into one case per row.
+// Each row's values are layered over the case's own inputs, and the case is
+// named after its row so failures point at the offending data. A relative
+// dataset path is resolved against baseDir, the suite file's directory.
+// Skipped cases are not expanded.
+func expandDataset(tc TestCase, baseDir string) ([]TestCase, error) {
+	if tc.Dataset == "" || tc.Skip {
+		return []TestCase{tc}, nil
+	}
+
+	path := tc.Dataset
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	rows, err := LoadDataset(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cases := make([]TestCase, len(rows))
+	for i, row := range rows {
+		inputs := make(map[string]any, len(tc.Inputs)+len(row))
+		for k, v := range tc.Inputs {
+			inputs[k] = v
+		}
+		for k, v := range row {
+			inputs[k] = v
+		}
+
+		c := tc
+		c.Name = fmt.Sprintf("%s [row %d]", tc.Name, i+1)
+		c.Inputs = inputs
+		c.Row = i + 1
+		cases[i] = c
+	}
+	return cases, nil
+}
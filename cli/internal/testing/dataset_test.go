@@ -0,0 +1,123 @@
+package testing
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeDataset(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write dataset: %v", err)
+	}
+	return path
+}
+
+func TestLoadDatasetCSV(t *testing.T) {
+	path := writeDataset(t, t.TempDir(), "people.csv", "name, city\nAlice,Paris\n\"Bob, Jr.\",Berlin\n")
+
+	rows, err := LoadDataset(path)
+	if err != nil {
+		t.Fatalf("LoadDataset failed: %v", err)
+	}
+	want := []map[string]any{
+		{"name": "Alice", "city": "Paris"},
+		{"name": "Bob, Jr.", "city": "Berlin"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("expected %v, got %v", want, rows)
+	}
+}
+
+func TestLoadDatasetJSONL(t *testing.T) {
+	path := writeDataset(t, t.TempDir(), "people.jsonl", `{"name": "Alice", "age": 30}
+
+{"name": "Bob", "tags": ["a", "b"]}
+`)
+
+	rows, err := LoadDataset(path)
+	if err != nil {
+		t.Fatalf("LoadDataset failed: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if rows[0]["name"] != "Alice" || rows[0]["age"] != float64(30) {
+		t.Errorf("unexpected first row: %v", rows[0])
+	}
+	if rows[1]["name"] != "Bob" {
+		t.Errorf("unexpected second row: %v", rows[1])
+	}
+}
+
+func TestLoadDatasetErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{"unsupported extension", "rows.txt", "a\n1\n", "unsupported dataset format"},
+		{"header only", "empty.csv", "name\n", "has no rows"},
+		{"ragged csv", "ragged.csv", "a,b\n1,2\n3\n", "wrong number of fields"},
+		{"blank column name", "blank.csv", "a,\n1,2\n", "column 2 has no name"},
+		{"jsonl not an object", "bad.jsonl", "{\"a\": 1}\n[1, 2]\n", "line 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeDataset(t, dir, tt.file, tt.content)
+			_, err := LoadDataset(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if _, err := LoadDataset(filepath.Join(dir, "missing.csv")); err == nil {
+		t.Error("expected error for missing dataset")
+	}
+}
+
+func TestExpandDataset(t *testing.T) {
+	dir := t.TempDir()
+	writeDataset(t, dir, "rows.csv", "name\nAlice\nBob\n")
+
+	tc := TestCase{
+		Name:    "greets",
+		Dataset: "rows.csv",
+		Inputs:  map[string]any{"name": "default", "greeting": "Hi"},
+	}
+	cases, err := expandDataset(tc, dir)
+	if err != nil {
+		t.Fatalf("expandDataset failed: %v", err)
+	}
+	if len(cases) != 2 {
+		t.Fatalf("expected 2 cases, got %d", len(cases))
+	}
+	if cases[1].Name != "greets [row 2]" || cases[1].Row != 2 {
+		t.Errorf("expected second case named after its row, got %q (row %d)", cases[1].Name, cases[1].Row)
+	}
+	if cases[0].Inputs["name"] != "Alice" || cases[0].Inputs["greeting"] != "Hi" {
+		t.Errorf("expected row values layered over inputs, got %v", cases[0].Inputs)
+	}
+	if tc.Inputs["name"] != "default" {
+		t.Error("expected original inputs to be left untouched")
+	}
+
+	plain, err := expandDataset(TestCase{Name: "plain"}, dir)
+	if err != nil || len(plain) != 1 || plain[0].Name != "plain" {
+		t.Errorf("expected a case without a dataset to pass through, got %v, %v", plain, err)
+	}
+
+	skipped, err := expandDataset(TestCase{Name: "skipped", Skip: true, Dataset: "missing.csv"}, dir)
+	if err != nil || len(skipped) != 1 {
+		t.Errorf("expected skipped case not to be expanded, got %v, %v", skipped, err)
+	}
+}
@@ -18,6 +18,9 @@ type LLMExecutor struct {
 	maxTokens   int
 	temperature float64
 	timeout     time.Duration
+	recorder    *Fixtures
+	tracer      benchmark.Tracer
+	maxRetries  int
 }
 
 // LLMExecutorOption configures the LLM executor
@@ -52,6 +55,29 @@ func WithTimeout(timeout time.Duration) LLMExecutorOption {
 	}
 }
 
+// WithRecorder records every successful completion into fixtures so the run
+// can later be replayed with a ReplayExecutor.
+func WithRecorder(fixtures *Fixtures) LLMExecutorOption {
+	return func(e *LLMExecutor) {
+		e.recorder = fixtures
+	}
+}
+
+// WithTracer traces a span around every provider call
+func WithTracer(tracer benchmark.Tracer) LLMExecutorOption {
+	return func(e *LLMExecutor) {
+		e.tracer = tracer
+	}
+}
+
+// WithMaxRetries retries a completion that failed with a transient API
+// error up to maxRetries times
+func WithMaxRetries(maxRetries int) LLMExecutorOption {
+	return func(e *LLMExecutor) {
+		e.maxRetries = maxRetries
+	}
+}
+
 // NewLLMExecutor creates a new LLM executor
 func NewLLMExecutor(registry *benchmark.ProviderRegistry, opts ...LLMExecutorOption) *LLMExecutor {
 	e := &LLMExecutor{
@@ -68,31 +94,43 @@ func NewLLMExecutor(registry *benchmark.ProviderRegistry, opts ...LLMExecutorOpt
 }
 
 // Execute sends the prompt to an LLM and returns the response
-func (e *LLMExecutor) Execute(renderedPrompt string, inputs map[string]any) (string, error) {
-	provider, err := e.registry.GetForModel(e.model)
+func (e *LLMExecutor) Execute(ctx context.Context, renderedPrompt string, inputs map[string]any) (string, error) {
+	output, _, err := e.ExecuteWithUsage(ctx, renderedPrompt, inputs)
+	return output, err
+}
+
+// ExecuteWithUsage is Execute, also returning the output token count and cost
+// reported by the provider
+func (e *LLMExecutor) ExecuteWithUsage(ctx context.Context, renderedPrompt string, inputs map[string]any) (string, *Usage, error) {
+	model := e.registry.ResolveModel(e.model)
+	provider, err := e.registry.GetForModel(model)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
+	provider = benchmark.WithRetries(provider, e.maxRetries)
 
 	req := benchmark.CompletionRequest{
-		Model:       e.model,
+		Model:       model,
 		Prompt:      renderedPrompt,
 		MaxTokens:   e.maxTokens,
 		Temperature: e.temperature,
 		Variables:   inputs,
 	}
 
-	ctx := context.Background()
 	if e.timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, e.timeout)
 		defer cancel()
 	}
 
-	resp, err := provider.Complete(ctx, req)
+	resp, err := benchmark.TracedComplete(ctx, e.tracer, provider, req)
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	if e.recorder != nil {
+		e.recorder.Record(model, renderedPrompt, resp.Content)
 	}
 
-	return resp.Content, nil
+	return resp.Content, &Usage{OutputTokens: resp.OutputTokens, Cost: resp.Cost}, nil
 }
@@ -2,6 +2,7 @@ package testing
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -14,12 +15,14 @@ type mockProvider struct {
 	response *benchmark.CompletionResponse
 	err      error
 	block    bool // when true, Complete blocks until the context is cancelled
+	gotModel string
 }
 
 func (m *mockProvider) Name() string                    { return m.name }
 func (m *mockProvider) Models() []string                { return []string{"gpt-4o-mini"} }
 func (m *mockProvider) SupportsModel(model string) bool { return true }
 func (m *mockProvider) Complete(ctx context.Context, req benchmark.CompletionRequest) (*benchmark.CompletionResponse, error) {
+	m.gotModel = req.Model
 	if m.block {
 		<-ctx.Done()
 		return nil, ctx.Err()
@@ -30,6 +33,53 @@ func (m *mockProvider) Complete(ctx context.Context, req benchmark.CompletionReq
 	return m.response, nil
 }
 
+// recordingTracer counts the spans started and ended around provider calls
+type recordingTracer struct {
+	started []benchmark.SpanStart
+	ended   []benchmark.SpanEnd
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs benchmark.SpanStart) (context.Context, benchmark.Span) {
+	t.started = append(t.started, attrs)
+	return ctx, t
+}
+
+func (t *recordingTracer) End(attrs benchmark.SpanEnd) {
+	t.ended = append(t.ended, attrs)
+}
+
+func TestLLMExecutor_Tracer(t *testing.T) {
+	registry := benchmark.NewProviderRegistry()
+	registry.Register(&mockProvider{
+		name: "openai",
+		response: &benchmark.CompletionResponse{
+			Content:      "Hello, world!",
+			PromptTokens: 10,
+			OutputTokens: 5,
+			TotalTokens:  15,
+			LatencyMs:    42,
+		},
+	})
+	tracer := &recordingTracer{}
+
+	executor := NewLLMExecutor(registry, WithModel("gpt-4o-mini"), WithTracer(tracer))
+	for i := 0; i < 2; i++ {
+		if _, err := executor.Execute(context.Background(), "Test prompt", nil); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+	}
+
+	if len(tracer.started) != 2 || len(tracer.ended) != 2 {
+		t.Fatalf("expected a span per call, got %d started and %d ended", len(tracer.started), len(tracer.ended))
+	}
+	if tracer.started[0].Provider != "openai" || tracer.started[0].Model != "gpt-4o-mini" {
+		t.Errorf("unexpected span start: %+v", tracer.started[0])
+	}
+	if end := tracer.ended[0]; end.PromptTokens != 10 || end.OutputTokens != 5 || end.TotalTokens != 15 || end.LatencyMs != 42 || end.Err != nil {
+		t.Errorf("unexpected span end: %+v", end)
+	}
+}
+
 func TestLLMExecutor_Execute(t *testing.T) {
 	registry := benchmark.NewProviderRegistry()
 	registry.Register(&mockProvider{
@@ -45,7 +95,7 @@ func TestLLMExecutor_Execute(t *testing.T) {
 
 	executor := NewLLMExecutor(registry, WithModel("gpt-4o-mini"))
 
-	output, err := executor.Execute("Test prompt", nil)
+	output, err := executor.Execute(context.Background(), "Test prompt", nil)
 	if err != nil {
 		t.Fatalf("Execute failed: %v", err)
 	}
@@ -55,6 +105,49 @@ func TestLLMExecutor_Execute(t *testing.T) {
 	}
 }
 
+func TestLLMExecutor_ModelAlias(t *testing.T) {
+	provider := &mockProvider{
+		name:     "openai",
+		response: &benchmark.CompletionResponse{Content: "ok"},
+	}
+	registry := benchmark.NewProviderRegistry()
+	registry.Register(provider)
+	registry.SetAliases(map[string]string{"fast": "gpt-4o-mini"})
+
+	executor := NewLLMExecutor(registry, WithModel("fast"))
+	if _, err := executor.Execute(context.Background(), "Test prompt", nil); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if provider.gotModel != "gpt-4o-mini" {
+		t.Errorf("expected request for gpt-4o-mini, got %q", provider.gotModel)
+	}
+}
+
+func TestLLMExecutor_ExecuteWithUsage(t *testing.T) {
+	registry := benchmark.NewProviderRegistry()
+	registry.Register(&mockProvider{
+		name: "openai",
+		response: &benchmark.CompletionResponse{
+			Content:      "Hello, world!",
+			OutputTokens: 5,
+			Cost:         0.0002,
+		},
+	})
+
+	executor := NewLLMExecutor(registry, WithModel("gpt-4o-mini"))
+
+	output, usage, err := executor.ExecuteWithUsage(context.Background(), "Test prompt", nil)
+	if err != nil {
+		t.Fatalf("ExecuteWithUsage failed: %v", err)
+	}
+	if output != "Hello, world!" {
+		t.Errorf("Expected 'Hello, world!', got '%s'", output)
+	}
+	if usage == nil || usage.OutputTokens != 5 || usage.Cost != 0.0002 {
+		t.Errorf("Expected usage of 5 tokens and $0.0002, got %+v", usage)
+	}
+}
+
 func TestLLMExecutor_Options(t *testing.T) {
 	registry := benchmark.NewProviderRegistry()
 
@@ -83,7 +176,7 @@ func TestLLMExecutor_Timeout(t *testing.T) {
 
 	done := make(chan error, 1)
 	go func() {
-		_, err := executor.Execute("Test prompt", nil)
+		_, err := executor.Execute(context.Background(), "Test prompt", nil)
 		done <- err
 	}()
 
@@ -119,3 +212,43 @@ func TestLLMExecutor_Defaults(t *testing.T) {
 		t.Errorf("Expected default temperature 0.7, got %f", executor.temperature)
 	}
 }
+
+func TestLLMExecutor_RecordThenReplay(t *testing.T) {
+	registry := benchmark.NewProviderRegistry()
+	registry.Register(&mockProvider{
+		name:     "openai",
+		response: &benchmark.CompletionResponse{Content: "Recorded answer", Model: "gpt-4o-mini"},
+	})
+
+	fixtures := NewFixtures()
+	live := NewLLMExecutor(registry, WithModel("gpt-4o-mini"), WithRecorder(fixtures))
+	if _, err := live.Execute(context.Background(), "Summarize: hello", nil); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	if err := fixtures.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadFixtures(path)
+	if err != nil {
+		t.Fatalf("LoadFixtures failed: %v", err)
+	}
+	if loaded.Len() != 1 {
+		t.Fatalf("Expected 1 recorded output, got %d", loaded.Len())
+	}
+
+	replay := NewReplayExecutor(loaded)
+	output, err := replay.Execute(context.Background(), "Summarize: hello", nil)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if output != "Recorded answer" {
+		t.Errorf("Expected 'Recorded answer', got '%s'", output)
+	}
+
+	if _, err := replay.Execute(context.Background(), "Summarize: something else", nil); err == nil {
+		t.Error("Expected error for prompt without a recorded output")
+	}
+}
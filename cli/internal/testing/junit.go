@@ -0,0 +1,132 @@
+package testing
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// junitTestSuites is the root element of a JUnit XML report, as consumed by
+// CI systems such as GitLab and Jenkins
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Time      string         `xml:"time,attr"`
+	Failures  []junitFailure `xml:"failure"`
+	Error     *junitFailure  `xml:"error"`
+	Skipped   *struct{}      `xml:"skipped"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// MarshalJUnit renders suite results as a JUnit XML report. Each suite becomes
+// a <testsuite> named after the prompt, each failed assertion a <failure>,
+// and a test that could not run (render or execution error) an <error>.
+func MarshalJUnit(results []*SuiteResult) ([]byte, error) {
+	report := junitTestSuites{Suites: make([]junitTestSuite, 0, len(results))}
+	var totalMs int64
+
+	for _, sr := range results {
+		suite := junitTestSuite{
+			Name: sr.PromptName,
+			Time: junitSeconds(sr.DurationMs),
+			Properties: []junitProperty{
+				{Name: "suite", Value: sr.SuiteName},
+				{Name: "version", Value: sr.Version},
+			},
+			Cases: make([]junitTestCase, 0, len(sr.Results)),
+		}
+
+		for _, tr := range sr.Results {
+			tc := junitTestCase{
+				Name:      tr.TestName,
+				ClassName: sr.PromptName,
+				Time:      junitSeconds(tr.DurationMs),
+			}
+
+			switch {
+			case tr.Skipped:
+				tc.Skipped = &struct{}{}
+				suite.Skipped++
+			case tr.Error != "":
+				tc.Error = &junitFailure{Message: tr.Error}
+				suite.Errors++
+			case !tr.Passed:
+				for _, f := range tr.Failures {
+					tc.Failures = append(tc.Failures, junitFailure{
+						Message: f.Message,
+						Type:    string(f.Type),
+						Body:    failureBody(f),
+					})
+				}
+				suite.Failures++
+			}
+
+			suite.Cases = append(suite.Cases, tc)
+			suite.Tests++
+		}
+
+		report.Suites = append(report.Suites, suite)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Skipped += suite.Skipped
+		totalMs += sr.DurationMs
+	}
+	report.Time = junitSeconds(totalMs)
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.Write(data)
+	b.WriteString("\n")
+	return []byte(b.String()), nil
+}
+
+// junitSeconds formats a millisecond duration the way JUnit expects
+func junitSeconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
+
+// failureBody describes a failed assertion, ending with its diff if it has one
+func failureBody(f AssertionResult) string {
+	body := fmt.Sprintf("expected: %s\nactual: %s", f.Expected, f.Actual)
+	if f.Diff != "" {
+		body += "\n" + f.Diff
+	}
+	return body
+}
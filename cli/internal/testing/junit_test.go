@@ -0,0 +1,109 @@
+package testing
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestMarshalJUnit(t *testing.T) {
+	results := []*SuiteResult{
+		{
+			SuiteName:  "greeting-tests",
+			PromptName: "greeting",
+			Version:    "1.0.1",
+			DurationMs: 1500,
+			Results: []TestResult{
+				{TestName: "passes", Passed: true, DurationMs: 250},
+				{
+					TestName:   "fails",
+					DurationMs: 1200,
+					Failures: []AssertionResult{
+						{Type: AssertContains, Expected: "Goodbye", Actual: "Hello", Message: "output does not contain 'Goodbye'"},
+					},
+				},
+				{TestName: "skipped", Skipped: true},
+				{TestName: "broken", Error: "execution failed: no provider"},
+			},
+		},
+		{
+			SuiteName:  "other-tests",
+			PromptName: "other",
+			Version:    "2.0.0",
+			Results: []TestResult{
+				{TestName: "ok", Passed: true},
+			},
+		},
+	}
+
+	data, err := MarshalJUnit(results)
+	if err != nil {
+		t.Fatalf("MarshalJUnit failed: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "<?xml") {
+		t.Error("expected XML declaration")
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("failed to parse JUnit XML: %v\n%s", err, data)
+	}
+
+	if report.Tests != 5 || report.Failures != 1 || report.Errors != 1 || report.Skipped != 1 {
+		t.Errorf("unexpected totals: tests=%d failures=%d errors=%d skipped=%d",
+			report.Tests, report.Failures, report.Errors, report.Skipped)
+	}
+	if len(report.Suites) != 2 {
+		t.Fatalf("expected 2 testsuites, got %d", len(report.Suites))
+	}
+
+	suite := report.Suites[0]
+	if suite.Name != "greeting" {
+		t.Errorf("expected testsuite named after prompt, got %q", suite.Name)
+	}
+	if suite.Tests != 4 || suite.Time != "1.500" {
+		t.Errorf("unexpected suite attributes: tests=%d time=%s", suite.Tests, suite.Time)
+	}
+	if len(suite.Cases) != 4 {
+		t.Fatalf("expected 4 testcases, got %d", len(suite.Cases))
+	}
+
+	failed := suite.Cases[1]
+	if failed.Time != "1.200" {
+		t.Errorf("expected time 1.200, got %s", failed.Time)
+	}
+	if len(failed.Failures) != 1 {
+		t.Fatalf("expected 1 failure element, got %d", len(failed.Failures))
+	}
+	if failed.Failures[0].Message != "output does not contain 'Goodbye'" {
+		t.Errorf("unexpected failure message: %q", failed.Failures[0].Message)
+	}
+	if failed.Failures[0].Type != "contains" {
+		t.Errorf("expected failure type contains, got %q", failed.Failures[0].Type)
+	}
+
+	if suite.Cases[0].Failures != nil || suite.Cases[0].Skipped != nil {
+		t.Error("expected passing testcase to have no failure or skipped element")
+	}
+	if suite.Cases[2].Skipped == nil {
+		t.Error("expected skipped element on skipped testcase")
+	}
+	if suite.Cases[3].Error == nil || suite.Cases[3].Error.Message != "execution failed: no provider" {
+		t.Errorf("expected error element with message, got %+v", suite.Cases[3].Error)
+	}
+}
+
+func TestMarshalJUnitEmpty(t *testing.T) {
+	data, err := MarshalJUnit(nil)
+	if err != nil {
+		t.Fatalf("MarshalJUnit failed: %v", err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("failed to parse JUnit XML: %v", err)
+	}
+	if report.Tests != 0 || len(report.Suites) != 0 {
+		t.Errorf("expected empty report, got %+v", report)
+	}
+}
@@ -0,0 +1,113 @@
+package testing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Fixture is a single recorded LLM response
+type Fixture struct {
+	Model  string `json:"model,omitempty"`
+	Prompt string `json:"prompt"`
+	Output string `json:"output"`
+}
+
+// Fixtures holds recorded LLM outputs keyed by a hash of the rendered prompt.
+// A live run with recording enabled fills it in; a ReplayExecutor reads it back.
+type Fixtures struct {
+	mu      sync.Mutex
+	Entries map[string]Fixture `json:"entries"`
+}
+
+// NewFixtures creates an empty fixture set
+func NewFixtures() *Fixtures {
+	return &Fixtures{Entries: make(map[string]Fixture)}
+}
+
+// LoadFixtures reads a fixtures file written by Save
+func LoadFixtures(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+
+	f := NewFixtures()
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("failed to parse fixtures: %w", err)
+	}
+	if f.Entries == nil {
+		f.Entries = make(map[string]Fixture)
+	}
+	return f, nil
+}
+
+// Save writes the fixtures to path as indented JSON
+func (f *Fixtures) Save(path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixtures: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write fixtures: %w", err)
+	}
+	return nil
+}
+
+// Record stores the output produced for a rendered prompt
+func (f *Fixtures) Record(model, renderedPrompt, output string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Entries[fixtureKey(renderedPrompt)] = Fixture{
+		Model:  model,
+		Prompt: renderedPrompt,
+		Output: output,
+	}
+}
+
+// Lookup returns the recorded output for a rendered prompt
+func (f *Fixtures) Lookup(renderedPrompt string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entry, ok := f.Entries[fixtureKey(renderedPrompt)]
+	return entry.Output, ok
+}
+
+// Len returns the number of recorded outputs
+func (f *Fixtures) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.Entries)
+}
+
+func fixtureKey(renderedPrompt string) string {
+	sum := sha256.Sum256([]byte(renderedPrompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// ReplayExecutor returns outputs recorded by a previous live run instead of
+// calling an LLM, so assertions can be re-run deterministically in CI.
+type ReplayExecutor struct {
+	fixtures *Fixtures
+}
+
+// NewReplayExecutor creates an executor that serves outputs from fixtures
+func NewReplayExecutor(fixtures *Fixtures) *ReplayExecutor {
+	return &ReplayExecutor{fixtures: fixtures}
+}
+
+// Execute returns the recorded output for the rendered prompt
+func (e *ReplayExecutor) Execute(ctx context.Context, renderedPrompt string, inputs map[string]any) (string, error) {
+	output, ok := e.fixtures.Lookup(renderedPrompt)
+	if !ok {
+		return "", fmt.Errorf("no recorded output for prompt (re-record with --live --record)")
+	}
+	return output, nil
+}
@@ -2,10 +2,16 @@ package testing
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"text/template"
 	"time"
 
+	"github.com/promptsmith/cli/internal/benchmark"
 	"github.com/promptsmith/cli/internal/db"
 	"github.com/promptsmith/cli/internal/prompt"
 )
@@ -15,12 +21,43 @@ type Runner struct {
 	db              *db.DB
 	executor        OutputExecutor
 	UpdateSnapshots bool
+
+	// Embedder backs similarity assertions. When nil they are skipped.
+	Embedder benchmark.EmbeddingProvider
+
+	// OnResult, when set, is called as each test case completes, before the
+	// suite finishes. suite carries the name, prompt and version under test.
+	OnResult func(suite *SuiteResult, tr TestResult)
+
+	// FailuresDir, when set, receives the rendered prompt, output and
+	// failed assertions of each failing test as
+	// <FailuresDir>/<suite>/<test>.txt. The suite's directory is cleared
+	// first, so it only holds failures of the latest run.
+	FailuresDir string
+
+	// artifactDirs maps each failures or snapshot directory used so far to
+	// the suite file that used it, so two suites of the same name cannot
+	// overwrite each other's files
+	artifactDirs map[string]string
 }
 
 // OutputExecutor generates output for a rendered prompt
 // For now, we use mock outputs; LLM integration comes in Phase 4
 type OutputExecutor interface {
-	Execute(renderedPrompt string, inputs map[string]any) (string, error)
+	Execute(ctx context.Context, renderedPrompt string, inputs map[string]any) (string, error)
+}
+
+// Usage is what producing an output cost, as reported by the provider
+type Usage struct {
+	OutputTokens int
+	Cost         float64
+}
+
+// UsageExecutor is implemented by executors that know the token count and
+// cost of each output. Without it, max_tokens and max_cost are skipped.
+type UsageExecutor interface {
+	OutputExecutor
+	ExecuteWithUsage(ctx context.Context, renderedPrompt string, inputs map[string]any) (string, *Usage, error)
 }
 
 // MockExecutor uses expected outputs defined in test cases
@@ -32,7 +69,7 @@ func NewMockExecutor(outputs map[string]string) *MockExecutor {
 	return &MockExecutor{outputs: outputs}
 }
 
-func (m *MockExecutor) Execute(renderedPrompt string, inputs map[string]any) (string, error) {
+func (m *MockExecutor) Execute(ctx context.Context, renderedPrompt string, inputs map[string]any) (string, error) {
 	// For mock testing, we just return the renderedPrompt as "output"
 	// In real usage, the test file would specify expected_output
 	return renderedPrompt, nil
@@ -49,8 +86,9 @@ func NewRunner(database *db.DB, executor OutputExecutor) *Runner {
 	}
 }
 
-// Run executes a test suite and returns results
-func (r *Runner) Run(suite *TestSuite) (*SuiteResult, error) {
+// Run executes a test suite and returns results. Cancelling ctx aborts any
+// in-flight executor calls.
+func (r *Runner) Run(ctx context.Context, suite *TestSuite) (*SuiteResult, error) {
 	startTime := time.Now()
 
 	result := &SuiteResult{
@@ -60,7 +98,7 @@ func (r *Runner) Run(suite *TestSuite) (*SuiteResult, error) {
 	}
 
 	// Get the prompt
-	p, err := r.db.GetPromptByName(suite.Prompt)
+	p, _, err := r.db.ResolvePromptName(suite.Prompt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get prompt: %w", err)
 	}
@@ -95,10 +133,16 @@ func (r *Runner) Run(suite *TestSuite) (*SuiteResult, error) {
 		return nil, fmt.Errorf("failed to parse prompt: %w", err)
 	}
 
-	// Run each test
-	for _, tc := range suite.Tests {
-		testResult := r.runTest(tc, parsed, suite.FilePath)
+	suiteTimeout, err := parseTimeout(suite.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid suite timeout: %w", err)
+	}
+
+	record := func(testResult TestResult) {
 		result.Results = append(result.Results, testResult)
+		if r.OnResult != nil {
+			r.OnResult(result, testResult)
+		}
 
 		if testResult.Skipped {
 			result.Skipped++
@@ -110,14 +154,56 @@ func (r *Runner) Run(suite *TestSuite) (*SuiteResult, error) {
 		result.Total++
 	}
 
+	var failuresDir string
+	if r.FailuresDir != "" {
+		failuresDir, err = artifactPath(r.FailuresDir, suite.Name, "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid suite name: %w", err)
+		}
+		if err := r.claimArtifactDir(failuresDir, suite); err != nil {
+			return nil, err
+		}
+		if err := os.RemoveAll(failuresDir); err != nil {
+			return nil, fmt.Errorf("failed to clear failures directory: %w", err)
+		}
+	}
+
+	// Run each test
+	for _, tc := range suite.Tests {
+		timeout := suiteTimeout
+		if tc.Timeout != "" {
+			timeout, err = parseTimeout(tc.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout for test '%s': %w", tc.Name, err)
+			}
+		}
+
+		// A dataset that can't be loaded fails its own case, not the suite
+		cases, err := expandDataset(tc, filepath.Dir(suite.FilePath))
+		if err != nil {
+			record(TestResult{TestName: tc.Name, Failures: []AssertionResult{}, Error: err.Error()})
+			continue
+		}
+		for _, c := range cases {
+			testResult := r.runTest(ctx, c, parsed, suite, timeout)
+			if failuresDir != "" && !testResult.Passed && !testResult.Skipped {
+				if err := writeFailure(failuresDir, parsed.Content, c, testResult); err != nil {
+					return nil, err
+				}
+			}
+			record(testResult)
+		}
+	}
+
 	result.DurationMs = time.Since(startTime).Milliseconds()
 	return result, nil
 }
 
-func (r *Runner) runTest(tc TestCase, parsed *prompt.ParsedPrompt, suiteFile string) TestResult {
+func (r *Runner) runTest(ctx context.Context, tc TestCase, parsed *prompt.ParsedPrompt, suite *TestSuite, timeout time.Duration) TestResult {
 	testStart := time.Now()
 	result := TestResult{
 		TestName: tc.Name,
+		Row:      tc.Row,
 		Failures: make([]AssertionResult, 0),
 	}
 
@@ -135,8 +221,25 @@ func (r *Runner) runTest(tc TestCase, parsed *prompt.ParsedPrompt, suiteFile str
 		return result
 	}
 
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	// Get output (for now, use the rendered prompt or mock)
-	output, err := r.executor.Execute(rendered, tc.Inputs)
+	var output string
+	var usage *Usage
+	if ue, ok := r.executor.(UsageExecutor); ok {
+		output, usage, err = ue.ExecuteWithUsage(ctx, rendered, tc.Inputs)
+	} else {
+		output, err = r.executor.Execute(ctx, rendered, tc.Inputs)
+	}
+	if timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		result.Error = fmt.Sprintf("timed out after %s", timeout)
+		result.DurationMs = time.Since(testStart).Milliseconds()
+		return result
+	}
 	if err != nil {
 		result.Error = fmt.Sprintf("execution failed: %s", err)
 		result.DurationMs = time.Since(testStart).Milliseconds()
@@ -147,11 +250,45 @@ func (r *Runner) runTest(tc TestCase, parsed *prompt.ParsedPrompt, suiteFile str
 	// Run assertions
 	result.Passed = true
 	for _, assertion := range tc.Assertions {
-		// For snapshot assertions, inject the expected_output as the value
+		// Snapshot files are read and written here rather than in Evaluate
+		if assertion.Type == AssertSnapshot && assertion.Store == SnapshotStoreFile {
+			if suite.FilePath == "" {
+				result.Passed = false
+				result.Error = "snapshot files need a suite file to be stored beside"
+				result.DurationMs = time.Since(testStart).Milliseconds()
+				return result
+			}
+			path, err := SnapshotPath(suite.FilePath, suite.Name, tc.Name)
+			if err == nil {
+				err = r.claimArtifactDir(filepath.Dir(path), suite)
+			}
+			if err != nil {
+				result.Passed = false
+				result.Error = err.Error()
+				result.DurationMs = time.Since(testStart).Milliseconds()
+				return result
+			}
+			if r.UpdateSnapshots {
+				if err := writeSnapshotFile(path, output); err != nil {
+					result.Passed = false
+					result.Error = fmt.Sprintf("failed to update snapshot: %s", err)
+					result.DurationMs = time.Since(testStart).Milliseconds()
+					return result
+				}
+				continue
+			}
+			if ar := evaluateSnapshotFile(assertion, path, output); !ar.Passed {
+				result.Passed = false
+				result.Failures = append(result.Failures, ar)
+			}
+			continue
+		}
+
+		// For inline snapshot assertions, inject the expected_output as the value
 		if assertion.Type == AssertSnapshot {
-			if r.UpdateSnapshots && suiteFile != "" {
+			if r.UpdateSnapshots && suite.FilePath != "" {
 				// Update mode: store current output as the new snapshot
-				if err := UpdateSnapshot(suiteFile, tc.Name, output); err != nil {
+				if err := UpdateSnapshot(suite.FilePath, tc.Name, output); err != nil {
 					result.Error = fmt.Sprintf("failed to update snapshot: %s", err)
 					result.DurationMs = time.Since(testStart).Milliseconds()
 					return result
@@ -161,7 +298,19 @@ func (r *Runner) runTest(tc TestCase, parsed *prompt.ParsedPrompt, suiteFile str
 			}
 			assertion.Value = tc.ExpectedOutput
 		}
-		ar := assertion.Evaluate(output)
+		var ar AssertionResult
+		switch {
+		case assertion.needsUsage():
+			ar = assertion.EvaluateUsage(usage)
+		case assertion.Type == AssertSimilarity:
+			ar = assertion.EvaluateSimilarity(ctx, r.Embedder, output)
+		default:
+			ar = assertion.Evaluate(output)
+		}
+		if ar.Skipped {
+			result.SkippedAssertions = append(result.SkippedAssertions, ar)
+			continue
+		}
 		if !ar.Passed {
 			result.Passed = false
 			result.Failures = append(result.Failures, ar)
@@ -172,6 +321,105 @@ func (r *Runner) runTest(tc TestCase, parsed *prompt.ParsedPrompt, suiteFile str
 	return result
 }
 
+// writeFailure saves what a failing test sent and got back, so the output
+// can be inspected after the run
+func writeFailure(dir, promptBody string, tc TestCase, tr TestResult) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create failures directory: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("=== PROMPT ===\n")
+	if rendered, err := renderPrompt(promptBody, tc.Inputs); err != nil {
+		buf.WriteString("ERROR: " + err.Error())
+	} else {
+		buf.WriteString(rendered)
+	}
+	buf.WriteString("\n\n=== OUTPUT ===\n")
+	if tr.Error != "" {
+		buf.WriteString("ERROR: " + tr.Error)
+	} else {
+		buf.WriteString(tr.Output)
+	}
+	buf.WriteString("\n")
+	if len(tr.Failures) > 0 {
+		buf.WriteString("\n=== FAILURES ===\n")
+		for _, f := range tr.Failures {
+			if f.Message != "" {
+				fmt.Fprintf(&buf, "%s: %s\n", f.Type, f.Message)
+			} else {
+				fmt.Fprintf(&buf, "%s\n", f.Type)
+			}
+			fmt.Fprintf(&buf, "  expected: %s\n", f.Expected)
+			fmt.Fprintf(&buf, "  actual:   %s\n", f.Actual)
+		}
+	}
+
+	path, err := artifactPath(dir, tc.Name, ".txt")
+	if err != nil {
+		return fmt.Errorf("invalid test name: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write failure output: %w", err)
+	}
+	return nil
+}
+
+// artifactName makes a suite or test name usable as a file name. Names that
+// would refer to a directory rather than name a file in one are refused.
+func artifactName(name string) (string, error) {
+	switch strings.TrimSpace(name) {
+	case "", ".", "..":
+		return "", fmt.Errorf("'%s' cannot be used as a file name", name)
+	}
+	return strings.NewReplacer("/", "_", "\\", "_").Replace(name), nil
+}
+
+// artifactPath returns the file for a suite or test name, with ext, in dir.
+// The path is checked to stay inside dir, since directories built from it
+// are deleted.
+func artifactPath(dir, name, ext string) (string, error) {
+	base, err := artifactName(name)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, base+ext)
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("'%s' does not name a file inside %s", name, dir)
+	}
+	return path, nil
+}
+
+// claimArtifactDir records that suite writes its files to dir, refusing a
+// directory already used by a different suite file
+func (r *Runner) claimArtifactDir(dir string, suite *TestSuite) error {
+	if r.artifactDirs == nil {
+		r.artifactDirs = make(map[string]string)
+	}
+	if other, ok := r.artifactDirs[dir]; ok && other != suite.FilePath {
+		return fmt.Errorf("suites %s and %s are both named '%s', so their files in %s would overwrite each other; rename one", other, suite.FilePath, suite.Name, dir)
+	}
+	r.artifactDirs[dir] = suite.FilePath
+	return nil
+}
+
+// parseTimeout parses a suite or test timeout such as "30s". An empty string
+// means no timeout.
+func parseTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("timeout must not be negative")
+	}
+	return d, nil
+}
+
 func renderPrompt(tmplBody string, inputs map[string]any) (string, error) {
 	tmpl, err := template.New("prompt").Parse(tmplBody)
 	if err != nil {
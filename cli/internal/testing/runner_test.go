@@ -1,9 +1,12 @@
 package testing
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/promptsmith/cli/internal/db"
 )
@@ -90,7 +93,7 @@ func TestRunnerRun(t *testing.T) {
 				{Name: "test1", Assertions: []Assertion{{Type: AssertNotEmpty}}},
 			},
 		}
-		_, err := runner.Run(suite)
+		_, err := runner.Run(context.Background(), suite)
 		if err == nil {
 			t.Fatal("expected error for nonexistent prompt")
 		}
@@ -105,7 +108,7 @@ func TestRunnerRun(t *testing.T) {
 				{Name: "test1", Assertions: []Assertion{{Type: AssertNotEmpty}}},
 			},
 		}
-		_, err := runner.Run(suite)
+		_, err := runner.Run(context.Background(), suite)
 		if err == nil {
 			t.Fatal("expected error for nonexistent version")
 		}
@@ -128,7 +131,7 @@ func TestRunnerRun(t *testing.T) {
 			},
 		}
 
-		result, err := runner.Run(suite)
+		result, err := runner.Run(context.Background(), suite)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -161,7 +164,7 @@ func TestRunnerRun(t *testing.T) {
 			},
 		}
 
-		result, err := runner.Run(suite)
+		result, err := runner.Run(context.Background(), suite)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -188,7 +191,7 @@ func TestRunnerRun(t *testing.T) {
 			},
 		}
 
-		result, err := runner.Run(suite)
+		result, err := runner.Run(context.Background(), suite)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -231,7 +234,7 @@ func TestRunnerRun(t *testing.T) {
 			},
 		}
 
-		result, err := runner.Run(suite)
+		result, err := runner.Run(context.Background(), suite)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -265,7 +268,7 @@ func TestRunnerRun(t *testing.T) {
 			},
 		}
 
-		result, err := runner.Run(suite)
+		result, err := runner.Run(context.Background(), suite)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -347,7 +350,7 @@ func TestRenderPrompt(t *testing.T) {
 func TestMockExecutor(t *testing.T) {
 	exec := NewMockExecutor(map[string]string{"test": "output"})
 
-	result, err := exec.Execute("prompt text", map[string]any{"key": "value"})
+	result, err := exec.Execute(context.Background(), "prompt text", map[string]any{"key": "value"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -375,7 +378,7 @@ func TestRunnerNoVersions(t *testing.T) {
 		},
 	}
 
-	_, err := runner.Run(suite)
+	_, err := runner.Run(context.Background(), suite)
 	if err == nil {
 		t.Fatal("expected error for prompt with no versions")
 	}
@@ -413,7 +416,7 @@ func TestRunnerTemplateError(t *testing.T) {
 		},
 	}
 
-	result, err := runner.Run(suite)
+	result, err := runner.Run(context.Background(), suite)
 	if err != nil {
 		t.Fatalf("Run should not error, but got: %v", err)
 	}
@@ -424,6 +427,382 @@ func TestRunnerTemplateError(t *testing.T) {
 	}
 }
 
+// slowExecutor takes delay to respond, returning early if the context ends
+type slowExecutor struct {
+	delay time.Duration
+}
+
+func (e *slowExecutor) Execute(ctx context.Context, renderedPrompt string, inputs map[string]any) (string, error) {
+	select {
+	case <-time.After(e.delay):
+		return renderedPrompt, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func TestRunnerTimeout(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := database.CreateProject("test-project")
+	p, _ := database.CreatePrompt(project.ID, "slow", "", "prompts/slow.prompt")
+	database.CreateVersion(p.ID, "1.0.0", "Hello", "[]", "{}", "Initial", "test", nil)
+
+	runner := NewRunner(database, &slowExecutor{delay: 200 * time.Millisecond})
+	suite := &TestSuite{
+		Name:    "timeouts",
+		Prompt:  "slow",
+		Timeout: "20ms",
+		Tests: []TestCase{
+			{
+				Name:       "suite timeout",
+				Assertions: []Assertion{{Type: AssertNotEmpty}},
+			},
+			{
+				Name:       "test timeout overrides suite",
+				Timeout:    "1s",
+				Assertions: []Assertion{{Type: AssertNotEmpty}},
+			},
+		},
+	}
+
+	result, err := runner.Run(context.Background(), suite)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	timedOut := result.Results[0]
+	if timedOut.Passed {
+		t.Error("expected slow test to fail")
+	}
+	if timedOut.Error != "timed out after 20ms" {
+		t.Errorf("expected timeout error, got %q", timedOut.Error)
+	}
+
+	if !result.Results[1].Passed {
+		t.Errorf("expected test with a longer timeout to pass, got error %q", result.Results[1].Error)
+	}
+	if result.Failed != 1 || result.Passed != 1 {
+		t.Errorf("expected 1 passed and 1 failed, got %d passed, %d failed", result.Passed, result.Failed)
+	}
+}
+
+func TestRunnerFailuresDir(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := database.CreateProject("test-project")
+	p, _ := database.CreatePrompt(project.ID, "greeting", "", "prompts/greeting.prompt")
+	database.CreateVersion(p.ID, "1.0.0", "Hello {{.name}}!", "[]", "{}", "Initial", "test", nil)
+
+	failuresDir := filepath.Join(t.TempDir(), "__failures__")
+	runner := NewRunner(database, nil)
+	runner.FailuresDir = failuresDir
+
+	// A leftover from an earlier run is cleared
+	staleDir := filepath.Join(failuresDir, "greetings")
+	os.MkdirAll(staleDir, 0755)
+	os.WriteFile(filepath.Join(staleDir, "fixed.txt"), []byte("old"), 0644)
+
+	suite := &TestSuite{
+		Name:   "greetings",
+		Prompt: "greeting",
+		Tests: []TestCase{
+			{
+				Name:       "passes",
+				Inputs:     map[string]any{"name": "Ada"},
+				Assertions: []Assertion{{Type: AssertContains, Value: "Ada"}},
+			},
+			{
+				Name:       "wrong name",
+				Inputs:     map[string]any{"name": "Ada"},
+				Assertions: []Assertion{{Type: AssertContains, Value: "Grace"}},
+			},
+		},
+	}
+	result, err := runner.Run(context.Background(), suite)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Failed != 1 {
+		t.Fatalf("expected 1 failure, got %d", result.Failed)
+	}
+
+	data, err := os.ReadFile(filepath.Join(failuresDir, "greetings", "wrong name.txt"))
+	if err != nil {
+		t.Fatalf("expected failure artifact: %v", err)
+	}
+	content := string(data)
+	for _, want := range []string{"=== PROMPT ===\nHello Ada!", "=== OUTPUT ===\nHello Ada!", "=== FAILURES ===\ncontains", "expected: Grace"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("expected %q in failure artifact, got:\n%s", want, content)
+		}
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(failuresDir, "greetings"))
+	if len(entries) != 1 {
+		t.Errorf("expected only the failing test's artifact, got %d files", len(entries))
+	}
+}
+
+func TestRunnerFailuresDirGuards(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := database.CreateProject("test-project")
+	p, _ := database.CreatePrompt(project.ID, "greeting", "", "prompts/greeting.prompt")
+	database.CreateVersion(p.ID, "1.0.0", "Hello {{.name}}!", "[]", "{}", "Initial", "test", nil)
+
+	testsDir := t.TempDir()
+	keep := filepath.Join(testsDir, "greeting.test.yaml")
+	os.WriteFile(keep, []byte("name: greetings"), 0644)
+
+	runner := NewRunner(database, nil)
+	runner.FailuresDir = filepath.Join(testsDir, "__failures__")
+	suite := func(name, file string) *TestSuite {
+		return &TestSuite{
+			Name:     name,
+			Prompt:   "greeting",
+			FilePath: file,
+			Tests: []TestCase{{
+				Name:       "wrong name",
+				Inputs:     map[string]any{"name": "Ada"},
+				Assertions: []Assertion{{Type: AssertContains, Value: "Grace"}},
+			}},
+		}
+	}
+
+	// A suite named ".." would clear the directory holding __failures__
+	if _, err := runner.Run(context.Background(), suite("..", keep)); err == nil {
+		t.Error("expected a suite named '..' to be refused")
+	}
+	if _, err := os.Stat(keep); err != nil {
+		t.Fatalf("expected the tests directory to survive: %v", err)
+	}
+
+	// Two suite files of the same name would share a failures directory
+	if _, err := runner.Run(context.Background(), suite("greetings", keep)); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if _, err := runner.Run(context.Background(), suite("greetings", keep)); err != nil {
+		t.Errorf("expected the same suite to run again, got %v", err)
+	}
+	other := filepath.Join(testsDir, "other.test.yaml")
+	if _, err := runner.Run(context.Background(), suite("greetings", other)); err == nil || !strings.Contains(err.Error(), "both named 'greetings'") {
+		t.Errorf("expected a clash between suites of the same name, got %v", err)
+	}
+}
+
+func TestRunnerNoTimeoutByDefault(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := database.CreateProject("test-project")
+	p, _ := database.CreatePrompt(project.ID, "slow", "", "prompts/slow.prompt")
+	database.CreateVersion(p.ID, "1.0.0", "Hello", "[]", "{}", "Initial", "test", nil)
+
+	runner := NewRunner(database, &slowExecutor{delay: 30 * time.Millisecond})
+	suite := &TestSuite{
+		Name:   "no-timeout",
+		Prompt: "slow",
+		Tests: []TestCase{
+			{Name: "slow but fine", Assertions: []Assertion{{Type: AssertNotEmpty}}},
+		},
+	}
+
+	result, err := runner.Run(context.Background(), suite)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !result.Results[0].Passed {
+		t.Errorf("expected test without a timeout to pass, got error %q", result.Results[0].Error)
+	}
+}
+
+func TestRunnerDataset(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := database.CreateProject("test-project")
+	p, _ := database.CreatePrompt(project.ID, "greeting", "", "prompts/greeting.prompt")
+	database.CreateVersion(p.ID, "1.0.0", "Hello {{.name}} from {{.city}}!", "[]", "{}", "Initial", "test", nil)
+
+	// The suite file's directory is where relative datasets are looked up
+	dir := t.TempDir()
+	writeDataset(t, dir, "people.csv", "name,city\nAlice,Paris\nBob,Berlin\n")
+
+	runner := NewRunner(database, nil)
+	suite := &TestSuite{
+		Name:     "dataset",
+		Prompt:   "greeting",
+		FilePath: filepath.Join(dir, "greeting.test.yaml"),
+		Tests: []TestCase{
+			{
+				Name:       "from paris",
+				Dataset:    "people.csv",
+				Assertions: []Assertion{{Type: AssertContains, Value: "Paris"}},
+			},
+			{
+				Name:       "missing dataset",
+				Dataset:    "missing.csv",
+				Assertions: []Assertion{{Type: AssertNotEmpty}},
+			},
+		},
+	}
+
+	result, err := runner.Run(context.Background(), suite)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Total != 3 || result.Passed != 1 || result.Failed != 2 {
+		t.Fatalf("expected 3 results (1 passed, 2 failed), got %d (%d passed, %d failed)",
+			result.Total, result.Passed, result.Failed)
+	}
+
+	first, second := result.Results[0], result.Results[1]
+	if first.TestName != "from paris [row 1]" || first.Row != 1 || !first.Passed {
+		t.Errorf("expected row 1 to pass, got %+v", first)
+	}
+	if second.TestName != "from paris [row 2]" || second.Row != 2 || second.Passed {
+		t.Errorf("expected row 2 to fail, got %+v", second)
+	}
+	if len(second.Failures) != 1 || !strings.Contains(second.Failures[0].Actual, "Bob from Berlin") {
+		t.Errorf("expected row 2 failure on its own output, got %+v", second.Failures)
+	}
+
+	missing := result.Results[2]
+	if missing.TestName != "missing dataset" || !strings.Contains(missing.Error, "failed to read dataset") {
+		t.Errorf("expected unreadable dataset to fail its case, got %+v", missing)
+	}
+}
+
+// usageExecutor echoes the prompt and reports fixed usage, like a live provider
+type usageExecutor struct {
+	usage Usage
+}
+
+func (e *usageExecutor) Execute(ctx context.Context, renderedPrompt string, inputs map[string]any) (string, error) {
+	return renderedPrompt, nil
+}
+
+func (e *usageExecutor) ExecuteWithUsage(ctx context.Context, renderedPrompt string, inputs map[string]any) (string, *Usage, error) {
+	usage := e.usage
+	return renderedPrompt, &usage, nil
+}
+
+func TestRunnerUsageAssertions(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := database.CreateProject("test-project")
+	p, _ := database.CreatePrompt(project.ID, "costly", "", "prompts/costly.prompt")
+	database.CreateVersion(p.ID, "1.0.0", "Hello", "[]", "{}", "Initial", "test", nil)
+
+	suite := &TestSuite{
+		Name:   "budget",
+		Prompt: "costly",
+		Tests: []TestCase{
+			{
+				Name: "within budget",
+				Assertions: []Assertion{
+					{Type: AssertNotEmpty},
+					{Type: AssertMaxTokens, Value: 500},
+					{Type: AssertMaxCost, Value: 0.01},
+				},
+			},
+			{
+				Name:       "too many tokens",
+				Assertions: []Assertion{{Type: AssertMaxTokens, Value: 100}},
+			},
+			{
+				Name:       "too expensive",
+				Assertions: []Assertion{{Type: AssertMaxCost, Value: 0.001}},
+			},
+		},
+	}
+
+	runner := NewRunner(database, &usageExecutor{usage: Usage{OutputTokens: 320, Cost: 0.0048}})
+	result, err := runner.Run(context.Background(), suite)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if !result.Results[0].Passed {
+		t.Errorf("expected case within budget to pass, got %+v", result.Results[0].Failures)
+	}
+	tokens := result.Results[1]
+	if tokens.Passed || len(tokens.Failures) != 1 || tokens.Failures[0].Actual != "320 output tokens" {
+		t.Errorf("expected max_tokens failure with 320 tokens, got %+v", tokens)
+	}
+	cost := result.Results[2]
+	if cost.Passed || len(cost.Failures) != 1 || cost.Failures[0].Type != AssertMaxCost {
+		t.Errorf("expected max_cost failure, got %+v", cost)
+	}
+
+	// The mock executor has no usage data, so the same assertions are skipped
+	runner = NewRunner(database, nil)
+	result, err = runner.Run(context.Background(), suite)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Passed != 3 {
+		t.Errorf("expected all cases to pass with usage assertions skipped, got %d passed", result.Passed)
+	}
+	if skipped := result.Results[0].SkippedAssertions; len(skipped) != 2 || !skipped[0].Skipped {
+		t.Errorf("expected 2 skipped assertions, got %+v", skipped)
+	}
+}
+
+func TestRunnerSimilarityAssertions(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := database.CreateProject("test-project")
+	p, _ := database.CreatePrompt(project.ID, "scheduler", "", "prompts/scheduler.prompt")
+	database.CreateVersion(p.ID, "1.0.0", "We meet on Monday", "[]", "{}", "Initial", "test", nil)
+
+	suite := &TestSuite{
+		Name:   "meaning",
+		Prompt: "scheduler",
+		Tests: []TestCase{
+			{
+				Name:       "close in meaning",
+				Assertions: []Assertion{{Type: AssertSimilarity, Value: "The meeting is on Monday", Threshold: 0.9}},
+			},
+			{
+				Name:       "unrelated",
+				Assertions: []Assertion{{Type: AssertSimilarity, Value: "Bananas are rich in potassium", Threshold: 0.5}},
+			},
+		},
+	}
+
+	runner := NewRunner(database, nil)
+	runner.Embedder = newStubEmbedder()
+	result, err := runner.Run(context.Background(), suite)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !result.Results[0].Passed {
+		t.Errorf("expected similar output to pass, got %+v", result.Results[0].Failures)
+	}
+	if unrelated := result.Results[1]; unrelated.Passed || len(unrelated.Failures) != 1 {
+		t.Errorf("expected similarity failure, got %+v", unrelated)
+	}
+
+	// Without an embedder the assertions are skipped
+	result, err = NewRunner(database, nil).Run(context.Background(), suite)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Passed != 2 {
+		t.Errorf("expected both cases to pass with similarity skipped, got %d passed", result.Passed)
+	}
+	if skipped := result.Results[1].SkippedAssertions; len(skipped) != 1 || skipped[0].Type != AssertSimilarity {
+		t.Errorf("expected skipped similarity assertion, got %+v", skipped)
+	}
+}
+
 // Ensure temp dir path doesn't depend on working directory
 func init() {
 	// Get absolute path for temp directory
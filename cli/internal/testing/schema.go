@@ -0,0 +1,93 @@
+package testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// schemaURL names an inline schema inside the compiler. It is never fetched;
+// an absolute URL keeps relative resolution away from the working directory.
+const schemaURL = "file:///assertion.schema.json"
+
+// schemaFor compiles the schema of a json_schema assertion. It is taken from
+// the schema field, or from value when schema is unset. Either may be a YAML
+// mapping or a string containing JSON. Schemas without $schema are read as
+// draft 2020-12.
+func schemaFor(a Assertion) (*jsonschema.Schema, error) {
+	raw := a.Schema
+	if raw == nil {
+		raw = a.Value
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("json_schema requires a schema")
+	}
+
+	var data []byte
+	if s, ok := raw.(string); ok {
+		data = []byte(s)
+	} else {
+		// Round-trip through JSON so YAML-decoded numbers and maps take the
+		// same shape as parsed output
+		var err error
+		data, err = json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema: %w", err)
+		}
+	}
+
+	compiler := jsonschema.NewCompiler()
+	// A test suite must not read files or URLs through $ref; only references
+	// within the inline schema resolve
+	compiler.LoadURL = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("external $ref %s is not supported", url)
+	}
+	if err := compiler.AddResource(schemaURL, bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	schema, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return schema, nil
+}
+
+// validateSchema checks value against the schema and returns an error naming
+// the first failing field and constraint, e.g. "$.tags[1]: expected string,
+// but got number"
+func validateSchema(schema *jsonschema.Schema, value any) error {
+	err := schema.Validate(value)
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return err
+	}
+	// The library nests one error per schema level; the innermost one names
+	// the actual constraint
+	for len(ve.Causes) > 0 {
+		ve = ve.Causes[0]
+	}
+	return fmt.Errorf("%s: %s", instancePath(ve.InstanceLocation), ve.Message)
+}
+
+// instancePath turns a JSON pointer such as /tags/1 into $.tags[1]
+func instancePath(pointer string) string {
+	path := "$"
+	if pointer == "" {
+		return path
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		if _, err := strconv.Atoi(token); err == nil {
+			path += "[" + token + "]"
+		} else {
+			path += "." + token
+		}
+	}
+	return path
+}
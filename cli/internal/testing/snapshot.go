@@ -0,0 +1,109 @@
+package testing
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/promptsmith/cli/internal/diff"
+)
+
+// Where a snapshot assertion keeps its expected output
+const (
+	SnapshotStoreInline = "inline" // The test case's expected_output, the default
+	SnapshotStoreFile   = "file"   // A .snap file beside the suite
+)
+
+// SnapshotDir is the directory, beside a suite file, that holds the suite's
+// snapshot files
+const SnapshotDir = "__snapshots__"
+
+// SnapshotPath returns the file that holds the snapshot of a test in the
+// suite at suiteFile: <suite dir>/__snapshots__/<suite>/<test>.snap. Names
+// that cannot be file names, such as "..", are an error.
+func SnapshotPath(suiteFile, suiteName, testName string) (string, error) {
+	dir, err := artifactPath(filepath.Join(filepath.Dir(suiteFile), SnapshotDir), suiteName, "")
+	if err != nil {
+		return "", fmt.Errorf("invalid suite name: %w", err)
+	}
+	path, err := artifactPath(dir, testName, ".snap")
+	if err != nil {
+		return "", fmt.Errorf("invalid test name: %w", err)
+	}
+	return path, nil
+}
+
+// writeSnapshotFile stores output as the snapshot at path
+func writeSnapshotFile(path, output string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(output), 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// evaluateSnapshotFile compares output with the snapshot stored at path.
+// Like inline snapshots, surrounding whitespace is ignored. A mismatch
+// carries a line diff from the snapshot to the output.
+func evaluateSnapshotFile(a Assertion, path, output string) AssertionResult {
+	result := AssertionResult{Type: a.Type, Message: a.Message, Actual: truncate(output, 100)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		result.Expected = "(no snapshot stored)"
+		if result.Message == "" {
+			result.Message = fmt.Sprintf("no snapshot file at %s; run with --update-snapshots to create it", path)
+		}
+		return result
+	}
+	if err != nil {
+		result.Expected = "(unreadable snapshot)"
+		result.Message = fmt.Sprintf("failed to read snapshot: %v", err)
+		return result
+	}
+
+	expected := string(data)
+	result.Expected = truncate(expected, 100)
+	result.Passed = strings.TrimSpace(output) == strings.TrimSpace(expected)
+	if !result.Passed {
+		result.Diff = lineDiff(strings.TrimSpace(expected), strings.TrimSpace(output))
+		if result.Message == "" {
+			result.Message = fmt.Sprintf("output does not match snapshot %s; run with --update-snapshots to update", path)
+		}
+	}
+	return result
+}
+
+// lineDiff returns the lines that differ between expected and actual, as
+// "- " for a line only in expected and "+ " for one only in actual, with up
+// to diffContext unchanged lines around each change and "..." marking the
+// unchanged lines left out
+func lineDiff(expected, actual string) string {
+	const diffContext = 2
+
+	lines := strings.Split(expected, "\n")
+	hunks := diff.Compute(lines, strings.Split(actual, "\n"), diffContext)
+
+	var sb strings.Builder
+	for _, h := range hunks {
+		// Hunks are separated by unchanged lines, so one always precedes a
+		// hunk that does not open the text
+		if h.OldStart > 1 || h.NewStart > 1 {
+			sb.WriteString("...\n")
+		}
+		for _, line := range h.Lines {
+			sb.WriteByte(line[0])
+			sb.WriteByte(' ')
+			sb.WriteString(line[1:])
+			sb.WriteByte('\n')
+		}
+	}
+	if n := len(hunks); n > 0 && hunks[n-1].OldStart+hunks[n-1].OldCount <= len(lines) {
+		sb.WriteString("...\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,147 @@
+package testing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotPath(t *testing.T) {
+	suiteFile := filepath.Join("tests", "greeting.test.yaml")
+	got, err := SnapshotPath(suiteFile, "greetings", "rows [row 2]/a")
+	want := filepath.Join("tests", "__snapshots__", "greetings", "rows [row 2]_a.snap")
+	if err != nil || got != want {
+		t.Errorf("SnapshotPath() = %q, %v, want %q", got, err, want)
+	}
+
+	// Names that would escape the snapshot directory are refused
+	for _, names := range [][2]string{{"..", "a"}, {".", "a"}, {"", "a"}, {"greetings", ".."}, {"greetings", " "}} {
+		if path, err := SnapshotPath(suiteFile, names[0], names[1]); err == nil {
+			t.Errorf("SnapshotPath(%q, %q) = %q, expected an error", names[0], names[1], path)
+		}
+	}
+}
+
+func TestRunnerSnapshotFiles(t *testing.T) {
+	database, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	project, _ := database.CreateProject("test-project")
+	p, _ := database.CreatePrompt(project.ID, "greeting", "", "prompts/greeting.prompt")
+	database.CreateVersion(p.ID, "1.0.0", "Hello {{.name}}!\nHow are you?", "[]", "{}", "Initial", "test", nil)
+
+	dir := t.TempDir()
+	suiteWith := func(name string) *TestSuite {
+		return &TestSuite{
+			Name:     "greetings",
+			Prompt:   "greeting",
+			FilePath: filepath.Join(dir, "greeting.test.yaml"),
+			Tests: []TestCase{{
+				Name:       "ada",
+				Inputs:     map[string]any{"name": name},
+				Assertions: []Assertion{{Type: AssertSnapshot, Store: SnapshotStoreFile}},
+			}},
+		}
+	}
+	snapPath := filepath.Join(dir, SnapshotDir, "greetings", "ada.snap")
+
+	t.Run("missing snapshot fails", func(t *testing.T) {
+		result, err := NewRunner(database, nil).Run(context.Background(), suiteWith("Ada"))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		tr := result.Results[0]
+		if tr.Passed || len(tr.Failures) != 1 {
+			t.Fatalf("expected one failure without a snapshot file, got %+v", tr)
+		}
+		if !strings.Contains(tr.Failures[0].Message, "run with --update-snapshots") {
+			t.Errorf("expected hint to update snapshots, got %q", tr.Failures[0].Message)
+		}
+		if _, err := os.Stat(snapPath); !os.IsNotExist(err) {
+			t.Errorf("expected a normal run not to write %s", snapPath)
+		}
+	})
+
+	t.Run("update creates snapshot", func(t *testing.T) {
+		runner := NewRunner(database, nil)
+		runner.UpdateSnapshots = true
+		result, err := runner.Run(context.Background(), suiteWith("Ada"))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if !result.Results[0].Passed {
+			t.Fatalf("expected update run to pass, got %+v", result.Results[0])
+		}
+		data, err := os.ReadFile(snapPath)
+		if err != nil {
+			t.Fatalf("expected snapshot file: %v", err)
+		}
+		if string(data) != "Hello Ada!\nHow are you?" {
+			t.Errorf("unexpected snapshot content %q", data)
+		}
+	})
+
+	t.Run("matching output passes", func(t *testing.T) {
+		result, err := NewRunner(database, nil).Run(context.Background(), suiteWith("Ada"))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if !result.Results[0].Passed {
+			t.Errorf("expected output matching the snapshot to pass, got %+v", result.Results[0].Failures)
+		}
+	})
+
+	t.Run("mismatch fails with diff", func(t *testing.T) {
+		result, err := NewRunner(database, nil).Run(context.Background(), suiteWith("Grace"))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		tr := result.Results[0]
+		if tr.Passed || len(tr.Failures) != 1 {
+			t.Fatalf("expected one failure on mismatch, got %+v", tr)
+		}
+		want := "- Hello Ada!\n+ Hello Grace!\n  How are you?\n"
+		if tr.Failures[0].Diff != want {
+			t.Errorf("Diff = %q, want %q", tr.Failures[0].Diff, want)
+		}
+		data, _ := os.ReadFile(snapPath)
+		if string(data) != "Hello Ada!\nHow are you?" {
+			t.Errorf("expected a normal run to leave the snapshot alone, got %q", data)
+		}
+	})
+
+	t.Run("suites sharing a name clash", func(t *testing.T) {
+		runner := NewRunner(database, nil)
+		if _, err := runner.Run(context.Background(), suiteWith("Ada")); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		other := suiteWith("Ada")
+		other.FilePath = filepath.Join(dir, "other.test.yaml")
+		result, err := runner.Run(context.Background(), other)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if tr := result.Results[0]; tr.Passed || !strings.Contains(tr.Error, "both named 'greetings'") {
+			t.Errorf("expected a clash with the first suite's snapshots, got %+v", tr)
+		}
+	})
+}
+
+func TestLineDiffContext(t *testing.T) {
+	expected := "1\n2\n3\n4\n5\n6\n7\n8"
+	actual := "1\n2\n3\n4\n5\n6\nseven\n8"
+	want := "...\n  5\n  6\n- 7\n+ seven\n  8\n"
+	if got := lineDiff(expected, actual); got != want {
+		t.Errorf("lineDiff() = %q, want %q", got, want)
+	}
+
+	// Changes far apart are shown separately; deletions come before additions
+	expected = "a\nb\nc\nd\ne\nf\ng\nh\ni\nj"
+	actual = "A\nb\nc\nd\ne\nf\ng\nh\nI\nj\nk"
+	want = "- a\n+ A\n  b\n  c\n...\n  g\n  h\n- i\n+ I\n  j\n+ k\n"
+	if got := lineDiff(expected, actual); got != want {
+		t.Errorf("lineDiff() = %q, want %q", got, want)
+	}
+}
@@ -3,6 +3,9 @@ package testing
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -13,6 +16,7 @@ type TestSuite struct {
 	Prompt      string     `yaml:"prompt" json:"prompt"`
 	Description string     `yaml:"description,omitempty" json:"description,omitempty"`
 	Version     string     `yaml:"version,omitempty" json:"version,omitempty"` // Optional: pin to specific version
+	Timeout     string     `yaml:"timeout,omitempty" json:"timeout,omitempty"` // Optional: per-test execution limit, e.g. "30s"
 	Tests       []TestCase `yaml:"tests" json:"tests"`
 	FilePath    string     `yaml:"-" json:"-"` // Set by ParseSuiteFile, not serialized
 }
@@ -25,14 +29,21 @@ type TestCase struct {
 	ExpectedOutput string         `yaml:"expected_output,omitempty" json:"expected_output,omitempty"`
 	Skip           bool           `yaml:"skip,omitempty" json:"skip,omitempty"`
 	Tags           []string       `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Timeout        string         `yaml:"timeout,omitempty" json:"timeout,omitempty"` // Overrides the suite timeout
+	Dataset        string         `yaml:"dataset,omitempty" json:"dataset,omitempty"` // CSV or JSONL file; runs the case once per row
+	Row            int            `yaml:"-" json:"-"`                                 // Dataset row, set when the case is expanded
 }
 
 // Assertion defines an expected condition on the output
 type Assertion struct {
-	Type    AssertionType `yaml:"type" json:"type"`
-	Value   any           `yaml:"value,omitempty" json:"value,omitempty"`
-	Path    string        `yaml:"path,omitempty" json:"path,omitempty"`       // For json_path assertions
-	Message string        `yaml:"message,omitempty" json:"message,omitempty"` // Custom failure message
+	Type      AssertionType `yaml:"type" json:"type"`
+	Value     any           `yaml:"value,omitempty" json:"value,omitempty"`
+	Path      string        `yaml:"path,omitempty" json:"path,omitempty"`           // For json_path assertions
+	Values    []string      `yaml:"values,omitempty" json:"values,omitempty"`       // For one_of assertions
+	Schema    any           `yaml:"schema,omitempty" json:"schema,omitempty"`       // For json_schema assertions
+	Threshold float64       `yaml:"threshold,omitempty" json:"threshold,omitempty"` // For similarity assertions, 0-1
+	Store     string        `yaml:"store,omitempty" json:"store,omitempty"`         // For snapshot assertions: inline (default) or file
+	Message   string        `yaml:"message,omitempty" json:"message,omitempty"`     // Custom failure message
 }
 
 // AssertionType defines the type of assertion
@@ -42,7 +53,8 @@ const (
 	AssertContains    AssertionType = "contains"
 	AssertNotContains AssertionType = "not_contains"
 	AssertEquals      AssertionType = "equals"
-	AssertMatches     AssertionType = "matches" // regex
+	AssertMatches     AssertionType = "matches"     // regex
+	AssertNotMatches  AssertionType = "not_matches" // regex that must not match
 	AssertStartsWith  AssertionType = "starts_with"
 	AssertEndsWith    AssertionType = "ends_with"
 	AssertMinLength   AssertionType = "min_length"
@@ -54,20 +66,27 @@ const (
 	AssertMinLines    AssertionType = "min_lines"
 	AssertMaxLines    AssertionType = "max_lines"
 	AssertWordCount   AssertionType = "word_count"
-	AssertSnapshot    AssertionType = "snapshot"  // compare against stored expected_output
-	AssertSentiment   AssertionType = "sentiment" // positive, negative, neutral
-	AssertLanguage    AssertionType = "language"  // e.g., "en", "es"
+	AssertSnapshot    AssertionType = "snapshot"    // compare against expected_output or a .snap file
+	AssertSentiment   AssertionType = "sentiment"   // positive, negative, neutral
+	AssertLanguage    AssertionType = "language"    // e.g., "en", "es"
+	AssertOneOf       AssertionType = "one_of"      // output equals one of values
+	AssertJSONSchema  AssertionType = "json_schema" // output validates against a JSON Schema
+	AssertMaxTokens   AssertionType = "max_tokens"  // output token count (live runs only)
+	AssertMaxCost     AssertionType = "max_cost"    // dollar cost of the call (live runs only)
+	AssertSimilarity  AssertionType = "similarity"  // embedding cosine similarity to value (live runs only)
 )
 
 // TestResult holds the result of running a single test
 type TestResult struct {
-	TestName   string            `json:"test_name"`
-	Passed     bool              `json:"passed"`
-	Skipped    bool              `json:"skipped"`
-	Output     string            `json:"output,omitempty"`
-	Failures   []AssertionResult `json:"failures,omitempty"`
-	Error      string            `json:"error,omitempty"`
-	DurationMs int64             `json:"duration_ms"`
+	TestName          string            `json:"test_name"`
+	Passed            bool              `json:"passed"`
+	Skipped           bool              `json:"skipped"`
+	Output            string            `json:"output,omitempty"`
+	Failures          []AssertionResult `json:"failures,omitempty"`
+	SkippedAssertions []AssertionResult `json:"skipped_assertions,omitempty"` // Could not be checked, e.g. max_tokens without --live
+	Error             string            `json:"error,omitempty"`
+	Row               int               `json:"row,omitempty"` // 1-based dataset row, for dataset cases
+	DurationMs        int64             `json:"duration_ms"`
 }
 
 // AssertionResult holds the result of a single assertion
@@ -77,6 +96,8 @@ type AssertionResult struct {
 	Expected string        `json:"expected"`
 	Actual   string        `json:"actual"`
 	Message  string        `json:"message,omitempty"`
+	Skipped  bool          `json:"skipped,omitempty"`
+	Diff     string        `json:"diff,omitempty"` // Expected to actual line diff, for snapshot files
 }
 
 // SuiteResult holds the result of running an entire test suite
@@ -116,21 +137,41 @@ func ParseSuite(data []byte) (*TestSuite, error) {
 	if suite.Name == "" {
 		return nil, fmt.Errorf("test suite requires a name")
 	}
+	// Suite and test names name failure and snapshot files
+	if _, err := artifactName(suite.Name); err != nil {
+		return nil, fmt.Errorf("invalid suite name: %w", err)
+	}
 	if suite.Prompt == "" {
 		return nil, fmt.Errorf("test suite requires a prompt name")
 	}
 	if len(suite.Tests) == 0 {
 		return nil, fmt.Errorf("test suite requires at least one test")
 	}
+	if _, err := parseTimeout(suite.Timeout); err != nil {
+		return nil, fmt.Errorf("invalid suite timeout '%s': %w", suite.Timeout, err)
+	}
 
 	// Validate each test
 	for i, tc := range suite.Tests {
 		if tc.Name == "" {
 			return nil, fmt.Errorf("test %d requires a name", i+1)
 		}
+		if _, err := artifactName(tc.Name); err != nil {
+			return nil, fmt.Errorf("test %d has an invalid name: %w", i+1, err)
+		}
 		if len(tc.Assertions) == 0 && !tc.Skip {
 			return nil, fmt.Errorf("test '%s' requires at least one assertion", tc.Name)
 		}
+		if _, err := parseTimeout(tc.Timeout); err != nil {
+			return nil, fmt.Errorf("test '%s' has an invalid timeout '%s': %w", tc.Name, tc.Timeout, err)
+		}
+		if tc.Dataset != "" {
+			switch strings.ToLower(filepath.Ext(tc.Dataset)) {
+			case ".csv", ".jsonl":
+			default:
+				return nil, fmt.Errorf("test '%s' dataset must be a .csv or .jsonl file", tc.Name)
+			}
+		}
 		for j, a := range tc.Assertions {
 			if err := validateAssertion(a); err != nil {
 				return nil, fmt.Errorf("test '%s' assertion %d: %w", tc.Name, j+1, err)
@@ -196,21 +237,56 @@ func UpdateSnapshot(path string, testName string, output string) error {
 
 func validateAssertion(a Assertion) error {
 	switch a.Type {
-	case AssertContains, AssertNotContains, AssertEquals, AssertMatches,
+	case AssertContains, AssertNotContains, AssertEquals,
 		AssertStartsWith, AssertEndsWith:
 		if a.Value == nil {
 			return fmt.Errorf("%s requires a value", a.Type)
 		}
+	case AssertMatches, AssertNotMatches:
+		if a.Value == nil {
+			return fmt.Errorf("%s requires a value", a.Type)
+		}
+		if _, err := regexp.Compile(toString(a.Value)); err != nil {
+			return fmt.Errorf("%s has an invalid pattern: %w", a.Type, err)
+		}
 	case AssertMinLength, AssertMaxLength, AssertLineCount, AssertMinLines,
 		AssertMaxLines, AssertWordCount:
 		if a.Value == nil {
 			return fmt.Errorf("%s requires a value", a.Type)
 		}
+	case AssertMaxTokens, AssertMaxCost:
+		if a.Value == nil {
+			return fmt.Errorf("%s requires a value", a.Type)
+		}
+		if _, ok := toFloat(a.Value); !ok {
+			return fmt.Errorf("%s value must be a number", a.Type)
+		}
+	case AssertSimilarity:
+		if a.Value == nil {
+			return fmt.Errorf("%s requires a value", a.Type)
+		}
+		if a.Threshold <= 0 || a.Threshold > 1 {
+			return fmt.Errorf("%s requires a threshold between 0 and 1", a.Type)
+		}
 	case AssertJSONPath:
 		if a.Path == "" {
 			return fmt.Errorf("json_path requires a path")
 		}
-	case AssertJSONValid, AssertNotEmpty, AssertSnapshot:
+	case AssertOneOf:
+		if len(a.Values) == 0 {
+			return fmt.Errorf("one_of requires a non-empty values list")
+		}
+	case AssertJSONSchema:
+		if _, err := schemaFor(a); err != nil {
+			return err
+		}
+	case AssertSnapshot:
+		switch a.Store {
+		case "", SnapshotStoreInline, SnapshotStoreFile:
+		default:
+			return fmt.Errorf("snapshot store must be %s or %s, got '%s'", SnapshotStoreInline, SnapshotStoreFile, a.Store)
+		}
+	case AssertJSONValid, AssertNotEmpty:
 		// No value required
 	case AssertSentiment:
 		if a.Value == nil {
@@ -136,6 +136,33 @@ tests:
 			wantErr: true,
 			errMsg:  "test 'test' assertion 1: json_path requires a path",
 		},
+		{
+			name: "suite name that is not a file name",
+			yaml: `
+name: ".."
+prompt: summarizer
+tests:
+  - name: test
+    assertions:
+      - type: not_empty
+`,
+			wantErr: true,
+			errMsg:  "invalid suite name: '..' cannot be used as a file name",
+		},
+		{
+			name: "snapshot with unknown store",
+			yaml: `
+name: test-suite
+prompt: summarizer
+tests:
+  - name: test
+    assertions:
+      - type: snapshot
+        store: disk
+`,
+			wantErr: true,
+			errMsg:  "test 'test' assertion 1: snapshot store must be inline or file, got 'disk'",
+		},
 		{
 			name: "full suite with multiple tests",
 			yaml: `
@@ -191,6 +218,228 @@ tests:
 	}
 }
 
+func TestParseOneOfAssertion(t *testing.T) {
+	suite, err := ParseSuite([]byte(`
+name: classifier
+prompt: sentiment
+tests:
+  - name: label
+    assertions:
+      - type: one_of
+        values: [positive, negative, neutral]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	values := suite.Tests[0].Assertions[0].Values
+	if len(values) != 3 || values[0] != "positive" {
+		t.Errorf("expected parsed values, got %v", values)
+	}
+
+	_, err = ParseSuite([]byte(`
+name: classifier
+prompt: sentiment
+tests:
+  - name: label
+    assertions:
+      - type: one_of
+`))
+	if err == nil {
+		t.Error("expected error for one_of without values")
+	}
+}
+
+func TestParseRegexAssertionsValidatePattern(t *testing.T) {
+	for _, assertionType := range []string{"matches", "not_matches"} {
+		_, err := ParseSuite([]byte(`
+name: guarded
+prompt: support
+tests:
+  - name: no secrets
+    assertions:
+      - type: ` + assertionType + `
+        value: "sk-[a-z"
+`))
+		if err == nil || !strings.Contains(err.Error(), "invalid pattern") {
+			t.Errorf("%s: expected invalid pattern error, got %v", assertionType, err)
+		}
+	}
+}
+
+func TestParseUsageAssertions(t *testing.T) {
+	suite, err := ParseSuite([]byte(`
+name: budget
+prompt: support
+tests:
+  - name: stays cheap
+    assertions:
+      - type: max_tokens
+        value: 200
+      - type: max_cost
+        value: 0.002
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(suite.Tests[0].Assertions) != 2 {
+		t.Fatalf("expected 2 assertions, got %d", len(suite.Tests[0].Assertions))
+	}
+
+	for _, value := range []string{"", "value: lots"} {
+		_, err := ParseSuite([]byte(`
+name: budget
+prompt: support
+tests:
+  - name: stays cheap
+    assertions:
+      - type: max_cost
+        ` + value + `
+`))
+		if err == nil {
+			t.Errorf("expected error for max_cost with %q", value)
+		}
+	}
+}
+
+func TestParseSimilarityAssertion(t *testing.T) {
+	suite, err := ParseSuite([]byte(`
+name: meaning
+prompt: support
+tests:
+  - name: paraphrase
+    assertions:
+      - type: similarity
+        value: The meeting is on Monday
+        threshold: 0.85
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a := suite.Tests[0].Assertions[0]; a.Threshold != 0.85 {
+		t.Errorf("expected threshold 0.85, got %v", a.Threshold)
+	}
+
+	for _, fields := range []string{"threshold: 0.8", "value: x", "value: x\n        threshold: 1.5"} {
+		_, err := ParseSuite([]byte(`
+name: meaning
+prompt: support
+tests:
+  - name: paraphrase
+    assertions:
+      - type: similarity
+        ` + fields + `
+`))
+		if err == nil {
+			t.Errorf("expected error for similarity with %q", fields)
+		}
+	}
+}
+
+func TestParseJSONSchemaAssertion(t *testing.T) {
+	suite, err := ParseSuite([]byte(`
+name: extractor
+prompt: extract
+tests:
+  - name: person
+    assertions:
+      - type: json_schema
+        schema:
+          type: object
+          required: [name, age]
+          properties:
+            name: { type: string }
+            age: { type: integer, minimum: 0 }
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a := suite.Tests[0].Assertions[0]
+	if result := a.Evaluate(`{"name": "Ada", "age": 36}`); !result.Passed {
+		t.Errorf("expected YAML schema to accept valid output, got: %s", result.Message)
+	}
+	result := a.Evaluate(`{"name": "Ada", "age": -1}`)
+	if result.Passed || !strings.Contains(result.Message, "$.age: must be >= 0 but found -1") {
+		t.Errorf("expected YAML schema to reject output at $.age, got passed=%v: %s", result.Passed, result.Message)
+	}
+
+	_, err = ParseSuite([]byte(`
+name: extractor
+prompt: extract
+tests:
+  - name: person
+    assertions:
+      - type: json_schema
+        value: "{not json"
+`))
+	if err == nil {
+		t.Error("expected error for json_schema with an unparseable schema")
+	}
+}
+
+func TestParseSuiteTimeouts(t *testing.T) {
+	suite, err := ParseSuite([]byte(`
+name: timed
+prompt: summarizer
+timeout: 30s
+tests:
+  - name: quick
+    timeout: 5s
+    assertions:
+      - type: not_empty
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if suite.Timeout != "30s" || suite.Tests[0].Timeout != "5s" {
+		t.Errorf("expected timeouts to be parsed, got suite=%q test=%q", suite.Timeout, suite.Tests[0].Timeout)
+	}
+
+	_, err = ParseSuite([]byte(`
+name: timed
+prompt: summarizer
+tests:
+  - name: quick
+    timeout: soon
+    assertions:
+      - type: not_empty
+`))
+	if err == nil || !strings.Contains(err.Error(), "invalid timeout") {
+		t.Errorf("expected invalid timeout error, got %v", err)
+	}
+}
+
+func TestParseSuiteDataset(t *testing.T) {
+	suite, err := ParseSuite([]byte(`
+name: data
+prompt: summarizer
+tests:
+  - name: rows
+    dataset: data/articles.csv
+    assertions:
+      - type: not_empty
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if suite.Tests[0].Dataset != "data/articles.csv" {
+		t.Errorf("expected dataset to be parsed, got %q", suite.Tests[0].Dataset)
+	}
+
+	_, err = ParseSuite([]byte(`
+name: data
+prompt: summarizer
+tests:
+  - name: rows
+    dataset: data/articles.xlsx
+    assertions:
+      - type: not_empty
+`))
+	if err == nil || !strings.Contains(err.Error(), ".csv or .jsonl") {
+		t.Errorf("expected unsupported dataset error, got %v", err)
+	}
+}
+
 func TestParseSuiteFields(t *testing.T) {
 	yaml := `
 name: test-suite
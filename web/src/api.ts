@@ -17,6 +17,8 @@ export interface Prompt {
   description: string;
   file_path: string;
   version?: string;
+  content?: string;
+  estimated_tokens?: number;
   created_at: string;
 }
 
@@ -49,7 +51,12 @@ export interface TestResult {
     actual: string;
     message?: string;
   }>;
+  skipped_assertions?: Array<{
+    type: string;
+    message?: string;
+  }>;
   error?: string;
+  row?: number;
   duration_ms: number;
 }
 
@@ -76,10 +83,12 @@ export interface BenchmarkSuite {
 
 export interface ModelResult {
   model: string;
+  temperature?: number;
   runs: number;
   errors: number;
   error_rate: number;
   latency_p50_ms: number;
+  latency_p95_ms?: number;
   latency_p99_ms: number;
   total_tokens_avg: number;
   cost_per_request: number;
@@ -89,6 +98,7 @@ export interface BenchmarkResult {
   suite_name: string;
   prompt_name: string;
   version: string;
+  version_id?: string;
   models: ModelResult[];
   duration_ms: number;
 }
@@ -281,6 +291,8 @@ export async function runBenchmark(name: string): Promise<BenchmarkResult> {
 export interface BenchmarkRunEntry {
   id: string;
   benchmark_id: string;
+  version_id?: string;
+  version?: string;
   results: BenchmarkResult;
   created_at: string;
 }
@@ -411,6 +423,7 @@ export async function getAvailableModels(): Promise<ModelInfo[]> {
 // Dashboard
 
 export interface ActivityEvent {
+  // One of 'version', 'tag', 'test_run', 'benchmark_run' or 'comment'
   type: string;
   title: string;
   detail: string;
@@ -421,9 +434,11 @@ export interface ActivityEvent {
 export interface PromptHealth {
   prompt_name: string;
   version_count: number;
+  commits_last_30d: number;
   last_test_status: string;
   last_test_at: string;
   test_pass_rate: number;
+  estimated_tokens: number;
 }
 
 export async function getDashboardActivity(limit?: number): Promise<ActivityEvent[]> {
@@ -452,6 +467,9 @@ export interface ChainStep {
   prompt_name: string;
   input_mapping: Record<string, string>;
   output_key: string;
+  version?: string; // Pinned version or tag; absent runs the latest
+  depends_on?: string[]; // Output keys of steps this one waits for
+  condition?: string; // e.g. steps.classify.output == "spam"; skipped when false
 }
 
 export interface ChainDetail {
@@ -468,15 +486,23 @@ export interface ChainStepInput {
   prompt_name: string;
   input_mapping: Record<string, string>;
   output_key: string;
+  version?: string;
+  depends_on?: string[];
+  condition?: string;
 }
 
 export interface ChainStepRunResult {
   step_order: number;
   prompt_name: string;
+  version: string;
   output_key: string;
   rendered_prompt: string;
   output: string;
   duration_ms: number;
+  prompt_tokens: number;
+  output_tokens: number;
+  cost: number;
+  skipped?: boolean; // The step's condition was false
 }
 
 export interface ChainRunResult {
@@ -485,6 +511,8 @@ export interface ChainRunResult {
   inputs: Record<string, string>;
   results: ChainStepRunResult[];
   final_output: string;
+  total_tokens: number;
+  total_cost: number;
   started_at: string;
   completed_at: string;
 }
@@ -538,6 +566,17 @@ export async function runChain(
   });
 }
 
-export async function listChainRuns(name: string): Promise<ChainRunResult[]> {
-  return fetchApi<ChainRunResult[]>(`/api/chains/${pathSegment(name)}/runs`);
+export interface ChainRunFilter {
+  status?: string;
+  limit?: number;
+  offset?: number;
+}
+
+export async function listChainRuns(name: string, filter: ChainRunFilter = {}): Promise<ChainRunResult[]> {
+  const query = new URLSearchParams();
+  if (filter.status) query.set('status', filter.status);
+  if (filter.limit !== undefined) query.set('limit', String(filter.limit));
+  if (filter.offset !== undefined) query.set('offset', String(filter.offset));
+  const suffix = query.toString() ? `?${query}` : '';
+  return fetchApi<ChainRunResult[]>(`/api/chains/${pathSegment(name)}/runs${suffix}`);
 }
@@ -72,13 +72,19 @@ describe('ChainDetailPage', () => {
         {
           step_order: 1,
           prompt_name: 'summarize',
+          version: '1.0.0',
           output_key: 'summary',
           rendered_prompt: 'Summarize: hello',
           output: 'Summary of hello',
           duration_ms: 500,
+          prompt_tokens: 12,
+          output_tokens: 4,
+          cost: 0.0001,
         },
       ],
       final_output: 'Summary of hello',
+      total_tokens: 16,
+      total_cost: 0.0001,
       started_at: '2024-01-01T00:00:00Z',
       completed_at: '2024-01-01T00:00:01Z',
     })
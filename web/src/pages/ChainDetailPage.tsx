@@ -18,6 +18,9 @@ import styles from './ChainDetailPage.module.css'
 interface StepDraft {
   prompt_name: string
   output_key: string
+  version: string
+  depends_on: string
+  condition: string
   mappings: { key: string; value: string }[]
 }
 
@@ -59,6 +62,9 @@ export function ChainDetailPage() {
           chainData.steps.map((s) => ({
             prompt_name: s.prompt_name,
             output_key: s.output_key,
+            version: s.version || '',
+            depends_on: (s.depends_on || []).join(', '),
+            condition: s.condition || '',
             mappings: Object.entries(s.input_mapping || {}).map(([k, v]) => ({
               key: k,
               value: v,
@@ -85,7 +91,7 @@ export function ChainDetailPage() {
   const handleAddStep = () => {
     setSteps([
       ...steps,
-      { prompt_name: '', output_key: '', mappings: [{ key: '', value: '' }] },
+      { prompt_name: '', output_key: '', version: '', depends_on: '', condition: '', mappings: [{ key: '', value: '' }] },
     ])
     setDirty(true)
   }
@@ -153,6 +159,12 @@ export function ChainDetailPage() {
         step_order: i + 1,
         prompt_name: s.prompt_name,
         output_key: s.output_key,
+        version: s.version || undefined,
+        depends_on: s.depends_on
+          .split(',')
+          .map((k) => k.trim())
+          .filter(Boolean),
+        condition: s.condition.trim() || undefined,
         input_mapping: Object.fromEntries(
           s.mappings.filter((m) => m.key).map((m) => [m.key, m.value])
         ),
@@ -331,6 +343,42 @@ export function ChainDetailPage() {
                         }
                       />
                     </div>
+                    <div className={styles.stepField}>
+                      <label className={styles.stepLabel} htmlFor={`chain-step-${idx}-version`}>Version</label>
+                      <input
+                        id={`chain-step-${idx}-version`}
+                        className={styles.stepInput}
+                        placeholder="latest"
+                        value={step.version}
+                        onChange={(e) =>
+                          updateStep(idx, 'version', e.target.value)
+                        }
+                      />
+                    </div>
+                    <div className={styles.stepField}>
+                      <label className={styles.stepLabel} htmlFor={`chain-step-${idx}-depends`}>Depends On</label>
+                      <input
+                        id={`chain-step-${idx}-depends`}
+                        className={styles.stepInput}
+                        placeholder="previous step"
+                        value={step.depends_on}
+                        onChange={(e) =>
+                          updateStep(idx, 'depends_on', e.target.value)
+                        }
+                      />
+                    </div>
+                    <div className={styles.stepField}>
+                      <label className={styles.stepLabel} htmlFor={`chain-step-${idx}-condition`}>Run If</label>
+                      <input
+                        id={`chain-step-${idx}-condition`}
+                        className={styles.stepInput}
+                        placeholder='e.g. steps.label.output == "spam"'
+                        value={step.condition}
+                        onChange={(e) =>
+                          updateStep(idx, 'condition', e.target.value)
+                        }
+                      />
+                    </div>
                   </div>
 
                   <div className={styles.stepFieldFull}>
@@ -457,10 +505,10 @@ export function ChainDetailPage() {
                     onClick={() => toggleExpanded(result.step_order)}
                   >
                     <span className={styles.resultStepTitle}>
-                      Step {result.step_order}: {result.prompt_name} → {result.output_key}
+                      Step {result.step_order}: {result.prompt_name}@{result.version} → {result.output_key}
                     </span>
                     <span className={styles.resultStepDuration}>
-                      {result.duration_ms}ms
+                      {result.skipped ? 'skipped' : `${result.duration_ms}ms`}
                     </span>
                   </div>
                   {expandedSteps.has(result.step_order) && (
@@ -70,6 +70,7 @@ const mockHealth = [
   {
     prompt_name: 'greeting',
     version_count: 3,
+    commits_last_30d: 2,
     last_test_status: 'passed',
     last_test_at: '2024-01-15T00:00:00Z',
     test_pass_rate: 1.0,
@@ -77,6 +78,7 @@ const mockHealth = [
   {
     prompt_name: 'summarize',
     version_count: 5,
+    commits_last_30d: 4,
     last_test_status: 'failed',
     last_test_at: '2024-01-14T00:00:00Z',
     test_pass_rate: 0.67,
@@ -84,6 +86,7 @@ const mockHealth = [
   {
     prompt_name: 'code-review',
     version_count: 1,
+    commits_last_30d: 0,
     last_test_status: 'none',
     last_test_at: '',
     test_pass_rate: 0,
@@ -114,6 +114,8 @@ export function HomePage() {
       case 'version': return '<>'
       case 'test_run': return '\u2713'
       case 'benchmark_run': return '\u25A0'
+      case 'tag': return '#'
+      case 'comment': return '\u270E'
       default: return '\u2022'
     }
   }